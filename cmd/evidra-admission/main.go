@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"samebits.com/evidra/internal/admission"
+	"samebits.com/evidra/internal/config"
+	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/internal/outbox"
+	"samebits.com/evidra/pkg/evidence"
+	_ "samebits.com/evidra/pkg/evidence/sqlitestore" // sqlite: evidence store URIs
+	"samebits.com/evidra/pkg/mode"
+	"samebits.com/evidra/pkg/version"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("evidra-admission", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	showVersion := fs.Bool("version", false, "Print version information and exit")
+	listenFlag := fs.String("listen", envOr("LISTEN_ADDR", ":8443"), "HTTPS listen address")
+	certFlag := fs.String("tls-cert", os.Getenv("EVIDRA_ADMISSION_TLS_CERT"), "TLS certificate file")
+	keyFlag := fs.String("tls-key", os.Getenv("EVIDRA_ADMISSION_TLS_KEY"), "TLS private key file")
+	evidenceFlag := fs.String("evidence-dir", "", "Path to store evidence records")
+	environmentFlag := fs.String("environment", os.Getenv("EVIDRA_ENVIRONMENT"), "Environment label (production, staging, development); defaults to namespace hints")
+	clusterFlag := fs.String("cluster", os.Getenv("EVIDRA_CLUSTER"), "Cluster name recorded as a scope dimension")
+	maxRiskFlag := fs.String("max-risk", os.Getenv("EVIDRA_ADMISSION_MAX_RISK"), "Deny requests above this effective risk (low, medium, high, critical)")
+	requireFlag := fs.Bool("require-prescription", envBool("EVIDRA_ADMISSION_REQUIRE_PRESCRIPTION", false), "Deny requests no prior prescription covers")
+	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	signerBackendFlag := fs.String("signer-backend", "", "Signer backend: local (default), pkcs11, agent, or remote")
+	urlFlag := fs.String("url", os.Getenv("EVIDRA_URL"), "Evidra API URL")
+	apiKeyFlag := fs.String("api-key", os.Getenv("EVIDRA_API_KEY"), "Evidra API key")
+	syncIntervalFlag := fs.Duration("sync-interval", 30*time.Second, "Interval between background outbox syncs to the API")
+	helpFlag := fs.Bool("help", false, "Show help")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *showVersion {
+		fmt.Fprintln(stdout, version.BuildString("evidra-admission"))
+		return 0
+	}
+	if *helpFlag {
+		printHelp(stderr)
+		return 0
+	}
+	if (*certFlag == "") != (*keyFlag == "") {
+		fmt.Fprintln(stderr, "--tls-cert and --tls-key must be set together")
+		return 2
+	}
+
+	logger := log.New(stderr, "", log.LstdFlags)
+	evidencePath := resolveEvidencePath(*evidenceFlag)
+
+	writeMode, err := config.ResolveEvidenceWriteMode("")
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve evidence write mode: %v\n", err)
+		return 1
+	}
+	signer, err := resolveSigner(*signerBackendFlag, *signingModeFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve signer: %v\n", err)
+		return 1
+	}
+	resolved, err := mode.Resolve(mode.Config{
+		URL:     *urlFlag,
+		APIKey:  *apiKeyFlag,
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve mode: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var onWrite func()
+	if resolved.IsOnline {
+		syncer := &outbox.Syncer{
+			EvidencePath: evidencePath,
+			Client:       resolved.Client,
+			Signer:       signer,
+			Actor:        evidence.Actor{Type: "controller", ID: "evidra-admission", Provenance: "outbox"},
+			Logger:       slog.New(slog.NewTextHandler(stderr, nil)),
+		}
+		go syncer.Run(ctx, *syncIntervalFlag)
+		onWrite = syncer.Notify
+	}
+
+	handler, err := admission.NewHandler(admission.Options{
+		EvidencePath:        evidencePath,
+		Signer:              signer,
+		Environment:         *environmentFlag,
+		BestEffortWrites:    writeMode == config.EvidenceWriteModeBestEffort,
+		MaxRisk:             *maxRiskFlag,
+		RequirePrescription: *requireFlag,
+		Cluster:             *clusterFlag,
+		OnWrite:             onWrite,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 2
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /validate", handler)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := &http.Server{
+		Addr:         *listenFlag,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	logger.Printf("evidra-admission %s listening on %s (evidence: %s)", version.Version, *listenFlag, evidencePath)
+	return serveUntilDone(ctx, srv, *certFlag, *keyFlag, logger, stderr)
+}
+
+// serveUntilDone serves srv, over TLS when certFile is set, until ctx is
+// done, then shuts it down gracefully.
+func serveUntilDone(ctx context.Context, srv *http.Server, certFile, keyFile string, logger *log.Logger, stderr io.Writer) int {
+	errCh := make(chan error, 1)
+	go func() {
+		if certFile == "" {
+			// The API server only calls webhooks over HTTPS; plain HTTP is
+			// for a TLS-terminating proxy or local testing.
+			logger.Printf("warning: serving plain HTTP; set --tls-cert and --tls-key")
+			errCh <- srv.ListenAndServe()
+			return
+		}
+		errCh <- srv.ListenAndServeTLS(certFile, keyFile)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(stderr, "listen: %v\n", err)
+			return 1
+		}
+		return 0
+	case <-ctx.Done():
+	}
+	logger.Printf("shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Printf("shutdown error: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func resolveEvidencePath(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if v := strings.TrimSpace(os.Getenv("EVIDRA_EVIDENCE_DIR")); v != "" {
+		return v
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), ".evidra", "evidence")
+	}
+	return filepath.Join(home, ".evidra", "evidence")
+}
+
+func envOr(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envBool(key string, fallback bool) bool {
+	v := strings.TrimSpace(strings.ToLower(os.Getenv(key)))
+	switch v {
+	case "1", "true", "yes":
+		return true
+	case "0", "false", "no":
+		return false
+	}
+	return fallback
+}
+
+// resolveSigner creates a Signer from the backend flag and environment
+// variables. Returns an error when mode is strict and no key is configured.
+func resolveSigner(backendRaw, modeRaw string) (evidence.Signer, error) {
+	mode, err := config.ResolveSigningMode(modeRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	backendCfg, err := config.ResolveSignerBackendConfig(backendRaw)
+	if err != nil {
+		return nil, err
+	}
+	if backendCfg.Backend != config.SignerBackendLocal {
+		s, err := ievsigner.NewBackendSigner(backendCfg)
+		if err != nil {
+			return nil, fmt.Errorf("resolveSigner: %w", err)
+		}
+		return s, nil
+	}
+
+	keyBase64 := strings.TrimSpace(os.Getenv("EVIDRA_SIGNING_KEY"))
+	keyPath := strings.TrimSpace(os.Getenv("EVIDRA_SIGNING_KEY_PATH"))
+
+	noKey := keyBase64 == "" && keyPath == ""
+	if noKey && mode == config.SigningModeStrict {
+		return nil, fmt.Errorf("signing key required in strict mode: set EVIDRA_SIGNING_KEY or EVIDRA_SIGNING_KEY_PATH (or --signing-mode optional)")
+	}
+
+	s, err := ievsigner.NewSigner(ievsigner.SignerConfig{
+		KeyBase64: keyBase64,
+		KeyPath:   keyPath,
+		DevMode:   noKey && mode == config.SigningModeOptional,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resolveSigner: %w", err)
+	}
+	return s, nil
+}
+
+func printHelp(w io.Writer) {
+	fmt.Fprintln(w, "evidra-admission — Kubernetes validating admission webhook that prescribes writes on the API server path.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "USAGE:")
+	fmt.Fprintln(w, "  evidra-admission [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "FLAGS:")
+	fmt.Fprintln(w, "  --listen <addr>           Listen address (default :8443)")
+	fmt.Fprintln(w, "  --tls-cert <file>         TLS certificate (with --tls-key)")
+	fmt.Fprintln(w, "  --tls-key <file>          TLS private key")
+	fmt.Fprintln(w, "  --evidence-dir <dir>      Evidence chain directory or sqlite:<path> (default: ~/.evidra/evidence)")
+	fmt.Fprintln(w, "  --environment <label>     Environment label; defaults to namespace hints")
+	fmt.Fprintln(w, "  --cluster <name>          Cluster name recorded as a scope dimension")
+	fmt.Fprintln(w, "  --max-risk <level>        Deny requests above this effective risk")
+	fmt.Fprintln(w, "  --require-prescription    Deny requests no prior prescription covers")
+	fmt.Fprintln(w, "  --signing-mode <mode>     Signing mode: strict (default) or optional")
+	fmt.Fprintln(w, "  --signer-backend <name>   Signer backend: local (default), pkcs11, agent, remote")
+	fmt.Fprintln(w, "  --url <url>               Forward evidence to an Evidra API")
+	fmt.Fprintln(w, "  --sync-interval <dur>     Background outbox sync interval when --url is set (default: 30s)")
+	fmt.Fprintln(w, "  --version                 Print version and exit")
+	fmt.Fprintln(w, "  --help                    Show this help")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ENDPOINTS:")
+	fmt.Fprintln(w, "  POST /validate   AdmissionReview (admission.k8s.io/v1)")
+	fmt.Fprintln(w, "  GET  /healthz    Liveness")
+}
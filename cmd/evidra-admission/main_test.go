@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_RejectsInvalidFlags(t *testing.T) {
+	t.Setenv("EVIDRA_SIGNING_KEY", "")
+	t.Setenv("EVIDRA_SIGNING_KEY_PATH", "")
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "cert without key", args: []string{"--tls-cert", "tls.crt"}, wantCode: 2, wantErr: "must be set together"},
+		{name: "invalid max risk", args: []string{"--signing-mode", "optional", "--max-risk", "severe"}, wantCode: 2, wantErr: "invalid max risk"},
+		{name: "strict without key", args: []string{"--evidence-dir", t.TempDir()}, wantCode: 1, wantErr: "signing key required"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(tt.args, &stdout, &stderr); code != tt.wantCode {
+			t.Fatalf("%s: code = %d, want %d: %s", tt.name, code, tt.wantCode, stderr.String())
+		}
+		if !strings.Contains(stderr.String(), tt.wantErr) {
+			t.Fatalf("%s: stderr = %q, want %q", tt.name, stderr.String(), tt.wantErr)
+		}
+	}
+}
+
+func TestRun_Version(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--version"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "evidra-admission") {
+		t.Fatalf("code = %d, stdout = %q", code, stdout.String())
+	}
+}
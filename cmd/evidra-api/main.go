@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"embed"
 	"flag"
 	"fmt"
@@ -13,11 +14,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+
 	evidrabenchmark "samebits.com/evidra"
 	"samebits.com/evidra/internal/api"
+	"samebits.com/evidra/internal/config"
 	"samebits.com/evidra/internal/db"
 	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/internal/ingest"
+	"samebits.com/evidra/internal/notify"
 	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/stream"
+	pkevidence "samebits.com/evidra/pkg/evidence"
 	"samebits.com/evidra/pkg/version"
 )
 
@@ -47,15 +55,8 @@ func run(args []string) int {
 		log.Fatal("EVIDRA_API_KEY is required")
 	}
 
-	// Signer (optional).
-	signer, err := ievsigner.NewSigner(ievsigner.SignerConfig{
-		KeyBase64: os.Getenv("EVIDRA_SIGNING_KEY"),
-		KeyPath:   os.Getenv("EVIDRA_SIGNING_KEY_PATH"),
-		DevMode:   os.Getenv("EVIDRA_SIGNING_MODE") == "optional",
-	})
-	if err != nil {
-		log.Printf("warning: signer not configured: %v", err)
-	}
+	signer, closeSigner := resolveServerSigner()
+	defer closeSigner()
 
 	cfg := api.RouterConfig{
 		APIKey:        apiKey,
@@ -65,8 +66,16 @@ func run(args []string) int {
 	if signer != nil {
 		cfg.PublicKey = signer.PublicKey()
 	}
+	if keyringPath := os.Getenv("EVIDRA_KEYRING_PATH"); keyringPath != "" {
+		keyring, err := ievsigner.LoadKeyring(keyringPath)
+		if err != nil {
+			log.Fatalf("load keyring: %v", err)
+		}
+		cfg.RetiredKeys = keyring
+	}
 
 	// Database (optional).
+	var streamHub *stream.Hub
 	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL != "" {
 		pool, err := db.Connect(databaseURL)
@@ -75,19 +84,9 @@ func run(args []string) int {
 		}
 		defer pool.Close()
 
-		cfg.Pinger = pool
-		es := store.NewEntryStore(pool)
-		cfg.EntryStore = es
-		cfg.RawStore = es // EntryStore implements RawEntryStore
-		cfg.KeyStore = store.NewKeyStore(pool)
-		cfg.BenchmarkStore = store.NewBenchmarkStore(pool)
-		cfg.InviteSecret = os.Getenv("EVIDRA_INVITE_SECRET")
-		cfg.Scorecard = es
-		cfg.Explain = es
-		cfg.WebhookStore = es
-		cfg.WebhookSigner = signer
-		cfg.ArgoCDSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_ARGOCD")
-		cfg.GenericSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_GENERIC")
+		bgCtx, stopBackground := context.WithCancel(context.Background())
+		defer stopBackground()
+		streamHub = configurePersistence(bgCtx, &cfg, pool, signer)
 
 		log.Printf("database connected, migrations applied")
 	} else {
@@ -105,22 +104,108 @@ func run(args []string) int {
 		cfg.UIFS = uiFS
 	}
 
-	handler := api.NewRouter(cfg)
-
 	srv := &http.Server{
 		Addr:         listenAddr,
-		Handler:      handler,
+		Handler:      api.NewRouter(cfg),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	if streamHub != nil {
+		// Shutdown waits for in-flight requests; closing the hub ends open
+		// streams instead of letting them run out the timeout.
+		srv.RegisterOnShutdown(streamHub.Close)
+	}
+	return serveUntilSignal(srv)
+}
+
+// resolveServerSigner returns the signer for server-written evidence and a
+// function that releases it. The signer is nil when no local key is
+// configured; a non-local EVIDRA_SIGNER_BACKEND must be reachable.
+func resolveServerSigner() (pkevidence.Signer, func()) {
+	backendCfg, err := config.ResolveSignerBackendConfig("")
+	if err != nil {
+		log.Fatalf("signer backend: %v", err)
+	}
+	if backendCfg.Backend != config.SignerBackendLocal {
+		backendSigner, err := ievsigner.NewBackendSigner(backendCfg)
+		if err != nil {
+			log.Fatalf("signer backend: %v", err)
+		}
+		return backendSigner, func() { _ = backendSigner.Close() }
+	}
+	localSigner, err := ievsigner.NewSigner(ievsigner.SignerConfig{
+		KeyBase64: os.Getenv("EVIDRA_SIGNING_KEY"),
+		KeyPath:   os.Getenv("EVIDRA_SIGNING_KEY_PATH"),
+		DevMode:   os.Getenv("EVIDRA_SIGNING_MODE") == "optional",
+	})
+	if err != nil {
+		log.Printf("warning: signer not configured: %v", err)
+		return nil, func() {}
+	}
+	return localSigner, func() {}
+}
+
+// configurePersistence wires the database-backed stores into cfg and starts
+// the stream hub and notification worker, which run until ctx is done.
+func configurePersistence(ctx context.Context, cfg *api.RouterConfig, pool *pgxpool.Pool, signer pkevidence.Signer) *stream.Hub {
+	cfg.Pinger = pool
+	es := store.NewEntryStore(pool)
+	cfg.EntryStore = es
+	cfg.RawStore = es // EntryStore implements RawEntryStore
+	ingestPolicy, err := ingest.PolicyFromEnv()
+	if err != nil {
+		log.Fatalf("ingest policy: %v", err)
+	}
+	if ingestPolicy.Signatures != ingest.SignaturesRequired {
+		log.Printf("warning: tenants without registered signing keys can forward unsigned evidence; set EVIDRA_INGEST_SIGNATURES=required once every tenant has registered its keys")
+	}
+	signingKeys := store.NewSigningKeyStore(pool)
+	cfg.Ingester = ingest.NewVerifier(es, signingKeys, ingestPolicy)
+	cfg.SigningKeys = signingKeys
+	cfg.Quarantine = es
+	cfg.Chains = es
+	cfg.ChainValidator = ingest.NewChainValidator(es, signingKeys, serverKeyring(cfg.PublicKey, cfg.RetiredKeys))
+
+	streamHub := stream.NewHub()
+	go streamHub.Run(ctx, es.ListenEntries)
+	cfg.Stream = es
+	cfg.StreamHub = streamHub
+
+	notifications := store.NewNotificationStore(pool)
+	cfg.Notifications = notifications
+	egress, err := notify.EgressPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("notification egress: %v", err)
+	}
+	go notify.NewWorker(notifications, es, signer, egress).Run(ctx)
+
+	cfg.KeyStore = store.NewKeyStore(pool)
+	cfg.BenchmarkStore = store.NewBenchmarkStore(pool)
+	cfg.InviteSecret = os.Getenv("EVIDRA_INVITE_SECRET")
+	cfg.Scorecard = es
+	cfg.Explain = es
+	cfg.Approvals = es
+	cfg.ApprovalKeys = signingKeys
+	cfg.WebhookStore = es
+	cfg.WebhookSigner = signer
+	cfg.ArgoCDSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_ARGOCD")
+	cfg.GenericSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_GENERIC")
+	cfg.GitHubSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_GITHUB")
+	cfg.GitLabSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_GITLAB")
+	cfg.FluxSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_FLUX")
+	cfg.RolloutsSecret = os.Getenv("EVIDRA_WEBHOOK_SECRET_ROLLOUTS")
+	return streamHub
+}
 
-	// Graceful shutdown.
+// serveUntilSignal serves srv until SIGINT or SIGTERM, then shuts it down
+// gracefully.
+func serveUntilSignal(srv *http.Server) int {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("evidra-api %s listening on %s", version.Version, listenAddr)
+		log.Printf("evidra-api %s listening on %s", version.Version, srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("listen: %v", err)
 		}
@@ -145,3 +230,19 @@ func envOr(key, fallback string) string {
 	}
 	return fallback
 }
+
+// serverKeyring collects the keys the API signs webhook evidence with, so
+// server-side chain validation accepts those chains.
+func serverKeyring(current ed25519.PublicKey, retired *pkevidence.Keyring) *pkevidence.Keyring {
+	keyring := pkevidence.NewKeyring()
+	if len(current) == ed25519.PublicKeySize {
+		keyring.Add(current)
+	}
+	if retired != nil {
+		for _, kid := range retired.KeyIDs() {
+			pub, _ := retired.Lookup(kid)
+			keyring.Add(pub)
+		}
+	}
+	return keyring
+}
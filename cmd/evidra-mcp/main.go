@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,7 +17,10 @@ import (
 
 	"samebits.com/evidra/internal/config"
 	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/internal/outbox"
+	"samebits.com/evidra/internal/telemetry"
 	"samebits.com/evidra/pkg/evidence"
+	_ "samebits.com/evidra/pkg/evidence/sqlitestore" // sqlite: evidence store URIs
 	"samebits.com/evidra/pkg/mcpserver"
 	"samebits.com/evidra/pkg/mode"
 	"samebits.com/evidra/pkg/version"
@@ -34,10 +38,14 @@ func run(args []string, stdout, stderr io.Writer) int {
 	environmentFlag := fs.String("environment", "", "Environment label (production, staging, development)")
 	retryFlag := fs.Bool("retry-tracker", false, "Enable retry loop tracking")
 	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	signerBackendFlag := fs.String("signer-backend", "", "Signer backend: local (default), pkcs11, agent, or remote")
 	urlFlag := fs.String("url", os.Getenv("EVIDRA_URL"), "Evidra API URL")
 	apiKeyFlag := fs.String("api-key", os.Getenv("EVIDRA_API_KEY"), "Evidra API key")
 	offlineFlag := fs.Bool("offline", false, "Force offline mode")
 	fallbackOfflineFlag := fs.Bool("fallback-offline", false, "Fall back to offline on API failure")
+	requireApprovalFlag := fs.String("require-approval", "", "Lowest effective risk that needs human approval: low, medium, high, critical, or off")
+	approverKeyringFlag := fs.String("approver-keyring", "", "Approvers' public keys (JWKS or PEM); only approvals they signed count")
+	syncIntervalFlag := fs.Duration("sync-interval", 30*time.Second, "Interval between background outbox syncs to the API")
 	helpFlag := fs.Bool("help", false, "Show help")
 
 	if err := fs.Parse(args); err != nil {
@@ -61,7 +69,13 @@ func run(args []string, stdout, stderr io.Writer) int {
 		return 1
 	}
 
-	signer, signerErr := resolveSigner(*signingModeFlag)
+	approvalRisk, approvers, approvalErr := resolveApprovals(*requireApprovalFlag, *approverKeyringFlag)
+	if approvalErr != nil {
+		fmt.Fprintf(stderr, "%v\n", approvalErr)
+		return 1
+	}
+
+	signer, signerErr := resolveSigner(*signerBackendFlag, *signingModeFlag)
 	if signerErr != nil {
 		fmt.Fprintf(stderr, "resolve signer: %v\n", signerErr)
 		return 1
@@ -87,15 +101,29 @@ func run(args []string, stdout, stderr io.Writer) int {
 		return 1
 	}
 
+	// Entries are pushed by a background outbox loop that resumes from the
+	// last acknowledged entry; each write only wakes it up.
 	var forwardFn mcpserver.ForwardFunc
 	if resolved.IsOnline {
-		apiClient := resolved.Client
-		forwardFn = func(ctx context.Context, entry json.RawMessage) {
-			if _, fwdErr := apiClient.Forward(ctx, entry); fwdErr != nil {
-				log.New(stderr, "", log.LstdFlags).Printf("warning: forward evidence: %v", fwdErr)
-			}
+		syncer := &outbox.Syncer{
+			EvidencePath: evidencePath,
+			Client:       resolved.Client,
+			Signer:       signer,
+			Actor:        evidence.Actor{Type: "mcp", ID: "evidra-mcp", Provenance: "outbox"},
+			Logger:       slog.New(slog.NewTextHandler(stderr, nil)),
 		}
+		syncCtx, stopSync := context.WithCancel(context.Background())
+		defer stopSync()
+		go syncer.Run(syncCtx, *syncIntervalFlag)
+		forwardFn = func(context.Context, json.RawMessage) { syncer.Notify() }
+	}
+
+	spanFn, closeSpans, err := resolveSpans(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
 	}
+	defer closeSpans()
 
 	server, cleanup, err := mcpserver.NewServerWithCleanup(mcpserver.Options{
 		Name:             "evidra-benchmark",
@@ -106,6 +134,9 @@ func run(args []string, stdout, stderr io.Writer) int {
 		BestEffortWrites: writeMode == config.EvidenceWriteModeBestEffort,
 		Signer:           signer,
 		Forward:          forwardFn,
+		ApprovalRisk:     approvalRisk,
+		Approvers:        approvers,
+		Spans:            spanFn,
 	})
 	if err != nil {
 		fmt.Fprintf(stderr, "initialize server: %v\n", err)
@@ -127,6 +158,38 @@ func run(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
+// resolveSpans builds the span hook from the EVIDRA_TRACES_* environment.
+// It returns a nil hook when tracing is disabled; the returned func closes
+// the exporter.
+func resolveSpans(stderr io.Writer) (mcpserver.SpanFunc, func(), error) {
+	tracingCfg, err := config.ResolveTracingConfig("", "")
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("resolve tracing: %w", err)
+	}
+	if !tracingCfg.Enabled() {
+		return nil, func() {}, nil
+	}
+	tracingCfg.ServiceName = "evidra-mcp"
+	exporter, err := telemetry.NewSpanExporter(tracingCfg)
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("initialize tracing: %w", err)
+	}
+	spanLogger := log.New(stderr, "", log.LstdFlags)
+	// ExportOperation only queues the span; the exporter sends batches in
+	// the background and flushes the rest on close.
+	spanFn := func(ctx context.Context, span mcpserver.OperationSpan) {
+		if err := exporter.ExportOperation(ctx, telemetry.OperationSpan(span)); err != nil {
+			spanLogger.Printf("warning: trace export failed: %v", err)
+		}
+	}
+	closeFn := func() {
+		if err := exporter.Close(); err != nil {
+			spanLogger.Printf("warning: trace export failed: %v", err)
+		}
+	}
+	return spanFn, closeFn, nil
+}
+
 func resolveEvidencePath(explicit string) string {
 	if explicit != "" {
 		return explicit
@@ -162,14 +225,26 @@ func envBool(key string, fallback bool) bool {
 	return fallback
 }
 
-// resolveSigner creates a Signer from environment variables.
-// Returns an error when mode is strict and no key is configured.
-func resolveSigner(modeRaw string) (evidence.Signer, error) {
+// resolveSigner creates a Signer from the backend flag and environment
+// variables. Returns an error when mode is strict and no key is configured.
+func resolveSigner(backendRaw, modeRaw string) (evidence.Signer, error) {
 	mode, err := config.ResolveSigningMode(modeRaw)
 	if err != nil {
 		return nil, err
 	}
 
+	backendCfg, err := config.ResolveSignerBackendConfig(backendRaw)
+	if err != nil {
+		return nil, err
+	}
+	if backendCfg.Backend != config.SignerBackendLocal {
+		s, err := ievsigner.NewBackendSigner(backendCfg)
+		if err != nil {
+			return nil, fmt.Errorf("resolveSigner: %w", err)
+		}
+		return s, nil
+	}
+
 	keyBase64 := strings.TrimSpace(os.Getenv("EVIDRA_SIGNING_KEY"))
 	keyPath := strings.TrimSpace(os.Getenv("EVIDRA_SIGNING_KEY_PATH"))
 
@@ -189,6 +264,24 @@ func resolveSigner(modeRaw string) (evidence.Signer, error) {
 	return s, nil
 }
 
+// resolveApprovals returns the approval risk threshold and the approvers'
+// public keys from flags, then env. The keyring is nil when none is set.
+func resolveApprovals(riskFlag, keyringFlag string) (string, *evidence.Keyring, error) {
+	risk, err := config.ResolveApprovalRisk(riskFlag)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolve approval risk: %w", err)
+	}
+	path := config.ResolveApproverKeyringPath(keyringFlag)
+	if path == "" {
+		return risk, nil, nil
+	}
+	approvers, err := ievsigner.LoadKeyring(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("load approver keyring: %w", err)
+	}
+	return risk, approvers, nil
+}
+
 func printHelp(w io.Writer) {
 	fmt.Fprintln(w, "evidra-mcp — MCP integration point for infrastructure automation reliability (including AI agents).")
 	fmt.Fprintln(w)
@@ -196,10 +289,14 @@ func printHelp(w io.Writer) {
 	fmt.Fprintln(w, "  evidra-mcp [flags]")
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "FLAGS:")
-	fmt.Fprintln(w, "  --evidence-dir <dir>    Where to store evidence chain (default: ~/.evidra/evidence)")
+	fmt.Fprintln(w, "  --evidence-dir <dir>    Evidence chain directory or sqlite:<path> (default: ~/.evidra/evidence)")
 	fmt.Fprintln(w, "  --environment <label>   Environment label (production, staging, development)")
 	fmt.Fprintln(w, "  --retry-tracker         Enable retry loop tracking")
 	fmt.Fprintln(w, "  --signing-mode <mode>   Signing mode: strict (default) or optional")
+	fmt.Fprintln(w, "  --signer-backend <name> Signer backend: local (default), pkcs11, agent, remote")
+	fmt.Fprintln(w, "  --require-approval <risk>  Hold prescriptions at or above risk for human approval (default: off)")
+	fmt.Fprintln(w, "  --approver-keyring <path>  Approvers' public keys (JWKS or PEM); only approvals they signed count")
+	fmt.Fprintln(w, "  --sync-interval <dur>   Background outbox sync interval when --url is set (default: 30s)")
 	fmt.Fprintln(w, "  --version               Print version and exit")
 	fmt.Fprintln(w, "  --help                  Show this help")
 	fmt.Fprintln(w)
@@ -209,6 +306,11 @@ func printHelp(w io.Writer) {
 	fmt.Fprintln(w, "  EVIDRA_RETRY_TRACKER    Enable retry tracking (true/false)")
 	fmt.Fprintln(w, "  EVIDRA_EVIDENCE_WRITE_MODE  strict (default) or best_effort")
 	fmt.Fprintln(w, "  EVIDRA_SIGNING_MODE     strict (default) or optional")
+	fmt.Fprintln(w, "  EVIDRA_SIGNER_BACKEND   local (default), pkcs11, agent, or remote")
+	fmt.Fprintln(w, "  EVIDRA_REQUIRE_APPROVAL Approval threshold (low, medium, high, critical, off)")
+	fmt.Fprintln(w, "  EVIDRA_APPROVER_KEYRING Approvers' public keys (JWKS or PEM)")
+	fmt.Fprintln(w, "  EVIDRA_TRACES_OTLP_ENDPOINT  OTLP/HTTP traces URL; exports one span per reported operation")
+	fmt.Fprintln(w, "  TRACEPARENT             W3C parent for spans when the tool call's _meta has none")
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "TOOLS:")
 	fmt.Fprintln(w, "  prescribe   Analyze artifact BEFORE execution (returns risk + prescription_id)")
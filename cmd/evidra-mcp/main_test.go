@@ -1,15 +1,19 @@
 package main
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http/httptest"
 	"testing"
 
 	"samebits.com/evidra/internal/config"
+	"samebits.com/evidra/internal/evidence/signertest"
 )
 
 func TestResolveSigner_OptionalWithoutKey(t *testing.T) {
 	t.Setenv("EVIDRA_SIGNING_KEY", "")
 	t.Setenv("EVIDRA_SIGNING_KEY_PATH", "")
-	s, err := resolveSigner("optional")
+	s, err := resolveSigner("", "optional")
 	if err != nil {
 		t.Fatalf("resolveSigner(optional): %v", err)
 	}
@@ -21,7 +25,7 @@ func TestResolveSigner_OptionalWithoutKey(t *testing.T) {
 func TestResolveSigner_StrictWithoutKeyFails(t *testing.T) {
 	t.Setenv("EVIDRA_SIGNING_KEY", "")
 	t.Setenv("EVIDRA_SIGNING_KEY_PATH", "")
-	if _, err := resolveSigner("strict"); err == nil {
+	if _, err := resolveSigner("", "strict"); err == nil {
 		t.Fatal("expected strict mode error when no key configured")
 	}
 }
@@ -29,7 +33,7 @@ func TestResolveSigner_StrictWithoutKeyFails(t *testing.T) {
 func TestResolveSigner_InvalidModeFails(t *testing.T) {
 	t.Setenv("EVIDRA_SIGNING_KEY", "")
 	t.Setenv("EVIDRA_SIGNING_KEY_PATH", "")
-	if _, err := resolveSigner("bad"); err == nil {
+	if _, err := resolveSigner("", "bad"); err == nil {
 		t.Fatal("expected invalid mode error")
 	}
 }
@@ -44,3 +48,24 @@ func TestResolveEvidenceWriteMode_FromEnv(t *testing.T) {
 		t.Fatalf("mode=%q, want %q", mode, config.EvidenceWriteModeBestEffort)
 	}
 }
+
+func TestResolveSigner_RemoteBackend(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	srv := httptest.NewServer(signertest.NewRemoteServer(priv, "tok"))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("EVIDRA_SIGNING_KEY", "")
+	t.Setenv("EVIDRA_SIGNING_KEY_PATH", "")
+	t.Setenv("EVIDRA_SIGNER_URL", srv.URL)
+	t.Setenv("EVIDRA_SIGNER_TOKEN", "tok")
+	s, err := resolveSigner("remote", "strict")
+	if err != nil {
+		t.Fatalf("resolveSigner(remote): %v", err)
+	}
+	if !s.PublicKey().Equal(priv.Public()) {
+		t.Fatal("remote signer public key mismatch")
+	}
+}
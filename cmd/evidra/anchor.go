@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"samebits.com/evidra/internal/anchor"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
+)
+
+type anchorFlags struct {
+	evidenceDir    *string
+	origin         *string
+	signingKey     *string
+	signingKeyPath *string
+	signingMode    *string
+}
+
+func bindAnchorFlags(fs *flag.FlagSet) anchorFlags {
+	return anchorFlags{
+		evidenceDir:    fs.String("evidence-dir", "", "Evidence directory"),
+		origin:         fs.String("origin", anchor.DefaultOrigin, "Checkpoint origin (log identity line)"),
+		signingKey:     fs.String("signing-key", "", "Base64-encoded Ed25519 signing key"),
+		signingKeyPath: fs.String("signing-key-path", "", "Path to PEM-encoded Ed25519 signing key"),
+		signingMode:    fs.String("signing-mode", "", "Signing mode: strict (default) or optional"),
+	}
+}
+
+func cmdAnchor(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: evidra anchor <head|export|publish> [flags]")
+		return 2
+	}
+
+	switch args[0] {
+	case "head":
+		return cmdAnchorHead(args[1:], stdout, stderr)
+	case "export":
+		return cmdAnchorExport(args[1:], stdout, stderr)
+	case "publish":
+		return cmdAnchorPublish(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown anchor subcommand: %s\n", args[0])
+		return 2
+	}
+}
+
+// cmdAnchorHead appends a signed tree_head entry covering the current chain.
+// Run it periodically (cron, CI) to create anchoring points.
+func cmdAnchorHead(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("anchor head", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts := bindAnchorFlags(fs)
+	actorFlag := fs.String("actor", "evidra", "Actor ID recorded on the head entry")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	signer, err := resolveSigner(*opts.signingKey, *opts.signingKeyPath, *opts.signingMode)
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve signer: %v\n", err)
+		return 1
+	}
+
+	evidencePath := resolveEvidencePath(*opts.evidenceDir)
+	sessionID := evidence.GenerateSessionID()
+	entry, err := evidence.AppendTreeHeadAtPath(evidencePath, evidence.EntryBuildParams{
+		SessionID:      sessionID,
+		TraceID:        sessionID,
+		Actor:          evidence.Actor{Type: "cli", ID: *actorFlag, Provenance: "cli"},
+		SpecVersion:    version.SpecVersion,
+		AdapterVersion: version.Version,
+		Signer:         signer,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "append tree head: %v\n", err)
+		return 1
+	}
+	head, err := evidence.TreeHeadFromEntry(entry)
+	if err != nil {
+		fmt.Fprintf(stderr, "read tree head: %v\n", err)
+		return 1
+	}
+
+	return writeJSON(stdout, stderr, "encode tree head", map[string]interface{}{
+		"ok":          true,
+		"entry_id":    entry.EntryID,
+		"entry_count": head.EntryCount,
+		"last_hash":   head.LastHash,
+		"timestamp":   head.Timestamp,
+	})
+}
+
+// cmdAnchorExport writes the latest tree head as a signed checkpoint or as an
+// RFC 3161 timestamp request over that checkpoint.
+func cmdAnchorExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("anchor export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts := bindAnchorFlags(fs)
+	formatFlag := fs.String("format", "checkpoint", "Export format: checkpoint or rfc3161")
+	outFlag := fs.String("out", "", "Output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *formatFlag != "checkpoint" && *formatFlag != "rfc3161" {
+		fmt.Fprintf(stderr, "invalid --format %q (want checkpoint or rfc3161)\n", *formatFlag)
+		return 2
+	}
+
+	note, _, code := signLatestCheckpoint(opts, stderr)
+	if code != 0 {
+		return code
+	}
+
+	out := note
+	if *formatFlag == "rfc3161" {
+		tsq, err := anchor.TimestampRequest(note, nil)
+		if err != nil {
+			fmt.Fprintf(stderr, "build timestamp request: %v\n", err)
+			return 1
+		}
+		out = tsq
+	}
+
+	if *outFlag == "" {
+		if _, err := stdout.Write(out); err != nil {
+			fmt.Fprintf(stderr, "write export: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if err := os.WriteFile(*outFlag, out, 0o644); err != nil {
+		fmt.Fprintf(stderr, "write export: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// cmdAnchorPublish submits the latest tree head to a transparency log and
+// records the receipt in the published heads file.
+func cmdAnchorPublish(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("anchor publish", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts := bindAnchorFlags(fs)
+	logURLFlag := fs.String("log-url", "", "Transparency log base URL")
+	headsFileFlag := fs.String("heads-file", "", "Published heads file (default: <evidence-dir>/"+anchor.PublishedHeadsFileName+")")
+	timeoutFlag := fs.Duration("timeout", 10*time.Second, "Request timeout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *logURLFlag == "" {
+		fmt.Fprintln(stderr, "anchor publish requires --log-url")
+		return 2
+	}
+
+	note, signer, code := signLatestCheckpoint(opts, stderr)
+	if code != 0 {
+		return code
+	}
+	cp, err := anchor.ParseCheckpoint(note)
+	if err != nil {
+		fmt.Fprintf(stderr, "parse checkpoint: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeoutFlag)
+	defer cancel()
+	receipt, err := anchor.NewLogClient(*logURLFlag, *timeoutFlag).Publish(ctx, note, signer.PublicKey())
+	if err != nil {
+		fmt.Fprintf(stderr, "publish checkpoint: %v\n", err)
+		return 1
+	}
+
+	rec := anchor.PublishedHead{
+		Origin:         cp.Origin,
+		EntryCount:     cp.Head.EntryCount,
+		LastHash:       cp.Head.LastHash,
+		Timestamp:      cp.Head.Timestamp,
+		Checkpoint:     string(note),
+		LogURL:         *logURLFlag,
+		LogID:          receipt.LogID,
+		LogIndex:       receipt.LogIndex,
+		UUID:           receipt.UUID,
+		IntegratedTime: receipt.IntegratedTime,
+	}
+	headsPath := *headsFileFlag
+	if headsPath == "" {
+		headsPath = anchor.DefaultPublishedHeadsPath(resolveEvidencePath(*opts.evidenceDir))
+	}
+	if err := anchor.AppendPublishedHead(headsPath, rec); err != nil {
+		fmt.Fprintf(stderr, "record published head: %v\n", err)
+		return 1
+	}
+
+	return writeJSON(stdout, stderr, "encode publish result", map[string]interface{}{
+		"ok":          true,
+		"entry_count": rec.EntryCount,
+		"last_hash":   rec.LastHash,
+		"log_index":   rec.LogIndex,
+		"uuid":        rec.UUID,
+		"heads_file":  headsPath,
+	})
+}
+
+func signLatestCheckpoint(opts anchorFlags, stderr io.Writer) ([]byte, evidence.Signer, int) {
+	signer, err := resolveSigner(*opts.signingKey, *opts.signingKeyPath, *opts.signingMode)
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve signer: %v\n", err)
+		return nil, nil, 1
+	}
+
+	evidencePath := resolveEvidencePath(*opts.evidenceDir)
+	entry, found, err := evidence.LatestTreeHeadAtPath(evidencePath)
+	if err != nil {
+		fmt.Fprintf(stderr, "read evidence: %v\n", err)
+		return nil, nil, 1
+	}
+	if !found {
+		fmt.Fprintln(stderr, "no tree head found: run 'evidra anchor head' first")
+		return nil, nil, 1
+	}
+	head, err := evidence.TreeHeadFromEntry(entry)
+	if err != nil {
+		fmt.Fprintf(stderr, "read tree head: %v\n", err)
+		return nil, nil, 1
+	}
+
+	note, err := anchor.SignCheckpoint(anchor.Checkpoint{Origin: *opts.origin, Head: head}, signer)
+	if err != nil {
+		fmt.Fprintf(stderr, "sign checkpoint: %v\n", err)
+		return nil, nil, 1
+	}
+	return note, signer, 0
+}
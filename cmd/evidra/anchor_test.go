@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/anchor"
+	"samebits.com/evidra/internal/anchor/anchortest"
+	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/internal/testutil"
+)
+
+func writeTestPublicKeyPEM(t *testing.T, dir, signingKey string) string {
+	t.Helper()
+	signer, err := ievsigner.NewSigner(ievsigner.SignerConfig{KeyBase64: signingKey})
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.PublicKey())
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	path := filepath.Join(dir, "pub.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+	return path
+}
+
+func recordTestOperation(t *testing.T, evidenceDir, signingKey string) {
+	t.Helper()
+	artifact := filepath.Join(t.TempDir(), "artifact.json")
+	if err := os.WriteFile(artifact, []byte(`{"noop":true}`), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	var out, errBuf bytes.Buffer
+	code := run([]string{
+		"prescribe",
+		"--tool", "terraform",
+		"--artifact", artifact,
+		"--canonical-action", testCanonicalAction,
+		"--evidence-dir", evidenceDir,
+		"--signing-key", signingKey,
+	}, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("prescribe exit %d: %s", code, errBuf.String())
+	}
+}
+
+func TestCmdAnchor_PublishAndValidate(t *testing.T) {
+	t.Parallel()
+	signingKey := testutil.TestSigningKeyBase64(t)
+	evidenceDir := t.TempDir()
+	pubKeyPath := writeTestPublicKeyPEM(t, t.TempDir(), signingKey)
+
+	srv := httptest.NewServer(anchortest.NewServer())
+	defer srv.Close()
+
+	recordTestOperation(t, evidenceDir, signingKey)
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"anchor", "head", "--evidence-dir", evidenceDir, "--signing-key", signingKey}, &out, &errBuf); code != 0 {
+		t.Fatalf("anchor head exit %d: %s", code, errBuf.String())
+	}
+	var head map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &head); err != nil {
+		t.Fatalf("decode head output: %v", err)
+	}
+	if head["entry_count"] != float64(1) {
+		t.Fatalf("entry_count = %v", head["entry_count"])
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"anchor", "publish", "--evidence-dir", evidenceDir, "--signing-key", signingKey, "--log-url", srv.URL}, &out, &errBuf); code != 0 {
+		t.Fatalf("anchor publish exit %d: %s", code, errBuf.String())
+	}
+	if _, err := os.Stat(anchor.DefaultPublishedHeadsPath(evidenceDir)); err != nil {
+		t.Fatalf("published heads file: %v", err)
+	}
+
+	// More evidence after publishing keeps the published head a valid prefix.
+	recordTestOperation(t, evidenceDir, signingKey)
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"validate", "--evidence-dir", evidenceDir, "--public-key", pubKeyPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("validate exit %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "consistent with 1 published head(s) confirmed by the transparency log") {
+		t.Fatalf("validate output = %q", out.String())
+	}
+
+	// Rebuilding the store with the same key yields a valid chain that no
+	// longer matches the published head.
+	if err := os.RemoveAll(filepath.Join(evidenceDir, "segments")); err != nil {
+		t.Fatalf("remove segments: %v", err)
+	}
+	if err := os.Remove(filepath.Join(evidenceDir, "manifest.json")); err != nil {
+		t.Fatalf("remove manifest: %v", err)
+	}
+	recordTestOperation(t, evidenceDir, signingKey)
+	recordTestOperation(t, evidenceDir, signingKey)
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"validate", "--evidence-dir", evidenceDir, "--public-key", pubKeyPath}, &out, &errBuf); code != 1 {
+		t.Fatalf("validate exit %d, want 1; stdout=%s", code, out.String())
+	}
+	if !strings.Contains(errBuf.String(), "published head consistency failed") {
+		t.Fatalf("stderr = %q", errBuf.String())
+	}
+}
+
+func TestCmdAnchorExport_Formats(t *testing.T) {
+	t.Parallel()
+	signingKey := testutil.TestSigningKeyBase64(t)
+	evidenceDir := t.TempDir()
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"anchor", "export", "--evidence-dir", evidenceDir, "--signing-key", signingKey}, &out, &errBuf); code != 1 {
+		t.Fatalf("export without head exit %d, want 1", code)
+	}
+	if !strings.Contains(errBuf.String(), "no tree head found") {
+		t.Fatalf("stderr = %q", errBuf.String())
+	}
+
+	recordTestOperation(t, evidenceDir, signingKey)
+	errBuf.Reset()
+	if code := run([]string{"anchor", "head", "--evidence-dir", evidenceDir, "--signing-key", signingKey}, &out, &errBuf); code != 0 {
+		t.Fatalf("anchor head exit %d: %s", code, errBuf.String())
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"anchor", "export", "--evidence-dir", evidenceDir, "--signing-key", signingKey}, &out, &errBuf); code != 0 {
+		t.Fatalf("export checkpoint exit %d: %s", code, errBuf.String())
+	}
+	cp, err := anchor.ParseCheckpoint(out.Bytes())
+	if err != nil {
+		t.Fatalf("ParseCheckpoint: %v", err)
+	}
+	if cp.Origin != anchor.DefaultOrigin || cp.Head.EntryCount != 1 {
+		t.Fatalf("checkpoint = %+v", cp)
+	}
+
+	tsqPath := filepath.Join(t.TempDir(), "head.tsq")
+	errBuf.Reset()
+	if code := run([]string{"anchor", "export", "--evidence-dir", evidenceDir, "--signing-key", signingKey, "--format", "rfc3161", "--out", tsqPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("export rfc3161 exit %d: %s", code, errBuf.String())
+	}
+	der, err := os.ReadFile(tsqPath)
+	if err != nil {
+		t.Fatalf("read tsq: %v", err)
+	}
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &raw); err != nil {
+		t.Fatalf("tsq is not DER: %v (%s)", err, base64.StdEncoding.EncodeToString(der))
+	}
+
+	errBuf.Reset()
+	if code := run([]string{"anchor", "export", "--evidence-dir", evidenceDir, "--format", "pdf"}, &out, &errBuf); code != 2 {
+		t.Fatalf("invalid format exit %d, want 2", code)
+	}
+}
+
+func TestCmdValidate_RegeneratedHeadsFileFails(t *testing.T) {
+	t.Parallel()
+	signingKey := testutil.TestSigningKeyBase64(t)
+	evidenceDir := t.TempDir()
+	pubKeyPath := writeTestPublicKeyPEM(t, t.TempDir(), signingKey)
+
+	srv := httptest.NewServer(anchortest.NewServer())
+	defer srv.Close()
+
+	recordTestOperation(t, evidenceDir, signingKey)
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"anchor", "head", "--evidence-dir", evidenceDir, "--signing-key", signingKey}, &out, &errBuf); code != 0 {
+		t.Fatalf("anchor head exit %d: %s", code, errBuf.String())
+	}
+	if code := run([]string{"anchor", "publish", "--evidence-dir", evidenceDir, "--signing-key", signingKey, "--log-url", srv.URL}, &out, &errBuf); code != 0 {
+		t.Fatalf("anchor publish exit %d: %s", code, errBuf.String())
+	}
+
+	// Rewrite the chain with the same key, then regenerate the heads file
+	// so it matches the rewritten chain while keeping the original receipt.
+	if err := os.RemoveAll(filepath.Join(evidenceDir, "segments")); err != nil {
+		t.Fatalf("remove segments: %v", err)
+	}
+	if err := os.Remove(filepath.Join(evidenceDir, "manifest.json")); err != nil {
+		t.Fatalf("remove manifest: %v", err)
+	}
+	recordTestOperation(t, evidenceDir, signingKey)
+	if code := run([]string{"anchor", "head", "--evidence-dir", evidenceDir, "--signing-key", signingKey}, &out, &errBuf); code != 0 {
+		t.Fatalf("anchor head exit %d: %s", code, errBuf.String())
+	}
+	out.Reset()
+	if code := run([]string{"anchor", "export", "--evidence-dir", evidenceDir, "--signing-key", signingKey}, &out, &errBuf); code != 0 {
+		t.Fatalf("anchor export exit %d: %s", code, errBuf.String())
+	}
+	forged, err := anchor.ParseCheckpoint(out.Bytes())
+	if err != nil {
+		t.Fatalf("ParseCheckpoint: %v", err)
+	}
+	headsPath := anchor.DefaultPublishedHeadsPath(evidenceDir)
+	records, err := anchor.ReadPublishedHeads(headsPath)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("ReadPublishedHeads = %v, %v", records, err)
+	}
+	rec := records[0]
+	rec.EntryCount, rec.LastHash, rec.Timestamp = forged.Head.EntryCount, forged.Head.LastHash, forged.Head.Timestamp
+	rec.Checkpoint = out.String()
+	if err := os.Remove(headsPath); err != nil {
+		t.Fatalf("remove heads file: %v", err)
+	}
+	if err := anchor.AppendPublishedHead(headsPath, rec); err != nil {
+		t.Fatalf("AppendPublishedHead: %v", err)
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"validate", "--evidence-dir", evidenceDir, "--public-key", pubKeyPath, "--offline"}, &out, &errBuf); code != 0 {
+		t.Fatalf("offline validate exit %d: %s", code, errBuf.String())
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"validate", "--evidence-dir", evidenceDir, "--public-key", pubKeyPath}, &out, &errBuf); code != 1 {
+		t.Fatalf("validate exit %d, want 1; stdout=%s", code, out.String())
+	}
+	if !strings.Contains(errBuf.String(), "transparency log verification failed") {
+		t.Fatalf("stderr = %q", errBuf.String())
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/pkg/client"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// cmdApprove records a human decision on a prescription that requires
+// approval. The approval entry is signed with the approver's key, which
+// must differ from the key that signed the prescription and, when an
+// approver keyring is configured, be one of its keys. With --remote the
+// prescription is fetched from the API and the signed decision submitted
+// there instead of the local evidence directory.
+func cmdApprove(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("approve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	prescriptionFlag := fs.String("prescription", "", "Prescription event ID to decide on")
+	rejectFlag := fs.Bool("reject", false, "Reject the operation instead of approving it")
+	reasonFlag := fs.String("reason", "", "Reason for the decision (e.g. change ticket)")
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence directory")
+	var actor actorFlags
+	bindActorFlags(fs, &actor, "Approver ID (required)")
+	signingKeyFlag := fs.String("signing-key", "", "Base64-encoded Ed25519 signing key of the approver")
+	signingKeyPathFlag := fs.String("signing-key-path", "", "Path to PEM-encoded Ed25519 signing key of the approver")
+	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	approverKeyringFlag := fs.String("approver-keyring", "", "Approvers' public keys (JWKS or PEM); the signing key must be one of them")
+	remoteFlag := fs.Bool("remote", false, "Decide on a prescription stored by the API server instead of the local evidence directory")
+	urlFlag := fs.String("url", os.Getenv("EVIDRA_URL"), "Evidra API URL (with --remote)")
+	apiKeyFlag := fs.String("api-key", os.Getenv("EVIDRA_API_KEY"), "Evidra API key (with --remote)")
+	timeoutFlag := fs.Duration("timeout", 30*time.Second, "API request timeout (with --remote)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*prescriptionFlag) == "" {
+		fmt.Fprintln(stderr, "approve requires --prescription")
+		return 2
+	}
+	if strings.TrimSpace(actor.ID) == "" {
+		fmt.Fprintln(stderr, "approve requires --actor")
+		return 2
+	}
+	if *remoteFlag && strings.TrimSpace(*urlFlag) == "" {
+		fmt.Fprintln(stderr, "approve --remote requires --url or EVIDRA_URL")
+		return 2
+	}
+
+	input := lifecycle.ApproveInput{
+		PrescriptionID: strings.TrimSpace(*prescriptionFlag),
+		Decision:       evidence.ApprovalApproved,
+		Reason:         *reasonFlag,
+		Actor:          buildActor(actor, "", "human", "cli"),
+	}
+	if *rejectFlag {
+		input.Decision = evidence.ApprovalRejected
+	}
+
+	ctx := context.Background()
+	var (
+		svc *lifecycle.Service
+		c   *client.Client
+	)
+	if *remoteFlag {
+		signer, err := resolveSigner(*signingKeyFlag, *signingKeyPathFlag, *signingModeFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "resolve signer: %v\n", err)
+			return 1
+		}
+		c = client.New(client.Config{URL: strings.TrimRight(*urlFlag, "/"), APIKey: *apiKeyFlag, Timeout: *timeoutFlag})
+		prescription, err := fetchPendingPrescription(ctx, c, input.PrescriptionID)
+		if err != nil {
+			fmt.Fprintf(stderr, "approve: %v\n", err)
+			return 1
+		}
+		input.Prescription = &prescription
+		svc = lifecycle.NewService(lifecycle.Options{Signer: signer})
+	} else {
+		var err error
+		svc, _, _, err = newLifecycleServiceForCommand(*evidenceFlag, *signingKeyFlag, *signingKeyPathFlag, *signingModeFlag, "", *approverKeyringFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+	}
+
+	out, err := svc.Approve(ctx, input)
+	if err != nil {
+		if lifecycle.ErrorCode(err) == lifecycle.ErrCodeNotFound {
+			fmt.Fprintf(stderr, "prescription %s not found in evidence\n", input.PrescriptionID)
+			return 1
+		}
+		fmt.Fprintf(stderr, "approve: %v\n", err)
+		return 1
+	}
+	if c != nil {
+		if _, err := c.SubmitApproval(ctx, out.PrescriptionID, out.RawEntry); err != nil {
+			fmt.Fprintf(stderr, "submit approval: %v\n", err)
+			return 1
+		}
+	}
+
+	return writeJSON(stdout, stderr, "encode approval", map[string]interface{}{
+		"ok":              true,
+		"approval_id":     out.ApprovalID,
+		"prescription_id": out.PrescriptionID,
+		"session_id":      out.SessionID,
+		"decision":        out.Decision,
+		"approver":        out.Actor.ID,
+	})
+}
+
+// fetchPendingPrescription returns the signed prescription entry of a
+// prescription the API lists as awaiting approval.
+func fetchPendingPrescription(ctx context.Context, c *client.Client, prescriptionID string) (evidence.EvidenceEntry, error) {
+	pending, err := c.PendingApprovals(ctx, "")
+	if err != nil {
+		return evidence.EvidenceEntry{}, fmt.Errorf("list pending approvals: %w", err)
+	}
+	for _, p := range pending {
+		if p.PrescriptionID != prescriptionID {
+			continue
+		}
+		var entry evidence.EvidenceEntry
+		if err := json.Unmarshal(p.Entry, &entry); err != nil {
+			return evidence.EvidenceEntry{}, fmt.Errorf("decode prescription %s: %w", prescriptionID, err)
+		}
+		return entry, nil
+	}
+	return evidence.EvidenceEntry{}, fmt.Errorf("prescription %s is not awaiting approval on the server", prescriptionID)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func writeApprovalArtifact(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "artifact.yaml")
+	if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: gated-cm\n  namespace: prod\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	return path
+}
+
+func TestRecordWaitsForApproval(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	marker := filepath.Join(tmp, "ran")
+	agentKey, approverKey := testutil.TestSigningKeyBase64(t), testutil.TestSigningKeyBase64(t)
+	approvers := writeTestPublicKeyPEM(t, tmp, approverKey)
+
+	type recordResult struct {
+		code   int
+		out    bytes.Buffer
+		stderr bytes.Buffer
+	}
+	done := make(chan *recordResult, 1)
+	go func() {
+		res := &recordResult{}
+		res.code = run([]string{
+			"record",
+			"--tool", "kubectl",
+			"--operation", "delete",
+			"--artifact", writeApprovalArtifact(t, tmp),
+			"--environment", "production",
+			"--evidence-dir", evidenceDir,
+			"--signing-key", agentKey,
+			"--require-approval", "high",
+			"--approver-keyring", approvers,
+			"--approval-wait", "30s",
+			"--", "sh", "-c", "touch " + marker,
+		}, &res.out, &res.stderr)
+		done <- res
+	}()
+
+	var prescriptionID string
+	deadline := time.Now().Add(10 * time.Second)
+	for prescriptionID == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("record did not prescribe an operation awaiting approval")
+		}
+		pending, _ := lifecycle.ListPendingApprovals(evidenceDir, "", time.Now(), nil)
+		if len(pending) == 1 {
+			prescriptionID = pending[0].PrescriptionID
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"pending", "--awaiting-approval", "--approver-keyring", approvers, "--evidence-dir", evidenceDir}, &out, &errBuf); code != 0 {
+		t.Fatalf("pending exit=%d stderr=%s", code, errBuf.String())
+	}
+	var pending struct {
+		Total int `json:"total"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &pending); err != nil || pending.Total != 1 {
+		t.Fatalf("pending --awaiting-approval = %s", out.String())
+	}
+
+	if code := run([]string{"approve", "--prescription", prescriptionID, "--evidence-dir", evidenceDir}, &out, &errBuf); code != 2 {
+		t.Fatalf("approve without --actor exit=%d, want 2", code)
+	}
+	errBuf.Reset()
+	if code := run([]string{"approve", "--prescription", prescriptionID, "--actor", "alice",
+		"--signing-key", agentKey, "--evidence-dir", evidenceDir}, &out, &errBuf); code != 1 {
+		t.Fatalf("self-approval exit=%d, want 1 (stderr=%s)", code, errBuf.String())
+	}
+	if code := run([]string{"approve", "--prescription", prescriptionID, "--actor", "alice",
+		"--signing-key", testutil.TestSigningKeyBase64(t), "--approver-keyring", approvers, "--evidence-dir", evidenceDir}, &out, &errBuf); code != 1 {
+		t.Fatalf("approval by a key outside the keyring exit=%d, want 1 (stderr=%s)", code, errBuf.String())
+	}
+	out.Reset()
+	if code := run([]string{"approve", "--prescription", prescriptionID, "--actor", "alice", "--reason", "CHG-1",
+		"--signing-key", approverKey, "--approver-keyring", approvers, "--evidence-dir", evidenceDir}, &out, &errBuf); code != 0 {
+		t.Fatalf("approve exit=%d stderr=%s", code, errBuf.String())
+	}
+	var approval map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &approval); err != nil {
+		t.Fatalf("decode approve output: %v", err)
+	}
+	if approval["decision"] != "approved" || approval["approver"] != "alice" || approval["prescription_id"] != prescriptionID {
+		t.Fatalf("approve result = %#v", approval)
+	}
+
+	var res *recordResult
+	select {
+	case res = <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("record did not finish after approval")
+	}
+	if res.code != 0 {
+		t.Fatalf("record exit=%d stderr=%s", res.code, res.stderr.String())
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("approved command did not run: %v", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(res.out.Bytes(), &result); err != nil {
+		t.Fatalf("decode record output: %v", err)
+	}
+	if result["approval_required"] != true || result["verdict"] != "success" {
+		t.Fatalf("record result = %#v", result)
+	}
+}
+
+func TestRecordWaitsForRemoteApproval(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu         sync.Mutex
+		forwarded  []string
+		decisionOf string
+		polls      int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/evidence/batch":
+			var batch struct {
+				Entries []evidence.EvidenceEntry `json:"entries"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&batch)
+			for _, e := range batch.Entries {
+				forwarded = append(forwarded, string(e.Type))
+			}
+			_, _ = fmt.Fprintf(w, `{"accepted":%d}`, len(batch.Entries))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/approvals/"):
+			decisionOf = strings.TrimPrefix(r.URL.Path, "/v1/approvals/")
+			polls++
+			decision := ""
+			if polls > 1 {
+				// An approver ran `evidra approve --remote` in the meantime.
+				decision = evidence.ApprovalApproved
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"prescription_id": decisionOf, "decision": decision})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tmp := t.TempDir()
+	marker := filepath.Join(tmp, "ran")
+	var out, errBuf bytes.Buffer
+	code := run([]string{
+		"record",
+		"--tool", "kubectl",
+		"--operation", "delete",
+		"--artifact", writeApprovalArtifact(t, tmp),
+		"--environment", "production",
+		"--evidence-dir", filepath.Join(tmp, "evidence"),
+		"--signing-key", testutil.TestSigningKeyBase64(t),
+		"--require-approval", "high",
+		"--approval-wait", "30s",
+		"--url", server.URL,
+		"--api-key", "test-key",
+		"--", "sh", "-c", "touch " + marker,
+	}, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("record exit=%d stderr=%s", code, errBuf.String())
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("remotely approved command did not run: %v", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode record output: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if decisionOf != result["prescription_id"] {
+		t.Fatalf("polled decision of %q, want %v", decisionOf, result["prescription_id"])
+	}
+	if len(forwarded) < 2 || forwarded[0] != string(evidence.EntryTypePrescribe) || forwarded[len(forwarded)-1] != string(evidence.EntryTypeReport) {
+		t.Fatalf("forwarded = %v, want the prescription before the wait and the report after", forwarded)
+	}
+}
+
+func TestRecordWithoutApprovalIsBlocked(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	marker := filepath.Join(tmp, "ran")
+	var out, errBuf bytes.Buffer
+	code := run([]string{
+		"record",
+		"--tool", "kubectl",
+		"--operation", "delete",
+		"--artifact", writeApprovalArtifact(t, tmp),
+		"--environment", "production",
+		"--evidence-dir", filepath.Join(tmp, "evidence"),
+		"--signing-key", testutil.TestSigningKeyBase64(t),
+		"--require-approval", "high",
+		"--", "sh", "-c", "touch " + marker,
+	}, &out, &errBuf)
+	if code != exitCodeGateBlocked {
+		t.Fatalf("record exit=%d want %d (stderr=%s)", code, exitCodeGateBlocked, errBuf.String())
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("wrapped command ran without approval: %v", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	gate, _ := result["gate"].(map[string]interface{})
+	if gate["rule"] != approvalGateRule || gate["confirmed"] != false || result["verdict"] != "declined" {
+		t.Fatalf("result = %#v", result)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"samebits.com/evidra/internal/lifecycle"
+)
+
+// cmdCancel withdraws an open prescription the agent decided not to run.
+func cmdCancel(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("cancel", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	prescriptionFlag := fs.String("prescription", "", "Prescription event ID to withdraw")
+	reasonFlag := fs.String("reason", "", "Short operational reason the operation will not run")
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence directory")
+	var actor actorFlags
+	bindActorFlags(fs, &actor, "Actor ID (defaults to the prescription's)")
+	sessionIDFlag := fs.String("session-id", "", "Session/run boundary ID")
+	operationIDFlag := fs.String("operation-id", "", "Operation identifier")
+	signingKeyFlag := fs.String("signing-key", "", "Base64-encoded Ed25519 signing key")
+	signingKeyPathFlag := fs.String("signing-key-path", "", "Path to PEM-encoded Ed25519 signing key")
+	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*prescriptionFlag) == "" {
+		fmt.Fprintln(stderr, "cancel requires --prescription")
+		return 2
+	}
+	if strings.TrimSpace(*reasonFlag) == "" {
+		fmt.Fprintln(stderr, "cancel requires --reason")
+		return 2
+	}
+
+	svc, _, _, err := newLifecycleServiceForCommand(*evidenceFlag, *signingKeyFlag, *signingKeyPathFlag, *signingModeFlag, "", "")
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	input := lifecycle.CancelInput{
+		PrescriptionID: *prescriptionFlag,
+		Reason:         *reasonFlag,
+		SessionID:      *sessionIDFlag,
+		OperationID:    *operationIDFlag,
+	}
+	if actor.ID != "" {
+		input.Actor = buildActor(actor, "cli", "cli", "cli")
+	}
+	out, err := svc.Cancel(context.Background(), input)
+	if err != nil {
+		if lifecycle.ErrorCode(err) == lifecycle.ErrCodeNotFound {
+			fmt.Fprintf(stderr, "prescription %s not found in evidence\n", *prescriptionFlag)
+			return 1
+		}
+		fmt.Fprintf(stderr, "cancel: %v\n", err)
+		return 1
+	}
+
+	return writeJSON(stdout, stderr, "encode cancel", map[string]interface{}{
+		"ok":              true,
+		"cancel_id":       out.CancelID,
+		"prescription_id": out.PrescriptionID,
+		"session_id":      out.SessionID,
+		"reason":          out.Reason,
+	})
+}
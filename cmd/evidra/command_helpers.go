@@ -14,16 +14,26 @@ import (
 	"samebits.com/evidra/internal/config"
 	ievsigner "samebits.com/evidra/internal/evidence"
 	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/outbox"
 	"samebits.com/evidra/internal/signal"
+	"samebits.com/evidra/pkg/client"
 	"samebits.com/evidra/pkg/evidence"
 	"samebits.com/evidra/pkg/mode"
 )
 
-func newLifecycleServiceForCommand(evidenceDir, signingKey, signingKeyPath, signingMode string) (*lifecycle.Service, string, evidence.Signer, error) {
+func newLifecycleServiceForCommand(evidenceDir, signingKey, signingKeyPath, signingMode, approvalRisk, approverKeyring string) (*lifecycle.Service, string, evidence.Signer, error) {
 	writeMode, err := config.ResolveEvidenceWriteMode("")
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("resolve evidence write mode: %w", err)
 	}
+	approvalRisk, err = config.ResolveApprovalRisk(approvalRisk)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("resolve approval risk: %w", err)
+	}
+	approvers, err := loadApproverKeyring(approverKeyring)
+	if err != nil {
+		return nil, "", nil, err
+	}
 
 	signer, err := resolveSigner(signingKey, signingKeyPath, signingMode)
 	if err != nil {
@@ -35,10 +45,26 @@ func newLifecycleServiceForCommand(evidenceDir, signingKey, signingKeyPath, sign
 		EvidencePath:     evidencePath,
 		Signer:           signer,
 		BestEffortWrites: writeMode == config.EvidenceWriteModeBestEffort,
+		ApprovalRisk:     approvalRisk,
+		Approvers:        approvers,
 	})
 	return svc, evidencePath, signer, nil
 }
 
+// loadApproverKeyring loads the approvers' public keys from the explicit
+// path or EVIDRA_APPROVER_KEYRING. It returns nil when neither is set.
+func loadApproverKeyring(explicit string) (*evidence.Keyring, error) {
+	path := config.ResolveApproverKeyringPath(explicit)
+	if path == "" {
+		return nil, nil
+	}
+	keyring, err := ievsigner.LoadKeyring(path)
+	if err != nil {
+		return nil, fmt.Errorf("load approver keyring: %w", err)
+	}
+	return keyring, nil
+}
+
 func parseCanonicalActionFlag(raw string) (*canon.CanonicalAction, error) {
 	if raw == "" {
 		return nil, nil
@@ -75,18 +101,32 @@ func parseStringMapFlag(raw, flagName string) (map[string]string, error) {
 	return values, nil
 }
 
-// resolveSigner creates a Signer from explicit flags or environment variables.
-// Returns an error when mode is strict and no key is configured.
+// resolveSigner creates a Signer from explicit flags, then environment
+// variables, then the default. An explicit --signing-key or
+// --signing-key-path always wins, so an approver can sign with their own key
+// on a host configured for an agent. Otherwise a non-local
+// EVIDRA_SIGNER_BACKEND takes precedence over local key material from the
+// environment. Returns an error when mode is strict and no key is configured.
 func resolveSigner(keyBase64, keyPath, modeRaw string) (evidence.Signer, error) {
 	mode, err := config.ResolveSigningMode(modeRaw)
 	if err != nil {
 		return nil, err
 	}
 
-	if keyBase64 == "" {
+	keyBase64, keyPath = strings.TrimSpace(keyBase64), strings.TrimSpace(keyPath)
+	if keyBase64 == "" && keyPath == "" {
+		backendCfg, err := config.ResolveSignerBackendConfig("")
+		if err != nil {
+			return nil, err
+		}
+		if backendCfg.Backend != config.SignerBackendLocal {
+			s, err := ievsigner.NewBackendSigner(backendCfg)
+			if err != nil {
+				return nil, fmt.Errorf("resolveSigner: %w", err)
+			}
+			return s, nil
+		}
 		keyBase64 = strings.TrimSpace(os.Getenv("EVIDRA_SIGNING_KEY"))
-	}
-	if keyPath == "" {
 		keyPath = strings.TrimSpace(os.Getenv("EVIDRA_SIGNING_KEY_PATH"))
 	}
 
@@ -120,6 +160,16 @@ func resolveEvidencePath(explicit string) string {
 	return filepath.Join(home, ".evidra", "evidence")
 }
 
+// queryEntries returns the entries matching the actor/period/session filters.
+// Indexed backends answer without reading the whole chain.
+func queryEntries(evidencePath, actor, period, sessionID string) ([]evidence.EvidenceEntry, error) {
+	return evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		ActorID:   actor,
+		SessionID: sessionID,
+		Since:     parsePeriodCutoff(period),
+	})
+}
+
 func filterEntries(entries []evidence.EvidenceEntry, actor, period, sessionID string) []evidence.EvidenceEntry {
 	cutoff := parsePeriodCutoff(period)
 	var filtered []evidence.EvidenceEntry
@@ -200,9 +250,11 @@ func writeJSON(stdout, stderr io.Writer, context string, payload interface{}) in
 	return 0
 }
 
-// forwardEvidence resolves the operating mode and, if online, best-effort
-// forwards session evidence entries to the Evidra API.
-func forwardEvidence(url, apiKey string, offline, fallbackOffline bool, timeout time.Duration, evidencePath, sessionID string, stderr io.Writer) {
+// forwardEvidence resolves the operating mode and, if online, makes one
+// attempt to push every entry the API has not acknowledged yet. Entries that
+// cannot be sent stay in the outbox for `evidra sync` or the next command.
+// It returns the API client when online, or nil.
+func forwardEvidence(url, apiKey string, offline, fallbackOffline bool, timeout time.Duration, evidencePath string, signer evidence.Signer, stderr io.Writer) *client.Client {
 	fallbackPolicy := ""
 	if fallbackOffline {
 		fallbackPolicy = "offline"
@@ -220,43 +272,22 @@ func forwardEvidence(url, apiKey string, offline, fallbackOffline bool, timeout
 	})
 	if err != nil {
 		fmt.Fprintf(stderr, "warning: mode resolve: %v\n", err)
-		return
+		return nil
 	}
 	if !resolved.IsOnline {
-		return
-	}
-
-	entries, err := evidence.ReadAllEntriesAtPath(evidencePath)
-	if err != nil {
-		fmt.Fprintf(stderr, "warning: read evidence for forwarding: %v\n", err)
-		return
-	}
-
-	var toForward []json.RawMessage
-	for _, entry := range entries {
-		if sessionID != "" && entry.SessionID != sessionID {
-			continue
-		}
-		raw, marshalErr := json.Marshal(entry)
-		if marshalErr != nil {
-			continue
-		}
-		toForward = append(toForward, raw)
-	}
-	if len(toForward) == 0 {
-		return
+		return nil
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	if len(toForward) == 1 {
-		if _, fwdErr := resolved.Client.Forward(ctx, toForward[0]); fwdErr != nil {
-			fmt.Fprintf(stderr, "warning: forward evidence: %v\n", fwdErr)
-		}
-	} else {
-		if _, fwdErr := resolved.Client.Batch(ctx, toForward); fwdErr != nil {
-			fmt.Fprintf(stderr, "warning: batch forward evidence: %v\n", fwdErr)
-		}
+	syncer := &outbox.Syncer{EvidencePath: evidencePath, Client: resolved.Client, Signer: signer}
+	res, err := syncer.SyncOnce(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: forward evidence: %v (%d entries queued; run 'evidra sync')\n", err, res.Pending)
+	}
+	for _, r := range res.Rejected {
+		fmt.Fprintf(stderr, "warning: API rejected entry %s: %s %s\n", r.EntryID, r.Code, r.Message)
 	}
+	return resolved.Client
 }
@@ -33,13 +33,24 @@ var orderedCommands = []commandSpec{
 	{name: "record", description: "Execute command live and record lifecycle outcome", run: cmdRecord},
 	{name: "prescribe", description: "Analyze artifact before execution", run: cmdPrescribe},
 	{name: "report", description: "Record execution outcome or declined decision", run: cmdReport},
+	{name: "cancel", description: "Withdraw an open prescription with a reason", run: cmdCancel},
+	{name: "pending", description: "List open prescriptions per session", run: cmdPending},
+	{name: "approve", description: "Approve or reject a prescription that requires sign-off", run: cmdApprove},
 	{name: "import", description: "Ingest completed automation operation from structured input", run: cmdImport},
 	{name: "validate", description: "Validate evidence chain integrity and signatures", run: cmdValidate},
+	{name: "anchor", description: "Write, export, and publish signed tree heads", run: cmdAnchor},
+	{name: "verify", description: "Read back live state and compare it with a prescription", run: cmdVerify},
+	{name: "transcript", description: "Print the captured output attached to a report", run: cmdTranscript},
+	{name: "entries", description: "List evidence entries matching filters", run: cmdEntries},
+	{name: "store", description: "Import or export evidence between JSONL and SQLite stores", run: cmdStore},
+	{name: "sync", description: "Push unacknowledged evidence to the Evidra API", run: cmdSync},
+	{name: "import-audit", description: "Flag Kubernetes audit log writes that no prescription covers", run: cmdImportAudit},
 	{name: "import-findings", description: "Ingest SARIF scanner findings as evidence entries", run: cmdImportFindings},
 	{name: "prompts", description: "Prompt contract generation and verification", run: cmdPrompts},
 	{name: "detectors", description: "Detector registry command group", run: cmdDetectors},
 	{name: "export", description: "Export anonymized evidence bundle for sharing", run: runExport},
 	{name: "keygen", description: "Generate Ed25519 signing keypair", run: cmdKeygen},
+	{name: "shim", description: "Install wrappers that record infra CLI calls made outside the protocol", run: cmdShim},
 	{name: "skill", description: "Install Evidra skill for AI agent protocol compliance", run: cmdSkill},
 	{name: "version", description: "Print version information", run: cmdVersion},
 }
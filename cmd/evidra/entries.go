@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+const (
+	defaultEntriesLimit = 100
+	maxEntriesLimit     = 1000
+)
+
+// cmdEntries lists local evidence entries in chain order with the same
+// filters as GET /v1/evidence/entries. Pages chain through --cursor.
+func cmdEntries(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("entries", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence store (directory or sqlite:<path>)")
+	typeFlag := fs.String("type", "", "Comma-separated entry types")
+	sessionFlag := fs.String("session-id", "", "Session ID filter")
+	actorFlag := fs.String("actor", "", "Actor ID filter")
+	toolFlag := fs.String("tool", "", "Tool filter")
+	operationClassFlag := fs.String("operation-class", "", "Operation-class filter")
+	scopeFlag := fs.String("scope", "", "Scope-class filter")
+	riskFlag := fs.String("effective-risk", "", "Comma-separated effective risk levels")
+	verdictFlag := fs.String("verdict", "", "Comma-separated report verdicts")
+	intentFlag := fs.String("intent-digest", "", "Intent digest filter")
+	artifactFlag := fs.String("artifact-digest", "", "Artifact digest filter")
+	var dimFlags multiStringFlag
+	fs.Var(&dimFlags, "scope-dimension", "Scope dimension key=value (repeatable; all must match)")
+	periodFlag := fs.String("period", "", "Time period filter (e.g. 7d, 24h)")
+	sinceFlag := fs.String("since", "", "Only entries at or after this RFC 3339 time")
+	untilFlag := fs.String("until", "", "Only entries before this RFC 3339 time")
+	limitFlag := fs.Int("limit", defaultEntriesLimit, fmt.Sprintf("Page size (max %d)", maxEntriesLimit))
+	cursorFlag := fs.String("cursor", "", "Resume from the next_cursor of a previous page")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *periodFlag != "" && *sinceFlag != "" {
+		fmt.Fprintln(stderr, "entries: --period and --since cannot be combined")
+		return 2
+	}
+	if *limitFlag < 1 || *limitFlag > maxEntriesLimit {
+		fmt.Fprintf(stderr, "entries: --limit must be between 1 and %d\n", maxEntriesLimit)
+		return 2
+	}
+
+	q := evidence.EntryQuery{
+		SessionID:      *sessionFlag,
+		ActorID:        *actorFlag,
+		IntentDigest:   *intentFlag,
+		ArtifactDigest: *artifactFlag,
+		Tool:           *toolFlag,
+		OperationClass: *operationClassFlag,
+		ScopeClass:     *scopeFlag,
+		EffectiveRisks: splitCommaList(strings.ToLower(*riskFlag)),
+		Verdicts:       splitCommaList(*verdictFlag),
+		Since:          parsePeriodCutoff(*periodFlag),
+		// One extra entry tells whether another page follows.
+		Limit: *limitFlag + 1,
+	}
+	for _, t := range splitCommaList(*typeFlag) {
+		q.Types = append(q.Types, evidence.EntryType(t))
+	}
+	for _, dim := range dimFlags {
+		key, value, ok := strings.Cut(dim, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			fmt.Fprintf(stderr, "entries: invalid --scope-dimension %q (expected key=value)\n", dim)
+			return 2
+		}
+		if q.ScopeDimensions == nil {
+			q.ScopeDimensions = map[string]string{}
+		}
+		q.ScopeDimensions[strings.TrimSpace(key)] = value
+	}
+	for _, bound := range []struct {
+		name string
+		raw  string
+		dst  *time.Time
+	}{{"since", *sinceFlag, &q.Since}, {"until", *untilFlag, &q.Until}} {
+		if bound.raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.raw)
+		if err != nil {
+			fmt.Fprintf(stderr, "entries: invalid --%s %q (expected RFC 3339 time)\n", bound.name, bound.raw)
+			return 2
+		}
+		*bound.dst = t
+	}
+	if *cursorFlag != "" {
+		after, err := decodeLocalEntryCursor(*cursorFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "entries: %v\n", err)
+			return 2
+		}
+		q.After = after
+	}
+
+	entries, err := evidence.QueryEntriesAtPath(resolveEvidencePath(*evidenceFlag), q)
+	if err != nil {
+		fmt.Fprintf(stderr, "entries: %v\n", err)
+		return 1
+	}
+	result := map[string]interface{}{"limit": *limitFlag}
+	if len(entries) > *limitFlag {
+		entries = entries[:*limitFlag]
+		result["next_cursor"] = encodeLocalEntryCursor(entries[len(entries)-1].EntryID)
+	}
+	result["entries"] = entries
+	return writeJSON(stdout, stderr, "encode entries", result)
+}
+
+// Local cursors wrap the ID of the last entry returned; they are not
+// interchangeable with API cursors.
+func encodeLocalEntryCursor(entryID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("entry:" + entryID))
+}
+
+func decodeLocalEntryCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	id, ok := strings.CutPrefix(string(raw), "entry:")
+	if err != nil || !ok || id == "" {
+		return "", fmt.Errorf("invalid --cursor %q", cursor)
+	}
+	return id, nil
+}
+
+func splitCommaList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+)
+
+func TestCmdEntries_FiltersAndPages(t *testing.T) {
+	t.Parallel()
+	signingKey := testutil.TestSigningKeyBase64(t)
+
+	for _, store := range []struct{ name, path string }{
+		{name: "jsonl", path: t.TempDir()},
+		{name: "sqlite", path: "sqlite://" + filepath.Join(t.TempDir(), "evidence.db")},
+	} {
+		t.Run(store.name, func(t *testing.T) {
+			t.Parallel()
+			for i := 0; i < 3; i++ {
+				recordTestOperation(t, store.path, signingKey)
+			}
+
+			type page struct {
+				Entries []struct {
+					EntryID string `json:"entry_id"`
+					Type    string `json:"type"`
+				} `json:"entries"`
+				NextCursor string `json:"next_cursor"`
+			}
+			list := func(t *testing.T, args ...string) page {
+				t.Helper()
+				var out, errBuf bytes.Buffer
+				args = append([]string{"entries", "--evidence-dir", store.path}, args...)
+				if code := run(args, &out, &errBuf); code != 0 {
+					t.Fatalf("entries exit %d: %s", code, errBuf.String())
+				}
+				var p page
+				if err := json.Unmarshal(out.Bytes(), &p); err != nil {
+					t.Fatalf("decode entries: %v", err)
+				}
+				return p
+			}
+
+			first := list(t, "--tool", "terraform", "--scope", "production", "--limit", "2")
+			if len(first.Entries) != 2 || first.NextCursor == "" {
+				t.Fatalf("first page = %+v", first)
+			}
+			second := list(t, "--tool", "terraform", "--scope", "production", "--limit", "2", "--cursor", first.NextCursor)
+			if len(second.Entries) != 1 || second.NextCursor != "" || second.Entries[0].EntryID == first.Entries[1].EntryID {
+				t.Fatalf("second page = %+v", second)
+			}
+
+			if got := list(t, "--tool", "kubectl"); len(got.Entries) != 0 {
+				t.Fatalf("kubectl entries = %d, want 0", len(got.Entries))
+			}
+			if got := list(t, "--type", "prescribe", "--until", "2000-01-01T00:00:00Z"); len(got.Entries) != 0 {
+				t.Fatalf("entries before 2000 = %d, want 0", len(got.Entries))
+			}
+		})
+	}
+}
+
+func TestCmdEntries_RejectsInvalidFlags(t *testing.T) {
+	t.Parallel()
+	for _, args := range [][]string{
+		{"--cursor", "bogus"},
+		{"--since", "yesterday"},
+		{"--scope-dimension", "cluster"},
+		{"--limit", "0"},
+		{"--period", "7d", "--since", "2026-01-01T00:00:00Z"},
+	} {
+		var out, errBuf bytes.Buffer
+		if code := run(append([]string{"entries", "--evidence-dir", t.TempDir()}, args...), &out, &errBuf); code != 2 {
+			t.Errorf("entries %v exit %d, want 2", args, code)
+		}
+	}
+}
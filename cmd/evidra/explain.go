@@ -7,10 +7,10 @@ import (
 	"io"
 	"time"
 
+	"samebits.com/evidra/internal/analytics"
 	"samebits.com/evidra/internal/pipeline"
 	"samebits.com/evidra/internal/score"
 	"samebits.com/evidra/internal/signal"
-	"samebits.com/evidra/pkg/evidence"
 	"samebits.com/evidra/pkg/version"
 )
 
@@ -43,13 +43,12 @@ func cmdExplain(args []string, stdout, stderr io.Writer) int {
 	}
 
 	evidencePath := resolveEvidencePath(*evidenceFlag)
-	entries, err := evidence.ReadAllEntriesAtPath(evidencePath)
+	filtered, err := queryEntries(evidencePath, *actorFlag, *periodFlag, *sessionIDFlag)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error reading evidence: %v\n", err)
 		return 1
 	}
 
-	filtered := filterEntries(entries, *actorFlag, *periodFlag, *sessionIDFlag)
 	signalEntries, err := pipeline.EvidenceToSignalEntries(filtered)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error converting evidence: %v\n", err)
@@ -83,31 +82,28 @@ func cmdExplain(args []string, stdout, stderr io.Writer) int {
 			Rate:     rate,
 			EntryIDs: result.EventIDs,
 		}
-		if result.Name == "protocol_violation" {
-			subMap := make(map[string]int)
-			pvEvents := signal.DetectProtocolViolationEvents(signalEntries, ttlDuration)
-			for _, ev := range pvEvents {
-				subMap[ev.SubSignal]++
-			}
-			detail.SubSignals = subMap
-		}
+		detail.SubSignals = signal.SubSignalCounts(result.Name, signalEntries, ttlDuration)
 		details = append(details, detail)
 	}
 
 	output := struct {
-		Score            float64        `json:"score"`
-		Band             string         `json:"band"`
-		TotalOps         int            `json:"total_operations"`
-		ScoringProfileID string         `json:"scoring_profile_id"`
-		Signals          []signalDetail `json:"signals"`
-		EvidraVersion    string         `json:"evidra_version"`
-		GeneratedAt      string         `json:"generated_at"`
+		Score            float64                  `json:"score"`
+		Band             string                   `json:"band"`
+		TotalOps         int                      `json:"total_operations"`
+		ScoringProfileID string                   `json:"scoring_profile_id"`
+		Signals          []signalDetail           `json:"signals"`
+		Rollbacks        *analytics.RollbackStats `json:"rollbacks,omitempty"`
+		Approvals        *analytics.ApprovalStats `json:"approvals,omitempty"`
+		EvidraVersion    string                   `json:"evidra_version"`
+		GeneratedAt      string                   `json:"generated_at"`
 	}{
 		Score:            sc.Score,
 		Band:             sc.Band,
 		TotalOps:         totalOps,
 		ScoringProfileID: sc.ScoringProfileID,
 		Signals:          details,
+		Rollbacks:        analytics.ComputeRollbackStats(signalEntries),
+		Approvals:        analytics.ComputeApprovalStats(signalEntries),
 		EvidraVersion:    version.Version,
 		GeneratedAt:      time.Now().UTC().Format(time.RFC3339),
 	}
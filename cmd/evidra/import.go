@@ -33,6 +33,7 @@ type importFlags struct {
 type importCommand struct {
 	service      *lifecycle.Service
 	evidencePath string
+	signer       evidence.Signer
 	input        automationevent.RecordInput
 }
 
@@ -133,7 +134,7 @@ func cmdImport(args []string, stdout, stderr io.Writer) int {
 	code = writeJSON(stdout, stderr, "encode import", result)
 
 	// Best-effort forward evidence to API if online.
-	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, opResult.ReportOutput.SessionID, stderr)
+	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, cmd.signer, stderr)
 
 	return code
 }
@@ -172,7 +173,7 @@ func parseImportFlags(args []string, stderr io.Writer) (importFlags, int) {
 }
 
 func prepareImportCommand(opts importFlags) (importCommand, error) {
-	svc, evidencePath, _, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode)
+	svc, evidencePath, signer, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode, "", "")
 	if err != nil {
 		return importCommand{}, err
 	}
@@ -193,6 +194,7 @@ func prepareImportCommand(opts importFlags) (importCommand, error) {
 	return importCommand{
 		service:      svc,
 		evidencePath: evidencePath,
+		signer:       signer,
 		input:        in,
 	}, nil
 }
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"samebits.com/evidra/internal/k8saudit"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
+)
+
+type importAuditFlags struct {
+	inputPath      string
+	evidenceDir    string
+	sessionID      string
+	cluster        string
+	window         time.Duration
+	skew           time.Duration
+	ignoreUsers    []string
+	dryRun         bool
+	signingKey     string
+	signingKeyPath string
+	signingMode    string
+}
+
+type unprescribedMutation struct {
+	AuditID       string    `json:"audit_id"`
+	User          string    `json:"user"`
+	Verb          string    `json:"verb"`
+	Kind          string    `json:"kind"`
+	Namespace     string    `json:"namespace,omitempty"`
+	Name          string    `json:"name,omitempty"`
+	ObservedAt    time.Time `json:"observed_at"`
+	SignalEntryID string    `json:"signal_entry_id,omitempty"`
+}
+
+// cmdImportAudit reconciles Kubernetes audit log writes against local
+// prescriptions and records a protocol_violation/unprescribed_observed
+// signal entry for each write no prescription covers. Re-importing the same
+// log is idempotent: audit IDs already recorded are skipped.
+func cmdImportAudit(args []string, stdout, stderr io.Writer) int {
+	opts, code := parseImportAuditFlags(args, stderr)
+	if code != 0 {
+		return code
+	}
+
+	events, err := readAuditEvents(opts.inputPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	mutations := k8saudit.Mutations(events, k8saudit.FilterOptions{IgnoreUsers: opts.ignoreUsers})
+
+	evidencePath := resolveEvidencePath(opts.evidenceDir)
+	prescriptions, recorded, err := loadAuditReconcileState(evidencePath)
+	if err != nil {
+		fmt.Fprintf(stderr, "read evidence: %v\n", err)
+		return 1
+	}
+	results := k8saudit.Reconcile(mutations, prescriptions, k8saudit.ReconcileOptions{
+		Window: opts.window,
+		Skew:   opts.skew,
+	})
+
+	var signer evidence.Signer
+	if !opts.dryRun {
+		signer, err = resolveSigner(opts.signingKey, opts.signingKeyPath, opts.signingMode)
+		if err != nil {
+			fmt.Fprintf(stderr, "resolve signer: %v\n", err)
+			return 1
+		}
+	}
+	sessionID := opts.sessionID
+	if sessionID == "" {
+		sessionID = evidence.GenerateSessionID()
+	}
+
+	matched, duplicates := 0, 0
+	unprescribed := make([]unprescribedMutation, 0)
+	for _, res := range results {
+		m := res.Mutation
+		if res.PrescriptionID != "" {
+			matched++
+			continue
+		}
+		if m.AuditID != "" && recorded[m.AuditID] {
+			duplicates++
+			continue
+		}
+		out := unprescribedMutation{
+			AuditID:    m.AuditID,
+			User:       m.User,
+			Verb:       m.Verb,
+			Kind:       m.Resource.Kind,
+			Namespace:  m.Resource.Namespace,
+			Name:       m.Resource.Name,
+			ObservedAt: m.Time,
+		}
+		if !opts.dryRun {
+			entry, err := appendObservedMutationSignal(evidencePath, signer, sessionID, opts.cluster, m)
+			if err != nil {
+				fmt.Fprintf(stderr, "write signal entry for audit %s: %v\n", m.AuditID, err)
+				return 1
+			}
+			out.SignalEntryID = entry.EntryID
+		}
+		unprescribed = append(unprescribed, out)
+	}
+
+	return writeJSON(stdout, stderr, "encode import-audit result", map[string]interface{}{
+		"ok":                 true,
+		"session_id":         sessionID,
+		"dry_run":            opts.dryRun,
+		"events_read":        len(events),
+		"mutations":          len(mutations),
+		"matched":            matched,
+		"already_recorded":   duplicates,
+		"unprescribed_count": len(unprescribed),
+		"unprescribed":       unprescribed,
+	})
+}
+
+func parseImportAuditFlags(args []string, stderr io.Writer) (importAuditFlags, int) {
+	fs := flag.NewFlagSet("import-audit", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	inputFlag := fs.String("input", "-", "Path to Kubernetes audit log JSONL ('-' for stdin)")
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence directory")
+	sessionIDFlag := fs.String("session-id", "", "Session/run boundary ID for written signal entries")
+	clusterFlag := fs.String("cluster", "", "Cluster name recorded as a scope dimension")
+	windowFlag := fs.Duration("window", 0, "Time after a prescription during which matching writes are covered (default: the prescription's TTL)")
+	skewFlag := fs.Duration("skew", 30*time.Second, "Clock skew tolerated between the API server and prescribing hosts")
+	var ignoreUsers multiStringFlag
+	fs.Var(&ignoreUsers, "ignore-user", "Skip writes by usernames with this prefix (repeatable; replaces the control-plane defaults)")
+	dryRunFlag := fs.Bool("dry-run", false, "Report unprescribed writes without writing signal entries")
+	signingKeyFlag := fs.String("signing-key", "", "Base64-encoded Ed25519 signing key")
+	signingKeyPathFlag := fs.String("signing-key-path", "", "Path to PEM-encoded Ed25519 signing key")
+	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	if err := fs.Parse(args); err != nil {
+		return importAuditFlags{}, 2
+	}
+	if *windowFlag < 0 || *skewFlag < 0 {
+		fmt.Fprintln(stderr, "import-audit: --window and --skew must not be negative")
+		return importAuditFlags{}, 2
+	}
+	if len(ignoreUsers) == 0 {
+		ignoreUsers = k8saudit.DefaultIgnoreUsers
+	}
+
+	return importAuditFlags{
+		inputPath:      *inputFlag,
+		evidenceDir:    *evidenceFlag,
+		sessionID:      *sessionIDFlag,
+		cluster:        *clusterFlag,
+		window:         *windowFlag,
+		skew:           *skewFlag,
+		ignoreUsers:    ignoreUsers,
+		dryRun:         *dryRunFlag,
+		signingKey:     *signingKeyFlag,
+		signingKeyPath: *signingKeyPathFlag,
+		signingMode:    *signingModeFlag,
+	}, 0
+}
+
+func readAuditEvents(inputPath string) ([]k8saudit.Event, error) {
+	if inputPath == "" || inputPath == "-" {
+		return k8saudit.ParseEvents(os.Stdin)
+	}
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	defer f.Close()
+	return k8saudit.ParseEvents(f)
+}
+
+// loadAuditReconcileState returns the store's prescriptions and the audit
+// IDs of observed mutations already recorded by earlier imports. A missing
+// store has neither.
+func loadAuditReconcileState(evidencePath string) ([]evidence.EvidenceEntry, map[string]bool, error) {
+	entries, err := evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		Types: []evidence.EntryType{evidence.EntryTypePrescribe, evidence.EntryTypeSignal},
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var prescriptions []evidence.EvidenceEntry
+	recorded := make(map[string]bool)
+	for _, e := range entries {
+		if e.Type == evidence.EntryTypePrescribe {
+			prescriptions = append(prescriptions, e)
+			continue
+		}
+		if e.Actor.Provenance != k8saudit.Provenance || e.OperationID == "" {
+			continue
+		}
+		var sp evidence.SignalPayload
+		if json.Unmarshal(e.Payload, &sp) == nil && sp.SubSignal == k8saudit.SubSignal {
+			recorded[e.OperationID] = true
+		}
+	}
+	return prescriptions, recorded, nil
+}
+
+func appendObservedMutationSignal(evidencePath string, signer evidence.Signer, sessionID, cluster string, m k8saudit.Mutation) (evidence.EvidenceEntry, error) {
+	payload, _ := json.Marshal(m.SignalPayload()) // best-effort: struct is always marshalable
+	return evidence.AppendAtPath(evidencePath, evidence.EntryBuildParams{
+		Type:            evidence.EntryTypeSignal,
+		SessionID:       sessionID,
+		OperationID:     m.AuditID,
+		TraceID:         evidence.GenerateTraceID(),
+		Actor:           m.Actor(),
+		Payload:         payload,
+		ScopeDimensions: m.ScopeDimensions(cluster),
+		SpecVersion:     version.SpecVersion,
+		AdapterVersion:  version.Version,
+		ScoringVersion:  version.ScoringVersion,
+		Signer:          signer,
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestImportAuditFlagsUnprescribedWritesOnce(t *testing.T) {
+	t.Parallel()
+
+	signingKey := testutil.TestSigningKeyBase64(t)
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	artifactPath := filepath.Join(tmp, "deploy.yaml")
+	if err := os.WriteFile(artifactPath, []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: prod\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	var out, errBuf bytes.Buffer
+	if code := run([]string{
+		"prescribe", "-f", artifactPath, "--tool", "kubectl", "--operation", "apply",
+		"--actor", "deployer", "--signing-key", signingKey, "--evidence-dir", evidenceDir,
+	}, &out, &errBuf); code != 0 {
+		t.Fatalf("prescribe exit=%d stderr=%s", code, errBuf.String())
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	auditPath := filepath.Join(tmp, "audit.jsonl")
+	log := fmt.Sprintf(`{"auditID":"a1","stage":"ResponseComplete","verb":"patch","user":{"username":"system:serviceaccount:agents:deployer"},"objectRef":{"resource":"deployments","namespace":"prod","name":"web","apiGroup":"apps","apiVersion":"v1"},"responseStatus":{"code":200},"stageTimestamp":%[1]q}
+{"auditID":"a2","stage":"ResponseComplete","verb":"delete","user":{"username":"system:serviceaccount:agents:deployer"},"objectRef":{"resource":"configmaps","namespace":"prod","name":"web-config","apiVersion":"v1"},"responseStatus":{"code":200},"stageTimestamp":%[1]q}
+`, now)
+	if err := os.WriteFile(auditPath, []byte(log), 0o644); err != nil {
+		t.Fatalf("write audit log: %v", err)
+	}
+
+	importAudit := func(t *testing.T) map[string]interface{} {
+		t.Helper()
+		var out, errBuf bytes.Buffer
+		if code := run([]string{
+			"import-audit", "--input", auditPath, "--evidence-dir", evidenceDir,
+			"--cluster", "prod-eu", "--signing-key", signingKey,
+		}, &out, &errBuf); code != 0 {
+			t.Fatalf("import-audit exit=%d stderr=%s", code, errBuf.String())
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("decode import-audit output: %v", err)
+		}
+		return result
+	}
+
+	first := importAudit(t)
+	if first["mutations"] != float64(2) || first["matched"] != float64(1) || first["unprescribed_count"] != float64(1) {
+		t.Fatalf("first import = %#v", first)
+	}
+	second := importAudit(t)
+	if second["unprescribed_count"] != float64(0) || second["already_recorded"] != float64(1) {
+		t.Fatalf("second import = %#v", second)
+	}
+
+	entries, err := evidence.ReadAllEntriesAtPath(evidenceDir)
+	if err != nil {
+		t.Fatalf("read entries: %v", err)
+	}
+	var signals []evidence.EvidenceEntry
+	for _, e := range entries {
+		if e.Type == evidence.EntryTypeSignal {
+			signals = append(signals, e)
+		}
+	}
+	if len(signals) != 1 || signals[0].OperationID != "a2" || signals[0].ScopeDimensions["cluster"] != "prod-eu" {
+		t.Fatalf("signal entries = %+v", signals)
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"explain", "--evidence-dir", evidenceDir, "--ttl", "1h"}, &out, &errBuf); code != 0 {
+		t.Fatalf("explain exit=%d stderr=%s", code, errBuf.String())
+	}
+	var explained struct {
+		Signals []struct {
+			Signal     string         `json:"signal"`
+			SubSignals map[string]int `json:"sub_signals"`
+		} `json:"signals"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &explained); err != nil {
+		t.Fatalf("decode explain: %v", err)
+	}
+	for _, s := range explained.Signals {
+		if s.Signal == "protocol_violation" && s.SubSignals["unprescribed_observed"] != 1 {
+			t.Fatalf("protocol_violation sub-signals = %v", s.SubSignals)
+		}
+	}
+}
+
+func TestImportAuditDryRunWritesNothing(t *testing.T) {
+	t.Parallel()
+
+	evidenceDir := filepath.Join(t.TempDir(), "evidence")
+	auditPath := filepath.Join("..", "..", "tests", "testdata", "k8saudit", "audit.jsonl")
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"import-audit", "--input", auditPath, "--evidence-dir", evidenceDir, "--dry-run"}, &out, &errBuf); code != 0 {
+		t.Fatalf("import-audit exit=%d stderr=%s", code, errBuf.String())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if result["unprescribed_count"] != float64(4) || result["events_read"] != float64(10) {
+		t.Fatalf("result = %#v", result)
+	}
+	if entries, err := evidence.ReadAllEntriesAtPath(evidenceDir); err == nil && len(entries) != 0 {
+		t.Fatalf("dry run wrote %d entries", len(entries))
+	}
+}
@@ -178,8 +178,7 @@ func appendFindingsAsEvidence(findings []evidence.FindingPayload, cfg findingApp
 	written := 0
 	for _, finding := range findings {
 		findingPayload, _ := json.Marshal(finding)
-		lastHash, _ := evidence.LastHashAtPath(cfg.evidencePath)
-		entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+		if _, err := evidence.AppendAtPath(cfg.evidencePath, evidence.EntryBuildParams{
 			Type:           evidence.EntryTypeFinding,
 			SessionID:      cfg.sessionID,
 			OperationID:    cfg.operationID,
@@ -188,17 +187,11 @@ func appendFindingsAsEvidence(findings []evidence.FindingPayload, cfg findingApp
 			Actor:          cfg.actor,
 			ArtifactDigest: cfg.artifactDigest,
 			Payload:        findingPayload,
-			PreviousHash:   lastHash,
 			SpecVersion:    version.SpecVersion,
 			AdapterVersion: version.Version,
 			ScoringVersion: version.ScoringVersion,
 			Signer:         cfg.signer,
-		})
-		if err != nil {
-			fmt.Fprintf(stderr, "warning: build finding entry failed for rule %s: %v\n", finding.RuleID, err)
-			continue
-		}
-		if err := evidence.AppendEntryAtPath(cfg.evidencePath, entry); err != nil {
+		}); err != nil {
 			fmt.Fprintf(stderr, "warning: write finding entry failed for rule %s: %v\n", finding.RuleID, err)
 			continue
 		}
@@ -6,20 +6,60 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
+	"flag"
 	"fmt"
 	"io"
+
+	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
 )
 
-func cmdKeygen(_ []string, stdout, stderr io.Writer) int {
-	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+func cmdKeygen(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	rotateFlag := fs.Bool("rotate", false, "Append a key_rotation entry signed by the current key that introduces the new key")
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence directory (with --rotate)")
+	signingKeyFlag := fs.String("signing-key", "", "Current base64-encoded Ed25519 signing key (with --rotate)")
+	signingKeyPathFlag := fs.String("signing-key-path", "", "Current PEM-encoded Ed25519 signing key path (with --rotate)")
+	newSigningKeyFlag := fs.String("new-signing-key", "", "Rotate to this base64-encoded key instead of generating one (with --rotate)")
+	reasonFlag := fs.String("reason", "", "Rotation reason recorded in the entry (with --rotate)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *newSigningKeyFlag != "" && !*rotateFlag {
+		fmt.Fprintln(stderr, "--new-signing-key requires --rotate")
+		return 2
+	}
+
+	keyBase64 := *newSigningKeyFlag
+	if keyBase64 == "" {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			fmt.Fprintf(stderr, "generate key: %v\n", err)
+			return 1
+		}
+		// Private key seed as base64 (32 bytes)
+		keyBase64 = base64.StdEncoding.EncodeToString(priv.Seed())
+	}
+	newSigner, err := ievsigner.NewSigner(ievsigner.SignerConfig{KeyBase64: keyBase64})
 	if err != nil {
-		fmt.Fprintf(stderr, "generate key: %v\n", err)
+		fmt.Fprintf(stderr, "load new signing key: %v\n", err)
 		return 1
 	}
+	pub := newSigner.PublicKey()
+
+	var rotationEntryID string
+	if *rotateFlag {
+		entryID, code := appendKeyRotation(*evidenceFlag, *signingKeyFlag, *signingKeyPathFlag, newSigner, *reasonFlag, stderr)
+		if code != 0 {
+			return code
+		}
+		rotationEntryID = entryID
+	}
 
-	// Output private key seed as base64 (32 bytes)
-	seed := priv.Seed()
-	fmt.Fprintf(stdout, "EVIDRA_SIGNING_KEY=%s\n\n", base64.StdEncoding.EncodeToString(seed))
+	fmt.Fprintf(stdout, "EVIDRA_SIGNING_KEY=%s\n\n", keyBase64)
 
 	// Output public key as PEM
 	der, err := x509.MarshalPKIXPublicKey(pub)
@@ -32,6 +72,43 @@ func cmdKeygen(_ []string, stdout, stderr io.Writer) int {
 		Bytes: der,
 	})
 	fmt.Fprintf(stdout, "%s", pemBlock)
+	fmt.Fprintf(stdout, "\n# key_id: %s\n", evidence.KeyID(pub))
+	if rotationEntryID != "" {
+		fmt.Fprintf(stdout, "# rotation_entry_id: %s\n", rotationEntryID)
+	}
 
 	return 0
 }
+
+// appendKeyRotation writes a key_rotation entry, signed by the current key,
+// that hands over to newSigner.
+func appendKeyRotation(evidenceDir, signingKey, signingKeyPath string, newSigner evidence.Signer, reason string, stderr io.Writer) (string, int) {
+	oldSigner, err := resolveSigner(signingKey, signingKeyPath, "strict")
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve current signer: %v\n", err)
+		return "", 1
+	}
+	payload, err := evidence.BuildKeyRotationPayload(oldSigner, newSigner, reason)
+	if err != nil {
+		fmt.Fprintf(stderr, "build key rotation: %v\n", err)
+		return "", 1
+	}
+
+	evidencePath := resolveEvidencePath(evidenceDir)
+	sessionID := evidence.GenerateSessionID()
+	entry, err := evidence.AppendAtPath(evidencePath, evidence.EntryBuildParams{
+		Type:           evidence.EntryTypeKeyRotation,
+		SessionID:      sessionID,
+		TraceID:        sessionID,
+		Actor:          evidence.Actor{Type: "cli", ID: "evidra", Provenance: "cli"},
+		Payload:        payload,
+		SpecVersion:    version.SpecVersion,
+		AdapterVersion: version.Version,
+		Signer:         oldSigner,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "write key rotation entry: %v\n", err)
+		return "", 1
+	}
+	return entry.EntryID, 0
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/base64"
 	"strings"
 	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
 )
 
 func TestCmdKeygen_OutputsKeyPair(t *testing.T) {
@@ -37,3 +40,70 @@ func TestCmdKeygen_OutputsKeyPair(t *testing.T) {
 	}
 	t.Error("EVIDRA_SIGNING_KEY line not found")
 }
+
+func TestCmdKeygen_RotateAppendsRotationEntry(t *testing.T) {
+	t.Parallel()
+	oldKey := testutil.TestSigningKeyBase64(t)
+	evidenceDir := t.TempDir()
+	oldPubPath := writeTestPublicKeyPEM(t, t.TempDir(), oldKey)
+
+	recordTestOperation(t, evidenceDir, oldKey)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"keygen", "--rotate", "--evidence-dir", evidenceDir, "--signing-key", oldKey, "--reason", "scheduled"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("keygen --rotate exit %d: %s", code, stderr.String())
+	}
+	var newKey string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if strings.HasPrefix(line, "EVIDRA_SIGNING_KEY=") {
+			newKey = strings.TrimPrefix(line, "EVIDRA_SIGNING_KEY=")
+		}
+	}
+	if newKey == "" || newKey == oldKey {
+		t.Fatalf("expected a new signing key in output: %s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "# rotation_entry_id: ") {
+		t.Fatalf("expected rotation entry id in output: %s", stdout.String())
+	}
+
+	recordTestOperation(t, evidenceDir, newKey)
+
+	entries, err := evidence.ReadAllEntriesAtPath(evidenceDir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	var rotations int
+	for _, e := range entries {
+		if e.Type == evidence.EntryTypeKeyRotation {
+			rotations++
+		}
+	}
+	if rotations != 1 {
+		t.Fatalf("expected 1 key_rotation entry, got %d", rotations)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"validate", "--evidence-dir", evidenceDir, "--public-key", oldPubPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("validate with original key exit %d: %s", code, stderr.String())
+	}
+
+	newPubPath := writeTestPublicKeyPEM(t, t.TempDir(), newKey)
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"validate", "--evidence-dir", evidenceDir, "--keyring", newPubPath}, &stdout, &stderr); code != 1 {
+		t.Fatalf("validate with only the new key exit %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown key_id") {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+}
+
+func TestCmdKeygen_NewSigningKeyRequiresRotate(t *testing.T) {
+	t.Parallel()
+	var stdout, stderr bytes.Buffer
+	if code := cmdKeygen([]string{"--new-signing-key", testutil.TestSigningKeyBase64(t)}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 
+	_ "samebits.com/evidra/pkg/evidence/sqlitestore" // sqlite: evidence store URIs
 	"samebits.com/evidra/pkg/version"
 )
 
@@ -5,6 +5,7 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
 	"os"
@@ -13,6 +14,8 @@ import (
 	"testing"
 	"time"
 
+	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/internal/evidence/signertest"
 	"samebits.com/evidra/internal/testutil"
 	"samebits.com/evidra/pkg/evidence"
 	"samebits.com/evidra/pkg/version"
@@ -678,6 +681,69 @@ func TestResolveSigner_StrictWithoutKeyFails(t *testing.T) {
 	}
 }
 
+func TestResolveSigner_ExplicitKeyOverridesBackendEnv(t *testing.T) {
+	t.Setenv("EVIDRA_SIGNER_BACKEND", "agent")
+	t.Setenv("EVIDRA_SIGNER_AGENT_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	signingKey := testutil.TestSigningKeyBase64(t)
+	want, err := ievsigner.NewSigner(ievsigner.SignerConfig{KeyBase64: signingKey})
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+
+	s, err := resolveSigner(signingKey, "", "strict")
+	if err != nil {
+		t.Fatalf("resolveSigner: %v", err)
+	}
+	if !s.PublicKey().Equal(want.PublicKey()) {
+		t.Fatal("resolveSigner used EVIDRA_SIGNER_BACKEND instead of --signing-key")
+	}
+	if _, err := resolveSigner("", "", "strict"); err == nil {
+		t.Fatal("expected the agent backend from the environment without an explicit key")
+	}
+}
+
+func TestRunPrescribe_AgentSignerBackend(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	socket := signertest.StartAgent(t, priv, "evidra-ci")
+	t.Setenv("EVIDRA_SIGNING_KEY", "")
+	t.Setenv("EVIDRA_SIGNING_KEY_PATH", "")
+	t.Setenv("EVIDRA_SIGNER_BACKEND", "agent")
+	t.Setenv("EVIDRA_SIGNER_AGENT_SOCKET", socket)
+	t.Setenv("EVIDRA_SIGNER_AGENT_KEY", "evidra-ci")
+
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	artifact := filepath.Join(tmp, "artifact.json")
+	if err := os.WriteFile(artifact, []byte(`{"noop":true}`), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+
+	var out, errBuf bytes.Buffer
+	code := run([]string{
+		"prescribe",
+		"--tool", "terraform",
+		"--artifact", artifact,
+		"--canonical-action", testCanonicalAction,
+		"--evidence-dir", evidenceDir,
+	}, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("prescribe exit %d: %s", code, errBuf.String())
+	}
+
+	pubKeyPath := writeTestPublicKeyPEM(t, tmp, base64.StdEncoding.EncodeToString(priv.Seed()))
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"validate", "--evidence-dir", evidenceDir, "--public-key", pubKeyPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("validate exit %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "signatures verified") {
+		t.Fatalf("validate output = %q", out.String())
+	}
+}
+
 func TestRunPrescribe_OptionalSigningModeWithoutKey(t *testing.T) {
 	t.Setenv("EVIDRA_SIGNING_KEY", "")
 	t.Setenv("EVIDRA_SIGNING_KEY_PATH", "")
@@ -48,7 +48,7 @@ func TestRunPrescribe_ForwardsEvidenceOnline(t *testing.T) {
 		mu.Unlock()
 
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"receipt_id":"r1","status":"accepted"}`))
+		_, _ = w.Write([]byte(`{"accepted":1}`))
 	}))
 	defer server.Close()
 
@@ -71,14 +71,23 @@ func TestRunPrescribe_ForwardsEvidenceOnline(t *testing.T) {
 	mu.Lock()
 	defer mu.Unlock()
 	if len(requestPaths) != 1 {
-		t.Fatalf("forward request count = %d, want 1", len(requestPaths))
+		t.Fatalf("batch request count = %d, want 1", len(requestPaths))
 	}
-	if requestPaths[0] != "/v1/evidence/forward" {
-		t.Fatalf("forward path = %q, want /v1/evidence/forward", requestPaths[0])
+	if requestPaths[0] != "/v1/evidence/batch" {
+		t.Fatalf("batch path = %q, want /v1/evidence/batch", requestPaths[0])
 	}
 
+	var batch struct {
+		Entries []json.RawMessage `json:"entries"`
+	}
+	if err := json.Unmarshal(rawBodies[0], &batch); err != nil {
+		t.Fatalf("decode batch request: %v", err)
+	}
+	if len(batch.Entries) != 1 {
+		t.Fatalf("forwarded entries = %d, want 1", len(batch.Entries))
+	}
 	var entry evidence.EvidenceEntry
-	if err := json.Unmarshal(rawBodies[0], &entry); err != nil {
+	if err := json.Unmarshal(batch.Entries[0], &entry); err != nil {
 		t.Fatalf("decode forwarded entry: %v", err)
 	}
 	if entry.Type != evidence.EntryTypePrescribe {
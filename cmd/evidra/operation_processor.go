@@ -21,6 +21,7 @@ type OperationRequest struct {
 	ArtifactDigest string
 	ReportActor    evidence.Actor
 	ExternalRefs   []evidence.ExternalRef
+	Transcript     *evidence.TranscriptRef
 	SessionID      string
 	OperationID    string
 	SpanID         string
@@ -47,29 +48,46 @@ func (p *OperationProcessor) Process(ctx context.Context, req OperationRequest)
 	if err != nil {
 		return OperationResult{}, err
 	}
+	return p.Complete(ctx, req, prescOut)
+}
 
-	reportActor := req.ReportActor
-	if reportActor == (evidence.Actor{}) {
-		reportActor = req.PrescribeInput.Actor
-	}
-
+// Complete reports req.ExitCode against an operation that was already
+// prescribed, for adapters that act between the two steps.
+func (p *OperationProcessor) Complete(ctx context.Context, req OperationRequest, prescOut lifecycle.PrescribeOutput) (OperationResult, error) {
 	artifactDigest := strings.TrimSpace(req.ArtifactDigest)
 	if artifactDigest == "" {
 		artifactDigest = prescOut.ArtifactDigest
 	}
-
-	reportOut, err := p.service.Report(ctx, lifecycle.ReportInput{
-		PrescriptionID: prescOut.PrescriptionID,
+	return p.report(ctx, req, prescOut, lifecycle.ReportInput{
 		Verdict:        evidence.VerdictFromExitCode(req.ExitCode),
 		ExitCode:       intPtr(req.ExitCode),
 		ArtifactDigest: artifactDigest,
-		Actor:          reportActor,
 		ExternalRefs:   req.ExternalRefs,
-		SessionID:      req.SessionID,
-		OperationID:    req.OperationID,
-		SpanID:         req.SpanID,
-		ParentSpanID:   req.ParentSpanID,
+		Transcript:     req.Transcript,
 	})
+}
+
+// Decline reports that a prescribed operation was intentionally not run.
+func (p *OperationProcessor) Decline(ctx context.Context, req OperationRequest, prescOut lifecycle.PrescribeOutput, decision evidence.DecisionContext) (OperationResult, error) {
+	return p.report(ctx, req, prescOut, lifecycle.ReportInput{
+		Verdict:         evidence.VerdictDeclined,
+		DecisionContext: &decision,
+		ExternalRefs:    req.ExternalRefs,
+	})
+}
+
+func (p *OperationProcessor) report(ctx context.Context, req OperationRequest, prescOut lifecycle.PrescribeOutput, in lifecycle.ReportInput) (OperationResult, error) {
+	in.Actor = req.ReportActor
+	if in.Actor == (evidence.Actor{}) {
+		in.Actor = req.PrescribeInput.Actor
+	}
+	in.PrescriptionID = prescOut.PrescriptionID
+	in.SessionID = req.SessionID
+	in.OperationID = req.OperationID
+	in.SpanID = req.SpanID
+	in.ParentSpanID = req.ParentSpanID
+
+	reportOut, err := p.service.Report(ctx, in)
 	if err != nil {
 		return OperationResult{}, err
 	}
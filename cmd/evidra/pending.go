@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+type pendingSession struct {
+	SessionID string                          `json:"session_id"`
+	Pending   []lifecycle.PendingPrescription `json:"pending"`
+}
+
+// cmdPending lists prescriptions that have neither a report nor a
+// cancellation, grouped by session. With --awaiting-approval it lists only
+// those still waiting for an approver's decision.
+func cmdPending(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("pending", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence store (directory or sqlite:<path>)")
+	sessionFlag := fs.String("session-id", "", "Session ID filter")
+	actorFlag := fs.String("actor", "", "Actor ID filter")
+	expiredFlag := fs.Bool("expired", false, "Only list prescriptions past their TTL")
+	awaitingApprovalFlag := fs.Bool("awaiting-approval", false, "Only list prescriptions waiting for an approval decision")
+	approverKeyringFlag := fs.String("approver-keyring", "", "Approvers' public keys (JWKS or PEM); only decisions they signed count")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	evidencePath := resolveEvidencePath(*evidenceFlag)
+	now := time.Now().UTC()
+	var (
+		pending []lifecycle.PendingPrescription
+		err     error
+	)
+	if *awaitingApprovalFlag {
+		var approvers *evidence.Keyring
+		approvers, err = loadApproverKeyring(*approverKeyringFlag)
+		if err == nil {
+			pending, err = lifecycle.ListPendingApprovals(evidencePath, *sessionFlag, now, approvers)
+		}
+	} else {
+		pending, err = lifecycle.ListPending(evidencePath, *sessionFlag, *actorFlag, now)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "pending: %v\n", err)
+		return 1
+	}
+
+	sessions := []pendingSession{}
+	index := make(map[string]int)
+	total, expired := 0, 0
+	for _, p := range pending {
+		if (*expiredFlag && !p.Expired) || (*actorFlag != "" && p.ActorID != *actorFlag) {
+			continue
+		}
+		i, ok := index[p.SessionID]
+		if !ok {
+			i = len(sessions)
+			index[p.SessionID] = i
+			sessions = append(sessions, pendingSession{SessionID: p.SessionID})
+		}
+		sessions[i].Pending = append(sessions[i].Pending, p)
+		total++
+		if p.Expired {
+			expired++
+		}
+	}
+
+	return writeJSON(stdout, stderr, "encode pending", map[string]interface{}{
+		"total":    total,
+		"expired":  expired,
+		"sessions": sessions,
+	})
+}
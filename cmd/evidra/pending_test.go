@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+)
+
+func TestCancelClosesPrescriptionAndPendingListsTheRest(t *testing.T) {
+	t.Parallel()
+
+	signingKey := testutil.TestSigningKeyBase64(t)
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	artifactPath := filepath.Join(tmp, "artifact.yaml")
+	if err := os.WriteFile(artifactPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: prod\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	runJSON := func(args ...string) map[string]interface{} {
+		t.Helper()
+		var out, errBuf bytes.Buffer
+		if code := run(args, &out, &errBuf); code != 0 {
+			t.Fatalf("%v exit=%d stderr=%s", args, code, errBuf.String())
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("decode %s output: %v", args[0], err)
+		}
+		return result
+	}
+	prescribe := func() string {
+		t.Helper()
+		result := runJSON("prescribe", "-f", artifactPath, "--tool", "kubectl",
+			"--session-id", "sess-pending", "--signing-key", signingKey, "--evidence-dir", evidenceDir)
+		id, _ := result["prescription_id"].(string)
+		return id
+	}
+	withdrawn, open := prescribe(), prescribe()
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"cancel", "--prescription", withdrawn, "--evidence-dir", evidenceDir}, &out, &errBuf); code != 2 {
+		t.Fatalf("cancel without --reason exit=%d, want 2", code)
+	}
+	cancelled := runJSON("cancel", "--prescription", withdrawn, "--reason", "wrong namespace",
+		"--signing-key", signingKey, "--evidence-dir", evidenceDir)
+	if cancelled["prescription_id"] != withdrawn || cancelled["session_id"] != "sess-pending" || cancelled["cancel_id"] == "" {
+		t.Fatalf("cancel result = %#v", cancelled)
+	}
+
+	out.Reset()
+	if code := run([]string{"pending", "--evidence-dir", evidenceDir}, &out, &errBuf); code != 0 {
+		t.Fatalf("pending exit=%d stderr=%s", code, errBuf.String())
+	}
+	var pending struct {
+		Total    int `json:"total"`
+		Sessions []struct {
+			SessionID string `json:"session_id"`
+			Pending   []struct {
+				PrescriptionID string `json:"prescription_id"`
+				Tool           string `json:"tool"`
+				Expired        bool   `json:"expired"`
+			} `json:"pending"`
+		} `json:"sessions"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &pending); err != nil {
+		t.Fatalf("decode pending output: %v", err)
+	}
+	if pending.Total != 1 || len(pending.Sessions) != 1 || pending.Sessions[0].SessionID != "sess-pending" {
+		t.Fatalf("pending = %+v", pending)
+	}
+	if p := pending.Sessions[0].Pending; len(p) != 1 || p[0].PrescriptionID != open || p[0].Tool != "kubectl" || p[0].Expired {
+		t.Fatalf("pending prescriptions = %+v, want only %s", p, open)
+	}
+
+	expired := runJSON("pending", "--expired", "--evidence-dir", evidenceDir)
+	if expired["total"] != float64(0) {
+		t.Fatalf("pending --expired = %#v, want none", expired)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/pkg/evidence"
 )
 
 type prescribeFlags struct {
@@ -25,6 +26,8 @@ type prescribeFlags struct {
 	sessionID           string
 	operationID         string
 	attempt             int
+	reverts             string
+	requireApproval     string
 	traceID             string
 	spanID              string
 	parentSpanID        string
@@ -42,6 +45,7 @@ type prescribeCommand struct {
 	service      *lifecycle.Service
 	input        lifecycle.PrescribeInput
 	evidencePath string
+	signer       evidence.Signer
 }
 
 func cmdPrescribe(args []string, stdout, stderr io.Writer) int {
@@ -86,12 +90,19 @@ func cmdPrescribe(args []string, stdout, stderr io.Writer) int {
 		"scope_class":     prescOut.ScopeClass,
 		"canon_version":   prescOut.CanonVersion,
 	}
+	if prescOut.RevertsPrescriptionID != "" {
+		result["reverts_prescription_id"] = prescOut.RevertsPrescriptionID
+		result["revert_match"] = prescOut.RevertMatch
+	}
+	if prescOut.ApprovalRequired {
+		result["approval_required"] = true
+	}
 
 	if writeJSON(stdout, stderr, "encode prescription", result) != 0 {
 		return 1
 	}
 
-	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, prescOut.SessionID, stderr)
+	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, cmd.signer, stderr)
 	return 0
 }
 
@@ -113,6 +124,8 @@ func parsePrescribeFlags(args []string, stderr io.Writer) (prescribeFlags, int)
 	sessionIDFlag := fs.String("session-id", "", "Session/run boundary ID (generated if omitted)")
 	operationIDFlag := fs.String("operation-id", "", "Operation identifier")
 	attemptFlag := fs.Int("attempt", 0, "Retry attempt counter")
+	revertsFlag := fs.String("reverts", "", "Prescription ID this operation rolls back")
+	requireApprovalFlag := fs.String("require-approval", "", "Lowest effective risk that needs human approval: low, medium, high, critical, or off")
 	traceIDFlag := fs.String("trace-id", "", "Distributed tracing correlation ID")
 	spanIDFlag := fs.String("span-id", "", "Trace span identifier")
 	parentSpanIDFlag := fs.String("parent-span-id", "", "Parent span identifier")
@@ -146,6 +159,8 @@ func parsePrescribeFlags(args []string, stderr io.Writer) (prescribeFlags, int)
 		sessionID:           *sessionIDFlag,
 		operationID:         *operationIDFlag,
 		attempt:             *attemptFlag,
+		reverts:             *revertsFlag,
+		requireApproval:     *requireApprovalFlag,
 		traceID:             *traceIDFlag,
 		spanID:              *spanIDFlag,
 		parentSpanID:        *parentSpanIDFlag,
@@ -161,7 +176,7 @@ func parsePrescribeFlags(args []string, stderr io.Writer) (prescribeFlags, int)
 }
 
 func preparePrescribeCommand(opts prescribeFlags) (prescribeCommand, error) {
-	svc, evidencePath, _, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode)
+	svc, evidencePath, signer, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode, opts.requireApproval, "")
 	if err != nil {
 		return prescribeCommand{}, err
 	}
@@ -196,21 +211,23 @@ func preparePrescribeCommand(opts prescribeFlags) (prescribeCommand, error) {
 	return prescribeCommand{
 		service: svc,
 		input: lifecycle.PrescribeInput{
-			Actor:            actor,
-			Tool:             opts.tool,
-			Operation:        opts.operation,
-			RawArtifact:      data,
-			Environment:      opts.environment,
-			CanonicalAction:  preCanon,
-			ExternalFindings: externalFindings,
-			SessionID:        opts.sessionID,
-			OperationID:      opts.operationID,
-			Attempt:          opts.attempt,
-			TraceID:          opts.traceID,
-			SpanID:           opts.spanID,
-			ParentSpanID:     opts.parentSpanID,
-			ScopeDimensions:  scopeDimensions,
+			Actor:                 actor,
+			Tool:                  opts.tool,
+			Operation:             opts.operation,
+			RawArtifact:           data,
+			Environment:           opts.environment,
+			CanonicalAction:       preCanon,
+			ExternalFindings:      externalFindings,
+			SessionID:             opts.sessionID,
+			OperationID:           opts.operationID,
+			Attempt:               opts.attempt,
+			TraceID:               opts.traceID,
+			SpanID:                opts.spanID,
+			ParentSpanID:          opts.parentSpanID,
+			ScopeDimensions:       scopeDimensions,
+			RevertsPrescriptionID: opts.reverts,
 		},
 		evidencePath: evidencePath,
+		signer:       signer,
 	}, nil
 }
@@ -111,3 +111,59 @@ func TestPrescribePersistsExtendedActorMetadata(t *testing.T) {
 		t.Fatalf("actor.skill_version = %q", entries[0].Actor.SkillVersion)
 	}
 }
+
+func TestPrescribeRevertsLinksRollbackAndExplainReportsIt(t *testing.T) {
+	t.Parallel()
+
+	signingKey := testutil.TestSigningKeyBase64(t)
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	artifactPath := filepath.Join(tmp, "artifact.yaml")
+	if err := os.WriteFile(artifactPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: prod\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	prescribe := func(extra ...string) map[string]interface{} {
+		t.Helper()
+		var out, errBuf bytes.Buffer
+		args := append([]string{
+			"prescribe",
+			"-f", artifactPath,
+			"--tool", "kubectl",
+			"--signing-key", signingKey,
+			"--evidence-dir", evidenceDir,
+		}, extra...)
+		if code := run(args, &out, &errBuf); code != 0 {
+			t.Fatalf("prescribe %v exit=%d stderr=%s", extra, code, errBuf.String())
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("decode prescribe output: %v", err)
+		}
+		return result
+	}
+
+	original := prescribe()
+	originalID, _ := original["prescription_id"].(string)
+	rollback := prescribe("--reverts", originalID)
+	if rollback["reverts_prescription_id"] != originalID || rollback["revert_match"] != evidence.RevertMatchFull {
+		t.Fatalf("rollback result = %#v", rollback)
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"explain", "--evidence-dir", evidenceDir}, &out, &errBuf); code != 0 {
+		t.Fatalf("explain exit=%d stderr=%s", code, errBuf.String())
+	}
+	var explain struct {
+		Rollbacks *struct {
+			Rollbacks    int     `json:"rollbacks"`
+			RolledBack   int     `json:"rolled_back"`
+			RollbackRate float64 `json:"rollback_rate"`
+		} `json:"rollbacks"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &explain); err != nil {
+		t.Fatalf("decode explain output: %v", err)
+	}
+	if explain.Rollbacks == nil || explain.Rollbacks.Rollbacks != 1 || explain.Rollbacks.RolledBack != 1 || explain.Rollbacks.RollbackRate != 0.5 {
+		t.Fatalf("explain rollbacks = %+v", explain.Rollbacks)
+	}
+}
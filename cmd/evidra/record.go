@@ -13,6 +13,9 @@ import (
 	"time"
 
 	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/score"
+	"samebits.com/evidra/internal/transcript"
+	"samebits.com/evidra/internal/verify"
 	"samebits.com/evidra/pkg/evidence"
 )
 
@@ -29,9 +32,23 @@ type recordFlags struct {
 	sessionID           string
 	operationID         string
 	attempt             int
+	reverts             string
+	requireApproval     string
+	approverKeyring     string
 	signingKey          string
 	signingKeyPath      string
 	signingMode         string
+	gates               multiStringFlag
+	confirm             bool
+	approvalWait        time.Duration
+	captureOutput       bool
+	captureMaxBytes     int
+	verify              bool
+	discover            bool
+	// shim runs the wrapped command transparently for `evidra shim exec`:
+	// its stdio stays on the terminal, no JSON result is printed, and a
+	// failure to prescribe falls back to running the command unrecorded.
+	shim bool
 	// Mode flags
 	url             string
 	apiKey          string
@@ -43,8 +60,12 @@ type recordFlags struct {
 type recordCommand struct {
 	service        *lifecycle.Service
 	evidencePath   string
+	signer         evidence.Signer
 	prescribeInput lifecycle.PrescribeInput
 	wrapped        []string
+	// artifactSource says where a discovered artifact came from; empty
+	// when the artifact was given explicitly or none was found.
+	artifactSource string
 }
 
 type operationMetricsPayload struct {
@@ -59,14 +80,24 @@ type operationMetricsPayload struct {
 
 var emitRecordMetricsHook = emitOperationMetrics
 
+// cmdRecord prescribes the operation, applies any --gate rules, runs the
+// wrapped command, and reports its outcome. A tripped gate, or a required
+// approval that was not given, records a declined report instead of
+// running the command.
 func cmdRecord(args []string, stdout, stderr io.Writer) int {
 	opts, wrappedCmd, code := parseRecordFlags(args, stderr)
 	if code != 0 {
 		return code
 	}
+	return runRecord(opts, wrappedCmd, stdout, stderr)
+}
 
-	cmd, err := prepareRecordCommand(opts, wrappedCmd)
+func runRecord(opts recordFlags, wrappedCmd []string, stdout, stderr io.Writer) int {
+	cmd, err := prepareRecordCommand(opts, wrappedCmd, stderr)
 	if err != nil {
+		if opts.shim {
+			return runShimPassthrough(wrappedCmd, stdout, stderr, err)
+		}
 		fmt.Fprintf(stderr, "%v\n", err)
 		return 2
 	}
@@ -75,60 +106,207 @@ func cmdRecord(args []string, stdout, stderr io.Writer) int {
 		fmt.Fprintf(stderr, "%v\n", err)
 		return 2
 	}
-
-	exitCode, durationMs, err := executeWrappedCommand(context.Background(), cmd.wrapped, stderr)
+	gates, err := parseGateRules(opts.gates, profile)
 	if err != nil {
 		fmt.Fprintf(stderr, "%v\n", err)
-		return 1
+		return 2
 	}
 
+	ctx := context.Background()
 	processor := NewOperationProcessor(cmd.service)
-	opResult, err := processor.Process(context.Background(), OperationRequest{
+	req := OperationRequest{
 		PrescribeInput: cmd.prescribeInput,
-		ExitCode:       exitCode,
 		ReportActor:    cmd.prescribeInput.Actor,
 		SessionID:      cmd.prescribeInput.SessionID,
 		OperationID:    cmd.prescribeInput.OperationID,
-	})
+	}
+	prescOut, err := cmd.service.Prescribe(ctx, cmd.prescribeInput)
 	if err != nil {
+		if opts.shim {
+			return runShimPassthrough(wrappedCmd, stdout, stderr, err)
+		}
 		fmt.Fprintf(stderr, "record process: %v\n", err)
 		return 1
 	}
 
+	gate, err := decideRecordGate(ctx, opts, cmd, prescOut, gates, profile, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	var out recordOutcome
+	if gate != nil && !gate.Confirmed {
+		out.result, err = processor.Decline(ctx, req, prescOut, evidence.DecisionContext{Trigger: gateTrigger, Reason: gate.Reason})
+		if err != nil {
+			fmt.Fprintf(stderr, "record process: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stderr, "evidra: %s; wrapped command not run\n", gate.Reason)
+	} else {
+		if gate != nil {
+			req.ExternalRefs = append(req.ExternalRefs, evidence.ExternalRef{Type: gateOverrideRefType, ID: gate.Rule})
+		}
+		out, err = runRecordedCommand(ctx, opts, cmd, processor, &req, prescOut, stdout, stderr)
+		if err != nil {
+			if opts.shim && out.ran {
+				// The command ran; the caller sees its exit code even
+				// though its outcome was not recorded.
+				fmt.Fprintf(stderr, "evidra shim: warning: %v\n", err)
+				return out.exitCode
+			}
+			fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+	}
+
 	assessment, err := buildOperationAssessmentWithProfile(
 		cmd.evidencePath,
-		opResult.ReportOutput.SessionID,
-		opResult.PrescribeOutput.EffectiveRisk,
+		out.result.ReportOutput.SessionID,
+		out.result.PrescribeOutput.EffectiveRisk,
 		profile,
 	)
 	if err != nil {
 		fmt.Fprintf(stderr, "record assessment: %v\n", err)
 		return 1
 	}
+	emitRecordTelemetry(ctx, cmd, out, assessment, stderr)
+
+	result := recordResult(cmd, req, out, assessment, gate)
+	if !opts.shim && writeJSON(stdout, stderr, "encode record", result) != 0 {
+		return 1
+	}
+
+	// Best-effort forward evidence to API if online.
+	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, cmd.signer, stderr)
 
-	if err := emitRecordMetricsHook(context.Background(), operationMetricsPayload{
+	if gate != nil && !gate.Confirmed {
+		return exitCodeGateBlocked
+	}
+	return out.exitCode
+}
+
+// recordOutcome is what declining or running the wrapped command produced.
+type recordOutcome struct {
+	result OperationResult
+	// ran is set once the wrapped command has run, even when reporting
+	// its outcome then failed.
+	ran          bool
+	exitCode     int
+	durationMs   int64
+	verification *evidence.VerificationPayload
+}
+
+// decideRecordGate evaluates the --gate rules and, when the prescription
+// requires approval and no unconfirmed gate tripped, waits for the
+// approval. A nil decision lets the command run.
+func decideRecordGate(ctx context.Context, opts recordFlags, cmd recordCommand, prescOut lifecycle.PrescribeOutput, gates []gateRule, profile score.Profile, stderr io.Writer) (*gateDecision, error) {
+	var gate *gateDecision
+	if len(gates) > 0 {
+		facts := gateFactsFor(prescOut)
+		if needsScore(gates) {
+			pre, err := buildOperationAssessmentWithProfile(cmd.evidencePath, prescOut.SessionID, prescOut.EffectiveRisk, profile)
+			if err != nil {
+				return nil, fmt.Errorf("record assessment: %w", err)
+			}
+			facts.Score, facts.ScoreBand = pre.Score, pre.ScoreBand
+		}
+		if decision, tripped := evaluateGates(gates, facts); tripped {
+			decision.Confirmed = opts.confirm && confirmGateHook(decision, stderr)
+			gate = &decision
+		}
+	}
+	if prescOut.ApprovalRequired && (gate == nil || gate.Confirmed) {
+		// Forward the prescription first so remote approvers can see it.
+		remote := forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, cmd.signer, stderr)
+		approvalGate, err := awaitApproval(ctx, cmd.service, remote, prescOut, opts.approvalWait, stderr)
+		if err != nil {
+			return nil, fmt.Errorf("record approval: %w", err)
+		}
+		if approvalGate != nil {
+			gate = approvalGate
+		}
+	}
+	return gate, nil
+}
+
+// runRecordedCommand runs the wrapped command, capturing its transcript,
+// reports the outcome, and verifies live state when asked to.
+func runRecordedCommand(ctx context.Context, opts recordFlags, cmd recordCommand, processor *OperationProcessor, req *OperationRequest, prescOut lifecycle.PrescribeOutput, stdout, stderr io.Writer) (recordOutcome, error) {
+	streams := wrappedStreams{stdout: stderr, stderr: stderr}
+	if opts.shim {
+		streams = wrappedStreams{stdin: os.Stdin, stdout: stdout, stderr: stderr}
+	}
+	var recorder *transcript.Recorder
+	if opts.captureOutput {
+		recorder = transcript.NewRecorder(opts.captureMaxBytes)
+		streams.stdout = io.MultiWriter(streams.stdout, recorder)
+		streams.stderr = io.MultiWriter(streams.stderr, recorder)
+	}
+	var (
+		out     recordOutcome
+		execErr error
+	)
+	out.exitCode, out.durationMs, execErr = executeWrappedCommand(ctx, cmd.wrapped, streams)
+	if execErr != nil {
+		// Close the prescription so the failed start is not reported
+		// as an abandoned operation.
+		req.ExitCode = -1
+		if res, err := processor.Complete(ctx, *req, prescOut); err == nil { // best-effort: the start failure is the error surfaced
+			if err := emitOperationSpan(ctx, cmd.evidencePath, res.ReportOutput.Entry, nil); err != nil {
+				fmt.Fprintf(stderr, "warning: trace export failed: %v\n", err)
+			}
+		}
+		return recordOutcome{}, execErr
+	}
+	out.ran = true
+	req.ExitCode = out.exitCode
+	if recorder != nil {
+		req.Transcript = storeTranscript(cmd.evidencePath, recorder, stderr)
+	}
+	var err error
+	out.result, err = processor.Complete(ctx, *req, prescOut)
+	if err != nil {
+		return out, fmt.Errorf("record process: %w", err)
+	}
+	if opts.verify && out.exitCode == 0 {
+		out.verification = verifyRecordedOperation(ctx, cmd, prescOut.PrescriptionID, stderr)
+	}
+	return out, nil
+}
+
+// emitRecordTelemetry exports the operation's metrics and span. Failures
+// are warnings: the evidence is already recorded.
+func emitRecordTelemetry(ctx context.Context, cmd recordCommand, out recordOutcome, assessment operationAssessment, stderr io.Writer) {
+	if err := emitRecordMetricsHook(ctx, operationMetricsPayload{
 		Tool:           cmd.prescribeInput.Tool,
 		Environment:    cmd.prescribeInput.Environment,
-		ExitCode:       exitCode,
-		DurationMs:     durationMs,
+		ExitCode:       out.exitCode,
+		DurationMs:     out.durationMs,
 		ScoreBand:      assessment.ScoreBand,
 		AssessmentMode: assessment.Basis.AssessmentMode,
 		SignalSummary:  assessment.SignalSummary,
 	}); err != nil {
 		fmt.Fprintf(stderr, "warning: metrics export failed: %v\n", err)
 	}
+	if err := emitOperationSpan(ctx, cmd.evidencePath, out.result.ReportOutput.Entry, assessment.SignalSummary); err != nil {
+		fmt.Fprintf(stderr, "warning: trace export failed: %v\n", err)
+	}
+}
 
+// recordResult is the JSON object record prints.
+func recordResult(cmd recordCommand, req OperationRequest, out recordOutcome, assessment operationAssessment, gate *gateDecision) map[string]interface{} {
+	presc, report := out.result.PrescribeOutput, out.result.ReportOutput
 	result := map[string]interface{}{
-		"ok":                 exitCode == 0,
-		"session_id":         opResult.ReportOutput.SessionID,
+		"ok":                 out.exitCode == 0 && report.Verdict != evidence.VerdictDeclined,
+		"session_id":         report.SessionID,
 		"operation_id":       cmd.prescribeInput.OperationID,
-		"prescription_id":    opResult.PrescribeOutput.PrescriptionID,
-		"report_id":          opResult.ReportOutput.ReportID,
-		"exit_code":          exitCode,
-		"verdict":            evidence.VerdictFromExitCode(exitCode),
-		"duration_ms":        durationMs,
-		"risk_inputs":        opResult.PrescribeOutput.RiskInputs,
-		"effective_risk":     opResult.PrescribeOutput.EffectiveRisk,
+		"prescription_id":    presc.PrescriptionID,
+		"report_id":          report.ReportID,
+		"verdict":            report.Verdict,
+		"duration_ms":        out.durationMs,
+		"risk_inputs":        presc.RiskInputs,
+		"effective_risk":     presc.EffectiveRisk,
 		"score":              assessment.Score,
 		"score_band":         assessment.ScoreBand,
 		"scoring_profile_id": assessment.ScoringProfileID,
@@ -136,14 +314,32 @@ func cmdRecord(args []string, stdout, stderr io.Writer) int {
 		"basis":              assessment.Basis,
 		"confidence":         assessment.Confidence,
 	}
-	if writeJSON(stdout, stderr, "encode record", result) != 0 {
-		return 1
+	if report.ExitCode != nil {
+		result["exit_code"] = out.exitCode
 	}
-
-	// Best-effort forward evidence to API if online.
-	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, opResult.ReportOutput.SessionID, stderr)
-
-	return exitCode
+	if report.DecisionContext != nil {
+		result["decision_context"] = report.DecisionContext
+	}
+	if gate != nil {
+		result["gate"] = gate
+	}
+	if req.Transcript != nil {
+		result["transcript"] = req.Transcript
+	}
+	if out.verification != nil {
+		result["verification"] = out.verification
+	}
+	if cmd.artifactSource != "" {
+		result["artifact_source"] = cmd.artifactSource
+	}
+	if presc.RevertsPrescriptionID != "" {
+		result["reverts_prescription_id"] = presc.RevertsPrescriptionID
+		result["revert_match"] = presc.RevertMatch
+	}
+	if presc.ApprovalRequired {
+		result["approval_required"] = true
+	}
+	return result
 }
 
 func parseRecordFlags(args []string, stderr io.Writer) (recordFlags, []string, int) {
@@ -165,9 +361,20 @@ func parseRecordFlags(args []string, stderr io.Writer) (recordFlags, []string, i
 	sessionIDFlag := fs.String("session-id", "", "Session/run boundary ID (generated if omitted)")
 	operationIDFlag := fs.String("operation-id", "", "Operation identifier")
 	attemptFlag := fs.Int("attempt", 0, "Retry attempt counter")
+	revertsFlag := fs.String("reverts", "", "Prescription ID this operation rolls back")
+	requireApprovalFlag := fs.String("require-approval", "", "Lowest effective risk that needs human approval: low, medium, high, critical, or off")
+	approverKeyringFlag := fs.String("approver-keyring", "", "Approvers' public keys (JWKS or PEM); only approvals they signed count")
 	signingKeyFlag := fs.String("signing-key", "", "Base64-encoded Ed25519 signing key")
 	signingKeyPathFlag := fs.String("signing-key-path", "", "Path to PEM-encoded Ed25519 signing key")
 	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	var gates multiStringFlag
+	fs.Var(&gates, "gate", "Refuse to run when all comma-separated conditions hold: risk>=LEVEL, scope=CLASS, tag=TAG, band<BAND, score<N (repeatable)")
+	confirmFlag := fs.Bool("confirm", false, "Ask on the terminal whether to run anyway when a gate trips")
+	approvalWaitFlag := fs.Duration("approval-wait", 0, "How long to wait for an approval when the prescription requires one")
+	captureOutputFlag := fs.Bool("capture-output", true, "Store the wrapped command's redacted output as a transcript blob")
+	verifyFlag := fs.Bool("verify", false, "Read back live state after a successful run and record a verification entry")
+	discoverFlag := fs.Bool("discover", false, "Derive the artifact from the wrapped command line when --artifact and --canonical-action are not given")
+	captureMaxBytesFlag := fs.Int("capture-max-bytes", transcript.DefaultMaxBytes, "Maximum transcript size; the middle of longer output is dropped")
 	urlFlag := fs.String("url", os.Getenv("EVIDRA_URL"), "Evidra API URL")
 	apiKeyFlag := fs.String("api-key", os.Getenv("EVIDRA_API_KEY"), "Evidra API key")
 	offlineFlag := fs.Bool("offline", false, "Force offline mode")
@@ -177,6 +384,10 @@ func parseRecordFlags(args []string, stderr io.Writer) (recordFlags, []string, i
 		return recordFlags{}, nil, 2
 	}
 
+	if *captureMaxBytesFlag <= 0 {
+		fmt.Fprintln(stderr, "--capture-max-bytes must be positive")
+		return recordFlags{}, nil, 2
+	}
 	if len(fs.Args()) > 0 {
 		fmt.Fprintln(stderr, "record requires '--' before wrapped command")
 		return recordFlags{}, nil, 2
@@ -209,9 +420,19 @@ func parseRecordFlags(args []string, stderr io.Writer) (recordFlags, []string, i
 		sessionID:           *sessionIDFlag,
 		operationID:         *operationIDFlag,
 		attempt:             *attemptFlag,
+		reverts:             *revertsFlag,
+		requireApproval:     *requireApprovalFlag,
+		approverKeyring:     *approverKeyringFlag,
 		signingKey:          *signingKeyFlag,
 		signingKeyPath:      *signingKeyPathFlag,
 		signingMode:         *signingModeFlag,
+		gates:               gates,
+		confirm:             *confirmFlag,
+		approvalWait:        *approvalWaitFlag,
+		captureOutput:       *captureOutputFlag,
+		captureMaxBytes:     *captureMaxBytesFlag,
+		verify:              *verifyFlag,
+		discover:            *discoverFlag,
 		url:                 *urlFlag,
 		apiKey:              *apiKeyFlag,
 		offline:             *offlineFlag,
@@ -229,18 +450,35 @@ func splitRecordArgs(args []string) ([]string, []string) {
 	return args, nil
 }
 
-func prepareRecordCommand(opts recordFlags, wrapped []string) (recordCommand, error) {
-	svc, evidencePath, _, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode)
+func prepareRecordCommand(opts recordFlags, wrapped []string, stderr io.Writer) (recordCommand, error) {
+	svc, evidencePath, signer, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode, opts.requireApproval, opts.approverKeyring)
 	if err != nil {
 		return recordCommand{}, err
 	}
 
-	var data []byte
-	if opts.artifactPath != "" {
+	var (
+		data           []byte
+		artifactSource string
+	)
+	switch {
+	case opts.artifactPath != "":
 		data, err = os.ReadFile(opts.artifactPath)
 		if err != nil {
 			return recordCommand{}, fmt.Errorf("read artifact: %w", err)
 		}
+	case opts.canonicalActionJSON == "" && opts.discover && !isShellWrappedCommand(wrapped[0]):
+		// Discovery is best-effort: without an artifact the operation is
+		// still prescribed, as it was before discovery existed.
+		found, err := discoverArtifact(context.Background(), opts.tool, opts.operation, wrapped)
+		switch {
+		case err == nil:
+			data, artifactSource = found.data, found.source
+			if !opts.shim {
+				fmt.Fprintf(stderr, "evidra record: artifact discovered from %s\n", found.source)
+			}
+		case !errors.Is(err, errNoArtifact):
+			fmt.Fprintf(stderr, "warning: artifact discovery: %v\n", err)
+		}
 	}
 
 	preCanon, err := parseCanonicalActionFlag(opts.canonicalActionJSON)
@@ -268,19 +506,22 @@ func prepareRecordCommand(opts recordFlags, wrapped []string) (recordCommand, er
 	return recordCommand{
 		service:      svc,
 		evidencePath: evidencePath,
+		signer:       signer,
 		prescribeInput: lifecycle.PrescribeInput{
-			Actor:            actor,
-			Tool:             opts.tool,
-			Operation:        opts.operation,
-			RawArtifact:      data,
-			Environment:      opts.environment,
-			CanonicalAction:  preCanon,
-			ExternalFindings: externalFindings,
-			SessionID:        opts.sessionID,
-			OperationID:      opts.operationID,
-			Attempt:          opts.attempt,
+			Actor:                 actor,
+			Tool:                  opts.tool,
+			Operation:             opts.operation,
+			RawArtifact:           data,
+			Environment:           opts.environment,
+			CanonicalAction:       preCanon,
+			ExternalFindings:      externalFindings,
+			SessionID:             opts.sessionID,
+			OperationID:           opts.operationID,
+			Attempt:               opts.attempt,
+			RevertsPrescriptionID: opts.reverts,
 		},
-		wrapped: wrapped,
+		wrapped:        wrapped,
+		artifactSource: artifactSource,
 	}, nil
 }
 
@@ -342,8 +583,9 @@ func inferRecordOperation(tool string, wrapped []string) (string, error) {
 			return "build", nil
 		}
 	case "docker":
-		if wrapped[1] == "build" {
-			return "build", nil
+		switch wrapped[1] {
+		case "build", "run", "create", "start", "restart", "update", "pull", "push", "rm", "rmi", "stop", "kill":
+			return wrapped[1], nil
 		}
 		if wrapped[1] == "compose" && len(wrapped) >= 3 && !strings.HasPrefix(wrapped[2], "-") {
 			return wrapped[2], nil
@@ -386,15 +628,63 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
-func executeWrappedCommand(ctx context.Context, wrapped []string, stderr io.Writer) (int, int64, error) {
+// verifyRecordedOperation reads back the state a successful run left. A
+// failed read-back only warns: the operation itself is already recorded.
+func verifyRecordedOperation(ctx context.Context, cmd recordCommand, prescriptionID string, stderr io.Writer) *evidence.VerificationPayload {
+	target, err := loadVerifyTarget(cmd.evidencePath, prescriptionID)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(ctx, defaultVerifyTimeout)
+		defer cancel()
+		var payload evidence.VerificationPayload
+		payload, _, err = recordVerification(ctx, cmd.evidencePath, cmd.signer, target, verify.Request{
+			Artifact: cmd.prescribeInput.RawArtifact,
+		})
+		if err == nil {
+			return &payload
+		}
+	}
+	fmt.Fprintf(stderr, "warning: verification skipped: %v\n", err)
+	return nil
+}
+
+// storeTranscript writes the captured output as a blob next to the evidence
+// store. A failure only warns: the report is still worth recording.
+func storeTranscript(evidencePath string, recorder *transcript.Recorder, stderr io.Writer) *evidence.TranscriptRef {
+	captured := recorder.Finish()
+	digest, err := evidence.WriteBlobAtPath(evidencePath, captured.Data)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: transcript not stored: %v\n", err)
+		return nil
+	}
+	return &evidence.TranscriptRef{
+		Digest:        digest,
+		SizeBytes:     int64(len(captured.Data)),
+		CapturedBytes: captured.TotalBytes,
+		Truncated:     captured.Truncated,
+		Redactions:    captured.Redactions,
+	}
+}
+
+// wrappedStreams connects the wrapped command. record sends both output
+// streams to stderr, keeping stdout free for the JSON result; shims keep
+// the command's own stdio.
+type wrappedStreams struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// executeWrappedCommand runs wrapped and returns its exit code and duration.
+func executeWrappedCommand(ctx context.Context, wrapped []string, streams wrappedStreams) (int, int64, error) {
 	if len(wrapped) == 0 {
 		return 1, 0, fmt.Errorf("wrapped command is required")
 	}
 
 	start := time.Now()
 	cmd := exec.CommandContext(ctx, wrapped[0], wrapped[1:]...)
-	cmd.Stdout = stderr
-	cmd.Stderr = stderr
+	cmd.Stdin = streams.stdin
+	cmd.Stdout = streams.stdout
+	cmd.Stderr = streams.stderr
 	err := cmd.Run()
 	durationMs := time.Since(start).Milliseconds()
 	if err == nil {
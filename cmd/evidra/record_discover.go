@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"samebits.com/evidra/internal/verify"
+)
+
+// discoveredArtifact is an artifact derived from the wrapped command line
+// when neither --artifact nor --canonical-action is given.
+type discoveredArtifact struct {
+	data []byte
+	// source describes where the bytes came from, e.g. "file:deploy.yaml"
+	// or "kubectl kustomize overlays/prod".
+	source string
+}
+
+// errNoArtifact means the command line names nothing to derive an artifact
+// from; record then prescribes without one, as before.
+var errNoArtifact = errors.New("no artifact in command line")
+
+// discoverCommandHook runs artifact-generating commands such as
+// `helm template`; tests replace it.
+var discoverCommandHook verify.CommandRunner = verify.ExecRunner
+
+// discoverArtifact derives the artifact the wrapped command will act on.
+// It reads the files the command names, generates manifests for commands
+// that only name resources, and asks the tool itself to render what it
+// would apply (kustomize, helm template, terraform show).
+func discoverArtifact(ctx context.Context, tool, operation string, wrapped []string) (discoveredArtifact, error) {
+	if len(wrapped) < 2 {
+		return discoveredArtifact{}, errNoArtifact
+	}
+	binary, args := wrapped[0], wrapped[2:]
+	switch tool {
+	case "kubectl", "oc":
+		return discoverKubectlArtifact(ctx, binary, operation, args)
+	case "helm":
+		return discoverHelmArtifact(ctx, binary, operation, args)
+	case "terraform":
+		return discoverTerraformArtifact(ctx, binary, operation, args)
+	case "docker":
+		// The docker adapter canonicalizes the command line itself.
+		line := append([]string{normalizeWrappedToolName(binary)}, wrapped[1:]...)
+		return discoveredArtifact{data: []byte(strings.Join(line, " ")), source: "command line"}, nil
+	}
+	return discoveredArtifact{}, errNoArtifact
+}
+
+// parsedArgs splits a command line into flag values and positionals.
+type parsedArgs struct {
+	values      map[string][]string
+	bools       map[string]bool
+	positionals []string
+}
+
+// parseToolArgs parses args given the flags that take a value. aliases maps
+// short forms to their long name. Unknown flags are treated as booleans
+// unless written as --flag=value.
+func parseToolArgs(args []string, valueFlags map[string]bool, aliases map[string]string) parsedArgs {
+	p := parsedArgs{values: map[string][]string{}, bools: map[string]bool{}}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			p.positionals = append(p.positionals, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			p.positionals = append(p.positionals, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if long, ok := aliases[name]; ok {
+			name = long
+		}
+		switch {
+		case hasValue:
+			p.values[name] = append(p.values[name], value)
+		case valueFlags[name] && i+1 < len(args):
+			i++
+			p.values[name] = append(p.values[name], args[i])
+		default:
+			p.bools[name] = true
+		}
+	}
+	return p
+}
+
+func (p parsedArgs) last(name string) string {
+	if v := p.values[name]; len(v) > 0 {
+		return v[len(v)-1]
+	}
+	return ""
+}
+
+var kubectlValueFlags = map[string]bool{
+	"filename": true, "kustomize": true, "namespace": true, "selector": true, "output": true,
+	"field-selector": true, "context": true, "cluster": true, "user": true, "kubeconfig": true,
+	"container": true, "grace-period": true, "timeout": true, "field-manager": true,
+	"cascade": true, "prune-allowlist": true, "type": true, "patch": true,
+}
+
+var kubectlAliases = map[string]string{
+	"f": "filename", "k": "kustomize", "n": "namespace", "l": "selector", "o": "output",
+	"R": "recursive", "A": "all-namespaces", "c": "container",
+}
+
+func discoverKubectlArtifact(ctx context.Context, binary, operation string, args []string) (discoveredArtifact, error) {
+	p := parseToolArgs(args, kubectlValueFlags, kubectlAliases)
+	if files := p.values["filename"]; len(files) > 0 {
+		return readManifestFiles(files, p.bools["recursive"])
+	}
+	if dir := p.last("kustomize"); dir != "" {
+		out, err := discoverCommandHook(ctx, binary, "kustomize", dir)
+		if err != nil {
+			return discoveredArtifact{}, err
+		}
+		return discoveredArtifact{data: out, source: normalizeWrappedToolName(binary) + " kustomize " + dir}, nil
+	}
+	if operation == "delete" {
+		return kubectlDeleteManifest(p)
+	}
+	return discoveredArtifact{}, errNoArtifact
+}
+
+// readManifestFiles concatenates the manifests -f names, expanding
+// directories the way kubectl does (*.yaml, *.yml, *.json).
+func readManifestFiles(paths []string, recursive bool) (discoveredArtifact, error) {
+	var docs [][]byte
+	for _, path := range paths {
+		if path == "-" || strings.Contains(path, "://") {
+			return discoveredArtifact{}, fmt.Errorf("cannot read artifact from %q; pass --artifact", path)
+		}
+		files, err := manifestFiles(path, recursive)
+		if err != nil {
+			return discoveredArtifact{}, err
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return discoveredArtifact{}, fmt.Errorf("read artifact: %w", err)
+			}
+			docs = append(docs, data)
+		}
+	}
+	if len(docs) == 0 {
+		return discoveredArtifact{}, errNoArtifact
+	}
+	if len(docs) == 1 {
+		return discoveredArtifact{data: docs[0], source: "file:" + paths[0]}, nil
+	}
+	var buf bytes.Buffer
+	for i, d := range docs {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(d)
+		if len(d) > 0 && d[len(d)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return discoveredArtifact{data: buf.Bytes(), source: "file:" + strings.Join(paths, ",")}, nil
+}
+
+func manifestFiles(path string, recursive bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read artifact: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read artifact: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+type kubeKind struct {
+	apiVersion string
+	kind       string
+	namespaced bool
+}
+
+// kubeKinds resolves the resource names kubectl accepts on the command line
+// (plural, singular and short forms) for built-in kinds.
+var kubeKinds = func() map[string]kubeKind {
+	table := []struct {
+		kind  kubeKind
+		names []string
+	}{
+		{kubeKind{"v1", "Pod", true}, []string{"pods", "pod", "po"}},
+		{kubeKind{"v1", "Service", true}, []string{"services", "service", "svc"}},
+		{kubeKind{"v1", "ConfigMap", true}, []string{"configmaps", "configmap", "cm"}},
+		{kubeKind{"v1", "Secret", true}, []string{"secrets", "secret"}},
+		{kubeKind{"v1", "ServiceAccount", true}, []string{"serviceaccounts", "serviceaccount", "sa"}},
+		{kubeKind{"v1", "PersistentVolumeClaim", true}, []string{"persistentvolumeclaims", "persistentvolumeclaim", "pvc"}},
+		{kubeKind{"v1", "PersistentVolume", false}, []string{"persistentvolumes", "persistentvolume", "pv"}},
+		{kubeKind{"v1", "Namespace", false}, []string{"namespaces", "namespace", "ns"}},
+		{kubeKind{"v1", "Node", false}, []string{"nodes", "node", "no"}},
+		{kubeKind{"apps/v1", "Deployment", true}, []string{"deployments", "deployment", "deploy"}},
+		{kubeKind{"apps/v1", "StatefulSet", true}, []string{"statefulsets", "statefulset", "sts"}},
+		{kubeKind{"apps/v1", "DaemonSet", true}, []string{"daemonsets", "daemonset", "ds"}},
+		{kubeKind{"apps/v1", "ReplicaSet", true}, []string{"replicasets", "replicaset", "rs"}},
+		{kubeKind{"batch/v1", "Job", true}, []string{"jobs", "job"}},
+		{kubeKind{"batch/v1", "CronJob", true}, []string{"cronjobs", "cronjob", "cj"}},
+		{kubeKind{"networking.k8s.io/v1", "Ingress", true}, []string{"ingresses", "ingress", "ing"}},
+		{kubeKind{"networking.k8s.io/v1", "NetworkPolicy", true}, []string{"networkpolicies", "networkpolicy", "netpol"}},
+		{kubeKind{"autoscaling/v2", "HorizontalPodAutoscaler", true}, []string{"horizontalpodautoscalers", "horizontalpodautoscaler", "hpa"}},
+		{kubeKind{"rbac.authorization.k8s.io/v1", "Role", true}, []string{"roles", "role"}},
+		{kubeKind{"rbac.authorization.k8s.io/v1", "RoleBinding", true}, []string{"rolebindings", "rolebinding"}},
+		{kubeKind{"rbac.authorization.k8s.io/v1", "ClusterRole", false}, []string{"clusterroles", "clusterrole"}},
+		{kubeKind{"rbac.authorization.k8s.io/v1", "ClusterRoleBinding", false}, []string{"clusterrolebindings", "clusterrolebinding"}},
+		{kubeKind{"apiextensions.k8s.io/v1", "CustomResourceDefinition", false}, []string{"customresourcedefinitions", "customresourcedefinition", "crd", "crds"}},
+		{kubeKind{"storage.k8s.io/v1", "StorageClass", false}, []string{"storageclasses", "storageclass", "sc"}},
+	}
+	out := make(map[string]kubeKind)
+	for _, entry := range table {
+		for _, name := range entry.names {
+			out[name] = entry.kind
+		}
+	}
+	return out
+}()
+
+// kubectlDeleteManifest generates a minimal manifest for
+// `kubectl delete TYPE NAME...` and `kubectl delete TYPE/NAME...`, so the
+// prescription names what will be deleted. Selectors and --all cannot be
+// resolved offline.
+func kubectlDeleteManifest(p parsedArgs) (discoveredArtifact, error) {
+	if p.bools["all"] || p.bools["all-namespaces"] || p.last("selector") != "" || p.last("field-selector") != "" {
+		return discoveredArtifact{}, errors.New("delete by selector or --all names no resources; pass --artifact")
+	}
+	type target struct {
+		kind kubeKind
+		name string
+	}
+	var targets []target
+	pos := p.positionals
+	if len(pos) > 0 && !strings.Contains(pos[0], "/") {
+		kind, ok := kubeKinds[strings.ToLower(pos[0])]
+		if !ok {
+			return discoveredArtifact{}, fmt.Errorf("unknown resource type %q; pass --artifact", pos[0])
+		}
+		for _, name := range pos[1:] {
+			targets = append(targets, target{kind: kind, name: name})
+		}
+	} else {
+		for _, ref := range pos {
+			typ, name, _ := strings.Cut(ref, "/")
+			kind, ok := kubeKinds[strings.ToLower(typ)]
+			if !ok || name == "" {
+				return discoveredArtifact{}, fmt.Errorf("unknown resource %q; pass --artifact", ref)
+			}
+			targets = append(targets, target{kind: kind, name: name})
+		}
+	}
+	if len(targets) == 0 {
+		return discoveredArtifact{}, errNoArtifact
+	}
+
+	namespace := p.last("namespace")
+	var buf bytes.Buffer
+	for i, t := range targets {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		fmt.Fprintf(&buf, "apiVersion: %s\nkind: %s\nmetadata:\n  name: %s\n", t.kind.apiVersion, t.kind.kind, t.name)
+		if t.kind.namespaced && namespace != "" {
+			fmt.Fprintf(&buf, "  namespace: %s\n", namespace)
+		}
+	}
+	return discoveredArtifact{data: buf.Bytes(), source: "generated from command line"}, nil
+}
+
+var helmValueFlags = map[string]bool{
+	"namespace": true, "values": true, "set": true, "set-string": true, "set-file": true, "set-json": true,
+	"set-literal": true, "version": true, "repo": true, "kube-context": true, "kubeconfig": true,
+	"timeout": true, "output": true, "description": true, "history-max": true, "post-renderer": true,
+	"post-renderer-args": true, "api-versions": true, "kube-version": true, "username": true, "password": true,
+	"ca-file": true, "cert-file": true, "key-file": true,
+}
+
+var helmAliases = map[string]string{"n": "namespace", "f": "values", "o": "output", "i": "install"}
+
+// helmTemplateFlags are forwarded to `helm template` because they change
+// the rendered manifests.
+var helmTemplateFlags = []string{
+	"namespace", "values", "set", "set-string", "set-file", "set-json", "set-literal", "version", "repo",
+	"post-renderer", "post-renderer-args", "api-versions", "kube-version", "username", "password",
+	"ca-file", "cert-file", "key-file",
+}
+
+func discoverHelmArtifact(ctx context.Context, binary, operation string, args []string) (discoveredArtifact, error) {
+	p := parseToolArgs(args, helmValueFlags, helmAliases)
+	switch operation {
+	case "install", "upgrade":
+		if len(p.positionals) < 2 {
+			return discoveredArtifact{}, errNoArtifact
+		}
+		templateArgs := []string{"template", p.positionals[0], p.positionals[1]}
+		for _, flag := range helmTemplateFlags {
+			for _, v := range p.values[flag] {
+				templateArgs = append(templateArgs, "--"+flag, v)
+			}
+		}
+		if p.bools["devel"] {
+			templateArgs = append(templateArgs, "--devel")
+		}
+		out, err := discoverCommandHook(ctx, binary, templateArgs...)
+		if err != nil {
+			return discoveredArtifact{}, err
+		}
+		return discoveredArtifact{data: out, source: "helm template " + p.positionals[0] + " " + p.positionals[1]}, nil
+	case "uninstall", "delete":
+		if len(p.positionals) < 1 {
+			return discoveredArtifact{}, errNoArtifact
+		}
+		// The release's current manifest is what uninstall removes.
+		getArgs := []string{"get", "manifest", p.positionals[0]}
+		if ns := p.last("namespace"); ns != "" {
+			getArgs = append(getArgs, "--namespace", ns)
+		}
+		if kubeContext := p.last("kube-context"); kubeContext != "" {
+			getArgs = append(getArgs, "--kube-context", kubeContext)
+		}
+		out, err := discoverCommandHook(ctx, binary, getArgs...)
+		if err != nil {
+			return discoveredArtifact{}, err
+		}
+		return discoveredArtifact{data: out, source: "helm get manifest " + p.positionals[0]}, nil
+	}
+	return discoveredArtifact{}, errNoArtifact
+}
+
+var terraformValueFlags = map[string]bool{
+	"var": true, "var-file": true, "target": true, "replace": true, "parallelism": true,
+	"lock-timeout": true, "state": true, "state-out": true, "backup": true,
+}
+
+// discoverTerraformArtifact renders a saved plan for `terraform apply
+// PLANFILE`. Applying without a saved plan has no artifact until terraform
+// plans, so nothing is discovered.
+func discoverTerraformArtifact(ctx context.Context, binary, operation string, args []string) (discoveredArtifact, error) {
+	if operation != "apply" {
+		return discoveredArtifact{}, errNoArtifact
+	}
+	p := parseToolArgs(args, terraformValueFlags, nil)
+	if len(p.positionals) != 1 {
+		return discoveredArtifact{}, errNoArtifact
+	}
+	planFile := p.positionals[0]
+	if info, err := os.Stat(planFile); err != nil || info.IsDir() {
+		return discoveredArtifact{}, errNoArtifact
+	}
+	out, err := discoverCommandHook(ctx, binary, "show", "-json", planFile)
+	if err != nil {
+		return discoveredArtifact{}, err
+	}
+	return discoveredArtifact{data: out, source: "terraform show -json " + planFile}, nil
+}
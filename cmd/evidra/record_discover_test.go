@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+)
+
+func TestDiscoverArtifact_KubectlFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "nested")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile := func(path, body string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(dir, "a.yaml"), "kind: ConfigMap\nmetadata:\n  name: a\n")
+	writeFile(filepath.Join(dir, "b.yml"), "kind: ConfigMap\nmetadata:\n  name: b")
+	writeFile(filepath.Join(dir, "README.md"), "ignored")
+	writeFile(filepath.Join(nested, "c.yaml"), "kind: ConfigMap\nmetadata:\n  name: c\n")
+
+	got, err := discoverArtifact(context.Background(), "kubectl", "apply", []string{"kubectl", "apply", "-f", dir})
+	if err != nil {
+		t.Fatalf("discoverArtifact: %v", err)
+	}
+	if want := "kind: ConfigMap\nmetadata:\n  name: a\n---\nkind: ConfigMap\nmetadata:\n  name: b\n"; string(got.data) != want {
+		t.Fatalf("data = %q, want %q", got.data, want)
+	}
+
+	got, err = discoverArtifact(context.Background(), "kubectl", "apply", []string{"kubectl", "apply", "-R", "--filename=" + dir})
+	if err != nil {
+		t.Fatalf("discoverArtifact recursive: %v", err)
+	}
+	if strings.Count(string(got.data), "---\n") != 2 {
+		t.Fatalf("recursive data = %q, want three documents", got.data)
+	}
+
+	if _, err := discoverArtifact(context.Background(), "kubectl", "apply", []string{"kubectl", "apply", "-f", "-"}); err == nil || errors.Is(err, errNoArtifact) {
+		t.Fatalf("stdin manifest err = %v, want a discovery error", err)
+	}
+}
+
+func TestDiscoverArtifact_KubectlDelete(t *testing.T) {
+	t.Parallel()
+
+	got, err := discoverArtifact(context.Background(), "kubectl", "delete", []string{"kubectl", "delete", "deploy", "web", "api", "-n", "prod"})
+	if err != nil {
+		t.Fatalf("discoverArtifact: %v", err)
+	}
+	want := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: prod\n---\n" +
+		"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n  namespace: prod\n"
+	if string(got.data) != want {
+		t.Fatalf("data = %q, want %q", got.data, want)
+	}
+
+	got, err = discoverArtifact(context.Background(), "kubectl", "delete", []string{"kubectl", "delete", "ns/staging", "svc/web", "--namespace=staging"})
+	if err != nil {
+		t.Fatalf("discoverArtifact type/name: %v", err)
+	}
+	if !strings.Contains(string(got.data), "kind: Namespace\nmetadata:\n  name: staging\n---") {
+		t.Fatalf("cluster-scoped kind should carry no namespace: %q", got.data)
+	}
+
+	for _, args := range [][]string{
+		{"kubectl", "delete", "pods", "--all", "-n", "prod"},
+		{"kubectl", "delete", "pods", "-l", "app=web"},
+		{"kubectl", "delete", "widgets", "x"},
+	} {
+		if _, err := discoverArtifact(context.Background(), "kubectl", "delete", args); err == nil {
+			t.Errorf("discoverArtifact(%v) succeeded, want error", args)
+		}
+	}
+}
+
+func TestDiscoverArtifact_GeneratedByTool(t *testing.T) {
+	// Not parallel: mutates package-level discoverCommandHook.
+	var calls []string
+	orig := discoverCommandHook
+	discoverCommandHook = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return []byte("rendered"), nil
+	}
+	t.Cleanup(func() { discoverCommandHook = orig })
+
+	planFile := filepath.Join(t.TempDir(), "plan.out")
+	if err := os.WriteFile(planFile, []byte("binary plan"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tool, operation string
+		wrapped         []string
+		wantCall        string
+	}{
+		{"kubectl", "apply", []string{"kubectl", "apply", "-k", "overlays/prod"}, "kubectl kustomize overlays/prod"},
+		{"helm", "upgrade", []string{"helm", "upgrade", "--install", "web", "./chart", "-n", "prod", "-f", "values.yaml", "--set", "image.tag=v2", "--atomic"},
+			"helm template web ./chart --namespace prod --values values.yaml --set image.tag=v2"},
+		{"helm", "uninstall", []string{"helm", "uninstall", "web", "--namespace", "prod"}, "helm get manifest web --namespace prod"},
+		{"terraform", "apply", []string{"/usr/local/bin/terraform", "apply", "-auto-approve", planFile}, "/usr/local/bin/terraform show -json " + planFile},
+	}
+	for _, tt := range tests {
+		calls = nil
+		got, err := discoverArtifact(context.Background(), tt.tool, tt.operation, tt.wrapped)
+		if err != nil {
+			t.Fatalf("discoverArtifact(%v): %v", tt.wrapped, err)
+		}
+		if string(got.data) != "rendered" || len(calls) != 1 || calls[0] != tt.wantCall {
+			t.Errorf("discoverArtifact(%v) calls = %q, want [%q]", tt.wrapped, calls, tt.wantCall)
+		}
+	}
+
+	// Applying without a saved plan has nothing to render.
+	calls = nil
+	if _, err := discoverArtifact(context.Background(), "terraform", "apply", []string{"terraform", "apply", "-auto-approve"}); !errors.Is(err, errNoArtifact) {
+		t.Fatalf("terraform apply without plan err = %v, want errNoArtifact", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("unexpected calls %q", calls)
+	}
+}
+
+func TestDiscoverArtifact_DockerCommandLine(t *testing.T) {
+	t.Parallel()
+
+	got, err := discoverArtifact(context.Background(), "docker", "run", []string{"/usr/bin/docker", "run", "--privileged", "nginx"})
+	if err != nil {
+		t.Fatalf("discoverArtifact: %v", err)
+	}
+	if string(got.data) != "docker run --privileged nginx" {
+		t.Fatalf("data = %q", got.data)
+	}
+}
+
+func TestRecordCommandDiscoversArtifactFromWrappedCommand(t *testing.T) {
+	signingKey := testutil.TestSigningKeyBase64(t)
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	artifactPath := filepath.Join(tmp, "deploy.yaml")
+	if err := os.WriteFile(artifactPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: discovered\n  namespace: prod\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+
+	stubDir := filepath.Join(tmp, "bin")
+	mustInstallStubCommand(t, stubDir, "kubectl")
+
+	var out, errBuf bytes.Buffer
+	code := run([]string{
+		"record",
+		"--discover",
+		"--evidence-dir", evidenceDir,
+		"--signing-key", signingKey,
+		"--", "kubectl", "apply", "-f", artifactPath,
+	}, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("record exit=%d stderr=%s", code, errBuf.String())
+	}
+	if !strings.Contains(errBuf.String(), "artifact discovered from file:"+artifactPath) {
+		t.Fatalf("stderr does not report discovery: %s", errBuf.String())
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if result["artifact_source"] != "file:"+artifactPath {
+		t.Fatalf("artifact_source = %v", result["artifact_source"])
+	}
+	action := readPrescribedAction(t, evidenceDir)
+	if len(action.ResourceIdentity) != 1 || action.ResourceIdentity[0].Name != "discovered" || action.ResourceIdentity[0].Namespace != "prod" {
+		t.Fatalf("resource identity = %#v, want the discovered configmap", action.ResourceIdentity)
+	}
+}
+
+func TestRecordCommandDiscoverOffByDefault(t *testing.T) {
+	signingKey := testutil.TestSigningKeyBase64(t)
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+
+	stubDir := filepath.Join(tmp, "bin")
+	mustInstallStubCommand(t, stubDir, "docker")
+
+	var out, errBuf bytes.Buffer
+	code := run([]string{
+		"record",
+		"--evidence-dir", evidenceDir,
+		"--signing-key", signingKey,
+		"--", "docker", "rm", "-f", "web", "api",
+	}, &out, &errBuf)
+	if code != 0 {
+		t.Fatalf("record exit=%d stderr=%s", code, errBuf.String())
+	}
+	if strings.Contains(out.String(), "artifact_source") {
+		t.Fatalf("record discovered an artifact without --discover: %s", out.String())
+	}
+	for _, res := range readPrescribedAction(t, evidenceDir).ResourceIdentity {
+		if res.Name == "web" || res.Name == "api" {
+			t.Fatalf("resource %#v came from the command line without --discover", res)
+		}
+	}
+}
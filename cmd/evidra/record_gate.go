@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/risk"
+	"samebits.com/evidra/internal/score"
+	"samebits.com/evidra/pkg/client"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+const (
+	// gateTrigger is the decision_context trigger of reports declined by
+	// a record gate.
+	gateTrigger = "evidra_gate"
+	// gateOverrideRefType marks reports of operations a human confirmed
+	// past a tripped gate.
+	gateOverrideRefType = "evidra_gate_override"
+	// exitCodeGateBlocked is returned when a gate refuses to run the
+	// wrapped command (EX_NOPERM), distinct from wrapped-command failures.
+	exitCodeGateBlocked = 77
+	// approvalGateRule names the gate a prescription that requires
+	// approval trips until an approver allows it.
+	approvalGateRule = "approval"
+	// insufficientDataBand is the score band of sessions with fewer than
+	// the profile's minimum operations. Their score is not meaningful, so
+	// band and score conditions never hold for them.
+	insufficientDataBand = "insufficient_data"
+)
+
+// approvalPollInterval is how often record re-reads the evidence chain
+// and the API while waiting for an approval.
+var approvalPollInterval = time.Second
+
+// gateRule trips when all of its conditions hold. Rules are written as
+// comma-separated conditions, e.g. "risk>=high,scope=production".
+type gateRule struct {
+	raw        string
+	conditions []gateCondition
+}
+
+type gateCondition struct {
+	field string // risk, scope, tag, band, score
+	op    string // >=, =, <
+	value string
+	// minScore is the score threshold for band and score conditions.
+	minScore float64
+}
+
+// gateFacts is what gate conditions are evaluated against.
+type gateFacts struct {
+	EffectiveRisk string
+	ScopeClass    string
+	RiskTags      []string
+	Score         float64
+	ScoreBand     string
+}
+
+// gateDecision describes a tripped gate.
+type gateDecision struct {
+	Rule      string `json:"rule"`
+	Reason    string `json:"reason"`
+	Confirmed bool   `json:"confirmed"`
+}
+
+func parseGateRules(raw []string, profile score.Profile) ([]gateRule, error) {
+	rules := make([]gateRule, 0, len(raw))
+	for _, r := range raw {
+		rule := gateRule{raw: strings.TrimSpace(r)}
+		for _, part := range strings.Split(r, ",") {
+			cond, err := parseGateCondition(strings.TrimSpace(part), profile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --gate %q: %w", r, err)
+			}
+			rule.conditions = append(rule.conditions, cond)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseGateCondition(s string, profile score.Profile) (gateCondition, error) {
+	for _, op := range []string{">=", "<", "="} {
+		field, value, ok := strings.Cut(s, op)
+		if !ok {
+			continue
+		}
+		c := gateCondition{field: strings.TrimSpace(field), op: op, value: strings.ToLower(strings.TrimSpace(value))}
+		switch {
+		case c.field == "risk" && op == ">=":
+			// Any recognized level outranks the empty one.
+			if !risk.SeverityHigherThan(c.value, "") {
+				return c, fmt.Errorf("unknown risk level %q (expected low, medium, high, or critical)", c.value)
+			}
+		case (c.field == "scope" || c.field == "tag") && op == "=":
+			if c.value == "" {
+				return c, fmt.Errorf("%s requires a value", c.field)
+			}
+		case c.field == "band" && op == "<":
+			found := false
+			for _, b := range profile.Bands {
+				if b.Name == c.value {
+					c.minScore, found = b.MinScore, true
+				}
+			}
+			if !found {
+				return c, fmt.Errorf("unknown score band %q", c.value)
+			}
+		case c.field == "score" && op == "<":
+			v, err := strconv.ParseFloat(c.value, 64)
+			if err != nil {
+				return c, fmt.Errorf("score threshold %q is not a number", c.value)
+			}
+			c.minScore = v
+		default:
+			return c, fmt.Errorf("unsupported condition %q (expected risk>=LEVEL, scope=CLASS, tag=TAG, band<BAND, or score<N)", s)
+		}
+		return c, nil
+	}
+	return gateCondition{}, fmt.Errorf("unsupported condition %q (expected risk>=LEVEL, scope=CLASS, tag=TAG, band<BAND, or score<N)", s)
+}
+
+// needsScore reports whether evaluating rules requires a session score.
+func needsScore(rules []gateRule) bool {
+	for _, r := range rules {
+		for _, c := range r.conditions {
+			if c.field == "band" || c.field == "score" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evaluateGates returns the first rule whose conditions all hold.
+func evaluateGates(rules []gateRule, facts gateFacts) (gateDecision, bool) {
+	for _, r := range rules {
+		var reasons []string
+		tripped := true
+		for _, c := range r.conditions {
+			reason, ok := c.holds(facts)
+			if !ok {
+				tripped = false
+				break
+			}
+			reasons = append(reasons, reason)
+		}
+		if tripped {
+			return gateDecision{Rule: r.raw, Reason: fmt.Sprintf("gate %q tripped: %s", r.raw, strings.Join(reasons, ", "))}, true
+		}
+	}
+	return gateDecision{}, false
+}
+
+func (c gateCondition) holds(f gateFacts) (string, bool) {
+	switch c.field {
+	case "risk":
+		if f.EffectiveRisk == c.value || risk.SeverityHigherThan(f.EffectiveRisk, c.value) {
+			return "effective_risk " + f.EffectiveRisk, true
+		}
+	case "scope":
+		if f.ScopeClass == c.value {
+			return "scope " + f.ScopeClass, true
+		}
+	case "tag":
+		for _, t := range f.RiskTags {
+			if strings.EqualFold(t, c.value) {
+				return "tag " + t, true
+			}
+		}
+	case "band", "score":
+		if f.ScoreBand == insufficientDataBand {
+			return "", false
+		}
+		if f.Score < c.minScore {
+			return fmt.Sprintf("session score %.1f (%s) below %s", f.Score, f.ScoreBand, c.value), true
+		}
+	}
+	return "", false
+}
+
+// gateFactsFor collects the prescription's risk facts across all risk inputs.
+func gateFactsFor(out lifecycle.PrescribeOutput) gateFacts {
+	facts := gateFacts{EffectiveRisk: out.EffectiveRisk, ScopeClass: out.ScopeClass}
+	for _, in := range out.RiskInputs {
+		facts.RiskTags = append(facts.RiskTags, in.RiskTags...)
+	}
+	return facts
+}
+
+// awaitApproval waits up to wait for a decision on a prescription that
+// requires approval. It returns the gate to apply, or nil once the
+// operation is approved. The gate cannot be confirmed past on the terminal:
+// only an approval entry whose signature verifies against the approver
+// keyring, by a key other than the prescription's, lets the command run.
+//
+// Decisions are read from the local evidence chain through svc and, when
+// remote is non-nil, from the API, where `evidra approve --remote` submits
+// them. The caller forwards the prescription before waiting so remote
+// approvers can see it. API errors are reported once and do not end the
+// wait.
+func awaitApproval(ctx context.Context, svc *lifecycle.Service, remote *client.Client, out lifecycle.PrescribeOutput, wait time.Duration, stderr io.Writer) (*gateDecision, error) {
+	deadline := time.Now().Add(wait)
+	announced, remoteFailed := false, false
+	for {
+		decision, err := svc.ApprovalDecision(out.SessionID, out.PrescriptionID)
+		if err != nil {
+			return nil, err
+		}
+		if decision == "" && remote != nil {
+			decision, err = remote.ApprovalDecision(ctx, out.SessionID, out.PrescriptionID)
+			if err != nil && !remoteFailed {
+				fmt.Fprintf(stderr, "warning: read approval from API: %v\n", err)
+				remoteFailed = true
+			}
+		}
+		switch decision {
+		case evidence.ApprovalApproved:
+			return nil, nil
+		case evidence.ApprovalRejected:
+			return &gateDecision{Rule: approvalGateRule, Reason: fmt.Sprintf("prescription %s was rejected by its approver", out.PrescriptionID)}, nil
+		}
+		if !time.Now().Before(deadline) {
+			return &gateDecision{Rule: approvalGateRule, Reason: fmt.Sprintf("prescription %s (effective_risk %s) requires approval and none was recorded", out.PrescriptionID, out.EffectiveRisk)}, nil
+		}
+		if !announced {
+			approveCmd := "evidra approve --prescription " + out.PrescriptionID
+			if remote != nil {
+				approveCmd += " --remote"
+			}
+			fmt.Fprintf(stderr, "evidra: waiting up to %s for approval: %s\n", wait, approveCmd)
+			announced = true
+		}
+		time.Sleep(approvalPollInterval)
+	}
+}
+
+// confirmGateHook asks a human whether to run past a tripped gate.
+var confirmGateHook = confirmOnTerminal
+
+// confirmOnTerminal prompts on stderr and reads the answer from stdin. A
+// stdin that is not a terminal never confirms, so unattended agents stay
+// blocked.
+func confirmOnTerminal(decision gateDecision, stderr io.Writer) bool {
+	if !isTerminal(os.Stdin) {
+		return false
+	}
+	fmt.Fprintf(stderr, "evidra: %s\nRun anyway? [y/N] ", decision.Reason)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
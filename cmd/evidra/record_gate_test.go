@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/score"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestParseGateRules(t *testing.T) {
+	t.Parallel()
+
+	profile, err := score.LoadDefaultProfile()
+	if err != nil {
+		t.Fatalf("LoadDefaultProfile: %v", err)
+	}
+	rules, err := parseGateRules([]string{"risk>=high, scope=production", "tag=k8s.privileged_container", "band<good", "score<80"}, profile)
+	if err != nil {
+		t.Fatalf("parseGateRules: %v", err)
+	}
+	if len(rules) != 4 || len(rules[0].conditions) != 2 || rules[2].conditions[0].minScore != 95 || rules[3].conditions[0].minScore != 80 {
+		t.Fatalf("rules = %+v", rules)
+	}
+	if !needsScore(rules) || needsScore(rules[:2]) {
+		t.Fatal("needsScore should only hold for band and score conditions")
+	}
+
+	for _, bad := range []string{"risk>=extreme", "band<legendary", "score<many", "risk=high", "owner=me", "scope="} {
+		if _, err := parseGateRules([]string{bad}, profile); err == nil {
+			t.Errorf("parseGateRules(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestEvaluateGates(t *testing.T) {
+	t.Parallel()
+
+	profile, _ := score.LoadDefaultProfile()
+	rules, err := parseGateRules([]string{"risk>=high,scope=production", "tag=ops.mass_delete", "band<good"}, profile)
+	if err != nil {
+		t.Fatalf("parseGateRules: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		facts    gateFacts
+		wantRule string
+	}{
+		{name: "critical in production", facts: gateFacts{EffectiveRisk: "critical", ScopeClass: "production", Score: 100}, wantRule: "risk>=high,scope=production"},
+		{name: "high in staging", facts: gateFacts{EffectiveRisk: "high", ScopeClass: "staging", Score: 100}},
+		{name: "tag fires", facts: gateFacts{EffectiveRisk: "low", RiskTags: []string{"ops.mass_delete"}, Score: 100}, wantRule: "tag=ops.mass_delete"},
+		{name: "score below band", facts: gateFacts{EffectiveRisk: "low", Score: 90, ScoreBand: "fair"}, wantRule: "band<good"},
+		{name: "insufficient data never trips score", facts: gateFacts{EffectiveRisk: "low", Score: -1, ScoreBand: "insufficient_data"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			decision, tripped := evaluateGates(rules, tt.facts)
+			if tripped != (tt.wantRule != "") || decision.Rule != tt.wantRule {
+				t.Fatalf("decision = %+v tripped=%v, want rule %q", decision, tripped, tt.wantRule)
+			}
+		})
+	}
+}
+
+func recordGatedDelete(t *testing.T, evidenceDir, marker string, extra ...string) (int, map[string]interface{}, string) {
+	t.Helper()
+	return recordDelete(t, evidenceDir, marker, []string{"risk>=high,scope=production"}, extra...)
+}
+
+func recordDelete(t *testing.T, evidenceDir, marker string, gates []string, extra ...string) (int, map[string]interface{}, string) {
+	t.Helper()
+	artifactPath := filepath.Join(t.TempDir(), "artifact.yaml")
+	if err := os.WriteFile(artifactPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: gated-cm\n  namespace: prod\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	args := []string{
+		"record",
+		"--tool", "kubectl",
+		"--operation", "delete",
+		"--artifact", artifactPath,
+		"--environment", "production",
+		"--evidence-dir", evidenceDir,
+		"--signing-key", testutil.TestSigningKeyBase64(t),
+	}
+	for _, g := range gates {
+		args = append(args, "--gate", g)
+	}
+	args = append(args, extra...)
+	args = append(args, "--", "sh", "-c", "touch "+marker)
+
+	var out, errBuf bytes.Buffer
+	code := run(args, &out, &errBuf)
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode output: %v (stderr=%s)", err, errBuf.String())
+	}
+	return code, result, errBuf.String()
+}
+
+func TestRecordScoreGateSkipsInsufficientData(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	marker := filepath.Join(tmp, "ran")
+	// A fresh session is far below the profile's minimum operations, so
+	// its score says nothing and must not block the command.
+	code, result, stderr := recordDelete(t, filepath.Join(tmp, "evidence"), marker, []string{"score<90", "band<good"})
+	if code != 0 {
+		t.Fatalf("record exit=%d want 0 (stderr=%s result=%#v)", code, stderr, result)
+	}
+	if _, ok := result["gate"]; ok {
+		t.Fatalf("gate tripped on insufficient data: %#v", result["gate"])
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("wrapped command did not run: %v", err)
+	}
+}
+
+func TestRecordGateBlocksBeforeExecution(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	marker := filepath.Join(tmp, "ran")
+	code, result, stderr := recordGatedDelete(t, evidenceDir, marker)
+	if code != exitCodeGateBlocked {
+		t.Fatalf("record exit=%d want %d (stderr=%s)", code, exitCodeGateBlocked, stderr)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("wrapped command ran despite gate: %v", err)
+	}
+	if result["verdict"] != string(evidence.VerdictDeclined) || result["ok"] != false {
+		t.Fatalf("result = %#v", result)
+	}
+	if _, ok := result["exit_code"]; ok {
+		t.Fatalf("declined result must not carry exit_code: %#v", result)
+	}
+
+	entries, err := evidence.ReadAllEntriesAtPath(evidenceDir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Type != evidence.EntryTypePrescribe || entries[1].Type != evidence.EntryTypeReport {
+		t.Fatalf("entries = %+v", entries)
+	}
+	var report evidence.ReportPayload
+	if err := json.Unmarshal(entries[1].Payload, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Verdict != evidence.VerdictDeclined || report.DecisionContext == nil || report.DecisionContext.Trigger != gateTrigger ||
+		!strings.Contains(report.DecisionContext.Reason, "effective_risk critical") {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestRecordGateConfirmRunsAndRecordsOverride(t *testing.T) {
+	// Not parallel: mutates package-level confirmGateHook.
+	original := confirmGateHook
+	confirmGateHook = func(gateDecision, io.Writer) bool { return true }
+	defer func() { confirmGateHook = original }()
+
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	marker := filepath.Join(tmp, "ran")
+	code, result, stderr := recordGatedDelete(t, evidenceDir, marker, "--confirm")
+	if code != 0 {
+		t.Fatalf("record exit=%d (stderr=%s)", code, stderr)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("wrapped command did not run: %v", err)
+	}
+	gate, _ := result["gate"].(map[string]interface{})
+	if gate["confirmed"] != true || result["verdict"] != string(evidence.VerdictSuccess) {
+		t.Fatalf("result = %#v", result)
+	}
+
+	entries, err := evidence.ReadAllEntriesAtPath(evidenceDir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	var report evidence.ReportPayload
+	if err := json.Unmarshal(entries[len(entries)-1].Payload, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if len(report.ExternalRefs) != 1 || report.ExternalRefs[0].Type != gateOverrideRefType {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestRecordGateWithoutConfirmationStaysBlocked(t *testing.T) {
+	// Not parallel: mutates package-level confirmGateHook.
+	original := confirmGateHook
+	confirmGateHook = func(gateDecision, io.Writer) bool { return false }
+	defer func() { confirmGateHook = original }()
+
+	tmp := t.TempDir()
+	marker := filepath.Join(tmp, "ran")
+	code, _, stderr := recordGatedDelete(t, filepath.Join(tmp, "evidence"), marker, "--confirm")
+	if code != exitCodeGateBlocked {
+		t.Fatalf("record exit=%d want %d (stderr=%s)", code, exitCodeGateBlocked, stderr)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("wrapped command ran despite refusal: %v", err)
+	}
+}
+
+func TestAwaitApprovalIgnoresKeysOutsideTheApproverKeyring(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	approver := testutil.TestSigner(t)
+	svc := lifecycle.NewService(lifecycle.Options{
+		EvidencePath: dir,
+		Signer:       testutil.TestSigner(t),
+		ApprovalRisk: "high",
+		Approvers:    evidence.NewKeyring(approver.PublicKey()),
+	})
+	out, err := svc.Prescribe(context.Background(), lifecycle.PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "cli"},
+		Tool:        "kubectl",
+		Operation:   "delete",
+		RawArtifact: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n"),
+		SessionID:   "sess-1",
+	})
+	if err != nil || !out.ApprovalRequired {
+		t.Fatalf("Prescribe = %+v, %v; want approval_required", out, err)
+	}
+
+	// The agent approves its own operation with a fresh key and no keyring.
+	if _, err := lifecycle.NewService(lifecycle.Options{EvidencePath: dir, Signer: testutil.TestSigner(t)}).Approve(context.Background(), lifecycle.ApproveInput{
+		PrescriptionID: out.PrescriptionID,
+		Decision:       evidence.ApprovalApproved,
+		Actor:          evidence.Actor{ID: "agent-1"},
+	}); err != nil {
+		t.Fatalf("Approve with fresh key: %v", err)
+	}
+	gate, err := awaitApproval(context.Background(), svc, nil, out, 0, io.Discard)
+	if err != nil {
+		t.Fatalf("awaitApproval: %v", err)
+	}
+	if gate == nil || gate.Rule != approvalGateRule {
+		t.Fatalf("gate = %+v, want the approval gate", gate)
+	}
+
+	if _, err := lifecycle.NewService(lifecycle.Options{EvidencePath: dir, Signer: approver}).Approve(context.Background(), lifecycle.ApproveInput{
+		PrescriptionID: out.PrescriptionID,
+		Decision:       evidence.ApprovalApproved,
+		Actor:          evidence.Actor{ID: "alice"},
+	}); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if gate, err := awaitApproval(context.Background(), svc, nil, out, 0, io.Discard); err != nil || gate != nil {
+		t.Fatalf("awaitApproval after listed approver = %+v, %v; want approved", gate, err)
+	}
+}
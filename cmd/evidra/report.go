@@ -43,6 +43,7 @@ type reportFlags struct {
 type reportCommand struct {
 	service      *lifecycle.Service
 	evidencePath string
+	signer       evidence.Signer
 	input        lifecycle.ReportInput
 }
 
@@ -107,6 +108,9 @@ func cmdReport(args []string, stdout, stderr io.Writer) int {
 	if reportOut.DecisionContext != nil {
 		result["decision_context"] = reportOut.DecisionContext
 	}
+	if reportOut.Late {
+		result["late_report"] = true
+	}
 	snapshot, err := assessment.BuildAtPathWithProfile(cmd.evidencePath, reportOut.SessionID, profile)
 	if err != nil {
 		fmt.Fprintf(stderr, "report assessment: %v\n", err)
@@ -118,12 +122,15 @@ func cmdReport(args []string, stdout, stderr io.Writer) int {
 	result["signal_summary"] = snapshot.SignalSummary
 	result["basis"] = snapshot.Basis
 	result["confidence"] = snapshot.Confidence
+	if err := emitOperationSpan(context.Background(), cmd.evidencePath, reportOut.Entry, snapshot.SignalSummary); err != nil {
+		fmt.Fprintf(stderr, "warning: trace export failed: %v\n", err)
+	}
 	code = writeJSON(stdout, stderr, "encode report", result)
 	if code != 0 {
 		return code
 	}
 
-	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, reportOut.SessionID, stderr)
+	forwardEvidence(opts.url, opts.apiKey, opts.offline, opts.fallbackOffline, opts.timeout, cmd.evidencePath, cmd.signer, stderr)
 	return 0
 }
 
@@ -241,7 +248,7 @@ func validateReportVerdict(stderr io.Writer, verdict evidence.Verdict, exitCodeF
 }
 
 func prepareReportCommand(opts reportFlags) (reportCommand, error) {
-	svc, evidencePath, _, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode)
+	svc, evidencePath, signer, err := newLifecycleServiceForCommand(opts.evidenceDir, opts.signingKey, opts.signingKeyPath, opts.signingMode, "", "")
 	if err != nil {
 		return reportCommand{}, err
 	}
@@ -267,6 +274,7 @@ func prepareReportCommand(opts reportFlags) (reportCommand, error) {
 	return reportCommand{
 		service:      svc,
 		evidencePath: evidencePath,
+		signer:       signer,
 		input: lifecycle.ReportInput{
 			PrescriptionID:  opts.prescriptionID,
 			Verdict:         opts.verdict,
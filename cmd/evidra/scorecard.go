@@ -12,7 +12,6 @@ import (
 	"samebits.com/evidra/internal/pipeline"
 	"samebits.com/evidra/internal/score"
 	"samebits.com/evidra/internal/signal"
-	"samebits.com/evidra/pkg/evidence"
 	"samebits.com/evidra/pkg/version"
 )
 
@@ -72,13 +71,12 @@ func cmdScorecard(args []string, stdout, stderr io.Writer) int {
 
 	evidencePath := resolveEvidencePath(*evidenceFlag)
 
-	entries, err := evidence.ReadAllEntriesAtPath(evidencePath)
+	filtered, err := queryEntries(evidencePath, *actorFlag, *periodFlag, *sessionIDFlag)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error reading evidence: %v\n", err)
 		return 1
 	}
 
-	filtered := filterEntries(entries, *actorFlag, *periodFlag, *sessionIDFlag)
 	signalEntries, err := pipeline.EvidenceToSignalEntries(filtered)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error converting evidence: %v\n", err)
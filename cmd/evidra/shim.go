@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/transcript"
+)
+
+const (
+	// shimMarker identifies generated shims, so install never overwrites
+	// and the PATH lookup never resolves to another shim.
+	shimMarker = "# evidra-shim"
+	// shimActiveEnv is set while a shimmed command runs. Nested calls —
+	// helm invoking kubectl, or record's own discovery and read-back — go
+	// straight to the real tool.
+	shimActiveEnv = "EVIDRA_SHIM_ACTIVE"
+	// shimSessionEnv groups every intercepted invocation into one session.
+	shimSessionEnv = "EVIDRA_SESSION_ID"
+	// shimActorEnv overrides the actor ID recorded for intercepted calls.
+	shimActorEnv = "EVIDRA_ACTOR"
+)
+
+var defaultShimTools = []string{"kubectl", "oc", "helm", "terraform"}
+
+// shimReadOnlyVerbs lists subcommands that never change infrastructure.
+// They run without prescribing. A verb mapped to a non-nil set is
+// read-only only with one of those sub-verbs (`terraform state list`).
+var shimReadOnlyVerbs = map[string]map[string]map[string]bool{
+	"kubectl": kubectlReadOnlyVerbs,
+	"oc":      kubectlReadOnlyVerbs,
+	"helm": {
+		"list": nil, "ls": nil, "status": nil, "get": nil, "history": nil, "template": nil,
+		"show": nil, "inspect": nil, "search": nil, "repo": nil, "version": nil, "lint": nil,
+		"env": nil, "dependency": nil, "pull": nil, "package": nil, "verify": nil,
+		"completion": nil, "help": nil,
+	},
+	"terraform": {
+		"plan": nil, "show": nil, "validate": nil, "fmt": nil, "output": nil, "version": nil,
+		"providers": nil, "graph": nil, "init": nil, "console": nil, "get": nil, "help": nil,
+		"state":     {"list": true, "show": true, "pull": true},
+		"workspace": {"list": true, "show": true},
+	},
+	"docker": {
+		"ps": nil, "images": nil, "logs": nil, "inspect": nil, "version": nil, "info": nil,
+		"events": nil, "stats": nil, "top": nil, "history": nil, "search": nil, "login": nil,
+		"logout": nil, "context": nil, "help": nil,
+		"compose": {"ps": true, "logs": true, "config": true, "ls": true, "images": true, "top": true, "version": true},
+	},
+	"pulumi": {
+		"preview": nil, "whoami": nil, "version": nil, "about": nil, "logs": nil, "login": nil,
+		"logout": nil, "config": nil, "help": nil,
+		"stack": {"ls": true, "output": true, "history": true, "export": true, "select": true},
+	},
+	"argocd": {
+		"version": nil, "login": nil, "logout": nil, "context": nil, "account": nil, "completion": nil,
+		"app": {"get": true, "list": true, "diff": true, "history": true, "manifests": true, "logs": true, "resources": true, "wait": true},
+	},
+	"kustomize": {"version": nil, "help": nil, "completion": nil, "cfg": nil},
+}
+
+var kubectlReadOnlyVerbs = map[string]map[string]bool{
+	"get": nil, "describe": nil, "logs": nil, "explain": nil, "api-resources": nil, "api-versions": nil,
+	"version": nil, "cluster-info": nil, "top": nil, "config": nil, "diff": nil, "wait": nil,
+	"events": nil, "completion": nil, "plugin": nil, "options": nil, "help": nil, "kustomize": nil,
+	"whoami": nil, "status": nil, "projects": nil,
+	"auth": {"can-i": true, "whoami": true},
+}
+
+// shimInteractiveVerbs lists subcommands that attach the caller's
+// terminal. Shims run them recorded but with the real stdio, so no
+// transcript is captured.
+var shimInteractiveVerbs = map[string]map[string]bool{
+	"kubectl": kubectlInteractiveVerbs,
+	"oc":      {"exec": true, "attach": true, "edit": true, "port-forward": true, "proxy": true, "debug": true, "rsh": true},
+	"docker":  {"exec": true, "run": true, "attach": true},
+}
+
+var kubectlInteractiveVerbs = map[string]bool{
+	"exec": true, "attach": true, "edit": true, "port-forward": true, "proxy": true, "debug": true,
+}
+
+var dockerGlobalValueFlags = map[string]bool{"context": true, "config": true, "host": true, "log-level": true}
+
+func cmdShim(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printShimUsage(stderr)
+		return 2
+	}
+
+	switch args[0] {
+	case "help", "--help", "-h":
+		printShimUsage(stdout)
+		return 0
+	case "install":
+		return runShimInstall(args[1:], stdout, stderr)
+	case "uninstall":
+		return runShimUninstall(args[1:], stdout, stderr)
+	case "exec":
+		return runShimExec(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown shim subcommand: %s\n", args[0])
+		printShimUsage(stderr)
+		return 2
+	}
+}
+
+// runShimInstall writes one wrapper script per tool into --dir. Each script
+// hands its arguments to `evidra shim exec`.
+func runShimInstall(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("shim install", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dirFlag := fs.String("dir", "", "Directory to write shims to; put it first on the agent's PATH")
+	toolsFlag := fs.String("tools", strings.Join(defaultShimTools, ","), "Comma-separated tools to intercept")
+	evidraFlag := fs.String("evidra", "", "evidra binary the shims call (default: this executable)")
+	forceFlag := fs.Bool("force", false, "Overwrite existing files that are not evidra shims")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*dirFlag) == "" {
+		fmt.Fprintln(stderr, "shim install requires --dir")
+		return 2
+	}
+	tools, err := parseShimTools(*toolsFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "shim install: %v\n", err)
+		return 2
+	}
+
+	evidraPath := *evidraFlag
+	if evidraPath == "" {
+		if evidraPath, err = os.Executable(); err != nil {
+			fmt.Fprintf(stderr, "shim install: resolve evidra binary: %v\n", err)
+			return 1
+		}
+	}
+	evidraPath, err = filepath.Abs(evidraPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "shim install: %v\n", err)
+		return 1
+	}
+	dir, err := filepath.Abs(*dirFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "shim install: %v\n", err)
+		return 1
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Fprintf(stderr, "shim install: create %s: %v\n", dir, err)
+		return 1
+	}
+
+	var installed []string
+	for _, tool := range tools {
+		path := filepath.Join(dir, tool)
+		if !*forceFlag {
+			if existing, err := os.ReadFile(path); err == nil && !isShimScript(existing) {
+				fmt.Fprintf(stderr, "shim install: %s exists and is not an evidra shim; pass --force to replace it\n", path)
+				return 1
+			}
+		}
+		if err := os.WriteFile(path, shimScript(evidraPath, dir, tool), 0o755); err != nil {
+			fmt.Fprintf(stderr, "shim install: %v\n", err)
+			return 1
+		}
+		installed = append(installed, path)
+	}
+
+	return writeJSON(stdout, stderr, "encode shim install", map[string]interface{}{
+		"ok":     true,
+		"dir":    dir,
+		"tools":  tools,
+		"shims":  installed,
+		"evidra": evidraPath,
+		"path":   "export PATH=" + shellQuote(dir) + ":$PATH",
+	})
+}
+
+// runShimUninstall removes the shims in --dir, leaving other files alone.
+func runShimUninstall(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("shim uninstall", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dirFlag := fs.String("dir", "", "Directory the shims were installed to")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*dirFlag) == "" {
+		fmt.Fprintln(stderr, "shim uninstall requires --dir")
+		return 2
+	}
+	entries, err := os.ReadDir(*dirFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "shim uninstall: %v\n", err)
+		return 1
+	}
+	removed := []string{}
+	for _, e := range entries {
+		path := filepath.Join(*dirFlag, e.Name())
+		if e.IsDir() || !fileIsShim(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			fmt.Fprintf(stderr, "shim uninstall: %v\n", err)
+			return 1
+		}
+		removed = append(removed, path)
+	}
+	return writeJSON(stdout, stderr, "encode shim uninstall", map[string]interface{}{
+		"ok":      true,
+		"removed": removed,
+	})
+}
+
+// runShimExec is what a generated shim runs. Read-only verbs and nested
+// calls go straight to the real tool; everything else is recorded.
+func runShimExec(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("shim exec", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	shimDirFlag := fs.String("shim-dir", "", "Directory holding the shim, skipped when resolving the real tool")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fmt.Fprintln(stderr, "shim exec requires a tool")
+		return 2
+	}
+	tool, toolArgs := rest[0], rest[1:]
+
+	realPath, err := lookRealTool(tool, *shimDirFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "evidra shim: %v\n", err)
+		return 127
+	}
+	wrapped := append([]string{realPath}, toolArgs...)
+
+	if os.Getenv(shimActiveEnv) != "" {
+		return runShimPassthrough(wrapped, stdout, stderr, nil)
+	}
+	verb, readOnly := classifyShimInvocation(tool, toolArgs)
+	if readOnly {
+		return runShimPassthrough(wrapped, stdout, stderr, nil)
+	}
+
+	// Children inherit the marker, so nothing below records twice.
+	if err := os.Setenv(shimActiveEnv, "1"); err != nil {
+		return runShimPassthrough(wrapped, stdout, stderr, err)
+	}
+	defer os.Unsetenv(shimActiveEnv)
+
+	// Capturing output routes it through a pipe, which would take the
+	// terminal away from an interactive command.
+	interactive := shimInteractiveVerbs[tool][verb] || isTerminal(os.Stdin) || isTerminal(stdout)
+	opts := recordFlags{
+		tool:            tool,
+		operation:       verb,
+		environment:     strings.TrimSpace(os.Getenv("EVIDRA_ENVIRONMENT")),
+		actorID:         firstNonEmpty(strings.TrimSpace(os.Getenv(shimActorEnv)), "shim"),
+		sessionID:       strings.TrimSpace(os.Getenv(shimSessionEnv)),
+		captureOutput:   !interactive,
+		captureMaxBytes: transcript.DefaultMaxBytes,
+		discover:        true,
+		shim:            true,
+		url:             os.Getenv("EVIDRA_URL"),
+		apiKey:          os.Getenv("EVIDRA_API_KEY"),
+		timeout:         30 * time.Second,
+	}
+	return runRecord(opts, wrapped, stdout, stderr)
+}
+
+// runShimPassthrough runs the real tool unrecorded with the caller's stdio.
+// A non-nil cause is why recording was skipped; the command still runs so
+// the shim never breaks an agent's workflow.
+func runShimPassthrough(wrapped []string, stdout, stderr io.Writer, cause error) int {
+	if cause != nil {
+		fmt.Fprintf(stderr, "evidra shim: running %s unrecorded: %v\n", filepath.Base(wrapped[0]), cause)
+	}
+	cmd := exec.Command(wrapped[0], wrapped[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(stderr, "evidra shim: %v\n", err)
+		return 127
+	}
+	return 0
+}
+
+// isTerminal reports whether stream is a file attached to a terminal.
+func isTerminal(stream interface{}) bool {
+	f, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// classifyShimInvocation returns the subcommand and whether it is read-only.
+// Invocations without a subcommand (`kubectl --help`) are read-only.
+func classifyShimInvocation(tool string, args []string) (string, bool) {
+	var p parsedArgs
+	switch tool {
+	case "kubectl", "oc":
+		p = parseToolArgs(args, kubectlValueFlags, kubectlAliases)
+	case "helm":
+		p = parseToolArgs(args, helmValueFlags, helmAliases)
+	case "docker":
+		p = parseToolArgs(args, dockerGlobalValueFlags, map[string]string{"c": "context", "H": "host", "l": "log-level"})
+	default:
+		p = parseToolArgs(args, nil, nil)
+	}
+	if len(p.positionals) == 0 {
+		return "", true
+	}
+	verb := p.positionals[0]
+	subVerbs, listed := shimReadOnlyVerbs[tool][verb]
+	if !listed {
+		return verb, false
+	}
+	if subVerbs == nil {
+		return verb, true
+	}
+	if len(p.positionals) < 2 {
+		return verb, true
+	}
+	if tool == "docker" || tool == "argocd" {
+		// record names these operations by their second word.
+		return p.positionals[1], subVerbs[p.positionals[1]]
+	}
+	return verb, subVerbs[p.positionals[1]]
+}
+
+// lookRealTool finds tool on PATH, skipping shimDir and any other shim.
+func lookRealTool(tool, shimDir string) (string, error) {
+	skip := ""
+	if shimDir != "" {
+		skip = canonicalDir(shimDir)
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || (skip != "" && canonicalDir(dir) == skip) {
+			continue
+		}
+		candidate := filepath.Join(dir, tool)
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+			continue
+		}
+		if fileIsShim(candidate) {
+			continue
+		}
+		return candidate, nil
+	}
+	return "", fmt.Errorf("%s not found on PATH outside the shim directory", tool)
+}
+
+func canonicalDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Clean(dir)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+func parseShimTools(raw string) ([]string, error) {
+	seen := map[string]bool{}
+	var tools []string
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		if _, ok := shimReadOnlyVerbs[t]; !ok {
+			supported := make([]string, 0, len(shimReadOnlyVerbs))
+			for name := range shimReadOnlyVerbs {
+				supported = append(supported, name)
+			}
+			sort.Strings(supported)
+			return nil, fmt.Errorf("unsupported tool %q (supported: %s)", t, strings.Join(supported, ", "))
+		}
+		seen[t] = true
+		tools = append(tools, t)
+	}
+	if len(tools) == 0 {
+		return nil, errors.New("--tools is empty")
+	}
+	return tools, nil
+}
+
+func shimScript(evidraPath, dir, tool string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "#!/bin/sh")
+	fmt.Fprintf(&b, "%s: %s\n", shimMarker, tool)
+	fmt.Fprintf(&b, "# Generated by `evidra shim install`; routes %s through `evidra record`.\n", tool)
+	fmt.Fprintf(&b, "exec %s shim exec --shim-dir %s %s \"$@\"\n", shellQuote(evidraPath), shellQuote(dir), tool)
+	return b.Bytes()
+}
+
+func isShimScript(content []byte) bool {
+	return bytes.Contains(content, []byte("\n"+shimMarker+":"))
+}
+
+// fileIsShim reads just enough of path to find the marker line.
+func fileIsShim(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 256)
+	n, _ := io.ReadFull(f, head)
+	return isShimScript(head[:n])
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func printShimUsage(w io.Writer) {
+	fmt.Fprintln(w, "evidra shim <subcommand>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "SUBCOMMANDS:")
+	fmt.Fprintln(w, "  install    Write wrapper executables that route infra CLIs through record")
+	fmt.Fprintln(w, "  uninstall  Remove the wrappers from a directory")
+	fmt.Fprintln(w, "  exec       Run one intercepted invocation (called by the wrappers)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "FLAGS (install):")
+	fmt.Fprintln(w, "  --dir     Directory to write shims to; put it first on the agent's PATH")
+	fmt.Fprintf(w, "  --tools   Comma-separated tools to intercept (default: %s)\n", strings.Join(defaultShimTools, ","))
+	fmt.Fprintln(w, "  --evidra  evidra binary the shims call (default: this executable)")
+	fmt.Fprintln(w, "  --force   Overwrite existing files that are not evidra shims")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestShimInstallAndUninstall(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "shims")
+	install := func(extra ...string) (int, string) {
+		var out, errBuf bytes.Buffer
+		args := append([]string{"shim", "install", "--dir", dir, "--evidra", "/opt/evidra it's"}, extra...)
+		code := run(args, &out, &errBuf)
+		return code, out.String() + errBuf.String()
+	}
+
+	if code, msg := install("--tools", "kubectl,terraform"); code != 0 {
+		t.Fatalf("shim install exit=%d: %s", code, msg)
+	}
+	script, err := os.ReadFile(filepath.Join(dir, "kubectl"))
+	if err != nil {
+		t.Fatalf("read shim: %v", err)
+	}
+	if !isShimScript(script) || !strings.Contains(string(script), `exec '/opt/evidra it'\''s' shim exec --shim-dir '`+dir+`' kubectl "$@"`) {
+		t.Fatalf("shim script = %q", script)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "terraform")); info == nil || info.Mode()&0o111 == 0 {
+		t.Fatal("terraform shim missing or not executable")
+	}
+
+	// Reinstalling over shims is fine; foreign files need --force.
+	if code, msg := install("--tools", "kubectl"); code != 0 {
+		t.Fatalf("reinstall exit=%d: %s", code, msg)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "helm"), []byte("#!/bin/sh\necho real helm\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if code, msg := install("--tools", "helm"); code != 1 || !strings.Contains(msg, "--force") {
+		t.Fatalf("install over foreign file exit=%d: %s", code, msg)
+	}
+	if code, msg := install("--tools", "kubectl,make"); code != 2 || !strings.Contains(msg, `unsupported tool "make"`) {
+		t.Fatalf("install unsupported tool exit=%d: %s", code, msg)
+	}
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"shim", "uninstall", "--dir", dir}, &out, &errBuf); code != 0 {
+		t.Fatalf("shim uninstall exit=%d stderr=%s", code, errBuf.String())
+	}
+	for _, name := range []string{"kubectl", "terraform"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s shim not removed", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "helm")); err != nil {
+		t.Errorf("uninstall removed a file it did not install: %v", err)
+	}
+}
+
+func TestClassifyShimInvocation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tool     string
+		args     []string
+		verb     string
+		readOnly bool
+	}{
+		{"kubectl", []string{"get", "pods", "-n", "prod"}, "get", true},
+		{"kubectl", []string{"-n", "prod", "delete", "pod", "web"}, "delete", false},
+		{"kubectl", []string{"--context=prod", "apply", "-f", "x.yaml"}, "apply", false},
+		{"kubectl", []string{"auth", "can-i", "create", "pods"}, "auth", true},
+		{"kubectl", []string{"auth", "reconcile", "-f", "rbac.yaml"}, "auth", false},
+		{"kubectl", []string{"--help"}, "", true},
+		{"helm", []string{"upgrade", "--install", "web", "./chart"}, "upgrade", false},
+		{"helm", []string{"-n", "prod", "status", "web"}, "status", true},
+		{"terraform", []string{"-chdir=infra", "plan"}, "plan", true},
+		{"terraform", []string{"state", "rm", "aws_instance.web"}, "state", false},
+		{"terraform", []string{"state", "list"}, "state", true},
+		{"docker", []string{"compose", "down"}, "down", false},
+		{"docker", []string{"compose", "ps"}, "ps", true},
+		{"argocd", []string{"app", "sync", "web"}, "sync", false},
+	}
+	for _, tt := range tests {
+		verb, readOnly := classifyShimInvocation(tt.tool, tt.args)
+		if verb != tt.verb || readOnly != tt.readOnly {
+			t.Errorf("classifyShimInvocation(%s %v) = (%q, %v), want (%q, %v)", tt.tool, tt.args, verb, readOnly, tt.verb, tt.readOnly)
+		}
+	}
+}
+
+func TestShimExecRecordsMutatingCommands(t *testing.T) {
+	// Not parallel: mutates PATH and EVIDRA_* environment variables.
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	shimDir := filepath.Join(tmp, "shims")
+	realDir := filepath.Join(tmp, "real")
+	for _, d := range []string{shimDir, realDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(shimDir, "kubectl"), shimScript("/nonexistent/evidra", shimDir, "kubectl"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(realDir, "kubectl"), []byte("#!/bin/sh\necho \"real kubectl $*\"\n[ -z \"$BREAK_EVIDENCE\" ] || { rm -rf \"$EVIDRA_EVIDENCE_DIR\"; : > \"$EVIDRA_EVIDENCE_DIR\"; }\nexit 3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	artifactPath := filepath.Join(tmp, "cm.yaml")
+	if err := os.WriteFile(artifactPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: shimmed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+realDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("EVIDRA_EVIDENCE_DIR", evidenceDir)
+	t.Setenv("EVIDRA_SIGNING_KEY", testutil.TestSigningKeyBase64(t))
+	t.Setenv(shimSessionEnv, "agent-session-1")
+	t.Setenv(shimActiveEnv, "")
+
+	if got, err := lookRealTool("kubectl", shimDir); err != nil || got != filepath.Join(realDir, "kubectl") {
+		t.Fatalf("lookRealTool = %q, %v", got, err)
+	}
+
+	shimExec := func(args ...string) (int, string) {
+		t.Helper()
+		var out, errBuf bytes.Buffer
+		code := run(append([]string{"shim", "exec", "--shim-dir", shimDir, "kubectl"}, args...), &out, &errBuf)
+		return code, out.String()
+	}
+
+	code, out := shimExec("apply", "-f", artifactPath)
+	if code != 3 || out != "real kubectl apply -f "+artifactPath+"\n" {
+		t.Fatalf("shim apply exit=%d stdout=%q, want the real tool's exit code and output only", code, out)
+	}
+	entries, err := evidence.ReadAllEntriesAtPath(evidenceDir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	recorded := len(entries)
+	var sawPrescription bool
+	for _, e := range entries {
+		if e.Type != evidence.EntryTypePrescribe {
+			continue
+		}
+		sawPrescription = true
+		if e.SessionID != "agent-session-1" || e.Actor.ID != "shim" {
+			t.Fatalf("prescription session=%q actor=%q", e.SessionID, e.Actor.ID)
+		}
+		var p evidence.PrescriptionPayload
+		_ = json.Unmarshal(e.Payload, &p)
+		if !strings.Contains(string(p.CanonicalAction), "shimmed") {
+			t.Fatalf("prescription did not use the discovered artifact: %s", p.CanonicalAction)
+		}
+	}
+	if !sawPrescription {
+		t.Fatal("mutating verb was not prescribed")
+	}
+	if os.Getenv(shimActiveEnv) != "" {
+		t.Fatalf("%s leaked after the shim returned", shimActiveEnv)
+	}
+
+	// Read-only verbs and nested calls run without recording.
+	if code, out := shimExec("get", "pods"); code != 3 || out != "real kubectl get pods\n" {
+		t.Fatalf("shim get exit=%d stdout=%q", code, out)
+	}
+	t.Setenv(shimActiveEnv, "1")
+	if code, _ := shimExec("delete", "pod", "web"); code != 3 {
+		t.Fatalf("nested shim exit=%d", code)
+	}
+	entries, err = evidence.ReadAllEntriesAtPath(evidenceDir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	if len(entries) != recorded {
+		t.Fatalf("entries = %d after passthrough calls, want %d", len(entries), recorded)
+	}
+
+	// A report that cannot be written does not change the exit code.
+	t.Setenv(shimActiveEnv, "")
+	t.Setenv("BREAK_EVIDENCE", "1")
+	var shimOut, errBuf bytes.Buffer
+	code = run([]string{"shim", "exec", "--shim-dir", shimDir, "kubectl", "apply", "-f", artifactPath}, &shimOut, &errBuf)
+	if code != 3 || !strings.Contains(errBuf.String(), "evidra shim: warning:") {
+		t.Fatalf("shim apply with failing report exit=%d stderr=%q, want 3 and a warning", code, errBuf.String())
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func cmdStore(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: evidra store <import|export> [flags]")
+		return 2
+	}
+
+	switch args[0] {
+	case "import":
+		return cmdStoreCopy("import", args[1:], stdout, stderr)
+	case "export":
+		return cmdStoreCopy("export", args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown store subcommand: %s\n", args[0])
+		return 2
+	}
+}
+
+// cmdStoreCopy moves a validated chain between store formats. import copies
+// --from into the evidence store; export copies the evidence store to --to.
+// Either side may be a JSONL segment directory or a sqlite: URI, and the
+// destination must be empty.
+func cmdStoreCopy(direction string, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("store "+direction, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence store (directory or sqlite:<path>)")
+	otherFlag := fs.String(map[string]string{"import": "from", "export": "to"}[direction], "",
+		"Other store (directory or sqlite:<path>)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	other := strings.TrimSpace(*otherFlag)
+	if other == "" {
+		if direction == "import" {
+			fmt.Fprintln(stderr, "store import requires --from")
+		} else {
+			fmt.Fprintln(stderr, "store export requires --to")
+		}
+		return 2
+	}
+
+	src, dst := other, resolveEvidencePath(*evidenceFlag)
+	if direction == "export" {
+		src, dst = dst, src
+	}
+	n, err := evidence.CopyEntries(dst, src)
+	if err != nil {
+		fmt.Fprintf(stderr, "store %s: %v\n", direction, err)
+		return 1
+	}
+	return writeJSON(stdout, stderr, "encode store result", map[string]interface{}{
+		"ok":      true,
+		"from":    src,
+		"to":      dst,
+		"entries": n,
+	})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+)
+
+func TestCmdStore_ImportScorecardExport(t *testing.T) {
+	t.Parallel()
+	signingKey := testutil.TestSigningKeyBase64(t)
+	jsonlDir := t.TempDir()
+	pubKeyPath := writeTestPublicKeyPEM(t, t.TempDir(), signingKey)
+	recordTestOperation(t, jsonlDir, signingKey)
+	recordTestOperation(t, jsonlDir, signingKey)
+
+	db := "sqlite://" + filepath.Join(t.TempDir(), "evidence.db")
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"store", "import", "--from", jsonlDir, "--evidence-dir", db}, &out, &errBuf); code != 0 {
+		t.Fatalf("store import exit %d: %s", code, errBuf.String())
+	}
+	var result struct {
+		OK      bool `json:"ok"`
+		Entries int  `json:"entries"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode import result: %v", err)
+	}
+	if !result.OK || result.Entries != 2 {
+		t.Fatalf("import result = %+v", result)
+	}
+
+	// Appends go straight to the database.
+	recordTestOperation(t, db, signingKey)
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"validate", "--evidence-dir", db, "--public-key", pubKeyPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("validate exit %d: %s", code, errBuf.String())
+	}
+
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"scorecard", "--evidence-dir", db}, &out, &errBuf); code != 0 {
+		t.Fatalf("scorecard exit %d: %s", code, errBuf.String())
+	}
+	var scorecard struct {
+		TotalOperations int `json:"total_operations"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &scorecard); err != nil {
+		t.Fatalf("decode scorecard: %v", err)
+	}
+	if scorecard.TotalOperations != 3 {
+		t.Fatalf("total_operations = %d, want 3", scorecard.TotalOperations)
+	}
+
+	exportDir := t.TempDir()
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"store", "export", "--evidence-dir", db, "--to", exportDir}, &out, &errBuf); code != 0 {
+		t.Fatalf("store export exit %d: %s", code, errBuf.String())
+	}
+	out.Reset()
+	errBuf.Reset()
+	if code := run([]string{"validate", "--evidence-dir", exportDir, "--public-key", pubKeyPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("validate export exit %d: %s", code, errBuf.String())
+	}
+
+	errBuf.Reset()
+	if code := run([]string{"store", "export", "--evidence-dir", db, "--to", exportDir}, &out, &errBuf); code != 1 {
+		t.Fatalf("export into non-empty store exit %d, want 1", code)
+	}
+	if !strings.Contains(errBuf.String(), "not empty") {
+		t.Fatalf("stderr = %q", errBuf.String())
+	}
+}
+
+func TestCmdStore_Usage(t *testing.T) {
+	t.Parallel()
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"store"}, &out, &errBuf); code != 2 {
+		t.Fatalf("store exit %d, want 2", code)
+	}
+	if code := run([]string{"store", "import"}, &out, &errBuf); code != 2 {
+		t.Fatalf("store import without --from exit %d, want 2", code)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/outbox"
+	"samebits.com/evidra/pkg/mode"
+)
+
+// cmdSync pushes every local entry the API has not acknowledged yet,
+// retrying with exponential backoff, and records a receipt entry locally.
+func cmdSync(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence directory")
+	urlFlag := fs.String("url", os.Getenv("EVIDRA_URL"), "Evidra API URL")
+	apiKeyFlag := fs.String("api-key", os.Getenv("EVIDRA_API_KEY"), "Evidra API key")
+	timeoutFlag := fs.Duration("timeout", 30*time.Second, "API request timeout")
+	attemptsFlag := fs.Int("max-attempts", 5, "Attempts before giving up (exponential backoff between them)")
+	batchSizeFlag := fs.Int("batch-size", 100, "Entries per batch request")
+	resetFlag := fs.Bool("reset", false, "Forget the acknowledged position and resend the whole chain")
+	signingKeyFlag := fs.String("signing-key", "", "Base64-encoded Ed25519 signing key (signs receipt entries)")
+	signingKeyPathFlag := fs.String("signing-key-path", "", "Path to PEM-encoded Ed25519 signing key")
+	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*urlFlag) == "" {
+		fmt.Fprintln(stderr, "sync requires --url or EVIDRA_URL")
+		return 2
+	}
+
+	resolved, err := mode.Resolve(mode.Config{
+		URL:     *urlFlag,
+		APIKey:  *apiKeyFlag,
+		Timeout: *timeoutFlag,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve mode: %v\n", err)
+		return 2
+	}
+	signer, err := resolveSigner(*signingKeyFlag, *signingKeyPathFlag, *signingModeFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "resolve signer: %v\n", err)
+		return 1
+	}
+
+	evidencePath := resolveEvidencePath(*evidenceFlag)
+	if *resetFlag {
+		if err := outbox.ResetCursor(evidencePath, resolved.Client.URL()); err != nil {
+			fmt.Fprintf(stderr, "reset outbox cursor: %v\n", err)
+			return 1
+		}
+	}
+
+	syncer := &outbox.Syncer{
+		EvidencePath: evidencePath,
+		Client:       resolved.Client,
+		Signer:       signer,
+		BatchSize:    *batchSizeFlag,
+	}
+	res, err := syncer.Sync(context.Background(), *attemptsFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "sync: %v (%d sent, %d pending)\n", err, res.Sent, res.Pending)
+		return 1
+	}
+	for _, r := range res.Rejected {
+		fmt.Fprintf(stderr, "warning: API rejected entry %s: %s %s\n", r.EntryID, r.Code, r.Message)
+	}
+	return writeJSON(stdout, stderr, "encode sync result", map[string]interface{}{
+		"ok":               true,
+		"url":              res.URL,
+		"sent":             res.Sent,
+		"pending":          res.Pending,
+		"rejected":         len(res.Cursor.Rejected),
+		"acked":            res.Cursor.Acked,
+		"last_hash":        res.Cursor.LastHash,
+		"receipt_entry_id": res.ReceiptEntryID,
+	})
+}
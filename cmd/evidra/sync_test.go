@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"samebits.com/evidra/internal/outbox"
+	testutil "samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestRunSync_PushesQueuedEntriesAndWritesReceipt(t *testing.T) {
+	t.Parallel()
+
+	signingKey := testutil.TestSigningKeyBase64(t)
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	artifactPath := filepath.Join(tmp, "artifact.json")
+	if err := os.WriteFile(artifactPath, []byte(`{"noop":true}`), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	// Written offline, so the entry is only queued locally.
+	writeSuccessfulPrescription(t, signingKey, evidenceDir, artifactPath, "session-sync")
+
+	var (
+		mu      sync.Mutex
+		batches []int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() { _ = r.Body.Close() }()
+		if r.URL.Path != "/v1/evidence/batch" {
+			t.Errorf("path = %q, want /v1/evidence/batch", r.URL.Path)
+		}
+		var body struct {
+			Entries []json.RawMessage `json:"entries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode batch request: %v", err)
+		}
+		mu.Lock()
+		batches = append(batches, len(body.Entries))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]int{"accepted": len(body.Entries)})
+	}))
+	defer server.Close()
+
+	syncArgs := []string{
+		"sync",
+		"--evidence-dir", evidenceDir,
+		"--url", server.URL,
+		"--api-key", "test-key",
+		"--signing-key", signingKey,
+	}
+	var out, errBuf bytes.Buffer
+	if code := run(syncArgs, &out, &errBuf); code != 0 {
+		t.Fatalf("sync exit %d: %s", code, errBuf.String())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode sync output: %v", err)
+	}
+	if result["sent"] != float64(1) || result["pending"] != float64(0) || result["acked"] != float64(1) {
+		t.Fatalf("sync result = %#v", result)
+	}
+	receiptID, _ := result["receipt_entry_id"].(string)
+	receipt, ok, err := evidence.FindEntryByID(evidenceDir, receiptID)
+	if err != nil || !ok {
+		t.Fatalf("receipt entry %q not found: %v", receiptID, err)
+	}
+	if receipt.Type != evidence.EntryTypeReceipt {
+		t.Fatalf("receipt type = %q", receipt.Type)
+	}
+
+	cursor, err := outbox.LoadCursor(evidenceDir, server.URL)
+	if err != nil {
+		t.Fatalf("LoadCursor: %v", err)
+	}
+	if cursor.Acked != 1 || cursor.LastHash != result["last_hash"] {
+		t.Fatalf("cursor = %+v", cursor)
+	}
+
+	// The second run pushes only the receipt and resumes from the cursor.
+	out.Reset()
+	if code := run(syncArgs, &out, &errBuf); code != 0 {
+		t.Fatalf("second sync exit %d: %s", code, errBuf.String())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 2 || batches[0] != 1 || batches[1] != 1 {
+		t.Fatalf("batch sizes = %v, want [1 1]", batches)
+	}
+}
+
+func TestRunSync_RequiresURL(t *testing.T) {
+	t.Setenv("EVIDRA_URL", "")
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"sync", "--evidence-dir", t.TempDir()}, &out, &errBuf); code != 2 {
+		t.Fatalf("exit = %d, want 2", code)
+	}
+}
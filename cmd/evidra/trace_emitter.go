@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	"samebits.com/evidra/internal/config"
+	"samebits.com/evidra/internal/telemetry"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// emitOperationSpan exports the span of the operation the report closes
+// when EVIDRA_TRACES_OTLP_ENDPOINT is set, parented to TRACEPARENT.
+func emitOperationSpan(ctx context.Context, evidencePath string, report evidence.EvidenceEntry, signals map[string]int) error {
+	cfg, err := config.ResolveTracingConfig("", "")
+	if err != nil || !cfg.Enabled() {
+		return err
+	}
+	cfg.ServiceName = "evidra-cli"
+
+	span, err := telemetry.OperationSpanAtPath(evidencePath, report, signals)
+	if err != nil {
+		return err
+	}
+	span.TraceParent = config.ResolveTraceParent("")
+
+	exporter, err := telemetry.NewSpanExporter(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = exporter.Close()
+	}()
+	if err := exporter.ExportOperation(ctx, span); err != nil {
+		return err
+	}
+	return exporter.Flush(ctx)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"samebits.com/evidra/internal/testutil"
+)
+
+// newSpanReceiver stands in for an OTLP/HTTP collector and returns the
+// spans it received so far.
+func newSpanReceiver(t *testing.T) (*httptest.Server, func() []*tracepb.Span) {
+	t.Helper()
+	var mu sync.Mutex
+	var spans []*tracepb.Span
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(data, &req); err != nil {
+			t.Errorf("unmarshal protobuf: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+		mu.Unlock()
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver, func() []*tracepb.Span {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*tracepb.Span(nil), spans...)
+	}
+}
+
+func TestReportExportsOperationSpan(t *testing.T) {
+	receiver, received := newSpanReceiver(t)
+	t.Setenv("EVIDRA_TRACES_OTLP_ENDPOINT", receiver.URL+"/v1/traces")
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	signingKey := testutil.TestSigningKeyBase64(t)
+
+	var out, errBuf bytes.Buffer
+	if code := run([]string{
+		"prescribe",
+		"--tool", "kubectl",
+		"--operation", "apply",
+		"--artifact", writeApprovalArtifact(t, tmp),
+		"--evidence-dir", evidenceDir,
+		"--signing-key", signingKey,
+	}, &out, &errBuf); code != 0 {
+		t.Fatalf("prescribe exit=%d stderr=%s", code, errBuf.String())
+	}
+	var rx struct {
+		PrescriptionID string `json:"prescription_id"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &rx); err != nil {
+		t.Fatalf("decode prescribe output: %v", err)
+	}
+	if n := len(received()); n != 0 {
+		t.Fatalf("prescribe exported %d spans, want none before the report", n)
+	}
+
+	out.Reset()
+	if code := run([]string{
+		"report",
+		"--prescription", rx.PrescriptionID,
+		"--verdict", "success",
+		"--exit-code", "0",
+		"--evidence-dir", evidenceDir,
+		"--signing-key", signingKey,
+	}, &out, &errBuf); code != 0 {
+		t.Fatalf("report exit=%d stderr=%s", code, errBuf.String())
+	}
+	if bytes.Contains(errBuf.Bytes(), []byte("trace export failed")) {
+		t.Fatalf("stderr=%s", errBuf.String())
+	}
+
+	spans := received()
+	if len(spans) != 1 {
+		t.Fatalf("spans=%d want 1", len(spans))
+	}
+	if got := hex.EncodeToString(spans[0].ParentSpanId); got != "00f067aa0ba902b7" {
+		t.Fatalf("parent_span_id=%s, want TRACEPARENT's", got)
+	}
+	if spans[0].Name != "kubectl apply" {
+		t.Fatalf("name=%q", spans[0].Name)
+	}
+}
+
+func TestRecordExportsSpanWhenCommandFailsToStart(t *testing.T) {
+	receiver, received := newSpanReceiver(t)
+	t.Setenv("EVIDRA_TRACES_OTLP_ENDPOINT", receiver.URL+"/v1/traces")
+
+	tmp := t.TempDir()
+	var out, errBuf bytes.Buffer
+	if code := run([]string{
+		"record",
+		"--tool", "kubectl",
+		"--operation", "apply",
+		"--artifact", writeApprovalArtifact(t, tmp),
+		"--evidence-dir", filepath.Join(tmp, "evidence"),
+		"--signing-key", testutil.TestSigningKeyBase64(t),
+		"--", filepath.Join(tmp, "missing-binary"),
+	}, &out, &errBuf); code == 0 {
+		t.Fatalf("record of a missing command exited 0, stderr=%s", errBuf.String())
+	}
+	if bytes.Contains(errBuf.Bytes(), []byte("trace export failed")) {
+		t.Fatalf("stderr=%s", errBuf.String())
+	}
+
+	spans := received()
+	if len(spans) != 1 {
+		t.Fatalf("spans=%d want 1 for the failed start", len(spans))
+	}
+	if spans[0].Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR {
+		t.Fatalf("status=%v want error", spans[0].Status)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// cmdTranscript prints the captured output attached to a report, verifying
+// the blob against the digest the signed report recorded.
+func cmdTranscript(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("transcript", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence store (directory or sqlite:<path>)")
+	reportIDFlag := fs.String("report-id", "", "Report whose transcript to print")
+	digestFlag := fs.String("digest", "", "Transcript blob digest (sha256:<hex>)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	reportID := strings.TrimSpace(*reportIDFlag)
+	digest := strings.TrimSpace(*digestFlag)
+	if (reportID == "") == (digest == "") {
+		fmt.Fprintln(stderr, "transcript: exactly one of --report-id or --digest is required")
+		return 2
+	}
+
+	evidencePath := resolveEvidencePath(*evidenceFlag)
+	if reportID != "" {
+		ref, err := findReportTranscript(evidencePath, reportID)
+		if err != nil {
+			fmt.Fprintf(stderr, "transcript: %v\n", err)
+			return 1
+		}
+		digest = ref.Digest
+	}
+
+	data, err := evidence.ReadBlobAtPath(evidencePath, digest)
+	if err != nil {
+		fmt.Fprintf(stderr, "transcript: %v\n", err)
+		return 1
+	}
+	if _, err := stdout.Write(data); err != nil {
+		fmt.Fprintf(stderr, "transcript: write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func findReportTranscript(evidencePath, reportID string) (*evidence.TranscriptRef, error) {
+	entries, err := evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		Types: []evidence.EntryType{evidence.EntryTypeReport},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		var payload evidence.ReportPayload
+		if err := json.Unmarshal(entry.Payload, &payload); err != nil {
+			continue
+		}
+		// Commands print the report's entry ID as report_id.
+		if entry.EntryID != reportID && payload.ReportID != reportID {
+			continue
+		}
+		if payload.Transcript == nil {
+			return nil, fmt.Errorf("report %s has no transcript", reportID)
+		}
+		return payload.Transcript, nil
+	}
+	return nil, fmt.Errorf("report %s not found", reportID)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func recordWithOutput(t *testing.T, evidenceDir, script string, extra ...string) map[string]interface{} {
+	t.Helper()
+	artifactPath := filepath.Join(t.TempDir(), "artifact.yaml")
+	if err := os.WriteFile(artifactPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: transcript-cm\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	args := []string{
+		"record",
+		"--tool", "kubectl",
+		"--operation", "apply",
+		"--artifact", artifactPath,
+		"--environment", "staging",
+		"--evidence-dir", evidenceDir,
+		"--signing-key", testutil.TestSigningKeyBase64(t),
+	}
+	args = append(args, extra...)
+	args = append(args, "--", "sh", "-c", script)
+
+	var out, errBuf bytes.Buffer
+	if code := run(args, &out, &errBuf); code != 0 {
+		t.Fatalf("record exit=%d stderr=%s", code, errBuf.String())
+	}
+	if !strings.Contains(errBuf.String(), "configmap/transcript-cm configured") {
+		t.Fatalf("wrapped output not passed through: %q", errBuf.String())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	return result
+}
+
+func TestRecordCapturesRedactedTranscript(t *testing.T) {
+	t.Parallel()
+
+	evidenceDir := filepath.Join(t.TempDir(), "evidence")
+	result := recordWithOutput(t, evidenceDir, "echo configmap/transcript-cm configured; echo password=hunter22 >&2")
+	ref, _ := result["transcript"].(map[string]interface{})
+	if ref == nil || ref["redactions"] != float64(1) {
+		t.Fatalf("result transcript = %#v", result["transcript"])
+	}
+
+	entries, err := evidence.ReadAllEntriesAtPath(evidenceDir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	var report evidence.ReportPayload
+	if err := json.Unmarshal(entries[len(entries)-1].Payload, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Transcript == nil || report.Transcript.Digest != ref["digest"] {
+		t.Fatalf("report transcript = %+v", report.Transcript)
+	}
+
+	var out, errBuf bytes.Buffer
+	reportID, _ := result["report_id"].(string)
+	if code := run([]string{"transcript", "--evidence-dir", evidenceDir, "--report-id", reportID}, &out, &errBuf); code != 0 {
+		t.Fatalf("transcript exit=%d stderr=%s", code, errBuf.String())
+	}
+	if got := out.String(); !strings.Contains(got, "configmap/transcript-cm configured") ||
+		!strings.Contains(got, "password=[REDACTED]") || strings.Contains(got, "hunter22") {
+		t.Fatalf("transcript = %q", got)
+	}
+}
+
+func TestRecordCaptureOutputDisabled(t *testing.T) {
+	t.Parallel()
+
+	evidenceDir := filepath.Join(t.TempDir(), "evidence")
+	result := recordWithOutput(t, evidenceDir, "echo configmap/transcript-cm configured", "--capture-output=false")
+	if _, ok := result["transcript"]; ok {
+		t.Fatalf("transcript captured with --capture-output=false: %#v", result)
+	}
+	if _, err := os.Stat(evidence.BlobDir(evidenceDir)); !os.IsNotExist(err) {
+		t.Fatalf("blob dir exists: %v", err)
+	}
+}
+
+func TestTranscriptCommandRequiresOneSelector(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{
+		{"transcript"},
+		{"transcript", "--report-id", "r", "--digest", "sha256:" + strings.Repeat("0", 64)},
+	} {
+		var out, errBuf bytes.Buffer
+		if code := run(args, &out, &errBuf); code != 2 {
+			t.Fatalf("%v exit=%d want 2", args, code)
+		}
+	}
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"os"
+	"strings"
+	"time"
 
+	"samebits.com/evidra/internal/anchor"
 	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/pkg/client"
 	"samebits.com/evidra/pkg/evidence"
 )
 
@@ -13,10 +20,27 @@ func cmdValidate(args []string, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	evidenceFlag := fs.String("evidence-dir", "", "Evidence directory")
-	pubKeyFlag := fs.String("public-key", "", "PEM file with Ed25519 public key (enables signature verification)")
+	var pubKeyFlags multiStringFlag
+	fs.Var(&pubKeyFlags, "public-key", "PEM file with Ed25519 public key (repeatable; enables signature verification)")
+	keyringFlag := fs.String("keyring", "", "Keyring file: JWKS JSON or concatenated PEM public keys (enables signature verification)")
+	headsFlag := fs.String("published-heads", "", "Published heads file to check chain consistency against (default: <evidence-dir>/"+anchor.PublishedHeadsFileName+" if present)")
+	offlineFlag := fs.Bool("offline", false, "Check published heads without fetching their entries from the transparency log")
+	remoteFlag := fs.Bool("remote", false, "Validate the chains stored by the API server instead of the local evidence directory")
+	urlFlag := fs.String("url", os.Getenv("EVIDRA_URL"), "Evidra API URL (with --remote)")
+	apiKeyFlag := fs.String("api-key", os.Getenv("EVIDRA_API_KEY"), "Evidra API key (with --remote)")
+	chainFlag := fs.String("chain", "", "Validate only this server chain ID (with --remote)")
+	timeoutFlag := fs.Duration("timeout", 2*time.Minute, "API or transparency log request timeout")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
+	if *remoteFlag {
+		if strings.TrimSpace(*urlFlag) == "" {
+			fmt.Fprintln(stderr, "validate --remote requires --url or EVIDRA_URL")
+			return 2
+		}
+		c := client.New(client.Config{URL: strings.TrimRight(*urlFlag, "/"), APIKey: *apiKeyFlag, Timeout: *timeoutFlag})
+		return validateRemote(context.Background(), c, *chainFlag, stdout, stderr)
+	}
 
 	evidencePath := resolveEvidencePath(*evidenceFlag)
 	if err := evidence.ValidateChainAtPath(evidencePath); err != nil {
@@ -24,19 +48,166 @@ func cmdValidate(args []string, stdout, stderr io.Writer) int {
 		return 1
 	}
 
-	if *pubKeyFlag != "" {
-		pubKey, err := ievsigner.LoadPublicKeyPEM(*pubKeyFlag)
+	keyring, err := loadValidateKeyring(pubKeyFlags, *keyringFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "load public key: %v\n", err)
+		return 1
+	}
+	if keyring != nil {
+		// Keys introduced by verified key_rotation entries are also trusted
+		// for checkpoint signatures.
+		keyring, err = evidence.TrustedKeysAtPath(evidencePath, keyring)
 		if err != nil {
-			fmt.Fprintf(stderr, "load public key: %v\n", err)
+			fmt.Fprintf(stderr, "signature validation failed: %v\n", err)
 			return 1
 		}
-		if err := evidence.ValidateChainWithSignatures(evidencePath, pubKey); err != nil {
-			fmt.Fprintf(stderr, "signature validation failed: %v\n", err)
+	}
+
+	logTimeout := *timeoutFlag
+	if *offlineFlag {
+		logTimeout = 0
+	}
+	headsChecked, code := validatePublishedHeads(evidencePath, *headsFlag, keyring, logTimeout, stderr)
+	if code != 0 {
+		return code
+	}
+
+	summary := "chain valid: hashes verified (no public key provided, signatures not checked)"
+	if keyring != nil {
+		summary = "chain valid: hashes and signatures verified"
+	}
+	if headsChecked > 0 {
+		summary += fmt.Sprintf("; consistent with %d published head(s)", headsChecked)
+		if *offlineFlag {
+			summary += " (transparency log not consulted)"
+		} else {
+			summary += " confirmed by the transparency log"
+		}
+	}
+	fmt.Fprintln(stdout, summary)
+	if staged, err := evidence.StagedCountAtPath(evidencePath); err == nil && staged > 0 {
+		fmt.Fprintf(stderr, "warning: %d staged entries are not yet sealed into the chain; the next write with their signing key seals them\n", staged)
+	}
+	if corrupt, err := evidence.QuarantinedCountAtPath(evidencePath); err == nil && corrupt > 0 {
+		fmt.Fprintf(stderr, "warning: %d staged files could not be parsed and were set aside as staging/*.corrupt; they are not in the chain\n", corrupt)
+	}
+	return 0
+}
+
+// validateRemote asks the API to re-verify each stored chain (or only
+// chainID) and prints one line per chain. Any invalid chain fails.
+func validateRemote(ctx context.Context, c *client.Client, chainID string, stdout, stderr io.Writer) int {
+	ids := []string{chainID}
+	writers := map[string]string{}
+	if chainID == "" {
+		chains, err := c.Chains(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "list chains: %v\n", err)
 			return 1
 		}
-		fmt.Fprintln(stdout, "chain valid: hashes and signatures verified")
-	} else {
-		fmt.Fprintln(stdout, "chain valid: hashes verified (no public key provided, signatures not checked)")
+		ids = ids[:0]
+		for _, ch := range chains {
+			ids = append(ids, ch.ChainID)
+			writers[ch.ChainID] = ch.Writer
+		}
 	}
+
+	invalid := 0
+	for _, id := range ids {
+		report, err := c.ValidateChain(ctx, id)
+		if err != nil {
+			fmt.Fprintf(stderr, "validate chain %s: %v\n", id, err)
+			return 1
+		}
+		label := id
+		if w := writers[id]; w != "" {
+			label += " (" + w + ")"
+		}
+		if !report.Valid {
+			invalid++
+			fmt.Fprintf(stdout, "chain %s: invalid at position %d (entry %s): %s: %s\n",
+				label, report.FailedSeq, report.FailedEntryID, report.Code, report.Error)
+			continue
+		}
+		line := fmt.Sprintf("chain %s: valid, %d entries", label, report.Length)
+		if report.Unverifiable > 0 {
+			line += fmt.Sprintf(" (%d stored without raw bytes, hashes not recomputed)", report.Unverifiable)
+		}
+		if report.Detached {
+			line += "; detached: first entry links to an entry the server never received"
+		}
+		fmt.Fprintln(stdout, line)
+	}
+
+	if invalid > 0 {
+		fmt.Fprintf(stderr, "chain validation failed: %d of %d chain(s) invalid\n", invalid, len(ids))
+		return 1
+	}
+	fmt.Fprintf(stdout, "%d chain(s) valid\n", len(ids))
 	return 0
 }
+
+// loadValidateKeyring merges --public-key and --keyring inputs. It returns
+// nil when no key material was provided.
+func loadValidateKeyring(pubKeyPaths []string, keyringPath string) (*evidence.Keyring, error) {
+	if len(pubKeyPaths) == 0 && keyringPath == "" {
+		return nil, nil
+	}
+	keyring := evidence.NewKeyring()
+	if keyringPath != "" {
+		loaded, err := ievsigner.LoadKeyring(keyringPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, kid := range loaded.KeyIDs() {
+			pub, _ := loaded.Lookup(kid)
+			keyring.Add(pub)
+		}
+	}
+	for _, path := range pubKeyPaths {
+		pub, err := ievsigner.LoadPublicKeyPEM(path)
+		if err != nil {
+			return nil, err
+		}
+		keyring.Add(pub)
+	}
+	return keyring, nil
+}
+
+// validatePublishedHeads checks the chain against previously published tree
+// heads. An explicit heads file must exist; the default file is optional.
+// Unless logTimeout is zero, each head is first confirmed against the entry
+// its transparency log holds, so a regenerated heads file is detected.
+func validatePublishedHeads(evidencePath, headsPath string, keyring *evidence.Keyring, logTimeout time.Duration, stderr io.Writer) (int, int) {
+	explicit := headsPath != ""
+	if !explicit {
+		headsPath = anchor.DefaultPublishedHeadsPath(evidencePath)
+	}
+	records, err := anchor.ReadPublishedHeads(headsPath)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return 0, 0
+		}
+		fmt.Fprintf(stderr, "read published heads: %v\n", err)
+		return 0, 1
+	}
+	if logTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), logTimeout)
+		err := anchor.VerifyLogged(ctx, records, logTimeout)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(stderr, "transparency log verification failed: %v\n", err)
+			return 0, 1
+		}
+	}
+	heads, err := anchor.TreeHeads(records, keyring)
+	if err != nil {
+		fmt.Fprintf(stderr, "published head verification failed: %v\n", err)
+		return 0, 1
+	}
+	if err := evidence.ValidatePublishedHeadsAtPath(evidencePath, heads); err != nil {
+		fmt.Fprintf(stderr, "published head consistency failed: %v\n", err)
+		return 0, 1
+	}
+	return len(heads), 0
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunValidate_Remote(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/evidence/chains":
+			_, _ = w.Write([]byte(`{"chains":[{"chain_id":"c1","writer":"key:k1"},{"chain_id":"c2","writer":"actor:agent/a"}]}`))
+		case "POST /v1/evidence/chains/c1/validate":
+			_, _ = w.Write([]byte(`{"chain_id":"c1","valid":true,"length":4,"checked":4}`))
+		case "POST /v1/evidence/chains/c2/validate":
+			_, _ = w.Write([]byte(`{"chain_id":"c2","valid":false,"length":3,"failed_seq":2,"failed_entry_id":"e2","code":"hash_mismatch","error":"stored bytes changed"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  []string
+	}{
+		{
+			name:     "all chains",
+			wantCode: 1,
+			wantOut:  []string{"chain c1 (key:k1): valid, 4 entries", "chain c2 (actor:agent/a): invalid at position 2 (entry e2): hash_mismatch"},
+		},
+		{
+			name:     "one chain",
+			args:     []string{"--chain", "c1"},
+			wantCode: 0,
+			wantOut:  []string{"chain c1: valid, 4 entries", "1 chain(s) valid"},
+		},
+		{
+			name:     "unknown chain",
+			args:     []string{"--chain", "nope"},
+			wantCode: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out, errBuf bytes.Buffer
+			args := append([]string{"validate", "--remote", "--url", server.URL, "--api-key", "k"}, tt.args...)
+			if code := run(args, &out, &errBuf); code != tt.wantCode {
+				t.Fatalf("validate exit %d, want %d: %s", code, tt.wantCode, errBuf.String())
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
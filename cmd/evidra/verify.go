@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/internal/verify"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
+)
+
+// verifyRunnerHook runs read-back commands; tests replace it.
+var verifyRunnerHook verify.CommandRunner = verify.ExecRunner
+
+const defaultVerifyTimeout = time.Minute
+
+// verifyTarget is a prescription located in the store, with the report
+// that closed it when one exists.
+type verifyTarget struct {
+	prescription evidence.EvidenceEntry
+	action       canon.CanonicalAction
+	reportID     string
+}
+
+// cmdVerify reads back the live state a prescription touched and records a
+// verification entry comparing it with the prescription.
+func cmdVerify(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	prescriptionFlag := fs.String("prescription-id", "", "Prescription to verify")
+	artifactFlag := fs.String("artifact", "", "Prescribed artifact; enables field comparison (must match the prescribed digest)")
+	evidenceFlag := fs.String("evidence-dir", "", "Evidence directory")
+	contextFlag := fs.String("context", "", "kubeconfig context for kubectl/oc read-backs")
+	chdirFlag := fs.String("chdir", "", "Terraform working directory")
+	timeoutFlag := fs.Duration("timeout", defaultVerifyTimeout, "Read-back timeout")
+	signingKeyFlag := fs.String("signing-key", "", "Base64-encoded Ed25519 signing key")
+	signingKeyPathFlag := fs.String("signing-key-path", "", "Path to PEM-encoded Ed25519 signing key")
+	signingModeFlag := fs.String("signing-mode", "", "Signing mode: strict (default) or optional")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	prescriptionID := strings.TrimSpace(*prescriptionFlag)
+	if prescriptionID == "" {
+		fmt.Fprintln(stderr, "verify: --prescription-id is required")
+		return 2
+	}
+
+	signer, err := resolveSigner(*signingKeyFlag, *signingKeyPathFlag, *signingModeFlag)
+	if err != nil {
+		fmt.Fprintf(stderr, "verify: resolve signer: %v\n", err)
+		return 2
+	}
+	var artifact []byte
+	if *artifactFlag != "" {
+		if artifact, err = os.ReadFile(*artifactFlag); err != nil {
+			fmt.Fprintf(stderr, "verify: read artifact: %v\n", err)
+			return 2
+		}
+	}
+
+	evidencePath := resolveEvidencePath(*evidenceFlag)
+	target, err := loadVerifyTarget(evidencePath, prescriptionID)
+	if err != nil {
+		fmt.Fprintf(stderr, "verify: %v\n", err)
+		return 1
+	}
+	if artifact != nil && canon.SHA256Hex(artifact) != target.prescription.ArtifactDigest {
+		fmt.Fprintln(stderr, "verify: --artifact does not match the prescribed artifact digest")
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeoutFlag)
+	defer cancel()
+	payload, entry, err := recordVerification(ctx, evidencePath, signer, target, verify.Request{
+		Artifact:    artifact,
+		KubeContext: *contextFlag,
+		Dir:         *chdirFlag,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "verify: %v\n", err)
+		return 1
+	}
+
+	if code := writeJSON(stdout, stderr, "encode verify", map[string]interface{}{
+		"ok":           payload.Status == evidence.VerificationMatched,
+		"entry_id":     entry.EntryID,
+		"verification": payload,
+	}); code != 0 {
+		return code
+	}
+	if payload.Status != evidence.VerificationMatched {
+		return 1
+	}
+	return 0
+}
+
+// loadVerifyTarget finds the prescription and its first report.
+func loadVerifyTarget(evidencePath, prescriptionID string) (verifyTarget, error) {
+	entries, err := evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		Types: []evidence.EntryType{evidence.EntryTypePrescribe, evidence.EntryTypeReport},
+	})
+	if err != nil {
+		return verifyTarget{}, err
+	}
+	var target verifyTarget
+	found := false
+	for _, e := range entries {
+		switch e.Type {
+		case evidence.EntryTypePrescribe:
+			if e.EntryID != prescriptionID {
+				continue
+			}
+			var p evidence.PrescriptionPayload
+			if err := json.Unmarshal(e.Payload, &p); err != nil {
+				return verifyTarget{}, fmt.Errorf("decode prescription %s: %w", e.EntryID, err)
+			}
+			if err := json.Unmarshal(p.CanonicalAction, &target.action); err != nil {
+				return verifyTarget{}, fmt.Errorf("decode canonical action of %s: %w", e.EntryID, err)
+			}
+			target.prescription = e
+			found = true
+		case evidence.EntryTypeReport:
+			// report_id is the report's entry ID, as commands print it.
+			var r evidence.ReportPayload
+			if target.reportID == "" && json.Unmarshal(e.Payload, &r) == nil && r.PrescriptionID == prescriptionID {
+				target.reportID = e.EntryID
+			}
+		}
+	}
+	if !found {
+		return verifyTarget{}, fmt.Errorf("prescription %s not found", prescriptionID)
+	}
+	return target, nil
+}
+
+// recordVerification fills req from the prescription, runs the read-back
+// and appends the verification entry next to the prescription's session
+// and trace.
+func recordVerification(ctx context.Context, evidencePath string, signer evidence.Signer, target verifyTarget, req verify.Request) (evidence.VerificationPayload, evidence.EvidenceEntry, error) {
+	req.Tool = target.action.Tool
+	req.OperationClass = target.action.OperationClass
+	req.Resources = target.action.ResourceIdentity
+	if !verify.Supported(req.Tool) {
+		return evidence.VerificationPayload{}, evidence.EvidenceEntry{}, fmt.Errorf("no read-back for tool %q", req.Tool)
+	}
+
+	payload, err := verify.Verify(ctx, verifyRunnerHook, req)
+	if err != nil {
+		return evidence.VerificationPayload{}, evidence.EvidenceEntry{}, err
+	}
+	payload.PrescriptionID = target.prescription.EntryID
+	payload.ReportID = target.reportID
+
+	raw, _ := json.Marshal(payload) // best-effort: struct is always marshalable
+	p := target.prescription
+	entry, err := evidence.AppendAtPath(evidencePath, evidence.EntryBuildParams{
+		Type:            evidence.EntryTypeVerification,
+		SessionID:       p.SessionID,
+		OperationID:     p.OperationID,
+		TraceID:         p.TraceID,
+		Actor:           p.Actor,
+		IntentDigest:    p.IntentDigest,
+		ArtifactDigest:  p.ArtifactDigest,
+		Payload:         raw,
+		ScopeDimensions: p.ScopeDimensions,
+		SpecVersion:     version.SpecVersion,
+		AdapterVersion:  version.Version,
+		ScoringVersion:  version.ScoringVersion,
+		Signer:          signer,
+	})
+	if err != nil {
+		return evidence.VerificationPayload{}, evidence.EvidenceEntry{}, fmt.Errorf("append verification: %w", err)
+	}
+	return payload, entry, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+const verifyArtifact = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: verify-cm\n  namespace: staging\ndata:\n  mode: blue\n"
+
+// stubReadBack makes kubectl read-backs return live, restoring the real
+// runner when the test ends.
+func stubReadBack(t *testing.T, live string) *[]string {
+	t.Helper()
+	var calls []string
+	original := verifyRunnerHook
+	verifyRunnerHook = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return []byte(live), nil
+	}
+	t.Cleanup(func() { verifyRunnerHook = original })
+	return &calls
+}
+
+func recordConfigMapApply(t *testing.T, evidenceDir string, extra ...string) map[string]interface{} {
+	t.Helper()
+	artifactPath := filepath.Join(t.TempDir(), "artifact.yaml")
+	if err := os.WriteFile(artifactPath, []byte(verifyArtifact), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	args := []string{
+		"record",
+		"--tool", "kubectl",
+		"--operation", "apply",
+		"--artifact", artifactPath,
+		"--environment", "staging",
+		"--evidence-dir", evidenceDir,
+		"--signing-key", testutil.TestSigningKeyBase64(t),
+	}
+	args = append(args, extra...)
+	args = append(args, "--", "sh", "-c", "exit 0")
+
+	var out, errBuf bytes.Buffer
+	if code := run(args, &out, &errBuf); code != 0 {
+		t.Fatalf("record exit=%d stderr=%s", code, errBuf.String())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	return result
+}
+
+func verificationEntries(t *testing.T, evidenceDir string) []evidence.VerificationPayload {
+	t.Helper()
+	entries, err := evidence.QueryEntriesAtPath(evidenceDir, evidence.EntryQuery{
+		Types: []evidence.EntryType{evidence.EntryTypeVerification},
+	})
+	if err != nil {
+		t.Fatalf("QueryEntriesAtPath: %v", err)
+	}
+	out := make([]evidence.VerificationPayload, len(entries))
+	for i, e := range entries {
+		if err := json.Unmarshal(e.Payload, &out[i]); err != nil {
+			t.Fatalf("decode verification: %v", err)
+		}
+	}
+	return out
+}
+
+func TestRecordVerifyRecordsMatchedState(t *testing.T) {
+	// Not parallel: mutates package-level verifyRunnerHook.
+	calls := stubReadBack(t, verifyArtifact+"  extra: defaulted\n")
+
+	evidenceDir := filepath.Join(t.TempDir(), "evidence")
+	result := recordConfigMapApply(t, evidenceDir, "--verify")
+	if len(*calls) != 1 || (*calls)[0] != "kubectl get configmap/verify-cm -o yaml --ignore-not-found --namespace staging" {
+		t.Fatalf("read-backs = %v", *calls)
+	}
+	v, _ := result["verification"].(map[string]interface{})
+	if v["status"] != evidence.VerificationMatched {
+		t.Fatalf("result verification = %#v", result["verification"])
+	}
+	got := verificationEntries(t, evidenceDir)
+	if len(got) != 1 || got[0].PrescriptionID != result["prescription_id"] || got[0].ReportID != result["report_id"] || !got[0].FieldsCompared {
+		t.Fatalf("verification entries = %+v, result = %v", got, result)
+	}
+}
+
+func TestVerifyCommandRecordsStateDrift(t *testing.T) {
+	// Not parallel: mutates package-level verifyRunnerHook.
+	stubReadBack(t, strings.Replace(verifyArtifact, "blue", "green", 1))
+
+	tmp := t.TempDir()
+	evidenceDir := filepath.Join(tmp, "evidence")
+	result := recordConfigMapApply(t, evidenceDir)
+	if _, ok := result["verification"]; ok {
+		t.Fatalf("record verified without --verify: %#v", result)
+	}
+	prescriptionID := result["prescription_id"].(string)
+
+	wrongArtifact := filepath.Join(tmp, "other.yaml")
+	if err := os.WriteFile(wrongArtifact, []byte("kind: ConfigMap\n"), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	var out, errBuf bytes.Buffer
+	if code := run([]string{"verify", "--evidence-dir", evidenceDir, "--prescription-id", prescriptionID, "--artifact", wrongArtifact,
+		"--signing-key", testutil.TestSigningKeyBase64(t)}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "does not match") {
+		t.Fatalf("verify with wrong artifact exit=%d stderr=%s", code, errBuf.String())
+	}
+
+	artifactPath := filepath.Join(tmp, "artifact.yaml")
+	if err := os.WriteFile(artifactPath, []byte(verifyArtifact), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	out.Reset()
+	errBuf.Reset()
+	code := run([]string{"verify", "--evidence-dir", evidenceDir, "--prescription-id", prescriptionID, "--artifact", artifactPath,
+		"--signing-key", testutil.TestSigningKeyBase64(t)}, &out, &errBuf)
+	if code != 1 {
+		t.Fatalf("verify exit=%d want 1 (stderr=%s)", code, errBuf.String())
+	}
+	got := verificationEntries(t, evidenceDir)
+	if len(got) != 1 || got[0].Status != evidence.VerificationMismatched || got[0].Resources[0].Differences[0] != "data.mode" {
+		t.Fatalf("verification entries = %+v (stderr=%s)", got, errBuf.String())
+	}
+
+	out.Reset()
+	if code := run([]string{"explain", "--evidence-dir", evidenceDir}, &out, &errBuf); code != 0 {
+		t.Fatalf("explain exit=%d stderr=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"state_drift": 1`) {
+		t.Fatalf("explain output lacks state_drift: %s", out.String())
+	}
+}
@@ -0,0 +1,432 @@
+// Package admission implements a Kubernetes validating admission webhook
+// that records evidence for writes made on the API server path, including
+// ones that never went through evidra record or prescribe.
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/risk"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Tool is the canonical tool of entries written by the webhook.
+const Tool = "kube-apiserver"
+
+// Provenance marks actors of entries written by the webhook. Prior
+// prescriptions with this provenance never satisfy RequirePrescription.
+const Provenance = "admission"
+
+const (
+	triggerRiskThreshold        = "risk_threshold_exceeded"
+	triggerPrescriptionRequired = "prescription_required"
+
+	// maxReviewBytes bounds AdmissionReview bodies; etcd caps objects at
+	// 1.5MiB and a review can carry the old object too.
+	maxReviewBytes = 4 << 20
+
+	annotationPrescriptionID = "evidra.samebits.com/prescription-id"
+	annotationEffectiveRisk  = "evidra.samebits.com/effective-risk"
+)
+
+// admissionOperations maps AdmissionReview operations to canonical k8s
+// operations. CONNECT is not a write and is always allowed.
+var admissionOperations = map[string]string{
+	"CREATE": "create",
+	"UPDATE": "apply",
+	"DELETE": "delete",
+}
+
+// Options configures the webhook.
+type Options struct {
+	EvidencePath     string
+	Signer           evidence.Signer
+	Environment      string
+	BestEffortWrites bool
+	// MaxRisk denies requests whose effective risk is higher; empty never
+	// denies on risk.
+	MaxRisk string
+	// RequirePrescription denies requests no unexpired prescription covers.
+	RequirePrescription bool
+	// Cluster is recorded as a scope dimension when set.
+	Cluster string
+	// OnWrite runs after entries are persisted, e.g. to wake an outbox sync.
+	OnWrite func()
+	Now     func() time.Time
+}
+
+// Handler serves AdmissionReview requests.
+type Handler struct {
+	opts   Options
+	record *lifecycle.Service
+	// assess builds entries without persisting them, for evaluation and
+	// dry-run requests.
+	assess *lifecycle.Service
+	recent prescriptionWindow
+}
+
+// NewHandler validates opts and returns the webhook handler.
+func NewHandler(opts Options) (*Handler, error) {
+	if opts.Signer == nil {
+		return nil, errors.New("admission: signer is required")
+	}
+	opts.MaxRisk = strings.ToLower(strings.TrimSpace(opts.MaxRisk))
+	// Any recognized level outranks the empty one.
+	if opts.MaxRisk != "" && !risk.SeverityHigherThan(opts.MaxRisk, "") {
+		return nil, fmt.Errorf("admission: invalid max risk %q (expected low, medium, high, or critical)", opts.MaxRisk)
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Handler{
+		opts: opts,
+		record: lifecycle.NewService(lifecycle.Options{
+			EvidencePath:     opts.EvidencePath,
+			Signer:           opts.Signer,
+			BestEffortWrites: opts.BestEffortWrites,
+		}),
+		assess: lifecycle.NewService(lifecycle.Options{Signer: opts.Signer}),
+	}, nil
+}
+
+// Review is an admission.k8s.io/v1 AdmissionReview.
+type Review struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Request    *Request  `json:"request,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// Request is the subset of an AdmissionRequest the webhook reads.
+type Request struct {
+	UID  string `json:"uid"`
+	Kind struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"kind"`
+	Resource struct {
+		Group    string `json:"group"`
+		Version  string `json:"version"`
+		Resource string `json:"resource"`
+	} `json:"resource"`
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Operation string          `json:"operation"`
+	UserInfo  UserInfo        `json:"userInfo"`
+	Object    json.RawMessage `json:"object,omitempty"`
+	OldObject json.RawMessage `json:"oldObject,omitempty"`
+	DryRun    *bool           `json:"dryRun,omitempty"`
+}
+
+// UserInfo identifies the requester.
+type UserInfo struct {
+	Username string   `json:"username"`
+	UID      string   `json:"uid"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// Response is an AdmissionResponse.
+type Response struct {
+	UID              string            `json:"uid"`
+	Allowed          bool              `json:"allowed"`
+	Status           *Status           `json:"status,omitempty"`
+	Warnings         []string          `json:"warnings,omitempty"`
+	AuditAnnotations map[string]string `json:"auditAnnotations,omitempty"`
+}
+
+// Status carries the denial reason shown to the requester.
+type Status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxReviewBytes+1))
+	if err != nil || len(body) > maxReviewBytes {
+		http.Error(w, "unreadable or oversized AdmissionReview", http.StatusBadRequest)
+		return
+	}
+	var review Review
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "invalid AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.Review(r.Context(), review.Request)
+	if err != nil {
+		// A non-200 answer lets the webhook's failurePolicy decide.
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	apiVersion := review.APIVersion
+	if apiVersion == "" {
+		apiVersion = "admission.k8s.io/v1"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(Review{APIVersion: apiVersion, Kind: "AdmissionReview", Response: resp})
+}
+
+// Review evaluates one request. An unexpired prescription from another
+// actor that covers the object is reused; otherwise the webhook writes its
+// own, which stays unreported and so surfaces the bypass in scoring. Denied
+// requests are recorded as a prescription with a declined report. Dry-run
+// requests are evaluated without writing evidence.
+func (h *Handler) Review(ctx context.Context, req *Request) (*Response, error) {
+	resp := &Response{UID: req.UID, Allowed: true}
+	operation, ok := admissionOperations[req.Operation]
+	raw := req.Object
+	if operation == "delete" {
+		raw = req.OldObject
+	}
+	if !ok || isNullJSON(raw) {
+		return resp, nil
+	}
+	raw, err := withNamespace(raw, req.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("admission: decode object: %w", err)
+	}
+
+	input := lifecycle.PrescribeInput{
+		Actor:           requestActor(req.UserInfo),
+		Tool:            Tool,
+		Operation:       operation,
+		RawArtifact:     raw,
+		Environment:     h.opts.Environment,
+		OperationID:     req.UID,
+		ScopeDimensions: h.scopeDimensions(req),
+	}
+	assessed, err := h.assess.Prescribe(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("admission: assess %s: %w", describe(req), err)
+	}
+	resp.AuditAnnotations = map[string]string{annotationEffectiveRisk: assessed.EffectiveRisk}
+
+	prior, found, err := h.priorPrescription(assessed)
+	if err != nil {
+		return nil, fmt.Errorf("admission: find prescription: %w", err)
+	}
+
+	var decision *evidence.DecisionContext
+	switch {
+	case h.opts.MaxRisk != "" && risk.SeverityHigherThan(assessed.EffectiveRisk, h.opts.MaxRisk):
+		decision = &evidence.DecisionContext{
+			Trigger: triggerRiskThreshold,
+			Reason:  fmt.Sprintf("effective risk %s exceeds %s for %s", assessed.EffectiveRisk, h.opts.MaxRisk, describe(req)),
+		}
+	case h.opts.RequirePrescription && !found:
+		decision = &evidence.DecisionContext{
+			Trigger: triggerPrescriptionRequired,
+			Reason:  fmt.Sprintf("no prescription covers %s %s", operation, describe(req)),
+		}
+	}
+	if decision != nil {
+		resp.Allowed = false
+		resp.Status = &Status{Code: http.StatusForbidden, Message: "evidra: " + decision.Reason}
+	}
+
+	dryRun := req.DryRun != nil && *req.DryRun
+	switch {
+	case dryRun:
+		return resp, nil
+	case found && decision == nil:
+		resp.AuditAnnotations[annotationPrescriptionID] = prior.EntryID
+		return resp, nil
+	}
+
+	recorded, err := h.record.Prescribe(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("admission: prescribe %s: %w", describe(req), err)
+	}
+	resp.AuditAnnotations[annotationPrescriptionID] = recorded.PrescriptionID
+	if decision != nil {
+		if _, err := h.record.Report(ctx, lifecycle.ReportInput{
+			PrescriptionID:  recorded.PrescriptionID,
+			Verdict:         evidence.VerdictDeclined,
+			DecisionContext: decision,
+			Actor:           recorded.Actor,
+			SessionID:       recorded.SessionID,
+			OperationID:     req.UID,
+		}); err != nil {
+			return nil, fmt.Errorf("admission: report %s: %w", describe(req), err)
+		}
+	}
+	if h.opts.OnWrite != nil && recorded.Persisted {
+		h.opts.OnWrite()
+	}
+	return resp, nil
+}
+
+// priorPrescription finds the newest unexpired prescription, not written by
+// the webhook, whose canonical action touches the assessed object with the
+// same operation class. Prescriptions without a namespace match any.
+func (h *Handler) priorPrescription(assessed lifecycle.PrescribeOutput) (evidence.EvidenceEntry, bool, error) {
+	target, _, ok := prescribedAction(assessed.Entry)
+	if !ok || len(target.ResourceIdentity) == 0 || h.opts.EvidencePath == "" {
+		return evidence.EvidenceEntry{}, false, nil
+	}
+	id := target.ResourceIdentity[0]
+
+	live, err := h.recent.live(h.opts.EvidencePath, h.opts.Now())
+	if err != nil {
+		return evidence.EvidenceEntry{}, false, err
+	}
+	for i := len(live) - 1; i >= 0; i-- {
+		p := live[i]
+		if p.action.OperationClass != target.OperationClass {
+			continue
+		}
+		for _, r := range p.action.ResourceIdentity {
+			if r.Kind == id.Kind && r.Name == id.Name && (r.Namespace == "" || r.Namespace == id.Namespace) {
+				return p.entry, true, nil
+			}
+		}
+	}
+	return evidence.EvidenceEntry{}, false, nil
+}
+
+// prescriptionWindow holds the unexpired prescriptions not written by the
+// webhook. Each review reads only the entries appended since the last one,
+// so reviews do not rescan the store.
+type prescriptionWindow struct {
+	mu      sync.Mutex
+	cursor  evidence.EntryCursor
+	entries []windowedPrescription
+}
+
+type windowedPrescription struct {
+	entry     evidence.EvidenceEntry
+	action    canon.CanonicalAction
+	expiresAt time.Time
+}
+
+// live catches the window up with the store at path and returns the
+// prescriptions unexpired at now, oldest first.
+func (w *prescriptionWindow) live(path string, now time.Time) ([]windowedPrescription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	appended, cursor, err := evidence.EntriesSinceAtPath(path, w.cursor)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.cursor = cursor
+	for _, e := range appended {
+		if e.Type != evidence.EntryTypePrescribe || e.Actor.Provenance == Provenance {
+			continue
+		}
+		action, payload, ok := prescribedAction(e)
+		if !ok {
+			continue
+		}
+		ttl := payload.TTLMs
+		if ttl <= 0 {
+			ttl = evidence.DefaultTTLMs
+		}
+		w.entries = append(w.entries, windowedPrescription{
+			entry:     e,
+			action:    action,
+			expiresAt: e.Timestamp.Add(time.Duration(ttl) * time.Millisecond),
+		})
+	}
+	w.entries = slices.DeleteFunc(w.entries, func(p windowedPrescription) bool {
+		return now.After(p.expiresAt)
+	})
+	return slices.Clone(w.entries), nil
+}
+
+func prescribedAction(e evidence.EvidenceEntry) (canon.CanonicalAction, evidence.PrescriptionPayload, bool) {
+	var payload evidence.PrescriptionPayload
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return canon.CanonicalAction{}, payload, false
+	}
+	var action canon.CanonicalAction
+	if err := json.Unmarshal(payload.CanonicalAction, &action); err != nil {
+		return canon.CanonicalAction{}, payload, false
+	}
+	return action, payload, true
+}
+
+func (h *Handler) scopeDimensions(req *Request) map[string]string {
+	dims := map[string]string{"source_system": Provenance}
+	for k, v := range map[string]string{
+		"cluster":   h.opts.Cluster,
+		"namespace": req.Namespace,
+		"resource":  req.Resource.Resource,
+	} {
+		if v != "" {
+			dims[k] = v
+		}
+	}
+	return dims
+}
+
+// requestActor maps the requesting user. Service accounts are how agents
+// and controllers usually reach the API server.
+func requestActor(u UserInfo) evidence.Actor {
+	actor := evidence.Actor{Type: "human", ID: u.Username, Provenance: Provenance}
+	switch {
+	case strings.HasPrefix(u.Username, "system:serviceaccount:"):
+		actor.Type = "service_account"
+	case strings.HasPrefix(u.Username, "system:"):
+		actor.Type = "controller"
+	}
+	if actor.ID == "" {
+		actor.ID = "unknown"
+	}
+	return actor
+}
+
+// withNamespace fills metadata.namespace from the request; objects created
+// through a namespaced endpoint often omit it, and the scope class reads it.
+func withNamespace(raw json.RawMessage, namespace string) (json.RawMessage, error) {
+	if namespace == "" {
+		return raw, nil
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	metadata, _ := obj["metadata"].(map[string]any)
+	if metadata == nil {
+		metadata = map[string]any{}
+		obj["metadata"] = metadata
+	}
+	if ns, _ := metadata["namespace"].(string); ns != "" {
+		return raw, nil
+	}
+	metadata["namespace"] = namespace
+	return json.Marshal(obj)
+}
+
+func describe(req *Request) string {
+	name := req.Name
+	if req.Namespace != "" {
+		name = req.Namespace + "/" + name
+	}
+	return strings.ToLower(req.Kind.Kind) + " " + name
+}
+
+func isNullJSON(raw json.RawMessage) bool {
+	s := strings.TrimSpace(string(raw))
+	return s == "" || s == "null"
+}
@@ -0,0 +1,259 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func loadReview(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "tests", "testdata", "admission", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return data
+}
+
+func serveReview(t *testing.T, h http.Handler, body []byte) Review {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var review Review
+	if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil || review.Response == nil {
+		t.Fatalf("decode review: %v: %s", err, rec.Body.String())
+	}
+	return review
+}
+
+func readEntries(t *testing.T, dir string) []evidence.EvidenceEntry {
+	t.Helper()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	entries, err := evidence.ReadAllEntriesAtPath(dir)
+	if err != nil {
+		t.Fatalf("read entries: %v", err)
+	}
+	return entries
+}
+
+func TestHandler_Fixtures(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		fixture     string
+		opts        Options
+		wantAllowed bool
+		wantTypes   []evidence.EntryType
+		check       func(t *testing.T, resp *Response, entries []evidence.EvidenceEntry)
+	}{
+		{
+			name: "create is prescribed", fixture: "deployment_create.json", wantAllowed: true,
+			wantTypes: []evidence.EntryType{evidence.EntryTypePrescribe},
+			check: func(t *testing.T, resp *Response, entries []evidence.EvidenceEntry) {
+				action, _, _ := prescribedAction(entries[0])
+				if action.Tool != Tool || action.OperationClass != "mutate" || action.ScopeClass != "staging" {
+					t.Fatalf("action = %+v", action)
+				}
+				if entries[0].Actor.Type != "service_account" || entries[0].Actor.Provenance != Provenance || entries[0].OperationID != resp.UID {
+					t.Fatalf("entry = %+v", entries[0])
+				}
+				if resp.AuditAnnotations[annotationPrescriptionID] != entries[0].EntryID || entries[0].ScopeDimensions["resource"] != "deployments" {
+					t.Fatalf("annotations = %v, scope = %v", resp.AuditAnnotations, entries[0].ScopeDimensions)
+				}
+			},
+		},
+		{
+			name: "privileged pod over max risk is declined", fixture: "privileged_pod_create.json",
+			opts:      Options{MaxRisk: "high"},
+			wantTypes: []evidence.EntryType{evidence.EntryTypePrescribe, evidence.EntryTypeReport},
+			check: func(t *testing.T, resp *Response, entries []evidence.EvidenceEntry) {
+				if resp.Status == nil || resp.Status.Code != http.StatusForbidden || !strings.Contains(resp.Status.Message, "critical") {
+					t.Fatalf("status = %+v", resp.Status)
+				}
+				var rp evidence.ReportPayload
+				_ = json.Unmarshal(entries[1].Payload, &rp)
+				if rp.Verdict != evidence.VerdictDeclined || rp.DecisionContext == nil || rp.DecisionContext.Trigger != triggerRiskThreshold {
+					t.Fatalf("report = %+v", rp)
+				}
+			},
+		},
+		{
+			name: "privileged pod without max risk is allowed", fixture: "privileged_pod_create.json", wantAllowed: true,
+			wantTypes: []evidence.EntryType{evidence.EntryTypePrescribe},
+			check: func(t *testing.T, resp *Response, _ []evidence.EvidenceEntry) {
+				if resp.AuditAnnotations[annotationEffectiveRisk] != "critical" {
+					t.Fatalf("annotations = %v", resp.AuditAnnotations)
+				}
+			},
+		},
+		{
+			name: "delete reads the old object", fixture: "configmap_delete.json", wantAllowed: true,
+			wantTypes: []evidence.EntryType{evidence.EntryTypePrescribe},
+			check: func(t *testing.T, _ *Response, entries []evidence.EvidenceEntry) {
+				action, _, _ := prescribedAction(entries[0])
+				if action.OperationClass != "destroy" || len(action.ResourceIdentity) != 1 || action.ResourceIdentity[0].Name != "web-config" {
+					t.Fatalf("action = %+v", action)
+				}
+			},
+		},
+		{
+			name: "missing prescription is declined", fixture: "deployment_create.json",
+			opts:      Options{RequirePrescription: true},
+			wantTypes: []evidence.EntryType{evidence.EntryTypePrescribe, evidence.EntryTypeReport},
+			check: func(t *testing.T, resp *Response, _ []evidence.EvidenceEntry) {
+				if resp.Status == nil || !strings.Contains(resp.Status.Message, "no prescription covers create deployment shop-staging/web") {
+					t.Fatalf("status = %+v", resp.Status)
+				}
+			},
+		},
+		{
+			name: "dry run writes nothing", fixture: "deployment_update_dryrun.json",
+			opts: Options{RequirePrescription: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := filepath.Join(t.TempDir(), "evidence")
+			opts := tt.opts
+			opts.EvidencePath = dir
+			opts.Signer = testutil.TestSigner(t)
+			h, err := NewHandler(opts)
+			if err != nil {
+				t.Fatalf("NewHandler: %v", err)
+			}
+
+			review := serveReview(t, h, loadReview(t, tt.fixture))
+			if review.Response.Allowed != tt.wantAllowed {
+				t.Fatalf("allowed = %v, want %v: %+v", review.Response.Allowed, tt.wantAllowed, review.Response.Status)
+			}
+			entries := readEntries(t, dir)
+			if len(entries) != len(tt.wantTypes) {
+				t.Fatalf("entries = %d, want %d", len(entries), len(tt.wantTypes))
+			}
+			for i, e := range entries {
+				if e.Type != tt.wantTypes[i] {
+					t.Fatalf("entry %d type = %s, want %s", i, e.Type, tt.wantTypes[i])
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, review.Response, entries)
+			}
+		})
+	}
+}
+
+func TestHandler_ReusesPriorPrescription(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "evidence")
+	signer := testutil.TestSigner(t)
+
+	manifest := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`)
+	now := time.Now()
+	h, err := NewHandler(Options{EvidencePath: dir, Signer: signer, RequirePrescription: true, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+	if review := serveReview(t, h, loadReview(t, "deployment_create.json")); review.Response.Allowed {
+		t.Fatalf("uncovered create allowed: %+v", review.Response)
+	}
+
+	// A prescription written after the handler last read the store is seen.
+	svc := lifecycle.NewService(lifecycle.Options{EvidencePath: dir, Signer: signer})
+	prior, err := svc.Prescribe(context.Background(), lifecycle.PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "deploy-agent", Provenance: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: manifest,
+	})
+	if err != nil {
+		t.Fatalf("prescribe: %v", err)
+	}
+	recorded := len(readEntries(t, dir))
+	review := serveReview(t, h, loadReview(t, "deployment_create.json"))
+	if !review.Response.Allowed || review.Response.AuditAnnotations[annotationPrescriptionID] != prior.PrescriptionID {
+		t.Fatalf("response = %+v", review.Response)
+	}
+	if entries := readEntries(t, dir); len(entries) != recorded {
+		t.Fatalf("entries = %d, want %d: a covered request writes nothing", len(entries), recorded)
+	}
+
+	// A delete is a different operation class and is not covered.
+	review = serveReview(t, h, bytes.Replace(loadReview(t, "deployment_create.json"), []byte(`"CREATE"`), []byte(`"DELETE"`), 1))
+	if !review.Response.Allowed {
+		t.Fatalf("delete without old object should be skipped: %+v", review.Response)
+	}
+
+	// An expired prescription no longer covers the object.
+	now = now.Add(time.Duration(evidence.DefaultTTLMs)*time.Millisecond + time.Minute)
+	if review := serveReview(t, h, loadReview(t, "deployment_create.json")); review.Response.Allowed {
+		t.Fatalf("create covered by an expired prescription allowed: %+v", review.Response)
+	}
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+	h, err := NewHandler(Options{Signer: testutil.TestSigner(t)})
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+		{name: "invalid JSON", method: http.MethodPost, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "no request", method: http.MethodPost, body: `{"kind":"AdmissionReview"}`, wantStatus: http.StatusBadRequest},
+		{name: "connect allowed", method: http.MethodPost, body: `{"request":{"uid":"u1","operation":"CONNECT"}}`, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, "/validate", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && !strings.Contains(rec.Body.String(), `"allowed":true`) {
+				t.Fatalf("body = %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewHandler_Validates(t *testing.T) {
+	t.Parallel()
+	if _, err := NewHandler(Options{}); err == nil {
+		t.Fatal("expected error without signer")
+	}
+	if _, err := NewHandler(Options{Signer: testutil.TestSigner(t), MaxRisk: "severe"}); err == nil {
+		t.Fatal("expected error for invalid max risk")
+	}
+	if _, err := NewHandler(Options{Signer: testutil.TestSigner(t), MaxRisk: "High"}); err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package analytics
+
+import "samebits.com/evidra/internal/signal"
+
+// ApprovalStats summarizes decisions on prescriptions that required
+// approval.
+type ApprovalStats struct {
+	Required int `json:"required"`
+	Approved int `json:"approved"`
+	Rejected int `json:"rejected"`
+	// Undecided counts prescriptions in the window with no decision yet;
+	// UnapprovedExecutions counts reports of operations that ran anyway.
+	Undecided            int   `json:"undecided"`
+	UnapprovedExecutions int   `json:"unapproved_executions,omitempty"`
+	MeanLatencyMs        int64 `json:"mean_approval_latency_ms,omitempty"`
+	MaxLatencyMs         int64 `json:"max_approval_latency_ms,omitempty"`
+}
+
+// ComputeApprovalStats derives approval analytics from prescriptions that
+// required approval and the decisions on them. Latency runs from the
+// prescription to its first decision. It returns nil when entries hold no
+// prescription requiring approval.
+func ComputeApprovalStats(entries []signal.Entry) *ApprovalStats {
+	required := make(map[string]signal.Entry)
+	for _, e := range entries {
+		if e.IsPrescription && e.ApprovalRequired {
+			required[e.EventID] = e
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+
+	stats := ApprovalStats{Required: len(required)}
+	approved := make(map[string]bool)
+	decided := make(map[string]bool)
+	var totalLatency int64
+	for _, e := range entries {
+		rx, ok := required[e.PrescriptionID]
+		if !ok {
+			continue
+		}
+		switch {
+		case e.IsApproval && !decided[e.PrescriptionID]:
+			decided[e.PrescriptionID] = true
+			approved[e.PrescriptionID] = e.Approved
+			if e.Approved {
+				stats.Approved++
+			} else {
+				stats.Rejected++
+			}
+			latency := e.Timestamp.Sub(rx.Timestamp).Milliseconds()
+			totalLatency += latency
+			if latency > stats.MaxLatencyMs {
+				stats.MaxLatencyMs = latency
+			}
+		case e.IsReport && e.ExitCode != nil && !approved[e.PrescriptionID]:
+			stats.UnapprovedExecutions++
+		}
+	}
+	stats.Undecided = stats.Required - len(decided)
+	if len(decided) > 0 {
+		stats.MeanLatencyMs = totalLatency / int64(len(decided))
+	}
+	return &stats
+}
@@ -0,0 +1,44 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/signal"
+)
+
+func TestComputeApprovalStats(t *testing.T) {
+	t.Parallel()
+
+	if got := ComputeApprovalStats([]signal.Entry{{EventID: "p1", IsPrescription: true}}); got != nil {
+		t.Fatalf("stats without required approvals = %+v, want nil", got)
+	}
+
+	now := time.Now()
+	exit0 := 0
+	entries := []signal.Entry{
+		{EventID: "p1", IsPrescription: true, ApprovalRequired: true, Timestamp: now},
+		{EventID: "p2", IsPrescription: true, ApprovalRequired: true, Timestamp: now},
+		{EventID: "p3", IsPrescription: true, ApprovalRequired: true, Timestamp: now},
+		{EventID: "p4", IsPrescription: true, Timestamp: now},
+		{EventID: "a1", IsApproval: true, Approved: true, PrescriptionID: "p1", Timestamp: now.Add(2 * time.Minute)},
+		{EventID: "a2", IsApproval: true, PrescriptionID: "p2", Timestamp: now.Add(4 * time.Minute)},
+		{EventID: "a1b", IsApproval: true, PrescriptionID: "p1", Timestamp: now.Add(9 * time.Minute)},
+		{EventID: "r1", IsReport: true, PrescriptionID: "p1", ExitCode: &exit0},
+		{EventID: "r3", IsReport: true, PrescriptionID: "p3", ExitCode: &exit0},
+	}
+
+	got := ComputeApprovalStats(entries)
+	want := ApprovalStats{
+		Required:             3,
+		Approved:             1,
+		Rejected:             1,
+		Undecided:            1,
+		UnapprovedExecutions: 1,
+		MeanLatencyMs:        (3 * time.Minute).Milliseconds(),
+		MaxLatencyMs:         (4 * time.Minute).Milliseconds(),
+	}
+	if got == nil || *got != want {
+		t.Fatalf("approval stats = %+v, want %+v", got, want)
+	}
+}
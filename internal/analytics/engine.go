@@ -51,6 +51,8 @@ type ExplainOutput struct {
 	TotalOps         int            `json:"total_operations"`
 	ScoringProfileID string         `json:"scoring_profile_id"`
 	Signals          []SignalDetail `json:"signals"`
+	Rollbacks        *RollbackStats `json:"rollbacks,omitempty"`
+	Approvals        *ApprovalStats `json:"approvals,omitempty"`
 	EvidraVersion    string         `json:"evidra_version"`
 	GeneratedAt      string         `json:"generated_at"`
 }
@@ -99,14 +101,7 @@ func ComputeExplain(entries []evidence.EvidenceEntry, filters Filters) (ExplainO
 			Rate:     rate,
 			EntryIDs: result.EventIDs,
 		}
-		if result.Name == "protocol_violation" {
-			subMap := make(map[string]int)
-			pvEvents := signal.DetectProtocolViolationEvents(signalEntries, signal.DefaultTTL)
-			for _, ev := range pvEvents {
-				subMap[ev.SubSignal]++
-			}
-			detail.SubSignals = subMap
-		}
+		detail.SubSignals = signal.SubSignalCounts(result.Name, signalEntries, signal.DefaultTTL)
 		details = append(details, detail)
 	}
 
@@ -116,6 +111,8 @@ func ComputeExplain(entries []evidence.EvidenceEntry, filters Filters) (ExplainO
 		TotalOps:         totalOps,
 		ScoringProfileID: sc.ScoringProfileID,
 		Signals:          details,
+		Rollbacks:        ComputeRollbackStats(signalEntries),
+		Approvals:        ComputeApprovalStats(signalEntries),
 		EvidraVersion:    version.Version,
 		GeneratedAt:      time.Now().UTC().Format(time.RFC3339),
 	}, nil
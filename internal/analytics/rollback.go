@@ -0,0 +1,85 @@
+package analytics
+
+import (
+	"sort"
+
+	"samebits.com/evidra/internal/signal"
+)
+
+// RollbackStats summarizes prescriptions that revert earlier ones.
+type RollbackStats struct {
+	// Rollbacks counts prescriptions that revert another; RolledBack counts
+	// the distinct prescriptions in the window they revert.
+	Rollbacks            int                  `json:"rollbacks"`
+	RolledBack           int                  `json:"rolled_back"`
+	RollbackRate         float64              `json:"rollback_rate"`
+	MeanTimeToRollbackMs int64                `json:"mean_time_to_rollback_ms,omitempty"`
+	Actors               []ActorRollbackStats `json:"actors,omitempty"`
+}
+
+// ActorRollbackStats is the share of one actor's operations later rolled back.
+type ActorRollbackStats struct {
+	ActorID      string  `json:"actor_id"`
+	TotalOps     int     `json:"total_operations"`
+	RolledBack   int     `json:"rolled_back"`
+	RollbackRate float64 `json:"rollback_rate"`
+}
+
+// ComputeRollbackStats derives rollback analytics from prescriptions that
+// carry a reverts link. Time to rollback runs from the reverted
+// prescription to the first prescription reverting it. It returns nil when
+// entries hold no rollback.
+func ComputeRollbackStats(entries []signal.Entry) *RollbackStats {
+	prescriptions := make(map[string]signal.Entry)
+	opsByActor := make(map[string]int)
+	totalOps := 0
+	for _, e := range entries {
+		if !e.IsPrescription {
+			continue
+		}
+		prescriptions[e.EventID] = e
+		opsByActor[e.ActorID]++
+		totalOps++
+	}
+
+	stats := RollbackStats{}
+	rolledBackByActor := make(map[string]int)
+	reverted := make(map[string]bool)
+	var totalDelay int64
+	for _, e := range entries {
+		if !e.IsPrescription || e.RevertsPrescriptionID == "" {
+			continue
+		}
+		stats.Rollbacks++
+		target, ok := prescriptions[e.RevertsPrescriptionID]
+		if !ok || reverted[target.EventID] {
+			continue
+		}
+		reverted[target.EventID] = true
+		stats.RolledBack++
+		rolledBackByActor[target.ActorID]++
+		totalDelay += e.Timestamp.Sub(target.Timestamp).Milliseconds()
+	}
+	if stats.Rollbacks == 0 {
+		return nil
+	}
+
+	if totalOps > 0 {
+		stats.RollbackRate = float64(stats.RolledBack) / float64(totalOps)
+	}
+	if stats.RolledBack > 0 {
+		stats.MeanTimeToRollbackMs = totalDelay / int64(stats.RolledBack)
+	}
+	for actor, ops := range opsByActor {
+		stats.Actors = append(stats.Actors, ActorRollbackStats{
+			ActorID:      actor,
+			TotalOps:     ops,
+			RolledBack:   rolledBackByActor[actor],
+			RollbackRate: float64(rolledBackByActor[actor]) / float64(ops),
+		})
+	}
+	sort.Slice(stats.Actors, func(i, j int) bool {
+		return stats.Actors[i].ActorID < stats.Actors[j].ActorID
+	})
+	return &stats
+}
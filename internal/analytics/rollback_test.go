@@ -0,0 +1,55 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/signal"
+)
+
+func TestComputeRollbackStats(t *testing.T) {
+	t.Parallel()
+
+	if got := ComputeRollbackStats([]signal.Entry{{EventID: "p1", IsPrescription: true}}); got != nil {
+		t.Fatalf("stats without rollbacks = %+v, want nil", got)
+	}
+
+	now := time.Now()
+	entries := []signal.Entry{
+		{EventID: "p1", IsPrescription: true, ActorID: "agent-a", Timestamp: now},
+		{EventID: "p2", IsPrescription: true, ActorID: "agent-a", Timestamp: now.Add(time.Minute)},
+		{EventID: "p3", IsPrescription: true, ActorID: "agent-b", Timestamp: now.Add(2 * time.Minute)},
+		{EventID: "rb1", IsPrescription: true, ActorID: "agent-a", Timestamp: now.Add(4 * time.Minute), RevertsPrescriptionID: "p1"},
+		{EventID: "rb2", IsPrescription: true, ActorID: "agent-a", Timestamp: now.Add(5 * time.Minute), RevertsPrescriptionID: "p1"},
+		{EventID: "rb3", IsPrescription: true, ActorID: "agent-b", Timestamp: now.Add(4 * time.Minute), RevertsPrescriptionID: "p3"},
+		{EventID: "rb4", IsPrescription: true, ActorID: "agent-b", Timestamp: now.Add(6 * time.Minute), RevertsPrescriptionID: "outside-window"},
+		{EventID: "r1", IsReport: true, PrescriptionID: "p1"},
+	}
+
+	got := ComputeRollbackStats(entries)
+	if got == nil {
+		t.Fatal("expected rollback stats")
+	}
+	if got.Rollbacks != 4 || got.RolledBack != 2 {
+		t.Fatalf("rollbacks=%d rolled_back=%d, want 4 and 2", got.Rollbacks, got.RolledBack)
+	}
+	if got.RollbackRate != 2.0/7.0 {
+		t.Fatalf("rollback_rate = %v, want 2/7", got.RollbackRate)
+	}
+	// p1 reverted after 4m (the later rb2 does not count), p3 after 2m.
+	if want := (3 * time.Minute).Milliseconds(); got.MeanTimeToRollbackMs != want {
+		t.Fatalf("mean_time_to_rollback_ms = %d, want %d", got.MeanTimeToRollbackMs, want)
+	}
+	want := []ActorRollbackStats{
+		{ActorID: "agent-a", TotalOps: 4, RolledBack: 1, RollbackRate: 0.25},
+		{ActorID: "agent-b", TotalOps: 3, RolledBack: 1, RollbackRate: 1.0 / 3.0},
+	}
+	if len(got.Actors) != len(want) {
+		t.Fatalf("actors = %+v", got.Actors)
+	}
+	for i := range want {
+		if got.Actors[i] != want[i] {
+			t.Errorf("actors[%d] = %+v, want %+v", i, got.Actors[i], want[i])
+		}
+	}
+}
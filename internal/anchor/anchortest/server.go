@@ -0,0 +1,121 @@
+// Package anchortest provides an in-memory stand-in for a Rekor-style
+// transparency log, for use in tests.
+package anchortest
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"samebits.com/evidra/internal/anchor"
+)
+
+// Server accepts evidra_checkpoint entries, verifies their signatures, and
+// assigns sequential log indexes. It implements http.Handler; wrap it with
+// httptest.NewServer.
+type Server struct {
+	mu      sync.Mutex
+	logID   string
+	entries []anchor.LogEntry
+	now     func() time.Time
+}
+
+// NewServer returns an empty log.
+func NewServer() *Server {
+	sum := sha256.Sum256([]byte("evidra-anchortest"))
+	return &Server{
+		logID: hex.EncodeToString(sum[:]),
+		now:   time.Now,
+	}
+}
+
+// Entries returns a copy of every integrated entry in log order.
+func (s *Server) Entries() []anchor.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]anchor.LogEntry(nil), s.entries...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v1/log/entries" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		s.handleCreate(w, r)
+	case http.MethodGet:
+		s.handleGet(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var proposed anchor.ProposedEntry
+	if err := json.NewDecoder(r.Body).Decode(&proposed); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if proposed.Kind != anchor.EntryKind {
+		http.Error(w, "unsupported kind", http.StatusBadRequest)
+		return
+	}
+	note, err := base64.StdEncoding.DecodeString(proposed.Spec.Checkpoint)
+	if err != nil {
+		http.Error(w, "invalid checkpoint encoding", http.StatusBadRequest)
+		return
+	}
+	pub, err := base64.StdEncoding.DecodeString(proposed.Spec.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		http.Error(w, "invalid public key", http.StatusBadRequest)
+		return
+	}
+	if _, err := anchor.VerifyCheckpoint(note, ed25519.PublicKey(pub)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	canonical, _ := json.Marshal(proposed)
+	uuid := sha256.Sum256(canonical)
+
+	s.mu.Lock()
+	entry := anchor.LogEntry{
+		UUID:           hex.EncodeToString(uuid[:]),
+		Body:           base64.StdEncoding.EncodeToString(canonical),
+		IntegratedTime: s.now().Unix(),
+		LogID:          s.logID,
+		LogIndex:       int64(len(s.entries)),
+	}
+	s.entries = append(s.entries, entry)
+	s.mu.Unlock()
+
+	writeEntry(w, http.StatusCreated, entry)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.ParseInt(r.URL.Query().Get("logIndex"), 10, 64)
+	if err != nil {
+		http.Error(w, "logIndex is required", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index < 0 || index >= int64(len(s.entries)) {
+		http.NotFound(w, r)
+		return
+	}
+	writeEntry(w, http.StatusOK, s.entries[index])
+}
+
+func writeEntry(w http.ResponseWriter, status int, entry anchor.LogEntry) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]anchor.LogEntry{entry.UUID: entry})
+}
@@ -0,0 +1,225 @@
+// Package anchor exports evidence tree heads in transparency-log compatible
+// formats and publishes them to an external log, so that a key holder cannot
+// silently rewrite history that has already been anchored.
+package anchor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// DefaultOrigin is the checkpoint origin line used when none is configured.
+const DefaultOrigin = "evidra.local/evidence"
+
+const (
+	signatureLinePrefix = "— "
+	timestampLinePrefix = "Timestamp: "
+	algEd25519          = 0x01
+)
+
+var (
+	ErrInvalidCheckpoint = errors.New("invalid_checkpoint")
+	ErrSignatureMismatch = errors.New("checkpoint_signature_mismatch")
+)
+
+// Checkpoint is a tree head bound to a log origin. It is serialized as a
+// signed note (the checkpoint format used by Sigstore/Rekor and the Go
+// checksum database):
+//
+//	<origin>
+//	<entry count>
+//	<base64 last hash>
+//	Timestamp: <unix nanoseconds>
+//
+//	— <origin> <base64(key hash || ed25519 signature)>
+type Checkpoint struct {
+	Origin string
+	Head   evidence.TreeHead
+}
+
+// SignCheckpoint serializes head as a signed note using signer. Ed25519
+// signatures are deterministic, so the same head and key always produce the
+// same bytes. A signer backend failure, or a signature that is not an
+// Ed25519 signature, is an error rather than an unverifiable note.
+func SignCheckpoint(cp Checkpoint, signer evidence.Signer) ([]byte, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("anchor.SignCheckpoint: signer is required")
+	}
+	body, err := checkpointBody(cp)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := evidence.SignPayload(signer, body)
+	if err != nil {
+		return nil, fmt.Errorf("anchor.SignCheckpoint: %w", err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("anchor.SignCheckpoint: signer returned a %d-byte signature, want %d", len(sig), ed25519.SignatureSize)
+	}
+	keyHash := noteKeyHash(cp.Origin, signer.PublicKey())
+
+	stamp := make([]byte, 4+len(sig))
+	binary.BigEndian.PutUint32(stamp, keyHash)
+	copy(stamp[4:], sig)
+
+	var buf bytes.Buffer
+	buf.Write(body)
+	buf.WriteString("\n")
+	buf.WriteString(signatureLinePrefix + cp.Origin + " " + base64.StdEncoding.EncodeToString(stamp) + "\n")
+	return buf.Bytes(), nil
+}
+
+// ParseCheckpoint decodes a signed note without verifying its signature.
+func ParseCheckpoint(note []byte) (Checkpoint, error) {
+	cp, _, _, err := splitCheckpoint(note)
+	return cp, err
+}
+
+// VerifyCheckpoint decodes a signed note and verifies that it carries a valid
+// signature from pubKey.
+func VerifyCheckpoint(note []byte, pubKey ed25519.PublicKey) (Checkpoint, error) {
+	cp, body, sigLines, err := splitCheckpoint(note)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	want := noteKeyHash(cp.Origin, pubKey)
+	for _, line := range sigLines {
+		name, encoded, ok := strings.Cut(strings.TrimPrefix(line, signatureLinePrefix), " ")
+		if !ok || name != cp.Origin {
+			continue
+		}
+		stamp, decErr := base64.StdEncoding.DecodeString(encoded)
+		if decErr != nil || len(stamp) != 4+ed25519.SignatureSize {
+			continue
+		}
+		if binary.BigEndian.Uint32(stamp) != want {
+			continue
+		}
+		if ed25519.Verify(pubKey, body, stamp[4:]) {
+			return cp, nil
+		}
+	}
+	return Checkpoint{}, ErrSignatureMismatch
+}
+
+// VerifyCheckpointWithKeyring is VerifyCheckpoint accepting a signature from
+// any key in keyring.
+func VerifyCheckpointWithKeyring(note []byte, keyring *evidence.Keyring) (Checkpoint, error) {
+	for _, kid := range keyring.KeyIDs() {
+		pub, _ := keyring.Lookup(kid)
+		if cp, err := VerifyCheckpoint(note, pub); err == nil {
+			return cp, nil
+		} else if !errors.Is(err, ErrSignatureMismatch) {
+			return Checkpoint{}, err
+		}
+	}
+	return Checkpoint{}, ErrSignatureMismatch
+}
+
+func checkpointBody(cp Checkpoint) ([]byte, error) {
+	origin := strings.TrimSpace(cp.Origin)
+	if origin == "" || strings.ContainsAny(origin, " \n") {
+		return nil, fmt.Errorf("%w: origin must be a non-empty single token", ErrInvalidCheckpoint)
+	}
+	root, err := rootHashBytes(cp.Head)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(origin + "\n")
+	buf.WriteString(strconv.Itoa(cp.Head.EntryCount) + "\n")
+	buf.WriteString(base64.StdEncoding.EncodeToString(root) + "\n")
+	buf.WriteString(timestampLinePrefix + strconv.FormatInt(cp.Head.Timestamp.UTC().UnixNano(), 10) + "\n")
+	return buf.Bytes(), nil
+}
+
+func splitCheckpoint(note []byte) (Checkpoint, []byte, []string, error) {
+	text := string(note)
+	idx := strings.Index(text, "\n\n")
+	if idx < 0 {
+		return Checkpoint{}, nil, nil, fmt.Errorf("%w: missing signature block", ErrInvalidCheckpoint)
+	}
+	body := []byte(text[:idx+1])
+	lines := strings.Split(strings.TrimSuffix(text[:idx], "\n"), "\n")
+	if len(lines) < 3 {
+		return Checkpoint{}, nil, nil, fmt.Errorf("%w: expected origin, size and hash lines", ErrInvalidCheckpoint)
+	}
+
+	count, err := strconv.Atoi(lines[1])
+	if err != nil || count < 0 {
+		return Checkpoint{}, nil, nil, fmt.Errorf("%w: bad entry count %q", ErrInvalidCheckpoint, lines[1])
+	}
+	root, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil || len(root) != sha256.Size {
+		return Checkpoint{}, nil, nil, fmt.Errorf("%w: bad root hash %q", ErrInvalidCheckpoint, lines[2])
+	}
+	cp := Checkpoint{
+		Origin: lines[0],
+		Head:   evidence.TreeHead{EntryCount: count},
+	}
+	if count > 0 {
+		cp.Head.LastHash = "sha256:" + hex.EncodeToString(root)
+	}
+	for _, ext := range lines[3:] {
+		raw, ok := strings.CutPrefix(ext, timestampLinePrefix)
+		if !ok {
+			continue
+		}
+		nanos, parseErr := strconv.ParseInt(raw, 10, 64)
+		if parseErr != nil {
+			return Checkpoint{}, nil, nil, fmt.Errorf("%w: bad timestamp %q", ErrInvalidCheckpoint, raw)
+		}
+		cp.Head.Timestamp = time.Unix(0, nanos).UTC()
+	}
+
+	var sigLines []string
+	for _, line := range strings.Split(text[idx+2:], "\n") {
+		if strings.HasPrefix(line, signatureLinePrefix) {
+			sigLines = append(sigLines, line)
+		}
+	}
+	if len(sigLines) == 0 {
+		return Checkpoint{}, nil, nil, fmt.Errorf("%w: no signature lines", ErrInvalidCheckpoint)
+	}
+	return cp, body, sigLines, nil
+}
+
+// rootHashBytes returns the raw digest committed to by head. The chain hash
+// stands in for a Merkle root; an empty chain uses the SHA-256 of the empty
+// string, as RFC 6962 does for an empty tree.
+func rootHashBytes(head evidence.TreeHead) ([]byte, error) {
+	if head.EntryCount == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:], nil
+	}
+	raw, ok := strings.CutPrefix(head.LastHash, "sha256:")
+	if !ok {
+		return nil, fmt.Errorf("%w: last_hash must be sha256:<hex>", ErrInvalidCheckpoint)
+	}
+	root, err := hex.DecodeString(raw)
+	if err != nil || len(root) != sha256.Size {
+		return nil, fmt.Errorf("%w: last_hash must be sha256:<hex>", ErrInvalidCheckpoint)
+	}
+	return root, nil
+}
+
+// noteKeyHash is the signed-note key hash: the first four bytes of
+// SHA-256(name || "\n" || alg || public key).
+func noteKeyHash(name string, pubKey ed25519.PublicKey) uint32 {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte{'\n', algEd25519})
+	h.Write(pubKey)
+	return binary.BigEndian.Uint32(h.Sum(nil))
+}
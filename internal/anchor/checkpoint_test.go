@@ -0,0 +1,137 @@
+package anchor
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func testHead() evidence.TreeHead {
+	return evidence.TreeHead{
+		EntryCount: 42,
+		LastHash:   "sha256:" + strings.Repeat("ab", 32),
+		Timestamp:  time.Date(2026, 3, 1, 12, 0, 0, 123, time.UTC),
+	}
+}
+
+func TestSignCheckpoint_RoundTrip(t *testing.T) {
+	t.Parallel()
+	signer := testutil.TestSigner(t)
+
+	note, err := SignCheckpoint(Checkpoint{Origin: DefaultOrigin, Head: testHead()}, signer)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: %v", err)
+	}
+	lines := strings.Split(string(note), "\n")
+	if lines[0] != DefaultOrigin || lines[1] != "42" {
+		t.Fatalf("unexpected checkpoint header:\n%s", note)
+	}
+	if !strings.Contains(string(note), "\n\n— "+DefaultOrigin+" ") {
+		t.Fatalf("missing signature line:\n%s", note)
+	}
+
+	cp, err := VerifyCheckpoint(note, signer.PublicKey())
+	if err != nil {
+		t.Fatalf("VerifyCheckpoint: %v", err)
+	}
+	if cp.Origin != DefaultOrigin || cp.Head != testHead() {
+		t.Fatalf("checkpoint = %+v, want %+v", cp, testHead())
+	}
+
+	again, err := SignCheckpoint(Checkpoint{Origin: DefaultOrigin, Head: testHead()}, signer)
+	if err != nil {
+		t.Fatalf("SignCheckpoint again: %v", err)
+	}
+	if !bytes.Equal(note, again) {
+		t.Fatal("checkpoint signing should be deterministic")
+	}
+}
+
+type failingSigner struct{ evidence.Signer }
+
+func (failingSigner) TrySign([]byte) ([]byte, error) { return nil, errors.New("hsm offline") }
+
+type truncatingSigner struct{ evidence.Signer }
+
+func (s truncatingSigner) Sign(payload []byte) []byte { return s.Signer.Sign(payload)[:32] }
+
+func TestSignCheckpoint_SignerFailure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		signer evidence.Signer
+		want   string
+	}{
+		{name: "backend failure", signer: failingSigner{testutil.TestSigner(t)}, want: "hsm offline"},
+		{name: "malformed signature", signer: truncatingSigner{testutil.TestSigner(t)}, want: "32-byte signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			note, err := SignCheckpoint(Checkpoint{Origin: DefaultOrigin, Head: testHead()}, tt.signer)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("SignCheckpoint = %q, %v; want error containing %q", note, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignCheckpoint_EmptyChain(t *testing.T) {
+	t.Parallel()
+	signer := testutil.TestSigner(t)
+
+	head := evidence.TreeHead{Timestamp: time.Unix(0, 0).UTC()}
+	note, err := SignCheckpoint(Checkpoint{Origin: DefaultOrigin, Head: head}, signer)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: %v", err)
+	}
+	cp, err := VerifyCheckpoint(note, signer.PublicKey())
+	if err != nil {
+		t.Fatalf("VerifyCheckpoint: %v", err)
+	}
+	if cp.Head.EntryCount != 0 || cp.Head.LastHash != "" {
+		t.Fatalf("head = %+v", cp.Head)
+	}
+}
+
+func TestVerifyCheckpoint_Rejects(t *testing.T) {
+	t.Parallel()
+	signer := testutil.TestSigner(t)
+	note, err := SignCheckpoint(Checkpoint{Origin: DefaultOrigin, Head: testHead()}, signer)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: %v", err)
+	}
+
+	if _, err := VerifyCheckpoint(note, testutil.TestSigner(t).PublicKey()); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("wrong key: expected ErrSignatureMismatch, got %v", err)
+	}
+
+	tampered := bytes.Replace(note, []byte("\n42\n"), []byte("\n41\n"), 1)
+	if _, err := VerifyCheckpoint(tampered, signer.PublicKey()); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("tampered size: expected ErrSignatureMismatch, got %v", err)
+	}
+
+	if _, err := ParseCheckpoint([]byte("origin\n1\n")); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Fatalf("unsigned note: expected ErrInvalidCheckpoint, got %v", err)
+	}
+}
+
+func TestSignCheckpoint_RejectsBadInput(t *testing.T) {
+	t.Parallel()
+	signer := testutil.TestSigner(t)
+
+	if _, err := SignCheckpoint(Checkpoint{Origin: "has space", Head: testHead()}, signer); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Fatalf("bad origin: expected ErrInvalidCheckpoint, got %v", err)
+	}
+	head := testHead()
+	head.LastHash = "md5:abc"
+	if _, err := SignCheckpoint(Checkpoint{Origin: DefaultOrigin, Head: head}, signer); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Fatalf("bad hash: expected ErrInvalidCheckpoint, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EntryKind is the Rekor-style "kind" used when publishing checkpoints.
+const EntryKind = "evidra_checkpoint"
+
+const (
+	entryAPIVersion = "0.0.1"
+	logEntriesPath  = "/api/v1/log/entries"
+)
+
+// ProposedEntry is the request body for publishing a checkpoint. It mirrors
+// the shape of a Rekor proposed entry (apiVersion/kind/spec).
+type ProposedEntry struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       ProposedEntrySpec `json:"spec"`
+}
+
+// ProposedEntrySpec carries the signed checkpoint and the key that signed it,
+// both base64-encoded.
+type ProposedEntrySpec struct {
+	Checkpoint string `json:"checkpoint"`
+	PublicKey  string `json:"publicKey"`
+}
+
+// LogEntry is a log's acknowledgement of an integrated entry.
+type LogEntry struct {
+	UUID           string `json:"-"`
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// LogClient publishes checkpoints to a transparency log that speaks the
+// Rekor entries API subset used by Evidra.
+type LogClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewLogClient returns a client for the log at baseURL. A zero timeout
+// defaults to 10 seconds.
+func NewLogClient(baseURL string, timeout time.Duration) *LogClient {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &LogClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Publish submits a signed checkpoint and returns the log's receipt.
+func (c *LogClient) Publish(ctx context.Context, note []byte, pubKey ed25519.PublicKey) (LogEntry, error) {
+	body, err := json.Marshal(ProposedEntry{
+		APIVersion: entryAPIVersion,
+		Kind:       EntryKind,
+		Spec: ProposedEntrySpec{
+			Checkpoint: base64.StdEncoding.EncodeToString(note),
+			PublicKey:  base64.StdEncoding.EncodeToString(pubKey),
+		},
+	})
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("anchor.Publish: marshal: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+logEntriesPath, bytes.NewReader(body))
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("anchor.Publish: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, http.StatusCreated)
+}
+
+// Get fetches the entry at logIndex.
+func (c *LogClient) Get(ctx context.Context, logIndex int64) (LogEntry, error) {
+	q := url.Values{"logIndex": []string{strconv.FormatInt(logIndex, 10)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+logEntriesPath+"?"+q.Encode(), nil)
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("anchor.Get: %w", err)
+	}
+	return c.do(req, http.StatusOK)
+}
+
+func (c *LogClient) do(req *http.Request, wantStatus int) (LogEntry, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("transparency log request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("read transparency log response: %w", err)
+	}
+	if resp.StatusCode != wantStatus {
+		return LogEntry{}, fmt.Errorf("transparency log returned %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	var byUUID map[string]LogEntry
+	if err := json.Unmarshal(raw, &byUUID); err != nil {
+		return LogEntry{}, fmt.Errorf("decode transparency log response: %w", err)
+	}
+	if len(byUUID) != 1 {
+		return LogEntry{}, fmt.Errorf("transparency log returned %d entries, want 1", len(byUUID))
+	}
+	for uuid, entry := range byUUID {
+		entry.UUID = uuid
+		return entry, nil
+	}
+	return LogEntry{}, nil
+}
+
+// CheckpointFromBody extracts the signed checkpoint from a log entry body.
+func CheckpointFromBody(body string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("decode entry body: %w", err)
+	}
+	var proposed ProposedEntry
+	if err := json.Unmarshal(raw, &proposed); err != nil {
+		return nil, fmt.Errorf("decode entry body: %w", err)
+	}
+	if proposed.Kind != EntryKind {
+		return nil, fmt.Errorf("unexpected entry kind %q", proposed.Kind)
+	}
+	return base64.StdEncoding.DecodeString(proposed.Spec.Checkpoint)
+}
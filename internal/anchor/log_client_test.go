@@ -0,0 +1,154 @@
+package anchor_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/anchor"
+	"samebits.com/evidra/internal/anchor/anchortest"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestLogClient_PublishAndGet(t *testing.T) {
+	t.Parallel()
+
+	logServer := anchortest.NewServer()
+	srv := httptest.NewServer(logServer)
+	defer srv.Close()
+
+	signer := testutil.TestSigner(t)
+	head := evidence.TreeHead{EntryCount: 0, Timestamp: time.Now().UTC()}
+	note, err := anchor.SignCheckpoint(anchor.Checkpoint{Origin: anchor.DefaultOrigin, Head: head}, signer)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: %v", err)
+	}
+
+	client := anchor.NewLogClient(srv.URL, time.Second)
+	ctx := context.Background()
+	receipt, err := client.Publish(ctx, note, signer.PublicKey())
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if receipt.LogIndex != 0 || receipt.UUID == "" || receipt.LogID == "" {
+		t.Fatalf("receipt = %+v", receipt)
+	}
+
+	got, err := client.Get(ctx, receipt.LogIndex)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	stored, err := anchor.CheckpointFromBody(got.Body)
+	if err != nil {
+		t.Fatalf("CheckpointFromBody: %v", err)
+	}
+	if string(stored) != string(note) {
+		t.Fatalf("stored checkpoint differs:\n%s", stored)
+	}
+	if len(logServer.Entries()) != 1 {
+		t.Fatalf("log entries = %d", len(logServer.Entries()))
+	}
+}
+
+func TestLogClient_PublishRejectsWrongKey(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(anchortest.NewServer())
+	defer srv.Close()
+
+	signer := testutil.TestSigner(t)
+	note, err := anchor.SignCheckpoint(anchor.Checkpoint{Origin: anchor.DefaultOrigin}, signer)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: %v", err)
+	}
+	if _, err := anchor.NewLogClient(srv.URL, time.Second).Publish(context.Background(), note, testutil.TestSigner(t).PublicKey()); err == nil {
+		t.Fatal("expected publish with mismatched key to fail")
+	}
+}
+
+func TestPublishedHeads_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	signer := testutil.TestSigner(t)
+	head := evidence.TreeHead{EntryCount: 1, LastHash: "sha256:" + strings.Repeat("cd", 32), Timestamp: time.Now().UTC()}
+	note, err := anchor.SignCheckpoint(anchor.Checkpoint{Origin: anchor.DefaultOrigin, Head: head}, signer)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), anchor.PublishedHeadsFileName)
+	rec := anchor.PublishedHead{
+		Origin:     anchor.DefaultOrigin,
+		EntryCount: head.EntryCount,
+		LastHash:   head.LastHash,
+		Checkpoint: string(note),
+	}
+	if err := anchor.AppendPublishedHead(path, rec); err != nil {
+		t.Fatalf("AppendPublishedHead: %v", err)
+	}
+	records, err := anchor.ReadPublishedHeads(path)
+	if err != nil {
+		t.Fatalf("ReadPublishedHeads: %v", err)
+	}
+	heads, err := anchor.TreeHeads(records, evidence.NewKeyring(signer.PublicKey()))
+	if err != nil {
+		t.Fatalf("TreeHeads: %v", err)
+	}
+	if len(heads) != 1 || heads[0].LastHash != head.LastHash {
+		t.Fatalf("heads = %+v", heads)
+	}
+
+	records[0].EntryCount = 2
+	if _, err := anchor.TreeHeads(records, nil); err == nil {
+		t.Fatal("expected mismatch between record and checkpoint")
+	}
+}
+
+func TestVerifyLogged(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(anchortest.NewServer())
+	defer srv.Close()
+
+	signer := testutil.TestSigner(t)
+	sign := func(count int) []byte {
+		head := evidence.TreeHead{EntryCount: count, LastHash: "sha256:" + strings.Repeat("ab", 32), Timestamp: time.Now().UTC()}
+		note, err := anchor.SignCheckpoint(anchor.Checkpoint{Origin: anchor.DefaultOrigin, Head: head}, signer)
+		if err != nil {
+			t.Fatalf("SignCheckpoint: %v", err)
+		}
+		return note
+	}
+	note := sign(1)
+	ctx := context.Background()
+	receipt, err := anchor.NewLogClient(srv.URL, time.Second).Publish(ctx, note, signer.PublicKey())
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	rec := anchor.PublishedHead{
+		Checkpoint: string(note),
+		LogURL:     srv.URL,
+		LogID:      receipt.LogID,
+		LogIndex:   receipt.LogIndex,
+		UUID:       receipt.UUID,
+	}
+	if err := anchor.VerifyLogged(ctx, []anchor.PublishedHead{rec}, time.Second); err != nil {
+		t.Fatalf("VerifyLogged: %v", err)
+	}
+
+	forged := rec
+	forged.Checkpoint = string(sign(2))
+	if err := anchor.VerifyLogged(ctx, []anchor.PublishedHead{forged}, time.Second); !errors.Is(err, anchor.ErrLogMismatch) {
+		t.Fatalf("forged checkpoint err = %v, want ErrLogMismatch", err)
+	}
+	unlogged := rec
+	unlogged.LogURL = ""
+	if err := anchor.VerifyLogged(ctx, []anchor.PublishedHead{unlogged}, time.Second); !errors.Is(err, anchor.ErrLogMismatch) {
+		t.Fatalf("missing log_url err = %v, want ErrLogMismatch", err)
+	}
+}
@@ -0,0 +1,154 @@
+package anchor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// PublishedHeadsFileName is the default receipts file kept next to the
+// evidence store.
+const PublishedHeadsFileName = "published-heads.jsonl"
+
+// PublishedHead records a checkpoint that was accepted by a transparency
+// log. One record is stored per line in the published heads file.
+type PublishedHead struct {
+	Origin         string    `json:"origin"`
+	EntryCount     int       `json:"entry_count"`
+	LastHash       string    `json:"last_hash"`
+	Timestamp      time.Time `json:"timestamp"`
+	Checkpoint     string    `json:"checkpoint"`
+	LogURL         string    `json:"log_url,omitempty"`
+	LogID          string    `json:"log_id,omitempty"`
+	LogIndex       int64     `json:"log_index"`
+	UUID           string    `json:"uuid,omitempty"`
+	IntegratedTime int64     `json:"integrated_time,omitempty"`
+}
+
+// ErrLogMismatch reports a published head whose transparency log entry does
+// not hold the recorded checkpoint.
+var ErrLogMismatch = errors.New("published_head_log_mismatch")
+
+// DefaultPublishedHeadsPath returns the receipts file for an evidence store.
+func DefaultPublishedHeadsPath(evidencePath string) string {
+	return filepath.Join(evidence.StoreDir(evidencePath), PublishedHeadsFileName)
+}
+
+// AppendPublishedHead appends rec as one JSON line to path.
+func AppendPublishedHead(path string, rec PublishedHead) (err error) {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal published head: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create published heads directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open published heads: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("close published heads: %w", closeErr)
+		}
+	}()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write published head: %w", err)
+	}
+	return nil
+}
+
+// ReadPublishedHeads reads every record from a published heads file.
+func ReadPublishedHeads(path string) ([]PublishedHead, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []PublishedHead
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec PublishedHead
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("parse published heads line %d: %w", lineNo, err)
+		}
+		out = append(out, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read published heads: %w", err)
+	}
+	return out, nil
+}
+
+// TreeHeads decodes the checkpoint carried by each record and returns the
+// committed tree heads. When keyring is non-nil every checkpoint must carry a
+// signature from one of its keys. The checkpoint is authoritative; a record
+// whose summary fields disagree with its checkpoint is rejected.
+func TreeHeads(records []PublishedHead, keyring *evidence.Keyring) ([]evidence.TreeHead, error) {
+	heads := make([]evidence.TreeHead, 0, len(records))
+	for i, rec := range records {
+		var (
+			cp  Checkpoint
+			err error
+		)
+		if keyring != nil {
+			cp, err = VerifyCheckpointWithKeyring([]byte(rec.Checkpoint), keyring)
+		} else {
+			cp, err = ParseCheckpoint([]byte(rec.Checkpoint))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("published head %d: %w", i, err)
+		}
+		if cp.Head.EntryCount != rec.EntryCount || cp.Head.LastHash != rec.LastHash {
+			return nil, fmt.Errorf("published head %d: record does not match its checkpoint", i)
+		}
+		heads = append(heads, cp.Head)
+	}
+	return heads, nil
+}
+
+// VerifyLogged fetches each record's entry from the transparency log that
+// accepted it and checks that the log holds the recorded checkpoint at the
+// recorded index. The local file is only a cache of receipts: a key holder
+// can regenerate it, but not the entries the log already integrated.
+func VerifyLogged(ctx context.Context, records []PublishedHead, timeout time.Duration) error {
+	for i, rec := range records {
+		if rec.LogURL == "" {
+			return fmt.Errorf("%w: published head %d has no log_url", ErrLogMismatch, i)
+		}
+		entry, err := NewLogClient(rec.LogURL, timeout).Get(ctx, rec.LogIndex)
+		if err != nil {
+			return fmt.Errorf("published head %d: fetch log index %d: %w", i, rec.LogIndex, err)
+		}
+		if rec.UUID != "" && entry.UUID != rec.UUID {
+			return fmt.Errorf("%w: published head %d: log index %d has uuid %s, recorded %s", ErrLogMismatch, i, rec.LogIndex, entry.UUID, rec.UUID)
+		}
+		if rec.LogID != "" && entry.LogID != rec.LogID {
+			return fmt.Errorf("%w: published head %d: log id %s, recorded %s", ErrLogMismatch, i, entry.LogID, rec.LogID)
+		}
+		logged, err := CheckpointFromBody(entry.Body)
+		if err != nil {
+			return fmt.Errorf("published head %d: %w", i, err)
+		}
+		if !bytes.Equal(logged, []byte(rec.Checkpoint)) {
+			return fmt.Errorf("%w: published head %d: log index %d holds a different checkpoint", ErrLogMismatch, i, rec.LogIndex)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package anchor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+)
+
+// oidSHA256 is id-sha256 from RFC 5754.
+var oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type messageImprint struct {
+	HashAlgorithm algorithmIdentifier
+	HashedMessage []byte
+}
+
+// timeStampReq is the TimeStampReq structure from RFC 3161 section 2.4.1,
+// without the optional reqPolicy and extensions fields.
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int
+	CertReq        bool `asn1:"optional"`
+}
+
+// TimestampRequest builds a DER-encoded RFC 3161 TimeStampReq whose message
+// imprint is the SHA-256 of data (normally a signed checkpoint). The result
+// can be sent to any RFC 3161 timestamp authority, e.g.
+//
+//	curl -H 'Content-Type: application/timestamp-query' --data-binary @head.tsq $TSA_URL
+//
+// A nil nonce is replaced with a random 64-bit value.
+func TimestampRequest(data []byte, nonce *big.Int) ([]byte, error) {
+	if nonce == nil {
+		n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+		if err != nil {
+			return nil, fmt.Errorf("anchor.TimestampRequest: nonce: %w", err)
+		}
+		nonce = n
+	}
+	digest := sha256.Sum256(data)
+	der, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: algorithmIdentifier{
+				Algorithm:  oidSHA256,
+				Parameters: asn1.NullRawValue,
+			},
+			HashedMessage: digest[:],
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anchor.TimestampRequest: %w", err)
+	}
+	return der, nil
+}
@@ -0,0 +1,53 @@
+package anchor
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+)
+
+func TestTimestampRequest_DER(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("checkpoint bytes")
+	der, err := TimestampRequest(data, big.NewInt(7))
+	if err != nil {
+		t.Fatalf("TimestampRequest: %v", err)
+	}
+
+	var req timeStampReq
+	rest, err := asn1.Unmarshal(der, &req)
+	if err != nil {
+		t.Fatalf("asn1.Unmarshal: %v", err)
+	}
+	if len(rest) != 0 {
+		t.Fatalf("trailing bytes: %d", len(rest))
+	}
+	want := sha256.Sum256(data)
+	if req.Version != 1 || !req.CertReq || req.Nonce.Int64() != 7 {
+		t.Fatalf("request = %+v", req)
+	}
+	if !req.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) {
+		t.Fatalf("hash algorithm = %v", req.MessageImprint.HashAlgorithm.Algorithm)
+	}
+	if string(req.MessageImprint.HashedMessage) != string(want[:]) {
+		t.Fatal("message imprint does not match SHA-256 of data")
+	}
+}
+
+func TestTimestampRequest_RandomNonce(t *testing.T) {
+	t.Parallel()
+
+	a, err := TimestampRequest([]byte("x"), nil)
+	if err != nil {
+		t.Fatalf("TimestampRequest: %v", err)
+	}
+	b, err := TimestampRequest([]byte("x"), nil)
+	if err != nil {
+		t.Fatalf("TimestampRequest: %v", err)
+	}
+	if string(a) == string(b) {
+		t.Fatal("expected distinct nonces")
+	}
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// ApprovalStore reads the entries the approval workflow decides on: a
+// tenant's prescriptions, reports, cancellations and approvals, oldest
+// first.
+type ApprovalStore interface {
+	ApprovalEntries(ctx context.Context, tenantID, sessionID string) ([]evidence.EvidenceEntry, error)
+}
+
+// TenantKeyring returns the signing keys a tenant registered. Approval
+// decisions are verified against them.
+type TenantKeyring interface {
+	Keyring(ctx context.Context, tenantID string) (*evidence.Keyring, error)
+}
+
+// tenantKeyring loads the tenant's keyring, or returns nil when keys is nil
+// so decisions fall back to comparing recorded key_ids.
+func tenantKeyring(ctx context.Context, keys TenantKeyring, tenantID string) (*evidence.Keyring, error) {
+	if keys == nil {
+		return nil, nil
+	}
+	return keys.Keyring(ctx, tenantID)
+}
+
+type pendingApprovalResponse struct {
+	lifecycle.PendingPrescription
+	// Entry is the signed prescription, so approvers can review it and
+	// sign their decision without a local copy of the chain.
+	Entry json.RawMessage `json:"entry"`
+}
+
+func handleListApprovals(as ApprovalStore, keys TenantKeyring) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenantID := auth.TenantID(r.Context())
+		keyring, err := tenantKeyring(r.Context(), keys, tenantID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list approvals failed")
+			return
+		}
+		entries, err := as.ApprovalEntries(r.Context(), tenantID, r.URL.Query().Get("session_id"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list approvals failed")
+			return
+		}
+		pending, err := lifecycle.PendingApprovals(entries, time.Now().UTC(), keyring)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list approvals failed")
+			return
+		}
+
+		byID := make(map[string]evidence.EvidenceEntry, len(entries))
+		for _, e := range entries {
+			byID[e.EntryID] = e
+		}
+		resp := make([]pendingApprovalResponse, 0, len(pending))
+		for _, p := range pending {
+			raw, err := json.Marshal(byID[p.PrescriptionID])
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "list approvals failed")
+				return
+			}
+			resp = append(resp, pendingApprovalResponse{PendingPrescription: p, Entry: raw})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"approvals": resp,
+			"total":     len(resp),
+		})
+	}
+}
+
+// handleGetApproval returns the decision recorded on a prescription, or an
+// empty decision while it is still awaiting one. Agents blocked on an
+// approval poll it.
+func handleGetApproval(as ApprovalStore, keys TenantKeyring) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenantID := auth.TenantID(r.Context())
+		prescriptionID := r.PathValue("prescription_id")
+		keyring, err := tenantKeyring(r.Context(), keys, tenantID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "read approvals failed")
+			return
+		}
+		entries, err := as.ApprovalEntries(r.Context(), tenantID, r.URL.Query().Get("session_id"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "read approvals failed")
+			return
+		}
+		if findPrescription(entries, prescriptionID) == nil {
+			writeError(w, http.StatusNotFound, "prescription not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"prescription_id": prescriptionID,
+			"decision":        lifecycle.ApprovalDecision(entries, prescriptionID, keyring),
+		})
+	}
+}
+
+// findPrescription returns the prescription entry with the given ID among
+// entries, or nil.
+func findPrescription(entries []evidence.EvidenceEntry, prescriptionID string) *evidence.EvidenceEntry {
+	for i := range entries {
+		if entries[i].EntryID == prescriptionID && entries[i].Type == evidence.EntryTypePrescribe {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// handleSubmitApproval stores an approval entry the approver signed
+// client-side. The entry's hash and signature are verified against the
+// tenant's registered keys, and the verified signer must differ from the
+// prescription's. The decision must target a prescription still awaiting
+// one. Without a key store and an ingester nothing can be verified, so
+// every submission is refused.
+func handleSubmitApproval(as ApprovalStore, keys TenantKeyring, ingester EntryIngester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if keys == nil || ingester == nil {
+			writeError(w, http.StatusServiceUnavailable, "approval verification is not configured")
+			return
+		}
+		tenantID := auth.TenantID(r.Context())
+		prescriptionID := r.PathValue("prescription_id")
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil || len(body) == 0 {
+			writeError(w, http.StatusBadRequest, "empty or unreadable body")
+			return
+		}
+		entry, ok := decodeApproval(w, body, prescriptionID)
+		if !ok {
+			return
+		}
+
+		keyring, err := keys.Keyring(r.Context(), tenantID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "load signing keys failed")
+			return
+		}
+		if err := evidence.VerifyEntryHash(entry); err != nil {
+			writeError(w, http.StatusBadRequest, "approval hash does not match its contents")
+			return
+		}
+		approver, err := evidence.SignerKeyID(entry, keyring)
+		if err != nil {
+			writeError(w, http.StatusForbidden, "approval must be signed by a registered key")
+			return
+		}
+
+		entries, err := as.ApprovalEntries(r.Context(), tenantID, entry.SessionID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "read approvals failed")
+			return
+		}
+		prescription := findPrescription(entries, prescriptionID)
+		if prescription == nil {
+			writeError(w, http.StatusNotFound, "prescription not found")
+			return
+		}
+		pending, err := lifecycle.PendingApprovals(entries, time.Now().UTC(), keyring)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "read approvals failed")
+			return
+		}
+		awaiting := false
+		for _, p := range pending {
+			awaiting = awaiting || p.PrescriptionID == prescriptionID
+		}
+		if !awaiting {
+			writeError(w, http.StatusConflict, "prescription is not awaiting approval")
+			return
+		}
+		prescriber, err := evidence.SignerKeyID(*prescription, keyring)
+		if err != nil {
+			prescriber = prescription.KeyID
+		}
+		if approver == prescriber {
+			writeError(w, http.StatusForbidden, "approval must be signed by a key other than the prescription's")
+			return
+		}
+
+		results, err := ingester.Ingest(r.Context(), tenantID, []json.RawMessage{body})
+		if err != nil || len(results) != 1 {
+			writeError(w, http.StatusInternalServerError, "store entry failed")
+			return
+		}
+		writeForwardResult(w, results[0])
+	}
+}
+
+// decodeApproval decodes an approval entry for prescriptionID, writing a
+// 400 and returning false when body is not one.
+func decodeApproval(w http.ResponseWriter, body []byte, prescriptionID string) (evidence.EvidenceEntry, bool) {
+	var entry evidence.EvidenceEntry
+	if err := json.Unmarshal(body, &entry); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return entry, false
+	}
+	if entry.Type != evidence.EntryTypeApproval {
+		writeError(w, http.StatusBadRequest, "entry is not an approval")
+		return entry, false
+	}
+	var payload evidence.ApprovalPayload
+	if err := json.Unmarshal(entry.Payload, &payload); err != nil || payload.PrescriptionID != prescriptionID {
+		writeError(w, http.StatusBadRequest, "approval payload does not reference this prescription")
+		return entry, false
+	}
+	if payload.Decision != evidence.ApprovalApproved && payload.Decision != evidence.ApprovalRejected {
+		writeError(w, http.StatusBadRequest, "decision must be approved or rejected")
+		return entry, false
+	}
+	return entry, true
+}
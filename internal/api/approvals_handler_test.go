@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"samebits.com/evidra/internal/ingest"
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+type fakeApprovalStore struct {
+	entries []evidence.EvidenceEntry
+}
+
+func (f *fakeApprovalStore) ApprovalEntries(_ context.Context, _, sessionID string) ([]evidence.EvidenceEntry, error) {
+	var out []evidence.EvidenceEntry
+	for _, e := range f.entries {
+		if sessionID == "" || e.SessionID == sessionID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// fakeTenantKeyring serves one keyring for every tenant.
+type fakeTenantKeyring struct {
+	keyring *evidence.Keyring
+}
+
+func (f fakeTenantKeyring) Keyring(context.Context, string) (*evidence.Keyring, error) {
+	return f.keyring, nil
+}
+
+// recordingIngester accepts and keeps every entry.
+type recordingIngester struct {
+	lastTenant string
+	entries    []json.RawMessage
+}
+
+func (f *recordingIngester) Ingest(_ context.Context, tenantID string, raws []json.RawMessage) ([]ingest.Result, error) {
+	f.lastTenant = tenantID
+	results := make([]ingest.Result, len(raws))
+	for i, raw := range raws {
+		f.entries = append(f.entries, raw)
+		results[i] = ingest.Result{Index: i, Status: ingest.StatusAccepted, ReceiptID: "receipt-1"}
+	}
+	return results, nil
+}
+
+func TestApprovalsEndpoints(t *testing.T) {
+	t.Parallel()
+
+	agentSigner, approverSigner := testutil.TestSigner(t), testutil.TestSigner(t)
+	agent := lifecycle.NewService(lifecycle.Options{Signer: agentSigner, ApprovalRisk: "high"})
+	rx, err := agent.Prescribe(context.Background(), lifecycle.PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "delete",
+		RawArtifact: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n"),
+		SessionID:   "sess-1",
+	})
+	if err != nil || !rx.ApprovalRequired {
+		t.Fatalf("Prescribe = %+v, %v; want approval_required", rx, err)
+	}
+	approvals := &fakeApprovalStore{entries: []evidence.EvidenceEntry{rx.Entry}}
+	ingester := &recordingIngester{}
+	keys := fakeTenantKeyring{keyring: evidence.NewKeyring(agentSigner.PublicKey(), approverSigner.PublicKey())}
+	mux := NewRouter(RouterConfig{APIKey: "k", DefaultTenant: "t1", Approvals: approvals, ApprovalKeys: keys, Ingester: ingester})
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer k")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	decide := func(svc *lifecycle.Service) json.RawMessage {
+		t.Helper()
+		out, err := svc.Approve(context.Background(), lifecycle.ApproveInput{
+			PrescriptionID: rx.PrescriptionID,
+			Decision:       evidence.ApprovalApproved,
+			Actor:          evidence.Actor{ID: "alice"},
+			Prescription:   &rx.Entry,
+		})
+		if err != nil {
+			t.Fatalf("Approve: %v", err)
+		}
+		return out.RawEntry
+	}
+
+	rec := do("GET", "/v1/approvals?session_id=sess-1", nil)
+	var list struct {
+		Approvals []pendingApprovalResponse `json:"approvals"`
+		Total     int                       `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != 200 {
+		t.Fatalf("GET approvals status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if list.Total != 1 || list.Approvals[0].PrescriptionID != rx.PrescriptionID || len(list.Approvals[0].Entry) == 0 {
+		t.Fatalf("approvals = %+v", list)
+	}
+
+	decisionOf := func(id string) (int, string) {
+		t.Helper()
+		rec := do("GET", "/v1/approvals/"+id+"?session_id=sess-1", nil)
+		var resp struct {
+			Decision string `json:"decision"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp.Decision
+	}
+	if code, decision := decisionOf(rx.PrescriptionID); code != 200 || decision != "" {
+		t.Fatalf("GET decision before approval = %d %q", code, decision)
+	}
+	if code, _ := decisionOf("01UNKNOWN"); code != 404 {
+		t.Fatalf("GET decision of unknown prescription status=%d, want 404", code)
+	}
+
+	approval := decide(lifecycle.NewService(lifecycle.Options{Signer: approverSigner}))
+	if rec := do("POST", "/v1/approvals/01OTHER", approval); rec.Code != 400 {
+		t.Fatalf("approval for another prescription status=%d, want 400", rec.Code)
+	}
+	if rec := do("POST", "/v1/approvals/"+rx.PrescriptionID, rx.RawEntry); rec.Code != 400 {
+		t.Fatalf("non-approval entry status=%d, want 400", rec.Code)
+	}
+	if rec := do("POST", "/v1/approvals/"+rx.PrescriptionID, decide(lifecycle.NewService(lifecycle.Options{Signer: testutil.TestSigner(t)}))); rec.Code != 403 {
+		t.Fatalf("approval by an unregistered key status=%d, want 403", rec.Code)
+	}
+	var decided evidence.EvidenceEntry
+	_ = json.Unmarshal(approval, &decided)
+	selfSigned, err := evidence.BuildEntry(evidence.EntryBuildParams{
+		Type:      evidence.EntryTypeApproval,
+		SessionID: rx.SessionID,
+		Actor:     evidence.Actor{ID: "agent-1"},
+		Payload:   decided.Payload,
+		Signer:    agentSigner,
+	})
+	if err != nil {
+		t.Fatalf("BuildEntry: %v", err)
+	}
+	selfSignedRaw, _ := json.Marshal(selfSigned)
+	if rec := do("POST", "/v1/approvals/"+rx.PrescriptionID, selfSignedRaw); rec.Code != 403 {
+		t.Fatalf("self-signed approval status=%d, want 403", rec.Code)
+	}
+	tampered := decided
+	tampered.Payload = json.RawMessage(`{"prescription_id":"` + rx.PrescriptionID + `","decision":"approved","reason":"edited"}`)
+	tamperedRaw, _ := json.Marshal(tampered)
+	if rec := do("POST", "/v1/approvals/"+rx.PrescriptionID, tamperedRaw); rec.Code != 400 {
+		t.Fatalf("tampered approval status=%d, want 400", rec.Code)
+	}
+
+	if rec := do("POST", "/v1/approvals/"+rx.PrescriptionID, approval); rec.Code != 200 {
+		t.Fatalf("POST approval status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(ingester.entries) != 1 || ingester.lastTenant != "t1" {
+		t.Fatalf("stored entries = %d tenant=%q", len(ingester.entries), ingester.lastTenant)
+	}
+
+	var stored evidence.EvidenceEntry
+	_ = json.Unmarshal(ingester.entries[0], &stored)
+	approvals.entries = append(approvals.entries, stored)
+	if code, decision := decisionOf(rx.PrescriptionID); code != 200 || decision != evidence.ApprovalApproved {
+		t.Fatalf("GET decision after approval = %d %q", code, decision)
+	}
+	if rec := do("POST", "/v1/approvals/"+rx.PrescriptionID, approval); rec.Code != 409 {
+		t.Fatalf("second decision status=%d, want 409", rec.Code)
+	}
+	rec = do("GET", "/v1/approvals", nil)
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || list.Total != 0 {
+		t.Fatalf("approvals after decision = %s", rec.Body.String())
+	}
+}
+
+func TestSubmitApprovalRequiresVerifier(t *testing.T) {
+	t.Parallel()
+
+	mux := NewRouter(RouterConfig{APIKey: "k", DefaultTenant: "t1", Approvals: &fakeApprovalStore{}, RawStore: &fakeEntryStore{}})
+	req := httptest.NewRequest("POST", "/v1/approvals/01RX", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Authorization", "Bearer k")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != 503 {
+		t.Fatalf("status=%d, want 503", rec.Code)
+	}
+}
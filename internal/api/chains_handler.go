@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/ingest"
+	"samebits.com/evidra/internal/store"
+)
+
+// ChainStore reads the per-writer evidence chains of a tenant.
+type ChainStore interface {
+	ListChains(ctx context.Context, tenantID string) ([]store.Chain, error)
+	GetChain(ctx context.Context, tenantID, chainID string) (store.Chain, error)
+}
+
+// ChainValidator re-verifies one stored chain server-side.
+type ChainValidator interface {
+	Validate(ctx context.Context, tenantID, chainID string) (ingest.ChainReport, error)
+}
+
+// Chain validity values reported by the chains endpoints.
+const (
+	chainValidityUnverified = "unverified"
+	chainValidityValid      = "valid"
+	chainValidityInvalid    = "invalid"
+)
+
+type chainActor struct {
+	Type       string `json:"type,omitempty"`
+	ID         string `json:"id,omitempty"`
+	InstanceID string `json:"instance_id,omitempty"`
+}
+
+type chainResponse struct {
+	ChainID             string     `json:"chain_id"`
+	Writer              string     `json:"writer"`
+	KeyID               string     `json:"key_id,omitempty"`
+	Actor               chainActor `json:"actor"`
+	GenesisHash         string     `json:"genesis_hash"`
+	GenesisPreviousHash string     `json:"genesis_previous_hash,omitempty"`
+	Detached            bool       `json:"detached"`
+	HeadHash            string     `json:"head_hash"`
+	HeadEntryID         string     `json:"head_entry_id"`
+	Length              int64      `json:"length"`
+	FirstSeen           time.Time  `json:"first_seen"`
+	LastSeen            time.Time  `json:"last_seen"`
+	Validity            string     `json:"validity"`
+	ValidationError     string     `json:"validation_error,omitempty"`
+	ValidatedAt         *time.Time `json:"validated_at,omitempty"`
+}
+
+func toChainResponse(c store.Chain) chainResponse {
+	validity := chainValidityUnverified
+	if c.Valid != nil {
+		validity = chainValidityInvalid
+		if *c.Valid {
+			validity = chainValidityValid
+		}
+	}
+	return chainResponse{
+		ChainID:             c.ChainID,
+		Writer:              c.Writer,
+		KeyID:               c.KeyID,
+		Actor:               chainActor{Type: c.ActorType, ID: c.ActorID, InstanceID: c.InstanceID},
+		GenesisHash:         c.GenesisHash,
+		GenesisPreviousHash: c.GenesisPreviousHash,
+		Detached:            c.GenesisPreviousHash != "",
+		HeadHash:            c.HeadHash,
+		HeadEntryID:         c.HeadEntryID,
+		Length:              c.Length,
+		FirstSeen:           c.FirstSeen,
+		LastSeen:            c.LastSeen,
+		Validity:            validity,
+		ValidationError:     c.ValidationError,
+		ValidatedAt:         c.ValidatedAt,
+	}
+}
+
+func handleListChains(cs ChainStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chains, err := cs.ListChains(r.Context(), auth.TenantID(r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list chains failed")
+			return
+		}
+		writer := r.URL.Query().Get("writer")
+		out := make([]chainResponse, 0, len(chains))
+		for _, c := range chains {
+			if writer != "" && c.Writer != writer {
+				continue
+			}
+			out = append(out, toChainResponse(c))
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"chains": out})
+	}
+}
+
+func handleGetChain(cs ChainStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, err := cs.GetChain(r.Context(), auth.TenantID(r.Context()), r.PathValue("chain_id"))
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "chain not found")
+			} else {
+				writeError(w, http.StatusInternalServerError, "get chain failed")
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, toChainResponse(c))
+	}
+}
+
+func handleValidateChain(v ChainValidator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, err := v.Validate(r.Context(), auth.TenantID(r.Context()), r.PathValue("chain_id"))
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "chain not found")
+			} else {
+				writeError(w, http.StatusInternalServerError, "validate chain failed")
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+	}
+}
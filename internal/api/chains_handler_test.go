@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"samebits.com/evidra/internal/ingest"
+	"samebits.com/evidra/internal/store"
+)
+
+type fakeChainStore struct {
+	chains []store.Chain
+}
+
+func (f *fakeChainStore) ListChains(_ context.Context, tenantID string) ([]store.Chain, error) {
+	var out []store.Chain
+	for _, c := range f.chains {
+		if c.TenantID == tenantID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeChainStore) GetChain(_ context.Context, tenantID, chainID string) (store.Chain, error) {
+	for _, c := range f.chains {
+		if c.TenantID == tenantID && c.ChainID == chainID {
+			return c, nil
+		}
+	}
+	return store.Chain{}, store.ErrNotFound
+}
+
+func (f *fakeChainStore) Validate(ctx context.Context, tenantID, chainID string) (ingest.ChainReport, error) {
+	c, err := f.GetChain(ctx, tenantID, chainID)
+	if err != nil {
+		return ingest.ChainReport{}, err
+	}
+	return ingest.ChainReport{ChainID: c.ChainID, Valid: true, Length: c.Length, Checked: int(c.Length)}, nil
+}
+
+func TestChainsEndpoints(t *testing.T) {
+	t.Parallel()
+	valid := true
+	cs := &fakeChainStore{chains: []store.Chain{
+		{TenantID: "t1", ChainID: "c1", Writer: "key:k1", KeyID: "k1", HeadHash: "sha256:a", Length: 3, Valid: &valid},
+		{TenantID: "t1", ChainID: "c2", Writer: "actor:agent/a", GenesisPreviousHash: "sha256:x", Length: 1},
+		{TenantID: "t2", ChainID: "c3", Writer: "key:k1", Length: 1},
+	}}
+	mux := NewRouter(RouterConfig{APIKey: "k", DefaultTenant: "t1", Chains: cs, ChainValidator: cs})
+
+	tests := []struct {
+		name, method, path string
+		wantStatus         int
+		check              func(t *testing.T, body []byte)
+	}{
+		{
+			name: "list", method: "GET", path: "/v1/evidence/chains", wantStatus: 200,
+			check: func(t *testing.T, body []byte) {
+				var resp struct{ Chains []chainResponse }
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if len(resp.Chains) != 2 {
+					t.Fatalf("chains = %d, want the tenant's 2", len(resp.Chains))
+				}
+				if resp.Chains[0].Validity != chainValidityValid || resp.Chains[1].Validity != chainValidityUnverified || !resp.Chains[1].Detached {
+					t.Fatalf("chains = %+v", resp.Chains)
+				}
+			},
+		},
+		{
+			name: "list by writer", method: "GET", path: "/v1/evidence/chains?writer=key:k1", wantStatus: 200,
+			check: func(t *testing.T, body []byte) {
+				var resp struct{ Chains []chainResponse }
+				_ = json.Unmarshal(body, &resp)
+				if len(resp.Chains) != 1 || resp.Chains[0].ChainID != "c1" {
+					t.Fatalf("chains = %+v, want c1 only", resp.Chains)
+				}
+			},
+		},
+		{name: "get", method: "GET", path: "/v1/evidence/chains/c2", wantStatus: 200},
+		{name: "get other tenant", method: "GET", path: "/v1/evidence/chains/c3", wantStatus: 404},
+		{
+			name: "validate", method: "POST", path: "/v1/evidence/chains/c1/validate", wantStatus: 200,
+			check: func(t *testing.T, body []byte) {
+				var report ingest.ChainReport
+				_ = json.Unmarshal(body, &report)
+				if !report.Valid || report.Checked != 3 {
+					t.Fatalf("report = %+v", report)
+				}
+			},
+		},
+		{name: "validate unknown", method: "POST", path: "/v1/evidence/chains/nope/validate", wantStatus: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer k")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d body=%s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
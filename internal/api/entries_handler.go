@@ -1,51 +1,118 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"samebits.com/evidra/internal/auth"
 	"samebits.com/evidra/internal/store"
 )
 
-func handleListEntries(es *store.EntryStore) http.HandlerFunc {
+// EntryLister lists a tenant's stored entries.
+type EntryLister interface {
+	ListEntries(ctx context.Context, tenantID string, opts store.ListOptions) (store.EntryPage, error)
+}
+
+func handleListEntries(es EntryLister) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tenantID := auth.TenantID(r.Context())
-		q := r.URL.Query()
+		opts, err := parseListOptions(r.URL.Query())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
-		limit, _ := strconv.Atoi(q.Get("limit"))
-		offset, _ := strconv.Atoi(q.Get("offset"))
+		page, err := es.ListEntries(r.Context(), tenantID, opts)
+		if err != nil {
+			if errors.Is(err, store.ErrInvalidCursor) {
+				writeError(w, http.StatusBadRequest, "invalid cursor")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "list entries failed")
+			return
+		}
 
-		if limit < 0 {
-			limit = 0
+		// Echo resolved limit (after defaults) so clients know the effective page size.
+		resp := map[string]interface{}{
+			"entries": toEntryAPIResponses(page.Entries),
+			"limit":   opts.Resolved().Limit,
+			"offset":  opts.Offset,
+		}
+		if page.Total >= 0 {
+			resp["total"] = page.Total
 		}
-		if offset < 0 {
-			offset = 0
+		if page.NextCursor != "" {
+			resp["next_cursor"] = page.NextCursor
 		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
 
-		opts := store.ListOptions{
-			Limit:     limit,
-			Offset:    offset,
-			EntryType: q.Get("type"),
-			Period:    q.Get("period"),
-			SessionID: q.Get("session_id"),
+// parseListOptions reads entry filters from query parameters. Comma-separated
+// values of type, effective_risk and verdict match any of the values.
+func parseListOptions(q url.Values) (store.ListOptions, error) {
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	opts := store.ListOptions{
+		Limit:          max(limit, 0),
+		Offset:         max(offset, 0),
+		Cursor:         q.Get("cursor"),
+		Types:          splitList(q.Get("type")),
+		Period:         q.Get("period"),
+		SessionID:      q.Get("session_id"),
+		ActorID:        q.Get("actor"),
+		Tool:           q.Get("tool"),
+		OperationClass: q.Get("operation_class"),
+		ScopeClass:     q.Get("scope"),
+		EffectiveRisks: splitList(strings.ToLower(q.Get("effective_risk"))),
+		Verdicts:       splitList(q.Get("verdict")),
+		IntentDigest:   q.Get("intent_digest"),
+		ArtifactDigest: q.Get("artifact_digest"),
+	}
+	if opts.Cursor != "" && opts.Offset > 0 {
+		return store.ListOptions{}, errors.New("cursor and offset cannot be combined")
+	}
+	for _, dim := range q["scope_dimension"] {
+		key, value, ok := strings.Cut(dim, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return store.ListOptions{}, fmt.Errorf("invalid scope_dimension %q (expected key=value)", dim)
+		}
+		if opts.ScopeDimensions == nil {
+			opts.ScopeDimensions = map[string]string{}
+		}
+		opts.ScopeDimensions[strings.TrimSpace(key)] = value
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &opts.Since}, {"until", &opts.Until}} {
+		name, raw := bound.name, q.Get(bound.name)
+		if raw == "" {
+			continue
 		}
-		entries, total, err := es.ListEntries(r.Context(), tenantID, opts)
+		t, err := time.Parse(time.RFC3339, raw)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "list entries failed")
-			return
+			return store.ListOptions{}, fmt.Errorf("invalid %s %q (expected RFC 3339 time)", name, raw)
 		}
+		*bound.dst = t
+	}
+	return opts, nil
+}
 
-		// Echo resolved limit (after defaults) so clients know the effective page size.
-		resolvedLimit := opts.Resolved().Limit
-		writeJSON(w, http.StatusOK, map[string]interface{}{
-			"entries": toEntryAPIResponses(entries),
-			"total":   total,
-			"limit":   resolvedLimit,
-			"offset":  offset,
-		})
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
 	}
+	return out
 }
 
 func handleGetEntry(es *store.EntryStore) http.HandlerFunc {
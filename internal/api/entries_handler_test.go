@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/store"
+)
+
+type fakeEntryLister struct {
+	got  store.ListOptions
+	page store.EntryPage
+	err  error
+}
+
+func (f *fakeEntryLister) ListEntries(_ context.Context, _ string, opts store.ListOptions) (store.EntryPage, error) {
+	f.got = opts
+	return f.page, f.err
+}
+
+func TestListEntriesEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		lister     fakeEntryLister
+		wantStatus int
+		check      func(t *testing.T, got store.ListOptions, body map[string]interface{})
+	}{
+		{
+			name: "filters",
+			query: "?type=prescribe,report&actor=agent-a&tool=kubectl&operation_class=destructive&scope=production" +
+				"&effective_risk=High,critical&verdict=declined&intent_digest=i1&artifact_digest=a1" +
+				"&scope_dimension=cluster=prod-eu&scope_dimension=namespace=payments" +
+				"&since=2026-03-01T00:00:00Z&until=2026-03-02T00:00:00Z&limit=20",
+			lister:     fakeEntryLister{page: store.EntryPage{Total: 3}},
+			wantStatus: 200,
+			check: func(t *testing.T, got store.ListOptions, body map[string]interface{}) {
+				if len(got.Types) != 2 || got.ActorID != "agent-a" || got.Tool != "kubectl" ||
+					got.OperationClass != "destructive" || got.ScopeClass != "production" ||
+					got.IntentDigest != "i1" || got.ArtifactDigest != "a1" || got.Limit != 20 {
+					t.Fatalf("options = %+v", got)
+				}
+				if len(got.EffectiveRisks) != 2 || got.EffectiveRisks[0] != "high" || got.Verdicts[0] != "declined" {
+					t.Fatalf("risks = %v, verdicts = %v", got.EffectiveRisks, got.Verdicts)
+				}
+				if got.ScopeDimensions["cluster"] != "prod-eu" || got.ScopeDimensions["namespace"] != "payments" {
+					t.Fatalf("scope dimensions = %v", got.ScopeDimensions)
+				}
+				if !got.Since.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || got.Until.IsZero() {
+					t.Fatalf("since = %v, until = %v", got.Since, got.Until)
+				}
+				if body["total"] != float64(3) {
+					t.Fatalf("total = %v", body["total"])
+				}
+				if _, ok := body["next_cursor"]; ok {
+					t.Fatal("last page has next_cursor")
+				}
+			},
+		},
+		{
+			name:       "cursor page",
+			query:      "?cursor=abc",
+			lister:     fakeEntryLister{page: store.EntryPage{Entries: []store.StoredEntry{{ID: "e1"}}, Total: -1, NextCursor: "def"}},
+			wantStatus: 200,
+			check: func(t *testing.T, got store.ListOptions, body map[string]interface{}) {
+				if got.Cursor != "abc" {
+					t.Fatalf("cursor = %q", got.Cursor)
+				}
+				if body["next_cursor"] != "def" {
+					t.Fatalf("next_cursor = %v", body["next_cursor"])
+				}
+				if _, ok := body["total"]; ok {
+					t.Fatal("cursor page reports total")
+				}
+			},
+		},
+		{name: "invalid cursor", query: "?cursor=bogus", lister: fakeEntryLister{err: store.ErrInvalidCursor}, wantStatus: 400},
+		{name: "cursor with offset", query: "?cursor=abc&offset=10", wantStatus: 400},
+		{name: "bad since", query: "?since=yesterday", wantStatus: 400},
+		{name: "bad scope dimension", query: "?scope_dimension=cluster", wantStatus: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lister := tt.lister
+			req := httptest.NewRequest("GET", "/v1/evidence/entries"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handleListEntries(&lister).ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.check != nil {
+				var body map[string]interface{}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				tt.check(t, lister.got, body)
+			}
+		})
+	}
+}
@@ -20,6 +20,26 @@ type entryAPIResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type entryPayloadFields struct {
+	Tool          string `json:"tool"`
+	Operation     string `json:"operation"`
+	EffectiveRisk string `json:"effective_risk"`
+	RiskLevel     string `json:"risk_level"`
+	Actor         struct {
+		ID string `json:"id"`
+	} `json:"actor"`
+	CanonicalAction struct {
+		Tool           string `json:"tool"`
+		Operation      string `json:"operation"`
+		OperationClass string `json:"operation_class"`
+		ScopeClass     string `json:"scope_class"`
+	} `json:"canonical_action"`
+	Verdict  string          `json:"verdict"`
+	ExitCode *int            `json:"exit_code"`
+	Scope    string          `json:"scope"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
 func toEntryAPIResponse(e store.StoredEntry) entryAPIResponse {
 	resp := entryAPIResponse{
 		ID:        e.ID,
@@ -27,48 +47,27 @@ func toEntryAPIResponse(e store.StoredEntry) entryAPIResponse {
 		CreatedAt: e.CreatedAt,
 	}
 
-	// Extract fields from Payload JSON.
-	var payload struct {
-		Tool          string `json:"tool"`
-		Operation     string `json:"operation"`
-		EffectiveRisk string `json:"effective_risk"`
-		RiskLevel     string `json:"risk_level"`
-		Actor         struct {
-			ID string `json:"id"`
-		} `json:"actor"`
-		CanonicalAction struct {
-			Tool           string `json:"tool"`
-			Operation      string `json:"operation"`
-			OperationClass string `json:"operation_class"`
-			ScopeClass     string `json:"scope_class"`
-		} `json:"canonical_action"`
-		Verdict  string `json:"verdict"`
-		ExitCode *int   `json:"exit_code"`
-		Scope    string `json:"scope"`
+	// Stored payloads are either the bare entry payload or the full evidence
+	// entry, whose fields sit one level down under "payload".
+	var top entryPayloadFields
+	_ = json.Unmarshal(e.Payload, &top)
+	var nested entryPayloadFields
+	if len(top.Payload) > 0 {
+		_ = json.Unmarshal(top.Payload, &nested)
 	}
-	_ = json.Unmarshal(e.Payload, &payload)
 
-	resp.Actor = payload.Actor.ID
-	resp.Verdict = payload.Verdict
-	resp.ExitCode = payload.ExitCode
-	resp.RiskLevel = payload.EffectiveRisk
-	if resp.RiskLevel == "" {
-		resp.RiskLevel = payload.RiskLevel
+	resp.Actor = top.Actor.ID
+	resp.Verdict = firstNonEmpty(top.Verdict, nested.Verdict)
+	resp.ExitCode = top.ExitCode
+	if resp.ExitCode == nil {
+		resp.ExitCode = nested.ExitCode
 	}
+	resp.RiskLevel = firstNonEmpty(top.EffectiveRisk, top.RiskLevel, nested.EffectiveRisk, nested.RiskLevel)
 
 	// Prefer canonical_action fields, fall back to top-level.
-	resp.Tool = payload.CanonicalAction.Tool
-	if resp.Tool == "" {
-		resp.Tool = payload.Tool
-	}
-	resp.Operation = payload.CanonicalAction.Operation
-	if resp.Operation == "" {
-		resp.Operation = payload.Operation
-	}
-	resp.Scope = payload.CanonicalAction.ScopeClass
-	if resp.Scope == "" {
-		resp.Scope = payload.Scope
-	}
+	resp.Tool = firstNonEmpty(top.CanonicalAction.Tool, top.Tool, nested.CanonicalAction.Tool, nested.Tool)
+	resp.Operation = firstNonEmpty(top.CanonicalAction.Operation, top.Operation, nested.CanonicalAction.Operation, nested.Operation)
+	resp.Scope = firstNonEmpty(top.CanonicalAction.ScopeClass, top.Scope, nested.CanonicalAction.ScopeClass, nested.Scope)
 
 	return resp
 }
@@ -80,3 +79,12 @@ func toEntryAPIResponses(entries []store.StoredEntry) []entryAPIResponse {
 	}
 	return out
 }
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
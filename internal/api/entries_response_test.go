@@ -46,3 +46,30 @@ func TestToEntryAPIResponse_UsesEffectiveRiskFromPrescriptionPayload(t *testing.
 		t.Fatalf("Scope = %q, want production", got.Scope)
 	}
 }
+
+func TestToEntryAPIResponse_ReadsFullEvidenceEntry(t *testing.T) {
+	t.Parallel()
+
+	payload, err := json.Marshal(evidence.PrescriptionPayload{
+		PrescriptionID:  "rx-1",
+		CanonicalAction: json.RawMessage(`{"tool":"terraform","operation":"apply","scope_class":"staging"}`),
+		EffectiveRisk:   "critical",
+	})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	entry, err := json.Marshal(evidence.EvidenceEntry{
+		EntryID: "entry-1",
+		Type:    evidence.EntryTypePrescribe,
+		Actor:   evidence.Actor{Type: "agent", ID: "agent-a"},
+		Payload: payload,
+	})
+	if err != nil {
+		t.Fatalf("marshal entry: %v", err)
+	}
+
+	got := toEntryAPIResponse(store.StoredEntry{ID: "entry-1", EntryType: "prescribe", Payload: entry})
+	if got.Actor != "agent-a" || got.Tool != "terraform" || got.Scope != "staging" || got.RiskLevel != "critical" {
+		t.Fatalf("response = %+v", got)
+	}
+}
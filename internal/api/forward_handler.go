@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/ingest"
 )
 
 // RawEntryStore persists raw evidence entries.
@@ -15,7 +16,13 @@ type RawEntryStore interface {
 	SaveRaw(ctx context.Context, tenantID string, raw json.RawMessage) (receiptID string, err error)
 }
 
-func handleForward(store RawEntryStore) http.HandlerFunc {
+// EntryIngester verifies forwarded entries before storing them. It returns
+// one result per entry, in order.
+type EntryIngester interface {
+	Ingest(ctx context.Context, tenantID string, raws []json.RawMessage) ([]ingest.Result, error)
+}
+
+func handleForward(store RawEntryStore, ingester EntryIngester) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tenantID := auth.TenantID(r.Context())
 
@@ -30,6 +37,16 @@ func handleForward(store RawEntryStore) http.HandlerFunc {
 			return
 		}
 
+		if ingester != nil {
+			results, err := ingester.Ingest(r.Context(), tenantID, []json.RawMessage{body})
+			if err != nil || len(results) != 1 {
+				writeError(w, http.StatusInternalServerError, "store entry failed")
+				return
+			}
+			writeForwardResult(w, results[0])
+			return
+		}
+
 		receiptID, err := store.SaveRaw(r.Context(), tenantID, body)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "store entry failed")
@@ -43,7 +60,33 @@ func handleForward(store RawEntryStore) http.HandlerFunc {
 	}
 }
 
-func handleBatch(store RawEntryStore) http.HandlerFunc {
+func writeForwardResult(w http.ResponseWriter, res ingest.Result) {
+	switch {
+	case res.Status == ingest.StatusAccepted:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"receipt_id": res.ReceiptID,
+			"status":     res.Status,
+		})
+	case res.Status == ingest.StatusQuarantined:
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"entry_id": res.EntryID,
+			"status":   res.Status,
+			"code":     res.Code,
+			"error":    res.Message,
+		})
+	case res.Code == ingest.CodeStoreFailed:
+		writeError(w, http.StatusInternalServerError, "store entry failed")
+	default:
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"entry_id": res.EntryID,
+			"status":   res.Status,
+			"code":     res.Code,
+			"error":    res.Message,
+		})
+	}
+}
+
+func handleBatch(store RawEntryStore, ingester EntryIngester) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tenantID := auth.TenantID(r.Context())
 
@@ -59,6 +102,16 @@ func handleBatch(store RawEntryStore) http.HandlerFunc {
 			return
 		}
 
+		if ingester != nil {
+			results, err := ingester.Ingest(r.Context(), tenantID, req.Entries)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "store entries failed")
+				return
+			}
+			writeBatchResults(w, results)
+			return
+		}
+
 		accepted := 0
 		var errs []string
 		for i, entry := range req.Entries {
@@ -76,3 +129,31 @@ func handleBatch(store RawEntryStore) http.HandlerFunc {
 		})
 	}
 }
+
+// writeBatchResults reports counts, the legacy errors strings, and the
+// structured per-entry results.
+func writeBatchResults(w http.ResponseWriter, results []ingest.Result) {
+	var (
+		accepted, rejected, quarantined int
+		errs                            []string
+	)
+	for _, res := range results {
+		switch res.Status {
+		case ingest.StatusAccepted:
+			accepted++
+			continue
+		case ingest.StatusQuarantined:
+			quarantined++
+		default:
+			rejected++
+		}
+		errs = append(errs, fmt.Sprintf("entry %d: %s: %s", res.Index, res.Code, res.Message))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"accepted":    accepted,
+		"rejected":    rejected,
+		"quarantined": quarantined,
+		"errors":      errs,
+		"results":     results,
+	})
+}
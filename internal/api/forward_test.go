@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http/httptest"
 	"testing"
 
 	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/ingest"
+	"samebits.com/evidra/pkg/client"
 )
 
 type fakeEntryStore struct {
@@ -24,7 +27,7 @@ func (f *fakeEntryStore) SaveRaw(ctx context.Context, tenantID string, raw json.
 func TestForwardHandler_Success(t *testing.T) {
 	t.Parallel()
 	store := &fakeEntryStore{}
-	handler := handleForward(store)
+	handler := handleForward(store, nil)
 
 	body := []byte(`{"type":"prescribe","hash":"sha256:abc"}`)
 	req := httptest.NewRequest("POST", "/v1/evidence/forward", bytes.NewReader(body))
@@ -43,7 +46,7 @@ func TestForwardHandler_Success(t *testing.T) {
 func TestForwardHandler_EmptyBody(t *testing.T) {
 	t.Parallel()
 	store := &fakeEntryStore{}
-	handler := handleForward(store)
+	handler := handleForward(store, nil)
 
 	req := httptest.NewRequest("POST", "/v1/evidence/forward", nil)
 	req = req.WithContext(auth.WithTenantID(req.Context(), "t1"))
@@ -58,7 +61,7 @@ func TestForwardHandler_EmptyBody(t *testing.T) {
 func TestBatchHandler_Success(t *testing.T) {
 	t.Parallel()
 	store := &fakeEntryStore{}
-	handler := handleBatch(store)
+	handler := handleBatch(store, nil)
 
 	body := []byte(`{"entries":[{"type":"prescribe"},{"type":"report"}]}`)
 	req := httptest.NewRequest("POST", "/v1/evidence/batch", bytes.NewReader(body))
@@ -77,3 +80,95 @@ func TestBatchHandler_Success(t *testing.T) {
 		t.Fatalf("expected accepted=2, got %v", resp["accepted"])
 	}
 }
+
+// fakeIngester reports a fixed result per entry.
+type fakeIngester struct {
+	results []ingest.Result
+	err     error
+}
+
+func (f *fakeIngester) Ingest(_ context.Context, _ string, raws []json.RawMessage) ([]ingest.Result, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.results[:len(raws)], nil
+}
+
+func TestForwardHandler_VerificationStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		result ingest.Result
+		want   int
+	}{
+		{name: "accepted", result: ingest.Result{Status: ingest.StatusAccepted, ReceiptID: "e1"}, want: 200},
+		{name: "quarantined", result: ingest.Result{Status: ingest.StatusQuarantined, Code: ingest.CodeChainBreak}, want: 202},
+		{name: "rejected", result: ingest.Result{Status: ingest.StatusRejected, Code: ingest.CodeBadSignature}, want: 422},
+		{name: "store failed", result: ingest.Result{Status: ingest.StatusRejected, Code: ingest.CodeStoreFailed}, want: 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &fakeEntryStore{}
+			handler := handleForward(store, &fakeIngester{results: []ingest.Result{tt.result}})
+
+			req := httptest.NewRequest("POST", "/v1/evidence/forward", bytes.NewReader([]byte(`{"type":"prescribe"}`)))
+			req = req.WithContext(auth.WithTenantID(req.Context(), "t1"))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d body=%s", rec.Code, tt.want, rec.Body.String())
+			}
+			if len(store.entries) != 0 {
+				t.Fatal("ingester path must not call SaveRaw directly")
+			}
+		})
+	}
+}
+
+func TestBatchHandler_PerEntryResults(t *testing.T) {
+	t.Parallel()
+	handler := handleBatch(&fakeEntryStore{}, &fakeIngester{results: []ingest.Result{
+		{Index: 0, EntryID: "a", Status: ingest.StatusAccepted},
+		{Index: 1, EntryID: "b", Status: ingest.StatusRejected, Code: ingest.CodeUnknownKey, Message: "entry_unknown_key"},
+		{Index: 2, EntryID: "c", Status: ingest.StatusQuarantined, Code: ingest.CodeChainBreak, Message: "previous_hash missing"},
+	}})
+
+	body := []byte(`{"entries":[{"entry_id":"a"},{"entry_id":"b"},{"entry_id":"c"}]}`)
+	req := httptest.NewRequest("POST", "/v1/evidence/batch", bytes.NewReader(body))
+	req = req.WithContext(auth.WithTenantID(req.Context(), "t1"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp client.BatchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Accepted != 1 || resp.Rejected != 1 || resp.Quarantined != 1 {
+		t.Fatalf("counts = %+v", resp)
+	}
+	if len(resp.Results) != 3 || resp.Results[1].Code != ingest.CodeUnknownKey || resp.Results[1].EntryID != "b" {
+		t.Fatalf("results = %+v", resp.Results)
+	}
+	if len(resp.Errors) != 2 {
+		t.Fatalf("errors = %v, want 2", resp.Errors)
+	}
+}
+
+func TestBatchHandler_IngesterFailure(t *testing.T) {
+	t.Parallel()
+	handler := handleBatch(&fakeEntryStore{}, &fakeIngester{err: errors.New("db down")})
+
+	req := httptest.NewRequest("POST", "/v1/evidence/batch", bytes.NewReader([]byte(`{"entries":[{}]}`)))
+	req = req.WithContext(auth.WithTenantID(req.Context(), "t1"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != 500 {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+}
@@ -3,17 +3,26 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"samebits.com/evidra/internal/api"
 	"samebits.com/evidra/internal/db"
+	"samebits.com/evidra/internal/notify"
 	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
 )
 
 func TestIntegration_FullLifecycle(t *testing.T) {
@@ -40,6 +49,7 @@ func TestIntegration_FullLifecycle(t *testing.T) {
 		RawStore:      es,
 		KeyStore:      ks,
 		Pinger:        pool,
+		Chains:        es,
 	})
 
 	srv := httptest.NewServer(router)
@@ -131,4 +141,235 @@ func TestIntegration_FullLifecycle(t *testing.T) {
 			t.Fatalf("expected status accepted, got %v", body["status"])
 		}
 	})
+
+	t.Run("chains_per_writer", func(t *testing.T) {
+		ctx := context.Background()
+		signer := testutil.TestSigner(t)
+		build := func(prev, n string) json.RawMessage {
+			entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+				Type:         evidence.EntryTypeSignal,
+				SessionID:    "chain-session",
+				TraceID:      "chain-session",
+				Actor:        evidence.Actor{Type: "agent", ID: "integration", Provenance: "test"},
+				Payload:      json.RawMessage(`{"n":` + n + `}`),
+				PreviousHash: prev,
+				SpecVersion:  "0.3.0",
+				Signer:       signer,
+			})
+			if err != nil {
+				t.Fatalf("BuildEntry: %v", err)
+			}
+			raw, _ := json.Marshal(entry)
+			return raw
+		}
+		hashOf := func(raw json.RawMessage) string {
+			var e evidence.EvidenceEntry
+			_ = json.Unmarshal(raw, &e)
+			return e.Hash
+		}
+
+		first := build("", "1")
+		second := build(hashOf(first), "2")
+		fork := build(hashOf(first), "3")
+		for _, raw := range []json.RawMessage{first, second} {
+			if _, err := es.SaveRaw(ctx, "test-tenant", raw); err != nil {
+				t.Fatalf("SaveRaw: %v", err)
+			}
+		}
+		if _, err := es.SaveRaw(ctx, "test-tenant", fork); !errors.Is(err, store.ErrChainFork) {
+			t.Fatalf("SaveRaw(fork) err = %v, want ErrChainFork", err)
+		}
+
+		writer := store.ChainWriter(evidence.KeyID(signer.PublicKey()), evidence.Actor{})
+		head, err := es.ChainHead(ctx, "test-tenant", writer)
+		if err != nil || head != hashOf(second) {
+			t.Fatalf("ChainHead = %q, %v; want %s", head, err, hashOf(second))
+		}
+
+		req, _ := http.NewRequest("GET", srv.URL+"/v1/evidence/chains?writer="+url.QueryEscape(writer), nil)
+		req.Header.Set("Authorization", "Bearer "+testAPIKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /v1/evidence/chains: %v", err)
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Chains []struct {
+				Length   int64  `json:"length"`
+				HeadHash string `json:"head_hash"`
+			} `json:"chains"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(body.Chains) != 1 || body.Chains[0].Length != 2 || body.Chains[0].HeadHash != hashOf(second) {
+			t.Fatalf("chains = %+v", body.Chains)
+		}
+	})
+
+	t.Run("stream_notifies_on_save", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		head, err := es.StreamHead(ctx, "test-tenant")
+		if err != nil {
+			t.Fatalf("StreamHead: %v", err)
+		}
+
+		notified := make(chan string, 1)
+		listening := make(chan error, 1)
+		go func() {
+			listening <- es.ListenEntries(ctx, func(tenantID string) {
+				select {
+				case notified <- tenantID:
+				default:
+				}
+			})
+		}()
+		// LISTEN must be registered before the save; give it a moment.
+		time.Sleep(200 * time.Millisecond)
+
+		entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+			Type:        evidence.EntryTypeSignal,
+			SessionID:   "stream-session",
+			TraceID:     "stream-session",
+			Actor:       evidence.Actor{Type: "agent", ID: "stream", Provenance: "test"},
+			Payload:     json.RawMessage(`{"n":1}`),
+			SpecVersion: "0.3.0",
+			Signer:      testutil.TestSigner(t),
+		})
+		if err != nil {
+			t.Fatalf("BuildEntry: %v", err)
+		}
+		raw, _ := json.Marshal(entry)
+		if _, err := es.SaveRaw(ctx, "test-tenant", raw); err != nil {
+			t.Fatalf("SaveRaw: %v", err)
+		}
+
+		select {
+		case tenantID := <-notified:
+			if tenantID != "test-tenant" {
+				t.Fatalf("notified tenant = %q", tenantID)
+			}
+		case err := <-listening:
+			t.Fatalf("ListenEntries: %v", err)
+		case <-ctx.Done():
+			t.Fatal("no notification after SaveRaw")
+		}
+
+		got, err := es.EntriesSince(ctx, "test-tenant", head, store.StreamFilter{SessionID: "stream-session"}, 10)
+		if err != nil {
+			t.Fatalf("EntriesSince: %v", err)
+		}
+		if len(got) != 1 || got[0].Entry.ID != entry.EntryID || got[0].Seq <= head {
+			t.Fatalf("EntriesSince = %+v", got)
+		}
+	})
+
+	t.Run("notification_delivered", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		received := make(chan []byte, 4)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- body
+		}))
+		defer receiver.Close()
+
+		ns := store.NewNotificationStore(pool)
+		rule, err := ns.CreateRule(ctx, store.NotificationRule{
+			TenantID:   "test-tenant",
+			Target:     notify.TargetHTTP,
+			URL:        receiver.URL,
+			Conditions: store.NotificationConditions{MinRisk: "critical"},
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatalf("CreateRule: %v", err)
+		}
+		t.Cleanup(func() { _ = ns.DeleteRule(context.Background(), "test-tenant", rule.ID) })
+
+		payload, _ := json.Marshal(evidence.PrescriptionPayload{
+			CanonicalAction: json.RawMessage(`{"tool":"kubectl","operation":"delete","operation_class":"destroy","scope_class":"production"}`),
+			EffectiveRisk:   "critical",
+		})
+		entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+			Type:        evidence.EntryTypePrescribe,
+			SessionID:   "notify-session",
+			TraceID:     "notify-session",
+			Actor:       evidence.Actor{Type: "agent", ID: "notify", Provenance: "test"},
+			Payload:     payload,
+			SpecVersion: "0.3.0",
+			Signer:      testutil.TestSigner(t),
+		})
+		if err != nil {
+			t.Fatalf("BuildEntry: %v", err)
+		}
+		raw, _ := json.Marshal(entry)
+		if _, err := es.SaveRaw(ctx, "test-tenant", raw); err != nil {
+			t.Fatalf("SaveRaw: %v", err)
+		}
+
+		if err := notify.NewWorker(ns, es, nil, notify.EgressPolicy{Allowed: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}}).Tick(ctx); err != nil {
+			t.Fatalf("Tick: %v", err)
+		}
+		select {
+		case body := <-received:
+			if !strings.Contains(string(body), entry.EntryID) {
+				t.Fatalf("notification body = %s", body)
+			}
+		case <-ctx.Done():
+			t.Fatal("no notification received")
+		}
+		deliveries, err := ns.ListDeliveries(ctx, "test-tenant", rule.ID, 10)
+		if err != nil || len(deliveries) != 1 || deliveries[0].Status != store.DeliveryDelivered || deliveries[0].Attempts != 1 {
+			t.Fatalf("deliveries = %+v, %v", deliveries, err)
+		}
+	})
+
+	t.Run("entries_cursor_and_filters", func(t *testing.T) {
+		ctx := context.Background()
+		signer := testutil.TestSigner(t)
+		prev := ""
+		for i, scope := range []string{"production", "staging", "production"} {
+			payload, _ := json.Marshal(evidence.PrescriptionPayload{
+				CanonicalAction: json.RawMessage(`{"tool":"helm","operation":"upgrade","operation_class":"mutate","scope_class":"` + scope + `"}`),
+				EffectiveRisk:   "high",
+			})
+			entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+				Type:            evidence.EntryTypePrescribe,
+				SessionID:       "page-session",
+				TraceID:         "page-session",
+				Actor:           evidence.Actor{Type: "agent", ID: "pager", Provenance: "test"},
+				Payload:         payload,
+				ScopeDimensions: map[string]string{"cluster": "c" + strconv.Itoa(i%2)},
+				PreviousHash:    prev,
+				SpecVersion:     "0.3.0",
+				Signer:          signer,
+			})
+			if err != nil {
+				t.Fatalf("BuildEntry: %v", err)
+			}
+			prev = entry.Hash
+			raw, _ := json.Marshal(entry)
+			if _, err := es.SaveRaw(ctx, "test-tenant", raw); err != nil {
+				t.Fatalf("SaveRaw: %v", err)
+			}
+		}
+
+		opts := store.ListOptions{Limit: 1, ActorID: "pager", ScopeClass: "production", EffectiveRisks: []string{"high"}}
+		first, err := es.ListEntries(ctx, "test-tenant", opts)
+		if err != nil || len(first.Entries) != 1 || first.Total != 2 || first.NextCursor == "" {
+			t.Fatalf("first page = %+v, %v", first, err)
+		}
+		opts.Cursor = first.NextCursor
+		second, err := es.ListEntries(ctx, "test-tenant", opts)
+		if err != nil || len(second.Entries) != 1 || second.NextCursor != "" || second.Entries[0].ID == first.Entries[0].ID {
+			t.Fatalf("second page = %+v, %v", second, err)
+		}
+
+		dims, err := es.ListEntries(ctx, "test-tenant", store.ListOptions{ActorID: "pager", ScopeDimensions: map[string]string{"cluster": "c1"}})
+		if err != nil || dims.Total != 1 {
+			t.Fatalf("scope dimension page = %+v, %v", dims, err)
+		}
+	})
 }
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/notify"
+	"samebits.com/evidra/internal/store"
+)
+
+// NotificationRuleStore manages a tenant's outbound notification rules and
+// their delivery log.
+type NotificationRuleStore interface {
+	CreateRule(ctx context.Context, rule store.NotificationRule) (store.NotificationRule, error)
+	ListRules(ctx context.Context, tenantID string) ([]store.NotificationRule, error)
+	GetRule(ctx context.Context, tenantID, ruleID string) (store.NotificationRule, error)
+	DeleteRule(ctx context.Context, tenantID, ruleID string) error
+	ListDeliveries(ctx context.Context, tenantID, ruleID string, limit int) ([]store.NotificationDelivery, error)
+}
+
+type notificationRuleResponse struct {
+	ID         string                       `json:"id"`
+	Name       string                       `json:"name,omitempty"`
+	Target     string                       `json:"target"`
+	URL        string                       `json:"url"`
+	Conditions store.NotificationConditions `json:"conditions"`
+	Enabled    bool                         `json:"enabled"`
+	CreatedAt  time.Time                    `json:"created_at"`
+}
+
+func toNotificationRuleResponse(r store.NotificationRule) notificationRuleResponse {
+	return notificationRuleResponse{
+		ID:         r.ID,
+		Name:       r.Name,
+		Target:     r.Target,
+		URL:        r.URL,
+		Conditions: r.Conditions,
+		Enabled:    r.Enabled,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+type notificationDeliveryResponse struct {
+	ID             string     `json:"id"`
+	RuleID         string     `json:"rule_id"`
+	EntryID        string     `json:"entry_id"`
+	Signal         string     `json:"signal,omitempty"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+func toNotificationDeliveryResponse(d store.NotificationDelivery) notificationDeliveryResponse {
+	resp := notificationDeliveryResponse{
+		ID:             d.ID,
+		RuleID:         d.RuleID,
+		EntryID:        d.EntryID,
+		Signal:         d.Signal,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	if d.Status == store.DeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
+
+func handleCreateNotificationRule(ns NotificationRuleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name       string                       `json:"name"`
+			Target     string                       `json:"target"`
+			URL        string                       `json:"url"`
+			Conditions store.NotificationConditions `json:"conditions"`
+			Enabled    *bool                        `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		rule := store.NotificationRule{
+			TenantID:   auth.TenantID(r.Context()),
+			Name:       req.Name,
+			Target:     req.Target,
+			URL:        req.URL,
+			Conditions: req.Conditions,
+			Enabled:    req.Enabled == nil || *req.Enabled,
+		}
+		if err := notify.ValidateRule(rule); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		created, err := ns.CreateRule(r.Context(), rule)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "create notification rule failed")
+			return
+		}
+		writeJSON(w, http.StatusCreated, toNotificationRuleResponse(created))
+	}
+}
+
+func handleListNotificationRules(ns NotificationRuleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rules, err := ns.ListRules(r.Context(), auth.TenantID(r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list notification rules failed")
+			return
+		}
+		out := make([]notificationRuleResponse, 0, len(rules))
+		for _, rule := range rules {
+			out = append(out, toNotificationRuleResponse(rule))
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"rules": out})
+	}
+}
+
+func handleGetNotificationRule(ns NotificationRuleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rule, err := ns.GetRule(r.Context(), auth.TenantID(r.Context()), r.PathValue("rule_id"))
+		if err != nil {
+			writeNotificationRuleError(w, err, "get notification rule failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, toNotificationRuleResponse(rule))
+	}
+}
+
+func handleDeleteNotificationRule(ns NotificationRuleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ruleID := r.PathValue("rule_id")
+		if err := ns.DeleteRule(r.Context(), auth.TenantID(r.Context()), ruleID); err != nil {
+			writeNotificationRuleError(w, err, "delete notification rule failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"id": ruleID, "status": "deleted"})
+	}
+}
+
+func handleListNotificationDeliveries(ns NotificationRuleStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenantID := auth.TenantID(r.Context())
+		ruleID := r.PathValue("rule_id")
+		limit := 100
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > 500 {
+				writeError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+				return
+			}
+			limit = n
+		}
+		if _, err := ns.GetRule(r.Context(), tenantID, ruleID); err != nil {
+			writeNotificationRuleError(w, err, "list notification deliveries failed")
+			return
+		}
+
+		deliveries, err := ns.ListDeliveries(r.Context(), tenantID, ruleID, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list notification deliveries failed")
+			return
+		}
+		out := make([]notificationDeliveryResponse, 0, len(deliveries))
+		for _, d := range deliveries {
+			out = append(out, toNotificationDeliveryResponse(d))
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"deliveries": out})
+	}
+}
+
+func writeNotificationRuleError(w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "notification rule not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, msg)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/store"
+)
+
+type fakeNotificationStore struct {
+	rules      []store.NotificationRule
+	deliveries []store.NotificationDelivery
+}
+
+func (f *fakeNotificationStore) CreateRule(_ context.Context, rule store.NotificationRule) (store.NotificationRule, error) {
+	rule.ID = "new"
+	rule.CreatedAt = time.Now()
+	f.rules = append(f.rules, rule)
+	return rule, nil
+}
+
+func (f *fakeNotificationStore) ListRules(_ context.Context, tenantID string) ([]store.NotificationRule, error) {
+	var out []store.NotificationRule
+	for _, r := range f.rules {
+		if r.TenantID == tenantID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeNotificationStore) GetRule(_ context.Context, tenantID, ruleID string) (store.NotificationRule, error) {
+	for _, r := range f.rules {
+		if r.TenantID == tenantID && r.ID == ruleID {
+			return r, nil
+		}
+	}
+	return store.NotificationRule{}, store.ErrNotFound
+}
+
+func (f *fakeNotificationStore) DeleteRule(ctx context.Context, tenantID, ruleID string) error {
+	if _, err := f.GetRule(ctx, tenantID, ruleID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f *fakeNotificationStore) ListDeliveries(_ context.Context, tenantID, ruleID string, _ int) ([]store.NotificationDelivery, error) {
+	var out []store.NotificationDelivery
+	for _, d := range f.deliveries {
+		if d.TenantID == tenantID && d.RuleID == ruleID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func TestNotificationEndpoints(t *testing.T) {
+	t.Parallel()
+	code := 503
+	ns := &fakeNotificationStore{
+		rules: []store.NotificationRule{
+			{ID: "r1", TenantID: "t1", Target: "slack", URL: "https://hooks.example.com/a", Conditions: store.NotificationConditions{MinRisk: "critical"}, Enabled: true},
+			{ID: "r2", TenantID: "t2", Target: "http", URL: "https://example.com/b", Conditions: store.NotificationConditions{Signals: []string{"retry_loop"}}, Enabled: true},
+		},
+		deliveries: []store.NotificationDelivery{
+			{ID: "d1", TenantID: "t1", RuleID: "r1", EntryID: "e1", Status: store.DeliveryPending, Attempts: 1, LastStatusCode: &code, LastError: "503 Service Unavailable"},
+		},
+	}
+	mux := NewRouter(RouterConfig{APIKey: "k", DefaultTenant: "t1", Notifications: ns})
+
+	tests := []struct {
+		name, method, path, body string
+		wantStatus               int
+		check                    func(t *testing.T, body []byte)
+	}{
+		{
+			name: "create", method: "POST", path: "/v1/notifications", wantStatus: 201,
+			body: `{"name":"prod declines","target":"cloudevents","url":"https://events.example.com/in","conditions":{"verdicts":["declined"],"scopes":["production"]}}`,
+			check: func(t *testing.T, body []byte) {
+				var resp notificationRuleResponse
+				_ = json.Unmarshal(body, &resp)
+				if resp.ID != "new" || !resp.Enabled || resp.Target != "cloudevents" || resp.Conditions.Verdicts[0] != "declined" {
+					t.Fatalf("created = %+v", resp)
+				}
+			},
+		},
+		{
+			name: "create rejects invalid rule", method: "POST", path: "/v1/notifications", wantStatus: 400,
+			body: `{"target":"slack","url":"https://hooks.example.com/a","conditions":{"min_risk":"severe"}}`,
+			check: func(t *testing.T, body []byte) {
+				if !strings.Contains(string(body), "min_risk") {
+					t.Fatalf("body = %s", body)
+				}
+			},
+		},
+		{
+			name: "list", method: "GET", path: "/v1/notifications", wantStatus: 200,
+			check: func(t *testing.T, body []byte) {
+				var resp struct{ Rules []notificationRuleResponse }
+				_ = json.Unmarshal(body, &resp)
+				if len(resp.Rules) == 0 || resp.Rules[0].ID != "r1" {
+					t.Fatalf("rules = %+v", resp.Rules)
+				}
+				for _, r := range resp.Rules {
+					if r.ID == "r2" {
+						t.Fatal("listed another tenant's rule")
+					}
+				}
+			},
+		},
+		{name: "get", method: "GET", path: "/v1/notifications/r1", wantStatus: 200},
+		{name: "get other tenant", method: "GET", path: "/v1/notifications/r2", wantStatus: 404},
+		{
+			name: "deliveries", method: "GET", path: "/v1/notifications/r1/deliveries", wantStatus: 200,
+			check: func(t *testing.T, body []byte) {
+				var resp struct {
+					Deliveries []notificationDeliveryResponse
+				}
+				_ = json.Unmarshal(body, &resp)
+				if len(resp.Deliveries) != 1 || resp.Deliveries[0].Status != "pending" || *resp.Deliveries[0].LastStatusCode != 503 || resp.Deliveries[0].NextAttemptAt == nil {
+					t.Fatalf("deliveries = %+v", resp.Deliveries)
+				}
+			},
+		},
+		{name: "deliveries bad limit", method: "GET", path: "/v1/notifications/r1/deliveries?limit=0", wantStatus: 400},
+		{name: "deliveries unknown rule", method: "GET", path: "/v1/notifications/nope/deliveries", wantStatus: 404},
+		{name: "delete", method: "DELETE", path: "/v1/notifications/r1", wantStatus: 200},
+		{name: "delete unknown", method: "DELETE", path: "/v1/notifications/nope", wantStatus: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer k")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
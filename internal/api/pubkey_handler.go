@@ -5,21 +5,51 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"net/http"
+	"strings"
+
+	pkevidence "samebits.com/evidra/pkg/evidence"
 )
 
-func handlePubkey(pubkey ed25519.PublicKey) http.HandlerFunc {
-	return func(w http.ResponseWriter, _ *http.Request) {
-		if pubkey == nil {
+// handlePubkey serves the verification keys as a JSON Web Key Set. The
+// current signing key comes first, followed by retired keys that are still
+// needed to verify older entries. Clients that send
+// Accept: application/x-pem-file receive the current key as PEM.
+func handlePubkey(current ed25519.PublicKey, retired *pkevidence.Keyring) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if current == nil && (retired == nil || retired.Len() == 0) {
 			writeError(w, http.StatusNotImplemented, "signing not configured")
 			return
 		}
-		der, err := x509.MarshalPKIXPublicKey(pubkey)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "marshal public key")
+
+		if strings.Contains(r.Header.Get("Accept"), "application/x-pem-file") {
+			if current == nil {
+				writeError(w, http.StatusNotImplemented, "signing not configured")
+				return
+			}
+			der, err := x509.MarshalPKIXPublicKey(current)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "marshal public key")
+				return
+			}
+			block := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+			w.Header().Set("Content-Type", "application/x-pem-file")
+			_, _ = w.Write(block)
 			return
 		}
-		block := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
-		w.Header().Set("Content-Type", "application/x-pem-file")
-		_, _ = w.Write(block)
+
+		set := pkevidence.JWKS{Keys: []pkevidence.JWK{}}
+		seen := map[string]bool{}
+		if current != nil {
+			set.Keys = append(set.Keys, pkevidence.NewKeyring(current).JWKS().Keys...)
+			seen[pkevidence.KeyID(current)] = true
+		}
+		if retired != nil {
+			for _, jwk := range retired.JWKS().Keys {
+				if !seen[jwk.Kid] {
+					set.Keys = append(set.Keys, jwk)
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, set)
 	}
 }
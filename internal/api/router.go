@@ -17,12 +17,23 @@ type RouterConfig struct {
 	APIKey         string
 	DefaultTenant  string
 	PublicKey      ed25519.PublicKey
+	RetiredKeys    *pkevidence.Keyring // verification-only keys kept after rotation
 	EntryStore     *store.EntryStore
 	KeyStore       *store.KeyStore
 	BenchmarkStore *store.BenchmarkStore
 	RawStore       RawEntryStore
+	Ingester       EntryIngester // verifies forwarded entries; nil stores them unchecked
+	SigningKeys    SigningKeyStore
+	Quarantine     QuarantineLister
+	Chains         ChainStore
+	ChainValidator ChainValidator
+	Stream         EntryStreamer  // live entry feed; needs StreamHub
+	StreamHub      StreamNotifier // wakes streams when entries are stored
+	Notifications  NotificationRuleStore
 	Scorecard      ScorecardComputer
 	Explain        ExplainComputer
+	Approvals      ApprovalStore // pending approvals; submitting needs ApprovalKeys and Ingester
+	ApprovalKeys   TenantKeyring // registered keys approval decisions are verified against
 	InviteSecret   string
 	Pinger         Pinger
 	UIFS           fs.FS // Embedded landing page filesystem
@@ -30,6 +41,10 @@ type RouterConfig struct {
 	WebhookSigner  pkevidence.Signer
 	ArgoCDSecret   string
 	GenericSecret  string
+	GitHubSecret   string // HMAC secret of GitHub webhooks
+	GitLabSecret   string // secret token or signing token of GitLab webhooks
+	FluxSecret     string // HMAC key or bearer token of Flux notification-controller events
+	RolloutsSecret string // bearer token of Argo Rollouts notifications
 }
 
 // NewRouter creates the HTTP handler with all routes and middleware.
@@ -41,56 +56,131 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	if cfg.Pinger != nil {
 		mux.Handle("GET /readyz", handleReadyz(cfg.Pinger))
 	}
-	if cfg.PublicKey != nil {
-		mux.Handle("GET /v1/evidence/pubkey", handlePubkey(cfg.PublicKey))
+	if cfg.PublicKey != nil || (cfg.RetiredKeys != nil && cfg.RetiredKeys.Len() > 0) {
+		mux.Handle("GET /v1/evidence/pubkey", handlePubkey(cfg.PublicKey, cfg.RetiredKeys))
 	}
 
 	// Key issuance (gated, not behind standard auth).
 	mux.Handle("POST /v1/keys", handleKeys(cfg.KeyStore, cfg.InviteSecret))
-	if cfg.WebhookStore != nil && cfg.ArgoCDSecret != "" {
-		if cfg.KeyStore != nil {
-			mux.Handle("POST /v1/hooks/argocd", handleArgoCDWebhookWithTenantResolver(cfg.WebhookStore, cfg.WebhookSigner, cfg.ArgoCDSecret, tenantResolverFromKeyStore(cfg.KeyStore)))
-		}
-	}
-	if cfg.WebhookStore != nil && cfg.GenericSecret != "" {
-		if cfg.KeyStore != nil {
-			mux.Handle("POST /v1/hooks/generic", handleGenericWebhookWithTenantResolver(cfg.WebhookStore, cfg.WebhookSigner, cfg.GenericSecret, tenantResolverFromKeyStore(cfg.KeyStore)))
-		}
-	}
+	registerWebhookRoutes(mux, cfg)
 
 	// Authenticated routes.
-	authMw := iauth.StaticKeyMiddleware(cfg.APIKey, cfg.DefaultTenant)
-	if cfg.KeyStore != nil {
-		authMw = iauth.KeyStoreMiddleware(func(ctx context.Context, token string) (string, error) {
-			// Keep static key valid for Phase 0 compatibility.
-			if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.APIKey)) == 1 {
-				return cfg.DefaultTenant, nil
-			}
-
-			rec, err := cfg.KeyStore.LookupKey(ctx, token)
-			if err != nil {
-				return "", err
-			}
-			return rec.TenantID, nil
-		})
-	}
+	authMw := authMiddleware(cfg)
 
 	// Auth check (forwardAuth target).
 	mux.Handle("GET /auth/check", authMw(iauth.AuthCheckHandler()))
 	mux.Handle("HEAD /auth/check", authMw(iauth.AuthCheckHandler()))
 
+	registerEvidenceRoutes(mux, cfg, authMw)
+	registerWorkflowRoutes(mux, cfg, authMw)
+
+	// Embedded landing page.
+	if cfg.UIFS != nil {
+		mux.Handle("/", uiHandler(cfg.UIFS))
+	}
+
+	return wrapMiddleware(mux)
+}
+
+// registerWebhookRoutes mounts each webhook receiver that has a secret.
+// Receivers authenticate with their own secret and resolve the tenant from
+// the API key store.
+func registerWebhookRoutes(mux *http.ServeMux, cfg RouterConfig) {
+	if cfg.WebhookStore == nil || cfg.KeyStore == nil {
+		return
+	}
+	resolveTenant := tenantResolverFromKeyStore(cfg.KeyStore)
+	hooks := []struct {
+		path    string
+		secret  string
+		handler func(WebhookStore, pkevidence.Signer, string, WebhookTenantResolver) http.HandlerFunc
+	}{
+		{"POST /v1/hooks/argocd", cfg.ArgoCDSecret, handleArgoCDWebhookWithTenantResolver},
+		{"POST /v1/hooks/generic", cfg.GenericSecret, handleGenericWebhookWithTenantResolver},
+		{"POST /v1/hooks/github", cfg.GitHubSecret, handleGitHubWebhookWithTenantResolver},
+		{"POST /v1/hooks/gitlab", cfg.GitLabSecret, handleGitLabWebhookWithTenantResolver},
+		{"POST /v1/hooks/flux", cfg.FluxSecret, handleFluxWebhookWithTenantResolver},
+		{"POST /v1/hooks/argo-rollouts", cfg.RolloutsSecret, handleArgoRolloutsWebhookWithTenantResolver},
+	}
+	for _, h := range hooks {
+		if h.secret != "" {
+			mux.Handle(h.path, h.handler(cfg.WebhookStore, cfg.WebhookSigner, h.secret, resolveTenant))
+		}
+	}
+}
+
+// authMiddleware accepts the static API key, and keys from the key store
+// when one is configured.
+func authMiddleware(cfg RouterConfig) func(http.Handler) http.Handler {
+	if cfg.KeyStore == nil {
+		return iauth.StaticKeyMiddleware(cfg.APIKey, cfg.DefaultTenant)
+	}
+	return iauth.KeyStoreMiddleware(func(ctx context.Context, token string) (string, error) {
+		// Keep static key valid for Phase 0 compatibility.
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.APIKey)) == 1 {
+			return cfg.DefaultTenant, nil
+		}
+
+		rec, err := cfg.KeyStore.LookupKey(ctx, token)
+		if err != nil {
+			return "", err
+		}
+		return rec.TenantID, nil
+	})
+}
+
+// registerEvidenceRoutes mounts ingestion, signing keys, quarantine,
+// chains, the live stream, and entry queries.
+func registerEvidenceRoutes(mux *http.ServeMux, cfg RouterConfig, authMw func(http.Handler) http.Handler) {
 	// Evidence ingestion.
 	if cfg.RawStore != nil {
-		mux.Handle("POST /v1/evidence/forward", authMw(handleForward(cfg.RawStore)))
-		mux.Handle("POST /v1/evidence/batch", authMw(handleBatch(cfg.RawStore)))
+		mux.Handle("POST /v1/evidence/forward", authMw(handleForward(cfg.RawStore, cfg.Ingester)))
+		mux.Handle("POST /v1/evidence/batch", authMw(handleBatch(cfg.RawStore, cfg.Ingester)))
 		mux.Handle("POST /v1/evidence/findings", authMw(handleFindings(cfg.RawStore)))
 	}
 
+	// Ingestion signing keys and quarantine.
+	if cfg.SigningKeys != nil {
+		mux.Handle("POST /v1/signing-keys", authMw(handleRegisterSigningKey(cfg.SigningKeys)))
+		mux.Handle("GET /v1/signing-keys", authMw(handleListSigningKeys(cfg.SigningKeys)))
+		mux.Handle("DELETE /v1/signing-keys/{key_id}", authMw(handleRevokeSigningKey(cfg.SigningKeys)))
+	}
+	if cfg.Quarantine != nil {
+		mux.Handle("GET /v1/evidence/quarantine", authMw(handleListQuarantine(cfg.Quarantine)))
+	}
+
+	// Per-writer evidence chains.
+	if cfg.Chains != nil {
+		mux.Handle("GET /v1/evidence/chains", authMw(handleListChains(cfg.Chains)))
+		mux.Handle("GET /v1/evidence/chains/{chain_id}", authMw(handleGetChain(cfg.Chains)))
+	}
+	if cfg.ChainValidator != nil {
+		mux.Handle("POST /v1/evidence/chains/{chain_id}/validate", authMw(handleValidateChain(cfg.ChainValidator)))
+	}
+
+	// Live evidence stream (SSE).
+	if cfg.Stream != nil && cfg.StreamHub != nil {
+		mux.Handle("GET /v1/evidence/stream", authMw(handleStream(cfg.Stream, cfg.StreamHub)))
+	}
+
 	// Evidence queries.
 	if cfg.EntryStore != nil {
 		mux.Handle("GET /v1/evidence/entries", authMw(handleListEntries(cfg.EntryStore)))
 		mux.Handle("GET /v1/evidence/entries/{id}", authMw(handleGetEntry(cfg.EntryStore)))
 	}
+}
+
+// registerWorkflowRoutes mounts notifications, analytics, approvals, and
+// benchmarks.
+func registerWorkflowRoutes(mux *http.ServeMux, cfg RouterConfig, authMw func(http.Handler) http.Handler) {
+	// Outbound notification rules.
+	if cfg.Notifications != nil {
+		mux.Handle("POST /v1/notifications", authMw(handleCreateNotificationRule(cfg.Notifications)))
+		mux.Handle("GET /v1/notifications", authMw(handleListNotificationRules(cfg.Notifications)))
+		mux.Handle("GET /v1/notifications/{rule_id}", authMw(handleGetNotificationRule(cfg.Notifications)))
+		mux.Handle("DELETE /v1/notifications/{rule_id}", authMw(handleDeleteNotificationRule(cfg.Notifications)))
+		mux.Handle("GET /v1/notifications/{rule_id}/deliveries", authMw(handleListNotificationDeliveries(cfg.Notifications)))
+	}
 
 	// Analytics.
 	if cfg.Scorecard != nil {
@@ -100,17 +190,17 @@ func NewRouter(cfg RouterConfig) http.Handler {
 		mux.Handle("GET /v1/evidence/explain", authMw(handleExplain(cfg.Explain)))
 	}
 
+	// Approval workflow.
+	if cfg.Approvals != nil {
+		mux.Handle("GET /v1/approvals", authMw(handleListApprovals(cfg.Approvals, cfg.ApprovalKeys)))
+		mux.Handle("GET /v1/approvals/{prescription_id}", authMw(handleGetApproval(cfg.Approvals, cfg.ApprovalKeys)))
+		mux.Handle("POST /v1/approvals/{prescription_id}", authMw(handleSubmitApproval(cfg.Approvals, cfg.ApprovalKeys, cfg.Ingester)))
+	}
+
 	// Benchmark.
 	if cfg.BenchmarkStore != nil {
 		mux.Handle("POST /v1/benchmark/run", authMw(handleBenchmarkRun(cfg.BenchmarkStore)))
 		mux.Handle("GET /v1/benchmark/runs", authMw(handleBenchmarkRuns(cfg.BenchmarkStore)))
 		mux.Handle("GET /v1/benchmark/compare", authMw(handleBenchmarkCompare(cfg.BenchmarkStore)))
 	}
-
-	// Embedded landing page.
-	if cfg.UIFS != nil {
-		mux.Handle("/", uiHandler(cfg.UIFS))
-	}
-
-	return wrapMiddleware(mux)
 }
@@ -2,9 +2,13 @@ package api
 
 import (
 	"crypto/ed25519"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	pkevidence "samebits.com/evidra/pkg/evidence"
 )
 
 func TestRouter_HealthzNoAuth(t *testing.T) {
@@ -43,6 +47,61 @@ func TestRouter_PubkeyNoAuth(t *testing.T) {
 	}
 }
 
+func TestRouter_PubkeyServesJWKSWithRetiredKeys(t *testing.T) {
+	t.Parallel()
+	current, _, _ := ed25519.GenerateKey(nil)
+	retired, _, _ := ed25519.GenerateKey(nil)
+	router := NewRouter(RouterConfig{
+		APIKey:        "test-key",
+		DefaultTenant: "t1",
+		PublicKey:     current,
+		RetiredKeys:   pkevidence.NewKeyring(retired, current),
+	})
+
+	req := httptest.NewRequest("GET", "/v1/evidence/pubkey", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var set pkevidence.JWKS
+	if err := json.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode JWKS: %v", err)
+	}
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(set.Keys))
+	}
+	if set.Keys[0].Kid != pkevidence.KeyID(current) {
+		t.Fatalf("first key = %s, want current key %s", set.Keys[0].Kid, pkevidence.KeyID(current))
+	}
+	keyring, err := pkevidence.ParseJWKS(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("ParseJWKS: %v", err)
+	}
+	if _, ok := keyring.Lookup(pkevidence.KeyID(retired)); !ok {
+		t.Fatal("expected retired key in JWKS")
+	}
+}
+
+func TestRouter_PubkeyPEMOnRequest(t *testing.T) {
+	t.Parallel()
+	pub, _, _ := ed25519.GenerateKey(nil)
+	router := NewRouter(RouterConfig{APIKey: "test-key", DefaultTenant: "t1", PublicKey: pub})
+
+	req := httptest.NewRequest("GET", "/v1/evidence/pubkey", nil)
+	req.Header.Set("Accept", "application/x-pem-file")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "-----BEGIN PUBLIC KEY-----") {
+		t.Fatalf("expected PEM body, got %q", rec.Body.String())
+	}
+}
+
 func TestRouter_ForwardRequiresAuth(t *testing.T) {
 	t.Parallel()
 	cfg := RouterConfig{
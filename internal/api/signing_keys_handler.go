@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/store"
+)
+
+// SigningKeyStore manages the public keys a tenant registers to sign the
+// entries it forwards.
+type SigningKeyStore interface {
+	AddKey(ctx context.Context, tenantID string, pub ed25519.PublicKey, label, source string) (store.SigningKeyRecord, error)
+	ListKeys(ctx context.Context, tenantID string) ([]store.SigningKeyRecord, error)
+	RevokeKey(ctx context.Context, tenantID, keyID string) error
+}
+
+// QuarantineLister lists entries that failed ingestion verification.
+type QuarantineLister interface {
+	ListQuarantined(ctx context.Context, tenantID string, limit int) ([]store.QuarantinedEntry, error)
+}
+
+type signingKeyResponse struct {
+	KeyID     string     `json:"key_id"`
+	PublicKey string     `json:"public_key"`
+	Label     string     `json:"label,omitempty"`
+	Source    string     `json:"source"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func toSigningKeyResponse(rec store.SigningKeyRecord) signingKeyResponse {
+	return signingKeyResponse{
+		KeyID:     rec.KeyID,
+		PublicKey: base64.RawURLEncoding.EncodeToString(rec.PublicKey),
+		Label:     rec.Label,
+		Source:    rec.Source,
+		CreatedAt: rec.CreatedAt,
+		RevokedAt: rec.RevokedAt,
+	}
+}
+
+func handleRegisterSigningKey(ks SigningKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenantID := auth.TenantID(r.Context())
+
+		var req struct {
+			PublicKey string `json:"public_key"`
+			Label     string `json:"label"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if len(req.Label) > 128 {
+			writeError(w, http.StatusBadRequest, "label too long (max 128)")
+			return
+		}
+		pub, err := parseSigningPublicKey(req.PublicKey)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		rec, err := ks.AddKey(r.Context(), tenantID, pub, req.Label, store.SigningKeySourceAPI)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "register signing key failed")
+			return
+		}
+		writeJSON(w, http.StatusCreated, toSigningKeyResponse(rec))
+	}
+}
+
+func handleListSigningKeys(ks SigningKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recs, err := ks.ListKeys(r.Context(), auth.TenantID(r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list signing keys failed")
+			return
+		}
+		keys := make([]signingKeyResponse, 0, len(recs))
+		for _, rec := range recs {
+			keys = append(keys, toSigningKeyResponse(rec))
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+	}
+}
+
+func handleRevokeSigningKey(ks SigningKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keyID := r.PathValue("key_id")
+		if keyID == "" {
+			writeError(w, http.StatusBadRequest, "missing key id")
+			return
+		}
+		if err := ks.RevokeKey(r.Context(), auth.TenantID(r.Context()), keyID); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "signing key not found")
+			} else {
+				writeError(w, http.StatusInternalServerError, "revoke signing key failed")
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"key_id": keyID, "status": "revoked"})
+	}
+}
+
+func handleListQuarantine(q QuarantineLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		items, err := q.ListQuarantined(r.Context(), auth.TenantID(r.Context()), limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list quarantine failed")
+			return
+		}
+		out := make([]map[string]interface{}, 0, len(items))
+		for _, item := range items {
+			out = append(out, map[string]interface{}{
+				"digest":      item.Digest,
+				"entry_id":    item.EntryID,
+				"hash":        item.Hash,
+				"reason_code": item.ReasonCode,
+				"reason":      item.Reason,
+				"received_at": item.ReceivedAt,
+				"entry":       item.Raw,
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"items": out})
+	}
+}
+
+// parseSigningPublicKey accepts a PEM "PUBLIC KEY" block (as written by
+// `evidra keygen`) or the raw 32-byte key in base64 or base64url.
+func parseSigningPublicKey(s string) (ed25519.PublicKey, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("public_key is required")
+	}
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("public_key: unexpected PEM block %q", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("public_key: parse PKIX: %v", err)
+		}
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("public_key is not Ed25519")
+		}
+		return pub, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.RawURLEncoding, base64.URLEncoding} {
+		if raw, err := enc.DecodeString(s); err == nil && len(raw) == ed25519.PublicKeySize {
+			return ed25519.PublicKey(raw), nil
+		}
+	}
+	return nil, errors.New("public_key must be a PEM PUBLIC KEY or a base64 Ed25519 key")
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/store"
+	testutil "samebits.com/evidra/internal/testutil"
+	pkevidence "samebits.com/evidra/pkg/evidence"
+)
+
+type fakeSigningKeyStore struct {
+	keys map[string]store.SigningKeyRecord
+}
+
+func (f *fakeSigningKeyStore) AddKey(_ context.Context, tenantID string, pub ed25519.PublicKey, label, source string) (store.SigningKeyRecord, error) {
+	rec := store.SigningKeyRecord{TenantID: tenantID, KeyID: pkevidence.KeyID(pub), PublicKey: pub, Label: label, Source: source}
+	f.keys[rec.KeyID] = rec
+	return rec, nil
+}
+
+func (f *fakeSigningKeyStore) ListKeys(_ context.Context, tenantID string) ([]store.SigningKeyRecord, error) {
+	var out []store.SigningKeyRecord
+	for _, rec := range f.keys {
+		if rec.TenantID == tenantID {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSigningKeyStore) RevokeKey(_ context.Context, tenantID, keyID string) error {
+	if rec, ok := f.keys[keyID]; !ok || rec.TenantID != tenantID {
+		return store.ErrNotFound
+	}
+	delete(f.keys, keyID)
+	return nil
+}
+
+func TestRegisterSigningKey_Formats(t *testing.T) {
+	t.Parallel()
+	pub := testutil.TestSigner(t).PublicKey()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	tests := []struct {
+		name      string
+		publicKey string
+		want      int
+	}{
+		{name: "pem", publicKey: pemKey, want: 201},
+		{name: "base64", publicKey: base64.StdEncoding.EncodeToString(pub), want: 201},
+		{name: "base64url", publicKey: base64.RawURLEncoding.EncodeToString(pub), want: 201},
+		{name: "missing", publicKey: "", want: 400},
+		{name: "wrong length", publicKey: base64.StdEncoding.EncodeToString([]byte("short")), want: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ks := &fakeSigningKeyStore{keys: map[string]store.SigningKeyRecord{}}
+			body, _ := json.Marshal(map[string]string{"public_key": tt.publicKey, "label": "ci"})
+			req := httptest.NewRequest("POST", "/v1/signing-keys", bytes.NewReader(body))
+			req = req.WithContext(auth.WithTenantID(req.Context(), "t1"))
+			rec := httptest.NewRecorder()
+			handleRegisterSigningKey(ks).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d body=%s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want != 201 {
+				return
+			}
+			var resp signingKeyResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.KeyID != pkevidence.KeyID(pub) || resp.Source != store.SigningKeySourceAPI {
+				t.Fatalf("response = %+v", resp)
+			}
+		})
+	}
+}
+
+func TestRevokeSigningKey_ScopedToTenant(t *testing.T) {
+	t.Parallel()
+	pub := testutil.TestSigner(t).PublicKey()
+	ks := &fakeSigningKeyStore{keys: map[string]store.SigningKeyRecord{}}
+	if _, err := ks.AddKey(context.Background(), "t1", pub, "", store.SigningKeySourceAPI); err != nil {
+		t.Fatalf("AddKey: %v", err)
+	}
+
+	mux := NewRouter(RouterConfig{APIKey: "k", DefaultTenant: "t2", SigningKeys: ks})
+	req := httptest.NewRequest("DELETE", "/v1/signing-keys/"+pkevidence.KeyID(pub), nil)
+	req.Header.Set("Authorization", "Bearer k")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != 404 {
+		t.Fatalf("status = %d, want 404 for another tenant's key", rec.Code)
+	}
+	if len(ks.keys) != 1 {
+		t.Fatal("key of another tenant was revoked")
+	}
+}
@@ -0,0 +1,392 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/risk"
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/stream"
+	pkevidence "samebits.com/evidra/pkg/evidence"
+)
+
+// EntryStreamer reads a tenant's entries in stream order.
+type EntryStreamer interface {
+	StreamHead(ctx context.Context, tenantID string) (int64, error)
+	EntriesSince(ctx context.Context, tenantID string, after int64, f store.StreamFilter, limit int) ([]store.StreamEntry, error)
+	SessionHistory(ctx context.Context, tenantID, sessionID string, before int64, limit int) ([]pkevidence.EvidenceEntry, error)
+}
+
+// StreamNotifier wakes stream subscribers when a tenant stores entries.
+type StreamNotifier interface {
+	Subscribe(tenantID string) (wake <-chan struct{}, cancel func())
+	Done() <-chan struct{}
+}
+
+const (
+	streamBatchSize    = 100
+	streamKeepalive    = 15 * time.Second
+	streamRetryMillis  = 3000
+	signalHistoryLimit = 500
+	// streamFactsCacheSize bounds how many entries' facts are kept for
+	// subscribers that have not reached them yet.
+	streamFactsCacheSize = 4096
+)
+
+type streamOptions struct {
+	filter   store.StreamFilter
+	minRisk  string
+	signals  bool
+	cursor   int64
+	fromHead bool
+}
+
+// parseStreamOptions reads the stream filters. The cursor comes from the
+// Last-Event-ID header when a client reconnects, else from ?cursor=; without
+// either the stream starts at the current head.
+func parseStreamOptions(r *http.Request) (streamOptions, error) {
+	q := r.URL.Query()
+	opts := streamOptions{
+		filter: store.StreamFilter{
+			SessionID: q.Get("session_id"),
+			ActorID:   q.Get("actor"),
+		},
+		signals: true,
+	}
+	for _, t := range strings.Split(q.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opts.filter.Types = append(opts.filter.Types, t)
+		}
+	}
+	if minRisk := strings.ToLower(strings.TrimSpace(q.Get("min_risk"))); minRisk != "" {
+		// Every recognized level outranks the empty one.
+		if !risk.SeverityHigherThan(minRisk, "") {
+			return streamOptions{}, fmt.Errorf("invalid min_risk %q (expected low|medium|high|critical)", minRisk)
+		}
+		opts.minRisk = minRisk
+	}
+	if raw := q.Get("signals"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return streamOptions{}, fmt.Errorf("invalid signals %q", raw)
+		}
+		opts.signals = v
+	}
+
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = q.Get("cursor")
+	}
+	if cursor == "" {
+		opts.fromHead = true
+		return opts, nil
+	}
+	seq, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || seq < 0 {
+		return streamOptions{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	opts.cursor = seq
+	return opts, nil
+}
+
+type streamEntryEvent struct {
+	Cursor        string          `json:"cursor"`
+	EntryID       string          `json:"entry_id"`
+	Type          string          `json:"type"`
+	SessionID     string          `json:"session_id,omitempty"`
+	Actor         string          `json:"actor,omitempty"`
+	EffectiveRisk string          `json:"effective_risk,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Entry         json.RawMessage `json:"entry"`
+}
+
+func handleStream(entries EntryStreamer, notifier StreamNotifier) http.HandlerFunc {
+	facts := newFactsCache(streamFactsCacheSize)
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts, err := parseStreamOptions(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ctx := r.Context()
+		tenantID := auth.TenantID(ctx)
+
+		// Subscribe before reading the head so no commit falls in between.
+		wake, cancel := notifier.Subscribe(tenantID)
+		defer cancel()
+		if opts.fromHead {
+			if opts.cursor, err = entries.StreamHead(ctx, tenantID); err != nil {
+				writeError(w, http.StatusInternalServerError, "read stream head failed")
+				return
+			}
+		}
+
+		rc := http.NewResponseController(w)
+		// The stream outlives the server's write timeout.
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+		writeSSE(w, "ready", strconv.FormatInt(opts.cursor, 10), map[string]string{"cursor": strconv.FormatInt(opts.cursor, 10)})
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		s := &entryStream{entries: entries, facts: facts, tenantID: tenantID, opts: opts, w: w}
+		keepalive := time.NewTicker(streamKeepalive)
+		defer keepalive.Stop()
+		for {
+			if err := s.pump(ctx); err != nil {
+				if ctx.Err() == nil {
+					log.Printf("evidence stream: tenant %s: %v", tenantID, err)
+					writeSSE(w, "error", "", map[string]string{"error": "stream read failed; reconnect to resume"})
+					_ = rc.Flush()
+				}
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-notifier.Done():
+				return
+			case <-wake:
+			case <-keepalive.C:
+				if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// entryStream writes one subscriber's events from its cursor onward.
+type entryStream struct {
+	entries  EntryStreamer
+	facts    *factsCache
+	tenantID string
+	opts     streamOptions
+	w        io.Writer
+}
+
+// pump writes every entry stored after the cursor, each followed by the
+// signal events it fired. The SSE id goes on the last event of an entry's
+// group, so a client that resumes mid-group receives the group again.
+// Entries below min_risk are skipped, and when a batch ends on skipped
+// entries an id-only event moves the client's cursor past them.
+func (s *entryStream) pump(ctx context.Context) error {
+	for {
+		batch, err := s.entries.EntriesSince(ctx, s.tenantID, s.opts.cursor, s.opts.filter, streamBatchSize)
+		if err != nil {
+			return err
+		}
+		skipped := false
+		for _, se := range batch {
+			s.opts.cursor = se.Seq
+			sent, err := s.writeEntry(ctx, se)
+			if err != nil {
+				return err
+			}
+			skipped = !sent
+		}
+		if skipped {
+			writeSSEID(s.w, strconv.FormatInt(s.opts.cursor, 10))
+		}
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+	}
+}
+
+// writeEntry writes se and its signals, and reports false when min_risk
+// filtered it out.
+func (s *entryStream) writeEntry(ctx context.Context, se store.StreamEntry) (bool, error) {
+	var entry pkevidence.EvidenceEntry
+	_ = json.Unmarshal(se.Entry.Payload, &entry)
+	facts, err := s.facts.get(factsKey{tenantID: s.tenantID, seq: se.Seq}, func() (entryFacts, error) {
+		return s.entryFacts(ctx, se, entry)
+	})
+	if err != nil {
+		return false, err
+	}
+	effectiveRisk := facts.effectiveRisk
+	if s.opts.minRisk != "" && (effectiveRisk == "" || risk.SeverityHigherThan(s.opts.minRisk, effectiveRisk)) {
+		return false, nil
+	}
+	var signals []stream.SignalEvent
+	if s.opts.signals {
+		signals = facts.signals
+	}
+
+	id := strconv.FormatInt(se.Seq, 10)
+	entryID := id
+	if len(signals) > 0 {
+		entryID = ""
+	}
+	writeSSE(s.w, "entry", entryID, streamEntryEvent{
+		Cursor:        id,
+		EntryID:       se.Entry.ID,
+		Type:          se.Entry.EntryType,
+		SessionID:     se.Entry.SessionID,
+		Actor:         entry.Actor.ID,
+		EffectiveRisk: effectiveRisk,
+		CreatedAt:     se.Entry.CreatedAt,
+		Entry:         se.Entry.Payload,
+	})
+	for i, sig := range signals {
+		sigID := ""
+		if i == len(signals)-1 {
+			sigID = id
+		}
+		writeSSE(s.w, "signal", sigID, sig)
+	}
+	return true, nil
+}
+
+// entryFacts reads the session history before se once and derives the
+// entry's effective risk and the signals it fires.
+func (s *entryStream) entryFacts(ctx context.Context, se store.StreamEntry, entry pkevidence.EvidenceEntry) (entryFacts, error) {
+	if se.Entry.SessionID == "" {
+		return entryFacts{effectiveRisk: streamEntryRisk(entry, nil)}, nil
+	}
+	history, err := s.entries.SessionHistory(ctx, s.tenantID, se.Entry.SessionID, se.Seq, signalHistoryLimit)
+	if err != nil {
+		return entryFacts{}, err
+	}
+	// Entries the detectors cannot decode still stream; they just fire no
+	// signals.
+	signals, _ := stream.NewSignals(history, entry)
+	return entryFacts{effectiveRisk: streamEntryRisk(entry, history), signals: signals}, nil
+}
+
+// streamEntryRisk returns the effective risk a prescription recorded. A
+// report, cancellation, approval, or verification takes the risk of the
+// prescription it links to, when that prescription is in history.
+func streamEntryRisk(entry pkevidence.EvidenceEntry, history []pkevidence.EvidenceEntry) string {
+	if entry.Type == pkevidence.EntryTypePrescribe {
+		return prescriptionRisk(entry)
+	}
+	var link struct {
+		PrescriptionID string `json:"prescription_id"`
+	}
+	if err := json.Unmarshal(entry.Payload, &link); err != nil || link.PrescriptionID == "" {
+		return ""
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if e := history[i]; e.Type == pkevidence.EntryTypePrescribe && e.EntryID == link.PrescriptionID {
+			return prescriptionRisk(e)
+		}
+	}
+	return ""
+}
+
+func prescriptionRisk(entry pkevidence.EvidenceEntry) string {
+	var p pkevidence.PrescriptionPayload
+	if err := json.Unmarshal(entry.Payload, &p); err != nil {
+		return ""
+	}
+	return p.EffectiveRisk
+}
+
+// entryFacts are what a streamed entry's events carry beyond the entry
+// itself. They depend only on the stored stream, so subscribers share them.
+type entryFacts struct {
+	effectiveRisk string
+	signals       []stream.SignalEvent
+}
+
+type factsKey struct {
+	tenantID string
+	seq      int64
+}
+
+type factsItem struct {
+	once  sync.Once
+	facts entryFacts
+	err   error
+}
+
+// factsCache computes each entry's facts once for every subscriber that
+// streams it, keeping the most recent size entries.
+type factsCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[factsKey]*factsItem
+	order []factsKey
+}
+
+func newFactsCache(size int) *factsCache {
+	return &factsCache{size: size, items: make(map[factsKey]*factsItem)}
+}
+
+// get returns the facts for key, calling compute for the first caller
+// only; concurrent callers wait for its result. A failed computation is
+// dropped, and a caller that waited on it retries with its own compute, so
+// one subscriber disconnecting mid-read does not fail the others.
+func (c *factsCache) get(key factsKey, compute func() (entryFacts, error)) (entryFacts, error) {
+	c.mu.Lock()
+	item, ok := c.items[key]
+	if !ok {
+		item = &factsItem{}
+		c.items[key] = item
+		c.order = append(c.order, key)
+		if len(c.order) > c.size {
+			delete(c.items, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.mu.Unlock()
+
+	ran := false
+	item.once.Do(func() {
+		ran = true
+		item.facts, item.err = compute()
+	})
+	if item.err != nil {
+		c.mu.Lock()
+		if c.items[key] == item {
+			delete(c.items, key)
+			c.order = slices.DeleteFunc(c.order, func(k factsKey) bool { return k == key })
+		}
+		c.mu.Unlock()
+		if !ran {
+			return c.get(key, compute)
+		}
+	}
+	return item.facts, item.err
+}
+
+// writeSSE writes one Server-Sent Event. Write errors surface on the next
+// flush.
+func writeSSE(w io.Writer, event, id string, data interface{}) {
+	raw, _ := json.Marshal(data)
+	var b strings.Builder
+	b.WriteString("event: " + event + "\n")
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	b.WriteString("data: ")
+	b.Write(raw)
+	b.WriteString("\n\n")
+	_, _ = io.WriteString(w, b.String())
+}
+
+// writeSSEID writes an event with only an id. Clients record it as the
+// Last-Event-ID without dispatching anything.
+func writeSSEID(w io.Writer, id string) {
+	_, _ = io.WriteString(w, "id: "+id+"\n\n")
+}
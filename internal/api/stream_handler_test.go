@@ -0,0 +1,349 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/stream"
+	pkevidence "samebits.com/evidra/pkg/evidence"
+)
+
+type fakeEntryStreamer struct {
+	mu      sync.Mutex
+	entries []store.StreamEntry
+}
+
+func (f *fakeEntryStreamer) add(t *testing.T, tenantID string, e pkevidence.EvidenceEntry) {
+	t.Helper()
+	raw, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, store.StreamEntry{
+		Seq: int64(len(f.entries) + 1),
+		Entry: store.StoredEntry{
+			ID: e.EntryID, TenantID: tenantID, EntryType: string(e.Type), SessionID: e.SessionID,
+			Payload: raw, CreatedAt: e.Timestamp,
+		},
+	})
+}
+
+func (f *fakeEntryStreamer) StreamHead(_ context.Context, tenantID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var head int64
+	for _, se := range f.entries {
+		if se.Entry.TenantID == tenantID {
+			head = se.Seq
+		}
+	}
+	return head, nil
+}
+
+func (f *fakeEntryStreamer) EntriesSince(_ context.Context, tenantID string, after int64, sf store.StreamFilter, limit int) ([]store.StreamEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []store.StreamEntry
+	for _, se := range f.entries {
+		e := se.Entry
+		if e.TenantID != tenantID || se.Seq <= after ||
+			(sf.SessionID != "" && e.SessionID != sf.SessionID) ||
+			(len(sf.Types) > 0 && !slices.Contains(sf.Types, e.EntryType)) {
+			continue
+		}
+		if len(out) == limit {
+			break
+		}
+		out = append(out, se)
+	}
+	return out, nil
+}
+
+func (f *fakeEntryStreamer) SessionHistory(_ context.Context, tenantID, sessionID string, before int64, _ int) ([]pkevidence.EvidenceEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []pkevidence.EvidenceEntry
+	for _, se := range f.entries {
+		if se.Entry.TenantID == tenantID && se.Entry.SessionID == sessionID && se.Seq < before {
+			var e pkevidence.EvidenceEntry
+			_ = json.Unmarshal(se.Entry.Payload, &e)
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+type sseEvent struct {
+	event, id, data string
+}
+
+// readSSE returns the next n non-ready events of the stream, including
+// id-only ones.
+func readSSE(t *testing.T, sc *bufio.Scanner, n int) []sseEvent {
+	t.Helper()
+	var out []sseEvent
+	var cur sseEvent
+	for len(out) < n && sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			idOnly := cur.event == "" && cur.data == "" && cur.id != ""
+			if (cur.event != "" && cur.event != "ready") || idOnly {
+				out = append(out, cur)
+			}
+			cur = sseEvent{}
+		case strings.HasPrefix(line, "event: "):
+			cur.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "id: "):
+			cur.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			cur.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	if len(out) < n {
+		t.Fatalf("stream ended after %d events, want %d: %v", len(out), n, sc.Err())
+	}
+	return out
+}
+
+func streamPrescribe(t *testing.T, id, session, effectiveRisk string) pkevidence.EvidenceEntry {
+	t.Helper()
+	payload, _ := json.Marshal(pkevidence.PrescriptionPayload{
+		CanonicalAction: json.RawMessage(`{"tool":"kubectl","operation":"apply","operation_class":"mutate","scope_class":"staging"}`),
+		EffectiveRisk:   effectiveRisk,
+	})
+	return pkevidence.EvidenceEntry{
+		EntryID: id, Type: pkevidence.EntryTypePrescribe, SessionID: session,
+		Actor: pkevidence.Actor{Type: "agent", ID: "agent-1"}, Timestamp: time.Now().UTC(), Payload: payload,
+	}
+}
+
+func streamReport(t *testing.T, id, session, prescriptionID string) pkevidence.EvidenceEntry {
+	t.Helper()
+	exitCode := 0
+	payload, _ := json.Marshal(pkevidence.ReportPayload{PrescriptionID: prescriptionID, ExitCode: &exitCode, Verdict: pkevidence.VerdictSuccess})
+	return pkevidence.EvidenceEntry{
+		EntryID: id, Type: pkevidence.EntryTypeReport, SessionID: session,
+		Actor: pkevidence.Actor{Type: "agent", ID: "agent-1"}, Timestamp: time.Now().UTC(), Payload: payload,
+	}
+}
+
+func TestStreamEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		query   string
+		lastID  string
+		seed    func(t *testing.T, f *fakeEntryStreamer)
+		live    func(t *testing.T, f *fakeEntryStreamer, hub *stream.Hub)
+		want    []sseEvent // data is matched as a substring
+		wantErr int
+	}{
+		{
+			name:   "resume from Last-Event-ID with min_risk",
+			query:  "?min_risk=high&cursor=0",
+			lastID: "1",
+			seed: func(t *testing.T, f *fakeEntryStreamer) {
+				f.add(t, "t1", streamPrescribe(t, "p1", "s1", "critical"))
+				f.add(t, "t1", streamPrescribe(t, "p2", "s1", "low"))
+				f.add(t, "t1", streamPrescribe(t, "p3", "s1", "high"))
+				f.add(t, "t1", streamPrescribe(t, "p4", "s1", "critical"))
+			},
+			want: []sseEvent{
+				{event: "entry", id: "3", data: `"entry_id":"p3"`},
+				{event: "entry", id: "4", data: `"effective_risk":"critical"`},
+			},
+		},
+		{
+			name:  "min_risk sends the id of trailing skipped entries",
+			query: "?min_risk=high&cursor=0&signals=false",
+			seed: func(t *testing.T, f *fakeEntryStreamer) {
+				f.add(t, "t1", streamPrescribe(t, "p1", "s1", "critical"))
+				f.add(t, "t1", streamPrescribe(t, "p2", "s1", "low"))
+				f.add(t, "t1", streamReport(t, "r1", "s1", "p2"))
+			},
+			want: []sseEvent{
+				{event: "entry", id: "1", data: `"entry_id":"p1"`},
+				{event: "", id: "3"},
+			},
+		},
+		{
+			name:  "min_risk keeps reports of risky prescriptions and their signals",
+			query: "?min_risk=high&cursor=0",
+			seed: func(t *testing.T, f *fakeEntryStreamer) {
+				f.add(t, "t1", streamPrescribe(t, "p1", "s1", "critical"))
+				f.add(t, "t1", streamReport(t, "r1", "s1", "p1"))
+				f.add(t, "t1", streamReport(t, "r2", "s1", "p1"))
+			},
+			want: []sseEvent{
+				{event: "entry", id: "1", data: `"entry_id":"p1"`},
+				{event: "entry", id: "2", data: `"effective_risk":"critical"`},
+				{event: "entry", id: "", data: `"entry_id":"r2"`},
+				{event: "signal", id: "3", data: `"signal":"protocol_violation"`},
+			},
+		},
+		{
+			name:  "signal follows the entry that fired it",
+			query: "?cursor=0&session_id=s1",
+			seed: func(t *testing.T, f *fakeEntryStreamer) {
+				f.add(t, "t1", streamPrescribe(t, "p1", "s1", "low"))
+				f.add(t, "t1", streamPrescribe(t, "other", "s2", "low"))
+				f.add(t, "t1", streamReport(t, "r1", "s1", "p1"))
+				f.add(t, "t1", streamReport(t, "r2", "s1", "p1"))
+			},
+			want: []sseEvent{
+				{event: "entry", id: "1", data: `"entry_id":"p1"`},
+				{event: "entry", id: "3", data: `"entry_id":"r1"`},
+				{event: "entry", id: "", data: `"entry_id":"r2"`},
+				{event: "signal", id: "4", data: `"signal":"protocol_violation"`},
+			},
+		},
+		{
+			name:  "live entries after the head",
+			query: "?type=prescribe&signals=false",
+			seed: func(t *testing.T, f *fakeEntryStreamer) {
+				f.add(t, "t1", streamPrescribe(t, "old", "s1", "low"))
+			},
+			live: func(t *testing.T, f *fakeEntryStreamer, hub *stream.Hub) {
+				f.add(t, "t2", streamPrescribe(t, "other-tenant", "s1", "low"))
+				f.add(t, "t1", streamReport(t, "r1", "s1", "old"))
+				f.add(t, "t1", streamPrescribe(t, "new", "s1", "low"))
+				hub.Publish("t1")
+			},
+			want: []sseEvent{
+				{event: "entry", id: "4", data: `"entry_id":"new"`},
+			},
+		},
+		{name: "bad min_risk", query: "?min_risk=severe", wantErr: http.StatusBadRequest},
+		{name: "bad cursor", query: "?cursor=abc", wantErr: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := &fakeEntryStreamer{}
+			if tt.seed != nil {
+				tt.seed(t, f)
+			}
+			hub := stream.NewHub()
+			t.Cleanup(hub.Close)
+			server := httptest.NewServer(NewRouter(RouterConfig{APIKey: "k", DefaultTenant: "t1", Stream: f, StreamHub: hub}))
+			t.Cleanup(server.Close)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v1/evidence/stream"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer k")
+			if tt.lastID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer resp.Body.Close()
+			if tt.wantErr != 0 {
+				if resp.StatusCode != tt.wantErr {
+					t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantErr)
+				}
+				return
+			}
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+				t.Fatalf("status = %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+
+			sc := bufio.NewScanner(resp.Body)
+			if tt.live != nil {
+				// Wait for the ready event so the head is read before the
+				// live entries arrive.
+				for sc.Scan() && sc.Text() != "event: ready" {
+				}
+				tt.live(t, f, hub)
+			}
+			got := readSSE(t, sc, len(tt.want))
+			for i, want := range tt.want {
+				if got[i].event != want.event || got[i].id != want.id || !strings.Contains(got[i].data, want.data) {
+					t.Errorf("event %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamEndpoint_EndsWhenHubCloses(t *testing.T) {
+	t.Parallel()
+	hub := stream.NewHub()
+	server := httptest.NewServer(NewRouter(RouterConfig{APIKey: "k", DefaultTenant: "t1", Stream: &fakeEntryStreamer{}, StreamHub: hub}))
+	t.Cleanup(server.Close)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/evidence/stream", nil)
+	req.Header.Set("Authorization", "Bearer k")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	hub.Close()
+	done := make(chan struct{})
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still open after hub closed")
+	}
+}
+
+func TestFactsCache_ComputesOncePerEntry(t *testing.T) {
+	t.Parallel()
+
+	c := newFactsCache(2)
+	calls := 0
+	compute := func(risk string) func() (entryFacts, error) {
+		return func() (entryFacts, error) {
+			calls++
+			return entryFacts{effectiveRisk: risk}, nil
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if facts, err := c.get(factsKey{tenantID: "t1", seq: 1}, compute("high")); err != nil || facts.effectiveRisk != "high" {
+			t.Fatalf("get = %+v, %v", facts, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("compute calls = %d, want 1", calls)
+	}
+
+	failed := errors.New("read failed")
+	if _, err := c.get(factsKey{tenantID: "t1", seq: 2}, func() (entryFacts, error) { return entryFacts{}, failed }); !errors.Is(err, failed) {
+		t.Fatalf("get err = %v, want %v", err, failed)
+	}
+	if facts, _ := c.get(factsKey{tenantID: "t1", seq: 2}, compute("low")); facts.effectiveRisk != "low" {
+		t.Fatalf("failed facts were cached: %+v", facts)
+	}
+
+	// Seq 1 is evicted once two newer entries are cached.
+	_, _ = c.get(factsKey{tenantID: "t1", seq: 3}, compute("low"))
+	_, _ = c.get(factsKey{tenantID: "t1", seq: 1}, compute("high"))
+	if calls != 4 {
+		t.Fatalf("compute calls = %d, want 4 after eviction", calls)
+	}
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,7 +22,7 @@ import (
 )
 
 type WebhookStore interface {
-	LastHash(ctx context.Context, tenantID string) (string, error)
+	ChainHead(ctx context.Context, tenantID, writer string) (string, error)
 	SaveRaw(ctx context.Context, tenantID string, raw json.RawMessage) (string, error)
 	ClaimWebhookEvent(ctx context.Context, tenantID, source, key string, payload json.RawMessage) (bool, error)
 	ReleaseWebhookEvent(ctx context.Context, tenantID, source, key string) error
@@ -54,6 +56,23 @@ type argoCDWebhookPayload struct {
 	Message      string `json:"message"`
 }
 
+// maxWebhookChainAttempts bounds rebuilds when concurrent webhooks race to
+// extend the same chain head.
+const maxWebhookChainAttempts = 3
+
+// webhookChainWriter is the chain writer of server-signed mapped entries.
+func webhookChainWriter(signer pkevidence.Signer) string {
+	var keyID string
+	if signer != nil && len(signer.PublicKey()) == ed25519.PublicKeySize {
+		keyID = pkevidence.KeyID(signer.PublicKey())
+	}
+	return store.ChainWriter(keyID, pkevidence.Actor{Type: "controller", ID: "evidra-api"})
+}
+
+func isChainFork(err error) bool {
+	return errors.Is(err, store.ErrChainFork)
+}
+
 type mappedWebhookBuilder func(lastHash string) (pkevidence.EvidenceEntry, int, error)
 
 func handleGenericWebhookWithTenantResolver(store WebhookStore, signer pkevidence.Signer, secret string, resolveTenant WebhookTenantResolver) http.HandlerFunc {
@@ -105,7 +124,7 @@ func handleGenericWebhookWithTenantResolver(store WebhookStore, signer pkevidenc
 		scope := mappedScopeDimensions("generic", payload.Environment, map[string]string{})
 		artifactDigest := canon.SHA256Hex(body)
 
-		processMappedWebhook(w, r, store, tenantID, "generic", idempotencyKey, body, func(lastHash string) (pkevidence.EvidenceEntry, int, error) {
+		processMappedWebhook(w, r, store, tenantID, webhookChainWriter(signer), "generic", idempotencyKey, body, func(lastHash string) (pkevidence.EvidenceEntry, int, error) {
 			if payload.EventType == "operation_started" {
 				entry, err := buildMappedPrescribeEntry(lastHash, signer, actor, sessionID, operationID, prescriptionID, action, artifactDigest, scope)
 				return entry, http.StatusInternalServerError, err
@@ -158,7 +177,7 @@ func handleArgoCDWebhookWithTenantResolver(store WebhookStore, signer pkevidence
 		})
 		artifactDigest := canon.SHA256Hex(body)
 
-		processMappedWebhook(w, r, store, tenantID, source, idempotencyKey, body, func(lastHash string) (pkevidence.EvidenceEntry, int, error) {
+		processMappedWebhook(w, r, store, tenantID, webhookChainWriter(signer), source, idempotencyKey, body, func(lastHash string) (pkevidence.EvidenceEntry, int, error) {
 			switch payload.Event {
 			case "sync_started":
 				entry, err := buildMappedPrescribeEntry(lastHash, signer, actor, payload.OperationID, payload.OperationID, sourceKey, action, artifactDigest, scope)
@@ -249,19 +268,52 @@ func processMappedWebhook(
 	w http.ResponseWriter,
 	r *http.Request,
 	store WebhookStore,
-	tenantID, source, idempotencyKey string,
+	tenantID, writer, source, idempotencyKey string,
 	body json.RawMessage,
 	build mappedWebhookBuilder,
 ) {
-	duplicate, release, err := claimWebhook(r.Context(), store, tenantID, source, idempotencyKey, body)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "webhook idempotency failed")
-		return
+	processMappedSteps(w, r, store, tenantID, writer, body, mappedStep{source: source, key: idempotencyKey, build: build})
+}
+
+// mappedStep is one entry a webhook maps to, claimed under its own
+// idempotency key.
+type mappedStep struct {
+	source, key string
+	build       mappedWebhookBuilder
+}
+
+// processMappedSteps saves the steps in order, skipping those already
+// claimed. A failing step releases its claim and stops, so a redelivery
+// resumes at that step.
+func processMappedSteps(w http.ResponseWriter, r *http.Request, store WebhookStore, tenantID, writer string, body json.RawMessage, steps ...mappedStep) {
+	saved := 0
+	for _, step := range steps {
+		duplicate, status, err := saveMappedStep(r.Context(), store, tenantID, writer, body, step)
+		if err != nil {
+			writeError(w, status, err.Error())
+			return
+		}
+		if !duplicate {
+			saved++
+		}
 	}
-	if duplicate {
+	if saved == 0 {
 		writeJSON(w, http.StatusOK, map[string]string{"status": "duplicate"})
 		return
 	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// saveMappedStep claims and stores one mapped entry. The error is the
+// message for the client, returned with its HTTP status.
+func saveMappedStep(ctx context.Context, store WebhookStore, tenantID, writer string, body json.RawMessage, step mappedStep) (bool, int, error) {
+	duplicate, release, err := claimWebhook(ctx, store, tenantID, step.source, step.key, body)
+	if err != nil {
+		return false, http.StatusInternalServerError, errors.New("webhook idempotency failed")
+	}
+	if duplicate {
+		return true, 0, nil
+	}
 	success := false
 	defer func() {
 		if !success {
@@ -269,37 +321,93 @@ func processMappedWebhook(
 		}
 	}()
 
-	lastHash, err := store.LastHash(r.Context(), tenantID)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "load evidence chain failed")
-		return
-	}
-
-	entry, status, err := build(lastHash)
-	if err != nil {
-		if status == 0 {
-			status = http.StatusInternalServerError
+	// Mapped entries extend the server signing key's own chain. A concurrent
+	// webhook may extend it first; rebuild on the new head when that happens.
+	for attempt := 1; ; attempt++ {
+		lastHash, err := store.ChainHead(ctx, tenantID, writer)
+		if err != nil {
+			return false, http.StatusInternalServerError, errors.New("load evidence chain failed")
 		}
-		if status == http.StatusInternalServerError {
-			writeError(w, status, "build mapped evidence failed")
-			return
+
+		entry, status, err := step.build(lastHash)
+		if err != nil {
+			if status == 0 || status == http.StatusInternalServerError {
+				return false, http.StatusInternalServerError, errors.New("build mapped evidence failed")
+			}
+			return false, status, err
 		}
-		writeError(w, status, err.Error())
-		return
-	}
 
-	raw, err := json.Marshal(entry)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "encode mapped evidence failed")
-		return
-	}
-	if _, err := store.SaveRaw(r.Context(), tenantID, raw); err != nil {
-		writeError(w, http.StatusInternalServerError, "store mapped evidence failed")
-		return
+		raw, err := json.Marshal(entry)
+		if err != nil {
+			return false, http.StatusInternalServerError, errors.New("encode mapped evidence failed")
+		}
+		_, err = store.SaveRaw(ctx, tenantID, raw)
+		if isChainFork(err) && attempt < maxWebhookChainAttempts {
+			continue
+		}
+		if err != nil {
+			return false, http.StatusInternalServerError, errors.New("store mapped evidence failed")
+		}
+		break
 	}
 
 	success = true
-	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+	return false, 0, nil
+}
+
+// mappedLifecycle is a CI or GitOps state change mapped onto the
+// prescribe/report lifecycle. Start events become prescriptions and
+// completion events become reports for the same operation.
+type mappedLifecycle struct {
+	Source      string
+	Tool        string
+	Operation   string
+	OperationID string
+	SessionID   string
+	Environment string
+	Actor       pkevidence.Actor
+	Scope       map[string]string
+	// ArtifactDigest identifies what was rolled out; empty uses the digest
+	// of the webhook body.
+	ArtifactDigest string
+	Started        bool
+	Verdict        pkevidence.Verdict
+	ExitCode       int
+}
+
+// processMappedLifecycle records ev. Both entries carry a prescription ID
+// derived from the operation, so a report pairs with its prescription
+// without server-side state. A completion whose start was never received
+// records the prescription first, so the report is not left unprescribed.
+func processMappedLifecycle(w http.ResponseWriter, r *http.Request, store WebhookStore, signer pkevidence.Signer, tenantID string, body json.RawMessage, ev mappedLifecycle) {
+	prescriptionID := mappedPrescriptionID(ev.Source, ev.Tool, ev.Operation, "", ev.OperationID, ev.Environment, "")
+	action := mappedCanonicalAction(ev.Tool, ev.Operation, ev.Environment)
+	scope := mappedScopeDimensions(ev.Source, ev.Environment, ev.Scope)
+	artifactDigest := ev.ArtifactDigest
+	if artifactDigest == "" {
+		artifactDigest = canon.SHA256Hex(body)
+	}
+
+	steps := []mappedStep{{
+		source: ev.Source + "_start",
+		key:    ev.OperationID + ":start",
+		build: func(lastHash string) (pkevidence.EvidenceEntry, int, error) {
+			entry, err := buildMappedPrescribeEntry(lastHash, signer, ev.Actor, ev.SessionID, ev.OperationID, prescriptionID, action, artifactDigest, scope)
+			return entry, http.StatusInternalServerError, err
+		},
+	}}
+	if !ev.Started {
+		steps = append(steps, mappedStep{
+			source: ev.Source + "_complete",
+			key:    ev.OperationID + ":complete",
+			build: func(lastHash string) (pkevidence.EvidenceEntry, int, error) {
+				exitCode := ev.ExitCode
+				entry, err := buildMappedReportEntry(lastHash, signer, ev.Actor, ev.SessionID, ev.OperationID, prescriptionID, artifactDigest, scope, ev.Verdict, &exitCode)
+				return entry, http.StatusInternalServerError, err
+			},
+		})
+	}
+	processMappedSteps(w, r, store, tenantID, webhookChainWriter(signer), body, steps...)
 }
 
 func mappedActor(actorID, source string) pkevidence.Actor {
@@ -0,0 +1,474 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pkevidence "samebits.com/evidra/pkg/evidence"
+)
+
+const (
+	toolGitHubActions = "github_actions"
+	toolGitLabCI      = "gitlab_ci"
+
+	// maxCIWebhookBody bounds CI webhook bodies; GitLab pipeline events list
+	// every job and can be large.
+	maxCIWebhookBody = 5 << 20
+
+	// gitLabSignatureTolerance is how far a signed GitLab delivery's
+	// timestamp may be from now.
+	gitLabSignatureTolerance = 5 * time.Minute
+)
+
+type gitHubUser struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+type gitHubWorkflowJobPayload struct {
+	Action      string `json:"action"`
+	WorkflowJob struct {
+		ID           int64    `json:"id"`
+		RunID        int64    `json:"run_id"`
+		RunAttempt   int      `json:"run_attempt"`
+		Name         string   `json:"name"`
+		WorkflowName string   `json:"workflow_name"`
+		Conclusion   string   `json:"conclusion"`
+		HeadSHA      string   `json:"head_sha"`
+		HeadBranch   string   `json:"head_branch"`
+		Steps        []any    `json:"steps"`
+		Labels       []string `json:"labels"`
+	} `json:"workflow_job"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender gitHubUser `json:"sender"`
+}
+
+type gitHubDeploymentStatusPayload struct {
+	DeploymentStatus struct {
+		State       string `json:"state"`
+		Environment string `json:"environment"`
+	} `json:"deployment_status"`
+	Deployment struct {
+		ID          int64      `json:"id"`
+		SHA         string     `json:"sha"`
+		Ref         string     `json:"ref"`
+		Task        string     `json:"task"`
+		Environment string     `json:"environment"`
+		Creator     gitHubUser `json:"creator"`
+	} `json:"deployment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender gitHubUser `json:"sender"`
+}
+
+type gitLabUser struct {
+	Username string `json:"username"`
+}
+
+type gitLabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+type gitLabPipelinePayload struct {
+	ObjectKind       string `json:"object_kind"`
+	ObjectAttributes struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+		Ref    string `json:"ref"`
+		SHA    string `json:"sha"`
+		Source string `json:"source"`
+	} `json:"object_attributes"`
+	User    gitLabUser    `json:"user"`
+	Project gitLabProject `json:"project"`
+}
+
+type gitLabDeploymentPayload struct {
+	ObjectKind      string        `json:"object_kind"`
+	Status          string        `json:"status"`
+	DeploymentID    int64         `json:"deployment_id"`
+	Environment     string        `json:"environment"`
+	EnvironmentTier string        `json:"environment_tier"`
+	ShortSHA        string        `json:"short_sha"`
+	Ref             string        `json:"ref"`
+	User            gitLabUser    `json:"user"`
+	Project         gitLabProject `json:"project"`
+}
+
+func handleGitHubWebhookWithTenantResolver(store WebhookStore, signer pkevidence.Signer, secret string, resolveTenant WebhookTenantResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := signedWebhookBody(w, r, signer, func(h http.Header, body []byte) bool {
+			return verifySHA256HMAC(secret, h.Get("X-Hub-Signature-256"), body)
+		})
+		if !ok {
+			return
+		}
+		event := r.Header.Get("X-GitHub-Event")
+		if event == "ping" {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "pong"})
+			return
+		}
+		tenantID, ok := resolveCIWebhookTenant(w, r, resolveTenant)
+		if !ok {
+			return
+		}
+
+		ev, ok, err := mapGitHubEvent(event, body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !ok {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		}
+		processMappedLifecycle(w, r, store, signer, tenantID, body, ev)
+	}
+}
+
+func handleGitLabWebhookWithTenantResolver(store WebhookStore, signer pkevidence.Signer, secret string, resolveTenant WebhookTenantResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := signedWebhookBody(w, r, signer, func(h http.Header, body []byte) bool {
+			return verifyGitLabRequest(secret, h, body, time.Now())
+		})
+		if !ok {
+			return
+		}
+		tenantID, ok := resolveCIWebhookTenant(w, r, resolveTenant)
+		if !ok {
+			return
+		}
+
+		ev, ok, err := mapGitLabEvent(r.Header.Get("X-Gitlab-Event"), body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !ok {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		}
+		processMappedLifecycle(w, r, store, signer, tenantID, body, ev)
+	}
+}
+
+// signedWebhookBody reads a webhook body whose authenticity is proven by a
+// signature or token the verify func checks against the body and headers.
+func signedWebhookBody(w http.ResponseWriter, r *http.Request, signer pkevidence.Signer, verify func(http.Header, []byte) bool) (json.RawMessage, bool) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCIWebhookBody+1))
+	if err != nil || len(body) > maxCIWebhookBody {
+		writeError(w, http.StatusBadRequest, "empty or unreadable body")
+		return nil, false
+	}
+	if !verify(r.Header, body) {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return nil, false
+	}
+	if signer == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook ingestion requires server signing")
+		return nil, false
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(strings.ToLower(ct), "application/json") {
+		writeError(w, http.StatusBadRequest, "content-type must be application/json")
+		return nil, false
+	}
+	if len(body) == 0 {
+		writeError(w, http.StatusBadRequest, "empty or unreadable body")
+		return nil, false
+	}
+	return body, true
+}
+
+// resolveCIWebhookTenant accepts the tenant API key from the api_key query
+// parameter as well, because GitHub cannot send custom headers.
+func resolveCIWebhookTenant(w http.ResponseWriter, r *http.Request, resolveTenant WebhookTenantResolver) (string, bool) {
+	if r.Header.Get(webhookTenantAPIKeyHeader) == "" {
+		if key := r.URL.Query().Get("api_key"); key != "" {
+			r.Header.Set(webhookTenantAPIKeyHeader, key)
+		}
+	}
+	return resolveWebhookTenant(w, r, resolveTenant)
+}
+
+// verifySHA256HMAC checks a "sha256=<hex>" signature header, the
+// HMAC-SHA256 of the body keyed with the webhook secret. GitHub and Flux's
+// generic-hmac provider sign this way.
+func verifySHA256HMAC(secret, header string, body []byte) bool {
+	sig, ok := strings.CutPrefix(strings.TrimSpace(header), "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// verifyGitLabRequest accepts GitLab's signing-token scheme (Standard
+// Webhooks: base64 HMAC-SHA256 of "<id>.<timestamp>.<body>" in
+// webhook-signature) and falls back to the plain X-Gitlab-Token secret.
+func verifyGitLabRequest(secret string, h http.Header, body []byte, now time.Time) bool {
+	if secret == "" {
+		return false
+	}
+	signatures := h.Get("Webhook-Signature")
+	if signatures == "" {
+		token := h.Get("X-Gitlab-Token")
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+
+	id, ts := h.Get("Webhook-Id"), h.Get("Webhook-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if id == "" || err != nil {
+		return false
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > gitLabSignatureTolerance || d < -gitLabSignatureTolerance {
+		return false
+	}
+	key := []byte(secret)
+	if enc, ok := strings.CutPrefix(secret, "whsec_"); ok {
+		if key, err = base64.StdEncoding.DecodeString(enc); err != nil {
+			return false
+		}
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id + "." + ts + "."))
+	mac.Write(body)
+	want := mac.Sum(nil)
+	for _, s := range strings.Fields(signatures) {
+		enc, ok := strings.CutPrefix(s, "v1,")
+		if !ok {
+			continue
+		}
+		if got, err := base64.StdEncoding.DecodeString(enc); err == nil && hmac.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// mapGitHubEvent maps workflow_job and deployment_status events. ok is false
+// for events and states that are not lifecycle transitions.
+func mapGitHubEvent(event string, body []byte) (mappedLifecycle, bool, error) {
+	switch event {
+	case "workflow_job":
+		var p gitHubWorkflowJobPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			return mappedLifecycle{}, false, fmt.Errorf("invalid JSON")
+		}
+		job, repo := p.WorkflowJob, p.Repository.FullName
+		if job.ID == 0 || repo == "" {
+			return mappedLifecycle{}, false, fmt.Errorf("workflow_job.id and repository.full_name are required")
+		}
+		ev := mappedLifecycle{
+			Source:      "github",
+			Tool:        toolGitHubActions,
+			Operation:   "workflow_job",
+			OperationID: fmt.Sprintf("github:%s:job:%d", repo, job.ID),
+			SessionID:   fmt.Sprintf("github:%s:run:%d:%d", repo, job.RunID, max(job.RunAttempt, 1)),
+			Actor:       ciActor(p.Sender.Login, isGitHubBot(p.Sender), "github"),
+			Scope: map[string]string{
+				"repository": repo,
+				"workflow":   job.WorkflowName,
+				"job":        job.Name,
+				"ref":        job.HeadBranch,
+				"revision":   job.HeadSHA,
+			},
+		}
+		switch p.Action {
+		case "in_progress":
+			ev.Started = true
+			return ev, true, nil
+		case "completed":
+			// Skipped jobs and jobs cancelled while queued never ran.
+			if job.Conclusion == "skipped" || (job.Conclusion == "cancelled" && len(job.Steps) == 0) {
+				return mappedLifecycle{}, false, nil
+			}
+			ev.Verdict, ev.ExitCode = ciVerdict(job.Conclusion)
+			return ev, true, nil
+		default:
+			return mappedLifecycle{}, false, nil
+		}
+
+	case "deployment_status":
+		var p gitHubDeploymentStatusPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			return mappedLifecycle{}, false, fmt.Errorf("invalid JSON")
+		}
+		d, repo := p.Deployment, p.Repository.FullName
+		if d.ID == 0 || repo == "" {
+			return mappedLifecycle{}, false, fmt.Errorf("deployment.id and repository.full_name are required")
+		}
+		creator := d.Creator
+		if creator.Login == "" {
+			creator = p.Sender
+		}
+		env := p.DeploymentStatus.Environment
+		if env == "" {
+			env = d.Environment
+		}
+		operationID := fmt.Sprintf("github:%s:deployment:%d", repo, d.ID)
+		ev := mappedLifecycle{
+			Source:      "github",
+			Tool:        toolGitHubActions,
+			Operation:   "deploy",
+			OperationID: operationID,
+			SessionID:   operationID,
+			Environment: env,
+			Actor:       ciActor(creator.Login, isGitHubBot(creator), "github"),
+			Scope: map[string]string{
+				"repository": repo,
+				"task":       d.Task,
+				"ref":        d.Ref,
+				"revision":   d.SHA,
+			},
+		}
+		switch state := p.DeploymentStatus.State; state {
+		case "queued", "pending", "in_progress":
+			ev.Started = true
+			return ev, true, nil
+		case "success", "failure", "error":
+			ev.Verdict, ev.ExitCode = ciVerdict(state)
+			return ev, true, nil
+		default:
+			return mappedLifecycle{}, false, nil
+		}
+
+	default:
+		return mappedLifecycle{}, false, nil
+	}
+}
+
+// mapGitLabEvent maps pipeline and deployment events. ok is false for events
+// and states that are not lifecycle transitions.
+func mapGitLabEvent(event string, body []byte) (mappedLifecycle, bool, error) {
+	switch event {
+	case "Pipeline Hook":
+		var p gitLabPipelinePayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			return mappedLifecycle{}, false, fmt.Errorf("invalid JSON")
+		}
+		attrs, project := p.ObjectAttributes, p.Project.PathWithNamespace
+		if attrs.ID == 0 || project == "" {
+			return mappedLifecycle{}, false, fmt.Errorf("object_attributes.id and project.path_with_namespace are required")
+		}
+		operationID := fmt.Sprintf("gitlab:%s:pipeline:%d", project, attrs.ID)
+		ev := mappedLifecycle{
+			Source:      "gitlab",
+			Tool:        toolGitLabCI,
+			Operation:   "pipeline",
+			OperationID: operationID,
+			SessionID:   operationID,
+			Actor:       ciActor(p.User.Username, isGitLabBot(p.User), "gitlab"),
+			Scope: map[string]string{
+				"repository":      project,
+				"ref":             attrs.Ref,
+				"revision":        attrs.SHA,
+				"pipeline_source": attrs.Source,
+			},
+		}
+		return gitLabTransition(ev, attrs.Status)
+
+	case "Deployment Hook":
+		var p gitLabDeploymentPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			return mappedLifecycle{}, false, fmt.Errorf("invalid JSON")
+		}
+		project := p.Project.PathWithNamespace
+		if p.DeploymentID == 0 || project == "" {
+			return mappedLifecycle{}, false, fmt.Errorf("deployment_id and project.path_with_namespace are required")
+		}
+		// The tier classifies custom environment names such as "prod-eu".
+		env := p.EnvironmentTier
+		if env == "" {
+			env = p.Environment
+		}
+		operationID := fmt.Sprintf("gitlab:%s:deployment:%d", project, p.DeploymentID)
+		ev := mappedLifecycle{
+			Source:      "gitlab",
+			Tool:        toolGitLabCI,
+			Operation:   "deploy",
+			OperationID: operationID,
+			SessionID:   operationID,
+			Environment: env,
+			Actor:       ciActor(p.User.Username, isGitLabBot(p.User), "gitlab"),
+			Scope: map[string]string{
+				"repository":       project,
+				"environment_name": p.Environment,
+				"ref":              p.Ref,
+				"revision":         p.ShortSHA,
+			},
+		}
+		return gitLabTransition(ev, p.Status)
+
+	default:
+		return mappedLifecycle{}, false, nil
+	}
+}
+
+func gitLabTransition(ev mappedLifecycle, status string) (mappedLifecycle, bool, error) {
+	switch status {
+	case "running":
+		ev.Started = true
+		return ev, true, nil
+	case "success", "failed", "canceled":
+		ev.Verdict, ev.ExitCode = ciVerdict(status)
+		return ev, true, nil
+	default:
+		return mappedLifecycle{}, false, nil
+	}
+}
+
+// ciVerdict maps a CI conclusion onto a report verdict. Cancelled and timed
+// out runs did not finish, so they are errors rather than failures.
+func ciVerdict(conclusion string) (pkevidence.Verdict, int) {
+	switch conclusion {
+	case "success", "neutral":
+		return pkevidence.VerdictSuccess, 0
+	case "failure", "failed":
+		return pkevidence.VerdictFailure, 1
+	default:
+		return pkevidence.VerdictError, -1
+	}
+}
+
+func isGitHubBot(u gitHubUser) bool {
+	return u.Type == "Bot" || strings.HasSuffix(u.Login, "[bot]")
+}
+
+// isGitLabBot recognizes project, group, and service account bot users,
+// whose usernames GitLab generates as "<kind>_<id>_bot_<suffix>".
+func isGitLabBot(u gitLabUser) bool {
+	return strings.Contains(u.Username, "_bot_") || strings.HasSuffix(u.Username, "_bot")
+}
+
+// ciActor is the user or bot that triggered a CI event.
+func ciActor(login string, bot bool, source string) pkevidence.Actor {
+	if strings.TrimSpace(login) == "" {
+		return mappedActor("", source)
+	}
+	actorType := "human"
+	if bot {
+		actorType = "bot"
+	}
+	return pkevidence.Actor{
+		Type:       actorType,
+		ID:         strings.TrimSpace(login),
+		Provenance: "mapped:" + source,
+	}
+}
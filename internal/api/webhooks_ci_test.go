@@ -0,0 +1,347 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/canon"
+	testutil "samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func gitHubSignature(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySHA256HMAC(t *testing.T) {
+	t.Parallel()
+	body := `{"action":"completed"}`
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		want   bool
+	}{
+		{name: "valid", secret: "s3cret", header: gitHubSignature("s3cret", body), want: true},
+		{name: "wrong secret", secret: "s3cret", header: gitHubSignature("other", body)},
+		{name: "missing prefix", secret: "s3cret", header: strings.TrimPrefix(gitHubSignature("s3cret", body), "sha256=")},
+		{name: "not hex", secret: "s3cret", header: "sha256=zz"},
+		{name: "empty secret", secret: "", header: gitHubSignature("", body)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := verifySHA256HMAC(tt.secret, tt.header, []byte(body)); got != tt.want {
+				t.Fatalf("verifySHA256HMAC = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyGitLabRequest(t *testing.T) {
+	t.Parallel()
+	body := []byte(`{"object_kind":"pipeline"}`)
+	now := time.Unix(1_760_000_000, 0)
+	key := []byte("0123456789abcdef")
+	signingToken := "whsec_" + base64.StdEncoding.EncodeToString(key)
+	signed := func(key []byte, ts time.Time) http.Header {
+		stamp := strconv.FormatInt(ts.Unix(), 10)
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte("msg-1." + stamp + "."))
+		mac.Write(body)
+		h := http.Header{}
+		h.Set("Webhook-Id", "msg-1")
+		h.Set("Webhook-Timestamp", stamp)
+		h.Set("Webhook-Signature", "v1,bm90LXRoaXM= v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		return h
+	}
+	token := func(v string) http.Header {
+		h := http.Header{}
+		h.Set("X-Gitlab-Token", v)
+		return h
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		header http.Header
+		want   bool
+	}{
+		{name: "secret token", secret: "tok", header: token("tok"), want: true},
+		{name: "wrong token", secret: "tok", header: token("nope")},
+		{name: "missing token", secret: "tok", header: http.Header{}},
+		{name: "signing token", secret: signingToken, header: signed(key, now), want: true},
+		{name: "signing token wrong key", secret: signingToken, header: signed([]byte("other"), now)},
+		{name: "stale signature", secret: signingToken, header: signed(key, now.Add(-time.Hour))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := verifyGitLabRequest(tt.secret, tt.header, body, now); got != tt.want {
+				t.Fatalf("verifyGitLabRequest = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+const gitHubJobPayload = `{
+	"action": %q,
+	"workflow_job": {
+		"id": 42, "run_id": 7, "run_attempt": 1,
+		"name": "deploy", "workflow_name": "release",
+		"conclusion": %q, "head_sha": "abc123", "head_branch": "main",
+		"steps": [{"name": "checkout"}]
+	},
+	"repository": {"full_name": "acme/app"},
+	"sender": {"login": "renovate[bot]", "type": "Bot"}
+}`
+
+func gitHubJob(action, conclusion string) string {
+	return fmt.Sprintf(gitHubJobPayload, action, conclusion)
+}
+
+func TestMapGitHubEvent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		event, body string
+		wantOK      bool
+		wantErr     bool
+		check       func(t *testing.T, ev mappedLifecycle)
+	}{
+		{
+			name: "job started", event: "workflow_job", body: gitHubJob("in_progress", ""), wantOK: true,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if !ev.Started || ev.Tool != toolGitHubActions || ev.OperationID != "github:acme/app:job:42" || ev.SessionID != "github:acme/app:run:7:1" {
+					t.Fatalf("event = %+v", ev)
+				}
+				if ev.Actor.Type != "bot" || ev.Actor.ID != "renovate[bot]" {
+					t.Fatalf("actor = %+v", ev.Actor)
+				}
+			},
+		},
+		{
+			name: "job failed", event: "workflow_job", body: gitHubJob("completed", "failure"), wantOK: true,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Started || ev.Verdict != evidence.VerdictFailure || ev.ExitCode != 1 {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{name: "job queued", event: "workflow_job", body: gitHubJob("queued", "")},
+		{name: "job skipped", event: "workflow_job", body: gitHubJob("completed", "skipped")},
+		{name: "job missing id", event: "workflow_job", body: `{"action":"in_progress","repository":{"full_name":"acme/app"}}`, wantErr: true},
+		{
+			name: "deployment succeeded", event: "deployment_status", wantOK: true,
+			body: `{"deployment_status":{"state":"success","environment":"production"},
+				"deployment":{"id":9,"sha":"abc","ref":"main","task":"deploy","creator":{"login":"alice","type":"User"}},
+				"repository":{"full_name":"acme/app"},"sender":{"login":"github-actions[bot]","type":"Bot"}}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Verdict != evidence.VerdictSuccess || ev.Environment != "production" || ev.OperationID != "github:acme/app:deployment:9" {
+					t.Fatalf("event = %+v", ev)
+				}
+				if ev.Actor.Type != "human" || ev.Actor.ID != "alice" {
+					t.Fatalf("actor = %+v", ev.Actor)
+				}
+			},
+		},
+		{
+			name: "deployment inactive", event: "deployment_status",
+			body: `{"deployment_status":{"state":"inactive"},"deployment":{"id":9},"repository":{"full_name":"acme/app"}}`,
+		},
+		{name: "other event", event: "push", body: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ev, ok, err := mapGitHubEvent(tt.event, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.check != nil {
+				tt.check(t, ev)
+			}
+		})
+	}
+}
+
+func TestMapGitLabEvent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		event, body string
+		wantOK      bool
+		wantErr     bool
+		check       func(t *testing.T, ev mappedLifecycle)
+	}{
+		{
+			name: "pipeline running", event: "Pipeline Hook", wantOK: true,
+			body: `{"object_kind":"pipeline","object_attributes":{"id":11,"status":"running","ref":"main","sha":"abc"},
+				"user":{"username":"project_5_bot_3f2a"},"project":{"path_with_namespace":"acme/app"}}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if !ev.Started || ev.Tool != toolGitLabCI || ev.OperationID != "gitlab:acme/app:pipeline:11" || ev.Actor.Type != "bot" {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "pipeline canceled", event: "Pipeline Hook", wantOK: true,
+			body: `{"object_kind":"pipeline","object_attributes":{"id":11,"status":"canceled"},
+				"user":{"username":"bob"},"project":{"path_with_namespace":"acme/app"}}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Verdict != evidence.VerdictError || ev.ExitCode != -1 || ev.Actor.Type != "human" {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "pipeline pending", event: "Pipeline Hook",
+			body: `{"object_kind":"pipeline","object_attributes":{"id":11,"status":"pending"},"project":{"path_with_namespace":"acme/app"}}`,
+		},
+		{
+			name: "deployment uses tier", event: "Deployment Hook", wantOK: true,
+			body: `{"object_kind":"deployment","status":"failed","deployment_id":3,"environment":"prod-eu",
+				"environment_tier":"production","user":{"username":"bob"},"project":{"path_with_namespace":"acme/app"}}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Verdict != evidence.VerdictFailure || ev.Environment != "production" || ev.Scope["environment_name"] != "prod-eu" {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "deployment missing id", event: "Deployment Hook", wantErr: true,
+			body: `{"object_kind":"deployment","status":"running","project":{"path_with_namespace":"acme/app"}}`,
+		},
+		{name: "other event", event: "Push Hook", body: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ev, ok, err := mapGitLabEvent(tt.event, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.check != nil {
+				tt.check(t, ev)
+			}
+		})
+	}
+}
+
+func TestHandleGitHubWebhook_MapsJobLifecycle(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeWebhookStore{}
+	handler := handleGitHubWebhookWithTenantResolver(store, testutil.TestSigner(t), "gh-secret", func(_ context.Context, key string) (string, error) {
+		if key != "tenant-api-key" {
+			return "", nil
+		}
+		return "tenant-123", nil
+	})
+	send := func(event, body, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/hooks/github?api_key=tenant-api-key", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-Hub-Signature-256", signature)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send("ping", `{"zen":"hi"}`, gitHubSignature("gh-secret", `{"zen":"hi"}`)); rec.Code != http.StatusOK {
+		t.Fatalf("ping status = %d", rec.Code)
+	}
+	started := gitHubJob("in_progress", "")
+	if rec := send("workflow_job", started, gitHubSignature("wrong", started)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad signature status = %d, want 401", rec.Code)
+	}
+	if rec := send("workflow_job", started, gitHubSignature("gh-secret", started)); rec.Code != http.StatusAccepted {
+		t.Fatalf("start status = %d: %s", rec.Code, rec.Body.String())
+	}
+	completed := gitHubJob("completed", "success")
+	if rec := send("workflow_job", completed, gitHubSignature("gh-secret", completed)); rec.Code != http.StatusAccepted {
+		t.Fatalf("complete status = %d: %s", rec.Code, rec.Body.String())
+	}
+	queued := gitHubJob("queued", "")
+	if rec := send("workflow_job", queued, gitHubSignature("gh-secret", queued)); rec.Code != http.StatusOK || len(store.savedRaw) != 2 {
+		t.Fatalf("queued status = %d, saved = %d", rec.Code, len(store.savedRaw))
+	}
+
+	var prescribe, report evidence.EvidenceEntry
+	_ = json.Unmarshal(store.savedRaw[0], &prescribe)
+	_ = json.Unmarshal(store.savedRaw[1], &report)
+	if prescribe.Type != evidence.EntryTypePrescribe || report.Type != evidence.EntryTypeReport {
+		t.Fatalf("types = %s, %s", prescribe.Type, report.Type)
+	}
+	if store.savedTenants[0] != "tenant-123" || prescribe.Actor.ID != "renovate[bot]" || prescribe.Actor.Type != "bot" {
+		t.Fatalf("tenant = %s, actor = %+v", store.savedTenants[0], prescribe.Actor)
+	}
+	var rx evidence.PrescriptionPayload
+	_ = json.Unmarshal(prescribe.Payload, &rx)
+	var action canon.CanonicalAction
+	_ = json.Unmarshal(rx.CanonicalAction, &action)
+	if action.Tool != toolGitHubActions || prescribe.ScopeDimensions["repository"] != "acme/app" {
+		t.Fatalf("action = %+v, scope = %v", action, prescribe.ScopeDimensions)
+	}
+	var rp evidence.ReportPayload
+	_ = json.Unmarshal(report.Payload, &rp)
+	if rp.PrescriptionID != prescribe.EntryID || rp.Verdict != evidence.VerdictSuccess {
+		t.Fatalf("report = %+v, prescription %s", rp, prescribe.EntryID)
+	}
+}
+
+func TestHandleGitLabWebhook_RequiresTokenAndTenant(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeWebhookStore{}
+	handler := handleGitLabWebhookWithTenantResolver(store, testutil.TestSigner(t), "gl-token", func(context.Context, string) (string, error) {
+		return "tenant-123", nil
+	})
+	body := `{"object_kind":"deployment","status":"running","deployment_id":3,"environment":"production",
+		"user":{"username":"bob"},"project":{"path_with_namespace":"acme/app"}}`
+	tests := []struct {
+		name       string
+		token      string
+		apiKey     string
+		wantStatus int
+	}{
+		{name: "bad token", token: "nope", apiKey: "k", wantStatus: http.StatusUnauthorized},
+		{name: "no tenant key", token: "gl-token", wantStatus: http.StatusUnauthorized},
+		{name: "accepted", token: "gl-token", apiKey: "k", wantStatus: http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/v1/hooks/gitlab", strings.NewReader(body))
+		req.Header.Set("X-Gitlab-Event", "Deployment Hook")
+		req.Header.Set("X-Gitlab-Token", tt.token)
+		if tt.apiKey != "" {
+			req.Header.Set("X-Evidra-API-Key", tt.apiKey)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+	}
+	if len(store.savedRaw) != 1 {
+		t.Fatalf("saved = %d, want 1", len(store.savedRaw))
+	}
+}
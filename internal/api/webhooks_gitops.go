@@ -0,0 +1,253 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	iauth "samebits.com/evidra/internal/auth"
+	"samebits.com/evidra/internal/canon"
+	pkevidence "samebits.com/evidra/pkg/evidence"
+)
+
+const (
+	toolFlux         = "flux"
+	toolArgoRollouts = "argo_rollouts"
+)
+
+// fluxEventPayload is the event the Flux notification-controller posts to a
+// generic or generic-hmac provider.
+type fluxEventPayload struct {
+	InvolvedObject struct {
+		Kind      string `json:"kind"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"involvedObject"`
+	Severity            string            `json:"severity"`
+	Timestamp           string            `json:"timestamp"`
+	Message             string            `json:"message"`
+	Reason              string            `json:"reason"`
+	Metadata            map[string]string `json:"metadata"`
+	ReportingController string            `json:"reportingController"`
+}
+
+// argoRolloutsWebhookPayload is the body an Argo Rollouts notification
+// template renders for the webhook service. Like the Argo CD hook, the
+// contract is documented rather than native.
+type argoRolloutsWebhookPayload struct {
+	Rollout     string `json:"rollout"`
+	Namespace   string `json:"namespace"`
+	Revision    string `json:"revision"`
+	Phase       string `json:"phase"`
+	Step        string `json:"step"`
+	Message     string `json:"message"`
+	InitiatedBy string `json:"initiated_by"`
+}
+
+func handleFluxWebhookWithTenantResolver(store WebhookStore, signer pkevidence.Signer, secret string, resolveTenant WebhookTenantResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := signedWebhookBody(w, r, signer, func(h http.Header, body []byte) bool {
+			return verifyFluxRequest(secret, h, body)
+		})
+		if !ok {
+			return
+		}
+		tenantID, ok := resolveWebhookTenant(w, r, resolveTenant)
+		if !ok {
+			return
+		}
+
+		ev, ok, err := mapFluxEvent(body, r.URL.Query().Get("environment"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !ok {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		}
+		processMappedLifecycle(w, r, store, signer, tenantID, body, ev)
+	}
+}
+
+func handleArgoRolloutsWebhookWithTenantResolver(store WebhookStore, signer pkevidence.Signer, secret string, resolveTenant WebhookTenantResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := webhookRequestBody(w, r, secret, signer)
+		if !ok {
+			return
+		}
+		tenantID, ok := resolveWebhookTenant(w, r, resolveTenant)
+		if !ok {
+			return
+		}
+
+		ev, ok, err := mapArgoRolloutsEvent(body, r.URL.Query().Get("environment"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !ok {
+			writeJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		}
+		processMappedLifecycle(w, r, store, signer, tenantID, body, ev)
+	}
+}
+
+// verifyFluxRequest accepts the generic-hmac provider's X-Signature and
+// falls back to a bearer token set through the provider's headers.
+func verifyFluxRequest(secret string, h http.Header, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+	if sig := h.Get("X-Signature"); sig != "" {
+		return verifySHA256HMAC(secret, sig, body)
+	}
+	token := strings.TrimSpace(iauth.ParseBearerToken(h.Get("Authorization")))
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// mapFluxEvent maps Kustomization and HelmRelease reconciliation events.
+// Progressing starts an operation; success, failure and rollback reasons
+// complete it. Each source revision is one operation, so repeated events for
+// a revision Flux keeps retrying are recorded once.
+func mapFluxEvent(body []byte, environment string) (mappedLifecycle, bool, error) {
+	var p fluxEventPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return mappedLifecycle{}, false, fmt.Errorf("invalid JSON")
+	}
+	obj := p.InvolvedObject
+	if obj.Kind == "" || obj.Name == "" {
+		return mappedLifecycle{}, false, fmt.Errorf("involvedObject.kind and involvedObject.name are required")
+	}
+
+	revision := fluxRevision(p.Metadata)
+	attempt := revision
+	if attempt == "" {
+		attempt = "ts:" + p.Timestamp
+	}
+	operationID := fmt.Sprintf("flux:%s/%s/%s@%s", obj.Kind, obj.Namespace, obj.Name, attempt)
+	if environment == "" {
+		environment = obj.Namespace
+	}
+	ev := mappedLifecycle{
+		Source:         "flux",
+		Tool:           toolFlux,
+		Operation:      "reconcile",
+		OperationID:    operationID,
+		SessionID:      operationID,
+		Environment:    environment,
+		Actor:          mappedActor(p.ReportingController, "flux"),
+		ArtifactDigest: revisionArtifactDigest(revision),
+		Scope: map[string]string{
+			"kind":      obj.Kind,
+			"name":      obj.Name,
+			"namespace": obj.Namespace,
+			"revision":  revision,
+		},
+	}
+
+	reason := p.Reason
+	switch {
+	case strings.EqualFold(p.Severity, "error") || strings.HasPrefix(reason, "Rollback"):
+		ev.Verdict, ev.ExitCode = pkevidence.VerdictFailure, 1
+		return ev, true, nil
+	case reason == "Progressing" || reason == "ProgressingWithRetry":
+		ev.Started = true
+		return ev, true, nil
+	case reason == "ReconciliationSucceeded" || reason == "InstallSucceeded" || reason == "UpgradeSucceeded" || reason == "TestSucceeded":
+		ev.Verdict, ev.ExitCode = pkevidence.VerdictSuccess, 0
+		return ev, true, nil
+	default:
+		return mappedLifecycle{}, false, nil
+	}
+}
+
+// fluxRevision reads the applied revision: "revision" on older controllers,
+// "<group>/revision" on current ones.
+func fluxRevision(metadata map[string]string) string {
+	if rev := metadata["revision"]; rev != "" {
+		return rev
+	}
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasSuffix(k, "/revision") && metadata[k] != "" {
+			return metadata[k]
+		}
+	}
+	return ""
+}
+
+// mapArgoRolloutsEvent maps rollout phase transitions. Progressing and
+// Paused (a canary step waiting) start an operation; Healthy completes it,
+// and Degraded or Aborted fail it.
+func mapArgoRolloutsEvent(body []byte, environment string) (mappedLifecycle, bool, error) {
+	var p argoRolloutsWebhookPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return mappedLifecycle{}, false, fmt.Errorf("invalid JSON")
+	}
+	if strings.TrimSpace(p.Rollout) == "" || strings.TrimSpace(p.Revision) == "" {
+		return mappedLifecycle{}, false, fmt.Errorf("rollout and revision are required")
+	}
+
+	operationID := fmt.Sprintf("rollouts:%s/%s@%s", p.Namespace, p.Rollout, p.Revision)
+	if environment == "" {
+		environment = p.Namespace
+	}
+	ev := mappedLifecycle{
+		Source:         "argo_rollouts",
+		Tool:           toolArgoRollouts,
+		Operation:      "rollout",
+		OperationID:    operationID,
+		SessionID:      operationID,
+		Environment:    environment,
+		Actor:          mappedActor(p.InitiatedBy, "argo_rollouts"),
+		ArtifactDigest: revisionArtifactDigest(p.Revision),
+		Scope: map[string]string{
+			"rollout":     p.Rollout,
+			"namespace":   p.Namespace,
+			"revision":    p.Revision,
+			"canary_step": p.Step,
+		},
+	}
+
+	switch strings.ToLower(strings.TrimSpace(p.Phase)) {
+	case "progressing", "paused":
+		ev.Started = true
+		return ev, true, nil
+	case "healthy":
+		ev.Verdict, ev.ExitCode = pkevidence.VerdictSuccess, 0
+		return ev, true, nil
+	case "degraded", "aborted":
+		ev.Verdict, ev.ExitCode = pkevidence.VerdictFailure, 1
+		return ev, true, nil
+	default:
+		return mappedLifecycle{}, false, nil
+	}
+}
+
+// revisionArtifactDigest uses the sha256 digest a revision carries, as OCI
+// sources do ("latest@sha256:…"), and otherwise digests the revision itself.
+// An empty revision leaves the digest to the webhook body.
+func revisionArtifactDigest(revision string) string {
+	if revision == "" {
+		return ""
+	}
+	candidate := revision
+	if i := strings.LastIndex(revision, "@"); i >= 0 {
+		candidate = revision[i+1:]
+	}
+	if strings.HasPrefix(strings.ToLower(candidate), "sha256:") {
+		if digest, err := pkevidence.FormatDigest(candidate); err == nil {
+			return digest
+		}
+	}
+	return canon.SHA256Hex([]byte(revision))
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/canon"
+	testutil "samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+const ociDigest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+func TestMapFluxEvent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		body, env string
+		wantOK    bool
+		wantErr   bool
+		check     func(t *testing.T, ev mappedLifecycle)
+	}{
+		{
+			name: "kustomization progressing", wantOK: true,
+			body: `{"involvedObject":{"kind":"Kustomization","namespace":"flux-system","name":"apps"},"severity":"info",
+				"reason":"Progressing","metadata":{"kustomize.toolkit.fluxcd.io/revision":"main@sha1:abc123"},
+				"reportingController":"kustomize-controller"}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if !ev.Started || ev.Tool != toolFlux || ev.OperationID != "flux:Kustomization/flux-system/apps@main@sha1:abc123" {
+					t.Fatalf("event = %+v", ev)
+				}
+				if ev.Actor.ID != "kustomize-controller" || ev.Environment != "flux-system" || ev.ArtifactDigest != canon.SHA256Hex([]byte("main@sha1:abc123")) {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "oci revision digest and environment override", env: "production", wantOK: true,
+			body: `{"involvedObject":{"kind":"Kustomization","namespace":"apps","name":"web"},"severity":"info",
+				"reason":"ReconciliationSucceeded","metadata":{"revision":"latest@` + ociDigest + `"}}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Started || ev.Verdict != evidence.VerdictSuccess || ev.ArtifactDigest != ociDigest || ev.Environment != "production" {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "helm upgrade failed", wantOK: true,
+			body: `{"involvedObject":{"kind":"HelmRelease","namespace":"web","name":"api"},"severity":"error",
+				"reason":"UpgradeFailed","metadata":{"helm.toolkit.fluxcd.io/revision":"1.4.0"}}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Verdict != evidence.VerdictFailure || ev.ExitCode != 1 || ev.Scope["revision"] != "1.4.0" {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "helm rollback is a failure", wantOK: true,
+			body: `{"involvedObject":{"kind":"HelmRelease","namespace":"web","name":"api"},"severity":"info",
+				"reason":"RollbackSucceeded","metadata":{"helm.toolkit.fluxcd.io/revision":"1.4.0"}}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Verdict != evidence.VerdictFailure {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "no revision falls back to timestamp", wantOK: true,
+			body: `{"involvedObject":{"kind":"Kustomization","namespace":"apps","name":"web"},"severity":"info",
+				"reason":"Progressing","timestamp":"2026-10-18T10:00:00Z"}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.OperationID != "flux:Kustomization/apps/web@ts:2026-10-18T10:00:00Z" || ev.ArtifactDigest != "" {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "other reason ignored",
+			body: `{"involvedObject":{"kind":"Kustomization","namespace":"apps","name":"web"},"severity":"info","reason":"DependencyNotReady"}`,
+		},
+		{name: "missing object", wantErr: true, body: `{"severity":"info","reason":"Progressing"}`},
+		{name: "invalid JSON", wantErr: true, body: `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ev, ok, err := mapFluxEvent([]byte(tt.body), tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.check != nil {
+				tt.check(t, ev)
+			}
+		})
+	}
+}
+
+func TestMapArgoRolloutsEvent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		body    string
+		wantOK  bool
+		wantErr bool
+		check   func(t *testing.T, ev mappedLifecycle)
+	}{
+		{
+			name: "canary step paused", wantOK: true,
+			body: `{"rollout":"web","namespace":"prod","revision":"7","phase":"Paused","step":"2","initiated_by":"alice"}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if !ev.Started || ev.Tool != toolArgoRollouts || ev.OperationID != "rollouts:prod/web@7" || ev.Scope["canary_step"] != "2" {
+					t.Fatalf("event = %+v", ev)
+				}
+				if ev.Actor.ID != "alice" || ev.Environment != "prod" {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "healthy", wantOK: true,
+			body: `{"rollout":"web","namespace":"prod","revision":"7","phase":"Healthy"}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Started || ev.Verdict != evidence.VerdictSuccess || ev.ExitCode != 0 {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "aborted", wantOK: true,
+			body: `{"rollout":"web","namespace":"prod","revision":"7","phase":"Aborted"}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Verdict != evidence.VerdictFailure || ev.ExitCode != 1 {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name: "degraded", wantOK: true,
+			body: `{"rollout":"web","namespace":"prod","revision":"7","phase":"Degraded"}`,
+			check: func(t *testing.T, ev mappedLifecycle) {
+				if ev.Verdict != evidence.VerdictFailure {
+					t.Fatalf("event = %+v", ev)
+				}
+			},
+		},
+		{name: "unknown phase ignored", body: `{"rollout":"web","namespace":"prod","revision":"7","phase":"Unknown"}`},
+		{name: "missing revision", wantErr: true, body: `{"rollout":"web","phase":"Healthy"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ev, ok, err := mapArgoRolloutsEvent([]byte(tt.body), "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.check != nil {
+				tt.check(t, ev)
+			}
+		})
+	}
+}
+
+func TestVerifyFluxRequest(t *testing.T) {
+	t.Parallel()
+	body := []byte(`{"reason":"Progressing"}`)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   bool
+	}{
+		{name: "hmac", header: map[string]string{"X-Signature": gitHubSignature("flux-key", string(body))}, want: true},
+		{name: "bad hmac", header: map[string]string{"X-Signature": gitHubSignature("other", string(body))}},
+		{name: "bearer", header: map[string]string{"Authorization": "Bearer flux-key"}, want: true},
+		{name: "bad bearer", header: map[string]string{"Authorization": "Bearer nope"}},
+		{name: "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := http.Header{}
+			for k, v := range tt.header {
+				h.Set(k, v)
+			}
+			if got := verifyFluxRequest("flux-key", h, body); got != tt.want {
+				t.Fatalf("verifyFluxRequest = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleFluxWebhook_TerminalEventRecordsBothEntries(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeWebhookStore{}
+	handler := handleFluxWebhookWithTenantResolver(store, testutil.TestSigner(t), "flux-key", func(context.Context, string) (string, error) {
+		return "tenant-123", nil
+	})
+	body := `{"involvedObject":{"kind":"HelmRelease","namespace":"web","name":"api"},"severity":"error",
+		"reason":"UpgradeFailed","metadata":{"helm.toolkit.fluxcd.io/revision":"1.4.0"},"reportingController":"helm-controller"}`
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/hooks/flux", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Signature", gitHubSignature("flux-key", body))
+		req.Header.Set("X-Evidra-API-Key", "k")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(); rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := send(); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "duplicate") {
+		t.Fatalf("redelivery status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(store.savedRaw) != 2 {
+		t.Fatalf("saved = %d, want 2", len(store.savedRaw))
+	}
+
+	var prescribe, report evidence.EvidenceEntry
+	_ = json.Unmarshal(store.savedRaw[0], &prescribe)
+	_ = json.Unmarshal(store.savedRaw[1], &report)
+	if prescribe.Type != evidence.EntryTypePrescribe || report.Type != evidence.EntryTypeReport {
+		t.Fatalf("types = %s, %s", prescribe.Type, report.Type)
+	}
+	if prescribe.ArtifactDigest != canon.SHA256Hex([]byte("1.4.0")) || prescribe.ScopeDimensions["kind"] != "HelmRelease" {
+		t.Fatalf("prescribe = %+v", prescribe)
+	}
+	var rp evidence.ReportPayload
+	_ = json.Unmarshal(report.Payload, &rp)
+	if rp.PrescriptionID != prescribe.EntryID || rp.Verdict != evidence.VerdictFailure {
+		t.Fatalf("report = %+v, prescription %s", rp, prescribe.EntryID)
+	}
+}
+
+func TestHandleArgoRolloutsWebhook_RequiresBearer(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeWebhookStore{}
+	handler := handleArgoRolloutsWebhookWithTenantResolver(store, testutil.TestSigner(t), "ro-token", func(context.Context, string) (string, error) {
+		return "tenant-123", nil
+	})
+	body := `{"rollout":"web","namespace":"prod","revision":"7","phase":"Progressing"}`
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "bad token", token: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "accepted", token: "ro-token", wantStatus: http.StatusAccepted},
+		{name: "duplicate", token: "ro-token", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/v1/hooks/argo-rollouts", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		req.Header.Set("X-Evidra-API-Key", "k")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.wantStatus, rec.Body.String())
+		}
+	}
+	if len(store.savedRaw) != 1 {
+		t.Fatalf("saved = %d, want 1", len(store.savedRaw))
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	istore "samebits.com/evidra/internal/store"
 	testutil "samebits.com/evidra/internal/testutil"
 	"samebits.com/evidra/pkg/evidence"
 )
@@ -258,13 +259,28 @@ func TestHandleArgoCDWebhook_UsesOperationIDForLifecycleCorrelation(t *testing.T
 type fakeWebhookStore struct {
 	savedTenants []string
 	savedRaw     []json.RawMessage
+	writers      []string
+	head         string
+	forks        int // SaveRaw calls to fail with ErrChainFork
+	claimed      map[string]bool
 }
 
-func (f *fakeWebhookStore) LastHash(context.Context, string) (string, error) {
-	return "", nil
+func (f *fakeWebhookStore) ChainHead(_ context.Context, _ string, writer string) (string, error) {
+	f.writers = append(f.writers, writer)
+	return f.head, nil
 }
 
 func (f *fakeWebhookStore) SaveRaw(_ context.Context, tenantID string, raw json.RawMessage) (string, error) {
+	if f.forks > 0 {
+		f.forks--
+		// Another writer extended the head meanwhile.
+		f.head = "sha256:concurrent"
+		return "", istore.ErrChainFork
+	}
+	var entry evidence.EvidenceEntry
+	if err := json.Unmarshal(raw, &entry); err == nil {
+		f.head = entry.Hash
+	}
 	f.savedTenants = append(f.savedTenants, tenantID)
 	f.savedRaw = append(f.savedRaw, append(json.RawMessage(nil), raw...))
 	if len(raw) == 0 {
@@ -273,10 +289,55 @@ func (f *fakeWebhookStore) SaveRaw(_ context.Context, tenantID string, raw json.
 	return "receipt-1", nil
 }
 
-func (f *fakeWebhookStore) ClaimWebhookEvent(context.Context, string, string, string, json.RawMessage) (bool, error) {
-	return false, nil
+func (f *fakeWebhookStore) ClaimWebhookEvent(_ context.Context, tenantID, source, key string, _ json.RawMessage) (bool, error) {
+	if f.claimed == nil {
+		f.claimed = map[string]bool{}
+	}
+	id := tenantID + "/" + source + "/" + key
+	duplicate := f.claimed[id]
+	f.claimed[id] = true
+	return duplicate, nil
 }
 
-func (f *fakeWebhookStore) ReleaseWebhookEvent(context.Context, string, string, string) error {
+func (f *fakeWebhookStore) ReleaseWebhookEvent(_ context.Context, tenantID, source, key string) error {
+	delete(f.claimed, tenantID+"/"+source+"/"+key)
 	return nil
 }
+
+func TestHandleGenericWebhook_ExtendsServerKeyChain(t *testing.T) {
+	t.Parallel()
+
+	signer := testutil.TestSigner(t)
+	store := &fakeWebhookStore{head: "sha256:head", forks: 1}
+	handler := handleGenericWebhookWithTenantResolver(store, signer, "route-secret", func(context.Context, string) (string, error) {
+		return "tenant-123", nil
+	})
+
+	req := httptest.NewRequest("POST", "/v1/hooks/generic", strings.NewReader(`{
+		"event_type":"operation_started",
+		"tool":"kubectl",
+		"operation":"apply",
+		"operation_id":"op-chain",
+		"environment":"production"
+	}`))
+	req.Header.Set("Authorization", "Bearer route-secret")
+	req.Header.Set("X-Evidra-API-Key", "tenant-api-key")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	wantWriter := "key:" + evidence.KeyID(signer.PublicKey())
+	if len(store.writers) != 2 || store.writers[0] != wantWriter || store.writers[1] != wantWriter {
+		t.Fatalf("chain head lookups = %v, want two for %s", store.writers, wantWriter)
+	}
+	var entry evidence.EvidenceEntry
+	if err := json.Unmarshal(store.savedRaw[0], &entry); err != nil {
+		t.Fatalf("decode entry: %v", err)
+	}
+	if entry.PreviousHash != "sha256:concurrent" {
+		t.Fatalf("previous_hash = %q, want the head after the fork retry", entry.PreviousHash)
+	}
+}
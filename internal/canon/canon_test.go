@@ -460,6 +460,28 @@ func TestGenericAdapter(t *testing.T) {
 	}
 }
 
+// --- ClassifyOperation Tests ---
+
+func TestClassifyOperation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tool, operation, want string
+	}{
+		{"kubectl", "apply", "mutate"},
+		{"oc", "delete", "destroy"},
+		{"helm", "uninstall", "destroy"},
+		{"terraform", "destroy", "destroy"},
+		{"docker", "run", "mutate"},
+		{"argocd", "sync", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := ClassifyOperation(tt.tool, tt.operation); got != tt.want {
+			t.Errorf("ClassifyOperation(%q, %q) = %q, want %q", tt.tool, tt.operation, got, tt.want)
+		}
+	}
+}
+
 // --- ResolveScopeClass Tests ---
 
 func TestResolveScopeClass_ExplicitEnv(t *testing.T) {
@@ -14,12 +14,13 @@ import (
 	"go.yaml.in/yaml/v3"
 )
 
-// K8sAdapter handles kubectl and oc artifacts.
+// K8sAdapter handles kubectl and oc artifacts, and objects intercepted on
+// the API server path by the admission webhook ("kube-apiserver").
 type K8sAdapter struct{}
 
 func (a *K8sAdapter) Name() string { return "k8s/v1" }
 func (a *K8sAdapter) CanHandle(tool string) bool {
-	return tool == "kubectl" || tool == "oc" || tool == "helm" || tool == "kustomize"
+	return tool == "kubectl" || tool == "oc" || tool == "helm" || tool == "kustomize" || tool == "kube-apiserver"
 }
 func (a *K8sAdapter) Canonicalize(tool, operation, environment string, rawArtifact []byte) (CanonResult, error) {
 	r, err := canonicalizeK8s(tool, operation, environment, rawArtifact)
@@ -132,6 +133,41 @@ func splitYAMLDocuments(data []byte) ([]map[string]interface{}, error) {
 	return objects, nil
 }
 
+// K8sObject is one Kubernetes object with its canonical identity and the
+// noise fields excluded from resource_shape_hash removed.
+type K8sObject struct {
+	Identity ResourceID
+	Object   map[string]interface{}
+}
+
+// K8sObjects splits a YAML stream into noise-stripped objects. List kinds,
+// as printed by `kubectl get -o yaml` for several resources, are unwrapped.
+func K8sObjects(raw []byte) ([]K8sObject, error) {
+	docs, err := splitYAMLDocuments(raw)
+	if err != nil {
+		return nil, fmt.Errorf("canon.k8s: split YAML: %w", err)
+	}
+	var out []K8sObject
+	for _, doc := range docs {
+		objs := []map[string]interface{}{doc}
+		if kind, _ := doc["kind"].(string); strings.HasSuffix(kind, "List") {
+			objs = objs[:0]
+			items, _ := doc["items"].([]interface{})
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					objs = append(objs, obj)
+				}
+			}
+		}
+		for _, obj := range objs {
+			copied := deepCopyMap(obj)
+			removeK8sNoiseFields(copied)
+			out = append(out, K8sObject{Identity: extractK8sIdentity(obj), Object: copied})
+		}
+	}
+	return out, nil
+}
+
 // extractK8sIdentity pulls apiVersion, kind, namespace, name from a K8s object.
 func extractK8sIdentity(obj map[string]interface{}) ResourceID {
 	id := ResourceID{}
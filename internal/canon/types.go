@@ -158,6 +158,24 @@ func terraformOperationClass(op string) string {
 	}
 }
 
+// ClassifyOperation returns the operation class the adapter for tool would
+// assign, for intents that have no artifact yet.
+func ClassifyOperation(tool, operation string) string {
+	switch {
+	case (&K8sAdapter{}).CanHandle(tool):
+		return k8sOperationClass(operation)
+	case (&TerraformAdapter{}).CanHandle(tool):
+		return terraformOperationClass(operation)
+	case dockerCompatibleTools[tool]:
+		if composeTool(tool) {
+			return composeOperationClass(operation)
+		}
+		return dockerOperationClass(operation)
+	default:
+		return "unknown"
+	}
+}
+
 // ResolveScopeClass determines the environment-based scope class.
 // If env is explicitly "production", "staging", or "development", it is returned directly.
 // Otherwise, resource namespaces are scanned for environment hints.
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const requireApprovalEnv = "EVIDRA_REQUIRE_APPROVAL"
+
+// ResolveApprovalRisk returns the lowest effective risk that holds a
+// prescription for human approval, from explicit flag, then env. Empty
+// means no prescription requires approval.
+func ResolveApprovalRisk(explicit string) (string, error) {
+	raw := strings.TrimSpace(explicit)
+	if raw == "" {
+		raw = strings.TrimSpace(os.Getenv(requireApprovalEnv))
+	}
+	switch level := strings.ToLower(raw); level {
+	case "", "off":
+		return "", nil
+	case "low", "medium", "high", "critical":
+		return level, nil
+	default:
+		return "", fmt.Errorf("invalid approval risk %q (expected low|medium|high|critical|off)", raw)
+	}
+}
+
+const approverKeyringEnv = "EVIDRA_APPROVER_KEYRING"
+
+// ResolveApproverKeyringPath returns the path of the approvers' public
+// keys (JWKS or PEM), from explicit flag, then env. Empty means no
+// approver is configured, so no approval entry counts.
+func ResolveApproverKeyringPath(explicit string) string {
+	if path := strings.TrimSpace(explicit); path != "" {
+		return path
+	}
+	return strings.TrimSpace(os.Getenv(approverKeyringEnv))
+}
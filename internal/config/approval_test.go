@@ -0,0 +1,45 @@
+package config
+
+import "testing"
+
+func TestResolveApprovalRisk_DefaultOff(t *testing.T) {
+	t.Setenv("EVIDRA_REQUIRE_APPROVAL", "")
+	level, err := ResolveApprovalRisk("")
+	if err != nil {
+		t.Fatalf("ResolveApprovalRisk: %v", err)
+	}
+	if level != "" {
+		t.Fatalf("level = %q, want empty", level)
+	}
+}
+
+func TestResolveApprovalRisk_ExplicitOverridesEnv(t *testing.T) {
+	t.Setenv("EVIDRA_REQUIRE_APPROVAL", "high")
+	level, err := ResolveApprovalRisk("Critical")
+	if err != nil {
+		t.Fatalf("ResolveApprovalRisk: %v", err)
+	}
+	if level != "critical" {
+		t.Fatalf("level = %q, want critical", level)
+	}
+	if level, _ := ResolveApprovalRisk(""); level != "high" {
+		t.Fatalf("env level = %q, want high", level)
+	}
+}
+
+func TestResolveApprovalRisk_Invalid(t *testing.T) {
+	t.Setenv("EVIDRA_REQUIRE_APPROVAL", "")
+	if _, err := ResolveApprovalRisk("severe"); err == nil {
+		t.Fatal("expected error for invalid level")
+	}
+}
+
+func TestResolveApproverKeyringPath_ExplicitOverridesEnv(t *testing.T) {
+	t.Setenv("EVIDRA_APPROVER_KEYRING", "/etc/evidra/approvers.jwks")
+	if got := ResolveApproverKeyringPath(" /tmp/approvers.pem "); got != "/tmp/approvers.pem" {
+		t.Fatalf("explicit path = %q, want /tmp/approvers.pem", got)
+	}
+	if got := ResolveApproverKeyringPath(""); got != "/etc/evidra/approvers.jwks" {
+		t.Fatalf("env path = %q, want /etc/evidra/approvers.jwks", got)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	signerBackendEnv       = "EVIDRA_SIGNER_BACKEND"
+	pkcs11ModuleEnv        = "EVIDRA_PKCS11_MODULE"
+	pkcs11TokenLabelEnv    = "EVIDRA_PKCS11_TOKEN_LABEL"
+	pkcs11PINEnv           = "EVIDRA_PKCS11_PIN"
+	pkcs11KeyLabelEnv      = "EVIDRA_PKCS11_KEY_LABEL"
+	signerAgentSocketEnv   = "EVIDRA_SIGNER_AGENT_SOCKET"
+	signerAgentKeyEnv      = "EVIDRA_SIGNER_AGENT_KEY"
+	sshAuthSockEnv         = "SSH_AUTH_SOCK"
+	signerRemoteURLEnv     = "EVIDRA_SIGNER_URL"
+	signerRemoteTokenEnv   = "EVIDRA_SIGNER_TOKEN"
+	signerRemoteKeyIDEnv   = "EVIDRA_SIGNER_KEY_ID"
+	signerRemoteTimeoutEnv = "EVIDRA_SIGNER_TIMEOUT"
+)
+
+// SignerBackend selects where the evidence signing key lives.
+type SignerBackend string
+
+const (
+	// SignerBackendLocal uses EVIDRA_SIGNING_KEY / EVIDRA_SIGNING_KEY_PATH.
+	SignerBackendLocal SignerBackend = "local"
+	// SignerBackendPKCS11 uses a key held in a PKCS#11 token (HSM, SoftHSM).
+	SignerBackendPKCS11 SignerBackend = "pkcs11"
+	// SignerBackendAgent uses an Ed25519 key held by an ssh-agent protocol
+	// socket (ssh-agent, gpg-agent with enable-ssh-support).
+	SignerBackendAgent SignerBackend = "agent"
+	// SignerBackendRemote uses a remote signing service over HTTP.
+	SignerBackendRemote SignerBackend = "remote"
+)
+
+// SignerBackendConfig configures a non-local signer backend.
+type SignerBackendConfig struct {
+	Backend SignerBackend
+
+	PKCS11Module     string
+	PKCS11TokenLabel string
+	PKCS11PIN        string
+	PKCS11KeyLabel   string
+
+	AgentSocket string
+	AgentKey    string
+
+	RemoteURL     string
+	RemoteToken   string
+	RemoteKeyID   string
+	RemoteTimeout time.Duration
+}
+
+// ResolveSignerBackendConfig resolves the signer backend from the explicit
+// flag, then env, then default (local), and reads the settings the selected
+// backend requires from env.
+func ResolveSignerBackendConfig(explicitBackend string) (SignerBackendConfig, error) {
+	raw := strings.TrimSpace(explicitBackend)
+	if raw == "" {
+		raw = strings.TrimSpace(os.Getenv(signerBackendEnv))
+	}
+	if raw == "" {
+		return SignerBackendConfig{Backend: SignerBackendLocal}, nil
+	}
+
+	cfg := SignerBackendConfig{}
+	switch strings.ToLower(raw) {
+	case string(SignerBackendLocal):
+		cfg.Backend = SignerBackendLocal
+	case string(SignerBackendPKCS11):
+		cfg.Backend = SignerBackendPKCS11
+		cfg.PKCS11Module = strings.TrimSpace(os.Getenv(pkcs11ModuleEnv))
+		cfg.PKCS11TokenLabel = strings.TrimSpace(os.Getenv(pkcs11TokenLabelEnv))
+		cfg.PKCS11PIN = os.Getenv(pkcs11PINEnv)
+		cfg.PKCS11KeyLabel = strings.TrimSpace(os.Getenv(pkcs11KeyLabelEnv))
+		if cfg.PKCS11Module == "" || cfg.PKCS11KeyLabel == "" {
+			return SignerBackendConfig{}, fmt.Errorf("signer backend pkcs11 requires %s and %s", pkcs11ModuleEnv, pkcs11KeyLabelEnv)
+		}
+	case string(SignerBackendAgent):
+		cfg.Backend = SignerBackendAgent
+		cfg.AgentSocket = strings.TrimSpace(os.Getenv(signerAgentSocketEnv))
+		if cfg.AgentSocket == "" {
+			cfg.AgentSocket = strings.TrimSpace(os.Getenv(sshAuthSockEnv))
+		}
+		cfg.AgentKey = strings.TrimSpace(os.Getenv(signerAgentKeyEnv))
+		if cfg.AgentSocket == "" {
+			return SignerBackendConfig{}, fmt.Errorf("signer backend agent requires %s or %s", signerAgentSocketEnv, sshAuthSockEnv)
+		}
+	case string(SignerBackendRemote):
+		cfg.Backend = SignerBackendRemote
+		cfg.RemoteURL = strings.TrimSpace(os.Getenv(signerRemoteURLEnv))
+		cfg.RemoteToken = strings.TrimSpace(os.Getenv(signerRemoteTokenEnv))
+		cfg.RemoteKeyID = strings.TrimSpace(os.Getenv(signerRemoteKeyIDEnv))
+		if cfg.RemoteURL == "" {
+			return SignerBackendConfig{}, fmt.Errorf("signer backend remote requires %s", signerRemoteURLEnv)
+		}
+		cfg.RemoteTimeout = 10 * time.Second
+		if timeoutRaw := strings.TrimSpace(os.Getenv(signerRemoteTimeoutEnv)); timeoutRaw != "" {
+			parsed, err := time.ParseDuration(timeoutRaw)
+			if err != nil || parsed <= 0 {
+				return SignerBackendConfig{}, fmt.Errorf("invalid signer timeout %q", timeoutRaw)
+			}
+			cfg.RemoteTimeout = parsed
+		}
+	default:
+		return SignerBackendConfig{}, fmt.Errorf("invalid signer backend %q (expected local|pkcs11|agent|remote)", raw)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func clearSignerBackendEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		signerBackendEnv, pkcs11ModuleEnv, pkcs11TokenLabelEnv, pkcs11PINEnv, pkcs11KeyLabelEnv,
+		signerAgentSocketEnv, signerAgentKeyEnv, sshAuthSockEnv,
+		signerRemoteURLEnv, signerRemoteTokenEnv, signerRemoteKeyIDEnv, signerRemoteTimeoutEnv,
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestResolveSignerBackendConfig_DefaultLocal(t *testing.T) {
+	clearSignerBackendEnv(t)
+	cfg, err := ResolveSignerBackendConfig("")
+	if err != nil {
+		t.Fatalf("ResolveSignerBackendConfig: %v", err)
+	}
+	if cfg.Backend != SignerBackendLocal {
+		t.Fatalf("backend = %q, want %q", cfg.Backend, SignerBackendLocal)
+	}
+}
+
+func TestResolveSignerBackendConfig_ExplicitOverridesEnv(t *testing.T) {
+	clearSignerBackendEnv(t)
+	t.Setenv(signerBackendEnv, "remote")
+	cfg, err := ResolveSignerBackendConfig("local")
+	if err != nil {
+		t.Fatalf("ResolveSignerBackendConfig: %v", err)
+	}
+	if cfg.Backend != SignerBackendLocal {
+		t.Fatalf("backend = %q, want %q", cfg.Backend, SignerBackendLocal)
+	}
+}
+
+func TestResolveSignerBackendConfig_Backends(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    SignerBackendConfig
+		wantErr bool
+	}{
+		{
+			name: "pkcs11",
+			env: map[string]string{
+				signerBackendEnv:    "PKCS11",
+				pkcs11ModuleEnv:     "/usr/lib/softhsm/libsofthsm2.so",
+				pkcs11TokenLabelEnv: "evidra",
+				pkcs11PINEnv:        "1234",
+				pkcs11KeyLabelEnv:   "evidence",
+			},
+			want: SignerBackendConfig{
+				Backend:          SignerBackendPKCS11,
+				PKCS11Module:     "/usr/lib/softhsm/libsofthsm2.so",
+				PKCS11TokenLabel: "evidra",
+				PKCS11PIN:        "1234",
+				PKCS11KeyLabel:   "evidence",
+			},
+		},
+		{
+			name:    "pkcs11 missing key label",
+			env:     map[string]string{signerBackendEnv: "pkcs11", pkcs11ModuleEnv: "/lib.so"},
+			wantErr: true,
+		},
+		{
+			name: "agent falls back to SSH_AUTH_SOCK",
+			env:  map[string]string{signerBackendEnv: "agent", sshAuthSockEnv: "/tmp/agent.sock", signerAgentKeyEnv: "ci"},
+			want: SignerBackendConfig{Backend: SignerBackendAgent, AgentSocket: "/tmp/agent.sock", AgentKey: "ci"},
+		},
+		{
+			name:    "agent without socket",
+			env:     map[string]string{signerBackendEnv: "agent"},
+			wantErr: true,
+		},
+		{
+			name: "remote with defaults",
+			env:  map[string]string{signerBackendEnv: "remote", signerRemoteURLEnv: "http://signer", signerRemoteTokenEnv: "tok"},
+			want: SignerBackendConfig{Backend: SignerBackendRemote, RemoteURL: "http://signer", RemoteToken: "tok", RemoteTimeout: 10 * time.Second},
+		},
+		{
+			name: "remote with timeout",
+			env:  map[string]string{signerBackendEnv: "remote", signerRemoteURLEnv: "http://signer", signerRemoteKeyIDEnv: "kid", signerRemoteTimeoutEnv: "2s"},
+			want: SignerBackendConfig{Backend: SignerBackendRemote, RemoteURL: "http://signer", RemoteKeyID: "kid", RemoteTimeout: 2 * time.Second},
+		},
+		{
+			name:    "remote invalid timeout",
+			env:     map[string]string{signerBackendEnv: "remote", signerRemoteURLEnv: "http://signer", signerRemoteTimeoutEnv: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "remote without url",
+			env:     map[string]string{signerBackendEnv: "remote"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown backend",
+			env:     map[string]string{signerBackendEnv: "kms"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearSignerBackendEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			got, err := ResolveSignerBackendConfig("")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSignerBackendConfig: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
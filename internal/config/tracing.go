@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	tracesOTLPEndpointEnv = "EVIDRA_TRACES_OTLP_ENDPOINT"
+	tracesTimeoutEnv      = "EVIDRA_TRACES_TIMEOUT"
+	traceParentEnv        = "TRACEPARENT"
+)
+
+// TracingConfig configures OTLP span export. An empty OTLPEndpoint
+// disables tracing.
+type TracingConfig struct {
+	OTLPEndpoint string
+	Timeout      time.Duration
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+}
+
+// Enabled reports whether spans should be exported.
+func (c TracingConfig) Enabled() bool {
+	return c.OTLPEndpoint != ""
+}
+
+// ResolveTracingConfig resolves tracing config from explicit values, then env, then defaults.
+func ResolveTracingConfig(explicitEndpoint, explicitTimeout string) (TracingConfig, error) {
+	endpoint := strings.TrimSpace(explicitEndpoint)
+	if endpoint == "" {
+		endpoint = strings.TrimSpace(os.Getenv(tracesOTLPEndpointEnv))
+	}
+
+	timeoutRaw := strings.TrimSpace(explicitTimeout)
+	if timeoutRaw == "" {
+		timeoutRaw = strings.TrimSpace(os.Getenv(tracesTimeoutEnv))
+	}
+	timeout := 3 * time.Second
+	if timeoutRaw != "" {
+		parsed, err := time.ParseDuration(timeoutRaw)
+		if err != nil {
+			return TracingConfig{}, fmt.Errorf("invalid traces timeout %q: %w", timeoutRaw, err)
+		}
+		if parsed <= 0 {
+			return TracingConfig{}, fmt.Errorf("traces timeout must be > 0")
+		}
+		timeout = parsed
+	}
+
+	return TracingConfig{
+		OTLPEndpoint: endpoint,
+		Timeout:      timeout,
+	}, nil
+}
+
+// ResolveTraceParent returns the caller's W3C traceparent: the explicit
+// value, else TRACEPARENT from the environment.
+func ResolveTraceParent(explicit string) string {
+	if v := strings.TrimSpace(explicit); v != "" {
+		return v
+	}
+	return strings.TrimSpace(os.Getenv(traceParentEnv))
+}
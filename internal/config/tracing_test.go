@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveTracingConfigDisabledByDefault(t *testing.T) {
+	t.Setenv(tracesOTLPEndpointEnv, "")
+	t.Setenv(tracesTimeoutEnv, "")
+
+	cfg, err := ResolveTracingConfig("", "")
+	if err != nil {
+		t.Fatalf("ResolveTracingConfig: %v", err)
+	}
+	if cfg.Enabled() {
+		t.Fatalf("cfg=%+v want disabled", cfg)
+	}
+	if cfg.Timeout != 3*time.Second {
+		t.Fatalf("timeout=%s want 3s", cfg.Timeout)
+	}
+}
+
+func TestResolveTracingConfigFromEnv(t *testing.T) {
+	t.Setenv(tracesOTLPEndpointEnv, "http://127.0.0.1:4318/v1/traces")
+	t.Setenv(tracesTimeoutEnv, "0s")
+
+	if _, err := ResolveTracingConfig("", ""); err == nil {
+		t.Fatal("expected error for zero timeout")
+	}
+	cfg, err := ResolveTracingConfig("", "5s")
+	if err != nil {
+		t.Fatalf("ResolveTracingConfig: %v", err)
+	}
+	if !cfg.Enabled() || cfg.OTLPEndpoint != "http://127.0.0.1:4318/v1/traces" || cfg.Timeout != 5*time.Second {
+		t.Fatalf("cfg=%+v", cfg)
+	}
+}
+
+func TestResolveTraceParent(t *testing.T) {
+	t.Setenv(traceParentEnv, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	if got := ResolveTraceParent(""); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("from env = %q", got)
+	}
+	if got := ResolveTraceParent(" explicit "); got != "explicit" {
+		t.Fatalf("explicit = %q", got)
+	}
+}
@@ -23,6 +23,11 @@ func TestMigrationsEmbedded(t *testing.T) {
 		"002_evidence_entries.up.sql",
 		"003_benchmark_runs.up.sql",
 		"004_webhook_events.up.sql",
+		"005_ingestion_verification.up.sql",
+		"006_evidence_chains.up.sql",
+		"007_evidence_stream.up.sql",
+		"008_notifications.up.sql",
+		"009_entry_listing.up.sql",
 	} {
 		if !found[want] {
 			t.Fatalf("missing embedded migration %s", want)
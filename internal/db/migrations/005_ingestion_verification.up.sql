@@ -0,0 +1,28 @@
+-- 005_ingestion_verification.sql
+CREATE TABLE IF NOT EXISTS signing_keys (
+    tenant_id   TEXT NOT NULL REFERENCES tenants(id),
+    key_id      TEXT NOT NULL,
+    public_key  BYTEA NOT NULL,
+    label       TEXT NOT NULL DEFAULT '',
+    source      TEXT NOT NULL DEFAULT 'api',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at  TIMESTAMPTZ,
+    PRIMARY KEY (tenant_id, key_id)
+);
+
+-- Entries that failed ingestion verification, kept byte-for-byte for review.
+CREATE TABLE IF NOT EXISTS quarantined_entries (
+    tenant_id    TEXT NOT NULL REFERENCES tenants(id),
+    digest       TEXT NOT NULL,
+    entry_id     TEXT NOT NULL DEFAULT '',
+    hash         TEXT NOT NULL DEFAULT '',
+    reason_code  TEXT NOT NULL,
+    reason       TEXT NOT NULL DEFAULT '',
+    raw          BYTEA NOT NULL,
+    received_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tenant_id, digest)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantine_hash ON quarantined_entries(tenant_id, hash);
+CREATE INDEX IF NOT EXISTS idx_quarantine_received ON quarantined_entries(tenant_id, received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_entries_hash ON evidence_entries(tenant_id, hash);
@@ -0,0 +1,73 @@
+-- 006_evidence_chains.sql
+-- One chain per writer (signing key or actor instance) instead of one chain
+-- per tenant. Each stored entry records the chain it extends and its position.
+ALTER TABLE evidence_entries
+    ADD COLUMN IF NOT EXISTS chain_id  TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS chain_seq BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS raw       BYTEA;
+
+CREATE TABLE IF NOT EXISTS evidence_chains (
+    tenant_id             TEXT NOT NULL REFERENCES tenants(id),
+    chain_id              TEXT NOT NULL,
+    writer                TEXT NOT NULL DEFAULT '',
+    key_id                TEXT NOT NULL DEFAULT '',
+    actor_type            TEXT NOT NULL DEFAULT '',
+    actor_id              TEXT NOT NULL DEFAULT '',
+    instance_id           TEXT NOT NULL DEFAULT '',
+    genesis_hash          TEXT NOT NULL,
+    -- Non-empty when the first stored entry links to an entry the server
+    -- never received (stored without ingestion verification).
+    genesis_previous_hash TEXT NOT NULL DEFAULT '',
+    head_hash             TEXT NOT NULL,
+    head_entry_id         TEXT NOT NULL,
+    length                BIGINT NOT NULL DEFAULT 0,
+    first_seen            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen             TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- NULL until the chain is validated server-side.
+    valid                 BOOLEAN,
+    validation_error      TEXT NOT NULL DEFAULT '',
+    validated_at          TIMESTAMPTZ,
+    PRIMARY KEY (tenant_id, chain_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chains_head ON evidence_chains(tenant_id, head_hash);
+CREATE INDEX IF NOT EXISTS idx_chains_writer ON evidence_chains(tenant_id, writer, last_seen DESC);
+CREATE INDEX IF NOT EXISTS idx_entries_chain ON evidence_entries(tenant_id, chain_id, chain_seq);
+
+-- Backfill: walk every chain forward from its genesis entry.
+WITH RECURSIVE walk AS (
+    SELECT e.tenant_id, e.id, e.hash, e.id AS chain_id, 1::BIGINT AS seq
+    FROM evidence_entries e
+    WHERE e.previous_hash = '' AND e.hash <> ''
+  UNION ALL
+    SELECT e.tenant_id, e.id, e.hash, w.chain_id, w.seq + 1
+    FROM evidence_entries e
+    JOIN walk w ON e.tenant_id = w.tenant_id AND e.previous_hash = w.hash
+    WHERE e.hash <> ''
+)
+UPDATE evidence_entries e
+SET chain_id = w.chain_id, chain_seq = w.seq
+FROM walk w
+WHERE e.id = w.id AND e.chain_id = '';
+
+INSERT INTO evidence_chains
+    (tenant_id, chain_id, writer, key_id, actor_type, actor_id, instance_id,
+     genesis_hash, head_hash, head_entry_id, length, first_seen, last_seen)
+SELECT DISTINCT ON (e.tenant_id, e.chain_id)
+       e.tenant_id, e.chain_id,
+       CASE WHEN COALESCE(g.payload->>'key_id', '') <> ''
+            THEN 'key:' || (g.payload->>'key_id')
+            ELSE 'actor:' || COALESCE(g.payload->'actor'->>'type', '') || '/' || COALESCE(g.payload->'actor'->>'id', '')
+                 || CASE WHEN COALESCE(g.payload->'actor'->>'instance_id', '') <> ''
+                         THEN '/' || (g.payload->'actor'->>'instance_id') ELSE '' END
+       END,
+       COALESCE(e.payload->>'key_id', ''),
+       COALESCE(g.payload->'actor'->>'type', ''),
+       COALESCE(g.payload->'actor'->>'id', ''),
+       COALESCE(g.payload->'actor'->>'instance_id', ''),
+       g.hash, e.hash, e.id, e.chain_seq, g.created_at, e.created_at
+FROM evidence_entries e
+JOIN evidence_entries g ON g.id = e.chain_id
+WHERE e.chain_id <> ''
+ORDER BY e.tenant_id, e.chain_id, e.chain_seq DESC, e.created_at DESC
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+-- 007_evidence_stream.sql
+-- Per-tenant stream position for GET /v1/evidence/stream. SaveRaw holds a
+-- per-tenant advisory lock while inserting, so positions are assigned in
+-- commit order within a tenant and a resumed stream never skips an entry.
+ALTER TABLE evidence_entries
+    ADD COLUMN IF NOT EXISTS stream_seq BIGSERIAL;
+
+CREATE INDEX IF NOT EXISTS idx_entries_stream ON evidence_entries(tenant_id, stream_seq);
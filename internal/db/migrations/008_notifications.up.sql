@@ -0,0 +1,49 @@
+-- 008_notifications.sql
+-- Outbound notification rules and their delivery log.
+CREATE TABLE IF NOT EXISTS notification_rules (
+    id          TEXT PRIMARY KEY,
+    tenant_id   TEXT NOT NULL REFERENCES tenants(id),
+    name        TEXT NOT NULL DEFAULT '',
+    target      TEXT NOT NULL,
+    url         TEXT NOT NULL,
+    conditions  JSONB NOT NULL DEFAULT '{}',
+    enabled     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_rules_tenant ON notification_rules(tenant_id);
+
+-- Stream position up to which a tenant's entries have been matched against
+-- its rules.
+CREATE TABLE IF NOT EXISTS notification_cursors (
+    tenant_id   TEXT PRIMARY KEY REFERENCES tenants(id),
+    stream_seq  BIGINT NOT NULL DEFAULT 0,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One row per (rule, event). The body is rendered once so every attempt
+-- sends the same bytes.
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id               TEXT PRIMARY KEY,
+    tenant_id        TEXT NOT NULL REFERENCES tenants(id),
+    rule_id          TEXT NOT NULL REFERENCES notification_rules(id) ON DELETE CASCADE,
+    event_key        TEXT NOT NULL,
+    entry_id         TEXT NOT NULL,
+    signal           TEXT NOT NULL DEFAULT '',
+    target           TEXT NOT NULL,
+    url              TEXT NOT NULL,
+    body             BYTEA NOT NULL,
+    status           TEXT NOT NULL DEFAULT 'pending',
+    attempts         INT NOT NULL DEFAULT 0,
+    last_status_code INT,
+    last_error       TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at     TIMESTAMPTZ,
+    UNIQUE (rule_id, event_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due
+    ON notification_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_rule
+    ON notification_deliveries(tenant_id, rule_id, created_at DESC);
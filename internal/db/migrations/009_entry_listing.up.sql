@@ -0,0 +1,8 @@
+-- 009_entry_listing.sql
+-- GET /v1/evidence/entries pages by (created_at, id) and filters on fields of
+-- the stored entry JSON.
+CREATE INDEX IF NOT EXISTS idx_entries_page ON evidence_entries(tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_entries_actor ON evidence_entries(tenant_id, (payload->'actor'->>'id'));
+CREATE INDEX IF NOT EXISTS idx_entries_artifact ON evidence_entries(tenant_id, artifact_digest);
+CREATE INDEX IF NOT EXISTS idx_entries_intent ON evidence_entries(tenant_id, intent_digest);
+CREATE INDEX IF NOT EXISTS idx_entries_scope_dims ON evidence_entries USING GIN ((payload->'scope_dimensions') jsonb_path_ops);
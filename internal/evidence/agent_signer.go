@@ -0,0 +1,102 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// AgentConfig selects an Ed25519 key held by an ssh-agent protocol socket.
+// gpg-agent works when started with enable-ssh-support.
+type AgentConfig struct {
+	// Socket is the agent's unix socket path (usually $SSH_AUTH_SOCK).
+	Socket string
+	// Key selects the key by comment or SHA256 fingerprint. Empty selects
+	// the first Ed25519 key the agent offers.
+	Key string
+}
+
+type agentBackend struct {
+	cfg AgentConfig
+	key *agent.Key
+}
+
+func newAgentBackend(cfg AgentConfig) (*agentBackend, error) {
+	if cfg.Socket == "" {
+		return nil, fmt.Errorf("agent socket is required")
+	}
+	b := &agentBackend{cfg: cfg}
+	err := b.withClient(func(client agent.ExtendedAgent) error {
+		keys, err := client.List()
+		if err != nil {
+			return fmt.Errorf("list agent keys: %w", err)
+		}
+		for _, k := range keys {
+			if k.Format != ssh.KeyAlgoED25519 {
+				continue
+			}
+			if cfg.Key == "" || cfg.Key == k.Comment || cfg.Key == ssh.FingerprintSHA256(k) {
+				b.key = k
+				return nil
+			}
+		}
+		if cfg.Key != "" {
+			return fmt.Errorf("no Ed25519 key matching %q in agent", cfg.Key)
+		}
+		return fmt.Errorf("agent holds no Ed25519 keys")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// withClient dials the agent per operation so a restarted agent is picked up
+// without restarting the process.
+func (b *agentBackend) withClient(fn func(agent.ExtendedAgent) error) error {
+	conn, err := net.Dial("unix", b.cfg.Socket)
+	if err != nil {
+		return fmt.Errorf("connect to agent: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	return fn(agent.NewClient(conn))
+}
+
+func (b *agentBackend) publicKey() (ed25519.PublicKey, error) {
+	parsed, err := ssh.ParsePublicKey(b.key.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("parse agent key: %w", err)
+	}
+	cryptoKey, ok := parsed.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("agent key does not expose a crypto public key")
+	}
+	pub, ok := cryptoKey.CryptoPublicKey().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("agent key is not Ed25519")
+	}
+	return pub, nil
+}
+
+func (b *agentBackend) sign(payload []byte) ([]byte, error) {
+	var out []byte
+	err := b.withClient(func(client agent.ExtendedAgent) error {
+		sig, err := client.Sign(b.key, payload)
+		if err != nil {
+			return fmt.Errorf("agent sign: %w", err)
+		}
+		// ssh-ed25519 signatures are plain Ed25519 over the message.
+		if !strings.EqualFold(sig.Format, ssh.KeyAlgoED25519) {
+			return fmt.Errorf("unexpected agent signature format %q", sig.Format)
+		}
+		out = sig.Blob
+		return nil
+	})
+	return out, err
+}
+
+func (b *agentBackend) close() error { return nil }
@@ -0,0 +1,120 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"samebits.com/evidra/internal/config"
+)
+
+// ErrBackendSignatureInvalid is returned when a signer backend produces a
+// signature that does not verify under the key it advertised.
+var ErrBackendSignatureInvalid = errors.New("evidence.BackendSigner: backend returned an invalid signature")
+
+// signingBackend produces Ed25519 signatures with a key held outside the
+// process.
+type signingBackend interface {
+	publicKey() (ed25519.PublicKey, error)
+	sign(payload []byte) ([]byte, error)
+	close() error
+}
+
+// BackendSigner adapts an external key store (PKCS#11 token, agent socket,
+// remote signing service) to the evidence Signer interface. It implements
+// pkg/evidence.FallibleSigner so BuildEntry fails loudly when the backend is
+// unavailable.
+type BackendSigner struct {
+	name    string
+	backend signingBackend
+	pub     ed25519.PublicKey
+}
+
+// NewBackendSigner connects to the backend selected by cfg. It returns an
+// error for config.SignerBackendLocal, which is handled by NewSigner.
+func NewBackendSigner(cfg config.SignerBackendConfig) (*BackendSigner, error) {
+	var (
+		backend signingBackend
+		err     error
+	)
+	switch cfg.Backend {
+	case config.SignerBackendPKCS11:
+		backend, err = newPKCS11Backend(PKCS11Config{
+			Module:     cfg.PKCS11Module,
+			TokenLabel: cfg.PKCS11TokenLabel,
+			PIN:        cfg.PKCS11PIN,
+			KeyLabel:   cfg.PKCS11KeyLabel,
+		})
+	case config.SignerBackendAgent:
+		backend, err = newAgentBackend(AgentConfig{
+			Socket: cfg.AgentSocket,
+			Key:    cfg.AgentKey,
+		})
+	case config.SignerBackendRemote:
+		backend, err = newRemoteBackend(RemoteConfig{
+			URL:     cfg.RemoteURL,
+			Token:   cfg.RemoteToken,
+			KeyID:   cfg.RemoteKeyID,
+			Timeout: cfg.RemoteTimeout,
+		})
+	default:
+		return nil, fmt.Errorf("evidence.NewBackendSigner: unsupported backend %q", cfg.Backend)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("evidence.NewBackendSigner: %s: %w", cfg.Backend, err)
+	}
+	return newBackendSigner(string(cfg.Backend), backend)
+}
+
+func newBackendSigner(name string, backend signingBackend) (*BackendSigner, error) {
+	pub, err := backend.publicKey()
+	if err != nil {
+		_ = backend.close()
+		return nil, fmt.Errorf("evidence.NewBackendSigner: %s: public key: %w", name, err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		_ = backend.close()
+		return nil, fmt.Errorf("evidence.NewBackendSigner: %s: public key is not Ed25519", name)
+	}
+	return &BackendSigner{name: name, backend: backend, pub: pub}, nil
+}
+
+// TrySign signs payload with the backend key and checks the result against
+// the advertised public key.
+func (s *BackendSigner) TrySign(payload []byte) ([]byte, error) {
+	sig, err := s.backend.sign(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s signer: %w", s.name, err)
+	}
+	if !ed25519.Verify(s.pub, payload, sig) {
+		return nil, ErrBackendSignatureInvalid
+	}
+	return sig, nil
+}
+
+// Sign implements pkg/evidence.Signer. Failures are logged and yield a nil
+// signature; callers that can handle errors should use TrySign.
+func (s *BackendSigner) Sign(payload []byte) []byte {
+	sig, err := s.TrySign(payload)
+	if err != nil {
+		slog.Error("evidence signing failed", "backend", s.name, "error", err)
+		return nil
+	}
+	return sig
+}
+
+// Verify checks an Ed25519 signature against the backend public key.
+func (s *BackendSigner) Verify(payload, sig []byte) bool {
+	return ed25519.Verify(s.pub, payload, sig)
+}
+
+// PublicKey returns the backend's Ed25519 public key.
+func (s *BackendSigner) PublicKey() ed25519.PublicKey {
+	return s.pub
+}
+
+// Close releases backend resources (PKCS#11 sessions, connections).
+func (s *BackendSigner) Close() error {
+	return s.backend.close()
+}
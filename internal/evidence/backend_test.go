@@ -0,0 +1,195 @@
+package evidence_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"samebits.com/evidra/internal/config"
+	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/internal/evidence/signertest"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func generateKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return pub, priv
+}
+
+func assertSignerRoundTrip(t *testing.T, s *ievsigner.BackendSigner, want ed25519.PublicKey) {
+	t.Helper()
+	if !s.PublicKey().Equal(want) {
+		t.Fatal("backend public key does not match")
+	}
+	payload := []byte("sha256:abc")
+	sig, err := s.TrySign(payload)
+	if err != nil {
+		t.Fatalf("TrySign: %v", err)
+	}
+	if !ed25519.Verify(want, payload, sig) {
+		t.Fatal("signature does not verify")
+	}
+	if !s.Verify(payload, s.Sign(payload)) {
+		t.Fatal("Sign/Verify round trip failed")
+	}
+}
+
+func TestBackendSigner_Remote(t *testing.T) {
+	t.Parallel()
+	pub, priv := generateKey(t)
+	remote := signertest.NewRemoteServer(priv, "s3cret")
+	srv := httptest.NewServer(remote)
+	t.Cleanup(srv.Close)
+
+	s, err := ievsigner.NewBackendSigner(config.SignerBackendConfig{
+		Backend:       config.SignerBackendRemote,
+		RemoteURL:     srv.URL + "/",
+		RemoteToken:   "s3cret",
+		RemoteTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewBackendSigner: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	assertSignerRoundTrip(t, s, pub)
+	if remote.Signs() != 2 {
+		t.Fatalf("remote signs = %d, want 2", remote.Signs())
+	}
+
+	remote.SetFailing(true)
+	if _, err := s.TrySign([]byte("x")); err == nil {
+		t.Fatal("expected error from failing remote signer")
+	}
+	if sig := s.Sign([]byte("x")); sig != nil {
+		t.Fatal("Sign should return nil on backend failure")
+	}
+}
+
+func TestBackendSigner_RemoteErrors(t *testing.T) {
+	t.Parallel()
+	_, priv := generateKey(t)
+	remote := signertest.NewRemoteServer(priv, "s3cret")
+	srv := httptest.NewServer(remote)
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name string
+		cfg  config.SignerBackendConfig
+	}{
+		{"bad token", config.SignerBackendConfig{Backend: config.SignerBackendRemote, RemoteURL: srv.URL, RemoteToken: "wrong"}},
+		{"unknown key id", config.SignerBackendConfig{Backend: config.SignerBackendRemote, RemoteURL: srv.URL, RemoteToken: "s3cret", RemoteKeyID: "nope"}},
+		// Unescaped, "#" would start a fragment and drop the key_id.
+		{"key id needing escaping", config.SignerBackendConfig{Backend: config.SignerBackendRemote, RemoteURL: srv.URL, RemoteToken: "s3cret", RemoteKeyID: "#nope"}},
+		{"missing url", config.SignerBackendConfig{Backend: config.SignerBackendRemote}},
+		{"unsupported backend", config.SignerBackendConfig{Backend: config.SignerBackendLocal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := ievsigner.NewBackendSigner(tt.cfg); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestBackendSigner_Agent(t *testing.T) {
+	t.Parallel()
+	_, otherPriv := generateKey(t)
+	pub, priv := generateKey(t)
+	socket := signertest.StartAgent(t, priv, "evidra-ci")
+
+	s, err := ievsigner.NewBackendSigner(config.SignerBackendConfig{
+		Backend:     config.SignerBackendAgent,
+		AgentSocket: socket,
+		AgentKey:    "evidra-ci",
+	})
+	if err != nil {
+		t.Fatalf("NewBackendSigner: %v", err)
+	}
+	assertSignerRoundTrip(t, s, pub)
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("ssh.NewPublicKey: %v", err)
+	}
+	byFingerprint, err := ievsigner.NewBackendSigner(config.SignerBackendConfig{
+		Backend:     config.SignerBackendAgent,
+		AgentSocket: socket,
+		AgentKey:    ssh.FingerprintSHA256(sshPub),
+	})
+	if err != nil {
+		t.Fatalf("NewBackendSigner by fingerprint: %v", err)
+	}
+	if !byFingerprint.PublicKey().Equal(pub) {
+		t.Fatal("fingerprint selected the wrong key")
+	}
+
+	otherSocket := signertest.StartAgent(t, otherPriv, "other")
+	if _, err := ievsigner.NewBackendSigner(config.SignerBackendConfig{
+		Backend:     config.SignerBackendAgent,
+		AgentSocket: otherSocket,
+		AgentKey:    "evidra-ci",
+	}); err == nil {
+		t.Fatal("expected error for missing agent key")
+	}
+}
+
+func TestBackendSigner_PKCS11UnavailableWithoutTag(t *testing.T) {
+	t.Parallel()
+	_, err := ievsigner.NewBackendSigner(config.SignerBackendConfig{
+		Backend:        config.SignerBackendPKCS11,
+		PKCS11Module:   "/nonexistent/libsofthsm2.so",
+		PKCS11KeyLabel: "evidence",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestBackendSigner_BuildEntryFailsWhenBackendDown(t *testing.T) {
+	t.Parallel()
+	_, priv := generateKey(t)
+	remote := signertest.NewRemoteServer(priv, "")
+	srv := httptest.NewServer(remote)
+	t.Cleanup(srv.Close)
+
+	s, err := ievsigner.NewBackendSigner(config.SignerBackendConfig{
+		Backend:   config.SignerBackendRemote,
+		RemoteURL: srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewBackendSigner: %v", err)
+	}
+	params := evidence.EntryBuildParams{
+		Type:      evidence.EntryTypeSignal,
+		SessionID: "s",
+		TraceID:   "t",
+		Actor:     evidence.Actor{Type: "cli", ID: "test", Provenance: "cli"},
+		Payload:   []byte(`{"signal_name":"retry_loop"}`),
+		Signer:    s,
+	}
+	entry, err := evidence.BuildEntry(params)
+	if err != nil {
+		t.Fatalf("BuildEntry: %v", err)
+	}
+	if entry.KeyID != remote.KeyID() {
+		t.Fatalf("key_id = %q, want %q", entry.KeyID, remote.KeyID())
+	}
+
+	remote.SetFailing(true)
+	if _, err := evidence.BuildEntry(params); err == nil {
+		t.Fatal("expected BuildEntry to fail when the signer backend is down")
+	} else if errors.Is(err, ievsigner.ErrBackendSignatureInvalid) {
+		t.Fatalf("unexpected error kind: %v", err)
+	}
+}
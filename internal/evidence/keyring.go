@@ -0,0 +1,55 @@
+package evidence
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	pkevidence "samebits.com/evidra/pkg/evidence"
+)
+
+// LoadKeyring reads a keyring file. Two formats are accepted: a JSON Web Key
+// Set (as served by GET /v1/evidence/pubkey) or one or more concatenated
+// PEM "PUBLIC KEY" blocks.
+func LoadKeyring(path string) (*pkevidence.Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("evidence.LoadKeyring: read file: %w", err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		keyring, err := pkevidence.ParseJWKS(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("evidence.LoadKeyring: %w", err)
+		}
+		return keyring, nil
+	}
+
+	keyring := pkevidence.NewKeyring()
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("evidence.LoadKeyring: unexpected PEM block %q", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("evidence.LoadKeyring: parse PKIX: %w", err)
+		}
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("evidence.LoadKeyring: key is not Ed25519")
+		}
+		keyring.Add(pub)
+	}
+	if keyring.Len() == 0 {
+		return nil, fmt.Errorf("evidence.LoadKeyring: no keys found in %s", path)
+	}
+	return keyring, nil
+}
@@ -0,0 +1,83 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pkevidence "samebits.com/evidra/pkg/evidence"
+)
+
+func TestLoadKeyring_ConcatenatedPEM(t *testing.T) {
+	t.Parallel()
+	a, b := testSigner(t), testSigner(t)
+	pemA, err := a.PublicKeyPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemB, err := b.PublicKeyPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keyring.pem")
+	if err := os.WriteFile(path, append(pemA, pemB...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	if keyring.Len() != 2 {
+		t.Fatalf("keyring has %d keys, want 2", keyring.Len())
+	}
+	if _, ok := keyring.Lookup(pkevidence.KeyID(b.PublicKey())); !ok {
+		t.Fatal("expected second key in keyring")
+	}
+}
+
+func TestLoadKeyring_JWKS(t *testing.T) {
+	t.Parallel()
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	data, err := json.Marshal(pkevidence.NewKeyring(pub).JWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	if _, ok := keyring.Lookup(pkevidence.KeyID(pub)); !ok {
+		t.Fatal("expected key in keyring")
+	}
+}
+
+func TestLoadKeyring_Rejects(t *testing.T) {
+	t.Parallel()
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("no keys here"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyring(empty); err == nil {
+		t.Fatal("expected error for file without keys")
+	}
+
+	private := filepath.Join(dir, "priv.pem")
+	if err := os.WriteFile(private, marshalPrivateKeyPEM(t, priv), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyring(private); err == nil {
+		t.Fatal("expected error for private key block")
+	}
+}
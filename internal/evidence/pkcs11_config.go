@@ -0,0 +1,14 @@
+package evidence
+
+// PKCS11Config selects an Ed25519 key pair in a PKCS#11 token.
+type PKCS11Config struct {
+	// Module is the path to the PKCS#11 shared library
+	// (e.g. /usr/lib/softhsm/libsofthsm2.so).
+	Module string
+	// TokenLabel selects the token. Empty selects the first token present.
+	TokenLabel string
+	// PIN is the user PIN. Empty skips login.
+	PIN string
+	// KeyLabel is the CKA_LABEL shared by the private and public key objects.
+	KeyLabel string
+}
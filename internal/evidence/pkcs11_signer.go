@@ -0,0 +1,163 @@
+//go:build pkcs11 && cgo
+
+package evidence
+
+import (
+	"crypto/ed25519"
+	"encoding/asn1"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS#11 v3.0 identifiers not exported by miekg/pkcs11.
+const (
+	ckmEdDSA       = 0x1057
+	ckkECEdwards   = 0x40
+	maxFindObjects = 2
+)
+
+type pkcs11Backend struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	privKey pkcs11.ObjectHandle
+	pubKey  pkcs11.ObjectHandle
+}
+
+func newPKCS11Backend(cfg PKCS11Config) (signingBackend, error) {
+	if cfg.Module == "" || cfg.KeyLabel == "" {
+		return nil, fmt.Errorf("pkcs11 module and key label are required")
+	}
+	ctx := pkcs11.New(cfg.Module)
+	if ctx == nil {
+		return nil, fmt.Errorf("load pkcs11 module %s", cfg.Module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("initialize pkcs11 module: %w", err)
+	}
+	b := &pkcs11Backend{ctx: ctx}
+	if err := b.open(cfg); err != nil {
+		_ = b.close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *pkcs11Backend) open(cfg PKCS11Config) error {
+	slot, err := b.findSlot(cfg.TokenLabel)
+	if err != nil {
+		return err
+	}
+	session, err := b.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return fmt.Errorf("open session: %w", err)
+	}
+	b.session = session
+	if cfg.PIN != "" {
+		if err := b.ctx.Login(session, pkcs11.CKU_USER, cfg.PIN); err != nil {
+			return fmt.Errorf("login: %w", err)
+		}
+	}
+	b.privKey, err = b.findKey(pkcs11.CKO_PRIVATE_KEY, cfg.KeyLabel)
+	if err != nil {
+		return err
+	}
+	b.pubKey, err = b.findKey(pkcs11.CKO_PUBLIC_KEY, cfg.KeyLabel)
+	return err
+}
+
+func (b *pkcs11Backend) findSlot(tokenLabel string) (uint, error) {
+	slots, err := b.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("list slots: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := b.ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if tokenLabel == "" || strings.TrimSpace(info.Label) == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("token %q not found", tokenLabel)
+}
+
+func (b *pkcs11Backend) findKey(class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, ckkECEdwards),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := b.ctx.FindObjectsInit(b.session, template); err != nil {
+		return 0, fmt.Errorf("find key %q: %w", label, err)
+	}
+	handles, _, err := b.ctx.FindObjects(b.session, maxFindObjects)
+	_ = b.ctx.FindObjectsFinal(b.session)
+	if err != nil {
+		return 0, fmt.Errorf("find key %q: %w", label, err)
+	}
+	switch len(handles) {
+	case 0:
+		return 0, fmt.Errorf("Ed25519 key %q not found", label)
+	case 1:
+		return handles[0], nil
+	default:
+		return 0, fmt.Errorf("Ed25519 key label %q is ambiguous", label)
+	}
+}
+
+func (b *pkcs11Backend) publicKey() (ed25519.PublicKey, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	attrs, err := b.ctx.GetAttributeValue(b.session, b.pubKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read CKA_EC_POINT: %w", err)
+	}
+	point := attrs[0].Value
+	// CKA_EC_POINT is a DER OCTET STRING; some tokens return the raw point.
+	if len(point) != ed25519.PublicKeySize {
+		var raw []byte
+		if _, err := asn1.Unmarshal(point, &raw); err != nil {
+			return nil, fmt.Errorf("decode CKA_EC_POINT: %w", err)
+		}
+		point = raw
+	}
+	if len(point) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("CKA_EC_POINT is %d bytes, want %d", len(point), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(point), nil
+}
+
+func (b *pkcs11Backend) sign(payload []byte) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	mech := []*pkcs11.Mechanism{pkcs11.NewMechanism(ckmEdDSA, nil)}
+	if err := b.ctx.SignInit(b.session, mech, b.privKey); err != nil {
+		return nil, fmt.Errorf("sign init: %w", err)
+	}
+	sig, err := b.ctx.Sign(b.session, payload)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	return sig, nil
+}
+
+func (b *pkcs11Backend) close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.session != 0 {
+		_ = b.ctx.Logout(b.session)
+		_ = b.ctx.CloseSession(b.session)
+		b.session = 0
+	}
+	err := b.ctx.Finalize()
+	b.ctx.Destroy()
+	return err
+}
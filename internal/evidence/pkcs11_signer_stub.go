@@ -0,0 +1,13 @@
+//go:build !pkcs11 || !cgo
+
+package evidence
+
+import "errors"
+
+// ErrPKCS11Unavailable is returned when the binary was built without PKCS#11
+// support.
+var ErrPKCS11Unavailable = errors.New("pkcs11 signer backend requires a build with -tags pkcs11 (cgo)")
+
+func newPKCS11Backend(PKCS11Config) (signingBackend, error) {
+	return nil, ErrPKCS11Unavailable
+}
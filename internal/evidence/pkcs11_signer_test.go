@@ -0,0 +1,54 @@
+//go:build pkcs11 && cgo
+
+package evidence
+
+import (
+	"crypto/ed25519"
+	"os"
+	"testing"
+)
+
+// TestPKCS11Backend_SoftHSM runs against a SoftHSM token holding an Ed25519
+// key pair, e.g.:
+//
+//	softhsm2-util --init-token --free --label evidra --pin 1234 --so-pin 1234
+//	pkcs11-tool --module $MODULE --login --pin 1234 --token-label evidra \
+//	    --keypairgen --key-type EC:edwards25519 --label evidence
+//	EVIDRA_TEST_PKCS11_MODULE=$MODULE go test -tags pkcs11 ./internal/evidence/
+func TestPKCS11Backend_SoftHSM(t *testing.T) {
+	module := os.Getenv("EVIDRA_TEST_PKCS11_MODULE")
+	if module == "" {
+		t.Skip("EVIDRA_TEST_PKCS11_MODULE not set")
+	}
+	cfg := PKCS11Config{
+		Module:     module,
+		TokenLabel: envOrDefault("EVIDRA_TEST_PKCS11_TOKEN_LABEL", "evidra"),
+		PIN:        envOrDefault("EVIDRA_TEST_PKCS11_PIN", "1234"),
+		KeyLabel:   envOrDefault("EVIDRA_TEST_PKCS11_KEY_LABEL", "evidence"),
+	}
+	backend, err := newPKCS11Backend(cfg)
+	if err != nil {
+		t.Fatalf("newPKCS11Backend: %v", err)
+	}
+	s, err := newBackendSigner("pkcs11", backend)
+	if err != nil {
+		t.Fatalf("newBackendSigner: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	payload := []byte("sha256:abc")
+	sig, err := s.TrySign(payload)
+	if err != nil {
+		t.Fatalf("TrySign: %v", err)
+	}
+	if !ed25519.Verify(s.PublicKey(), payload, sig) {
+		t.Fatal("signature does not verify")
+	}
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
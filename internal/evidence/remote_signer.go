@@ -0,0 +1,143 @@
+package evidence
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RemoteConfig points at a remote signing service. The service exposes
+//
+//	GET  {URL}/v1/public-key  -> {"key_id": "...", "public_key": "<base64>"}
+//	POST {URL}/v1/sign        <- {"key_id": "...", "payload": "<base64>"}
+//	                          -> {"key_id": "...", "signature": "<base64>"}
+//
+// and authenticates requests with a bearer token when Token is set.
+type RemoteConfig struct {
+	URL     string
+	Token   string
+	KeyID   string
+	Timeout time.Duration
+}
+
+// RemotePublicKeyResponse is the body of GET /v1/public-key.
+type RemotePublicKeyResponse struct {
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+}
+
+// RemoteSignRequest is the body of POST /v1/sign.
+type RemoteSignRequest struct {
+	KeyID   string `json:"key_id,omitempty"`
+	Payload string `json:"payload"`
+}
+
+// RemoteSignResponse is the response of POST /v1/sign.
+type RemoteSignResponse struct {
+	KeyID     string `json:"key_id"`
+	Signature string `json:"signature"`
+}
+
+type remoteBackend struct {
+	cfg    RemoteConfig
+	client *http.Client
+}
+
+func newRemoteBackend(cfg RemoteConfig) (*remoteBackend, error) {
+	cfg.URL = strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("remote signer URL is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &remoteBackend{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+func (b *remoteBackend) publicKey() (ed25519.PublicKey, error) {
+	path := "/v1/public-key"
+	if b.cfg.KeyID != "" {
+		path += "?" + url.Values{"key_id": []string{b.cfg.KeyID}}.Encode()
+	}
+	var resp RemotePublicKeyResponse
+	if err := b.do(http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key is %d bytes, want %d", len(raw), ed25519.PublicKeySize)
+	}
+	if b.cfg.KeyID == "" {
+		b.cfg.KeyID = resp.KeyID
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+func (b *remoteBackend) sign(payload []byte) ([]byte, error) {
+	req := RemoteSignRequest{KeyID: b.cfg.KeyID, Payload: base64.StdEncoding.EncodeToString(payload)}
+	var resp RemoteSignResponse
+	if err := b.do(http.MethodPost, "/v1/sign", req, &resp); err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+	return sig, nil
+}
+
+func (b *remoteBackend) do(method, path string, body, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.Timeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.cfg.URL+path, reader)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.cfg.Token)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%s %s: read body: %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (b *remoteBackend) close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
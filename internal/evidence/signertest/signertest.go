@@ -0,0 +1,148 @@
+// Package signertest provides local stand-ins for external signer backends:
+// a remote signing service and an ssh-agent socket, for use in tests.
+package signertest
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	ievsigner "samebits.com/evidra/internal/evidence"
+	"samebits.com/evidra/pkg/evidence"
+
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// RemoteServer implements the remote signing protocol with an in-memory
+// Ed25519 key. It implements http.Handler; wrap it with httptest.NewServer.
+type RemoteServer struct {
+	mu    sync.Mutex
+	priv  ed25519.PrivateKey
+	kid   string
+	token string
+	signs int
+	fail  bool
+}
+
+// NewRemoteServer returns a signing service for priv. When token is
+// non-empty, requests must carry "Authorization: Bearer <token>".
+func NewRemoteServer(priv ed25519.PrivateKey, token string) *RemoteServer {
+	pub := priv.Public().(ed25519.PublicKey)
+	return &RemoteServer{priv: priv, kid: evidence.KeyID(pub), token: token}
+}
+
+// KeyID returns the key ID the server advertises.
+func (s *RemoteServer) KeyID() string { return s.kid }
+
+// Signs returns the number of successful sign requests.
+func (s *RemoteServer) Signs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signs
+}
+
+// SetFailing makes subsequent sign requests return 503.
+func (s *RemoteServer) SetFailing(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+// ServeHTTP implements http.Handler.
+func (s *RemoteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/public-key":
+		if kid := r.URL.Query().Get("key_id"); kid != "" && kid != s.kid {
+			http.Error(w, "unknown key_id", http.StatusNotFound)
+			return
+		}
+		pub := s.priv.Public().(ed25519.PublicKey)
+		writeJSON(w, ievsigner.RemotePublicKeyResponse{
+			KeyID:     s.kid,
+			PublicKey: base64.StdEncoding.EncodeToString(pub),
+		})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/sign":
+		var req ievsigner.RemoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.KeyID != "" && req.KeyID != s.kid {
+			http.Error(w, "unknown key_id", http.StatusNotFound)
+			return
+		}
+		payload, err := base64.StdEncoding.DecodeString(req.Payload)
+		if err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		fail := s.fail
+		if !fail {
+			s.signs++
+		}
+		s.mu.Unlock()
+		if fail {
+			http.Error(w, "signer unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, ievsigner.RemoteSignResponse{
+			KeyID:     s.kid,
+			Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.priv, payload)),
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// StartAgent serves an ssh-agent holding priv (with the given comment) on a
+// unix socket and returns the socket path. The agent stops when the test
+// ends.
+func StartAgent(t testing.TB, priv ed25519.PrivateKey, comment string) string {
+	t.Helper()
+	// Unix socket paths are length-limited; t.TempDir can exceed it.
+	dir, err := os.MkdirTemp("", "evidra-agent")
+	if err != nil {
+		t.Fatalf("agent temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "agent.sock")
+
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: priv, Comment: comment}); err != nil {
+		t.Fatalf("agent add key: %v", err)
+	}
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("agent listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	return socket
+}
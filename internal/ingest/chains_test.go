@@ -0,0 +1,164 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// fakeChains serves one stored chain from memory.
+type fakeChains struct {
+	chain    store.Chain
+	entries  []store.ChainEntry
+	recorded *bool
+	reason   string
+}
+
+func (f *fakeChains) GetChain(context.Context, string, string) (store.Chain, error) {
+	return f.chain, nil
+}
+
+func (f *fakeChains) ChainEntries(_ context.Context, _, _ string, fn func(store.ChainEntry) error) error {
+	for _, e := range f.entries {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeChains) RecordChainValidation(_ context.Context, _, _ string, valid bool, reason string) error {
+	f.recorded = &valid
+	f.reason = reason
+	return nil
+}
+
+func chainOf(t *testing.T, entries ...evidence.EvidenceEntry) *fakeChains {
+	t.Helper()
+	f := &fakeChains{chain: store.Chain{
+		ChainID:             entries[0].EntryID,
+		GenesisHash:         entries[0].Hash,
+		GenesisPreviousHash: entries[0].PreviousHash,
+		HeadHash:            entries[len(entries)-1].Hash,
+		Length:              int64(len(entries)),
+	}}
+	for i, raw := range raws(t, entries...) {
+		f.entries = append(f.entries, store.ChainEntry{Seq: int64(i + 1), EntryID: entries[i].EntryID, Raw: raw, Exact: true})
+	}
+	return f
+}
+
+func TestChainValidator_Validate(t *testing.T) {
+	t.Parallel()
+	signer, stranger := testutil.TestSigner(t), testutil.TestSigner(t)
+	first := buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":1}`, "")
+	second := buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":2}`, first.Hash)
+	third := buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":3}`, second.Hash)
+	foreign := buildEntry(t, stranger, evidence.EntryTypeSignal, `{"n":4}`, "")
+
+	tests := []struct {
+		name     string
+		chains   func() *fakeChains
+		wantCode string
+		wantSeq  int64
+	}{
+		{
+			name:   "intact chain",
+			chains: func() *fakeChains { return chainOf(t, first, second, third) },
+		},
+		{
+			name: "tampered stored bytes",
+			chains: func() *fakeChains {
+				f := chainOf(t, first, second, third)
+				tampered := second
+				tampered.Payload = json.RawMessage(`{"n":9}`)
+				f.entries[1].Raw = raws(t, tampered)[0]
+				return f
+			},
+			wantCode: CodeHashMismatch,
+			wantSeq:  2,
+		},
+		{
+			name: "missing middle entry",
+			chains: func() *fakeChains {
+				f := chainOf(t, first, second, third)
+				f.entries = append(f.entries[:1], f.entries[2])
+				return f
+			},
+			wantCode: CodeSequenceGap,
+			wantSeq:  3,
+		},
+		{
+			name: "head beyond stored entries",
+			chains: func() *fakeChains {
+				f := chainOf(t, first, second)
+				f.chain.HeadHash, f.chain.Length = third.Hash, 3
+				return f
+			},
+			wantCode: CodeHeadMismatch,
+		},
+		{
+			name:     "unregistered signer",
+			chains:   func() *fakeChains { return chainOf(t, foreign) },
+			wantCode: CodeUnknownKey,
+			wantSeq:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			chains := tt.chains()
+			report, err := NewChainValidator(chains, newFakeKeyLister(signer), nil).Validate(context.Background(), "t1", chains.chain.ChainID)
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if report.Valid != (tt.wantCode == "") || report.Code != tt.wantCode || report.FailedSeq != tt.wantSeq {
+				t.Fatalf("report = %+v, want code %q at %d", report, tt.wantCode, tt.wantSeq)
+			}
+			if chains.recorded == nil || *chains.recorded != report.Valid {
+				t.Fatalf("recorded validity = %v, want %v", chains.recorded, report.Valid)
+			}
+		})
+	}
+}
+
+func TestChainValidator_TrustsServerAndRotatedKeys(t *testing.T) {
+	t.Parallel()
+	server, oldKey, newKey := testutil.TestSigner(t), testutil.TestSigner(t), testutil.TestSigner(t)
+
+	webhook := buildEntry(t, server, evidence.EntryTypeSignal, `{"n":1}`, "")
+	serverKeys := evidence.NewKeyring(server.PublicKey())
+	report, err := NewChainValidator(chainOf(t, webhook), newFakeKeyLister(), serverKeys).Validate(context.Background(), "t1", webhook.EntryID)
+	if err != nil || !report.Valid {
+		t.Fatalf("server-signed chain: report = %+v, err = %v", report, err)
+	}
+
+	rotation, err := evidence.BuildKeyRotationPayload(oldKey, newKey, "scheduled")
+	if err != nil {
+		t.Fatalf("BuildKeyRotationPayload: %v", err)
+	}
+	first := buildEntry(t, oldKey, evidence.EntryTypeKeyRotation, string(rotation), "")
+	second := buildEntry(t, newKey, evidence.EntryTypeSignal, `{"n":1}`, first.Hash)
+	report, err = NewChainValidator(chainOf(t, first, second), newFakeKeyLister(oldKey), nil).Validate(context.Background(), "t1", first.EntryID)
+	if err != nil || !report.Valid || report.Checked != 2 {
+		t.Fatalf("rotated chain: report = %+v, err = %v", report, err)
+	}
+}
+
+type fakeKeyLister []store.SigningKeyRecord
+
+func newFakeKeyLister(signers ...evidence.Signer) fakeKeyLister {
+	var recs fakeKeyLister
+	for _, s := range signers {
+		recs = append(recs, store.SigningKeyRecord{KeyID: evidence.KeyID(s.PublicKey()), PublicKey: s.PublicKey()})
+	}
+	return recs
+}
+
+func (f fakeKeyLister) ListKeys(context.Context, string) ([]store.SigningKeyRecord, error) {
+	return f, nil
+}
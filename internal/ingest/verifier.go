@@ -0,0 +1,276 @@
+// Package ingest verifies evidence entries forwarded to the API before they
+// are stored. Each entry must carry a correct hash, link to an entry the
+// tenant already stored (or start a new chain), and be signed by one of the
+// tenant's registered signing keys. Entries that fail are rejected or, when
+// the policy says so, quarantined for review.
+package ingest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Action is what happens to an entry that fails verification.
+type Action string
+
+const (
+	ActionReject     Action = "reject"
+	ActionQuarantine Action = "quarantine"
+)
+
+// SignaturePolicy controls which entries must carry a verifiable signature.
+type SignaturePolicy string
+
+const (
+	// SignaturesRequired rejects unsigned entries and entries signed by a
+	// key the tenant has not registered. Operators opt in once every tenant
+	// has registered its keys.
+	SignaturesRequired SignaturePolicy = "required"
+	// SignaturesRegistered verifies signatures only for tenants that have
+	// registered at least one key, so existing tenants keep forwarding
+	// unsigned entries until they register one. It is the default.
+	SignaturesRegistered SignaturePolicy = "registered"
+)
+
+const (
+	onFailureEnv  = "EVIDRA_INGEST_ON_FAILURE"
+	signaturesEnv = "EVIDRA_INGEST_SIGNATURES"
+)
+
+// Policy configures ingestion verification.
+type Policy struct {
+	OnFailure  Action
+	Signatures SignaturePolicy
+}
+
+// PolicyFromEnv reads EVIDRA_INGEST_ON_FAILURE (reject|quarantine, default
+// reject) and EVIDRA_INGEST_SIGNATURES (required|registered, default
+// registered).
+func PolicyFromEnv() (Policy, error) {
+	p := Policy{OnFailure: ActionReject, Signatures: SignaturesRegistered}
+	if raw := strings.ToLower(strings.TrimSpace(os.Getenv(onFailureEnv))); raw != "" {
+		switch Action(raw) {
+		case ActionReject, ActionQuarantine:
+			p.OnFailure = Action(raw)
+		default:
+			return Policy{}, fmt.Errorf("invalid %s %q (expected reject|quarantine)", onFailureEnv, raw)
+		}
+	}
+	if raw := strings.ToLower(strings.TrimSpace(os.Getenv(signaturesEnv))); raw != "" {
+		switch SignaturePolicy(raw) {
+		case SignaturesRequired, SignaturesRegistered:
+			p.Signatures = SignaturePolicy(raw)
+		default:
+			return Policy{}, fmt.Errorf("invalid %s %q (expected required|registered)", signaturesEnv, raw)
+		}
+	}
+	return p, nil
+}
+
+// Entry statuses reported per entry.
+const (
+	StatusAccepted    = "accepted"
+	StatusRejected    = "rejected"
+	StatusQuarantined = "quarantined"
+)
+
+// Failure codes reported per entry.
+const (
+	CodeInvalidEntry           = "invalid_entry"
+	CodeHashMismatch           = "hash_mismatch"
+	CodeUnsigned               = "unsigned"
+	CodeUnknownKey             = "unknown_key"
+	CodeBadSignature           = "bad_signature"
+	CodeChainBreak             = "chain_break"
+	CodePredecessorQuarantined = "predecessor_quarantined"
+	CodeChainFork              = "chain_fork"
+	CodeIDConflict             = "id_conflict"
+	// CodeStoreFailed is a server-side failure; the entry may be resent.
+	CodeStoreFailed = "store_failed"
+)
+
+// Result is the outcome for one entry of a forward or batch request.
+type Result struct {
+	Index     int    `json:"index"`
+	EntryID   string `json:"entry_id,omitempty"`
+	Status    string `json:"status"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message,omitempty"`
+	ReceiptID string `json:"receipt_id,omitempty"`
+}
+
+// EntryStore persists verified and quarantined entries.
+type EntryStore interface {
+	SaveRaw(ctx context.Context, tenantID string, raw json.RawMessage) (string, error)
+	LookupHash(ctx context.Context, tenantID, hash string) (store.HashState, error)
+	Quarantine(ctx context.Context, tenantID string, q store.QuarantinedEntry) error
+}
+
+// KeyStore holds each tenant's registered signing keys.
+type KeyStore interface {
+	Keyring(ctx context.Context, tenantID string) (*evidence.Keyring, error)
+	AddKey(ctx context.Context, tenantID string, pub ed25519.PublicKey, label, source string) (store.SigningKeyRecord, error)
+}
+
+// Verifier checks and stores incoming entries.
+type Verifier struct {
+	entries EntryStore
+	keys    KeyStore
+	policy  Policy
+}
+
+// NewVerifier creates a Verifier. Zero Policy fields take the defaults
+// (reject, signatures checked for tenants with registered keys).
+func NewVerifier(entries EntryStore, keys KeyStore, policy Policy) *Verifier {
+	if policy.OnFailure == "" {
+		policy.OnFailure = ActionReject
+	}
+	if policy.Signatures == "" {
+		policy.Signatures = SignaturesRegistered
+	}
+	return &Verifier{entries: entries, keys: keys, policy: policy}
+}
+
+// Ingest verifies and stores raws in order, so an entry may link to one
+// accepted earlier in the same call. It returns one Result per entry; the
+// error is reserved for failures that affect the whole request.
+func (v *Verifier) Ingest(ctx context.Context, tenantID string, raws []json.RawMessage) ([]Result, error) {
+	keyring, err := v.keys.Keyring(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("ingest: load signing keys: %w", err)
+	}
+	results := make([]Result, len(raws))
+	for i, raw := range raws {
+		results[i] = v.ingestOne(ctx, tenantID, keyring, i, raw)
+	}
+	return results, nil
+}
+
+// failure is a verification or storage failure for one entry.
+type failure struct {
+	code string
+	err  error
+}
+
+func (v *Verifier) ingestOne(ctx context.Context, tenantID string, keyring *evidence.Keyring, index int, raw json.RawMessage) Result {
+	res := Result{Index: index}
+	var entry evidence.EvidenceEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return v.fail(ctx, tenantID, res, entry, raw, failure{CodeInvalidEntry, fmt.Errorf("decode entry: %w", err)})
+	}
+	res.EntryID = entry.EntryID
+
+	verified, f := v.verify(ctx, tenantID, keyring, entry)
+	if f != nil {
+		return v.fail(ctx, tenantID, res, entry, raw, *f)
+	}
+
+	receiptID, err := v.entries.SaveRaw(ctx, tenantID, raw)
+	if errors.Is(err, store.ErrEntryConflict) {
+		return v.fail(ctx, tenantID, res, entry, raw, failure{CodeIDConflict, fmt.Errorf("entry_id %s is already stored with a different hash", entry.EntryID)})
+	}
+	if errors.Is(err, store.ErrChainFork) {
+		return v.fail(ctx, tenantID, res, entry, raw, failure{CodeChainFork, fmt.Errorf("previous_hash %s already has a stored successor", entry.PreviousHash)})
+	}
+	if err != nil {
+		return v.fail(ctx, tenantID, res, entry, raw, failure{CodeStoreFailed, err})
+	}
+
+	// A key_rotation entry signed by a trusted key hands trust to its
+	// successor, exactly as local chain validation does.
+	if verified && entry.Type == evidence.EntryTypeKeyRotation {
+		newPub, err := evidence.IntroducedKey(entry)
+		if err == nil {
+			if _, err = v.keys.AddKey(ctx, tenantID, newPub, "rotated from "+entry.KeyID, store.SigningKeySourceKeyRotation); err == nil {
+				keyring.Add(newPub)
+			}
+		}
+		if err != nil {
+			res.Message = fmt.Sprintf("rotated key not registered: %v", err)
+		}
+	}
+
+	res.Status = StatusAccepted
+	res.ReceiptID = receiptID
+	return res
+}
+
+// verify runs every check short of storing the entry. verified reports
+// whether the signature was checked against a registered key.
+func (v *Verifier) verify(ctx context.Context, tenantID string, keyring *evidence.Keyring, entry evidence.EvidenceEntry) (verified bool, f *failure) {
+	switch {
+	case entry.EntryID == "":
+		return false, &failure{CodeInvalidEntry, errors.New("entry_id is required")}
+	case entry.Hash == "":
+		return false, &failure{CodeInvalidEntry, errors.New("hash is required")}
+	case !entry.Type.Valid():
+		return false, &failure{CodeInvalidEntry, fmt.Errorf("unknown entry type %q", entry.Type)}
+	case entry.TenantID != "" && entry.TenantID != tenantID:
+		return false, &failure{CodeInvalidEntry, fmt.Errorf("tenant_id %s does not match the API key's tenant", entry.TenantID)}
+	}
+
+	if err := evidence.VerifyEntryHash(entry); err != nil {
+		return false, &failure{CodeHashMismatch, err}
+	}
+
+	if v.policy.Signatures == SignaturesRequired || keyring.Len() > 0 {
+		if err := evidence.VerifyEntrySignature(entry, keyring); err != nil {
+			switch {
+			case errors.Is(err, evidence.ErrEntryUnsigned):
+				return false, &failure{CodeUnsigned, err}
+			case errors.Is(err, evidence.ErrEntryUnknownKey):
+				return false, &failure{CodeUnknownKey, err}
+			default:
+				return false, &failure{CodeBadSignature, err}
+			}
+		}
+		verified = true
+	}
+
+	if entry.PreviousHash != "" {
+		state, err := v.entries.LookupHash(ctx, tenantID, entry.PreviousHash)
+		if err != nil {
+			return verified, &failure{CodeStoreFailed, err}
+		}
+		switch state {
+		case store.HashStored:
+		case store.HashQuarantined:
+			return verified, &failure{CodePredecessorQuarantined, fmt.Errorf("previous entry %s is quarantined", entry.PreviousHash)}
+		default:
+			return verified, &failure{CodeChainBreak, fmt.Errorf("previous_hash %s is not stored for this tenant", entry.PreviousHash)}
+		}
+	}
+	return verified, nil
+}
+
+// fail records f on res, quarantining the entry when the policy asks for it.
+// Store failures are never quarantined: the client should resend.
+func (v *Verifier) fail(ctx context.Context, tenantID string, res Result, entry evidence.EvidenceEntry, raw json.RawMessage, f failure) Result {
+	res.Status = StatusRejected
+	res.Code = f.code
+	res.Message = f.err.Error()
+	if v.policy.OnFailure != ActionQuarantine || f.code == CodeStoreFailed {
+		return res
+	}
+	err := v.entries.Quarantine(ctx, tenantID, store.QuarantinedEntry{
+		EntryID:    entry.EntryID,
+		Hash:       entry.Hash,
+		ReasonCode: f.code,
+		Reason:     res.Message,
+		Raw:        raw,
+	})
+	if err != nil {
+		res.Message = fmt.Sprintf("%s (quarantine failed: %v)", res.Message, err)
+		return res
+	}
+	res.Status = StatusQuarantined
+	return res
+}
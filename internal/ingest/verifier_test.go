@@ -0,0 +1,306 @@
+package ingest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// fakeStore keeps stored and quarantined hashes per tenant in memory.
+type fakeStore struct {
+	stored      map[string]string // hash -> entry_id
+	quarantined map[string]store.QuarantinedEntry
+	keys        map[string]ed25519.PublicKey
+	sources     map[string]string
+	saveErr     error
+}
+
+func newFakeStore(keys ...ed25519.PublicKey) *fakeStore {
+	f := &fakeStore{
+		stored:      map[string]string{},
+		quarantined: map[string]store.QuarantinedEntry{},
+		keys:        map[string]ed25519.PublicKey{},
+		sources:     map[string]string{},
+	}
+	for _, k := range keys {
+		f.keys[evidence.KeyID(k)] = k
+		f.sources[evidence.KeyID(k)] = store.SigningKeySourceAPI
+	}
+	return f
+}
+
+func (f *fakeStore) SaveRaw(_ context.Context, _ string, raw json.RawMessage) (string, error) {
+	if f.saveErr != nil {
+		return "", f.saveErr
+	}
+	var e evidence.EvidenceEntry
+	_ = json.Unmarshal(raw, &e)
+	f.stored[e.Hash] = e.EntryID
+	return e.EntryID, nil
+}
+
+func (f *fakeStore) LookupHash(_ context.Context, _ string, hash string) (store.HashState, error) {
+	if _, ok := f.stored[hash]; ok {
+		return store.HashStored, nil
+	}
+	if _, ok := f.quarantined[hash]; ok {
+		return store.HashQuarantined, nil
+	}
+	return store.HashUnknown, nil
+}
+
+func (f *fakeStore) Quarantine(_ context.Context, _ string, q store.QuarantinedEntry) error {
+	f.quarantined[q.Hash] = q
+	return nil
+}
+
+func (f *fakeStore) Keyring(context.Context, string) (*evidence.Keyring, error) {
+	k := evidence.NewKeyring()
+	for _, pub := range f.keys {
+		k.Add(pub)
+	}
+	return k, nil
+}
+
+func (f *fakeStore) AddKey(_ context.Context, tenantID string, pub ed25519.PublicKey, label, source string) (store.SigningKeyRecord, error) {
+	kid := evidence.KeyID(pub)
+	f.keys[kid] = pub
+	f.sources[kid] = source
+	return store.SigningKeyRecord{TenantID: tenantID, KeyID: kid, PublicKey: pub, Label: label, Source: source}, nil
+}
+
+func buildEntry(t *testing.T, signer evidence.Signer, typ evidence.EntryType, payload, prev string) evidence.EvidenceEntry {
+	t.Helper()
+	e, err := evidence.BuildEntry(evidence.EntryBuildParams{
+		Type:         typ,
+		SessionID:    "s1",
+		TraceID:      "s1",
+		Actor:        evidence.Actor{Type: "agent", ID: "a", Provenance: "test"},
+		Payload:      json.RawMessage(payload),
+		PreviousHash: prev,
+		SpecVersion:  "0.3.0",
+		Signer:       signer,
+	})
+	if err != nil {
+		t.Fatalf("BuildEntry: %v", err)
+	}
+	return e
+}
+
+func raws(t *testing.T, entries ...evidence.EvidenceEntry) []json.RawMessage {
+	t.Helper()
+	out := make([]json.RawMessage, len(entries))
+	for i, e := range entries {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		out[i] = b
+	}
+	return out
+}
+
+func statuses(results []Result) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Status + "/" + r.Code
+	}
+	return out
+}
+
+func TestIngest_Verification(t *testing.T) {
+	t.Parallel()
+	signer, stranger := testutil.TestSigner(t), testutil.TestSigner(t)
+	first := buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":1}`, "")
+	second := buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":2}`, first.Hash)
+	tampered := second
+	tampered.Payload = json.RawMessage(`{"n":3}`)
+	unsigned := first
+	unsigned.Signature = ""
+	orphan := buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":4}`, "sha256:missing")
+	otherTenant := first
+	otherTenant.TenantID = "t2"
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		keys    []ed25519.PublicKey
+		entries []evidence.EvidenceEntry
+		want    []string
+	}{
+		{
+			name:    "signed chain accepted in order",
+			keys:    []ed25519.PublicKey{signer.PublicKey()},
+			entries: []evidence.EvidenceEntry{first, second},
+			want:    []string{"accepted/", "accepted/"},
+		},
+		{
+			name:    "tampered payload",
+			keys:    []ed25519.PublicKey{signer.PublicKey()},
+			entries: []evidence.EvidenceEntry{first, tampered},
+			want:    []string{"accepted/", "rejected/hash_mismatch"},
+		},
+		{
+			name:    "missing predecessor",
+			keys:    []ed25519.PublicKey{signer.PublicKey()},
+			entries: []evidence.EvidenceEntry{second},
+			want:    []string{"rejected/chain_break"},
+		},
+		{
+			name:    "unregistered key",
+			keys:    []ed25519.PublicKey{stranger.PublicKey()},
+			entries: []evidence.EvidenceEntry{first},
+			want:    []string{"rejected/unknown_key"},
+		},
+		{
+			name:    "unsigned entry",
+			keys:    []ed25519.PublicKey{signer.PublicKey()},
+			entries: []evidence.EvidenceEntry{unsigned},
+			want:    []string{"rejected/unsigned"},
+		},
+		{
+			name:    "no registered keys with signatures required",
+			policy:  Policy{Signatures: SignaturesRequired},
+			entries: []evidence.EvidenceEntry{first},
+			want:    []string{"rejected/unknown_key"},
+		},
+		{
+			name:    "no registered keys by default",
+			entries: []evidence.EvidenceEntry{unsigned},
+			want:    []string{"accepted/"},
+		},
+		{
+			name:    "tenant mismatch",
+			keys:    []ed25519.PublicKey{signer.PublicKey()},
+			entries: []evidence.EvidenceEntry{otherTenant},
+			want:    []string{"rejected/invalid_entry"},
+		},
+		{
+			name:    "quarantine keeps chain broken downstream",
+			policy:  Policy{OnFailure: ActionQuarantine},
+			keys:    []ed25519.PublicKey{signer.PublicKey()},
+			entries: []evidence.EvidenceEntry{orphan, buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":5}`, orphan.Hash)},
+			want:    []string{"quarantined/chain_break", "quarantined/predecessor_quarantined"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fs := newFakeStore(tt.keys...)
+			results, err := NewVerifier(fs, fs, tt.policy).Ingest(context.Background(), "t1", raws(t, tt.entries...))
+			if err != nil {
+				t.Fatalf("Ingest: %v", err)
+			}
+			got := statuses(results)
+			if len(got) != len(tt.want) {
+				t.Fatalf("results = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("results = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIngest_InvalidJSONEntry(t *testing.T) {
+	t.Parallel()
+	fs := newFakeStore()
+	results, err := NewVerifier(fs, fs, Policy{}).Ingest(context.Background(), "t1", []json.RawMessage{json.RawMessage(`{"type":"prescription"}`)})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if results[0].Status != StatusRejected || results[0].Code != CodeInvalidEntry {
+		t.Fatalf("result = %+v", results[0])
+	}
+}
+
+func TestIngest_KeyRotationRegistersSuccessor(t *testing.T) {
+	t.Parallel()
+	oldKey, newKey := testutil.TestSigner(t), testutil.TestSigner(t)
+	rotation, err := evidence.BuildKeyRotationPayload(oldKey, newKey, "scheduled")
+	if err != nil {
+		t.Fatalf("BuildKeyRotationPayload: %v", err)
+	}
+	first := buildEntry(t, oldKey, evidence.EntryTypeKeyRotation, string(rotation), "")
+	second := buildEntry(t, newKey, evidence.EntryTypeSignal, `{"n":1}`, first.Hash)
+
+	fs := newFakeStore(oldKey.PublicKey())
+	results, err := NewVerifier(fs, fs, Policy{}).Ingest(context.Background(), "t1", raws(t, first, second))
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if got := statuses(results); got[0] != "accepted/" || got[1] != "accepted/" {
+		t.Fatalf("results = %v", got)
+	}
+	if src := fs.sources[evidence.KeyID(newKey.PublicKey())]; src != store.SigningKeySourceKeyRotation {
+		t.Fatalf("successor key source = %q, want %q", src, store.SigningKeySourceKeyRotation)
+	}
+}
+
+func TestIngest_StoreFailures(t *testing.T) {
+	t.Parallel()
+	signer := testutil.TestSigner(t)
+	entry := buildEntry(t, signer, evidence.EntryTypeSignal, `{"n":1}`, "")
+
+	tests := []struct {
+		name     string
+		saveErr  error
+		wantCode string
+	}{
+		{name: "id conflict", saveErr: store.ErrEntryConflict, wantCode: CodeIDConflict},
+		{name: "chain fork", saveErr: store.ErrChainFork, wantCode: CodeChainFork},
+		{name: "database down", saveErr: errors.New("connection refused"), wantCode: CodeStoreFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fs := newFakeStore(signer.PublicKey())
+			fs.saveErr = tt.saveErr
+			results, err := NewVerifier(fs, fs, Policy{OnFailure: ActionQuarantine}).Ingest(context.Background(), "t1", raws(t, entry))
+			if err != nil {
+				t.Fatalf("Ingest: %v", err)
+			}
+			if results[0].Code != tt.wantCode {
+				t.Fatalf("code = %q, want %q", results[0].Code, tt.wantCode)
+			}
+			// Store failures are left for the client to resend.
+			wantQuarantined := tt.wantCode != CodeStoreFailed
+			if _, ok := fs.quarantined[entry.Hash]; ok != wantQuarantined {
+				t.Fatalf("quarantined = %v, want %v", ok, wantQuarantined)
+			}
+		})
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	tests := []struct {
+		onFailure, signatures string
+		want                  Policy
+		wantErr               bool
+	}{
+		{want: Policy{OnFailure: ActionReject, Signatures: SignaturesRegistered}},
+		{signatures: "Required", want: Policy{OnFailure: ActionReject, Signatures: SignaturesRequired}},
+		{onFailure: "Quarantine", signatures: "registered", want: Policy{OnFailure: ActionQuarantine, Signatures: SignaturesRegistered}},
+		{onFailure: "drop", wantErr: true},
+		{signatures: "never", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv(onFailureEnv, tt.onFailure)
+		t.Setenv(signaturesEnv, tt.signatures)
+		got, err := PolicyFromEnv()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("PolicyFromEnv(%q, %q) err = %v", tt.onFailure, tt.signatures, err)
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Fatalf("PolicyFromEnv(%q, %q) = %+v, want %+v", tt.onFailure, tt.signatures, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+// Package k8saudit reads Kubernetes audit logs and reconciles the writes
+// they record against prescriptions in an evidence store, so mutations made
+// without prescribing can be flagged.
+package k8saudit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Provenance marks actors of entries written from audit logs.
+const Provenance = "k8s_audit"
+
+// SubSignal is the protocol_violation sub-signal recorded for observed
+// mutations no prescription covers.
+const SubSignal = "unprescribed_observed"
+
+// maxLineBytes bounds a single audit event; RequestResponse-level events
+// carry whole objects.
+const maxLineBytes = 8 << 20
+
+// writeVerbs maps audit verbs to canonical k8s operations. Reads, watches
+// and proxied connections are not mutations.
+var writeVerbs = map[string]string{
+	"create":           "create",
+	"update":           "apply",
+	"patch":            "patch",
+	"delete":           "delete",
+	"deletecollection": "delete",
+}
+
+// operationClasses mirrors the k8s adapter's classification of the
+// operations in writeVerbs.
+var operationClasses = map[string]string{
+	"create": "mutate",
+	"apply":  "mutate",
+	"patch":  "mutate",
+	"delete": "destroy",
+}
+
+// irregularKinds covers resources whose kind is not the plural minus its
+// suffix.
+var irregularKinds = map[string]string{
+	"endpoints": "endpoints",
+}
+
+var envelopeKinds = map[string]bool{
+	"DeleteOptions": true,
+	"Status":        true,
+}
+
+// Event is the subset of an audit.k8s.io/v1 Event the importer reads.
+type Event struct {
+	AuditID    string `json:"auditID"`
+	Stage      string `json:"stage"`
+	RequestURI string `json:"requestURI"`
+	Verb       string `json:"verb"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ImpersonatedUser *struct {
+		Username string `json:"username"`
+	} `json:"impersonatedUser,omitempty"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		APIGroup    string `json:"apiGroup"`
+		APIVersion  string `json:"apiVersion"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef,omitempty"`
+	ResponseStatus *struct {
+		Code int `json:"code"`
+	} `json:"responseStatus,omitempty"`
+	RequestObject            json.RawMessage `json:"requestObject,omitempty"`
+	ResponseObject           json.RawMessage `json:"responseObject,omitempty"`
+	RequestReceivedTimestamp time.Time       `json:"requestReceivedTimestamp"`
+	StageTimestamp           time.Time       `json:"stageTimestamp"`
+}
+
+// ParseEvents reads audit events, one JSON object per line. Blank lines
+// are skipped.
+func ParseEvents(r io.Reader) ([]Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	var events []Event
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(raw, &ev); err != nil {
+			return nil, fmt.Errorf("k8saudit: line %d: %w", line, err)
+		}
+		events = append(events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("k8saudit: read events: %w", err)
+	}
+	return events, nil
+}
+
+// Mutation is a successful write observed in the audit log.
+type Mutation struct {
+	AuditID        string
+	User           string
+	Verb           string
+	Operation      string
+	OperationClass string
+	Resource       canon.ResourceID
+	// Plural is the audit resource name, e.g. "deployments".
+	Plural string
+	Time   time.Time
+}
+
+// Describe renders the mutation for signal details.
+func (m Mutation) Describe() string {
+	name := m.Resource.Name
+	if m.Resource.Namespace != "" {
+		name = m.Resource.Namespace + "/" + name
+	}
+	return fmt.Sprintf("%s %s %s by %s at %s (audit %s)",
+		m.Verb, m.Resource.Kind, name, m.User, m.Time.UTC().Format(time.RFC3339), m.AuditID)
+}
+
+// FilterOptions selects which events count as mutations.
+type FilterOptions struct {
+	// IgnoreUsers skips events whose effective username has one of these
+	// prefixes, e.g. built-in controllers.
+	IgnoreUsers []string
+}
+
+// DefaultIgnoreUsers skips the control plane's own writes. Agents reach
+// the API server as users or service accounts outside kube-system.
+var DefaultIgnoreUsers = []string{
+	"system:kube-controller-manager",
+	"system:kube-scheduler",
+	"system:apiserver",
+	"system:node:",
+	"system:serviceaccount:kube-system:",
+}
+
+// Mutations extracts completed, successful, non-dry-run writes to named
+// resources. Status subresource updates are controller bookkeeping and are
+// skipped; other subresources (scale, eviction) count against their parent.
+func Mutations(events []Event, opts FilterOptions) []Mutation {
+	var out []Mutation
+	seen := make(map[string]bool)
+	for _, ev := range events {
+		operation, ok := writeVerbs[ev.Verb]
+		if !ok || ev.ObjectRef == nil || ev.ObjectRef.Resource == "" {
+			continue
+		}
+		if ev.Stage != "" && ev.Stage != "ResponseComplete" {
+			continue
+		}
+		if ev.ResponseStatus != nil && ev.ResponseStatus.Code >= 300 {
+			continue
+		}
+		if ev.ObjectRef.Subresource == "status" || strings.Contains(ev.RequestURI, "dryRun=") {
+			continue
+		}
+		user := effectiveUser(ev)
+		if ignored(user, opts.IgnoreUsers) {
+			continue
+		}
+		if ev.AuditID != "" {
+			if seen[ev.AuditID] {
+				continue
+			}
+			seen[ev.AuditID] = true
+		}
+
+		ts := ev.StageTimestamp
+		if ts.IsZero() {
+			ts = ev.RequestReceivedTimestamp
+		}
+		out = append(out, Mutation{
+			AuditID:        ev.AuditID,
+			User:           user,
+			Verb:           ev.Verb,
+			Operation:      operation,
+			OperationClass: operationClasses[operation],
+			Resource:       resourceID(ev),
+			Plural:         ev.ObjectRef.Resource,
+			Time:           ts,
+		})
+	}
+	return out
+}
+
+func effectiveUser(ev Event) string {
+	if ev.ImpersonatedUser != nil && ev.ImpersonatedUser.Username != "" {
+		return ev.ImpersonatedUser.Username
+	}
+	if ev.User.Username == "" {
+		return "unknown"
+	}
+	return ev.User.Username
+}
+
+func ignored(user string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if p != "" && strings.HasPrefix(user, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceID builds an identity with the same normalization the k8s
+// adapter applies to manifests: lowercase kind, namespace and name.
+func resourceID(ev Event) canon.ResourceID {
+	ref := ev.ObjectRef
+	apiVersion := ref.APIVersion
+	if ref.APIGroup != "" {
+		apiVersion = ref.APIGroup + "/" + ref.APIVersion
+	}
+
+	meta := objectMeta(ev.RequestObject)
+	if meta.Kind == "" || meta.Metadata.Name == "" {
+		resp := objectMeta(ev.ResponseObject)
+		if meta.Kind == "" {
+			meta.Kind = resp.Kind
+		}
+		if meta.Metadata.Name == "" {
+			meta.Metadata.Name = resp.Metadata.Name
+		}
+	}
+	kind := meta.Kind
+	// Deletes and subresource writes carry envelope kinds (DeleteOptions,
+	// Status, Scale) rather than the kind of the resource they target.
+	if kind == "" || envelopeKinds[kind] || ref.Subresource != "" {
+		kind = singular(ref.Resource)
+	}
+	name := ref.Name
+	if name == "" {
+		name = meta.Metadata.Name
+	}
+
+	return canon.ResourceID{
+		APIVersion: strings.ToLower(strings.TrimSpace(apiVersion)),
+		Kind:       strings.ToLower(strings.TrimSpace(kind)),
+		Namespace:  strings.ToLower(strings.TrimSpace(ref.Namespace)),
+		Name:       strings.ToLower(strings.TrimSpace(name)),
+	}
+}
+
+type auditObject struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+}
+
+func objectMeta(raw json.RawMessage) auditObject {
+	var obj auditObject
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &obj) // best-effort: identity falls back to objectRef
+	}
+	return obj
+}
+
+func singular(resource string) string {
+	resource = strings.ToLower(resource)
+	if kind, ok := irregularKinds[resource]; ok {
+		return kind
+	}
+	switch {
+	case strings.HasSuffix(resource, "ies"):
+		return strings.TrimSuffix(resource, "ies") + "y"
+	case strings.HasSuffix(resource, "sses"), strings.HasSuffix(resource, "ches"),
+		strings.HasSuffix(resource, "shes"), strings.HasSuffix(resource, "xes"):
+		return strings.TrimSuffix(resource, "es")
+	default:
+		return strings.TrimSuffix(resource, "s")
+	}
+}
+
+// ReconcileOptions controls how mutations are matched to prescriptions.
+type ReconcileOptions struct {
+	// Window overrides each prescription's TTL as the time after it during
+	// which a matching write is covered; zero uses the stored TTL.
+	Window time.Duration
+	// Skew tolerates clock differences between the API server and the
+	// hosts that wrote the prescriptions.
+	Skew time.Duration
+}
+
+// Result is the outcome of reconciling one mutation.
+type Result struct {
+	Mutation Mutation
+	// PrescriptionID is the covering prescription; empty when unprescribed.
+	PrescriptionID string
+}
+
+// Reconcile matches each mutation to a prescription for the same resource
+// and operation class whose window contains the write. Prescriptions
+// written by the admission webhook or this importer record observations,
+// not intent, and never cover a mutation. Prescriptions without a
+// namespace match any namespace, as in the admission webhook.
+func Reconcile(mutations []Mutation, prescriptions []evidence.EvidenceEntry, opts ReconcileOptions) []Result {
+	type candidate struct {
+		entry  evidence.EvidenceEntry
+		action canon.CanonicalAction
+		until  time.Time
+	}
+	var candidates []candidate
+	for _, e := range prescriptions {
+		if e.Type != evidence.EntryTypePrescribe || e.Actor.Provenance == "admission" || e.Actor.Provenance == Provenance {
+			continue
+		}
+		var payload evidence.PrescriptionPayload
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			continue
+		}
+		var action canon.CanonicalAction
+		if err := json.Unmarshal(payload.CanonicalAction, &action); err != nil {
+			continue
+		}
+		window := opts.Window
+		if window <= 0 {
+			ttl := payload.TTLMs
+			if ttl <= 0 {
+				ttl = evidence.DefaultTTLMs
+			}
+			window = time.Duration(ttl) * time.Millisecond
+		}
+		candidates = append(candidates, candidate{entry: e, action: action, until: e.Timestamp.Add(window)})
+	}
+
+	results := make([]Result, len(mutations))
+	for i, m := range mutations {
+		results[i] = Result{Mutation: m}
+		// Prefer the newest covering prescription.
+		for j := len(candidates) - 1; j >= 0; j-- {
+			c := candidates[j]
+			if m.Time.Before(c.entry.Timestamp.Add(-opts.Skew)) || m.Time.After(c.until.Add(opts.Skew)) {
+				continue
+			}
+			if c.action.OperationClass != m.OperationClass || !covers(c.action.ResourceIdentity, m.Resource) {
+				continue
+			}
+			results[i].PrescriptionID = c.entry.EntryID
+			break
+		}
+	}
+	return results
+}
+
+// covers reports whether any prescribed identity names the mutated
+// resource. Writes without a name (deletecollection, generateName creates)
+// match any prescribed resource of the same kind and namespace.
+func covers(ids []canon.ResourceID, target canon.ResourceID) bool {
+	for _, r := range ids {
+		if r.Kind != target.Kind {
+			continue
+		}
+		if r.Namespace != "" && r.Namespace != target.Namespace {
+			continue
+		}
+		if target.Name == "" || r.Name == target.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Actor maps the mutating user the way the admission webhook maps
+// requesters, with audit provenance.
+func (m Mutation) Actor() evidence.Actor {
+	actor := evidence.Actor{Type: "human", ID: m.User, Provenance: Provenance}
+	switch {
+	case strings.HasPrefix(m.User, "system:serviceaccount:"):
+		actor.Type = "service_account"
+	case strings.HasPrefix(m.User, "system:"):
+		actor.Type = "controller"
+	}
+	return actor
+}
+
+// SignalPayload builds the unprescribed_observed payload for m. Observed
+// mutations have no chain entry to reference, so EntryRefs is empty.
+func (m Mutation) SignalPayload() evidence.SignalPayload {
+	return evidence.SignalPayload{
+		SignalName: "protocol_violation",
+		SubSignal:  SubSignal,
+		EntryRefs:  []string{},
+		Details:    "unprescribed " + m.Describe(),
+	}
+}
+
+// ScopeDimensions describes where the mutation landed.
+func (m Mutation) ScopeDimensions(cluster string) map[string]string {
+	dims := map[string]string{"source_system": Provenance, "resource": m.Plural}
+	if m.Resource.Namespace != "" {
+		dims["namespace"] = m.Resource.Namespace
+	}
+	if cluster != "" {
+		dims["cluster"] = cluster
+	}
+	return dims
+}
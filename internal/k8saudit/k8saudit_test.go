@@ -0,0 +1,201 @@
+package k8saudit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func loadFixture(t *testing.T) []Event {
+	t.Helper()
+	f, err := os.Open(filepath.Join("..", "..", "tests", "testdata", "k8saudit", "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer f.Close()
+	events, err := ParseEvents(f)
+	if err != nil {
+		t.Fatalf("ParseEvents: %v", err)
+	}
+	return events
+}
+
+func prescription(t *testing.T, ts time.Time, provenance, operation string, ids ...canon.ResourceID) evidence.EvidenceEntry {
+	t.Helper()
+	action, _ := json.Marshal(canon.CanonicalAction{
+		Tool:             "kubectl",
+		Operation:        operation,
+		OperationClass:   operationClasses[operation],
+		ResourceIdentity: ids,
+	})
+	payload, _ := json.Marshal(evidence.PrescriptionPayload{CanonicalAction: action, TTLMs: evidence.DefaultTTLMs})
+	e, err := evidence.BuildEntry(evidence.EntryBuildParams{
+		Type:    evidence.EntryTypePrescribe,
+		Actor:   evidence.Actor{Type: "agent", ID: "deployer", Provenance: provenance},
+		Payload: payload,
+		Signer:  testutil.TestSigner(t),
+	})
+	if err != nil {
+		t.Fatalf("BuildEntry: %v", err)
+	}
+	e.Timestamp = ts
+	return e
+}
+
+func TestMutations_Fixture(t *testing.T) {
+	t.Parallel()
+
+	events := loadFixture(t)
+	if len(events) != 10 {
+		t.Fatalf("events = %d, want 10", len(events))
+	}
+	mutations := Mutations(events, FilterOptions{IgnoreUsers: DefaultIgnoreUsers})
+
+	var got []string
+	for _, m := range mutations {
+		got = append(got, m.AuditID+" "+m.Operation+" "+m.OperationClass+" "+m.Resource.Kind+" "+m.Resource.Namespace+"/"+m.Resource.Name+" "+m.User)
+	}
+	want := []string{
+		"a1 patch mutate deployment prod/web system:serviceaccount:agents:deployer",
+		"a2 delete destroy configmap prod/web-config system:serviceaccount:agents:deployer",
+		"a8 create mutate networkpolicy prod/allow-all ops-bot",
+		"a9 delete destroy ingress prod/web system:serviceaccount:agents:deployer",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("mutations:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if mutations[0].Resource.APIVersion != "apps/v1" || mutations[1].Resource.APIVersion != "v1" {
+		t.Fatalf("api versions = %q, %q", mutations[0].Resource.APIVersion, mutations[1].Resource.APIVersion)
+	}
+	if !mutations[0].Time.Equal(time.Date(2026, 10, 18, 10, 0, 0, 120000000, time.UTC)) {
+		t.Fatalf("time = %v", mutations[0].Time)
+	}
+}
+
+func TestMutations_IgnoreUsersReplacesDefaults(t *testing.T) {
+	t.Parallel()
+
+	mutations := Mutations(loadFixture(t), FilterOptions{IgnoreUsers: []string{"system:serviceaccount:agents:"}})
+	var ids []string
+	for _, m := range mutations {
+		ids = append(ids, m.AuditID)
+	}
+	if strings.Join(ids, ",") != "a4,a8" {
+		t.Fatalf("audit ids = %v, want [a4 a8]", ids)
+	}
+}
+
+func TestParseEvents_InvalidLine(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseEvents(strings.NewReader("{\"auditID\":\"a1\"}\n\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("err = %v, want line 3 error", err)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	t.Parallel()
+
+	for plural, want := range map[string]string{
+		"deployments":          "deployment",
+		"ingresses":            "ingress",
+		"networkpolicies":      "networkpolicy",
+		"storageclasses":       "storageclass",
+		"endpoints":            "endpoints",
+		"leases":               "lease",
+		"poddisruptionbudgets": "poddisruptionbudget",
+	} {
+		if got := singular(plural); got != want {
+			t.Errorf("singular(%q) = %q, want %q", plural, got, want)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	web := canon.ResourceID{Kind: "deployment", Namespace: "prod", Name: "web"}
+	mutation := Mutation{AuditID: "a1", Operation: "patch", OperationClass: "mutate", Resource: web, Time: base.Add(time.Minute)}
+
+	tests := []struct {
+		name   string
+		m      Mutation
+		rx     []evidence.EvidenceEntry
+		opts   ReconcileOptions
+		wantRx int // index into rx, -1 for unprescribed
+	}{
+		{name: "covered within ttl", m: mutation, rx: []evidence.EvidenceEntry{prescription(t, base, "", "apply", web)}, wantRx: 0},
+		{name: "no prescriptions", m: mutation, wantRx: -1},
+		{
+			name: "expired ttl", m: Mutation{AuditID: "a1", OperationClass: "mutate", Resource: web, Time: base.Add(10 * time.Minute)},
+			rx: []evidence.EvidenceEntry{prescription(t, base, "", "apply", web)}, wantRx: -1,
+		},
+		{
+			name: "window overrides ttl", m: Mutation{AuditID: "a1", OperationClass: "mutate", Resource: web, Time: base.Add(10 * time.Minute)},
+			rx: []evidence.EvidenceEntry{prescription(t, base, "", "apply", web)}, opts: ReconcileOptions{Window: time.Hour}, wantRx: 0,
+		},
+		{
+			name: "write before prescription within skew", m: Mutation{AuditID: "a1", OperationClass: "mutate", Resource: web, Time: base.Add(-10 * time.Second)},
+			rx: []evidence.EvidenceEntry{prescription(t, base, "", "apply", web)}, opts: ReconcileOptions{Skew: 30 * time.Second}, wantRx: 0,
+		},
+		{name: "operation class differs", m: mutation, rx: []evidence.EvidenceEntry{prescription(t, base, "", "delete", web)}, wantRx: -1},
+		{
+			name: "other resource", m: mutation, wantRx: -1,
+			rx: []evidence.EvidenceEntry{prescription(t, base, "", "apply", canon.ResourceID{Kind: "deployment", Namespace: "prod", Name: "api"})},
+		},
+		{
+			name: "prescription without namespace", m: mutation, wantRx: 0,
+			rx: []evidence.EvidenceEntry{prescription(t, base, "", "apply", canon.ResourceID{Kind: "deployment", Name: "web"})},
+		},
+		{name: "admission prescription does not cover", m: mutation, rx: []evidence.EvidenceEntry{prescription(t, base, "admission", "apply", web)}, wantRx: -1},
+		{
+			name: "newest covering prescription wins", m: mutation, wantRx: 1,
+			rx: []evidence.EvidenceEntry{prescription(t, base, "", "apply", web), prescription(t, base.Add(30*time.Second), "", "apply", web)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			results := Reconcile([]Mutation{tt.m}, tt.rx, tt.opts)
+			want := ""
+			if tt.wantRx >= 0 {
+				want = tt.rx[tt.wantRx].EntryID
+			}
+			if len(results) != 1 || results[0].PrescriptionID != want {
+				t.Fatalf("results = %+v, want prescription %q", results, want)
+			}
+		})
+	}
+}
+
+func TestMutation_SignalPayloadAndActor(t *testing.T) {
+	t.Parallel()
+
+	m := Mutation{
+		AuditID: "a2", User: "system:serviceaccount:agents:deployer", Verb: "delete", Plural: "configmaps",
+		Resource: canon.ResourceID{Kind: "configmap", Namespace: "prod", Name: "web-config"},
+		Time:     time.Date(2026, 10, 18, 10, 2, 0, 0, time.UTC),
+	}
+	p := m.SignalPayload()
+	if p.SignalName != "protocol_violation" || p.SubSignal != SubSignal || p.EntryRefs == nil {
+		t.Fatalf("payload = %+v", p)
+	}
+	if p.Details != "unprescribed delete configmap prod/web-config by system:serviceaccount:agents:deployer at 2026-10-18T10:02:00Z (audit a2)" {
+		t.Fatalf("details = %q", p.Details)
+	}
+	if a := m.Actor(); a.Type != "service_account" || a.Provenance != Provenance || a.ID != m.User {
+		t.Fatalf("actor = %+v", a)
+	}
+	if dims := m.ScopeDimensions("prod-eu"); dims["cluster"] != "prod-eu" || dims["namespace"] != "prod" || dims["resource"] != "configmaps" {
+		t.Fatalf("scope = %v", dims)
+	}
+}
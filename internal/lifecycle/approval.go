@@ -0,0 +1,272 @@
+package lifecycle
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/risk"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
+)
+
+// requiresApproval reports whether a prescription at effectiveRisk needs
+// an approval entry under the service's threshold.
+func (s *Service) requiresApproval(effectiveRisk string) bool {
+	if s.approvalRisk == "" {
+		return false
+	}
+	return effectiveRisk == s.approvalRisk || risk.SeverityHigherThan(effectiveRisk, s.approvalRisk)
+}
+
+// Approve records an approver's decision on a prescription that requires
+// approval. The entry is signed with the service's signer, which must not
+// be the key that signed the prescription: an agent cannot approve its
+// own operation. When approvers are configured the signer must be one of
+// them. Session and trace are inherited from the prescription.
+func (s *Service) Approve(_ context.Context, input ApproveInput) (ApproveOutput, error) {
+	if err := requiredSigner(s.signer); err != nil {
+		return ApproveOutput{}, err
+	}
+	prescriptionID := strings.TrimSpace(input.PrescriptionID)
+	if prescriptionID == "" {
+		return ApproveOutput{}, wrapError(ErrCodeInvalidInput, "prescription_id is required", nil)
+	}
+	decision := strings.TrimSpace(input.Decision)
+	if decision != evidence.ApprovalApproved && decision != evidence.ApprovalRejected {
+		return ApproveOutput{}, wrapError(ErrCodeInvalidInput, fmt.Sprintf("decision must be %q or %q", evidence.ApprovalApproved, evidence.ApprovalRejected), nil)
+	}
+	actor := normalizeActor(input.Actor)
+	if actor.ID == "" {
+		return ApproveOutput{}, wrapError(ErrCodeInvalidInput, "actor.id is required", nil)
+	}
+	if actor.Type == "" {
+		actor.Type = "human"
+	}
+
+	prescription, err := s.loadApprovalPrescription(input.Prescription, prescriptionID)
+	if err != nil {
+		return ApproveOutput{}, err
+	}
+	var p evidence.PrescriptionPayload
+	if err := json.Unmarshal(prescription.Payload, &p); err != nil {
+		return ApproveOutput{}, wrapError(ErrCodeInternal, fmt.Sprintf("failed to decode prescription %s", prescriptionID), err)
+	}
+	if !p.ApprovalRequired {
+		return ApproveOutput{}, wrapError(ErrCodeInvalidInput, fmt.Sprintf("prescription %s does not require approval", prescriptionID), nil)
+	}
+	var approverKey string
+	if pub := s.signer.PublicKey(); len(pub) == ed25519.PublicKeySize {
+		approverKey = evidence.KeyID(pub)
+	}
+	if approverKey != "" && prescription.KeyID == approverKey {
+		return ApproveOutput{}, wrapError(ErrCodeInvalidInput, "approval must be signed by a key other than the one that signed the prescription", nil)
+	}
+	if s.approvers != nil {
+		if _, ok := s.approvers.Lookup(approverKey); !ok {
+			return ApproveOutput{}, wrapError(ErrCodeInvalidInput, "approval signing key is not in the approver keyring", nil)
+		}
+	}
+	if s.evidencePath != "" {
+		if err := checkAwaitingApproval(s.evidencePath, prescription, s.approvers); err != nil {
+			return ApproveOutput{}, err
+		}
+	}
+
+	payloadJSON, err := json.Marshal(evidence.ApprovalPayload{
+		PrescriptionID: prescriptionID,
+		Decision:       decision,
+		Reason:         strings.TrimSpace(input.Reason),
+	})
+	if err != nil {
+		return ApproveOutput{}, wrapError(ErrCodeInternal, "failed to marshal approval payload", err)
+	}
+
+	entry, state, err := s.recordEntry(evidence.EntryBuildParams{
+		Type:           evidence.EntryTypeApproval,
+		SessionID:      prescription.SessionID,
+		OperationID:    strings.TrimSpace(input.OperationID),
+		TraceID:        prescription.TraceID,
+		Actor:          actor,
+		Payload:        payloadJSON,
+		SpecVersion:    version.SpecVersion,
+		AdapterVersion: version.Version,
+		ScoringVersion: version.ScoringVersion,
+		Signer:         s.signer,
+	})
+	if err != nil {
+		return ApproveOutput{}, err
+	}
+
+	rawEntry, err := json.Marshal(entry)
+	if err != nil {
+		return ApproveOutput{}, wrapError(ErrCodeInternal, "failed to marshal evidence entry", err)
+	}
+
+	return ApproveOutput{
+		ApprovalID:     entry.EntryID,
+		SessionID:      prescription.SessionID,
+		TraceID:        prescription.TraceID,
+		Actor:          actor,
+		PrescriptionID: prescriptionID,
+		Decision:       decision,
+		Entry:          entry,
+		RawEntry:       rawEntry,
+		Persisted:      state == entrySealed,
+		Staged:         state == entryStaged,
+	}, nil
+}
+
+// loadApprovalPrescription returns the supplied prescription, or reads it
+// from the local evidence chain when none was supplied.
+func (s *Service) loadApprovalPrescription(supplied *evidence.EvidenceEntry, prescriptionID string) (evidence.EvidenceEntry, error) {
+	if supplied != nil {
+		if supplied.EntryID != prescriptionID || supplied.Type != evidence.EntryTypePrescribe {
+			return evidence.EvidenceEntry{}, wrapError(ErrCodeInvalidInput, "supplied entry is not the prescription being approved", nil)
+		}
+		return *supplied, nil
+	}
+	if s.evidencePath == "" {
+		return evidence.EvidenceEntry{}, wrapError(ErrCodeNotFound, "prescription_id not found", nil)
+	}
+	entry, ok, err := evidence.FindEntryByID(s.evidencePath, prescriptionID)
+	if err != nil {
+		return evidence.EvidenceEntry{}, wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	if !ok || entry.Type != evidence.EntryTypePrescribe {
+		return evidence.EvidenceEntry{}, wrapError(ErrCodeNotFound, "prescription_id not found", nil)
+	}
+	return entry, nil
+}
+
+// checkAwaitingApproval rejects a decision on a prescription that is
+// already closed or already decided by one of approvers.
+func checkAwaitingApproval(evidencePath string, prescription evidence.EvidenceEntry, approvers *evidence.Keyring) error {
+	closedBy, err := closingEntry(evidencePath, prescription.EntryID, prescription.SessionID)
+	if err != nil {
+		return err
+	}
+	if closedBy != nil {
+		return wrapError(ErrCodeInvalidInput, fmt.Sprintf("prescription %s is already closed by %s %s", prescription.EntryID, closedBy.Type, closedBy.EntryID), nil)
+	}
+	decision, err := ApprovalDecisionAtPath(evidencePath, prescription.SessionID, prescription.EntryID, approvers)
+	if err != nil {
+		return err
+	}
+	if decision != "" {
+		return wrapError(ErrCodeInvalidInput, fmt.Sprintf("prescription %s is already %s", prescription.EntryID, decision), nil)
+	}
+	return nil
+}
+
+// approvalMissing reports whether the prescription requires approval and
+// the local evidence chain holds no approved decision for it.
+func (s *Service) approvalMissing(prescription evidence.EvidenceEntry) (bool, error) {
+	var p evidence.PrescriptionPayload
+	if err := json.Unmarshal(prescription.Payload, &p); err != nil || !p.ApprovalRequired {
+		return false, nil
+	}
+	decision, err := s.ApprovalDecision(prescription.SessionID, prescription.EntryID)
+	if err != nil {
+		return false, err
+	}
+	return decision != evidence.ApprovalApproved, nil
+}
+
+// ApprovalDecision returns the first decision on the prescription in the
+// local evidence chain that is signed by one of the service's approvers.
+func (s *Service) ApprovalDecision(sessionID, prescriptionID string) (string, error) {
+	return ApprovalDecisionAtPath(s.evidencePath, sessionID, prescriptionID, s.approvers)
+}
+
+// ApprovalDecisionAtPath returns the first decision recorded at
+// evidencePath on the prescription by one of approvers, or "" when it has
+// none; see ApprovalDecision.
+func ApprovalDecisionAtPath(evidencePath, sessionID, prescriptionID string, approvers *evidence.Keyring) (string, error) {
+	entries, err := evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		SessionID: sessionID,
+		Types:     []evidence.EntryType{evidence.EntryTypePrescribe, evidence.EntryTypeApproval},
+	})
+	if err != nil {
+		return "", wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	return ApprovalDecision(entries, prescriptionID, approvers), nil
+}
+
+// ApprovalDecision returns the first decision on the prescription among
+// entries, or "" when it has none. The prescription must be among entries.
+// A decision counts only when its signature verifies against a key in
+// approvers and that key is not the one that signed the prescription, so
+// an agent cannot approve itself with a fresh key or a claimed key_id.
+// With no approvers, no decision counts.
+func ApprovalDecision(entries []evidence.EvidenceEntry, prescriptionID string, approvers *evidence.Keyring) string {
+	if approvers == nil {
+		return ""
+	}
+	var prescriptionKey string
+	found := false
+	for _, e := range entries {
+		if e.Type == evidence.EntryTypePrescribe && e.EntryID == prescriptionID {
+			prescriptionKey = e.KeyID
+			if kid, err := evidence.SignerKeyID(e, approvers); err == nil {
+				prescriptionKey = kid
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ""
+	}
+	for _, e := range entries {
+		if e.Type != evidence.EntryTypeApproval {
+			continue
+		}
+		var a evidence.ApprovalPayload
+		if json.Unmarshal(e.Payload, &a) != nil || a.PrescriptionID != prescriptionID {
+			continue
+		}
+		if signer, err := evidence.SignerKeyID(e, approvers); err == nil && signer != prescriptionKey {
+			return a.Decision
+		}
+	}
+	return ""
+}
+
+// PendingApprovals returns the open prescriptions among entries that
+// require approval and have no decision yet, oldest first. approvers is
+// passed to ApprovalDecision.
+func PendingApprovals(entries []evidence.EvidenceEntry, now time.Time, approvers *evidence.Keyring) ([]PendingPrescription, error) {
+	open, err := OpenPrescriptions(entries, now)
+	if err != nil {
+		return nil, err
+	}
+	var pending []PendingPrescription
+	for _, p := range open {
+		if p.ApprovalRequired && ApprovalDecision(entries, p.PrescriptionID, approvers) == "" {
+			pending = append(pending, p)
+		}
+	}
+	return pending, nil
+}
+
+// ListPendingApprovals returns the prescriptions at evidencePath awaiting
+// a decision from one of approvers. Empty sessionID matches every session.
+func ListPendingApprovals(evidencePath, sessionID string, now time.Time, approvers *evidence.Keyring) ([]PendingPrescription, error) {
+	entries, err := evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		SessionID: sessionID,
+		Types: []evidence.EntryType{
+			evidence.EntryTypePrescribe,
+			evidence.EntryTypeReport,
+			evidence.EntryTypeCancel,
+			evidence.EntryTypeApproval,
+		},
+	})
+	if err != nil {
+		return nil, wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	return PendingApprovals(entries, now, approvers)
+}
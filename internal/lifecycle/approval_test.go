@@ -0,0 +1,221 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestServiceApprove_GatesCriticalPrescription(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	approverSigner := testutil.TestSigner(t)
+	approvers := evidence.NewKeyring(approverSigner.PublicKey())
+	agent := NewService(Options{
+		EvidencePath: dir,
+		Signer:       testutil.TestSigner(t),
+		ApprovalRisk: "high",
+		Approvers:    approvers,
+	})
+	approver := NewService(Options{
+		EvidencePath: dir,
+		Signer:       approverSigner,
+		Approvers:    approvers,
+	})
+	// An agent that signs with a fresh key is not an approver.
+	outsider := NewService(Options{
+		EvidencePath: dir,
+		Signer:       testutil.TestSigner(t),
+		Approvers:    approvers,
+	})
+	prescribe := func(artifact string) PrescribeOutput {
+		t.Helper()
+		out, err := agent.Prescribe(context.Background(), PrescribeInput{
+			Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+			Tool:        "kubectl",
+			Operation:   "delete",
+			RawArtifact: []byte(artifact),
+			SessionID:   "sess-1",
+		})
+		if err != nil {
+			t.Fatalf("Prescribe: %v", err)
+		}
+		return out
+	}
+	critical := prescribe("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n")
+	if !critical.ApprovalRequired {
+		t.Fatalf("prescription at %s risk does not require approval", critical.EffectiveRisk)
+	}
+	rejected := prescribe("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: staging\n")
+
+	pending, err := ListPendingApprovals(dir, "sess-1", time.Now(), approvers)
+	if err != nil {
+		t.Fatalf("ListPendingApprovals: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("pending approvals = %+v, want 2", pending)
+	}
+
+	approverActor := evidence.Actor{ID: "alice"}
+	if _, err := agent.Approve(context.Background(), ApproveInput{
+		PrescriptionID: critical.PrescriptionID,
+		Decision:       evidence.ApprovalApproved,
+		Actor:          approverActor,
+	}); ErrorCode(err) != ErrCodeInvalidInput {
+		t.Fatalf("self-approval err = %v, want invalid_input", err)
+	}
+	if _, err := outsider.Approve(context.Background(), ApproveInput{
+		PrescriptionID: critical.PrescriptionID,
+		Decision:       evidence.ApprovalApproved,
+		Actor:          approverActor,
+	}); ErrorCode(err) != ErrCodeInvalidInput {
+		t.Fatalf("approval by a key outside the keyring err = %v, want invalid_input", err)
+	}
+	if _, err := approver.Approve(context.Background(), ApproveInput{
+		PrescriptionID: critical.PrescriptionID,
+		Decision:       "maybe",
+		Actor:          approverActor,
+	}); ErrorCode(err) != ErrCodeInvalidInput {
+		t.Fatalf("unknown decision err = %v, want invalid_input", err)
+	}
+
+	out, err := approver.Approve(context.Background(), ApproveInput{
+		PrescriptionID: critical.PrescriptionID,
+		Decision:       evidence.ApprovalApproved,
+		Reason:         "change window CHG-1",
+		Actor:          approverActor,
+	})
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if out.Entry.Type != evidence.EntryTypeApproval || out.SessionID != "sess-1" || out.TraceID != critical.TraceID || out.Actor.Type != "human" {
+		t.Errorf("approval entry type=%q session=%q trace=%q actor=%+v", out.Entry.Type, out.SessionID, out.TraceID, out.Actor)
+	}
+	if out.Entry.KeyID == critical.Entry.KeyID {
+		t.Error("approval signed with the prescriber's key")
+	}
+	if _, err := approver.Approve(context.Background(), ApproveInput{
+		PrescriptionID: critical.PrescriptionID,
+		Decision:       evidence.ApprovalRejected,
+		Actor:          approverActor,
+	}); ErrorCode(err) != ErrCodeInvalidInput {
+		t.Fatalf("second decision err = %v, want invalid_input", err)
+	}
+	if _, err := approver.Approve(context.Background(), ApproveInput{
+		PrescriptionID: rejected.PrescriptionID,
+		Decision:       evidence.ApprovalRejected,
+		Actor:          approverActor,
+	}); err != nil {
+		t.Fatalf("Approve reject: %v", err)
+	}
+	if pending, _ := ListPendingApprovals(dir, "sess-1", time.Now(), approvers); len(pending) != 0 {
+		t.Errorf("pending approvals after decisions = %+v", pending)
+	}
+
+	for _, tc := range []struct {
+		id   string
+		want bool
+	}{
+		{critical.PrescriptionID, false},
+		{rejected.PrescriptionID, true},
+	} {
+		report, err := agent.Report(context.Background(), ReportInput{
+			PrescriptionID: tc.id,
+			Verdict:        evidence.VerdictSuccess,
+			ExitCode:       intPtr(0),
+		})
+		if err != nil {
+			t.Fatalf("Report: %v", err)
+		}
+		if report.ApprovalMissing != tc.want {
+			t.Errorf("report for %s ApprovalMissing = %v, want %v", tc.id, report.ApprovalMissing, tc.want)
+		}
+	}
+}
+
+func TestServicePrescribe_ApprovalThreshold(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(Options{Signer: testutil.TestSigner(t), ApprovalRisk: "critical"})
+	out, err := svc.Prescribe(context.Background(), PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: dev\n"),
+	})
+	if err != nil {
+		t.Fatalf("Prescribe: %v", err)
+	}
+	if out.ApprovalRequired {
+		t.Errorf("%s prescription requires approval under a critical threshold", out.EffectiveRisk)
+	}
+}
+
+func TestApprovalDecision_IgnoresApprovalsByThePrescriptionKey(t *testing.T) {
+	t.Parallel()
+
+	agentSigner, approverSigner := testutil.TestSigner(t), testutil.TestSigner(t)
+	agent := NewService(Options{Signer: agentSigner, ApprovalRisk: "high"})
+	rx, err := agent.Prescribe(context.Background(), PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "delete",
+		RawArtifact: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n"),
+		SessionID:   "sess-1",
+	})
+	if err != nil || !rx.ApprovalRequired {
+		t.Fatalf("Prescribe = %+v, %v; want approval_required", rx, err)
+	}
+	approval, err := NewService(Options{Signer: approverSigner}).Approve(context.Background(), ApproveInput{
+		PrescriptionID: rx.PrescriptionID,
+		Decision:       evidence.ApprovalApproved,
+		Actor:          evidence.Actor{ID: "alice"},
+		Prescription:   &rx.Entry,
+	})
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	// The agent signs an approval of its own operation; Approve refuses, so
+	// build the entry directly.
+	selfApproval, err := evidence.BuildEntry(evidence.EntryBuildParams{
+		Type:      evidence.EntryTypeApproval,
+		SessionID: rx.SessionID,
+		Actor:     evidence.Actor{ID: "agent-1"},
+		Payload:   approval.Entry.Payload,
+		Signer:    agentSigner,
+	})
+	if err != nil {
+		t.Fatalf("BuildEntry: %v", err)
+	}
+	// An approval claiming another key_id than the one that signed it.
+	mislabeled := selfApproval
+	mislabeled.KeyID = approval.Entry.KeyID
+
+	keyring := evidence.NewKeyring(agentSigner.PublicKey(), approverSigner.PublicKey())
+	agentOnly := evidence.NewKeyring(agentSigner.PublicKey())
+	tests := []struct {
+		name    string
+		entries []evidence.EvidenceEntry
+		keyring *evidence.Keyring
+		want    string
+	}{
+		{name: "approver key", entries: []evidence.EvidenceEntry{rx.Entry, approval.Entry}, keyring: keyring, want: evidence.ApprovalApproved},
+		{name: "no approver keyring", entries: []evidence.EvidenceEntry{rx.Entry, approval.Entry}},
+		{name: "approver key outside keyring", entries: []evidence.EvidenceEntry{rx.Entry, approval.Entry}, keyring: agentOnly},
+		{name: "prescription key", entries: []evidence.EvidenceEntry{rx.Entry, selfApproval}, keyring: keyring},
+		{name: "mislabeled key", entries: []evidence.EvidenceEntry{rx.Entry, mislabeled}, keyring: keyring},
+		{name: "prescription absent", entries: []evidence.EvidenceEntry{approval.Entry}, keyring: keyring},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ApprovalDecision(tt.entries, rx.PrescriptionID, tt.keyring); got != tt.want {
+				t.Fatalf("ApprovalDecision = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
+)
+
+// Cancel withdraws an open prescription. The cancellation closes it the
+// same way a report does, so it is neither unreported nor reportable
+// afterwards without a duplicate_report violation.
+func (s *Service) Cancel(_ context.Context, input CancelInput) (CancelOutput, error) {
+	if err := requiredSigner(s.signer); err != nil {
+		return CancelOutput{}, err
+	}
+	prescriptionID := strings.TrimSpace(input.PrescriptionID)
+	if prescriptionID == "" {
+		return CancelOutput{}, wrapError(ErrCodeInvalidInput, "prescription_id is required", nil)
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return CancelOutput{}, wrapError(ErrCodeInvalidInput, "reason is required", nil)
+	}
+
+	var prescription evidence.EvidenceEntry
+	found := false
+	if s.evidencePath != "" {
+		entry, ok, err := evidence.FindEntryByID(s.evidencePath, prescriptionID)
+		if err != nil {
+			return CancelOutput{}, wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+		}
+		if !ok || entry.Type != evidence.EntryTypePrescribe {
+			return CancelOutput{}, wrapError(ErrCodeNotFound, "prescription_id not found", nil)
+		}
+		closedBy, err := closingEntry(s.evidencePath, prescriptionID, entry.SessionID)
+		if err != nil {
+			return CancelOutput{}, err
+		}
+		if closedBy != nil {
+			return CancelOutput{}, wrapError(ErrCodeInvalidInput, fmt.Sprintf("prescription %s is already closed by %s %s", prescriptionID, closedBy.Type, closedBy.EntryID), nil)
+		}
+		prescription, found = entry, true
+	}
+
+	ctx := resolveReportContext(ReportInput{
+		Actor:       input.Actor,
+		SessionID:   input.SessionID,
+		OperationID: input.OperationID,
+	}, prescription, found)
+	if err := ctx.validate(found, prescription.SessionID); err != nil {
+		return CancelOutput{}, err
+	}
+
+	payloadJSON, err := json.Marshal(evidence.CancelPayload{
+		PrescriptionID: prescriptionID,
+		Reason:         reason,
+	})
+	if err != nil {
+		return CancelOutput{}, wrapError(ErrCodeInternal, "failed to marshal cancel payload", err)
+	}
+
+	entry, state, err := s.recordEntry(evidence.EntryBuildParams{
+		Type:           evidence.EntryTypeCancel,
+		SessionID:      ctx.sessionID,
+		OperationID:    ctx.operationID,
+		TraceID:        ctx.traceID,
+		SpanID:         strings.TrimSpace(input.SpanID),
+		ParentSpanID:   strings.TrimSpace(input.ParentSpanID),
+		Actor:          ctx.actor,
+		Payload:        payloadJSON,
+		SpecVersion:    version.SpecVersion,
+		AdapterVersion: version.Version,
+		ScoringVersion: version.ScoringVersion,
+		Signer:         s.signer,
+	})
+	if err != nil {
+		return CancelOutput{}, err
+	}
+
+	rawEntry, err := json.Marshal(entry)
+	if err != nil {
+		return CancelOutput{}, wrapError(ErrCodeInternal, "failed to marshal evidence entry", err)
+	}
+
+	return CancelOutput{
+		CancelID:       entry.EntryID,
+		SessionID:      ctx.sessionID,
+		TraceID:        ctx.traceID,
+		Actor:          ctx.actor,
+		PrescriptionID: prescriptionID,
+		Reason:         reason,
+		Entry:          entry,
+		RawEntry:       rawEntry,
+		Persisted:      state == entrySealed,
+		Staged:         state == entryStaged,
+	}, nil
+}
+
+// closingEntry returns the first report or cancellation for the
+// prescription, or nil while it is still open.
+func closingEntry(evidencePath, prescriptionID, sessionID string) (*evidence.EvidenceEntry, error) {
+	entries, err := evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		SessionID: sessionID,
+		Types:     []evidence.EntryType{evidence.EntryTypeReport, evidence.EntryTypeCancel},
+	})
+	if err != nil {
+		return nil, wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	for i := range entries {
+		if closedPrescriptionID(entries[i]) == prescriptionID {
+			return &entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// closedPrescriptionID returns the prescription a report or cancellation
+// closes, or "" for any other entry.
+func closedPrescriptionID(entry evidence.EvidenceEntry) string {
+	switch entry.Type {
+	case evidence.EntryTypeReport:
+		var p evidence.ReportPayload
+		if json.Unmarshal(entry.Payload, &p) == nil {
+			return p.PrescriptionID
+		}
+	case evidence.EntryTypeCancel:
+		var p evidence.CancelPayload
+		if json.Unmarshal(entry.Payload, &p) == nil {
+			return p.PrescriptionID
+		}
+	}
+	return ""
+}
+
+// ListPending returns the prescriptions at evidencePath that have neither
+// a report nor a cancellation, oldest first. Empty sessionID or actorID
+// match every session or actor.
+func ListPending(evidencePath, sessionID, actorID string, now time.Time) ([]PendingPrescription, error) {
+	entries, err := evidence.QueryEntriesAtPath(evidencePath, evidence.EntryQuery{
+		SessionID: sessionID,
+		Types:     []evidence.EntryType{evidence.EntryTypePrescribe, evidence.EntryTypeReport, evidence.EntryTypeCancel},
+	})
+	if err != nil {
+		return nil, wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	open, err := OpenPrescriptions(entries, now)
+	if err != nil || actorID == "" {
+		return open, err
+	}
+	var pending []PendingPrescription
+	for _, p := range open {
+		if p.ActorID == actorID {
+			pending = append(pending, p)
+		}
+	}
+	return pending, nil
+}
+
+// OpenPrescriptions returns the prescriptions among entries that have
+// neither a report nor a cancellation among them, oldest first. A
+// prescription is expired once now is past its stored TTL; reporting it
+// after that is flagged as late_report.
+func OpenPrescriptions(entries []evidence.EvidenceEntry, now time.Time) ([]PendingPrescription, error) {
+	closed := make(map[string]bool)
+	for _, e := range entries {
+		if id := closedPrescriptionID(e); id != "" {
+			closed[id] = true
+		}
+	}
+
+	var pending []PendingPrescription
+	for _, e := range entries {
+		if e.Type != evidence.EntryTypePrescribe || closed[e.EntryID] {
+			continue
+		}
+		var p evidence.PrescriptionPayload
+		if err := json.Unmarshal(e.Payload, &p); err != nil {
+			return nil, wrapError(ErrCodeInternal, fmt.Sprintf("failed to decode prescription %s", e.EntryID), err)
+		}
+		var action canon.CanonicalAction
+		_ = json.Unmarshal(p.CanonicalAction, &action)
+		age := now.Sub(e.Timestamp)
+		pending = append(pending, PendingPrescription{
+			PrescriptionID:   e.EntryID,
+			SessionID:        e.SessionID,
+			ActorID:          e.Actor.ID,
+			Tool:             action.Tool,
+			Operation:        action.Operation,
+			EffectiveRisk:    p.EffectiveRisk,
+			ApprovalRequired: p.ApprovalRequired,
+			PrescribedAt:     e.Timestamp,
+			AgeMs:            age.Milliseconds(),
+			TTLMs:            p.TTLMs,
+			Expired:          p.TTLMs > 0 && age > time.Duration(p.TTLMs)*time.Millisecond,
+		})
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].PrescribedAt.Before(pending[j].PrescribedAt)
+	})
+	return pending, nil
+}
+
+// isLateReport reports whether a report at reportedAt falls past the
+// prescription's stored TTL.
+func isLateReport(prescription evidence.EvidenceEntry, reportedAt time.Time) bool {
+	var p evidence.PrescriptionPayload
+	if err := json.Unmarshal(prescription.Payload, &p); err != nil || p.TTLMs <= 0 {
+		return false
+	}
+	return reportedAt.Sub(prescription.Timestamp) > time.Duration(p.TTLMs)*time.Millisecond
+}
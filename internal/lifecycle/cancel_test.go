@@ -0,0 +1,110 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestServiceCancel_ClosesPrescription(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	svc := NewService(Options{
+		EvidencePath: dir,
+		Signer:       testutil.TestSigner(t),
+	})
+	actor := evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"}
+	prescribe := func() PrescribeOutput {
+		t.Helper()
+		out, err := svc.Prescribe(context.Background(), PrescribeInput{
+			Actor:       actor,
+			Tool:        "kubectl",
+			Operation:   "apply",
+			RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: prod\n"),
+			SessionID:   "sess-1",
+		})
+		if err != nil {
+			t.Fatalf("Prescribe: %v", err)
+		}
+		return out
+	}
+	cancelled, reported, open := prescribe(), prescribe(), prescribe()
+
+	if _, err := svc.Cancel(context.Background(), CancelInput{PrescriptionID: cancelled.PrescriptionID}); ErrorCode(err) != ErrCodeInvalidInput {
+		t.Fatalf("Cancel without reason err = %v, want invalid_input", err)
+	}
+	if _, err := svc.Cancel(context.Background(), CancelInput{PrescriptionID: "01UNKNOWN", Reason: "x"}); ErrorCode(err) != ErrCodeNotFound {
+		t.Fatalf("Cancel unknown err = %v, want not_found", err)
+	}
+
+	out, err := svc.Cancel(context.Background(), CancelInput{
+		PrescriptionID: cancelled.PrescriptionID,
+		Reason:         " target namespace was wrong ",
+	})
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if out.SessionID != "sess-1" || out.TraceID != cancelled.TraceID || out.Actor.ID != "agent-1" {
+		t.Errorf("cancel session=%q trace=%q actor=%q, want the prescription's", out.SessionID, out.TraceID, out.Actor.ID)
+	}
+	var payload evidence.CancelPayload
+	if err := json.Unmarshal(out.Entry.Payload, &payload); err != nil {
+		t.Fatalf("unmarshal cancel payload: %v", err)
+	}
+	if out.Entry.Type != evidence.EntryTypeCancel || payload.PrescriptionID != cancelled.PrescriptionID || payload.Reason != "target namespace was wrong" {
+		t.Errorf("cancel entry type=%q payload=%+v", out.Entry.Type, payload)
+	}
+
+	if _, err := svc.Report(context.Background(), ReportInput{
+		PrescriptionID: reported.PrescriptionID,
+		Verdict:        evidence.VerdictSuccess,
+		ExitCode:       intPtr(0),
+	}); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	for _, id := range []string{cancelled.PrescriptionID, reported.PrescriptionID} {
+		if _, err := svc.Cancel(context.Background(), CancelInput{PrescriptionID: id, Reason: "again"}); ErrorCode(err) != ErrCodeInvalidInput {
+			t.Errorf("Cancel closed prescription %s err = %v, want invalid_input", id, err)
+		}
+	}
+
+	pending, err := ListPending(dir, "sess-1", "", time.Now())
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].PrescriptionID != open.PrescriptionID {
+		t.Fatalf("pending = %+v, want only %s", pending, open.PrescriptionID)
+	}
+	if p := pending[0]; p.Tool != "kubectl" || p.Operation != "apply" || p.TTLMs != evidence.DefaultTTLMs || p.Expired {
+		t.Errorf("pending entry = %+v", p)
+	}
+	later := time.Now().Add(time.Duration(evidence.DefaultTTLMs+1000) * time.Millisecond)
+	if pending, _ := ListPending(dir, "", "", later); len(pending) != 1 || !pending[0].Expired {
+		t.Errorf("pending past TTL = %+v, want expired", pending)
+	}
+	if pending, _ := ListPending(dir, "", "someone-else", time.Now()); len(pending) != 0 {
+		t.Errorf("pending for another actor = %+v", pending)
+	}
+}
+
+func TestIsLateReport(t *testing.T) {
+	t.Parallel()
+
+	payload, _ := json.Marshal(evidence.PrescriptionPayload{TTLMs: 60000})
+	rx := evidence.EvidenceEntry{Timestamp: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), Payload: payload}
+	if isLateReport(rx, rx.Timestamp.Add(59*time.Second)) {
+		t.Error("report within TTL flagged late")
+	}
+	if !isLateReport(rx, rx.Timestamp.Add(61*time.Second)) {
+		t.Error("report past TTL not flagged late")
+	}
+	noTTL, _ := json.Marshal(evidence.PrescriptionPayload{})
+	if isLateReport(evidence.EvidenceEntry{Timestamp: rx.Timestamp, Payload: noTTL}, rx.Timestamp.Add(time.Hour)) {
+		t.Error("prescription without TTL flagged late")
+	}
+}
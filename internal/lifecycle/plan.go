@@ -0,0 +1,189 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/internal/risk"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
+)
+
+// maxPlanSteps bounds a plan so one entry stays small.
+const maxPlanSteps = 50
+
+// PrescribePlan classifies each planned step and writes one plan entry
+// carrying the aggregate risk. Each step is then prescribed on its own with
+// PlanID and PlanStep set, and reported as usual.
+func (s *Service) PrescribePlan(_ context.Context, input PlanInput) (PlanOutput, error) {
+	if err := requiredSigner(s.signer); err != nil {
+		return PlanOutput{}, err
+	}
+	switch {
+	case len(input.Steps) == 0:
+		return PlanOutput{}, wrapError(ErrCodeInvalidInput, "plan requires at least one step", nil)
+	case len(input.Steps) > maxPlanSteps:
+		return PlanOutput{}, wrapError(ErrCodeInvalidInput, fmt.Sprintf("plan has %d steps; at most %d are allowed", len(input.Steps), maxPlanSteps), nil)
+	}
+
+	ctx, err := buildPrescribeContext(PrescribeInput{
+		Actor:       input.Actor,
+		Environment: input.Environment,
+		SessionID:   input.SessionID,
+		TraceID:     input.TraceID,
+	})
+	if err != nil {
+		return PlanOutput{}, err
+	}
+
+	steps := make([]evidence.PlanStep, 0, len(input.Steps))
+	aggregate := ""
+	for i, in := range input.Steps {
+		step, err := s.planStep(i+1, in, ctx, strings.TrimSpace(input.OperationID))
+		if err != nil {
+			return PlanOutput{}, err
+		}
+		if aggregate == "" || risk.SeverityHigherThan(step.EffectiveRisk, aggregate) {
+			aggregate = step.EffectiveRisk
+		}
+		steps = append(steps, step)
+	}
+
+	planPayload := evidence.PlanPayload{
+		PlanID:        ulid.Make().String(),
+		Steps:         steps,
+		EffectiveRisk: aggregate,
+		TTLMs:         evidence.DefaultTTLMs,
+	}
+	payloadJSON, err := json.Marshal(planPayload)
+	if err != nil {
+		return PlanOutput{}, wrapError(ErrCodeInternal, "failed to marshal plan payload", err)
+	}
+
+	entry, state, err := s.recordEntry(evidence.EntryBuildParams{
+		EntryID:         planPayload.PlanID,
+		Type:            evidence.EntryTypePlan,
+		SessionID:       ctx.sessionID,
+		OperationID:     strings.TrimSpace(input.OperationID),
+		TraceID:         ctx.traceID,
+		SpanID:          strings.TrimSpace(input.SpanID),
+		ParentSpanID:    strings.TrimSpace(input.ParentSpanID),
+		Actor:           ctx.actor,
+		Payload:         payloadJSON,
+		ScopeDimensions: input.ScopeDimensions,
+		SpecVersion:     version.SpecVersion,
+		AdapterVersion:  version.Version,
+		ScoringVersion:  version.ScoringVersion,
+		Signer:          s.signer,
+	})
+	if err != nil {
+		return PlanOutput{}, err
+	}
+
+	rawEntry, err := json.Marshal(entry)
+	if err != nil {
+		return PlanOutput{}, wrapError(ErrCodeInternal, "failed to marshal evidence entry", err)
+	}
+
+	return PlanOutput{
+		PlanID:        entry.EntryID,
+		SessionID:     ctx.sessionID,
+		TraceID:       ctx.traceID,
+		Actor:         ctx.actor,
+		EffectiveRisk: aggregate,
+		Steps:         steps,
+		Entry:         entry,
+		RawEntry:      rawEntry,
+		Persisted:     state == entrySealed,
+		Staged:        state == entryStaged,
+	}, nil
+}
+
+// planStep classifies one step. With an artifact or canonical action it is
+// canonicalized like a prescription; otherwise the class comes from the
+// tool's operation vocabulary and the risk from the matrix alone.
+func (s *Service) planStep(n int, in PlanStepInput, planCtx prescribeContext, operationID string) (evidence.PlanStep, error) {
+	ctx := planCtx
+	ctx.tool = normalizeToken(in.Tool)
+	ctx.operation = normalizeToken(in.Operation)
+	if ctx.tool == "" || ctx.operation == "" {
+		return evidence.PlanStep{}, wrapError(ErrCodeInvalidInput, fmt.Sprintf("plan step %d requires tool and operation", n), nil)
+	}
+	step := evidence.PlanStep{
+		Step:        n,
+		Tool:        ctx.tool,
+		Operation:   ctx.operation,
+		Description: strings.TrimSpace(in.Description),
+	}
+
+	if len(in.RawArtifact) == 0 && in.CanonicalAction == nil {
+		step.OperationClass = canon.ClassifyOperation(ctx.tool, ctx.operation)
+		step.ScopeClass = canon.ResolveScopeClass(ctx.environment, nil)
+		step.EffectiveRisk = risk.RiskLevel(step.OperationClass, step.ScopeClass)
+		return step, nil
+	}
+
+	cr, _, err := s.canonicalizePrescribeInput(PrescribeInput{
+		Tool:            in.Tool,
+		Operation:       in.Operation,
+		RawArtifact:     in.RawArtifact,
+		CanonicalAction: in.CanonicalAction,
+		OperationID:     operationID,
+	}, ctx)
+	if err != nil {
+		return evidence.PlanStep{}, wrapError(ErrorCode(err), fmt.Sprintf("plan step %d: %v", n, err), err)
+	}
+	_, effectiveRisk, _ := buildPrescribeRiskState(cr, in.RawArtifact, nil)
+	step.OperationClass = cr.CanonicalAction.OperationClass
+	step.ScopeClass = cr.CanonicalAction.ScopeClass
+	step.ResourceCount = cr.CanonicalAction.ResourceCount
+	step.IntentDigest = cr.IntentDigest
+	step.ArtifactDigest = cr.ArtifactDigest
+	step.EffectiveRisk = effectiveRisk
+	return step, nil
+}
+
+// resolvePlanLink validates a prescription's plan reference and defaults
+// its session and trace to the plan's.
+func (s *Service) resolvePlanLink(input *PrescribeInput) error {
+	input.PlanID = strings.TrimSpace(input.PlanID)
+	if input.PlanID == "" {
+		if input.PlanStep != 0 {
+			return wrapError(ErrCodeInvalidInput, "plan_step requires plan_id", nil)
+		}
+		return nil
+	}
+	if input.PlanStep < 0 {
+		return wrapError(ErrCodeInvalidInput, "plan_step must not be negative", nil)
+	}
+	if s.evidencePath == "" {
+		return nil
+	}
+
+	entry, found, err := evidence.FindEntryByID(s.evidencePath, input.PlanID)
+	if err != nil {
+		return wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	if !found || entry.Type != evidence.EntryTypePlan {
+		return wrapError(ErrCodeNotFound, "plan_id not found", nil)
+	}
+	var plan evidence.PlanPayload
+	if err := json.Unmarshal(entry.Payload, &plan); err != nil {
+		return wrapError(ErrCodeInternal, "failed to decode plan payload", err)
+	}
+	if input.PlanStep > len(plan.Steps) {
+		return wrapError(ErrCodeInvalidInput, fmt.Sprintf("plan_step %d is out of range; plan %s has %d steps", input.PlanStep, input.PlanID, len(plan.Steps)), nil)
+	}
+	if strings.TrimSpace(input.SessionID) == "" {
+		input.SessionID = entry.SessionID
+	}
+	if strings.TrimSpace(input.TraceID) == "" {
+		input.TraceID = entry.TraceID
+	}
+	return nil
+}
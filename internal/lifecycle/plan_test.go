@@ -0,0 +1,145 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestServicePrescribePlan_AggregatesRiskAndLinksSteps(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	svc := NewService(Options{
+		EvidencePath: dir,
+		Signer:       testutil.TestSigner(t),
+	})
+	actor := evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"}
+
+	plan, err := svc.PrescribePlan(context.Background(), PlanInput{
+		Actor:       actor,
+		Environment: "production",
+		Steps: []PlanStepInput{
+			{
+				Tool:        "kubectl",
+				Operation:   "apply",
+				RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\n"),
+				Description: "update config",
+			},
+			{Tool: "kubectl", Operation: "delete", Description: "remove old deployment"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("PrescribePlan: %v", err)
+	}
+	if plan.PlanID == "" || plan.PlanID != plan.Entry.EntryID || plan.Entry.Type != evidence.EntryTypePlan {
+		t.Fatalf("plan id=%q entry=%q type=%q", plan.PlanID, plan.Entry.EntryID, plan.Entry.Type)
+	}
+	if plan.TraceID != plan.SessionID {
+		t.Fatalf("trace_id=%q, want session_id=%q", plan.TraceID, plan.SessionID)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[0].Step != 1 || plan.Steps[1].Step != 2 {
+		t.Fatalf("steps = %#v", plan.Steps)
+	}
+	if plan.Steps[0].IntentDigest == "" || plan.Steps[0].Description != "update config" {
+		t.Fatalf("step 1 = %#v, want canonicalized step with description", plan.Steps[0])
+	}
+	if plan.Steps[1].OperationClass != "destroy" || plan.Steps[1].ScopeClass != "production" || plan.Steps[1].EffectiveRisk != "critical" {
+		t.Fatalf("step 2 = %#v, want a classified production destroy", plan.Steps[1])
+	}
+	if plan.EffectiveRisk != "critical" {
+		t.Fatalf("plan effective_risk = %q, want the highest step risk", plan.EffectiveRisk)
+	}
+
+	step, err := svc.Prescribe(context.Background(), PrescribeInput{
+		Actor:       actor,
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\n"),
+		PlanID:      plan.PlanID,
+		PlanStep:    1,
+	})
+	if err != nil {
+		t.Fatalf("Prescribe step: %v", err)
+	}
+	if step.SessionID != plan.SessionID || step.TraceID != plan.TraceID {
+		t.Fatalf("step session=%q trace=%q, want the plan's %q/%q", step.SessionID, step.TraceID, plan.SessionID, plan.TraceID)
+	}
+	var payload evidence.PrescriptionPayload
+	if err := json.Unmarshal(step.Entry.Payload, &payload); err != nil {
+		t.Fatalf("unmarshal prescription payload: %v", err)
+	}
+	if payload.PlanID != plan.PlanID || payload.PlanStep != 1 {
+		t.Fatalf("payload plan_id=%q plan_step=%d", payload.PlanID, payload.PlanStep)
+	}
+}
+
+func TestServicePrescribePlan_RejectsInvalidPlans(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(Options{
+		EvidencePath: t.TempDir(),
+		Signer:       testutil.TestSigner(t),
+	})
+	actor := evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"}
+
+	tests := []struct {
+		name  string
+		steps []PlanStepInput
+		code  Code
+	}{
+		{name: "no steps", code: ErrCodeInvalidInput},
+		{name: "missing operation", steps: []PlanStepInput{{Tool: "kubectl"}}, code: ErrCodeInvalidInput},
+		{name: "unparseable artifact", steps: []PlanStepInput{{Tool: "terraform", Operation: "apply", RawArtifact: []byte("{{{")}}, code: ErrCodeParseError},
+	}
+	for _, tt := range tests {
+		_, err := svc.PrescribePlan(context.Background(), PlanInput{Actor: actor, Steps: tt.steps})
+		if ErrorCode(err) != tt.code {
+			t.Errorf("%s: error code = %q (%v), want %q", tt.name, ErrorCode(err), err, tt.code)
+		}
+	}
+}
+
+func TestServicePrescribe_RejectsUnknownPlanLinks(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(Options{
+		EvidencePath: t.TempDir(),
+		Signer:       testutil.TestSigner(t),
+	})
+	actor := evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"}
+	plan, err := svc.PrescribePlan(context.Background(), PlanInput{
+		Actor: actor,
+		Steps: []PlanStepInput{{Tool: "kubectl", Operation: "apply"}},
+	})
+	if err != nil {
+		t.Fatalf("PrescribePlan: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		planID   string
+		planStep int
+		code     Code
+	}{
+		{name: "unknown plan", planID: "missing", planStep: 1, code: ErrCodeNotFound},
+		{name: "step out of range", planID: plan.PlanID, planStep: 2, code: ErrCodeInvalidInput},
+		{name: "step without plan", planStep: 1, code: ErrCodeInvalidInput},
+	}
+	for _, tt := range tests {
+		_, err := svc.Prescribe(context.Background(), PrescribeInput{
+			Actor:       actor,
+			Tool:        "kubectl",
+			Operation:   "apply",
+			RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n"),
+			PlanID:      tt.planID,
+			PlanStep:    tt.planStep,
+		})
+		if ErrorCode(err) != tt.code {
+			t.Errorf("%s: error code = %q (%v), want %q", tt.name, ErrorCode(err), err, tt.code)
+		}
+	}
+}
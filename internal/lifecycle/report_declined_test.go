@@ -108,6 +108,35 @@ func TestServiceReport_DeclinedRequiresDecisionReason(t *testing.T) {
 	}
 }
 
+func TestServiceReport_DeclinedRejectsTranscript(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(Options{
+		EvidencePath: t.TempDir(),
+		Signer:       testutil.TestSigner(t),
+	})
+	prescribeOut, err := svc.Prescribe(context.Background(), PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\n"),
+		SessionID:   "session-1",
+	})
+	if err != nil {
+		t.Fatalf("Prescribe: %v", err)
+	}
+
+	_, err = svc.Report(context.Background(), ReportInput{
+		PrescriptionID:  prescribeOut.PrescriptionID,
+		Verdict:         evidence.VerdictDeclined,
+		DecisionContext: &evidence.DecisionContext{Trigger: "risk_threshold_exceeded", Reason: "too risky"},
+		Transcript:      &evidence.TranscriptRef{Digest: "sha256:" + strings.Repeat("0", 64)},
+	})
+	if ErrorCode(err) != ErrCodeInvalidInput || !strings.Contains(err.Error(), "must not include a transcript") {
+		t.Fatalf("err = %v, want transcript rejection", err)
+	}
+}
+
 func intPtr(v int) *int {
 	return &v
 }
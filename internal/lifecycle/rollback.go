@@ -0,0 +1,69 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// loadRevertTarget checks that the prescription input reverts exists and
+// returns its resource identities. Without an evidence path there is
+// nothing to check against and the comparison reports unknown.
+func (s *Service) loadRevertTarget(input *PrescribeInput) ([]canon.ResourceID, error) {
+	input.RevertsPrescriptionID = strings.TrimSpace(input.RevertsPrescriptionID)
+	if input.RevertsPrescriptionID == "" || s.evidencePath == "" {
+		return nil, nil
+	}
+
+	entry, found, err := evidence.FindEntryByID(s.evidencePath, input.RevertsPrescriptionID)
+	if err != nil {
+		return nil, wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	if !found || entry.Type != evidence.EntryTypePrescribe {
+		return nil, wrapError(ErrCodeNotFound, "reverts_prescription_id not found", nil)
+	}
+	var p evidence.PrescriptionPayload
+	if err := json.Unmarshal(entry.Payload, &p); err != nil {
+		return nil, wrapError(ErrCodeInternal, "failed to decode reverted prescription", err)
+	}
+	var action canon.CanonicalAction
+	if err := json.Unmarshal(p.CanonicalAction, &action); err != nil {
+		return nil, wrapError(ErrCodeInternal, "failed to decode reverted canonical action", err)
+	}
+	return action.ResourceIdentity, nil
+}
+
+// compareRevertResources reports how many of the reverted resources the
+// rollback names. Resources match on kind, namespace and name (type and
+// name for Terraform); a rollback that names extra resources still counts
+// as full.
+func compareRevertResources(reverted, rollback []canon.ResourceID) string {
+	if len(reverted) == 0 || len(rollback) == 0 {
+		return evidence.RevertMatchUnknown
+	}
+	names := make(map[string]bool, len(rollback))
+	for _, r := range rollback {
+		names[revertResourceKey(r)] = true
+	}
+	covered := 0
+	for _, r := range reverted {
+		if names[revertResourceKey(r)] {
+			covered++
+		}
+	}
+	switch covered {
+	case len(reverted):
+		return evidence.RevertMatchFull
+	case 0:
+		return evidence.RevertMatchNone
+	default:
+		return evidence.RevertMatchPartial
+	}
+}
+
+func revertResourceKey(r canon.ResourceID) string {
+	return strings.ToLower(r.Kind) + "|" + r.Namespace + "|" + r.Type + "|" + r.Name
+}
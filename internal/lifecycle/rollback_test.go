@@ -0,0 +1,106 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestServicePrescribe_RevertsPrescriptionComparesResources(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(Options{
+		EvidencePath: t.TempDir(),
+		Signer:       testutil.TestSigner(t),
+	})
+	actor := evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"}
+	manifest := func(names ...string) []byte {
+		var out string
+		for i, name := range names {
+			if i > 0 {
+				out += "---\n"
+			}
+			out += "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  namespace: prod\n"
+		}
+		return []byte(out)
+	}
+
+	original, err := svc.Prescribe(context.Background(), PrescribeInput{
+		Actor:       actor,
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: manifest("web", "api"),
+	})
+	if err != nil {
+		t.Fatalf("Prescribe original: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		artifact []byte
+		action   *canon.CanonicalAction
+		want     string
+	}{
+		{name: "full", artifact: manifest("api", "web", "extra"), want: evidence.RevertMatchFull},
+		{name: "partial", artifact: manifest("web"), want: evidence.RevertMatchPartial},
+		{name: "none", artifact: manifest("other"), want: evidence.RevertMatchNone},
+		{name: "unknown", action: &canon.CanonicalAction{OperationClass: "mutate", ScopeClass: "production"}, want: evidence.RevertMatchUnknown},
+	}
+	for _, tt := range tests {
+		out, err := svc.Prescribe(context.Background(), PrescribeInput{
+			Actor:                 actor,
+			Tool:                  "kubectl",
+			Operation:             "apply",
+			RawArtifact:           tt.artifact,
+			CanonicalAction:       tt.action,
+			RevertsPrescriptionID: " " + original.PrescriptionID + " ",
+		})
+		if err != nil {
+			t.Fatalf("%s: Prescribe rollback: %v", tt.name, err)
+		}
+		if out.RevertsPrescriptionID != original.PrescriptionID || out.RevertMatch != tt.want {
+			t.Errorf("%s: reverts=%q match=%q, want %q", tt.name, out.RevertsPrescriptionID, out.RevertMatch, tt.want)
+		}
+		var payload evidence.PrescriptionPayload
+		if err := json.Unmarshal(out.Entry.Payload, &payload); err != nil {
+			t.Fatalf("unmarshal prescription payload: %v", err)
+		}
+		if payload.RevertsPrescriptionID != original.PrescriptionID || payload.RevertMatch != tt.want {
+			t.Errorf("%s: payload reverts=%q match=%q", tt.name, payload.RevertsPrescriptionID, payload.RevertMatch)
+		}
+	}
+}
+
+func TestServicePrescribe_RevertsUnknownPrescription(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(Options{
+		EvidencePath: t.TempDir(),
+		Signer:       testutil.TestSigner(t),
+	})
+	plan, err := svc.PrescribePlan(context.Background(), PlanInput{
+		Actor: evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+		Steps: []PlanStepInput{{Tool: "kubectl", Operation: "apply"}},
+	})
+	if err != nil {
+		t.Fatalf("PrescribePlan: %v", err)
+	}
+
+	// Only prescriptions can be reverted.
+	for _, target := range []string{"missing", plan.PlanID} {
+		_, err := svc.Prescribe(context.Background(), PrescribeInput{
+			Actor:                 evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+			Tool:                  "helm",
+			Operation:             "rollback",
+			RawArtifact:           []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n"),
+			RevertsPrescriptionID: target,
+		})
+		if ErrorCode(err) != ErrCodeNotFound {
+			t.Errorf("reverting %q: error code = %q (%v), want %q", target, ErrorCode(err), err, ErrCodeNotFound)
+		}
+	}
+}
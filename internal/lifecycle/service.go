@@ -38,6 +38,13 @@ func (s *Service) Prescribe(_ context.Context, input PrescribeInput) (PrescribeO
 	if err := requiredSigner(s.signer); err != nil {
 		return PrescribeOutput{}, err
 	}
+	if err := s.resolvePlanLink(&input); err != nil {
+		return PrescribeOutput{}, err
+	}
+	reverted, err := s.loadRevertTarget(&input)
+	if err != nil {
+		return PrescribeOutput{}, err
+	}
 
 	ctx, err := buildPrescribeContext(input)
 	if err != nil {
@@ -50,6 +57,10 @@ func (s *Service) Prescribe(_ context.Context, input PrescribeInput) (PrescribeO
 	}
 
 	riskInputs, effectiveRisk, nativeTags := buildPrescribeRiskState(cr, input.RawArtifact, input.ExternalFindings)
+	revertMatch := ""
+	if input.RevertsPrescriptionID != "" {
+		revertMatch = compareRevertResources(reverted, cr.CanonicalAction.ResourceIdentity)
+	}
 
 	retryCount := 0
 	if s.retryTracker != nil {
@@ -57,24 +68,24 @@ func (s *Service) Prescribe(_ context.Context, input PrescribeInput) (PrescribeO
 	}
 
 	prescPayload := evidence.PrescriptionPayload{
-		PrescriptionID:  ulid.Make().String(),
-		CanonicalAction: cr.RawAction,
-		RiskInputs:      riskInputs,
-		EffectiveRisk:   effectiveRisk,
-		TTLMs:           evidence.DefaultTTLMs,
-		CanonSource:     canonSource,
+		PrescriptionID:        ulid.Make().String(),
+		CanonicalAction:       cr.RawAction,
+		RiskInputs:            riskInputs,
+		EffectiveRisk:         effectiveRisk,
+		TTLMs:                 evidence.DefaultTTLMs,
+		CanonSource:           canonSource,
+		PlanID:                input.PlanID,
+		PlanStep:              input.PlanStep,
+		RevertsPrescriptionID: input.RevertsPrescriptionID,
+		RevertMatch:           revertMatch,
+		ApprovalRequired:      s.requiresApproval(effectiveRisk),
 	}
 	payloadJSON, err := json.Marshal(prescPayload)
 	if err != nil {
 		return PrescribeOutput{}, wrapError(ErrCodeInternal, "failed to marshal prescription payload", err)
 	}
 
-	lastHash, err := s.lastHash()
-	if err != nil {
-		return PrescribeOutput{}, err
-	}
-
-	entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+	entry, state, err := s.recordEntry(evidence.EntryBuildParams{
 		EntryID:         prescPayload.PrescriptionID,
 		Type:            evidence.EntryTypePrescribe,
 		SessionID:       ctx.sessionID,
@@ -87,7 +98,6 @@ func (s *Service) Prescribe(_ context.Context, input PrescribeInput) (PrescribeO
 		IntentDigest:    cr.IntentDigest,
 		ArtifactDigest:  cr.ArtifactDigest,
 		Payload:         payloadJSON,
-		PreviousHash:    lastHash,
 		ScopeDimensions: input.ScopeDimensions,
 		SpecVersion:     version.SpecVersion,
 		CanonVersion:    cr.CanonVersion,
@@ -95,15 +105,10 @@ func (s *Service) Prescribe(_ context.Context, input PrescribeInput) (PrescribeO
 		ScoringVersion:  version.ScoringVersion,
 		Signer:          s.signer,
 	})
-	if err != nil {
-		return PrescribeOutput{}, wrapError(ErrCodeInternal, err.Error(), err)
-	}
-
-	persisted, err := s.appendEntry(entry)
 	if err != nil {
 		return PrescribeOutput{}, err
 	}
-	if persisted {
+	if state != entryBuilt {
 		s.writeFindingsEvidence(input.ExternalFindings, ctx.sessionID, ctx.traceID, strings.TrimSpace(input.OperationID), input.Attempt, ctx.actor, cr.ArtifactDigest)
 	}
 
@@ -113,25 +118,31 @@ func (s *Service) Prescribe(_ context.Context, input PrescribeInput) (PrescribeO
 	}
 
 	return PrescribeOutput{
-		PrescriptionID: entry.EntryID,
-		SessionID:      ctx.sessionID,
-		TraceID:        ctx.traceID,
-		Actor:          ctx.actor,
-		RiskInputs:     riskInputs,
-		EffectiveRisk:  effectiveRisk,
-		RiskLevel:      effectiveRisk,
-		RiskTags:       nativeTags,
-		ArtifactDigest: cr.ArtifactDigest,
-		IntentDigest:   cr.IntentDigest,
-		ShapeHash:      cr.CanonicalAction.ResourceShapeHash,
-		ResourceCount:  cr.CanonicalAction.ResourceCount,
-		OperationClass: cr.CanonicalAction.OperationClass,
-		ScopeClass:     cr.CanonicalAction.ScopeClass,
-		CanonVersion:   cr.CanonVersion,
-		RetryCount:     retryCount,
-		Entry:          entry,
-		RawEntry:       rawEntry,
-		Persisted:      persisted,
+		PrescriptionID:        entry.EntryID,
+		SessionID:             ctx.sessionID,
+		TraceID:               ctx.traceID,
+		Actor:                 ctx.actor,
+		RiskInputs:            riskInputs,
+		EffectiveRisk:         effectiveRisk,
+		RiskLevel:             effectiveRisk,
+		RiskTags:              nativeTags,
+		ArtifactDigest:        cr.ArtifactDigest,
+		IntentDigest:          cr.IntentDigest,
+		ShapeHash:             cr.CanonicalAction.ResourceShapeHash,
+		ResourceCount:         cr.CanonicalAction.ResourceCount,
+		OperationClass:        cr.CanonicalAction.OperationClass,
+		ScopeClass:            cr.CanonicalAction.ScopeClass,
+		CanonVersion:          cr.CanonVersion,
+		RetryCount:            retryCount,
+		PlanID:                input.PlanID,
+		PlanStep:              input.PlanStep,
+		RevertsPrescriptionID: input.RevertsPrescriptionID,
+		RevertMatch:           revertMatch,
+		ApprovalRequired:      prescPayload.ApprovalRequired,
+		Entry:                 entry,
+		RawEntry:              rawEntry,
+		Persisted:             state == entrySealed,
+		Staged:                state == entryStaged,
 	}, nil
 }
 
@@ -162,6 +173,7 @@ func (s *Service) Report(_ context.Context, input ReportInput) (ReportOutput, er
 		Verdict:         input.Verdict,
 		DecisionContext: decisionContext,
 		ExternalRefs:    input.ExternalRefs,
+		Transcript:      input.Transcript,
 	}
 	payloadJSON, err := json.Marshal(reportPayload)
 	if err != nil {
@@ -173,12 +185,7 @@ func (s *Service) Report(_ context.Context, input ReportInput) (ReportOutput, er
 		return ReportOutput{}, err
 	}
 
-	lastHash, err := s.lastHash()
-	if err != nil {
-		return ReportOutput{}, err
-	}
-
-	entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+	entry, state, err := s.recordEntry(evidence.EntryBuildParams{
 		Type:           evidence.EntryTypeReport,
 		SessionID:      ctx.sessionID,
 		OperationID:    ctx.operationID,
@@ -188,17 +195,11 @@ func (s *Service) Report(_ context.Context, input ReportInput) (ReportOutput, er
 		Actor:          ctx.actor,
 		ArtifactDigest: input.ArtifactDigest,
 		Payload:        payloadJSON,
-		PreviousHash:   lastHash,
 		SpecVersion:    version.SpecVersion,
 		AdapterVersion: version.Version,
 		ScoringVersion: version.ScoringVersion,
 		Signer:         s.signer,
 	})
-	if err != nil {
-		return ReportOutput{}, wrapError(ErrCodeInternal, err.Error(), err)
-	}
-
-	persisted, err := s.appendEntry(entry)
 	if err != nil {
 		return ReportOutput{}, err
 	}
@@ -207,6 +208,12 @@ func (s *Service) Report(_ context.Context, input ReportInput) (ReportOutput, er
 	if err != nil {
 		return ReportOutput{}, wrapError(ErrCodeInternal, "failed to marshal evidence entry", err)
 	}
+	approvalMissing := false
+	if prescriptionFound && input.Verdict != evidence.VerdictDeclined {
+		if approvalMissing, err = s.approvalMissing(prescriptionEntry); err != nil {
+			return ReportOutput{}, err
+		}
+	}
 
 	return ReportOutput{
 		ReportID:        entry.EntryID,
@@ -217,9 +224,12 @@ func (s *Service) Report(_ context.Context, input ReportInput) (ReportOutput, er
 		Verdict:         input.Verdict,
 		ExitCode:        input.ExitCode,
 		DecisionContext: decisionContext,
+		Late:            prescriptionFound && isLateReport(prescriptionEntry, entry.Timestamp),
+		ApprovalMissing: approvalMissing,
 		Entry:           entry,
 		RawEntry:        rawEntry,
-		Persisted:       persisted,
+		Persisted:       state == entrySealed,
+		Staged:          state == entryStaged,
 	}, nil
 }
 
@@ -365,6 +375,9 @@ func validateDecisionReportInput(input ReportInput) (*evidence.DecisionContext,
 		if input.ExitCode != nil {
 			return nil, wrapError(ErrCodeInvalidInput, "declined reports must not include exit_code", nil)
 		}
+		if input.Transcript != nil {
+			return nil, wrapError(ErrCodeInvalidInput, "declined reports must not include a transcript", nil)
+		}
 		if input.DecisionContext == nil {
 			return nil, wrapError(ErrCodeInvalidInput, "decision_context is required for declined reports", nil)
 		}
@@ -413,8 +426,7 @@ func (s *Service) writeCanonicalizationFailure(actor evidence.Actor, cr canon.Ca
 		RawDigest:    cr.ArtifactDigest,
 	})
 
-	lastHash, _ := evidence.LastHashAtPath(s.evidencePath) // best-effort: failure recording is advisory
-	entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+	_, _ = evidence.AppendAtPath(s.evidencePath, evidence.EntryBuildParams{ // best-effort: failure recording is advisory
 		Type:           evidence.EntryTypeCanonFailure,
 		SessionID:      sessionID,
 		OperationID:    operationID,
@@ -423,15 +435,11 @@ func (s *Service) writeCanonicalizationFailure(actor evidence.Actor, cr canon.Ca
 		Actor:          actor,
 		ArtifactDigest: cr.ArtifactDigest,
 		Payload:        failPayload,
-		PreviousHash:   lastHash,
 		SpecVersion:    version.SpecVersion,
 		AdapterVersion: version.Version,
 		ScoringVersion: version.ScoringVersion,
 		Signer:         s.signer,
 	})
-	if err == nil {
-		_ = evidence.AppendEntryAtPath(s.evidencePath, entry) // best-effort: failure signal is advisory
-	}
 }
 
 func (s *Service) writeUnknownPrescriptionSignal(actor evidence.Actor, prescriptionID, sessionID, operationID string) {
@@ -445,24 +453,19 @@ func (s *Service) writeUnknownPrescriptionSignal(actor evidence.Actor, prescript
 		Details:    "report references unknown prescription " + prescriptionID,
 	})
 
-	lastHash, _ := evidence.LastHashAtPath(s.evidencePath) // best-effort: signal recording is advisory
 	traceID := evidence.GenerateTraceID()
-	entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+	_, _ = evidence.AppendAtPath(s.evidencePath, evidence.EntryBuildParams{ // best-effort: signal recording is advisory
 		Type:           evidence.EntryTypeSignal,
 		SessionID:      sessionID,
 		OperationID:    operationID,
 		TraceID:        traceID,
 		Actor:          actor,
 		Payload:        sigPayload,
-		PreviousHash:   lastHash,
 		SpecVersion:    version.SpecVersion,
 		AdapterVersion: version.Version,
 		ScoringVersion: version.ScoringVersion,
 		Signer:         s.signer,
 	})
-	if err == nil {
-		_ = evidence.AppendEntryAtPath(s.evidencePath, entry) // best-effort: signal entry is advisory
-	}
 }
 
 func (s *Service) writeFindingsEvidence(sources []ExternalFindingsSource, sessionID, traceID, operationID string, attempt int, actor evidence.Actor, artifactDigest string) {
@@ -480,12 +483,7 @@ func (s *Service) writeFindingsEvidence(sources []ExternalFindingsSource, sessio
 				slog.Warn("failed to marshal finding payload", "rule_id", finding.RuleID, "error", err)
 				continue
 			}
-			lastHash, err := s.lastHash()
-			if err != nil {
-				slog.Warn("failed to read last hash for finding entry", "rule_id", finding.RuleID, "error", err)
-				continue
-			}
-			entry, err := evidence.BuildEntry(evidence.EntryBuildParams{
+			if _, _, err := s.recordEntry(evidence.EntryBuildParams{
 				Type:           evidence.EntryTypeFinding,
 				SessionID:      sessionID,
 				OperationID:    operationID,
@@ -494,59 +492,71 @@ func (s *Service) writeFindingsEvidence(sources []ExternalFindingsSource, sessio
 				Actor:          actor,
 				ArtifactDigest: artifactDigest,
 				Payload:        payload,
-				PreviousHash:   lastHash,
 				SpecVersion:    version.SpecVersion,
 				AdapterVersion: version.Version,
 				ScoringVersion: version.ScoringVersion,
 				Signer:         s.signer,
-			})
-			if err != nil {
-				slog.Warn("failed to build finding entry", "rule_id", finding.RuleID, "error", err)
-				continue
-			}
-			if _, err := s.appendEntry(entry); err != nil {
+			}); err != nil {
 				slog.Warn("failed to append finding entry", "rule_id", finding.RuleID, "error", err)
 			}
 		}
 	}
 }
 
-func (s *Service) appendEntry(entry evidence.EvidenceEntry) (bool, error) {
-	if s.evidencePath == "" {
-		return false, nil
-	}
-	if err := evidence.AppendEntryAtPath(s.evidencePath, entry); err != nil {
-		if s.bestEffortWrites {
-			slog.Warn(
-				"best-effort evidence write failed",
-				"entry_id", entry.EntryID,
-				"entry_type", string(entry.Type),
-				"error", err,
-			)
-			return false, nil
-		}
-		return false, wrapError(ErrCodeEvidenceWrite, fmt.Sprintf("failed to write evidence: %v", err), err)
-	}
-	return true, nil
-}
+// entryState is how far recordEntry got with an entry.
+type entryState int
+
+const (
+	// entryBuilt entries are not in the store and never will be.
+	entryBuilt entryState = iota
+	// entryStaged entries are durable in the staging area and are sealed by
+	// the next writer with the same key. They have no hash yet, so they
+	// must not be forwarded.
+	entryStaged
+	// entrySealed entries are linked and signed in the chain.
+	entrySealed
+)
 
-func (s *Service) lastHash() (string, error) {
+// recordEntry builds the entry described by p and appends it to the local
+// chain. The store's sequencer links previous_hash at commit time, so
+// concurrent writers never fork the chain. Without an evidence path, or when
+// a best-effort write fails, the entry is built but not persisted.
+func (s *Service) recordEntry(p evidence.EntryBuildParams) (evidence.EvidenceEntry, entryState, error) {
 	if s.evidencePath == "" {
-		return "", nil
-	}
-	lastHash, err := evidence.LastHashAtPath(s.evidencePath)
-	if err != nil {
-		if s.bestEffortWrites {
-			slog.Warn(
-				"best-effort evidence read failed",
-				"operation", "last_hash",
-				"error", err,
-			)
-			return "", nil
+		entry, err := evidence.BuildEntry(p)
+		if err != nil {
+			return evidence.EvidenceEntry{}, entryBuilt, wrapError(ErrCodeInternal, err.Error(), err)
 		}
-		return "", wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+		return entry, entryBuilt, nil
 	}
-	return lastHash, nil
+	entry, err := evidence.AppendAtPath(s.evidencePath, p)
+	switch evidence.ErrorCode(err) {
+	case "":
+		if err != nil {
+			// Invalid input or a signer failure, not the store.
+			return evidence.EvidenceEntry{}, entryBuilt, wrapError(ErrCodeInternal, err.Error(), err)
+		}
+		return entry, entrySealed, nil
+	case evidence.ErrorCodeEntryStaged:
+		// The next writer with the same key seals it under this ID.
+		slog.Warn("evidence entry staged, not yet sealed", "entry_id", entry.EntryID, "entry_type", string(entry.Type), "error", err)
+		return entry, entryStaged, nil
+	}
+	// The store discarded the entry, so returning it unsealed cannot
+	// collide with a sealed copy later.
+	if s.bestEffortWrites {
+		slog.Warn(
+			"best-effort evidence write failed",
+			"entry_id", entry.EntryID,
+			"entry_type", string(entry.Type),
+			"error", err,
+		)
+		return entry, entryBuilt, nil
+	}
+	if evidence.ErrorCode(err) == evidence.ErrorCodeStoreRead {
+		return evidence.EvidenceEntry{}, entryBuilt, wrapError(ErrCodeEvidenceRead, fmt.Sprintf("failed to read evidence: %v", err), err)
+	}
+	return evidence.EvidenceEntry{}, entryBuilt, wrapError(ErrCodeEvidenceWrite, fmt.Sprintf("failed to write evidence: %v", err), err)
 }
 
 func normalizeCanonicalAction(action canon.CanonicalAction, tool, operation string) (canon.CanonicalAction, error) {
@@ -11,6 +11,7 @@ import (
 	"samebits.com/evidra/internal/canon"
 	"samebits.com/evidra/internal/testutil"
 	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/evlock"
 )
 
 type countingSigner struct {
@@ -373,6 +374,47 @@ func TestServicePrescribe_StoreFailureReturnsErrorInStrictMode(t *testing.T) {
 	}
 }
 
+func TestServicePrescribe_StagedWriteReturnsStagedEntryID(t *testing.T) {
+	t.Setenv("EVIDRA_EVIDENCE_LOCK_TIMEOUT_MS", "20")
+	t.Setenv("EVIDRA_EVIDENCE_SEQUENCE_TIMEOUT_MS", "60")
+	dir := t.TempDir()
+	signer := testutil.TestSigner(t)
+	svc := NewService(Options{EvidencePath: dir, Signer: signer})
+	input := PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\n"),
+		SessionID:   "session-staged",
+	}
+	if _, err := svc.Prescribe(context.Background(), input); err != nil {
+		t.Fatalf("Prescribe: %v", err)
+	}
+
+	lock, err := evlock.Acquire(filepath.Join(dir, ".evidra.lock"), 0)
+	if err != nil {
+		t.Fatalf("hold store lock: %v", err)
+	}
+	staged, err := svc.Prescribe(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Prescribe while the store is locked: %v", err)
+	}
+	if !staged.Staged || staged.Persisted || staged.Entry.Hash != "" {
+		t.Fatalf("staged prescription: staged=%v persisted=%v hash=%q, want staged and unsealed", staged.Staged, staged.Persisted, staged.Entry.Hash)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("release lock: %v", err)
+	}
+
+	// The next write seals the staged entry under the ID already returned.
+	if _, err := svc.Prescribe(context.Background(), input); err != nil {
+		t.Fatalf("Prescribe: %v", err)
+	}
+	if _, ok, err := evidence.FindEntryByID(dir, staged.PrescriptionID); err != nil || !ok {
+		t.Fatalf("staged prescription %s not in the chain: ok=%v err=%v", staged.PrescriptionID, ok, err)
+	}
+}
+
 func TestServicePrescribe_BestEffortWriteModeSuppressesWriteError(t *testing.T) {
 	t.Parallel()
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"samebits.com/evidra/internal/canon"
 	"samebits.com/evidra/pkg/evidence"
@@ -20,6 +21,13 @@ type Options struct {
 	Signer           evidence.Signer
 	RetryTracker     RetryRecorder
 	BestEffortWrites bool
+	// ApprovalRisk is the lowest effective risk whose prescriptions need
+	// an approval entry before they run; empty disables approvals.
+	ApprovalRisk string
+	// Approvers holds the human approvers' public keys. An approval entry
+	// counts only when its signature verifies against one of them; with
+	// none configured, no approval counts.
+	Approvers *evidence.Keyring
 }
 
 // Service is the shared prescribe/report business logic used by CLI and MCP.
@@ -28,6 +36,8 @@ type Service struct {
 	signer           evidence.Signer
 	retryTracker     RetryRecorder
 	bestEffortWrites bool
+	approvalRisk     string
+	approvers        *evidence.Keyring
 }
 
 // NewService creates a lifecycle service from options.
@@ -37,6 +47,8 @@ func NewService(opts Options) *Service {
 		signer:           opts.Signer,
 		retryTracker:     opts.RetryTracker,
 		bestEffortWrites: opts.BestEffortWrites,
+		approvalRisk:     opts.ApprovalRisk,
+		approvers:        opts.Approvers,
 	}
 }
 
@@ -56,6 +68,14 @@ type PrescribeInput struct {
 	SpanID           string
 	ParentSpanID     string
 	ScopeDimensions  map[string]string
+	// PlanID links the operation to a plan from PrescribePlan; PlanStep is
+	// the 1-based step it carries out, or 0 for an unplanned extra step.
+	// Session and trace default to the plan's.
+	PlanID   string
+	PlanStep int
+	// RevertsPrescriptionID marks the operation as a rollback of an
+	// earlier prescription, which must exist in the evidence chain.
+	RevertsPrescriptionID string
 }
 
 type ExternalFindingsSource struct {
@@ -82,9 +102,61 @@ type PrescribeOutput struct {
 	ScopeClass     string
 	CanonVersion   string
 	RetryCount     int
-	Entry          evidence.EvidenceEntry
-	RawEntry       json.RawMessage
-	Persisted      bool
+	PlanID         string
+	PlanStep       int
+	// RevertMatch is one of the evidence.RevertMatch* values when
+	// RevertsPrescriptionID is set.
+	RevertsPrescriptionID string
+	RevertMatch           string
+	// ApprovalRequired is set when the effective risk reaches the
+	// service's approval threshold.
+	ApprovalRequired bool
+	Entry            evidence.EvidenceEntry
+	RawEntry         json.RawMessage
+	Persisted        bool
+	// Staged is set, with Persisted false, when the entry is durable but
+	// awaits sealing by the next writer; it has no hash or signature yet.
+	Staged bool
+}
+
+// PlanInput captures an ordered set of operations prescribed up front.
+type PlanInput struct {
+	Actor           evidence.Actor
+	Environment     string
+	Steps           []PlanStepInput
+	SessionID       string
+	OperationID     string
+	TraceID         string
+	SpanID          string
+	ParentSpanID    string
+	ScopeDimensions map[string]string
+}
+
+// PlanStepInput is one planned operation. The artifact or canonical action
+// is optional: later steps often depend on earlier ones and are planned
+// from tool and operation alone.
+type PlanStepInput struct {
+	Tool            string
+	Operation       string
+	Description     string
+	RawArtifact     []byte
+	CanonicalAction *canon.CanonicalAction
+}
+
+// PlanOutput contains the written plan entry.
+type PlanOutput struct {
+	PlanID        string
+	SessionID     string
+	TraceID       string
+	Actor         evidence.Actor
+	EffectiveRisk string
+	Steps         []evidence.PlanStep
+	Entry         evidence.EvidenceEntry
+	RawEntry      json.RawMessage
+	Persisted     bool
+	// Staged is set, with Persisted false, when the entry is durable but
+	// awaits sealing by the next writer; it has no hash or signature yet.
+	Staged bool
 }
 
 // ReportInput captures post-execution operation context.
@@ -96,10 +168,13 @@ type ReportInput struct {
 	ArtifactDigest  string
 	Actor           evidence.Actor
 	ExternalRefs    []evidence.ExternalRef
-	SessionID       string
-	OperationID     string
-	SpanID          string
-	ParentSpanID    string
+	// Transcript references captured command output already stored as a
+	// blob next to the evidence chain.
+	Transcript   *evidence.TranscriptRef
+	SessionID    string
+	OperationID  string
+	SpanID       string
+	ParentSpanID string
 }
 
 // ReportOutput contains identifiers/correlation for written report entries.
@@ -112,9 +187,87 @@ type ReportOutput struct {
 	Verdict         evidence.Verdict
 	ExitCode        *int
 	DecisionContext *evidence.DecisionContext
+	// Late is set when the report arrived after the prescription's TTL.
+	Late bool
+	// ApprovalMissing is set when an executed operation required approval
+	// and no approved decision was recorded for it.
+	ApprovalMissing bool
 	Entry           evidence.EvidenceEntry
 	RawEntry        json.RawMessage
 	Persisted       bool
+	// Staged is set, with Persisted false, when the entry is durable but
+	// awaits sealing by the next writer; it has no hash or signature yet.
+	Staged bool
+}
+
+// CancelInput withdraws an open prescription.
+type CancelInput struct {
+	PrescriptionID string
+	Reason         string
+	Actor          evidence.Actor
+	SessionID      string
+	OperationID    string
+	SpanID         string
+	ParentSpanID   string
+}
+
+// CancelOutput contains the written cancellation entry.
+type CancelOutput struct {
+	CancelID       string
+	SessionID      string
+	TraceID        string
+	Actor          evidence.Actor
+	PrescriptionID string
+	Reason         string
+	Entry          evidence.EvidenceEntry
+	RawEntry       json.RawMessage
+	Persisted      bool
+	// Staged is set, with Persisted false, when the entry is durable but
+	// awaits sealing by the next writer; it has no hash or signature yet.
+	Staged bool
+}
+
+// ApproveInput records a human decision on a prescription that requires
+// approval. Prescription carries the entry when the caller fetched it from
+// a remote store instead of a local evidence chain.
+type ApproveInput struct {
+	PrescriptionID string
+	Decision       string
+	Reason         string
+	Actor          evidence.Actor
+	Prescription   *evidence.EvidenceEntry
+	OperationID    string
+}
+
+// ApproveOutput contains the written approval entry.
+type ApproveOutput struct {
+	ApprovalID     string
+	SessionID      string
+	TraceID        string
+	Actor          evidence.Actor
+	PrescriptionID string
+	Decision       string
+	Entry          evidence.EvidenceEntry
+	RawEntry       json.RawMessage
+	Persisted      bool
+	// Staged is set, with Persisted false, when the entry is durable but
+	// awaits sealing by the next writer; it has no hash or signature yet.
+	Staged bool
+}
+
+// PendingPrescription is a prescription with no report or cancellation.
+type PendingPrescription struct {
+	PrescriptionID   string    `json:"prescription_id"`
+	SessionID        string    `json:"session_id"`
+	ActorID          string    `json:"actor_id"`
+	Tool             string    `json:"tool"`
+	Operation        string    `json:"operation"`
+	EffectiveRisk    string    `json:"effective_risk,omitempty"`
+	ApprovalRequired bool      `json:"approval_required,omitempty"`
+	PrescribedAt     time.Time `json:"prescribed_at"`
+	AgeMs            int64     `json:"age_ms"`
+	TTLMs            int64     `json:"ttl_ms"`
+	Expired          bool      `json:"expired"`
 }
 
 // Code is a stable adapter-facing lifecycle error code.
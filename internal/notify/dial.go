@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const allowedNetworksEnv = "EVIDRA_NOTIFY_ALLOWED_NETWORKS"
+
+// ErrBlockedAddress is returned when a notification URL resolves to an
+// address the egress policy does not permit.
+var ErrBlockedAddress = errors.New("notification_target_blocked")
+
+// EgressPolicy decides which addresses notifications may be sent to.
+// Every address that is not public global unicast — loopback, private,
+// link-local, multicast, unspecified, and the special-purpose ranges — is
+// blocked unless it falls inside one of the Allowed networks.
+type EgressPolicy struct {
+	Allowed []netip.Prefix
+}
+
+// EgressPolicyFromEnv reads EVIDRA_NOTIFY_ALLOWED_NETWORKS, a comma-separated
+// list of CIDRs that notifications may reach even though they are internal.
+// Unset allows only public addresses.
+func EgressPolicyFromEnv() (EgressPolicy, error) {
+	var p EgressPolicy
+	for _, raw := range strings.Split(os.Getenv(allowedNetworksEnv), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(raw)
+		if err != nil {
+			return EgressPolicy{}, fmt.Errorf("invalid %s entry %q (expected a CIDR)", allowedNetworksEnv, raw)
+		}
+		p.Allowed = append(p.Allowed, prefix.Masked())
+	}
+	return p, nil
+}
+
+// specialPurposePrefixes are the IANA special-purpose ranges that are
+// global unicast by address form but do not reach a public host: shared,
+// benchmarking, documentation and reserved space, and the IPv6 translation
+// ranges that embed an IPv4 address the policy would otherwise not see.
+var specialPurposePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/96"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("3fff::/20"),
+}
+
+// Permits reports whether notifications may be sent to addr. Only public
+// global unicast addresses are permitted, unless addr is in an Allowed
+// network.
+func (p EgressPolicy) Permits(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p.Allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range specialPurposePrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// client returns an HTTP client that checks every address it connects to,
+// after DNS resolution, so a name cannot be re-pointed at an internal
+// address once the rule is stored. Redirects are dialed the same way.
+// Proxies are not used, since the proxy would make the connection the
+// policy cannot see.
+func (p EgressPolicy) client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+			}
+			if !p.Permits(ap.Addr()) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, ap.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
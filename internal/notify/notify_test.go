@@ -0,0 +1,453 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	prescribe := Event{Kind: KindEntry, EntryType: "prescribe", EffectiveRisk: "critical", Scope: "production"}
+	declined := Event{Kind: KindEntry, EntryType: "report", Verdict: "declined", Scope: "production"}
+	retry := Event{Kind: KindSignal, EntryType: "prescribe", Signal: "retry_loop", Scope: "staging"}
+
+	tests := []struct {
+		name string
+		c    store.NotificationConditions
+		e    Event
+		want bool
+	}{
+		{"risk at threshold", store.NotificationConditions{MinRisk: "critical"}, prescribe, true},
+		{"risk below threshold", store.NotificationConditions{MinRisk: "critical"}, Event{Kind: KindEntry, EffectiveRisk: "high"}, false},
+		{"risk on entry without one", store.NotificationConditions{MinRisk: "low"}, declined, false},
+		{"verdict and scope", store.NotificationConditions{Verdicts: []string{"declined"}, Scopes: []string{"production"}}, declined, true},
+		{"verdict in other scope", store.NotificationConditions{Verdicts: []string{"declined"}, Scopes: []string{"staging"}}, declined, false},
+		{"signal", store.NotificationConditions{Signals: []string{"retry_loop"}}, retry, true},
+		{"signal rule ignores entries", store.NotificationConditions{Signals: []string{"retry_loop"}}, prescribe, false},
+		{"entry rule ignores signals", store.NotificationConditions{Scopes: []string{"staging"}, MinRisk: "low"}, retry, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Match(tt.c, tt.e); got != tt.want {
+				t.Fatalf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	t.Parallel()
+	valid := store.NotificationRule{Target: TargetSlack, URL: "https://hooks.example.com/x", Conditions: store.NotificationConditions{MinRisk: "high"}}
+	tests := []struct {
+		name    string
+		mutate  func(r *store.NotificationRule)
+		wantErr string
+	}{
+		{name: "valid", mutate: func(*store.NotificationRule) {}},
+		{name: "target", mutate: func(r *store.NotificationRule) { r.Target = "email" }, wantErr: "target"},
+		{name: "relative url", mutate: func(r *store.NotificationRule) { r.URL = "/hook" }, wantErr: "url"},
+		{name: "no conditions", mutate: func(r *store.NotificationRule) {
+			r.Conditions = store.NotificationConditions{Scopes: []string{"production"}}
+		}, wantErr: "must set"},
+		{name: "bad risk", mutate: func(r *store.NotificationRule) { r.Conditions.MinRisk = "severe" }, wantErr: "min_risk"},
+		{name: "unknown signal", mutate: func(r *store.NotificationRule) {
+			r.Conditions = store.NotificationConditions{Signals: []string{"nope"}}
+		}, wantErr: "unknown signal"},
+		{name: "signal with risk", mutate: func(r *store.NotificationRule) { r.Conditions.Signals = []string{"retry_loop"} }, wantErr: "cannot be combined"},
+		{name: "bad verdict", mutate: func(r *store.NotificationRule) { r.Conditions.Verdicts = []string{"maybe"} }, wantErr: "verdict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := valid
+			tt.mutate(&r)
+			err := ValidateRule(r)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateRule: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateRule err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeStore keeps rules, one tenant cursor, entries, and deliveries in
+// memory.
+type fakeStore struct {
+	mu         sync.Mutex
+	rules      []store.NotificationRule
+	cursor     int64
+	entries    []store.StreamEntry
+	deliveries []*store.NotificationDelivery
+	now        func() time.Time
+}
+
+func (f *fakeStore) NotificationTenants(context.Context) ([]string, error) {
+	return []string{"t1"}, nil
+}
+
+func (f *fakeStore) EnabledRules(context.Context, string) ([]store.NotificationRule, error) {
+	return f.rules, nil
+}
+
+func (f *fakeStore) DispatchNotifications(_ context.Context, _ string, fn func(after int64) (int64, []store.NotificationDelivery, error)) error {
+	next, queued, err := fn(f.cursor)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range queued {
+		d := d
+		d.ID = d.RuleID + "/" + d.EventKey
+		d.Status = store.DeliveryPending
+		d.NextAttemptAt = f.now()
+		f.deliveries = append(f.deliveries, &d)
+	}
+	f.cursor = next
+	return nil
+}
+
+func (f *fakeStore) ClaimDeliveries(_ context.Context, limit int, lease time.Duration) ([]store.NotificationDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []store.NotificationDelivery
+	for _, d := range f.deliveries {
+		if d.Status == store.DeliveryPending && !d.NextAttemptAt.After(f.now()) && len(out) < limit {
+			d.NextAttemptAt = f.now().Add(lease)
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) RecordDelivery(_ context.Context, id string, a store.DeliveryAttempt) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.deliveries {
+		if d.ID != id {
+			continue
+		}
+		d.Attempts++
+		d.LastError = a.Error
+		switch {
+		case a.Delivered:
+			d.Status = store.DeliveryDelivered
+		case a.RetryAt != nil:
+			d.NextAttemptAt = *a.RetryAt
+		default:
+			d.Status = store.DeliveryFailed
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) EntriesSince(_ context.Context, _ string, after int64, _ store.StreamFilter, limit int) ([]store.StreamEntry, error) {
+	var out []store.StreamEntry
+	for _, se := range f.entries {
+		if se.Seq > after && len(out) < limit {
+			out = append(out, se)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) SessionHistory(_ context.Context, _, sessionID string, before int64, _ int) ([]evidence.EvidenceEntry, error) {
+	var out []evidence.EvidenceEntry
+	for _, se := range f.entries {
+		var e evidence.EvidenceEntry
+		_ = json.Unmarshal(se.Entry.Payload, &e)
+		if se.Seq < before && e.SessionID == sessionID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) add(t *testing.T, e evidence.EvidenceEntry) {
+	t.Helper()
+	raw, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	f.entries = append(f.entries, store.StreamEntry{Seq: int64(len(f.entries) + 1), Entry: store.StoredEntry{ID: e.EntryID, Payload: raw}})
+}
+
+func (f *fakeStore) delivery(ruleID string) *store.NotificationDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.deliveries {
+		if d.RuleID == ruleID {
+			return d
+		}
+	}
+	return nil
+}
+
+func testEntry(t *testing.T, id string, typ evidence.EntryType, payload interface{}) evidence.EvidenceEntry {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return evidence.EvidenceEntry{
+		EntryID: id, Type: typ, SessionID: "s1", Actor: evidence.Actor{Type: "agent", ID: "agent-1"},
+		Timestamp: time.Now().UTC(), Payload: raw,
+	}
+}
+
+type received struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func TestWorker_DeliversSignedNotificationsWithRetry(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var got []received
+	failHTTP := true
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, received{path: r.URL.Path, header: r.Header.Clone(), body: body})
+		switch {
+		case r.URL.Path == "/http" && failHTTP:
+			failHTTP = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(receiver.Close)
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fs := &fakeStore{now: func() time.Time { return now }}
+	fs.rules = []store.NotificationRule{
+		{ID: "critical", Name: "critical risk", Target: TargetHTTP, URL: receiver.URL + "/http", Conditions: store.NotificationConditions{MinRisk: "critical"}},
+		{ID: "declined", Target: TargetSlack, URL: receiver.URL + "/slack", Conditions: store.NotificationConditions{Verdicts: []string{"declined"}, Scopes: []string{"production"}}},
+		{ID: "violation", Target: TargetCloudEvents, URL: receiver.URL + "/ce", Conditions: store.NotificationConditions{Signals: []string{"protocol_violation"}}},
+		{ID: "gone", Target: TargetHTTP, URL: receiver.URL + "/gone", Conditions: store.NotificationConditions{MinRisk: "low"}},
+	}
+	action := json.RawMessage(`{"tool":"kubectl","operation":"delete","operation_class":"destroy","scope_class":"production"}`)
+	fs.add(t, testEntry(t, "p1", evidence.EntryTypePrescribe, evidence.PrescriptionPayload{CanonicalAction: action, EffectiveRisk: "critical"}))
+	fs.add(t, testEntry(t, "r1", evidence.EntryTypeReport, evidence.ReportPayload{PrescriptionID: "p1", Verdict: evidence.VerdictDeclined}))
+	fs.add(t, testEntry(t, "r2", evidence.EntryTypeReport, evidence.ReportPayload{PrescriptionID: "p1", Verdict: evidence.VerdictDeclined}))
+
+	signer := testutil.TestSigner(t)
+	w := NewWorker(fs, fs, signer, loopbackEgress)
+	w.now = fs.now
+
+	ctx := context.Background()
+	if err := w.Tick(ctx); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if fs.cursor != 3 {
+		t.Fatalf("cursor = %d, want 3", fs.cursor)
+	}
+	if d := fs.delivery("critical"); d == nil || d.Status != store.DeliveryPending || d.Attempts != 1 || !d.NextAttemptAt.Equal(now.Add(firstRetryDelay)) {
+		t.Fatalf("critical delivery after 503 = %+v", d)
+	}
+	if d := fs.delivery("gone"); d == nil || d.Status != store.DeliveryFailed || d.Attempts != 1 {
+		t.Fatalf("gone delivery after 404 = %+v, want failed without retry", d)
+	}
+	if d := fs.delivery("declined"); d == nil || d.Status != store.DeliveryDelivered || d.EntryID != "r1" {
+		t.Fatalf("declined delivery = %+v", d)
+	}
+
+	// A second pass over the same cursor queues nothing new; the retry is
+	// sent once it is due.
+	now = now.Add(firstRetryDelay)
+	if err := w.Tick(ctx); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if d := fs.delivery("critical"); d.Status != store.DeliveryDelivered || d.Attempts != 2 {
+		t.Fatalf("critical delivery after retry = %+v", d)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	byPath := map[string][]received{}
+	for _, r := range got {
+		if !Verify(signer.PublicKey(), r.header, r.body) {
+			t.Errorf("%s: signature does not verify", r.path)
+		}
+		if r.header.Get(HeaderKeyID) != evidence.KeyID(signer.PublicKey()) {
+			t.Errorf("%s: key id = %q", r.path, r.header.Get(HeaderKeyID))
+		}
+		byPath[r.path] = append(byPath[r.path], r)
+	}
+	if n := len(byPath["/http"]); n != 2 {
+		t.Fatalf("/http received %d requests, want 2", n)
+	}
+	if string(byPath["/http"][0].body) != string(byPath["/http"][1].body) {
+		t.Fatal("retry sent a different body")
+	}
+	var httpBody struct {
+		RuleID string `json:"rule_id"`
+		Event  Event  `json:"event"`
+	}
+	_ = json.Unmarshal(byPath["/http"][1].body, &httpBody)
+	if httpBody.RuleID != "critical" || httpBody.Event.EntryID != "p1" || httpBody.Event.Tool != "kubectl" || httpBody.Event.Scope != "production" {
+		t.Fatalf("http body = %+v", httpBody)
+	}
+
+	if n := len(byPath["/slack"]); n != 2 {
+		t.Fatalf("/slack received %d requests, want one per declined report", n)
+	}
+	var slack struct{ Text string }
+	_ = json.Unmarshal(byPath["/slack"][0].body, &slack)
+	if !strings.Contains(slack.Text, "declined report — kubectl delete (production) by agent-1") {
+		t.Fatalf("slack text = %q", slack.Text)
+	}
+
+	if n := len(byPath["/ce"]); n != 1 {
+		t.Fatalf("/ce received %d requests, want 1", n)
+	}
+	ce := byPath["/ce"][0]
+	var cloudEvent struct {
+		SpecVersion string `json:"specversion"`
+		Type        string `json:"type"`
+		Subject     string `json:"subject"`
+	}
+	_ = json.Unmarshal(ce.body, &cloudEvent)
+	if ce.header.Get("Content-Type") != "application/cloudevents+json" || cloudEvent.SpecVersion != "1.0" ||
+		cloudEvent.Type != "dev.evidra.signal.protocol_violation" || cloudEvent.Subject != "r2" {
+		t.Fatalf("cloudevent = %+v (content type %q)", cloudEvent, ce.header.Get("Content-Type"))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	for attempts, want := range map[int]time.Duration{1: 30 * time.Second, 2: 2 * time.Minute, 3: 8 * time.Minute, 6: maxRetryDelay} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+// loopbackEgress lets workers under test reach httptest receivers.
+var loopbackEgress = EgressPolicy{Allowed: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}}
+
+func TestEgressPolicy_Permits(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		addr   string
+		policy EgressPolicy
+		want   bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1::1", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "fd00::1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "224.0.0.1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:10.0.0.1"},
+		{addr: "0.1.2.3"},
+		{addr: "100.64.0.1"},
+		{addr: "192.0.0.8"},
+		{addr: "192.0.2.1"},
+		{addr: "198.18.0.1"},
+		{addr: "198.51.100.7"},
+		{addr: "203.0.113.9"},
+		{addr: "240.0.0.1"},
+		{addr: "255.255.255.255"},
+		{addr: "::10.0.0.1"},
+		{addr: "64:ff9b::a00:1"},
+		{addr: "2001:db8::1"},
+		{addr: "2002:a00:1::1"},
+		{addr: "ff02::1"},
+		{addr: "100.64.0.1", policy: EgressPolicy{Allowed: []netip.Prefix{netip.MustParsePrefix("100.64.0.0/10")}}, want: true},
+		{addr: "127.0.0.1", policy: loopbackEgress, want: true},
+		{addr: "10.1.2.3", policy: EgressPolicy{Allowed: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}}, want: true},
+		{addr: "10.2.0.1", policy: EgressPolicy{Allowed: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}}},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Permits(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Permits(%s) with %v = %v, want %v", tt.addr, tt.policy.Allowed, got, tt.want)
+		}
+	}
+}
+
+func TestEgressPolicyFromEnv(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []netip.Prefix
+		wantErr bool
+	}{
+		{raw: ""},
+		{raw: " 10.0.0.0/8, fd00::1/8 ", want: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}},
+		{raw: "10.0.0.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv(allowedNetworksEnv, tt.raw)
+		got, err := EgressPolicyFromEnv()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("EgressPolicyFromEnv(%q) err = %v", tt.raw, err)
+		}
+		if !slices.Equal(got.Allowed, tt.want) {
+			t.Fatalf("EgressPolicyFromEnv(%q) = %v, want %v", tt.raw, got.Allowed, tt.want)
+		}
+	}
+}
+
+func TestWorker_BlocksInternalTargets(t *testing.T) {
+	t.Parallel()
+	var hits atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { hits.Add(1) }))
+	t.Cleanup(receiver.Close)
+	// A name that resolves to loopback is checked after resolution, not
+	// only as a literal address.
+	target := strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fs := &fakeStore{now: func() time.Time { return now }}
+	fs.rules = []store.NotificationRule{
+		{ID: "literal", Target: TargetHTTP, URL: receiver.URL, Conditions: store.NotificationConditions{MinRisk: "low"}},
+		{ID: "name", Target: TargetHTTP, URL: target, Conditions: store.NotificationConditions{MinRisk: "low"}},
+	}
+	action := json.RawMessage(`{"tool":"kubectl","operation":"delete","operation_class":"destroy","scope_class":"production"}`)
+	fs.add(t, testEntry(t, "p1", evidence.EntryTypePrescribe, evidence.PrescriptionPayload{CanonicalAction: action, EffectiveRisk: "critical"}))
+
+	w := NewWorker(fs, fs, nil, EgressPolicy{})
+	w.now = fs.now
+	if err := w.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	for _, id := range []string{"literal", "name"} {
+		if d := fs.delivery(id); d == nil || d.Status != store.DeliveryPending || !strings.Contains(d.LastError, ErrBlockedAddress.Error()) {
+			t.Fatalf("%s delivery = %+v, want blocked", id, d)
+		}
+	}
+	if hits.Load() != 0 {
+		t.Fatalf("receiver got %d requests, want none", hits.Load())
+	}
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/internal/store"
+)
+
+// ContentType returns the Content-Type a target's body is sent with.
+func ContentType(target string) string {
+	if target == TargetCloudEvents {
+		return "application/cloudevents+json"
+	}
+	return "application/json"
+}
+
+type httpBody struct {
+	RuleID   string `json:"rule_id"`
+	RuleName string `json:"rule_name,omitempty"`
+	Event    Event  `json:"event"`
+}
+
+type slackBody struct {
+	Text string `json:"text"`
+}
+
+// cloudEvent is a CloudEvents 1.0 event in structured JSON mode.
+type cloudEvent struct {
+	SpecVersion     string   `json:"specversion"`
+	ID              string   `json:"id"`
+	Source          string   `json:"source"`
+	Type            string   `json:"type"`
+	Subject         string   `json:"subject"`
+	Time            string   `json:"time"`
+	DataContentType string   `json:"datacontenttype"`
+	Data            httpBody `json:"data"`
+}
+
+// Render returns the body a rule sends for an event.
+func Render(rule store.NotificationRule, e Event) ([]byte, error) {
+	data := httpBody{RuleID: rule.ID, RuleName: rule.Name, Event: e}
+	switch rule.Target {
+	case TargetSlack:
+		return json.Marshal(slackBody{Text: Summary(rule, e)})
+	case TargetCloudEvents:
+		eventType := "dev.evidra.entry." + e.EntryType
+		if e.Kind == KindSignal {
+			eventType = "dev.evidra.signal." + e.Signal
+		}
+		return json.Marshal(cloudEvent{
+			SpecVersion:     "1.0",
+			ID:              rule.ID + "/" + e.key(),
+			Source:          "/evidra/tenants/" + e.TenantID,
+			Type:            eventType,
+			Subject:         e.EntryID,
+			Time:            e.Timestamp.UTC().Format(time.RFC3339Nano),
+			DataContentType: "application/json",
+			Data:            data,
+		})
+	case TargetHTTP:
+		return json.Marshal(data)
+	default:
+		return nil, fmt.Errorf("notify: unknown target %q", rule.Target)
+	}
+}
+
+// Summary is the one-line text of a chat notification.
+func Summary(rule store.NotificationRule, e Event) string {
+	var b strings.Builder
+	b.WriteString("Evidra")
+	if rule.Name != "" {
+		b.WriteString(" [" + rule.Name + "]")
+	}
+	b.WriteString(": ")
+	switch {
+	case e.Kind == KindSignal:
+		fmt.Fprintf(&b, "signal %s fired", e.Signal)
+	case e.EffectiveRisk != "":
+		fmt.Fprintf(&b, "%s-risk %s", e.EffectiveRisk, e.EntryType)
+	case e.Verdict != "":
+		fmt.Fprintf(&b, "%s %s", e.Verdict, e.EntryType)
+	default:
+		b.WriteString(e.EntryType)
+	}
+	if action := strings.TrimSpace(e.Tool + " " + e.Operation); action != "" {
+		b.WriteString(" — " + action)
+	}
+	if e.Scope != "" {
+		b.WriteString(" (" + e.Scope + ")")
+	}
+	if e.Actor != "" {
+		b.WriteString(" by " + e.Actor)
+	}
+	if e.SessionID != "" {
+		b.WriteString(", session " + e.SessionID)
+	}
+	b.WriteString(", entry " + e.EntryID)
+	return b.String()
+}
@@ -0,0 +1,128 @@
+// Package notify matches a tenant's new evidence against its notification
+// rules and delivers signed JSON to Slack-compatible, generic HTTP, or
+// CloudEvents endpoints.
+//
+// Matching follows the tenant's evidence stream from a stored cursor, so
+// every entry is considered once however it was ingested. Matches are queued
+// as deliveries and sent with retries; each attempt is logged on the
+// delivery.
+package notify
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"samebits.com/evidra/internal/risk"
+	"samebits.com/evidra/internal/signal"
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Delivery targets.
+const (
+	TargetSlack       = "slack"
+	TargetHTTP        = "http"
+	TargetCloudEvents = "cloudevents"
+)
+
+// Event kinds.
+const (
+	KindEntry  = "entry"
+	KindSignal = "signal"
+)
+
+// Event is a stored entry, or a signal it fired, as rules see it.
+type Event struct {
+	Kind          string    `json:"kind"`
+	TenantID      string    `json:"tenant_id"`
+	EntryID       string    `json:"entry_id"`
+	EntryType     string    `json:"entry_type"`
+	SessionID     string    `json:"session_id,omitempty"`
+	Actor         string    `json:"actor,omitempty"`
+	Tool          string    `json:"tool,omitempty"`
+	Operation     string    `json:"operation,omitempty"`
+	Scope         string    `json:"scope,omitempty"`
+	EffectiveRisk string    `json:"effective_risk,omitempty"`
+	Verdict       string    `json:"verdict,omitempty"`
+	Signal        string    `json:"signal,omitempty"`
+	FlaggedEntry  string    `json:"flagged_entry_id,omitempty"`
+	Timestamp     time.Time `json:"ts"`
+}
+
+// key identifies the event within a rule's deliveries.
+func (e Event) key() string {
+	if e.Kind == KindSignal {
+		return e.EntryID + "/" + e.Signal + "/" + e.FlaggedEntry
+	}
+	return e.EntryID
+}
+
+// Match reports whether the event satisfies every condition the rule sets.
+// Rules with signals match only signal events; other rules match entries.
+func Match(c store.NotificationConditions, e Event) bool {
+	if len(c.Signals) > 0 {
+		if e.Kind != KindSignal || !slices.Contains(c.Signals, e.Signal) {
+			return false
+		}
+	} else if e.Kind != KindEntry {
+		return false
+	}
+	if c.MinRisk != "" {
+		if _, ok := riskLevels[e.EffectiveRisk]; !ok || risk.SeverityHigherThan(c.MinRisk, e.EffectiveRisk) {
+			return false
+		}
+	}
+	if len(c.Verdicts) > 0 && !slices.Contains(c.Verdicts, e.Verdict) {
+		return false
+	}
+	if len(c.Scopes) > 0 && !slices.Contains(c.Scopes, e.Scope) {
+		return false
+	}
+	return true
+}
+
+var riskLevels = map[string]struct{}{"low": {}, "medium": {}, "high": {}, "critical": {}}
+
+// ValidateRule checks a rule before it is stored. The URL's address is
+// checked against the worker's EgressPolicy each time it is dialed.
+func ValidateRule(r store.NotificationRule) error {
+	switch r.Target {
+	case TargetSlack, TargetHTTP, TargetCloudEvents:
+	default:
+		return fmt.Errorf("target must be %s, %s, or %s", TargetSlack, TargetHTTP, TargetCloudEvents)
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	if len(r.Name) > 128 {
+		return fmt.Errorf("name too long (max 128)")
+	}
+
+	c := r.Conditions
+	if c.MinRisk == "" && len(c.Signals) == 0 && len(c.Verdicts) == 0 {
+		return fmt.Errorf("conditions must set min_risk, signals, or verdicts")
+	}
+	if len(c.Signals) > 0 && (c.MinRisk != "" || len(c.Verdicts) > 0) {
+		return fmt.Errorf("signals cannot be combined with min_risk or verdicts")
+	}
+	if c.MinRisk != "" {
+		if _, ok := riskLevels[c.MinRisk]; !ok {
+			return fmt.Errorf("invalid min_risk %q (expected low|medium|high|critical)", c.MinRisk)
+		}
+	}
+	known := signal.RegisteredSignalNames()
+	for _, name := range c.Signals {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("unknown signal %q", name)
+		}
+	}
+	for _, v := range c.Verdicts {
+		if !evidence.Verdict(v).Valid() {
+			return fmt.Errorf("invalid verdict %q", v)
+		}
+	}
+	return nil
+}
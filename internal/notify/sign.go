@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"time"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Signature headers. The signature is Ed25519 over "<timestamp>.<body>"
+// with the server's signing key, whose public half is served by
+// GET /v1/evidence/pubkey under the key ID.
+const (
+	HeaderTimestamp = "X-Evidra-Timestamp"
+	HeaderKeyID     = "X-Evidra-Key-Id"
+	HeaderSignature = "X-Evidra-Signature"
+)
+
+func signedMessage(timestamp string, body []byte) []byte {
+	msg := make([]byte, 0, len(timestamp)+1+len(body))
+	msg = append(msg, timestamp...)
+	msg = append(msg, '.')
+	return append(msg, body...)
+}
+
+// sign sets the signature headers on req.
+func sign(req *http.Request, signer evidence.Signer, body []byte, now time.Time) error {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	sig, err := evidence.SignPayload(signer, signedMessage(timestamp, body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderKeyID, evidence.KeyID(signer.PublicKey()))
+	req.Header.Set(HeaderSignature, base64.StdEncoding.EncodeToString(sig))
+	return nil
+}
+
+// Verify checks a notification's signature headers against pub. Receivers
+// should also reject timestamps too far from their own clock.
+func Verify(pub ed25519.PublicKey, h http.Header, body []byte) bool {
+	sig, err := base64.StdEncoding.DecodeString(h.Get(HeaderSignature))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(pub, signedMessage(h.Get(HeaderTimestamp), body), sig)
+}
@@ -0,0 +1,268 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"samebits.com/evidra/internal/pipeline"
+	"samebits.com/evidra/internal/store"
+	"samebits.com/evidra/internal/stream"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Store persists rules, cursors, and deliveries.
+type Store interface {
+	NotificationTenants(ctx context.Context) ([]string, error)
+	EnabledRules(ctx context.Context, tenantID string) ([]store.NotificationRule, error)
+	DispatchNotifications(ctx context.Context, tenantID string, fn func(after int64) (int64, []store.NotificationDelivery, error)) error
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]store.NotificationDelivery, error)
+	RecordDelivery(ctx context.Context, deliveryID string, a store.DeliveryAttempt) error
+}
+
+// Entries reads a tenant's evidence stream.
+type Entries interface {
+	EntriesSince(ctx context.Context, tenantID string, after int64, f store.StreamFilter, limit int) ([]store.StreamEntry, error)
+	SessionHistory(ctx context.Context, tenantID, sessionID string, before int64, limit int) ([]evidence.EvidenceEntry, error)
+}
+
+const (
+	dispatchBatchSize  = 200
+	historyLimit       = 500
+	sendBatchSize      = 20
+	sendLease          = 2 * time.Minute
+	maxAttempts        = 6
+	firstRetryDelay    = 30 * time.Second
+	maxRetryDelay      = 2 * time.Hour
+	defaultPollEvery   = 2 * time.Second
+	defaultSendTimeout = 10 * time.Second
+)
+
+// Worker matches new entries against rules and sends queued deliveries.
+type Worker struct {
+	store   Store
+	entries Entries
+	signer  evidence.Signer
+	client  *http.Client
+	now     func() time.Time
+}
+
+// NewWorker creates a Worker that sends only to addresses egress permits.
+// With a nil signer notifications are sent unsigned.
+func NewWorker(s Store, entries Entries, signer evidence.Signer, egress EgressPolicy) *Worker {
+	return &Worker{
+		store:   s,
+		entries: entries,
+		signer:  signer,
+		client:  egress.client(defaultSendTimeout),
+		now:     time.Now,
+	}
+}
+
+// Run calls Tick every few seconds until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(defaultPollEvery)
+	defer ticker.Stop()
+	for {
+		if err := w.Tick(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("notifications", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick queues deliveries for every tenant's new entries, then sends the
+// deliveries that are due. A tenant that fails is retried next tick without
+// holding up the others.
+func (w *Worker) Tick(ctx context.Context) error {
+	tenants, err := w.store.NotificationTenants(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, tenantID := range tenants {
+		if err := w.dispatch(ctx, tenantID); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+		}
+	}
+	return errors.Join(append(errs, w.send(ctx))...)
+}
+
+// dispatch matches the tenant's entries after its cursor against its rules.
+func (w *Worker) dispatch(ctx context.Context, tenantID string) error {
+	rules, err := w.store.EnabledRules(ctx, tenantID)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	for more := true; more; {
+		more = false
+		err := w.store.DispatchNotifications(ctx, tenantID, func(after int64) (int64, []store.NotificationDelivery, error) {
+			batch, err := w.entries.EntriesSince(ctx, tenantID, after, store.StreamFilter{}, dispatchBatchSize)
+			if err != nil {
+				return after, nil, err
+			}
+			var deliveries []store.NotificationDelivery
+			for _, se := range batch {
+				after = se.Seq
+				events, err := w.events(ctx, tenantID, se)
+				if err != nil {
+					return after, nil, err
+				}
+				for _, rule := range rules {
+					for _, e := range events {
+						if !Match(rule.Conditions, e) {
+							continue
+						}
+						body, err := Render(rule, e)
+						if err != nil {
+							return after, nil, err
+						}
+						deliveries = append(deliveries, store.NotificationDelivery{
+							RuleID:   rule.ID,
+							EventKey: e.key(),
+							EntryID:  e.EntryID,
+							Signal:   e.Signal,
+							Target:   rule.Target,
+							URL:      rule.URL,
+							Body:     body,
+						})
+					}
+				}
+			}
+			more = len(batch) == dispatchBatchSize
+			return after, deliveries, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// events returns the entry event and the signal events the entry fired.
+// Reports take their tool and scope from the prescription in the session.
+func (w *Worker) events(ctx context.Context, tenantID string, se store.StreamEntry) ([]Event, error) {
+	var entry evidence.EvidenceEntry
+	if err := json.Unmarshal(se.Entry.Payload, &entry); err != nil {
+		// Not an evidence entry (e.g. a legacy raw payload); nothing to match.
+		return nil, nil
+	}
+	var history []evidence.EvidenceEntry
+	if entry.SessionID != "" {
+		var err error
+		if history, err = w.entries.SessionHistory(ctx, tenantID, entry.SessionID, se.Seq, historyLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	e := Event{
+		Kind:      KindEntry,
+		TenantID:  tenantID,
+		EntryID:   entry.EntryID,
+		EntryType: string(entry.Type),
+		SessionID: entry.SessionID,
+		Actor:     entry.Actor.ID,
+		Timestamp: entry.Timestamp,
+	}
+	switch entry.Type {
+	case evidence.EntryTypePrescribe:
+		var p evidence.PrescriptionPayload
+		if json.Unmarshal(entry.Payload, &p) == nil {
+			e.EffectiveRisk = p.EffectiveRisk
+		}
+	case evidence.EntryTypeReport:
+		var r evidence.ReportPayload
+		if json.Unmarshal(entry.Payload, &r) == nil {
+			e.Verdict = string(r.Verdict)
+		}
+	}
+	withEntry := append(history[:len(history):len(history)], entry)
+	if signalEntries, err := pipeline.EvidenceToSignalEntries(withEntry); err == nil && len(signalEntries) > 0 {
+		last := signalEntries[len(signalEntries)-1]
+		e.Tool, e.Operation, e.Scope = last.Tool, last.Operation, last.ScopeClass
+	}
+
+	events := []Event{e}
+	if entry.SessionID == "" {
+		return events, nil
+	}
+	// Entries the detectors cannot decode fire no signals.
+	signals, _ := stream.NewSignals(history, entry)
+	for _, s := range signals {
+		sig := e
+		sig.Kind = KindSignal
+		sig.Signal = s.Signal
+		sig.FlaggedEntry = s.EntryID
+		events = append(events, sig)
+	}
+	return events, nil
+}
+
+// send delivers the due deliveries and records each attempt.
+func (w *Worker) send(ctx context.Context) error {
+	deliveries, err := w.store.ClaimDeliveries(ctx, sendBatchSize, sendLease)
+	if err != nil {
+		return err
+	}
+	for _, d := range deliveries {
+		attempt := w.deliver(ctx, d)
+		if !attempt.Delivered && d.Attempts+1 < maxAttempts && retryable(attempt.StatusCode) {
+			retryAt := w.now().Add(retryDelay(d.Attempts + 1))
+			attempt.RetryAt = &retryAt
+		}
+		if err := w.store.RecordDelivery(ctx, d.ID, attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Worker) deliver(ctx context.Context, d store.NotificationDelivery) store.DeliveryAttempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return store.DeliveryAttempt{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", ContentType(d.Target))
+	req.Header.Set("User-Agent", "evidra-notifications")
+	if w.signer != nil {
+		if err := sign(req, w.signer, d.Body, w.now()); err != nil {
+			return store.DeliveryAttempt{Error: "sign: " + err.Error()}
+		}
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return store.DeliveryAttempt{Error: err.Error()}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return store.DeliveryAttempt{StatusCode: resp.StatusCode, Error: resp.Status}
+	}
+	return store.DeliveryAttempt{StatusCode: resp.StatusCode, Delivered: true}
+}
+
+// retryable reports whether an attempt that got statusCode (0 for no
+// response) may succeed later. Other client errors will not.
+func retryable(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryDelay is the wait after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	d := firstRetryDelay
+	for i := 1; i < attempts && d < maxRetryDelay; i++ {
+		d *= 4
+	}
+	return min(d, maxRetryDelay)
+}
@@ -0,0 +1,160 @@
+// Package outbox tracks which local evidence entries an Evidra API has
+// acknowledged and pushes the rest.
+//
+// The cursor for each API URL lives in <store-dir>/outbox.json next to the
+// evidence store. It records how many chain entries the API has accepted and
+// the hash of the last one, so a sync resumes exactly where the previous one
+// stopped, across restarts and offline periods.
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/evlock"
+)
+
+const (
+	// FileName is the cursor file kept in the evidence store directory.
+	FileName     = "outbox.json"
+	lockFileName = ".outbox.lock"
+)
+
+// Cursor is the acknowledged position of one API in the local chain.
+type Cursor struct {
+	URL string `json:"url"`
+	// Acked is the number of leading chain entries the API has processed:
+	// accepted, quarantined, or rejected for good.
+	Acked       int    `json:"acked"`
+	LastEntryID string `json:"last_entry_id,omitempty"`
+	LastHash    string `json:"last_hash,omitempty"`
+	// Rejected lists the entries the API refused after verifying them. They
+	// are not resent; the cursor moves past them so later entries still
+	// sync.
+	Rejected  []Rejection `json:"rejected,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Rejection records one entry the API refused, with the reason it gave.
+type Rejection struct {
+	EntryID    string    `json:"entry_id"`
+	Hash       string    `json:"hash"`
+	Code       string    `json:"code"`
+	Message    string    `json:"message,omitempty"`
+	RejectedAt time.Time `json:"rejected_at"`
+}
+
+type cursorFile struct {
+	Cursors map[string]Cursor `json:"cursors"`
+}
+
+// Path returns the cursor file for the evidence store at evidencePath.
+func Path(evidencePath string) string {
+	return filepath.Join(evidence.StoreDir(evidencePath), FileName)
+}
+
+// NormalizeURL returns the cursor key for an API base URL.
+func NormalizeURL(url string) string {
+	return strings.TrimRight(strings.TrimSpace(url), "/")
+}
+
+// LoadCursor returns the cursor for url, or a zero cursor when nothing has
+// been acknowledged yet.
+func LoadCursor(evidencePath, url string) (Cursor, error) {
+	f, err := readCursorFile(Path(evidencePath))
+	if err != nil {
+		return Cursor{}, err
+	}
+	key := NormalizeURL(url)
+	c, ok := f.Cursors[key]
+	if !ok {
+		return Cursor{URL: key}, nil
+	}
+	return c, nil
+}
+
+// ResetCursor forgets the acknowledged position and the rejected entries for
+// url, so the next sync resends the whole chain.
+func ResetCursor(evidencePath, url string) error {
+	return withLock(evidencePath, 0, func() error {
+		path := Path(evidencePath)
+		f, err := readCursorFile(path)
+		if err != nil {
+			return err
+		}
+		delete(f.Cursors, NormalizeURL(url))
+		return writeCursorFile(path, f)
+	})
+}
+
+func saveCursor(evidencePath string, c Cursor) error {
+	path := Path(evidencePath)
+	f, err := readCursorFile(path)
+	if err != nil {
+		return err
+	}
+	c.URL = NormalizeURL(c.URL)
+	c.UpdatedAt = time.Now().UTC()
+	f.Cursors[c.URL] = c
+	return writeCursorFile(path, f)
+}
+
+func readCursorFile(path string) (cursorFile, error) {
+	f := cursorFile{Cursors: map[string]Cursor{}}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return cursorFile{}, fmt.Errorf("read outbox cursor: %w", err)
+	}
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return cursorFile{}, fmt.Errorf("parse outbox cursor %s: %w", path, err)
+	}
+	if f.Cursors == nil {
+		f.Cursors = map[string]Cursor{}
+	}
+	return f, nil
+}
+
+func writeCursorFile(path string, f cursorFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal outbox cursor: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create outbox directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write outbox cursor: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit outbox cursor: %w", err)
+	}
+	return nil
+}
+
+// withLock serializes syncs of one evidence store across processes, so two
+// pushers never race on the cursor.
+func withLock(evidencePath string, timeout time.Duration, fn func() error) error {
+	dir := evidence.StoreDir(evidencePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create outbox directory: %w", err)
+	}
+	lock, err := evlock.Acquire(filepath.Join(dir, lockFileName), timeout)
+	if err != nil {
+		if errors.Is(err, evlock.ErrBusy) {
+			return ErrBusy
+		}
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+	return fn()
+}
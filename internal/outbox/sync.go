@@ -0,0 +1,388 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"samebits.com/evidra/pkg/client"
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/version"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultBaseDelay   = time.Second
+	defaultMaxDelay    = time.Minute
+	defaultLockTimeout = 2 * time.Second
+)
+
+var (
+	// ErrBusy is returned when another process is syncing the same store.
+	ErrBusy = errors.New("outbox_busy")
+	// ErrCursorMismatch is returned when the acknowledged position no
+	// longer matches the local chain (for example, the store was replaced).
+	ErrCursorMismatch = errors.New("outbox_cursor_mismatch")
+	// ErrBatchRejected is returned when the API could not store every entry
+	// of a batch. The batch is retried on the next attempt.
+	ErrBatchRejected = errors.New("outbox_batch_rejected")
+)
+
+// Pusher sends entry batches to an Evidra API. *client.Client implements it.
+type Pusher interface {
+	URL() string
+	Batch(ctx context.Context, entries []json.RawMessage) (client.BatchResponse, error)
+}
+
+// Syncer pushes un-acknowledged entries from one evidence store to one API.
+type Syncer struct {
+	EvidencePath string
+	Client       Pusher
+	// Signer signs the receipt entries written back after each successful
+	// sync. Receipts are skipped when nil.
+	Signer evidence.Signer
+	// Actor is recorded on receipt entries.
+	Actor evidence.Actor
+	// BatchSize caps entries per Batch call (default 100).
+	BatchSize int
+	// BaseDelay and MaxDelay bound the exponential backoff between failed
+	// attempts (defaults 1s and 1m).
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Logger    *slog.Logger
+
+	notifyOnce sync.Once
+	notify     chan struct{}
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// Result summarizes one sync pass.
+type Result struct {
+	URL string `json:"url"`
+	// Sent is the number of entries the API acknowledged in this pass.
+	Sent int `json:"sent"`
+	// Rejected lists the entries the API refused in this pass. The cursor
+	// has moved past them and they are kept in Cursor.Rejected.
+	Rejected []Rejection `json:"rejected,omitempty"`
+	// Pending is the number of entries still un-acknowledged.
+	Pending int    `json:"pending"`
+	Cursor  Cursor `json:"cursor"`
+	// ReceiptEntryID is the local receipt entry written for this pass.
+	ReceiptEntryID string `json:"receipt_entry_id,omitempty"`
+}
+
+// SyncOnce makes a single attempt to push every un-acknowledged entry. The
+// cursor advances after each accepted batch, so a failure part-way keeps the
+// progress made so far; it returns the partial Result with the error.
+func (s *Syncer) SyncOnce(ctx context.Context) (Result, error) {
+	url := NormalizeURL(s.Client.URL())
+	res := Result{URL: url}
+	err := withLock(s.EvidencePath, defaultLockTimeout, func() error {
+		var err error
+		res, err = s.syncLocked(ctx, url)
+		return err
+	})
+	return res, err
+}
+
+// Sync calls SyncOnce up to maxAttempts times, backing off exponentially
+// after retryable failures (unreachable API, server errors, rate limiting,
+// partially rejected batches, a concurrent sync).
+func (s *Syncer) Sync(ctx context.Context, maxAttempts int) (Result, error) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	var (
+		total Result
+		err   error
+	)
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := s.sleepFor(ctx, s.backoff(attempt)); sleepErr != nil {
+				return total, sleepErr
+			}
+		}
+		var res Result
+		res, err = s.SyncOnce(ctx)
+		sent, rejected := total.Sent+res.Sent, append(total.Rejected, res.Rejected...)
+		total = res
+		total.Sent, total.Rejected = sent, rejected
+		if err == nil || !Retryable(err) {
+			return total, err
+		}
+		s.logger().Warn("outbox sync failed", "url", total.URL, "attempt", attempt+1, "error", err)
+	}
+	return total, err
+}
+
+// Notify asks a running Run loop to sync now. It never blocks.
+func (s *Syncer) Notify() {
+	s.initNotify()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run syncs every interval and whenever Notify is called, backing off after
+// failures, until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	s.initNotify()
+	failures := 0
+	for {
+		res, err := s.SyncOnce(ctx)
+		wait := interval
+		switch {
+		case err == nil:
+			failures = 0
+			if res.Sent > 0 {
+				s.logger().Info("outbox synced", "url", res.URL, "sent", res.Sent)
+			}
+			for _, r := range res.Rejected {
+				s.logger().Warn("outbox entry rejected by the API", "url", res.URL, "entry_id", r.EntryID, "code", r.Code, "message", r.Message)
+			}
+		case errors.Is(err, ErrBusy):
+			// Another process is pushing this store.
+		default:
+			failures++
+			if backoff := s.backoff(failures); backoff > wait {
+				wait = backoff
+			}
+			s.logger().Warn("outbox sync failed", "url", res.URL, "pending", res.Pending, "retry_in", wait, "error", err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.notify:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Retryable reports whether a sync error may succeed on a later attempt.
+func Retryable(err error) bool {
+	return client.IsReachabilityError(err) ||
+		errors.Is(err, client.ErrRateLimited) ||
+		errors.Is(err, ErrBatchRejected) ||
+		errors.Is(err, ErrBusy)
+}
+
+func (s *Syncer) syncLocked(ctx context.Context, url string) (Result, error) {
+	res := Result{URL: url}
+	cursor, err := LoadCursor(s.EvidencePath, url)
+	if err != nil {
+		return res, err
+	}
+	res.Cursor = cursor
+
+	pending, err := s.pendingEntries(cursor)
+	if err != nil {
+		return res, err
+	}
+	res.Pending = len(pending)
+	if len(pending) == 0 {
+		return res, nil
+	}
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	var (
+		acked        []evidence.EvidenceEntry
+		processed    int
+		pushErr      error
+		onlyReceipts = true
+	)
+	for start := 0; start < len(pending); start += batchSize {
+		end := min(start+batchSize, len(pending))
+		batch := pending[start:end]
+		var rejected []Rejection
+		if rejected, pushErr = s.push(ctx, batch); pushErr != nil {
+			break
+		}
+		last := batch[len(batch)-1]
+		cursor.Acked += len(batch)
+		cursor.LastEntryID = last.EntryID
+		cursor.LastHash = last.Hash
+		cursor.Rejected = append(cursor.Rejected, rejected...)
+		if pushErr = saveCursor(s.EvidencePath, cursor); pushErr != nil {
+			break
+		}
+		processed += len(batch)
+		res.Rejected = append(res.Rejected, rejected...)
+		for _, e := range batch {
+			if slices.ContainsFunc(rejected, func(r Rejection) bool { return r.EntryID == e.EntryID }) {
+				continue
+			}
+			acked = append(acked, e)
+			if e.Type != evidence.EntryTypeReceipt {
+				onlyReceipts = false
+			}
+		}
+	}
+	res.Cursor = cursor
+	res.Sent = len(acked)
+	res.Pending = len(pending) - processed
+
+	// A pass that only pushed earlier receipts writes none of its own, so
+	// the outbox drains instead of producing a receipt per sync forever.
+	if len(acked) > 0 && !onlyReceipts && s.Signer != nil {
+		receipt, err := s.writeReceipt(url, acked, cursor)
+		if err != nil {
+			return res, errors.Join(pushErr, fmt.Errorf("write receipt: %w", err))
+		}
+		res.ReceiptEntryID = receipt.EntryID
+	}
+	return res, pushErr
+}
+
+// pendingEntries returns the chain entries after the cursor, verifying that
+// the acknowledged prefix is still the prefix of the local chain.
+func (s *Syncer) pendingEntries(cursor Cursor) ([]evidence.EvidenceEntry, error) {
+	var (
+		pending []evidence.EvidenceEntry
+		index   int
+	)
+	err := evidence.ForEachEntryAtPath(s.EvidencePath, func(e evidence.EvidenceEntry) error {
+		index++
+		if index < cursor.Acked {
+			return nil
+		}
+		if index == cursor.Acked {
+			if e.Hash != cursor.LastHash {
+				return fmt.Errorf("%w: entry %d hash %s, acknowledged %s", ErrCursorMismatch, index-1, e.Hash, cursor.LastHash)
+			}
+			return nil
+		}
+		pending = append(pending, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if index < cursor.Acked {
+		return nil, fmt.Errorf("%w: chain has %d entries, %d acknowledged", ErrCursorMismatch, index, cursor.Acked)
+	}
+	return pending, nil
+}
+
+// push sends one batch. Entries the API refused after verifying them (for
+// example, signed by an unregistered key) fail the same way when resent, so
+// they are returned as rejections and the batch still counts as delivered.
+// Anything else short of full delivery is retried as ErrBatchRejected.
+func (s *Syncer) push(ctx context.Context, batch []evidence.EvidenceEntry) ([]Rejection, error) {
+	raw := make([]json.RawMessage, len(batch))
+	for i, e := range batch {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("marshal entry %s: %w", e.EntryID, err)
+		}
+		raw[i] = b
+	}
+	resp, err := s.Client.Batch(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	// Quarantined entries are kept by the API for review; resending them
+	// cannot change the outcome, so they count as delivered.
+	if resp.Quarantined > 0 {
+		s.logger().Warn("outbox entries quarantined by the API", "url", s.Client.URL(), "quarantined", resp.Quarantined, "errors", resp.Errors)
+	}
+	if resp.Accepted+resp.Quarantined == len(batch) {
+		return nil, nil
+	}
+	var rejected []Rejection
+	now := time.Now().UTC()
+	for _, r := range resp.Results {
+		if r.Status != "rejected" || r.Code == "store_failed" || r.Index < 0 || r.Index >= len(batch) {
+			continue
+		}
+		e := batch[r.Index]
+		rejected = append(rejected, Rejection{EntryID: e.EntryID, Hash: e.Hash, Code: r.Code, Message: r.Message, RejectedAt: now})
+	}
+	if resp.Accepted+resp.Quarantined+len(rejected) != len(batch) {
+		return nil, fmt.Errorf("%w: %d of %d accepted: %v", ErrBatchRejected, resp.Accepted, len(batch), resp.Errors)
+	}
+	return rejected, nil
+}
+
+func (s *Syncer) writeReceipt(url string, acked []evidence.EvidenceEntry, cursor Cursor) (evidence.EvidenceEntry, error) {
+	payload, err := json.Marshal(evidence.ReceiptPayload{
+		APIURL:        url,
+		EntriesAcked:  len(acked),
+		FirstEntryID:  acked[0].EntryID,
+		LastEntryID:   cursor.LastEntryID,
+		LastHash:      cursor.LastHash,
+		ChainPosition: cursor.Acked,
+	})
+	if err != nil {
+		return evidence.EvidenceEntry{}, err
+	}
+	sessionID := evidence.GenerateSessionID()
+	actor := s.Actor
+	if actor.ID == "" {
+		actor = evidence.Actor{Type: "system", ID: "evidra-sync", Provenance: "outbox"}
+	}
+	return evidence.AppendAtPath(s.EvidencePath, evidence.EntryBuildParams{
+		Type:           evidence.EntryTypeReceipt,
+		SessionID:      sessionID,
+		TraceID:        sessionID,
+		Actor:          actor,
+		Payload:        payload,
+		SpecVersion:    version.SpecVersion,
+		AdapterVersion: version.Version,
+		Signer:         s.Signer,
+	})
+}
+
+// backoff returns the delay before attempt n (n >= 1): BaseDelay doubled
+// per attempt, capped at MaxDelay.
+func (s *Syncer) backoff(n int) time.Duration {
+	base, maxDelay := s.BaseDelay, s.MaxDelay
+	if base <= 0 {
+		base = defaultBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+	d := base
+	for i := 1; i < n && d < maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxDelay)
+}
+
+func (s *Syncer) sleepFor(ctx context.Context, d time.Duration) error {
+	if s.sleep != nil {
+		return s.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (s *Syncer) initNotify() {
+	s.notifyOnce.Do(func() { s.notify = make(chan struct{}, 1) })
+}
+
+func (s *Syncer) logger() *slog.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,401 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/client"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// fakeAPI stores entries by ID, so resent entries are idempotent like the
+// real API. failures makes the next calls fail with the given errors;
+// verdict, when set, is reported for every entry instead of accepting it.
+type fakeAPI struct {
+	mu       sync.Mutex
+	entries  map[string]evidence.EvidenceEntry
+	order    []string
+	calls    int
+	failures []error
+	verdict  client.EntryResult
+	// reject refuses the entries with these IDs, with the given code.
+	reject map[string]string
+}
+
+func newFakeAPI() *fakeAPI { return &fakeAPI{entries: map[string]evidence.EvidenceEntry{}} }
+
+func (f *fakeAPI) URL() string { return "https://api.example.com/" }
+
+func (f *fakeAPI) Batch(_ context.Context, raw []json.RawMessage) (client.BatchResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if len(f.failures) > 0 {
+		err := f.failures[0]
+		f.failures = f.failures[1:]
+		if err != nil {
+			return client.BatchResponse{}, err
+		}
+	}
+	if f.verdict.Status != "" {
+		resp := client.BatchResponse{}
+		for i := range raw {
+			r := f.verdict
+			r.Index = i
+			resp.Results = append(resp.Results, r)
+			if r.Status == "quarantined" {
+				resp.Quarantined++
+			} else {
+				resp.Rejected++
+			}
+		}
+		return resp, nil
+	}
+	resp := client.BatchResponse{}
+	for i, r := range raw {
+		var e evidence.EvidenceEntry
+		if err := json.Unmarshal(r, &e); err != nil {
+			return client.BatchResponse{}, err
+		}
+		if code, ok := f.reject[e.EntryID]; ok {
+			resp.Rejected++
+			resp.Results = append(resp.Results, client.EntryResult{Index: i, EntryID: e.EntryID, Status: "rejected", Code: code})
+			continue
+		}
+		if _, dup := f.entries[e.EntryID]; !dup {
+			f.order = append(f.order, e.EntryID)
+		}
+		f.entries[e.EntryID] = e
+		resp.Accepted++
+		resp.Results = append(resp.Results, client.EntryResult{Index: i, EntryID: e.EntryID, Status: "accepted"})
+	}
+	return resp, nil
+}
+
+func appendEntries(t *testing.T, dir string, signer evidence.Signer, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		_, err := evidence.AppendAtPath(dir, evidence.EntryBuildParams{
+			Type:      evidence.EntryTypeSignal,
+			SessionID: "s",
+			TraceID:   "s",
+			Actor:     evidence.Actor{Type: "agent", ID: "a", Provenance: "test"},
+			Payload:   []byte(`{"signal_name":"retry_loop"}`),
+			Signer:    signer,
+		})
+		if err != nil {
+			t.Fatalf("AppendAtPath: %v", err)
+		}
+	}
+}
+
+func noSleep(context.Context, time.Duration) error { return nil }
+
+func TestSync_PushesPendingAndWritesReceipt(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := testutil.TestSigner(t)
+	appendEntries(t, dir, signer, 5)
+	api := newFakeAPI()
+	s := &Syncer{EvidencePath: dir, Client: api, Signer: signer, BatchSize: 2, sleep: noSleep}
+
+	res, err := s.Sync(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Sent != 5 || res.Pending != 0 || res.Cursor.Acked != 5 {
+		t.Fatalf("result = %+v", res)
+	}
+	if api.calls != 3 {
+		t.Fatalf("batch calls = %d, want 3", api.calls)
+	}
+	receipt, ok, err := evidence.FindEntryByID(dir, res.ReceiptEntryID)
+	if err != nil || !ok {
+		t.Fatalf("receipt entry not found: %v", err)
+	}
+	var payload evidence.ReceiptPayload
+	if err := json.Unmarshal(receipt.Payload, &payload); err != nil {
+		t.Fatalf("decode receipt: %v", err)
+	}
+	if payload.APIURL != "https://api.example.com" || payload.EntriesAcked != 5 || payload.ChainPosition != 5 || payload.LastHash != res.Cursor.LastHash {
+		t.Fatalf("receipt payload = %+v", payload)
+	}
+
+	// The receipt itself is pushed next, without writing another receipt.
+	res, err = s.SyncOnce(context.Background())
+	if err != nil {
+		t.Fatalf("SyncOnce: %v", err)
+	}
+	if res.Sent != 1 || res.ReceiptEntryID != "" {
+		t.Fatalf("second pass = %+v, want the receipt only", res)
+	}
+	res, err = s.SyncOnce(context.Background())
+	if err != nil || res.Sent != 0 {
+		t.Fatalf("third pass = %+v, %v; want nothing to send", res, err)
+	}
+	if len(api.order) != 6 {
+		t.Fatalf("api has %d entries, want 6", len(api.order))
+	}
+	if err := evidence.ValidateChainAtPath(dir); err != nil {
+		t.Fatalf("ValidateChainAtPath: %v", err)
+	}
+}
+
+func TestSync_RetriesWithBackoffAndKeepsProgress(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := testutil.TestSigner(t)
+	appendEntries(t, dir, signer, 4)
+	api := newFakeAPI()
+	api.failures = []error{nil, client.ErrUnreachable, client.ErrServerError}
+
+	var delays []time.Duration
+	s := &Syncer{
+		EvidencePath: dir,
+		Client:       api,
+		Signer:       signer,
+		BatchSize:    2,
+		sleep: func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+	res, err := s.Sync(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	// The first attempt acknowledged one batch and recorded a receipt for
+	// it, which the final attempt pushes along with the rest.
+	if res.Sent != 5 || res.Cursor.Acked != 5 || res.Pending != 0 {
+		t.Fatalf("result = %+v", res)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !equalDurations(delays, want) {
+		t.Fatalf("delays = %v, want %v", delays, want)
+	}
+	if len(api.order) != 5 {
+		t.Fatalf("api has %d entries, want 5", len(api.order))
+	}
+}
+
+func TestSync_NonRetryableErrorStopsImmediately(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	appendEntries(t, dir, testutil.TestSigner(t), 1)
+	api := newFakeAPI()
+	api.failures = []error{client.ErrUnauthorized}
+	s := &Syncer{EvidencePath: dir, Client: api, sleep: noSleep}
+
+	res, err := s.Sync(context.Background(), 5)
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("err = %v, want unauthorized", err)
+	}
+	if api.calls != 1 || res.Pending != 1 {
+		t.Fatalf("calls = %d, result = %+v", api.calls, res)
+	}
+	cursor, err := LoadCursor(dir, api.URL())
+	if err != nil || cursor.Acked != 0 {
+		t.Fatalf("cursor = %+v, %v", cursor, err)
+	}
+}
+
+func TestSync_VerificationOutcomes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		verdict      client.EntryResult
+		wantErr      error
+		wantAcked    int
+		wantRejected int
+	}{
+		{
+			name:         "rejected entry is dead-lettered",
+			verdict:      client.EntryResult{Status: "rejected", Code: "unknown_key", Message: "entry_unknown_key"},
+			wantAcked:    2,
+			wantRejected: 2,
+		},
+		{
+			name:    "store failure is retried",
+			verdict: client.EntryResult{Status: "rejected", Code: "store_failed"},
+			wantErr: ErrBatchRejected,
+		},
+		{
+			name:      "quarantined entry counts as delivered",
+			verdict:   client.EntryResult{Status: "quarantined", Code: "chain_break"},
+			wantAcked: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			appendEntries(t, dir, testutil.TestSigner(t), 2)
+			api := newFakeAPI()
+			api.verdict = tt.verdict
+			s := &Syncer{EvidencePath: dir, Client: api, sleep: noSleep}
+
+			res, err := s.Sync(context.Background(), 3)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if res.Cursor.Acked != tt.wantAcked || len(res.Rejected) != tt.wantRejected {
+				t.Fatalf("acked = %d, rejected = %+v; want %d, %d", res.Cursor.Acked, res.Rejected, tt.wantAcked, tt.wantRejected)
+			}
+			wantCalls := 1
+			if errors.Is(tt.wantErr, ErrBatchRejected) {
+				wantCalls = 3
+			}
+			if api.calls != wantCalls {
+				t.Fatalf("batch calls = %d, want %d", api.calls, wantCalls)
+			}
+		})
+	}
+}
+
+func TestSync_DeadLettersRejectedEntriesAndKeepsSyncing(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := testutil.TestSigner(t)
+	appendEntries(t, dir, signer, 3)
+	entries, err := evidence.ReadAllEntriesAtPath(dir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	api := newFakeAPI()
+	api.reject = map[string]string{entries[1].EntryID: "unknown_key"}
+	s := &Syncer{EvidencePath: dir, Client: api, BatchSize: 2, sleep: noSleep}
+
+	res, err := s.Sync(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Sent != 2 || res.Pending != 0 || res.Cursor.Acked != 3 {
+		t.Fatalf("result = %+v", res)
+	}
+	if len(res.Rejected) != 1 || res.Rejected[0].EntryID != entries[1].EntryID || res.Rejected[0].Code != "unknown_key" {
+		t.Fatalf("rejected = %+v", res.Rejected)
+	}
+
+	// Entries written after the rejected one still reach the API, and the
+	// rejection stays on the cursor.
+	appendEntries(t, dir, signer, 1)
+	res, err = s.SyncOnce(context.Background())
+	if err != nil || res.Sent != 1 || len(res.Rejected) != 0 {
+		t.Fatalf("second pass = %+v, %v", res, err)
+	}
+	if len(api.order) != 3 || api.order[0] != entries[0].EntryID || api.order[1] != entries[2].EntryID {
+		t.Fatalf("api order = %v", api.order)
+	}
+	cursor, err := LoadCursor(dir, api.URL())
+	if err != nil || cursor.Acked != 4 || len(cursor.Rejected) != 1 || cursor.Rejected[0].Hash != entries[1].Hash {
+		t.Fatalf("cursor = %+v, %v", cursor, err)
+	}
+}
+
+func TestSync_DetectsReplacedChain(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := testutil.TestSigner(t)
+	appendEntries(t, dir, signer, 2)
+	api := newFakeAPI()
+	s := &Syncer{EvidencePath: dir, Client: api, sleep: noSleep}
+	if _, err := s.SyncOnce(context.Background()); err != nil {
+		t.Fatalf("SyncOnce: %v", err)
+	}
+
+	replaced := t.TempDir()
+	appendEntries(t, replaced, signer, 3)
+	s.EvidencePath = replaced
+	if err := saveCursor(replaced, mustLoadCursor(t, dir, api.URL())); err != nil {
+		t.Fatalf("copy cursor: %v", err)
+	}
+	if _, err := s.SyncOnce(context.Background()); !errors.Is(err, ErrCursorMismatch) {
+		t.Fatalf("err = %v, want %v", err, ErrCursorMismatch)
+	}
+
+	if err := ResetCursor(replaced, api.URL()); err != nil {
+		t.Fatalf("ResetCursor: %v", err)
+	}
+	res, err := s.SyncOnce(context.Background())
+	if err != nil || res.Sent != 3 {
+		t.Fatalf("after reset = %+v, %v", res, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	s := &Syncer{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 5 * time.Second},
+		{attempt: 50, want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := s.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRun_SyncsOnNotify(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := testutil.TestSigner(t)
+	api := newFakeAPI()
+	s := &Syncer{EvidencePath: dir, Client: api}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx, time.Hour)
+	}()
+	appendEntries(t, dir, signer, 1)
+	s.Notify()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		api.mu.Lock()
+		n := len(api.order)
+		api.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("entry not synced after Notify")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func mustLoadCursor(t *testing.T, dir, url string) Cursor {
+	t.Helper()
+	c, err := LoadCursor(dir, url)
+	if err != nil {
+		t.Fatalf("LoadCursor: %v", err)
+	}
+	return c
+}
+
+func equalDurations(a, b []time.Duration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
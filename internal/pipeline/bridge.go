@@ -3,6 +3,7 @@ package pipeline
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"samebits.com/evidra/internal/canon"
 	"samebits.com/evidra/internal/signal"
@@ -10,24 +11,16 @@ import (
 )
 
 // EvidenceToSignalEntries converts evidence entries to signal detector input.
-// Only prescribe and report entries produce signal entries; other types are skipped.
+// Only plan, prescribe, report, cancel, approval and verification entries,
+// and signal entries recording observed unprescribed mutations, produce
+// signal entries; other types are skipped.
 func EvidenceToSignalEntries(entries []evidence.EvidenceEntry) ([]signal.Entry, error) {
-	var result []signal.Entry
-	prescriptions := make(map[string]canon.CanonicalAction, len(entries))
-
-	for _, e := range entries {
-		if e.Type != evidence.EntryTypePrescribe {
-			continue
-		}
-		var p evidence.PrescriptionPayload
-		if err := json.Unmarshal(e.Payload, &p); err != nil {
-			return nil, fmt.Errorf("pipeline: unmarshal prescription %s: %w", e.EntryID, err)
-		}
-		if ca, err := extractCanonicalAction(p.CanonicalAction); err == nil {
-			prescriptions[e.EntryID] = ca
-		}
+	prescriptions, err := prescribedActions(entries)
+	if err != nil {
+		return nil, err
 	}
 
+	var result []signal.Entry
 	for _, e := range entries {
 		se := signal.Entry{
 			EventID:        e.EntryID,
@@ -37,53 +30,175 @@ func EvidenceToSignalEntries(entries []evidence.EvidenceEntry) ([]signal.Entry,
 			IntentDigest:   e.IntentDigest,
 		}
 
+		var err error
 		switch e.Type {
 		case evidence.EntryTypePrescribe:
-			se.IsPrescription = true
-			var p evidence.PrescriptionPayload
-			if err := json.Unmarshal(e.Payload, &p); err != nil {
-				return nil, fmt.Errorf("pipeline: unmarshal prescription %s: %w", e.EntryID, err)
-			}
-			// Signals only consume Evidra-native risk tags.
-			se.RiskTags = p.NativeRiskTags()
-			// Extract fields from canonical_action.
-			if ca, err := extractCanonicalAction(p.CanonicalAction); err == nil {
-				se.Tool = ca.Tool
-				se.Operation = ca.Operation
-				se.OperationClass = ca.OperationClass
-				se.ScopeClass = ca.ScopeClass
-				se.ResourceCount = ca.ResourceCount
-				se.ShapeHash = ca.ResourceShapeHash
-			}
-
+			err = fillPrescription(&se, e)
+		case evidence.EntryTypePlan:
+			err = fillPlan(&se, e)
 		case evidence.EntryTypeReport:
-			se.IsReport = true
-			var r evidence.ReportPayload
-			if err := json.Unmarshal(e.Payload, &r); err != nil {
-				return nil, fmt.Errorf("pipeline: unmarshal report %s: %w", e.EntryID, err)
+			err = fillReport(&se, e, prescriptions)
+		case evidence.EntryTypeCancel:
+			err = fillCancel(&se, e, prescriptions)
+		case evidence.EntryTypeApproval:
+			err = fillApproval(&se, e, prescriptions)
+		case evidence.EntryTypeVerification:
+			err = fillVerification(&se, e, prescriptions)
+		case evidence.EntryTypeSignal:
+			if !fillObservedMutation(&se, e) {
+				continue
 			}
-			se.PrescriptionID = r.PrescriptionID
-			se.ExitCode = r.ExitCode
-			if ca, ok := prescriptions[r.PrescriptionID]; ok {
-				se.Tool = ca.Tool
-				se.Operation = ca.Operation
-				se.OperationClass = ca.OperationClass
-				se.ScopeClass = ca.ScopeClass
-				se.ResourceCount = ca.ResourceCount
-				se.ShapeHash = ca.ResourceShapeHash
-			}
-
 		default:
-			// Skip finding, signal, receipt, canonicalization_failure, session_start, session_end, annotation entries
+			// Skip finding, receipt, canonicalization_failure, session_start, session_end, annotation entries
 			continue
 		}
-
+		if err != nil {
+			return nil, err
+		}
 		result = append(result, se)
 	}
 
 	return result, nil
 }
 
+// prescribedActions maps each prescription ID to its canonical action, so
+// later entries can be attributed to the prescribed tool and scope.
+func prescribedActions(entries []evidence.EvidenceEntry) (map[string]canon.CanonicalAction, error) {
+	prescriptions := make(map[string]canon.CanonicalAction, len(entries))
+	for _, e := range entries {
+		if e.Type != evidence.EntryTypePrescribe {
+			continue
+		}
+		var p evidence.PrescriptionPayload
+		if err := json.Unmarshal(e.Payload, &p); err != nil {
+			return nil, fmt.Errorf("pipeline: unmarshal prescription %s: %w", e.EntryID, err)
+		}
+		if ca, err := extractCanonicalAction(p.CanonicalAction); err == nil {
+			prescriptions[e.EntryID] = ca
+		}
+	}
+	return prescriptions, nil
+}
+
+func fillPrescription(se *signal.Entry, e evidence.EvidenceEntry) error {
+	var p evidence.PrescriptionPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return fmt.Errorf("pipeline: unmarshal prescription %s: %w", e.EntryID, err)
+	}
+	se.IsPrescription = true
+	// Signals only consume Evidra-native risk tags.
+	se.RiskTags = p.NativeRiskTags()
+	se.PlanID = p.PlanID
+	se.PlanStep = p.PlanStep
+	se.RevertsPrescriptionID = p.RevertsPrescriptionID
+	se.TTL = time.Duration(p.TTLMs) * time.Millisecond
+	se.ApprovalRequired = p.ApprovalRequired
+	if ca, err := extractCanonicalAction(p.CanonicalAction); err == nil {
+		setResources(se, ca)
+	}
+	return nil
+}
+
+func fillPlan(se *signal.Entry, e evidence.EvidenceEntry) error {
+	var p evidence.PlanPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return fmt.Errorf("pipeline: unmarshal plan %s: %w", e.EntryID, err)
+	}
+	se.IsPlan = true
+	se.PlannedSteps = make([]signal.PlannedStep, len(p.Steps))
+	for i, step := range p.Steps {
+		se.PlannedSteps[i] = signal.PlannedStep{Tool: step.Tool, Operation: step.Operation}
+	}
+	return nil
+}
+
+func fillReport(se *signal.Entry, e evidence.EvidenceEntry, prescriptions map[string]canon.CanonicalAction) error {
+	var r evidence.ReportPayload
+	if err := json.Unmarshal(e.Payload, &r); err != nil {
+		return fmt.Errorf("pipeline: unmarshal report %s: %w", e.EntryID, err)
+	}
+	se.IsReport = true
+	se.PrescriptionID = r.PrescriptionID
+	se.ExitCode = r.ExitCode
+	if ca, ok := prescriptions[r.PrescriptionID]; ok {
+		setResources(se, ca)
+	}
+	return nil
+}
+
+func fillCancel(se *signal.Entry, e evidence.EvidenceEntry, prescriptions map[string]canon.CanonicalAction) error {
+	var c evidence.CancelPayload
+	if err := json.Unmarshal(e.Payload, &c); err != nil {
+		return fmt.Errorf("pipeline: unmarshal cancel %s: %w", e.EntryID, err)
+	}
+	se.IsCancel = true
+	se.PrescriptionID = c.PrescriptionID
+	if ca, ok := prescriptions[c.PrescriptionID]; ok {
+		setAction(se, ca)
+	}
+	return nil
+}
+
+func fillApproval(se *signal.Entry, e evidence.EvidenceEntry, prescriptions map[string]canon.CanonicalAction) error {
+	var a evidence.ApprovalPayload
+	if err := json.Unmarshal(e.Payload, &a); err != nil {
+		return fmt.Errorf("pipeline: unmarshal approval %s: %w", e.EntryID, err)
+	}
+	se.IsApproval = true
+	se.PrescriptionID = a.PrescriptionID
+	se.Approved = a.Decision == evidence.ApprovalApproved
+	if ca, ok := prescriptions[a.PrescriptionID]; ok {
+		setAction(se, ca)
+	}
+	return nil
+}
+
+func fillVerification(se *signal.Entry, e evidence.EvidenceEntry, prescriptions map[string]canon.CanonicalAction) error {
+	var v evidence.VerificationPayload
+	if err := json.Unmarshal(e.Payload, &v); err != nil {
+		return fmt.Errorf("pipeline: unmarshal verification %s: %w", e.EntryID, err)
+	}
+	se.IsVerification = true
+	se.PrescriptionID = v.PrescriptionID
+	se.StateDrift = v.Status == evidence.VerificationMismatched
+	if ca, ok := prescriptions[v.PrescriptionID]; ok {
+		// Tool and scope filters keep the verification with its operation.
+		setAction(se, ca)
+	}
+	if se.StateDrift {
+		se.Details = fmt.Sprintf("%d of %d resources differ from prescription %s", v.Mismatched, v.Matched+v.Mismatched, v.PrescriptionID)
+	}
+	return nil
+}
+
+// fillObservedMutation reports whether e records an observed unprescribed
+// mutation. Other signal entries restate what the detectors derive from the
+// chain; observed mutations exist only as these entries.
+func fillObservedMutation(se *signal.Entry, e evidence.EvidenceEntry) bool {
+	var sp evidence.SignalPayload
+	if err := json.Unmarshal(e.Payload, &sp); err != nil || sp.SignalName != "protocol_violation" || sp.SubSignal != "unprescribed_observed" {
+		return false
+	}
+	se.ObservedMutation = true
+	se.Details = sp.Details
+	return true
+}
+
+// setAction copies the prescribed tool, operation, and scope onto se.
+func setAction(se *signal.Entry, ca canon.CanonicalAction) {
+	se.Tool = ca.Tool
+	se.Operation = ca.Operation
+	se.OperationClass = ca.OperationClass
+	se.ScopeClass = ca.ScopeClass
+}
+
+// setResources is setAction plus the resource count and shape.
+func setResources(se *signal.Entry, ca canon.CanonicalAction) {
+	setAction(se, ca)
+	se.ResourceCount = ca.ResourceCount
+	se.ShapeHash = ca.ResourceShapeHash
+}
+
 func extractCanonicalAction(raw json.RawMessage) (canon.CanonicalAction, error) {
 	var ca canon.CanonicalAction
 	if err := json.Unmarshal(raw, &ca); err != nil {
@@ -223,6 +223,151 @@ func TestEvidenceToSignalEntries_SkipsNonPrescribeReport(t *testing.T) {
 	}
 }
 
+func TestEvidenceToSignalEntries_ObservedMutation(t *testing.T) {
+	t.Parallel()
+
+	entries := []evidence.EvidenceEntry{
+		{
+			EntryID: "01OBSERVED",
+			Type:    evidence.EntryTypeSignal,
+			Actor:   evidence.Actor{ID: "system:serviceaccount:agents:bot"},
+			Payload: json.RawMessage(`{"signal_name":"protocol_violation","sub_signal":"unprescribed_observed","entry_refs":[],"details":"delete deployment prod/web"}`),
+		},
+		{
+			EntryID: "02UNKNOWN",
+			Type:    evidence.EntryTypeSignal,
+			Payload: json.RawMessage(`{"signal_name":"protocol_violation","sub_signal":"unprescribed_action","entry_refs":["x"]}`),
+		},
+	}
+
+	result, err := EvidenceToSignalEntries(entries)
+	if err != nil {
+		t.Fatalf("EvidenceToSignalEntries: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 signal entry, got %d", len(result))
+	}
+	se := result[0]
+	if !se.ObservedMutation || se.EventID != "01OBSERVED" || se.ActorID != "system:serviceaccount:agents:bot" || se.Details != "delete deployment prod/web" {
+		t.Errorf("entry = %+v", se)
+	}
+}
+
+func TestEvidenceToSignalEntries_Verification(t *testing.T) {
+	t.Parallel()
+
+	payload, _ := json.Marshal(evidence.VerificationPayload{
+		PrescriptionID: "01PRESC",
+		Method:         "kubectl_get",
+		Status:         evidence.VerificationMismatched,
+		Matched:        1,
+		Mismatched:     1,
+	})
+	result, err := EvidenceToSignalEntries([]evidence.EvidenceEntry{
+		{EntryID: "01VERIFY", Type: evidence.EntryTypeVerification, Payload: payload},
+	})
+	if err != nil {
+		t.Fatalf("EvidenceToSignalEntries: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 signal entry, got %d", len(result))
+	}
+	se := result[0]
+	if !se.IsVerification || !se.StateDrift || se.PrescriptionID != "01PRESC" || se.Details != "1 of 2 resources differ from prescription 01PRESC" {
+		t.Errorf("entry = %+v", se)
+	}
+}
+
+func TestEvidenceToSignalEntries_Plan(t *testing.T) {
+	t.Parallel()
+
+	planPayload, _ := json.Marshal(evidence.PlanPayload{
+		PlanID: "01PLAN",
+		Steps: []evidence.PlanStep{
+			{Step: 1, Tool: "kubectl", Operation: "apply"},
+			{Step: 2, Tool: "helm", Operation: "upgrade"},
+		},
+	})
+	prescPayload, _ := json.Marshal(evidence.PrescriptionPayload{
+		PrescriptionID:        "01PRESC",
+		CanonicalAction:       json.RawMessage(`{"tool":"helm","operation":"upgrade"}`),
+		PlanID:                "01PLAN",
+		PlanStep:              2,
+		RevertsPrescriptionID: "01EARLIER",
+	})
+	result, err := EvidenceToSignalEntries([]evidence.EvidenceEntry{
+		{EntryID: "01PLAN", Type: evidence.EntryTypePlan, Payload: planPayload},
+		{EntryID: "01PRESC", Type: evidence.EntryTypePrescribe, Payload: prescPayload},
+	})
+	if err != nil {
+		t.Fatalf("EvidenceToSignalEntries: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 signal entries, got %d", len(result))
+	}
+	want := []signal.PlannedStep{{Tool: "kubectl", Operation: "apply"}, {Tool: "helm", Operation: "upgrade"}}
+	if plan := result[0]; !plan.IsPlan || plan.IsPrescription || len(plan.PlannedSteps) != 2 || plan.PlannedSteps[0] != want[0] || plan.PlannedSteps[1] != want[1] {
+		t.Errorf("plan entry = %+v", plan)
+	}
+	if rx := result[1]; rx.PlanID != "01PLAN" || rx.PlanStep != 2 || rx.RevertsPrescriptionID != "01EARLIER" {
+		t.Errorf("prescription plan_id=%q plan_step=%d reverts=%q", rx.PlanID, rx.PlanStep, rx.RevertsPrescriptionID)
+	}
+}
+
+func TestEvidenceToSignalEntries_Cancel(t *testing.T) {
+	t.Parallel()
+
+	prescPayload, _ := json.Marshal(evidence.PrescriptionPayload{
+		PrescriptionID:  "01PRESC",
+		CanonicalAction: json.RawMessage(`{"tool":"kubectl","operation":"delete","scope_class":"production"}`),
+		TTLMs:           300000,
+	})
+	cancelPayload, _ := json.Marshal(evidence.CancelPayload{PrescriptionID: "01PRESC", Reason: "wrong namespace"})
+	result, err := EvidenceToSignalEntries([]evidence.EvidenceEntry{
+		{EntryID: "01PRESC", Type: evidence.EntryTypePrescribe, Payload: prescPayload},
+		{EntryID: "01CANCEL", Type: evidence.EntryTypeCancel, Payload: cancelPayload},
+	})
+	if err != nil {
+		t.Fatalf("EvidenceToSignalEntries: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 signal entries, got %d", len(result))
+	}
+	if rx := result[0]; rx.TTL != 5*time.Minute {
+		t.Errorf("prescription TTL = %v, want 5m", rx.TTL)
+	}
+	if c := result[1]; !c.IsCancel || c.IsReport || c.PrescriptionID != "01PRESC" || c.Tool != "kubectl" || c.ScopeClass != "production" {
+		t.Errorf("cancel entry = %+v", c)
+	}
+}
+
+func TestEvidenceToSignalEntries_Approval(t *testing.T) {
+	t.Parallel()
+
+	prescPayload, _ := json.Marshal(evidence.PrescriptionPayload{
+		PrescriptionID:   "01PRESC",
+		CanonicalAction:  json.RawMessage(`{"tool":"kubectl","operation":"delete","scope_class":"production"}`),
+		ApprovalRequired: true,
+	})
+	approvalPayload, _ := json.Marshal(evidence.ApprovalPayload{PrescriptionID: "01PRESC", Decision: evidence.ApprovalApproved})
+	result, err := EvidenceToSignalEntries([]evidence.EvidenceEntry{
+		{EntryID: "01PRESC", Type: evidence.EntryTypePrescribe, Payload: prescPayload},
+		{EntryID: "01APPROVAL", Type: evidence.EntryTypeApproval, Payload: approvalPayload},
+	})
+	if err != nil {
+		t.Fatalf("EvidenceToSignalEntries: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 signal entries, got %d", len(result))
+	}
+	if !result[0].ApprovalRequired {
+		t.Error("prescription lost approval_required")
+	}
+	if a := result[1]; !a.IsApproval || !a.Approved || a.PrescriptionID != "01PRESC" || a.Tool != "kubectl" {
+		t.Errorf("approval entry = %+v", a)
+	}
+}
+
 func TestEvidenceToSignalEntries_Empty(t *testing.T) {
 	t.Parallel()
 
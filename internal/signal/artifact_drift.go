@@ -1,6 +1,9 @@
 package signal
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 func init() {
 	registerSignal(signalDefinition{
@@ -13,8 +16,26 @@ func init() {
 }
 
 // DetectArtifactDrift finds reports where the artifact digest does not match
-// the prescription's artifact digest.
+// the prescription's artifact digest, and prescriptions whose live state did
+// not match when last verified.
 func DetectArtifactDrift(entries []Entry) SignalResult {
+	events := DetectArtifactDriftEvents(entries)
+	eventIDs := make([]string, len(events))
+	for i, e := range events {
+		eventIDs[i] = e.EntryRef
+	}
+	return SignalResult{
+		Name:     "artifact_drift",
+		Count:    len(eventIDs),
+		EventIDs: eventIDs,
+	}
+}
+
+// DetectArtifactDriftEvents returns detailed signal events for artifact
+// drift. digest_mismatch compares self-reported digests; state_drift comes
+// from verification entries, and only the latest verification of a
+// prescription counts, so a re-verified fix clears it.
+func DetectArtifactDriftEvents(entries []Entry) []SignalEvent {
 	// Build map: prescription event_id → artifact_digest
 	prescriptionDigest := make(map[string]string)
 	for _, e := range entries {
@@ -23,8 +44,13 @@ func DetectArtifactDrift(entries []Entry) SignalResult {
 		}
 	}
 
-	var eventIDs []string
-	for _, e := range entries {
+	var events []SignalEvent
+	latestVerification := make(map[string]int)
+	for i, e := range entries {
+		if e.IsVerification && e.PrescriptionID != "" {
+			latestVerification[e.PrescriptionID] = i
+			continue
+		}
 		if !e.IsReport || e.PrescriptionID == "" || e.ArtifactDigest == "" {
 			continue
 		}
@@ -33,13 +59,27 @@ func DetectArtifactDrift(entries []Entry) SignalResult {
 			continue
 		}
 		if presDigest != e.ArtifactDigest {
-			eventIDs = append(eventIDs, e.EventID)
+			events = append(events, SignalEvent{
+				Signal:    "artifact_drift",
+				SubSignal: "digest_mismatch",
+				Timestamp: e.Timestamp,
+				EntryRef:  e.EventID,
+				Details:   fmt.Sprintf("prescribed %s, reported %s", presDigest, e.ArtifactDigest),
+			})
 		}
 	}
 
-	return SignalResult{
-		Name:     "artifact_drift",
-		Count:    len(eventIDs),
-		EventIDs: eventIDs,
+	for i, e := range entries {
+		if !e.IsVerification || !e.StateDrift || latestVerification[e.PrescriptionID] != i {
+			continue
+		}
+		events = append(events, SignalEvent{
+			Signal:    "artifact_drift",
+			SubSignal: "state_drift",
+			Timestamp: e.Timestamp,
+			EntryRef:  e.EventID,
+			Details:   e.Details,
+		})
 	}
+	return events
 }
@@ -0,0 +1,101 @@
+package signal
+
+import "fmt"
+
+// DetectPlanDeviationEvents compares prescriptions made under a plan with
+// the plan's steps. Sub-signals:
+//   - plan_extra_step: a prescription the plan did not list, either step 0
+//     or a tool/operation that differs from the planned step
+//   - plan_step_reordered: a step first prescribed after a later step
+//   - plan_step_skipped: a step never prescribed although a later one was;
+//     trailing steps are still pending, not skipped
+//
+// Prescriptions naming a plan outside entries are ignored.
+func DetectPlanDeviationEvents(entries []Entry) []SignalEvent {
+	plans := make(map[string]Entry)
+	for _, e := range entries {
+		if e.IsPlan {
+			plans[e.EventID] = e
+		}
+	}
+	if len(plans) == 0 {
+		return nil
+	}
+
+	prescribed := make(map[string]map[int]bool) // plan → steps prescribed
+	furthest := make(map[string]int)            // plan → highest step prescribed
+
+	var events []SignalEvent
+	for _, e := range entries {
+		if !e.IsPrescription || e.PlanID == "" {
+			continue
+		}
+		plan, ok := plans[e.PlanID]
+		if !ok {
+			continue
+		}
+
+		if e.PlanStep < 1 || e.PlanStep > len(plan.PlannedSteps) {
+			events = append(events, SignalEvent{
+				Signal:    "protocol_violation",
+				SubSignal: "plan_extra_step",
+				Timestamp: e.Timestamp,
+				EntryRef:  e.EventID,
+				Details:   fmt.Sprintf("prescription %s (%s %s) is not a step of plan %s", e.EventID, e.Tool, e.Operation, e.PlanID),
+			})
+			continue
+		}
+		planned := plan.PlannedSteps[e.PlanStep-1]
+		if e.Tool != "" && (e.Tool != planned.Tool || e.Operation != planned.Operation) {
+			events = append(events, SignalEvent{
+				Signal:    "protocol_violation",
+				SubSignal: "plan_extra_step",
+				Timestamp: e.Timestamp,
+				EntryRef:  e.EventID,
+				Details:   fmt.Sprintf("prescription %s is %s %s but plan %s step %d is %s %s", e.EventID, e.Tool, e.Operation, e.PlanID, e.PlanStep, planned.Tool, planned.Operation),
+			})
+			continue
+		}
+
+		steps := prescribed[e.PlanID]
+		if steps == nil {
+			steps = make(map[int]bool)
+			prescribed[e.PlanID] = steps
+		}
+		if steps[e.PlanStep] {
+			continue // a retry of a step already under way
+		}
+		steps[e.PlanStep] = true
+		if furthest[e.PlanID] > e.PlanStep {
+			events = append(events, SignalEvent{
+				Signal:    "protocol_violation",
+				SubSignal: "plan_step_reordered",
+				Timestamp: e.Timestamp,
+				EntryRef:  e.EventID,
+				Details:   fmt.Sprintf("plan %s step %d prescribed after step %d", e.PlanID, e.PlanStep, furthest[e.PlanID]),
+			})
+			continue
+		}
+		furthest[e.PlanID] = e.PlanStep
+	}
+
+	for _, e := range entries {
+		if !e.IsPlan {
+			continue
+		}
+		for step := 1; step < furthest[e.EventID]; step++ {
+			if prescribed[e.EventID][step] {
+				continue
+			}
+			planned := e.PlannedSteps[step-1]
+			events = append(events, SignalEvent{
+				Signal:    "protocol_violation",
+				SubSignal: "plan_step_skipped",
+				Timestamp: e.Timestamp,
+				EntryRef:  e.EventID,
+				Details:   fmt.Sprintf("plan %s step %d (%s %s) skipped; step %d was prescribed", e.EventID, step, planned.Tool, planned.Operation, furthest[e.EventID]),
+			})
+		}
+	}
+	return events
+}
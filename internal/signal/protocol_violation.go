@@ -17,7 +17,11 @@ func init() {
 
 // DetectProtocolViolations finds prescriptions without matching reports
 // (unreported operations) and reports without matching prescriptions
-// (unprescribed actions). Also detects duplicate reports and cross-actor reports.
+// (unprescribed actions). Also detects duplicate reports, cross-actor reports,
+// reports past the prescription's TTL, executions that lacked a required
+// approval, observed mutations no prescription covers, and deviations from
+// plans. A cancellation closes its prescription
+// like a report.
 // TTL controls the window for unreported prescription detection.
 func DetectProtocolViolations(entries []Entry, ttl time.Duration) SignalResult {
 	events := DetectProtocolViolationEvents(entries, ttl, time.Now())
@@ -36,94 +40,147 @@ func DetectProtocolViolations(entries []Entry, ttl time.Duration) SignalResult {
 // TTL controls unreported prescription detection — only prescriptions older than
 // TTL without a matching report are flagged.
 func DetectProtocolViolationEvents(entries []Entry, ttl time.Duration, now ...time.Time) []SignalEvent {
-	prescriptions := make(map[string]Entry)
-	firstReport := make(map[string]Entry) // prescription_id → first report
-	reportedIDs := make(map[string]bool)
-
+	state := protocolState{
+		prescriptions: make(map[string]Entry),
+		firstReport:   make(map[string]Entry),
+		reportedIDs:   make(map[string]bool),
+		approved:      make(map[string]bool),
+		decided:       make(map[string]bool),
+	}
 	for _, e := range entries {
 		if e.IsPrescription {
-			prescriptions[e.EventID] = e
+			state.prescriptions[e.EventID] = e
 		}
 	}
 
 	var events []SignalEvent
-
 	for _, e := range entries {
-		if !e.IsReport || e.PrescriptionID == "" {
-			continue
-		}
+		events = append(events, state.observe(e)...)
+	}
 
-		// Unprescribed report
-		if _, found := prescriptions[e.PrescriptionID]; !found {
-			events = append(events, SignalEvent{
-				Signal:    "protocol_violation",
-				SubSignal: "unprescribed_action",
-				Timestamp: e.Timestamp,
-				EntryRef:  e.EventID,
-				Details:   fmt.Sprintf("report %s references unknown prescription %s", e.EventID, e.PrescriptionID),
-			})
-			continue
-		}
+	events = append(events, DetectPlanDeviationEvents(entries)...)
 
-		// Duplicate report
-		if reportedIDs[e.PrescriptionID] {
-			events = append(events, SignalEvent{
-				Signal:    "protocol_violation",
-				SubSignal: "duplicate_report",
-				Timestamp: e.Timestamp,
-				EntryRef:  e.EventID,
-				Details:   fmt.Sprintf("duplicate report for prescription %s (first: %s)", e.PrescriptionID, firstReport[e.PrescriptionID].EventID),
-			})
-			continue
-		}
+	// Unreported prescriptions — TTL-aware with sub-signal classification
+	resolvedNow := time.Now()
+	if len(now) > 0 {
+		resolvedNow = now[0]
+	}
+	events = append(events, DetectUnreported(entries, ttl, resolvedNow)...)
 
-		// Cross-actor report
-		rx := prescriptions[e.PrescriptionID]
-		if rx.ActorID != "" && e.ActorID != "" && rx.ActorID != e.ActorID {
-			events = append(events, SignalEvent{
-				Signal:    "protocol_violation",
-				SubSignal: "cross_actor_report",
-				Timestamp: e.Timestamp,
-				EntryRef:  e.EventID,
-				Details:   fmt.Sprintf("report actor %s != prescription actor %s", e.ActorID, rx.ActorID),
-			})
-			continue // Don't mark as reported — only a valid same-actor report should consume the slot.
+	return events
+}
+
+// protocolState follows the chain in order, tracking which prescriptions
+// have been reported, cancelled, or decided so far.
+type protocolState struct {
+	prescriptions map[string]Entry
+	firstReport   map[string]Entry // prescription_id → first report
+	reportedIDs   map[string]bool
+	approved      map[string]bool // prescription_id → first decision allowed it
+	decided       map[string]bool
+}
+
+func (st *protocolState) observe(e Entry) []SignalEvent {
+	switch {
+	case e.ObservedMutation:
+		return []SignalEvent{protocolViolation("unprescribed_observed", e, e.Details)}
+	case e.IsCancel && e.PrescriptionID != "":
+		if !st.reportedIDs[e.PrescriptionID] {
+			st.reportedIDs[e.PrescriptionID] = true
+			st.firstReport[e.PrescriptionID] = e
+		}
+		return nil
+	case e.IsApproval && e.PrescriptionID != "":
+		if !st.decided[e.PrescriptionID] {
+			st.decided[e.PrescriptionID] = true
+			st.approved[e.PrescriptionID] = e.Approved
 		}
+		return nil
+	case e.IsReport && e.PrescriptionID != "":
+		return st.observeReport(e)
+	}
+	return nil
+}
+
+func (st *protocolState) observeReport(e Entry) []SignalEvent {
+	// Unprescribed report
+	rx, found := st.prescriptions[e.PrescriptionID]
+	if !found {
+		return []SignalEvent{protocolViolation("unprescribed_action", e,
+			fmt.Sprintf("report %s references unknown prescription %s", e.EventID, e.PrescriptionID))}
+	}
 
-		// Missing artifact digest — prescription had a digest but report omits it.
-		// Artifact drift detection is disabled for this report pair.
-		if rx.ArtifactDigest != "" && e.ArtifactDigest == "" && e.ExitCode != nil {
-			events = append(events, SignalEvent{
-				Signal:    "protocol_violation",
-				SubSignal: "report_without_digest",
-				Timestamp: e.Timestamp,
-				EntryRef:  e.EventID,
-				Details:   fmt.Sprintf("report %s omits artifact_digest; drift detection unavailable for prescription %s", e.EventID, e.PrescriptionID),
-			})
+	// Duplicate report
+	if st.reportedIDs[e.PrescriptionID] {
+		first := st.firstReport[e.PrescriptionID]
+		details := fmt.Sprintf("duplicate report for prescription %s (first: %s)", e.PrescriptionID, first.EventID)
+		if first.IsCancel {
+			details = fmt.Sprintf("report for prescription %s after it was cancelled (%s)", e.PrescriptionID, first.EventID)
 		}
+		return []SignalEvent{protocolViolation("duplicate_report", e, details)}
+	}
 
-		reportedIDs[e.PrescriptionID] = true
-		firstReport[e.PrescriptionID] = e
+	// Cross-actor report
+	if rx.ActorID != "" && e.ActorID != "" && rx.ActorID != e.ActorID {
+		// Don't mark as reported — only a valid same-actor report should consume the slot.
+		return []SignalEvent{protocolViolation("cross_actor_report", e,
+			fmt.Sprintf("report actor %s != prescription actor %s", e.ActorID, rx.ActorID))}
 	}
 
-	// Unreported prescriptions — TTL-aware with sub-signal classification
-	resolvedNow := time.Now()
-	if len(now) > 0 {
-		resolvedNow = now[0]
+	events := reportViolations(rx, e, st.approved[e.PrescriptionID], st.decided[e.PrescriptionID])
+	st.reportedIDs[e.PrescriptionID] = true
+	st.firstReport[e.PrescriptionID] = e
+	return events
+}
+
+// reportViolations checks the first report of prescription rx. approved and
+// decided describe the first approval decision recorded before it.
+func reportViolations(rx, e Entry, approved, decided bool) []SignalEvent {
+	var events []SignalEvent
+
+	// Late report — arrived after the prescription's stored TTL.
+	if rx.TTL > 0 && e.Timestamp.Sub(rx.Timestamp) > rx.TTL {
+		events = append(events, protocolViolation("late_report", e,
+			fmt.Sprintf("report %s arrived %v after prescription %s (ttl %v)", e.EventID, e.Timestamp.Sub(rx.Timestamp).Round(time.Second), e.PrescriptionID, rx.TTL)))
 	}
-	events = append(events, DetectUnreported(entries, ttl, resolvedNow)...)
 
+	// Unapproved execution — the operation ran without an approved
+	// decision recorded before the report.
+	if rx.ApprovalRequired && e.ExitCode != nil && !approved {
+		details := fmt.Sprintf("prescription %s required approval but ran without one", e.PrescriptionID)
+		if decided {
+			details = fmt.Sprintf("prescription %s ran after its approval was rejected", e.PrescriptionID)
+		}
+		events = append(events, protocolViolation("unapproved_execution", e, details))
+	}
+
+	// Missing artifact digest — prescription had a digest but report omits it.
+	// Artifact drift detection is disabled for this report pair.
+	if rx.ArtifactDigest != "" && e.ArtifactDigest == "" && e.ExitCode != nil {
+		events = append(events, protocolViolation("report_without_digest", e,
+			fmt.Sprintf("report %s omits artifact_digest; drift detection unavailable for prescription %s", e.EventID, e.PrescriptionID)))
+	}
 	return events
 }
 
+func protocolViolation(subSignal string, e Entry, details string) SignalEvent {
+	return SignalEvent{
+		Signal:    "protocol_violation",
+		SubSignal: subSignal,
+		Timestamp: e.Timestamp,
+		EntryRef:  e.EventID,
+		Details:   details,
+	}
+}
+
 // DetectUnreported scans evidence chain for prescriptions without matching
-// reports within TTL. Called at scorecard computation time, not at
+// reports or cancellations within TTL. Called at scorecard computation time, not at
 // prescribe/report time. The now parameter makes detection deterministic
 // for testing.
 func DetectUnreported(entries []Entry, ttl time.Duration, now time.Time) []SignalEvent {
 	reportedIDs := make(map[string]bool)
 	for _, e := range entries {
-		if e.IsReport && e.PrescriptionID != "" {
+		if (e.IsReport || e.IsCancel) && e.PrescriptionID != "" {
 			reportedIDs[e.PrescriptionID] = true
 		}
 	}
@@ -1,6 +1,7 @@
 package signal
 
 import (
+	"fmt"
 	"sort"
 	"time"
 )
@@ -15,9 +16,30 @@ func init() {
 	})
 }
 
-// DetectRepairLoop finds sequences where a failed intent later succeeds
-// with a different artifact digest for the same actor+intent.
+// DetectRepairLoop finds failed operations that were later recovered,
+// either by fixing forward or by rolling back.
 func DetectRepairLoop(entries []Entry) SignalResult {
+	events := DetectRepairLoopEvents(entries)
+	eventIDs := make([]string, len(events))
+	for i, e := range events {
+		eventIDs[i] = e.EntryRef
+	}
+	return SignalResult{
+		Name:     "repair_loop",
+		Count:    len(eventIDs),
+		EventIDs: eventIDs,
+	}
+}
+
+// DetectRepairLoopEvents returns detailed signal events for recoveries.
+// Sub-signals:
+//   - fix_forward: a failed intent later succeeds with a different artifact
+//     digest for the same actor+intent
+//   - rollback: a prescription that reverts a failed prescription succeeds
+//
+// A successful rollback that shares the failed intent counts once, as a
+// rollback.
+func DetectRepairLoopEvents(entries []Entry) []SignalEvent {
 	type key struct{ actor, intent string }
 
 	reportExit := make(map[string]*int)
@@ -26,17 +48,37 @@ func DetectRepairLoop(entries []Entry) SignalResult {
 			reportExit[e.PrescriptionID] = e.ExitCode
 		}
 	}
+	failed := func(prescriptionID string) bool {
+		ec := reportExit[prescriptionID]
+		return ec != nil && *ec != 0
+	}
+	succeeded := func(prescriptionID string) bool {
+		ec := reportExit[prescriptionID]
+		return ec != nil && *ec == 0
+	}
 
+	var events []SignalEvent
 	groups := make(map[key][]Entry)
 	for _, e := range entries {
-		if !e.IsPrescription || e.IntentDigest == "" {
+		if !e.IsPrescription {
+			continue
+		}
+		if e.RevertsPrescriptionID != "" && succeeded(e.EventID) && failed(e.RevertsPrescriptionID) {
+			events = append(events, SignalEvent{
+				Signal:    "repair_loop",
+				SubSignal: "rollback",
+				Timestamp: e.Timestamp,
+				EntryRef:  e.EventID,
+				Details:   fmt.Sprintf("rolled back failed prescription %s", e.RevertsPrescriptionID),
+			})
+		}
+		if e.IntentDigest == "" {
 			continue
 		}
 		k := key{actor: e.ActorID, intent: e.IntentDigest}
 		groups[k] = append(groups[k], e)
 	}
 
-	var eventIDs []string
 	for _, group := range groups {
 		if len(group) < 2 {
 			continue
@@ -61,8 +103,14 @@ func DetectRepairLoop(entries []Entry) SignalResult {
 			}
 
 			// Success: repair requires prior failure and a changed artifact.
-			if sawFailure && failDigest != "" && p.ArtifactDigest != failDigest {
-				eventIDs = append(eventIDs, p.EventID)
+			if sawFailure && failDigest != "" && p.ArtifactDigest != failDigest && p.RevertsPrescriptionID == "" {
+				events = append(events, SignalEvent{
+					Signal:    "repair_loop",
+					SubSignal: "fix_forward",
+					Timestamp: p.Timestamp,
+					EntryRef:  p.EventID,
+					Details:   "repaired after failure with changed artifact",
+				})
 			}
 			// Chain is consumed on success, whether it counted as repair or not.
 			sawFailure = false
@@ -70,9 +118,8 @@ func DetectRepairLoop(entries []Entry) SignalResult {
 		}
 	}
 
-	return SignalResult{
-		Name:     "repair_loop",
-		Count:    len(eventIDs),
-		EventIDs: eventIDs,
-	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	return events
 }
@@ -144,3 +144,40 @@ func TestRepairLoop_EmptyEntries(t *testing.T) {
 		t.Fatalf("expected 0, got %d", result.Count)
 	}
 }
+
+func TestRepairLoop_RollbackIsNotFixForward(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	entries := []Entry{
+		// Failed upgrade, rolled back with a different intent (helm rollback).
+		{EventID: "p1", Timestamp: now, ActorID: "agent-1", IsPrescription: true, IntentDigest: "intent-upgrade", ArtifactDigest: "chart-v2"},
+		{EventID: "r1", Timestamp: now.Add(time.Second), IsReport: true, PrescriptionID: "p1", ExitCode: intPtr(1)},
+		{EventID: "p2", Timestamp: now.Add(2 * time.Second), ActorID: "agent-1", IsPrescription: true, IntentDigest: "intent-rollback", RevertsPrescriptionID: "p1"},
+		{EventID: "r2", Timestamp: now.Add(3 * time.Second), IsReport: true, PrescriptionID: "p2", ExitCode: intPtr(0)},
+		// Failed apply, rolled back by re-applying the old manifest: same
+		// intent, changed artifact, but a rollback rather than a fix.
+		{EventID: "p3", Timestamp: now.Add(4 * time.Second), ActorID: "agent-1", IsPrescription: true, IntentDigest: "intent-apply", ArtifactDigest: "manifest-v2"},
+		{EventID: "r3", Timestamp: now.Add(5 * time.Second), IsReport: true, PrescriptionID: "p3", ExitCode: intPtr(1)},
+		{EventID: "p4", Timestamp: now.Add(6 * time.Second), ActorID: "agent-1", IsPrescription: true, IntentDigest: "intent-apply", ArtifactDigest: "manifest-v1", RevertsPrescriptionID: "p3"},
+		{EventID: "r4", Timestamp: now.Add(7 * time.Second), IsReport: true, PrescriptionID: "p4", ExitCode: intPtr(0)},
+		// Rolling back an operation that succeeded is not a repair.
+		{EventID: "p5", Timestamp: now.Add(8 * time.Second), ActorID: "agent-1", IsPrescription: true, IntentDigest: "intent-other"},
+		{EventID: "r5", Timestamp: now.Add(9 * time.Second), IsReport: true, PrescriptionID: "p5", ExitCode: intPtr(0)},
+		{EventID: "p6", Timestamp: now.Add(10 * time.Second), ActorID: "agent-1", IsPrescription: true, IntentDigest: "intent-undo", RevertsPrescriptionID: "p5"},
+		{EventID: "r6", Timestamp: now.Add(11 * time.Second), IsReport: true, PrescriptionID: "p6", ExitCode: intPtr(0)},
+	}
+
+	events := DetectRepairLoopEvents(entries)
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want two rollbacks", events)
+	}
+	for i, want := range []string{"p2", "p4"} {
+		if events[i].EntryRef != want || events[i].SubSignal != "rollback" {
+			t.Errorf("events[%d] = %+v, want rollback %s", i, events[i], want)
+		}
+	}
+	if counts := SubSignalCounts("repair_loop", entries, DefaultTTL); counts["rollback"] != 2 || counts["fix_forward"] != 0 {
+		t.Fatalf("sub-signal counts = %v", counts)
+	}
+}
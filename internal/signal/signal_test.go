@@ -57,6 +57,71 @@ func TestDetectProtocolViolationEvents_DuplicateReport(t *testing.T) {
 	assertSubSignal(t, events, "duplicate_report")
 }
 
+func TestDetectProtocolViolationEvents_CancelClosesPrescription(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	entries := []Entry{
+		{EventID: "P1", IsPrescription: true, Timestamp: now.Add(-30 * time.Minute)},
+		{EventID: "C1", IsCancel: true, PrescriptionID: "P1", Timestamp: now.Add(-29 * time.Minute)},
+	}
+	if events := DetectProtocolViolationEvents(entries, DefaultTTL, now); len(events) != 0 {
+		t.Fatalf("cancelled prescription flagged: %+v", events)
+	}
+
+	entries = append(entries, Entry{EventID: "R1", IsReport: true, PrescriptionID: "P1", Timestamp: now.Add(-28 * time.Minute)})
+	events := DetectProtocolViolationEvents(entries, DefaultTTL, now)
+	if len(events) != 1 || events[0].SubSignal != "duplicate_report" || events[0].EntryRef != "R1" {
+		t.Fatalf("report after cancel events = %+v, want one duplicate_report on R1", events)
+	}
+}
+
+func TestDetectProtocolViolationEvents_LateReport(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	entries := []Entry{
+		{EventID: "P1", IsPrescription: true, TTL: 5 * time.Minute, Timestamp: now.Add(-20 * time.Minute)},
+		{EventID: "R1", IsReport: true, PrescriptionID: "P1", Timestamp: now.Add(-10 * time.Minute)},
+		{EventID: "P2", IsPrescription: true, TTL: 5 * time.Minute, Timestamp: now.Add(-20 * time.Minute)},
+		{EventID: "R2", IsReport: true, PrescriptionID: "P2", Timestamp: now.Add(-19 * time.Minute)},
+		{EventID: "P3", IsPrescription: true, Timestamp: now.Add(-20 * time.Minute)},
+		{EventID: "R3", IsReport: true, PrescriptionID: "P3", Timestamp: now.Add(-1 * time.Minute)},
+	}
+	events := DetectProtocolViolationEvents(entries, DefaultTTL, now)
+	if len(events) != 1 || events[0].SubSignal != "late_report" || events[0].EntryRef != "R1" {
+		t.Fatalf("events = %+v, want one late_report on R1", events)
+	}
+}
+
+func TestDetectProtocolViolationEvents_UnapprovedExecution(t *testing.T) {
+	t.Parallel()
+
+	exit0 := 0
+	entries := []Entry{
+		{EventID: "P1", IsPrescription: true, ApprovalRequired: true},
+		{EventID: "A1", IsApproval: true, Approved: true, PrescriptionID: "P1"},
+		{EventID: "R1", IsReport: true, PrescriptionID: "P1", ExitCode: &exit0},
+		{EventID: "P2", IsPrescription: true, ApprovalRequired: true},
+		{EventID: "R2", IsReport: true, PrescriptionID: "P2", ExitCode: &exit0},
+		{EventID: "P3", IsPrescription: true, ApprovalRequired: true},
+		{EventID: "A3", IsApproval: true, PrescriptionID: "P3"},
+		{EventID: "R3", IsReport: true, PrescriptionID: "P3", ExitCode: &exit0},
+		{EventID: "P4", IsPrescription: true, ApprovalRequired: true},
+		{EventID: "R4", IsReport: true, PrescriptionID: "P4"},
+	}
+	events := DetectProtocolViolationEvents(entries, DefaultTTL)
+	var refs []string
+	for _, e := range events {
+		if e.SubSignal == "unapproved_execution" {
+			refs = append(refs, e.EntryRef)
+		}
+	}
+	if len(refs) != 2 || refs[0] != "R2" || refs[1] != "R3" {
+		t.Fatalf("unapproved_execution refs = %v, want [R2 R3]", refs)
+	}
+}
+
 func TestDetectProtocolViolationEvents_CrossActorReport(t *testing.T) {
 	t.Parallel()
 
@@ -96,6 +161,19 @@ func TestDetectProtocolViolationEvents_UnprescribedAction(t *testing.T) {
 	assertSubSignal(t, events, "unprescribed_action")
 }
 
+func TestDetectProtocolViolationEvents_UnprescribedObserved(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{EventID: "S1", ObservedMutation: true, Details: "delete deployment prod/web by bot"},
+	}
+	events := DetectProtocolViolationEvents(entries, DefaultTTL)
+	assertSubSignal(t, events, "unprescribed_observed")
+	if events[0].EntryRef != "S1" || events[0].Details != "delete deployment prod/web by bot" {
+		t.Fatalf("event = %+v", events[0])
+	}
+}
+
 func TestDetectUnreported_TTLExpired(t *testing.T) {
 	t.Parallel()
 
@@ -187,6 +265,26 @@ func TestDetectArtifactDrift_NoDrift(t *testing.T) {
 	}
 }
 
+func TestDetectArtifactDriftEvents_StateDriftUsesLatestVerification(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{EventID: "P1", IsPrescription: true, ArtifactDigest: "abc123"},
+		{EventID: "R1", IsReport: true, PrescriptionID: "P1", ArtifactDigest: "abc123"},
+		{EventID: "V1", IsVerification: true, StateDrift: true, PrescriptionID: "P1"},
+		{EventID: "P2", IsPrescription: true},
+		{EventID: "V2", IsVerification: true, StateDrift: true, PrescriptionID: "P2"},
+		{EventID: "V3", IsVerification: true, PrescriptionID: "P2"},
+	}
+	events := DetectArtifactDriftEvents(entries)
+	if len(events) != 1 || events[0].SubSignal != "state_drift" || events[0].EntryRef != "V1" {
+		t.Fatalf("events = %+v", events)
+	}
+	if counts := SubSignalCounts("artifact_drift", entries, DefaultTTL); counts["state_drift"] != 1 {
+		t.Fatalf("sub-signal counts = %v", counts)
+	}
+}
+
 func intPtr(i int) *int { return &i }
 
 func TestDetectRetryLoops_LoopDetected(t *testing.T) {
@@ -556,6 +654,54 @@ func TestDetectProtocolViolationEvents_PrescriptionWithoutDigest_NoViolation(t *
 	}
 }
 
+func TestDetectPlanDeviationEvents(t *testing.T) {
+	t.Parallel()
+
+	planned := []PlannedStep{
+		{Tool: "kubectl", Operation: "apply"},
+		{Tool: "kubectl", Operation: "apply"},
+		{Tool: "helm", Operation: "upgrade"},
+		{Tool: "kubectl", Operation: "delete"},
+	}
+	entries := []Entry{
+		{EventID: "PLAN1", IsPlan: true, PlannedSteps: planned},
+		{EventID: "P1", IsPrescription: true, PlanID: "PLAN1", PlanStep: 1, Tool: "kubectl", Operation: "apply"},
+		{EventID: "P1b", IsPrescription: true, PlanID: "PLAN1", PlanStep: 1, Tool: "kubectl", Operation: "apply"},
+		{EventID: "P3", IsPrescription: true, PlanID: "PLAN1", PlanStep: 3, Tool: "helm", Operation: "upgrade"},
+		{EventID: "PX", IsPrescription: true, PlanID: "PLAN1", Tool: "terraform", Operation: "apply"},
+		{EventID: "PY", IsPrescription: true, PlanID: "PLAN1", PlanStep: 4, Tool: "kubectl", Operation: "apply"},
+		{EventID: "PZ", IsPrescription: true, PlanID: "UNKNOWN", PlanStep: 1, Tool: "kubectl", Operation: "apply"},
+		{EventID: "PLAN2", IsPlan: true, PlannedSteps: planned[:2]},
+		{EventID: "Q2", IsPrescription: true, PlanID: "PLAN2", PlanStep: 2, Tool: "kubectl", Operation: "apply"},
+		{EventID: "Q1", IsPrescription: true, PlanID: "PLAN2", PlanStep: 1, Tool: "kubectl", Operation: "apply"},
+	}
+
+	got := make(map[string]string)
+	for _, ev := range DetectPlanDeviationEvents(entries) {
+		if ev.Signal != "protocol_violation" {
+			t.Fatalf("signal = %q", ev.Signal)
+		}
+		got[ev.EntryRef] += ev.SubSignal
+	}
+	want := map[string]string{
+		"PX":    "plan_extra_step",
+		"PY":    "plan_extra_step",
+		"Q1":    "plan_step_reordered",
+		"PLAN1": "plan_step_skipped",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for ref, sub := range want {
+		if got[ref] != sub {
+			t.Errorf("%s: sub-signal = %q, want %q", ref, got[ref], sub)
+		}
+	}
+	if counts := SubSignalCounts("protocol_violation", entries, DefaultTTL); counts["plan_step_skipped"] != 1 || counts["plan_extra_step"] != 2 {
+		t.Fatalf("sub-signal counts = %v", counts)
+	}
+}
+
 func TestDetectVariantRetryLoops_DifferentIntentSameScope(t *testing.T) {
 	t.Parallel()
 
@@ -25,6 +25,42 @@ type Entry struct {
 	ScopeClass     string
 	ExitCode       *int
 	RiskTags       []string
+	// ObservedMutation marks a write seen outside the protocol (e.g. in a
+	// Kubernetes audit log) that no prescription covers.
+	ObservedMutation bool
+	// IsVerification marks a post-execution read-back of live state for
+	// PrescriptionID; StateDrift is set when it did not match.
+	IsVerification bool
+	StateDrift     bool
+	// IsPlan marks an up-front plan whose ordered steps are PlannedSteps.
+	// Prescriptions made under a plan carry its PlanID and their 1-based
+	// PlanStep, 0 for a step the plan did not list.
+	IsPlan       bool
+	PlannedSteps []PlannedStep
+	PlanID       string
+	PlanStep     int
+	// RevertsPrescriptionID marks a prescription as a rollback of an
+	// earlier one.
+	RevertsPrescriptionID string
+	// TTL is the prescription's stored time-to-live; a report arriving
+	// after it is late. Zero disables the check.
+	TTL time.Duration
+	// IsCancel marks a withdrawal of PrescriptionID. It closes the
+	// prescription the way a report does.
+	IsCancel bool
+	// ApprovalRequired marks a prescription that must be approved before
+	// it runs. IsApproval marks a decision on PrescriptionID; Approved is
+	// set when the decision allowed the operation.
+	ApprovalRequired bool
+	IsApproval       bool
+	Approved         bool
+	Details          string
+}
+
+// PlannedStep is one step of a plan as the detectors compare it.
+type PlannedStep struct {
+	Tool      string
+	Operation string
 }
 
 // SignalResult holds the result of a single signal detection.
@@ -43,6 +79,27 @@ type SignalEvent struct {
 	Details   string    `json:"details"`
 }
 
+// SubSignalCounts breaks a signal's count down by sub-signal. It returns
+// nil for signals without sub-signals.
+func SubSignalCounts(name string, entries []Entry, ttl time.Duration) map[string]int {
+	var events []SignalEvent
+	switch name {
+	case "protocol_violation":
+		events = DetectProtocolViolationEvents(entries, ttl)
+	case "artifact_drift":
+		events = DetectArtifactDriftEvents(entries)
+	case "repair_loop":
+		events = DetectRepairLoopEvents(entries)
+	default:
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, ev := range events {
+		counts[ev.SubSignal]++
+	}
+	return counts
+}
+
 // AllSignals runs all signal detectors and returns their results.
 // TTL controls the window for unreported prescription detection. Use
 // DefaultTTL if no override is needed.
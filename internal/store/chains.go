@@ -0,0 +1,266 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// ErrChainFork is returned when an entry links to a stored entry that is no
+// longer the head of its chain: a second entry claims the same predecessor.
+var ErrChainFork = errors.New("chain fork")
+
+// Chain is the server's view of one writer's hash chain within a tenant.
+// Writers are identified by signing key, or by actor instance for unsigned
+// entries. A chain is named after the entry_id of its first stored entry.
+type Chain struct {
+	TenantID   string
+	ChainID    string
+	Writer     string
+	KeyID      string // key of the latest entry; changes on key rotation
+	ActorType  string
+	ActorID    string
+	InstanceID string
+	// GenesisPreviousHash is set when the first stored entry links to an
+	// entry the server never received; the chain is detached.
+	GenesisHash         string
+	GenesisPreviousHash string
+	HeadHash            string
+	HeadEntryID         string
+	Length              int64
+	FirstSeen           time.Time
+	LastSeen            time.Time
+	// Valid is nil until the chain is validated server-side.
+	Valid           *bool
+	ValidationError string
+	ValidatedAt     *time.Time
+}
+
+// ChainEntry is one stored entry of a chain, in chain order. Exact is false
+// for entries stored before raw bytes were retained; Raw is then the
+// normalized JSONB payload and its hash cannot be recomputed.
+type ChainEntry struct {
+	Seq     int64
+	EntryID string
+	Raw     json.RawMessage
+	Exact   bool
+}
+
+// ChainWriter returns the writer identity of an entry: its signing key when
+// it names one, otherwise its actor instance.
+func ChainWriter(keyID string, actor evidence.Actor) string {
+	if keyID != "" {
+		return "key:" + keyID
+	}
+	writer := "actor:" + actor.Type + "/" + actor.ID
+	if actor.InstanceID != "" {
+		writer += "/" + actor.InstanceID
+	}
+	return writer
+}
+
+// chainEnvelope holds the entry fields SaveRaw indexes.
+type chainEnvelope struct {
+	EntryID        string         `json:"entry_id"`
+	Type           string         `json:"type"`
+	SessionID      string         `json:"session_id"`
+	OperationID    string         `json:"operation_id"`
+	PreviousHash   string         `json:"previous_hash"`
+	Hash           string         `json:"hash"`
+	Signature      string         `json:"signature"`
+	KeyID          string         `json:"key_id"`
+	IntentDigest   string         `json:"intent_digest"`
+	ArtifactDigest string         `json:"artifact_digest"`
+	Actor          evidence.Actor `json:"actor"`
+}
+
+// linkChain appends the entry to the chain whose head is its previous_hash,
+// or starts a new chain for a genesis entry. It returns the chain ID and the
+// entry's position. Entries without a hash are not chained.
+func linkChain(ctx context.Context, tx pgx.Tx, tenantID, entryID string, env chainEnvelope) (string, int64, error) {
+	if env.Hash == "" {
+		return "", 0, nil
+	}
+
+	if env.PreviousHash != "" {
+		var chainID string
+		var length int64
+		err := tx.QueryRow(ctx,
+			`SELECT chain_id, length FROM evidence_chains
+			 WHERE tenant_id = $1 AND head_hash = $2
+			 LIMIT 1
+			 FOR UPDATE`,
+			tenantID, env.PreviousHash,
+		).Scan(&chainID, &length)
+		switch {
+		case err == nil:
+			if _, err := tx.Exec(ctx,
+				`UPDATE evidence_chains
+				 SET head_hash = $3, head_entry_id = $4, length = length + 1,
+				     key_id = CASE WHEN $5 <> '' THEN $5 ELSE key_id END,
+				     last_seen = now()
+				 WHERE tenant_id = $1 AND chain_id = $2`,
+				tenantID, chainID, env.Hash, entryID, env.KeyID,
+			); err != nil {
+				return "", 0, fmt.Errorf("extend chain: %w", err)
+			}
+			return chainID, length + 1, nil
+		case !errors.Is(err, pgx.ErrNoRows):
+			return "", 0, fmt.Errorf("load chain head: %w", err)
+		}
+
+		var stored bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM evidence_entries WHERE tenant_id = $1 AND hash = $2)`,
+			tenantID, env.PreviousHash,
+		).Scan(&stored); err != nil {
+			return "", 0, fmt.Errorf("lookup predecessor: %w", err)
+		}
+		if stored {
+			return "", 0, fmt.Errorf("%w: %s already has a successor", ErrChainFork, env.PreviousHash)
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO evidence_chains
+		 (tenant_id, chain_id, writer, key_id, actor_type, actor_id, instance_id,
+		  genesis_hash, genesis_previous_hash, head_hash, head_entry_id, length)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$8,$2,1)`,
+		tenantID, entryID, ChainWriter(env.KeyID, env.Actor), env.KeyID,
+		env.Actor.Type, env.Actor.ID, env.Actor.InstanceID,
+		env.Hash, env.PreviousHash,
+	); err != nil {
+		return "", 0, fmt.Errorf("start chain: %w", err)
+	}
+	return entryID, 1, nil
+}
+
+// ChainHead returns the head hash of the writer's most recently extended
+// chain, or "" when the writer has not stored an entry yet.
+func (es *EntryStore) ChainHead(ctx context.Context, tenantID, writer string) (string, error) {
+	var head string
+	err := es.pool.QueryRow(ctx,
+		`SELECT head_hash FROM evidence_chains
+		 WHERE tenant_id = $1 AND writer = $2
+		 ORDER BY last_seen DESC
+		 LIMIT 1`,
+		tenantID, writer,
+	).Scan(&head)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("store.ChainHead: %w", err)
+	}
+	return head, nil
+}
+
+const chainColumns = `tenant_id, chain_id, writer, key_id, actor_type, actor_id, instance_id,
+	genesis_hash, genesis_previous_hash, head_hash, head_entry_id, length,
+	first_seen, last_seen, valid, validation_error, validated_at`
+
+func scanChain(row pgx.Row) (Chain, error) {
+	var c Chain
+	err := row.Scan(&c.TenantID, &c.ChainID, &c.Writer, &c.KeyID, &c.ActorType, &c.ActorID, &c.InstanceID,
+		&c.GenesisHash, &c.GenesisPreviousHash, &c.HeadHash, &c.HeadEntryID, &c.Length,
+		&c.FirstSeen, &c.LastSeen, &c.Valid, &c.ValidationError, &c.ValidatedAt)
+	return c, err
+}
+
+// ListChains returns the tenant's chains, most recently extended first.
+func (es *EntryStore) ListChains(ctx context.Context, tenantID string) ([]Chain, error) {
+	rows, err := es.pool.Query(ctx,
+		`SELECT `+chainColumns+`
+		 FROM evidence_chains
+		 WHERE tenant_id = $1
+		 ORDER BY last_seen DESC, chain_id`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store.ListChains: %w", err)
+	}
+	defer rows.Close()
+
+	var chains []Chain
+	for rows.Next() {
+		c, err := scanChain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("store.ListChains: scan: %w", err)
+		}
+		chains = append(chains, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.ListChains: rows: %w", err)
+	}
+	return chains, nil
+}
+
+// GetChain returns one chain of the tenant.
+func (es *EntryStore) GetChain(ctx context.Context, tenantID, chainID string) (Chain, error) {
+	c, err := scanChain(es.pool.QueryRow(ctx,
+		`SELECT `+chainColumns+`
+		 FROM evidence_chains
+		 WHERE tenant_id = $1 AND chain_id = $2`,
+		tenantID, chainID,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Chain{}, fmt.Errorf("store.GetChain: %w", ErrNotFound)
+		}
+		return Chain{}, fmt.Errorf("store.GetChain: %w", err)
+	}
+	return c, nil
+}
+
+// ChainEntries calls fn for each entry of the chain in chain order.
+func (es *EntryStore) ChainEntries(ctx context.Context, tenantID, chainID string, fn func(ChainEntry) error) error {
+	rows, err := es.pool.Query(ctx,
+		`SELECT chain_seq, id, COALESCE(raw, convert_to(payload::text, 'UTF8')), raw IS NOT NULL
+		 FROM evidence_entries
+		 WHERE tenant_id = $1 AND chain_id = $2
+		 ORDER BY chain_seq`,
+		tenantID, chainID,
+	)
+	if err != nil {
+		return fmt.Errorf("store.ChainEntries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e ChainEntry
+		var raw []byte
+		if err := rows.Scan(&e.Seq, &e.EntryID, &raw, &e.Exact); err != nil {
+			return fmt.Errorf("store.ChainEntries: scan: %w", err)
+		}
+		e.Raw = raw
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("store.ChainEntries: rows: %w", err)
+	}
+	return nil
+}
+
+// RecordChainValidation stores the outcome of a server-side validation.
+func (es *EntryStore) RecordChainValidation(ctx context.Context, tenantID, chainID string, valid bool, reason string) error {
+	tag, err := es.pool.Exec(ctx,
+		`UPDATE evidence_chains
+		 SET valid = $3, validation_error = $4, validated_at = now()
+		 WHERE tenant_id = $1 AND chain_id = $2`,
+		tenantID, chainID, valid, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("store.RecordChainValidation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("store.RecordChainValidation: %w", ErrNotFound)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,6 +21,10 @@ import (
 // ErrNotFound is returned when a requested resource does not exist.
 var ErrNotFound = errors.New("not found")
 
+// ErrEntryConflict is returned when an entry ID is already stored with a
+// different hash or for another tenant.
+var ErrEntryConflict = errors.New("entry id conflict")
+
 // StoredEntry represents an evidence entry in the database.
 type StoredEntry struct {
 	ID              string
@@ -37,13 +42,68 @@ type StoredEntry struct {
 	CreatedAt       time.Time
 }
 
-// ListOptions controls entry listing pagination and filters.
+// ErrInvalidCursor is returned when a page cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListOptions controls entry listing pagination and filters. String filters
+// match exactly; slice filters match any of their values.
 type ListOptions struct {
-	Limit     int
-	Offset    int
-	EntryType string
-	Period    string
-	SessionID string
+	Limit int
+	// Offset pages by position. Prefer Cursor, which stays stable while
+	// entries are being ingested.
+	Offset int
+	// Cursor resumes after the last entry of a previous page
+	// (EntryPage.NextCursor).
+	Cursor string
+
+	Types          []string
+	Period         string
+	SessionID      string
+	ActorID        string
+	Tool           string
+	OperationClass string
+	ScopeClass     string
+	EffectiveRisks []string
+	Verdicts       []string
+	IntentDigest   string
+	ArtifactDigest string
+	// ScopeDimensions matches entries whose scope dimensions contain every
+	// key/value pair.
+	ScopeDimensions map[string]string
+	// Since and Until bound created_at, inclusive and exclusive.
+	Since time.Time
+	Until time.Time
+}
+
+// EntryPage is one page of a ListEntries result.
+type EntryPage struct {
+	Entries []StoredEntry
+	// Total counts all matching entries. It is only computed for the first
+	// page (no cursor) and is -1 otherwise.
+	Total int
+	// NextCursor fetches the following page; empty on the last page.
+	NextCursor string
+}
+
+// entryCursor is the position encoded in an opaque page cursor. Entries are
+// listed newest first by (created_at, id).
+type entryCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func encodeEntryCursor(e StoredEntry) string {
+	data, _ := json.Marshal(entryCursor{CreatedAt: e.CreatedAt, ID: e.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeEntryCursor(s string) (entryCursor, error) {
+	var c entryCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return entryCursor{}, ErrInvalidCursor
+	}
+	return c, nil
 }
 
 // Resolved returns a copy with default values applied (limit clamped to 1–1000, default 100).
@@ -117,94 +177,164 @@ func (es *EntryStore) GetEntry(ctx context.Context, tenantID, entryID string) (S
 	return e, nil
 }
 
-// ListEntries returns paginated entries for a tenant.
-func (es *EntryStore) ListEntries(ctx context.Context, tenantID string, opts ListOptions) ([]StoredEntry, int, error) {
+// ListEntries returns a page of a tenant's entries, newest first.
+func (es *EntryStore) ListEntries(ctx context.Context, tenantID string, opts ListOptions) (EntryPage, error) {
 	opts = opts.withDefaults()
 
+	q, err := buildEntryQuery(tenantID, opts, time.Now())
+	if err != nil {
+		return EntryPage{}, fmt.Errorf("store.ListEntries: %w", err)
+	}
+
+	page := EntryPage{Total: -1}
+	if opts.Cursor == "" {
+		countQuery := "SELECT COUNT(*) FROM evidence_entries WHERE " + q.where
+		if err := es.pool.QueryRow(ctx, countQuery, q.args...).Scan(&page.Total); err != nil {
+			return EntryPage{}, fmt.Errorf("store.ListEntries: count: %w", err)
+		}
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	rows, err := es.pool.Query(ctx, q.pageSQL(), q.pageArgs()...)
+	if err != nil {
+		return EntryPage{}, fmt.Errorf("store.ListEntries: query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e StoredEntry
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.EntryType, &e.SessionID, &e.OperationID,
+			&e.PreviousHash, &e.Hash, &e.Signature, &e.IntentDigest, &e.ArtifactDigest,
+			&e.Payload, &e.ScopeDimensions, &e.CreatedAt); err != nil {
+			return EntryPage{}, fmt.Errorf("store.ListEntries: scan: %w", err)
+		}
+		page.Entries = append(page.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return EntryPage{}, fmt.Errorf("store.ListEntries: rows: %w", err)
+	}
+
+	if len(page.Entries) > opts.Limit {
+		page.Entries = page.Entries[:opts.Limit]
+		page.NextCursor = encodeEntryCursor(page.Entries[opts.Limit-1])
+	}
+	return page, nil
+}
+
+// entryQuery is the SQL for a filtered entry listing.
+type entryQuery struct {
+	where  string
+	args   []interface{}
+	limit  int
+	offset int
+}
+
+// The payload column holds the full evidence entry; these are the paths of
+// the fields the listing filters on.
+const (
+	actorIDExpr        = `payload->'actor'->>'id'`
+	toolExpr           = `payload->'payload'->'canonical_action'->>'tool'`
+	operationClassExpr = `payload->'payload'->'canonical_action'->>'operation_class'`
+	scopeClassExpr     = `payload->'payload'->'canonical_action'->>'scope_class'`
+	effectiveRiskExpr  = `payload->'payload'->>'effective_risk'`
+	verdictExpr        = `payload->'payload'->>'verdict'`
+	scopeDimsExpr      = `payload->'scope_dimensions'`
+)
+
+func buildEntryQuery(tenantID string, opts ListOptions, now time.Time) (entryQuery, error) {
 	var where []string
 	var args []interface{}
-	argIdx := 1
-
-	where = append(where, fmt.Sprintf("tenant_id = $%d", argIdx))
-	args = append(args, tenantID)
-	argIdx++
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, strings.ReplaceAll(cond, "$?", fmt.Sprintf("$%d", len(args))))
+	}
 
-	if opts.EntryType != "" {
-		where = append(where, fmt.Sprintf("entry_type = $%d", argIdx))
-		args = append(args, opts.EntryType)
-		argIdx++
+	add("tenant_id = $?", tenantID)
+	if len(opts.Types) > 0 {
+		add("entry_type = ANY($?)", opts.Types)
 	}
 	if opts.SessionID != "" {
-		where = append(where, fmt.Sprintf("session_id = $%d", argIdx))
-		args = append(args, opts.SessionID)
-		argIdx++
+		add("session_id = $?", opts.SessionID)
+	}
+	if opts.IntentDigest != "" {
+		add("intent_digest = $?", opts.IntentDigest)
+	}
+	if opts.ArtifactDigest != "" {
+		add("artifact_digest = $?", opts.ArtifactDigest)
+	}
+	if opts.ActorID != "" {
+		add(actorIDExpr+" = $?", opts.ActorID)
+	}
+	if opts.Tool != "" {
+		add(toolExpr+" = $?", opts.Tool)
+	}
+	if opts.OperationClass != "" {
+		add(operationClassExpr+" = $?", opts.OperationClass)
+	}
+	if opts.ScopeClass != "" {
+		add(scopeClassExpr+" = $?", opts.ScopeClass)
+	}
+	if len(opts.EffectiveRisks) > 0 {
+		add(effectiveRiskExpr+" = ANY($?)", opts.EffectiveRisks)
+	}
+	if len(opts.Verdicts) > 0 {
+		add(verdictExpr+" = ANY($?)", opts.Verdicts)
+	}
+	if len(opts.ScopeDimensions) > 0 {
+		dims, err := json.Marshal(opts.ScopeDimensions)
+		if err != nil {
+			return entryQuery{}, err
+		}
+		add(scopeDimsExpr+" @> $?::jsonb", string(dims))
 	}
 	if opts.Period != "" {
-		dur := parsePeriod(opts.Period)
-		where = append(where, fmt.Sprintf("created_at >= $%d", argIdx))
-		args = append(args, time.Now().Add(-dur))
-		argIdx++
+		add("created_at >= $?", now.Add(-parsePeriod(opts.Period)))
+	}
+	if !opts.Since.IsZero() {
+		add("created_at >= $?", opts.Since)
+	}
+	if !opts.Until.IsZero() {
+		add("created_at < $?", opts.Until)
 	}
 
-	whereClause := strings.Join(where, " AND ")
-
-	// Count total.
-	var total int
-	countQuery := "SELECT COUNT(*) FROM evidence_entries WHERE " + whereClause
-	if err := es.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("store.ListEntries: count: %w", err)
+	q := entryQuery{where: strings.Join(where, " AND "), args: args, limit: opts.Limit}
+	if opts.Cursor == "" {
+		q.offset = opts.Offset
+		return q, nil
+	}
+	c, err := decodeEntryCursor(opts.Cursor)
+	if err != nil {
+		return entryQuery{}, err
 	}
+	q.args = append(q.args, c.CreatedAt, c.ID)
+	q.where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(q.args)-1, len(q.args))
+	return q, nil
+}
 
-	// Fetch page.
-	query := fmt.Sprintf(
+// pageSQL selects limit+1 rows so the caller can tell whether more follow.
+func (q entryQuery) pageSQL() string {
+	n := len(q.args)
+	return fmt.Sprintf(
 		`SELECT id, tenant_id, entry_type, session_id, operation_id,
 		        previous_hash, hash, signature, intent_digest, artifact_digest,
 		        payload, scope_dimensions, created_at
 		 FROM evidence_entries
 		 WHERE %s
-		 ORDER BY created_at DESC
+		 ORDER BY created_at DESC, id DESC
 		 LIMIT $%d OFFSET $%d`,
-		whereClause, argIdx, argIdx+1,
+		q.where, n+1, n+2,
 	)
-	args = append(args, opts.Limit, opts.Offset)
-
-	rows, err := es.pool.Query(ctx, query, args...)
-	if err != nil {
-		return nil, 0, fmt.Errorf("store.ListEntries: query: %w", err)
-	}
-	defer rows.Close()
-
-	var entries []StoredEntry
-	for rows.Next() {
-		var e StoredEntry
-		if err := rows.Scan(&e.ID, &e.TenantID, &e.EntryType, &e.SessionID, &e.OperationID,
-			&e.PreviousHash, &e.Hash, &e.Signature, &e.IntentDigest, &e.ArtifactDigest,
-			&e.Payload, &e.ScopeDimensions, &e.CreatedAt); err != nil {
-			return nil, 0, fmt.Errorf("store.ListEntries: scan: %w", err)
-		}
-		entries = append(entries, e)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("store.ListEntries: rows: %w", err)
-	}
+}
 
-	return entries, total, nil
+func (q entryQuery) pageArgs() []interface{} {
+	return append(q.args[:len(q.args):len(q.args)], q.limit+1, q.offset)
 }
 
 // SaveRaw persists a raw JSON entry (implements RawEntryStore for forward/batch handlers).
 // Parses the JSON to extract structured fields for indexing and provenance continuity.
+// The entry is appended to the chain whose head it links to; see linkChain.
 func (es *EntryStore) SaveRaw(ctx context.Context, tenantID string, raw json.RawMessage) (string, error) {
-	var envelope struct {
-		EntryID        string `json:"entry_id"`
-		Type           string `json:"type"`
-		SessionID      string `json:"session_id"`
-		OperationID    string `json:"operation_id"`
-		PreviousHash   string `json:"previous_hash"`
-		Hash           string `json:"hash"`
-		Signature      string `json:"signature"`
-		IntentDigest   string `json:"intent_digest"`
-		ArtifactDigest string `json:"artifact_digest"`
-	}
+	var envelope chainEnvelope
 	_ = json.Unmarshal(raw, &envelope)
 
 	id := envelope.EntryID
@@ -216,40 +346,64 @@ func (es *EntryStore) SaveRaw(ctx context.Context, tenantID string, raw json.Raw
 		entryType = "raw"
 	}
 
-	_, err := es.pool.Exec(ctx,
+	tx, err := es.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("store.SaveRaw: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialize writers per tenant so stream positions follow commit order.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, tenantID); err != nil {
+		return "", fmt.Errorf("store.SaveRaw: lock tenant: %w", err)
+	}
+
+	// Re-sending an entry already stored for this tenant with the same hash
+	// is a no-op, so clients can retry batches after a lost response.
+	var existingTenant, existingHash string
+	err = tx.QueryRow(ctx,
+		`SELECT tenant_id, hash FROM evidence_entries WHERE id = $1`, id,
+	).Scan(&existingTenant, &existingHash)
+	switch {
+	case err == nil:
+		if existingTenant == tenantID && existingHash == envelope.Hash {
+			return id, nil
+		}
+		return "", fmt.Errorf("store.SaveRaw: %w: entry %s", ErrEntryConflict, id)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return "", fmt.Errorf("store.SaveRaw: lookup: %w", err)
+	}
+
+	chainID, seq, err := linkChain(ctx, tx, tenantID, id, envelope)
+	if err != nil {
+		return "", fmt.Errorf("store.SaveRaw: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx,
 		`INSERT INTO evidence_entries
 		 (id, tenant_id, entry_type, session_id, operation_id,
 		  previous_hash, hash, signature, intent_digest, artifact_digest,
-		  payload, created_at)
-		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now())`,
+		  payload, raw, chain_id, chain_seq, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,now())
+		 ON CONFLICT (id) DO NOTHING`,
 		id, tenantID, entryType, envelope.SessionID, envelope.OperationID,
 		envelope.PreviousHash, envelope.Hash, envelope.Signature,
-		envelope.IntentDigest, envelope.ArtifactDigest, raw,
+		envelope.IntentDigest, envelope.ArtifactDigest, raw, []byte(raw),
+		chainID, seq,
 	)
 	if err != nil {
 		return "", fmt.Errorf("store.SaveRaw: %w", err)
 	}
-	return id, nil
-}
-
-// LastHash returns the most recent entry hash for a tenant.
-func (es *EntryStore) LastHash(ctx context.Context, tenantID string) (string, error) {
-	var hash string
-	err := es.pool.QueryRow(ctx,
-		`SELECT hash
-		 FROM evidence_entries
-		 WHERE tenant_id = $1
-		 ORDER BY created_at DESC, id DESC
-		 LIMIT 1`,
-		tenantID,
-	).Scan(&hash)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return "", nil
-		}
-		return "", fmt.Errorf("store.LastHash: %w", err)
+	if tag.RowsAffected() == 0 {
+		return "", fmt.Errorf("store.SaveRaw: %w: entry %s", ErrEntryConflict, id)
 	}
-	return hash, nil
+	// Delivered to stream listeners when the transaction commits.
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, EntryNotifyChannel, tenantID); err != nil {
+		return "", fmt.Errorf("store.SaveRaw: notify: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("store.SaveRaw: commit: %w", err)
+	}
+	return id, nil
 }
 
 // ClaimWebhookEvent records an idempotency key. Returns duplicate=true when the
@@ -307,6 +461,22 @@ func (es *EntryStore) ComputeExplain(ctx context.Context, tenantID string, filte
 	return computeExplainFromStoredEntries(entries, filters)
 }
 
+// ApprovalEntries returns the tenant's prescriptions, reports,
+// cancellations and approval decisions from the default analytics period,
+// oldest first, for the approval workflow. Empty sessionID matches every
+// session.
+func (es *EntryStore) ApprovalEntries(ctx context.Context, tenantID, sessionID string) ([]evidence.EvidenceEntry, error) {
+	entries, err := collectAnalyticsReplayEntries(ctx, tenantID, ListOptions{
+		Types:     []string{"prescribe", "report", "cancel", "approval"},
+		Period:    "30d",
+		SessionID: sessionID,
+	}, analyticsReplayPageSize, es.ListEntries)
+	if err != nil {
+		return nil, fmt.Errorf("ApprovalEntries: %w", err)
+	}
+	return storedEntriesToEvidenceEntries(entries)
+}
+
 func storedEntriesToEvidenceEntries(entries []StoredEntry) ([]evidence.EvidenceEntry, error) {
 	return analyticsdb.EvidenceEntriesFromStoredRows(storedRows(entries))
 }
@@ -335,7 +505,7 @@ func collectAnalyticsReplayEntries(
 	tenantID string,
 	baseOpts ListOptions,
 	pageSize int,
-	list func(context.Context, string, ListOptions) ([]StoredEntry, int, error),
+	list func(context.Context, string, ListOptions) (EntryPage, error),
 ) ([]StoredEntry, error) {
 	if pageSize <= 0 {
 		pageSize = analyticsReplayPageSize
@@ -344,18 +514,19 @@ func collectAnalyticsReplayEntries(
 	opts := baseOpts
 	opts.Limit = pageSize
 	opts.Offset = 0
+	opts.Cursor = ""
 
 	all := make([]StoredEntry, 0, pageSize)
 	for {
-		page, total, err := list(ctx, tenantID, opts)
+		page, err := list(ctx, tenantID, opts)
 		if err != nil {
 			return nil, err
 		}
-		all = append(all, page...)
-		if len(all) >= total || len(page) < opts.Limit {
+		all = append(all, page.Entries...)
+		if page.NextCursor == "" {
 			return all, nil
 		}
-		opts.Offset += len(page)
+		opts.Cursor = page.NextCursor
 	}
 }
 
@@ -2,7 +2,10 @@ package store
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,20 +19,20 @@ func TestCollectAnalyticsReplayEntries_PaginatesUntilExhausted(t *testing.T) {
 	t.Parallel()
 
 	calls := 0
-	gotOffsets := make([]int, 0, 3)
-	fetch := func(_ context.Context, _ string, opts ListOptions) ([]StoredEntry, int, error) {
+	gotCursors := make([]string, 0, 3)
+	fetch := func(_ context.Context, _ string, opts ListOptions) (EntryPage, error) {
 		calls++
-		gotOffsets = append(gotOffsets, opts.Offset)
-		switch opts.Offset {
-		case 0:
-			return []StoredEntry{{ID: "a"}, {ID: "b"}}, 5, nil
-		case 2:
-			return []StoredEntry{{ID: "c"}, {ID: "d"}}, 5, nil
-		case 4:
-			return []StoredEntry{{ID: "e"}}, 5, nil
+		gotCursors = append(gotCursors, opts.Cursor)
+		switch opts.Cursor {
+		case "":
+			return EntryPage{Entries: []StoredEntry{{ID: "a"}, {ID: "b"}}, Total: 5, NextCursor: "c1"}, nil
+		case "c1":
+			return EntryPage{Entries: []StoredEntry{{ID: "c"}, {ID: "d"}}, Total: -1, NextCursor: "c2"}, nil
+		case "c2":
+			return EntryPage{Entries: []StoredEntry{{ID: "e"}}, Total: -1}, nil
 		default:
-			t.Fatalf("unexpected offset %d", opts.Offset)
-			return nil, 0, nil
+			t.Fatalf("unexpected cursor %q", opts.Cursor)
+			return EntryPage{}, nil
 		}
 	}
 
@@ -40,14 +43,115 @@ func TestCollectAnalyticsReplayEntries_PaginatesUntilExhausted(t *testing.T) {
 	if calls != 3 {
 		t.Fatalf("calls = %d, want 3", calls)
 	}
-	if want := []int{0, 2, 4}; len(gotOffsets) != len(want) || gotOffsets[0] != want[0] || gotOffsets[1] != want[1] || gotOffsets[2] != want[2] {
-		t.Fatalf("offsets = %v, want %v", gotOffsets, want)
+	if want := []string{"", "c1", "c2"}; strings.Join(gotCursors, ",") != strings.Join(want, ",") {
+		t.Fatalf("cursors = %q, want %q", gotCursors, want)
 	}
 	if len(got) != 5 {
 		t.Fatalf("entries len = %d, want 5", len(got))
 	}
 }
 
+func TestEntryCursor_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	cursor := encodeEntryCursor(StoredEntry{ID: "01HX", CreatedAt: at})
+	got, err := decodeEntryCursor(cursor)
+	if err != nil {
+		t.Fatalf("decodeEntryCursor: %v", err)
+	}
+	if got.ID != "01HX" || !got.CreatedAt.Equal(at) {
+		t.Fatalf("cursor = %+v", got)
+	}
+
+	for _, bad := range []string{"not base64!", "e30", base64.RawURLEncoding.EncodeToString([]byte(`{"id":"x"}`))} {
+		if _, err := decodeEntryCursor(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeEntryCursor(%q) err = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestBuildEntryQuery(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	cursor := encodeEntryCursor(StoredEntry{ID: "01HX", CreatedAt: now.Add(-time.Hour)})
+	tests := []struct {
+		name      string
+		opts      ListOptions
+		wantWhere string
+		wantArgs  int
+		wantErr   error
+	}{
+		{
+			name:      "tenant only",
+			opts:      ListOptions{Limit: 10},
+			wantWhere: "tenant_id = $1",
+			wantArgs:  1,
+		},
+		{
+			name: "filters",
+			opts: ListOptions{
+				Limit:           10,
+				Types:           []string{"prescribe"},
+				ActorID:         "agent-a",
+				Tool:            "kubectl",
+				OperationClass:  "destructive",
+				ScopeClass:      "production",
+				EffectiveRisks:  []string{"high", "critical"},
+				ArtifactDigest:  "sha256:abc",
+				ScopeDimensions: map[string]string{"cluster": "prod-eu"},
+				Since:           now.Add(-24 * time.Hour),
+				Until:           now,
+			},
+			wantWhere: "tenant_id = $1 AND entry_type = ANY($2) AND artifact_digest = $3" +
+				" AND payload->'actor'->>'id' = $4" +
+				" AND payload->'payload'->'canonical_action'->>'tool' = $5" +
+				" AND payload->'payload'->'canonical_action'->>'operation_class' = $6" +
+				" AND payload->'payload'->'canonical_action'->>'scope_class' = $7" +
+				" AND payload->'payload'->>'effective_risk' = ANY($8)" +
+				" AND payload->'scope_dimensions' @> $9::jsonb" +
+				" AND created_at >= $10 AND created_at < $11",
+			wantArgs: 11,
+		},
+		{
+			name:      "cursor",
+			opts:      ListOptions{Limit: 10, Verdicts: []string{"declined"}, Cursor: cursor},
+			wantWhere: "tenant_id = $1 AND payload->'payload'->>'verdict' = ANY($2) AND (created_at, id) < ($3, $4)",
+			wantArgs:  4,
+		},
+		{
+			name:    "bad cursor",
+			opts:    ListOptions{Limit: 10, Cursor: "bogus"},
+			wantErr: ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			q, err := buildEntryQuery("tenant-1", tt.opts, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildEntryQuery: %v", err)
+			}
+			if q.where != tt.wantWhere {
+				t.Fatalf("where = %s\nwant    %s", q.where, tt.wantWhere)
+			}
+			if len(q.args) != tt.wantArgs {
+				t.Fatalf("args = %d, want %d", len(q.args), tt.wantArgs)
+			}
+			if args := q.pageArgs(); args[len(args)-2] != tt.opts.Limit+1 {
+				t.Fatalf("page limit arg = %v, want %d", args[len(args)-2], tt.opts.Limit+1)
+			}
+		})
+	}
+}
+
 func TestListOptions_Defaults(t *testing.T) {
 	t.Parallel()
 	opts := ListOptions{}
@@ -0,0 +1,385 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oklog/ulid/v2"
+)
+
+// Delivery statuses recorded in notification_deliveries.status.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// NotificationConditions select the events a rule fires on. Every set
+// condition must hold.
+type NotificationConditions struct {
+	MinRisk  string   `json:"min_risk,omitempty"`
+	Signals  []string `json:"signals,omitempty"`
+	Verdicts []string `json:"verdicts,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+// NotificationRule is a tenant's outbound notification rule.
+type NotificationRule struct {
+	ID         string
+	TenantID   string
+	Name       string
+	Target     string
+	URL        string
+	Conditions NotificationConditions
+	Enabled    bool
+	CreatedAt  time.Time
+}
+
+// NotificationDelivery is one rendered notification and its delivery state.
+// EventKey identifies the event within the rule, so re-matching an entry
+// never queues it twice.
+type NotificationDelivery struct {
+	ID             string
+	TenantID       string
+	RuleID         string
+	EventKey       string
+	EntryID        string
+	Signal         string
+	Target         string
+	URL            string
+	Body           []byte
+	Status         string
+	Attempts       int
+	LastStatusCode *int
+	LastError      string
+	CreatedAt      time.Time
+	NextAttemptAt  time.Time
+	DeliveredAt    *time.Time
+}
+
+// DeliveryAttempt is the outcome of one send. A failed attempt is retried
+// at RetryAt, or marked failed when RetryAt is nil.
+type DeliveryAttempt struct {
+	StatusCode int // 0 when no response was received
+	Error      string
+	Delivered  bool
+	RetryAt    *time.Time
+}
+
+// NotificationStore manages notification rules and deliveries backed by
+// PostgreSQL.
+type NotificationStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewNotificationStore creates a NotificationStore with the given connection
+// pool.
+func NewNotificationStore(pool *pgxpool.Pool) *NotificationStore {
+	return &NotificationStore{pool: pool}
+}
+
+const notificationRuleColumns = `id, tenant_id, name, target, url, conditions, enabled, created_at`
+
+func scanNotificationRule(row pgx.Row) (NotificationRule, error) {
+	var r NotificationRule
+	var conditions []byte
+	if err := row.Scan(&r.ID, &r.TenantID, &r.Name, &r.Target, &r.URL, &conditions, &r.Enabled, &r.CreatedAt); err != nil {
+		return NotificationRule{}, err
+	}
+	if err := json.Unmarshal(conditions, &r.Conditions); err != nil {
+		return NotificationRule{}, fmt.Errorf("rule %s conditions: %w", r.ID, err)
+	}
+	return r, nil
+}
+
+// CreateRule stores a rule. The tenant's entries are matched from the
+// current stream head onward; entries stored earlier never notify.
+func (s *NotificationStore) CreateRule(ctx context.Context, rule NotificationRule) (NotificationRule, error) {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return NotificationRule{}, fmt.Errorf("store.CreateRule: conditions: %w", err)
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return NotificationRule{}, fmt.Errorf("store.CreateRule: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO tenants (id) VALUES ($1) ON CONFLICT (id) DO NOTHING`,
+		rule.TenantID,
+	); err != nil {
+		return NotificationRule{}, fmt.Errorf("store.CreateRule: ensure tenant: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO notification_cursors (tenant_id, stream_seq)
+		 SELECT $1, COALESCE(MAX(stream_seq), 0) FROM evidence_entries WHERE tenant_id = $1
+		 ON CONFLICT (tenant_id) DO NOTHING`,
+		rule.TenantID,
+	); err != nil {
+		return NotificationRule{}, fmt.Errorf("store.CreateRule: cursor: %w", err)
+	}
+	created, err := scanNotificationRule(tx.QueryRow(ctx,
+		`INSERT INTO notification_rules (id, tenant_id, name, target, url, conditions, enabled)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+notificationRuleColumns,
+		ulid.Make().String(), rule.TenantID, rule.Name, rule.Target, rule.URL, conditions, rule.Enabled,
+	))
+	if err != nil {
+		return NotificationRule{}, fmt.Errorf("store.CreateRule: insert: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return NotificationRule{}, fmt.Errorf("store.CreateRule: commit: %w", err)
+	}
+	return created, nil
+}
+
+// ListRules returns the tenant's rules, newest first.
+func (s *NotificationStore) ListRules(ctx context.Context, tenantID string) ([]NotificationRule, error) {
+	return s.queryRules(ctx, "store.ListRules",
+		`SELECT `+notificationRuleColumns+` FROM notification_rules
+		 WHERE tenant_id = $1 ORDER BY created_at DESC, id DESC`,
+		tenantID,
+	)
+}
+
+// EnabledRules returns the tenant's enabled rules, oldest first.
+func (s *NotificationStore) EnabledRules(ctx context.Context, tenantID string) ([]NotificationRule, error) {
+	return s.queryRules(ctx, "store.EnabledRules",
+		`SELECT `+notificationRuleColumns+` FROM notification_rules
+		 WHERE tenant_id = $1 AND enabled ORDER BY created_at, id`,
+		tenantID,
+	)
+}
+
+func (s *NotificationStore) queryRules(ctx context.Context, op, query string, args ...interface{}) ([]NotificationRule, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	var out []NotificationRule
+	for rows.Next() {
+		r, err := scanNotificationRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+	return out, nil
+}
+
+// GetRule returns one of the tenant's rules, or ErrNotFound.
+func (s *NotificationStore) GetRule(ctx context.Context, tenantID, ruleID string) (NotificationRule, error) {
+	r, err := scanNotificationRule(s.pool.QueryRow(ctx,
+		`SELECT `+notificationRuleColumns+` FROM notification_rules WHERE tenant_id = $1 AND id = $2`,
+		tenantID, ruleID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return NotificationRule{}, ErrNotFound
+	}
+	if err != nil {
+		return NotificationRule{}, fmt.Errorf("store.GetRule: %w", err)
+	}
+	return r, nil
+}
+
+// DeleteRule removes a rule and its delivery log, or returns ErrNotFound.
+func (s *NotificationStore) DeleteRule(ctx context.Context, tenantID, ruleID string) error {
+	tag, err := s.pool.Exec(ctx,
+		`DELETE FROM notification_rules WHERE tenant_id = $1 AND id = $2`,
+		tenantID, ruleID,
+	)
+	if err != nil {
+		return fmt.Errorf("store.DeleteRule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const notificationDeliveryColumns = `id, tenant_id, rule_id, event_key, entry_id, signal, target, url, body,
+	status, attempts, last_status_code, last_error, created_at, next_attempt_at, delivered_at`
+
+func scanNotificationDelivery(row pgx.Row) (NotificationDelivery, error) {
+	var d NotificationDelivery
+	err := row.Scan(&d.ID, &d.TenantID, &d.RuleID, &d.EventKey, &d.EntryID, &d.Signal, &d.Target, &d.URL, &d.Body,
+		&d.Status, &d.Attempts, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.NextAttemptAt, &d.DeliveredAt)
+	return d, err
+}
+
+// ListDeliveries returns up to limit of a rule's deliveries, newest first.
+func (s *NotificationStore) ListDeliveries(ctx context.Context, tenantID, ruleID string, limit int) ([]NotificationDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+notificationDeliveryColumns+` FROM notification_deliveries
+		 WHERE tenant_id = $1 AND rule_id = $2
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $3`,
+		tenantID, ruleID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store.ListDeliveries: %w", err)
+	}
+	defer rows.Close()
+	var out []NotificationDelivery
+	for rows.Next() {
+		d, err := scanNotificationDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("store.ListDeliveries: scan: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.ListDeliveries: rows: %w", err)
+	}
+	return out, nil
+}
+
+// NotificationTenants returns the tenants that have an enabled rule.
+func (s *NotificationStore) NotificationTenants(ctx context.Context) ([]string, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT DISTINCT tenant_id FROM notification_rules WHERE enabled ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("store.NotificationTenants: %w", err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("store.NotificationTenants: scan: %w", err)
+		}
+		out = append(out, tenantID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.NotificationTenants: rows: %w", err)
+	}
+	return out, nil
+}
+
+// DispatchNotifications advances the tenant's notification cursor. fn
+// receives the cursor and returns the new one with the deliveries to queue;
+// both are committed together. When another process holds the tenant's
+// cursor the call returns without calling fn.
+func (s *NotificationStore) DispatchNotifications(ctx context.Context, tenantID string, fn func(after int64) (int64, []NotificationDelivery, error)) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("store.DispatchNotifications: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var after int64
+	err = tx.QueryRow(ctx,
+		`SELECT stream_seq FROM notification_cursors WHERE tenant_id = $1 FOR UPDATE SKIP LOCKED`,
+		tenantID,
+	).Scan(&after)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("store.DispatchNotifications: cursor: %w", err)
+	}
+
+	next, deliveries, err := fn(after)
+	if err != nil {
+		return err
+	}
+	for _, d := range deliveries {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO notification_deliveries (id, tenant_id, rule_id, event_key, entry_id, signal, target, url, body)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 ON CONFLICT (rule_id, event_key) DO NOTHING`,
+			ulid.Make().String(), tenantID, d.RuleID, d.EventKey, d.EntryID, d.Signal, d.Target, d.URL, d.Body,
+		); err != nil {
+			return fmt.Errorf("store.DispatchNotifications: queue: %w", err)
+		}
+	}
+	if next != after {
+		if _, err := tx.Exec(ctx,
+			`UPDATE notification_cursors SET stream_seq = $2, updated_at = now() WHERE tenant_id = $1`,
+			tenantID, next,
+		); err != nil {
+			return fmt.Errorf("store.DispatchNotifications: advance: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("store.DispatchNotifications: commit: %w", err)
+	}
+	return nil
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due and
+// holds them for lease, so concurrent senders skip them until the attempt
+// is recorded or the lease runs out.
+func (s *NotificationStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]NotificationDelivery, error) {
+	rows, err := s.pool.Query(ctx,
+		`UPDATE notification_deliveries
+		 SET next_attempt_at = now() + make_interval(secs => $2)
+		 WHERE id IN (
+		   SELECT id FROM notification_deliveries
+		   WHERE status = 'pending' AND next_attempt_at <= now()
+		   ORDER BY next_attempt_at
+		   LIMIT $1
+		   FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+notificationDeliveryColumns,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store.ClaimDeliveries: %w", err)
+	}
+	defer rows.Close()
+	var out []NotificationDelivery
+	for rows.Next() {
+		d, err := scanNotificationDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("store.ClaimDeliveries: scan: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.ClaimDeliveries: rows: %w", err)
+	}
+	return out, nil
+}
+
+// RecordDelivery logs an attempt on a claimed delivery.
+func (s *NotificationStore) RecordDelivery(ctx context.Context, deliveryID string, a DeliveryAttempt) error {
+	status := DeliveryFailed
+	switch {
+	case a.Delivered:
+		status = DeliveryDelivered
+	case a.RetryAt != nil:
+		status = DeliveryPending
+	}
+	var code *int
+	if a.StatusCode != 0 {
+		code = &a.StatusCode
+	}
+	if _, err := s.pool.Exec(ctx,
+		`UPDATE notification_deliveries
+		 SET status = $2,
+		     attempts = attempts + 1,
+		     last_status_code = $3,
+		     last_error = $4,
+		     next_attempt_at = COALESCE($5, next_attempt_at),
+		     delivered_at = CASE WHEN $2 = 'delivered' THEN now() ELSE delivered_at END
+		 WHERE id = $1`,
+		deliveryID, status, code, a.Error, a.RetryAt,
+	); err != nil {
+		return fmt.Errorf("store.RecordDelivery: %w", err)
+	}
+	return nil
+}
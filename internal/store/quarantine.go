@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// HashState reports where an entry hash is known for a tenant.
+type HashState int
+
+const (
+	// HashUnknown means no stored or quarantined entry has the hash.
+	HashUnknown HashState = iota
+	// HashStored means an accepted entry has the hash.
+	HashStored
+	// HashQuarantined means only a quarantined entry has the hash.
+	HashQuarantined
+)
+
+// QuarantinedEntry is an entry that failed ingestion verification. Raw holds
+// the request bytes unchanged so the entry can be re-verified later.
+type QuarantinedEntry struct {
+	TenantID   string
+	Digest     string
+	EntryID    string
+	Hash       string
+	ReasonCode string
+	Reason     string
+	Raw        json.RawMessage
+	ReceivedAt time.Time
+}
+
+// LookupHash reports whether hash belongs to an accepted or a quarantined
+// entry of the tenant.
+func (es *EntryStore) LookupHash(ctx context.Context, tenantID, hash string) (HashState, error) {
+	var stored, quarantined bool
+	err := es.pool.QueryRow(ctx,
+		`SELECT
+		   EXISTS (SELECT 1 FROM evidence_entries WHERE tenant_id = $1 AND hash = $2),
+		   EXISTS (SELECT 1 FROM quarantined_entries WHERE tenant_id = $1 AND hash = $2)`,
+		tenantID, hash,
+	).Scan(&stored, &quarantined)
+	if err != nil {
+		return HashUnknown, fmt.Errorf("store.LookupHash: %w", err)
+	}
+	switch {
+	case stored:
+		return HashStored, nil
+	case quarantined:
+		return HashQuarantined, nil
+	default:
+		return HashUnknown, nil
+	}
+}
+
+// Quarantine keeps an entry that failed verification out of the evidence
+// table. Quarantining the same bytes again is a no-op.
+func (es *EntryStore) Quarantine(ctx context.Context, tenantID string, q QuarantinedEntry) error {
+	digest := q.Digest
+	if digest == "" {
+		digest = RawDigest(q.Raw)
+	}
+	if _, err := es.pool.Exec(ctx,
+		`INSERT INTO quarantined_entries
+		 (tenant_id, digest, entry_id, hash, reason_code, reason, raw)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)
+		 ON CONFLICT (tenant_id, digest) DO NOTHING`,
+		tenantID, digest, q.EntryID, q.Hash, q.ReasonCode, q.Reason, []byte(q.Raw),
+	); err != nil {
+		return fmt.Errorf("store.Quarantine: %w", err)
+	}
+	return nil
+}
+
+// ListQuarantined returns the tenant's quarantined entries, newest first.
+func (es *EntryStore) ListQuarantined(ctx context.Context, tenantID string, limit int) ([]QuarantinedEntry, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	rows, err := es.pool.Query(ctx,
+		`SELECT tenant_id, digest, entry_id, hash, reason_code, reason, raw, received_at
+		 FROM quarantined_entries
+		 WHERE tenant_id = $1
+		 ORDER BY received_at DESC, digest
+		 LIMIT $2`,
+		tenantID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store.ListQuarantined: query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []QuarantinedEntry
+	for rows.Next() {
+		var q QuarantinedEntry
+		var raw []byte
+		if err := rows.Scan(&q.TenantID, &q.Digest, &q.EntryID, &q.Hash, &q.ReasonCode, &q.Reason, &raw, &q.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("store.ListQuarantined: scan: %w", err)
+		}
+		q.Raw = raw
+		out = append(out, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.ListQuarantined: rows: %w", err)
+	}
+	return out, nil
+}
+
+// RawDigest returns the sha256 digest identifying raw request bytes.
+func RawDigest(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Signing key sources recorded in signing_keys.source.
+const (
+	SigningKeySourceAPI         = "api"
+	SigningKeySourceKeyRotation = "key_rotation"
+)
+
+// SigningKeyRecord is an Ed25519 public key a tenant registered to sign the
+// entries it forwards.
+type SigningKeyRecord struct {
+	TenantID  string
+	KeyID     string
+	PublicKey ed25519.PublicKey
+	Label     string
+	Source    string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// SigningKeyStore manages per-tenant entry signing keys backed by PostgreSQL.
+type SigningKeyStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewSigningKeyStore creates a SigningKeyStore with the given connection pool.
+func NewSigningKeyStore(pool *pgxpool.Pool) *SigningKeyStore {
+	return &SigningKeyStore{pool: pool}
+}
+
+// AddKey registers pub for the tenant. Registering a key that is already
+// known is a no-op and returns the existing record.
+func (s *SigningKeyStore) AddKey(ctx context.Context, tenantID string, pub ed25519.PublicKey, label, source string) (SigningKeyRecord, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return SigningKeyRecord{}, fmt.Errorf("store.AddKey: public key is not Ed25519")
+	}
+	if _, err := s.pool.Exec(ctx,
+		`INSERT INTO tenants (id) VALUES ($1)
+		 ON CONFLICT (id) DO NOTHING`,
+		tenantID,
+	); err != nil {
+		return SigningKeyRecord{}, fmt.Errorf("store.AddKey: ensure tenant: %w", err)
+	}
+
+	keyID := evidence.KeyID(pub)
+	if _, err := s.pool.Exec(ctx,
+		`INSERT INTO signing_keys (tenant_id, key_id, public_key, label, source)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (tenant_id, key_id) DO NOTHING`,
+		tenantID, keyID, []byte(pub), label, source,
+	); err != nil {
+		return SigningKeyRecord{}, fmt.Errorf("store.AddKey: insert: %w", err)
+	}
+
+	var rec SigningKeyRecord
+	var raw []byte
+	err := s.pool.QueryRow(ctx,
+		`SELECT tenant_id, key_id, public_key, label, source, created_at, revoked_at
+		 FROM signing_keys WHERE tenant_id = $1 AND key_id = $2`,
+		tenantID, keyID,
+	).Scan(&rec.TenantID, &rec.KeyID, &raw, &rec.Label, &rec.Source, &rec.CreatedAt, &rec.RevokedAt)
+	if err != nil {
+		return SigningKeyRecord{}, fmt.Errorf("store.AddKey: select: %w", err)
+	}
+	rec.PublicKey = ed25519.PublicKey(raw)
+	return rec, nil
+}
+
+// ListKeys returns every signing key registered for the tenant, including
+// revoked ones, oldest first.
+func (s *SigningKeyStore) ListKeys(ctx context.Context, tenantID string) ([]SigningKeyRecord, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT tenant_id, key_id, public_key, label, source, created_at, revoked_at
+		 FROM signing_keys
+		 WHERE tenant_id = $1
+		 ORDER BY created_at, key_id`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store.ListKeys: query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []SigningKeyRecord
+	for rows.Next() {
+		var rec SigningKeyRecord
+		var raw []byte
+		if err := rows.Scan(&rec.TenantID, &rec.KeyID, &raw, &rec.Label, &rec.Source, &rec.CreatedAt, &rec.RevokedAt); err != nil {
+			return nil, fmt.Errorf("store.ListKeys: scan: %w", err)
+		}
+		rec.PublicKey = ed25519.PublicKey(raw)
+		out = append(out, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.ListKeys: rows: %w", err)
+	}
+	return out, nil
+}
+
+// Keyring returns the tenant's active (non-revoked) signing keys.
+func (s *SigningKeyStore) Keyring(ctx context.Context, tenantID string) (*evidence.Keyring, error) {
+	recs, err := s.ListKeys(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("store.Keyring: %w", err)
+	}
+	keyring := evidence.NewKeyring()
+	for _, rec := range recs {
+		if rec.RevokedAt == nil && len(rec.PublicKey) == ed25519.PublicKeySize {
+			keyring.Add(rec.PublicKey)
+		}
+	}
+	return keyring, nil
+}
+
+// RevokeKey stops trusting a signing key for new entries.
+func (s *SigningKeyStore) RevokeKey(ctx context.Context, tenantID, keyID string) error {
+	tag, err := s.pool.Exec(ctx,
+		`UPDATE signing_keys SET revoked_at = now()
+		 WHERE tenant_id = $1 AND key_id = $2 AND revoked_at IS NULL`,
+		tenantID, keyID,
+	)
+	if err != nil {
+		return fmt.Errorf("store.RevokeKey: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("store.RevokeKey: %w", ErrNotFound)
+	}
+	return nil
+}
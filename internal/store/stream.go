@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// EntryNotifyChannel is the Postgres NOTIFY channel SaveRaw signals on. The
+// payload is the tenant ID.
+const EntryNotifyChannel = "evidra_entries"
+
+// StreamFilter narrows EntriesSince. Empty fields match everything.
+type StreamFilter struct {
+	SessionID string
+	ActorID   string
+	Types     []string
+}
+
+// StreamEntry is a stored entry with its position in the tenant's stream.
+type StreamEntry struct {
+	Seq   int64
+	Entry StoredEntry
+}
+
+// StreamHead returns the tenant's latest stream position, or 0 when the
+// tenant has no entries.
+func (es *EntryStore) StreamHead(ctx context.Context, tenantID string) (int64, error) {
+	var head int64
+	if err := es.pool.QueryRow(ctx,
+		`SELECT COALESCE(MAX(stream_seq), 0) FROM evidence_entries WHERE tenant_id = $1`,
+		tenantID,
+	).Scan(&head); err != nil {
+		return 0, fmt.Errorf("store.StreamHead: %w", err)
+	}
+	return head, nil
+}
+
+// EntriesSince returns up to limit entries after stream position after, in
+// stream order.
+func (es *EntryStore) EntriesSince(ctx context.Context, tenantID string, after int64, f StreamFilter, limit int) ([]StreamEntry, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	where := []string{"tenant_id = $1", "stream_seq > $2"}
+	args := []interface{}{tenantID, after}
+	if f.SessionID != "" {
+		args = append(args, f.SessionID)
+		where = append(where, fmt.Sprintf("session_id = $%d", len(args)))
+	}
+	if f.ActorID != "" {
+		args = append(args, f.ActorID)
+		where = append(where, fmt.Sprintf("payload->'actor'->>'id' = $%d", len(args)))
+	}
+	if len(f.Types) > 0 {
+		args = append(args, f.Types)
+		where = append(where, fmt.Sprintf("entry_type = ANY($%d)", len(args)))
+	}
+	args = append(args, limit)
+
+	rows, err := es.pool.Query(ctx, fmt.Sprintf(
+		`SELECT stream_seq, id, tenant_id, entry_type, session_id, operation_id,
+		        previous_hash, hash, signature, intent_digest, artifact_digest,
+		        payload, scope_dimensions, created_at
+		 FROM evidence_entries
+		 WHERE %s
+		 ORDER BY stream_seq
+		 LIMIT $%d`,
+		strings.Join(where, " AND "), len(args),
+	), args...)
+	if err != nil {
+		return nil, fmt.Errorf("store.EntriesSince: %w", err)
+	}
+	defer rows.Close()
+
+	var out []StreamEntry
+	for rows.Next() {
+		var se StreamEntry
+		e := &se.Entry
+		if err := rows.Scan(&se.Seq, &e.ID, &e.TenantID, &e.EntryType, &e.SessionID, &e.OperationID,
+			&e.PreviousHash, &e.Hash, &e.Signature, &e.IntentDigest, &e.ArtifactDigest,
+			&e.Payload, &e.ScopeDimensions, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("store.EntriesSince: scan: %w", err)
+		}
+		out = append(out, se)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.EntriesSince: rows: %w", err)
+	}
+	return out, nil
+}
+
+// SessionHistory returns up to limit of the session's most recent entries
+// before stream position before, oldest first.
+func (es *EntryStore) SessionHistory(ctx context.Context, tenantID, sessionID string, before int64, limit int) ([]evidence.EvidenceEntry, error) {
+	rows, err := es.pool.Query(ctx,
+		`SELECT id, payload FROM (
+		   SELECT id, payload, stream_seq
+		   FROM evidence_entries
+		   WHERE tenant_id = $1 AND session_id = $2 AND stream_seq < $3
+		   ORDER BY stream_seq DESC
+		   LIMIT $4
+		 ) recent
+		 ORDER BY stream_seq`,
+		tenantID, sessionID, before, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("store.SessionHistory: %w", err)
+	}
+	defer rows.Close()
+
+	var out []evidence.EvidenceEntry
+	for rows.Next() {
+		var id string
+		var payload json.RawMessage
+		if err := rows.Scan(&id, &payload); err != nil {
+			return nil, fmt.Errorf("store.SessionHistory: scan: %w", err)
+		}
+		var entry evidence.EvidenceEntry
+		if err := json.Unmarshal(payload, &entry); err != nil {
+			return nil, fmt.Errorf("store.SessionHistory: entry %s: %w", id, err)
+		}
+		out = append(out, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store.SessionHistory: rows: %w", err)
+	}
+	return out, nil
+}
+
+// ListenEntries calls fn with the tenant ID of every entry SaveRaw commits,
+// until ctx is done or the connection fails. It takes one connection out of
+// the pool and closes it on return rather than handing back a listening
+// session.
+func (es *EntryStore) ListenEntries(ctx context.Context, fn func(tenantID string)) error {
+	pooled, err := es.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("store.ListenEntries: acquire: %w", err)
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+EntryNotifyChannel); err != nil {
+		return fmt.Errorf("store.ListenEntries: listen: %w", err)
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("store.ListenEntries: %w", err)
+		}
+		fn(n.Payload)
+	}
+}
@@ -0,0 +1,121 @@
+// Package stream fans out "new evidence stored" notifications to live
+// subscribers and derives the signal events each new entry fires.
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ListenFunc blocks delivering the tenant ID of each stored entry to fn
+// until ctx is done or the underlying connection fails.
+type ListenFunc func(ctx context.Context, fn func(tenantID string)) error
+
+// Hub delivers wake-ups to the subscribers of a tenant. A wake-up carries no
+// data: subscribers re-read the store from their own cursor, so coalesced or
+// missed notifications lose nothing.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[string]map[chan struct{}]struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// NewHub creates an empty Hub.
+func NewHub() *Hub {
+	return &Hub{
+		subs: map[string]map[chan struct{}]struct{}{},
+		done: make(chan struct{}),
+	}
+}
+
+// Subscribe registers for the tenant's wake-ups. The returned channel has a
+// one-slot buffer, so a slow subscriber sees one pending wake-up rather than
+// a backlog. Call cancel to unsubscribe.
+func (h *Hub) Subscribe(tenantID string) (wake <-chan struct{}, cancel func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subs[tenantID] == nil {
+		h.subs[tenantID] = map[chan struct{}]struct{}{}
+	}
+	h.subs[tenantID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[tenantID], ch)
+			if len(h.subs[tenantID]) == 0 {
+				delete(h.subs, tenantID)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// Publish wakes every subscriber of the tenant.
+func (h *Hub) Publish(tenantID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[tenantID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Done is closed when the hub shuts down; subscribers should return.
+func (h *Hub) Done() <-chan struct{} { return h.done }
+
+// Close tells subscribers to return. It is safe to call more than once.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+}
+
+// Run feeds the hub from listen until ctx is done, reconnecting with capped
+// backoff. After each reconnect every subscriber is woken, since entries may
+// have been stored while no listener was attached.
+func (h *Hub) Run(ctx context.Context, listen ListenFunc) {
+	backoff := time.Second
+	for {
+		h.wakeAll()
+		start := time.Now()
+		err := listen(ctx, h.Publish)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > time.Minute {
+			backoff = time.Second
+		}
+		slog.Warn("evidence stream listener stopped; reconnecting", "error", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (h *Hub) wakeAll() {
+	h.mu.Lock()
+	tenants := make([]string, 0, len(h.subs))
+	for tenantID := range h.subs {
+		tenants = append(tenants, tenantID)
+	}
+	h.mu.Unlock()
+	for _, tenantID := range tenants {
+		h.Publish(tenantID)
+	}
+}
@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"sort"
+	"time"
+
+	"samebits.com/evidra/internal/pipeline"
+	"samebits.com/evidra/internal/signal"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// SignalEvent is a signal that fired when an entry was stored. EntryID is
+// the entry the detector flagged, which may be an earlier entry of the
+// session (a retry loop flags every attempt); TriggerID is the new entry.
+type SignalEvent struct {
+	Signal    string    `json:"signal"`
+	EntryID   string    `json:"entry_id"`
+	TriggerID string    `json:"trigger_entry_id"`
+	SessionID string    `json:"session_id,omitempty"`
+	Timestamp time.Time `json:"ts"`
+}
+
+// NewSignals runs the signal detectors over the session history with and
+// without entry and returns the detections entry added.
+func NewSignals(history []evidence.EvidenceEntry, entry evidence.EvidenceEntry) ([]SignalEvent, error) {
+	before, err := detections(history)
+	if err != nil {
+		return nil, err
+	}
+	after, err := detections(append(history[:len(history):len(history)], entry))
+	if err != nil {
+		return nil, err
+	}
+
+	var events []SignalEvent
+	for key := range after {
+		if before[key] {
+			continue
+		}
+		events = append(events, SignalEvent{
+			Signal:    key.signal,
+			EntryID:   key.entryID,
+			TriggerID: entry.EntryID,
+			SessionID: entry.SessionID,
+			Timestamp: entry.Timestamp,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Signal != events[j].Signal {
+			return events[i].Signal < events[j].Signal
+		}
+		return events[i].EntryID < events[j].EntryID
+	})
+	return events, nil
+}
+
+type detection struct {
+	signal  string
+	entryID string
+}
+
+func detections(entries []evidence.EvidenceEntry) (map[detection]bool, error) {
+	signalEntries, err := pipeline.EvidenceToSignalEntries(entries)
+	if err != nil {
+		return nil, err
+	}
+	out := map[detection]bool{}
+	for _, result := range signal.AllSignals(signalEntries, signal.DefaultTTL) {
+		for _, id := range result.EventIDs {
+			out[detection{result.Name, id}] = true
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,137 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+func TestHub_SubscribePublish(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	wake, cancel := h.Subscribe("t1")
+	other, cancelOther := h.Subscribe("t2")
+	defer cancelOther()
+
+	// Wake-ups coalesce into the one-slot buffer.
+	h.Publish("t1")
+	h.Publish("t1")
+	select {
+	case <-wake:
+	default:
+		t.Fatal("subscriber was not woken")
+	}
+	select {
+	case <-wake:
+		t.Fatal("wake-ups were not coalesced")
+	case <-other:
+		t.Fatal("another tenant's subscriber was woken")
+	default:
+	}
+
+	cancel()
+	cancel()
+	h.Publish("t1")
+	select {
+	case <-wake:
+		t.Fatal("cancelled subscriber was woken")
+	default:
+	}
+
+	h.Close()
+	h.Close()
+	select {
+	case <-h.Done():
+	default:
+		t.Fatal("Done not closed after Close")
+	}
+}
+
+func TestHub_RunWakesSubscribersAfterReconnect(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	wake, cancel := h.Subscribe("t1")
+	defer cancel()
+
+	ctx, stop := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	listen := func(ctx context.Context, fn func(string)) error {
+		if calls.Add(1) == 1 {
+			return errors.New("connection reset")
+		}
+		fn("t1")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx, listen)
+		close(done)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for calls.Load() < 2 {
+		select {
+		case <-wake:
+		case <-deadline:
+			t.Fatal("listener was not restarted")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	stop()
+	<-done
+}
+
+func TestNewSignals_ReportsOnlyNewDetections(t *testing.T) {
+	t.Parallel()
+	now := time.Now().UTC()
+	prescribe := evidence.EvidenceEntry{
+		EntryID:   "p1",
+		Type:      evidence.EntryTypePrescribe,
+		SessionID: "s1",
+		Actor:     evidence.Actor{ID: "agent-1"},
+		Timestamp: now,
+		Payload:   mustJSON(t, evidence.PrescriptionPayload{CanonicalAction: json.RawMessage(`{"tool":"kubectl","operation":"apply","operation_class":"mutate","scope_class":"staging"}`)}),
+	}
+	exitCode := 0
+	report := func(id string) evidence.EvidenceEntry {
+		return evidence.EvidenceEntry{
+			EntryID:   id,
+			Type:      evidence.EntryTypeReport,
+			SessionID: "s1",
+			Actor:     evidence.Actor{ID: "agent-1"},
+			Timestamp: now.Add(time.Second),
+			Payload:   mustJSON(t, evidence.ReportPayload{PrescriptionID: "p1", ExitCode: &exitCode, Verdict: evidence.VerdictSuccess}),
+		}
+	}
+
+	events, err := NewSignals([]evidence.EvidenceEntry{prescribe}, report("r1"))
+	if err != nil {
+		t.Fatalf("NewSignals: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("first report fired %+v, want nothing", events)
+	}
+
+	events, err = NewSignals([]evidence.EvidenceEntry{prescribe, report("r1")}, report("r2"))
+	if err != nil {
+		t.Fatalf("NewSignals: %v", err)
+	}
+	if len(events) != 1 || events[0].Signal != "protocol_violation" || events[0].EntryID != "r2" || events[0].TriggerID != "r2" || events[0].SessionID != "s1" {
+		t.Fatalf("duplicate report events = %+v", events)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return b
+}
@@ -0,0 +1,153 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"samebits.com/evidra/internal/config"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+type otlpTraceHTTPExporter struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewOTLPTraceHTTP creates an exporter that pushes one span per operation
+// via OTLP/HTTP protobuf. Spans are queued and sent in batches in the
+// background; Flush sends the queued spans and returns the export error.
+func NewOTLPTraceHTTP(cfg config.TracingConfig) (SpanExporter, error) {
+	endpoint := strings.TrimSpace(cfg.OTLPEndpoint)
+	if endpoint == "" {
+		return nil, fmt.Errorf("otlp traces endpoint is required")
+	}
+	if cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("otlp traces timeout must be > 0")
+	}
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = "evidra"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithTimeout(cfg.Timeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", serviceName),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create otel resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+	)
+
+	return &otlpTraceHTTPExporter{
+		provider: provider,
+		tracer:   provider.Tracer("samebits.com/evidra"),
+	}, nil
+}
+
+func (e *otlpTraceHTTPExporter) ExportOperation(ctx context.Context, span OperationSpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return fmt.Errorf("trace exporter is closed")
+	}
+
+	if span.TraceParent != "" {
+		ctx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": span.TraceParent})
+	}
+	_, s := e.tracer.Start(ctx, spanName(span),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithTimestamp(span.Start),
+		trace.WithAttributes(spanAttributes(span)...),
+	)
+	switch evidence.Verdict(span.Verdict) {
+	case evidence.VerdictFailure, evidence.VerdictError:
+		s.SetStatus(codes.Error, span.Verdict)
+	case evidence.VerdictSuccess:
+		s.SetStatus(codes.Ok, "")
+	}
+	s.End(trace.WithTimestamp(span.End))
+	return nil
+}
+
+func (e *otlpTraceHTTPExporter) Flush(ctx context.Context) error {
+	if err := e.provider.ForceFlush(ctx); err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+	return nil
+}
+
+func (e *otlpTraceHTTPExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Shutdown sends the queued spans and then shuts the OTLP exporter down.
+	if err := e.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutdown tracer provider: %w", err)
+	}
+	return nil
+}
+
+func spanName(span OperationSpan) string {
+	name := strings.TrimSpace(span.Tool + " " + span.Operation)
+	if name == "" {
+		return "evidra.operation"
+	}
+	return name
+}
+
+func spanAttributes(span OperationSpan) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("evidra.session_id", span.SessionID),
+		attribute.String("evidra.trace_id", span.TraceID),
+		attribute.String("evidra.prescription_id", span.PrescriptionID),
+		attribute.String("evidra.report_id", span.ReportID),
+		attribute.String("evidra.tool", span.Tool),
+		attribute.String("evidra.operation", span.Operation),
+		attribute.String("evidra.operation_class", span.OperationClass),
+		attribute.String("evidra.scope_class", span.ScopeClass),
+		attribute.String("evidra.effective_risk", span.EffectiveRisk),
+		attribute.String("evidra.verdict", span.Verdict),
+		attribute.StringSlice("evidra.signals", span.Signals),
+	}
+	if span.ExitCode != nil {
+		attrs = append(attrs, attribute.Int("evidra.exit_code", *span.ExitCode))
+	}
+	return attrs
+}
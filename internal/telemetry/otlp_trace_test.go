@@ -0,0 +1,237 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"samebits.com/evidra/internal/config"
+	"samebits.com/evidra/internal/lifecycle"
+	"samebits.com/evidra/internal/testutil"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// newTraceReceiver stands in for an OTLP/HTTP collector and returns the
+// spans it received.
+func newTraceReceiver(t *testing.T, status int) (*httptest.Server, func() []*tracepb.Span) {
+	t.Helper()
+	var mu sync.Mutex
+	var spans []*tracepb.Span
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() { _ = r.Body.Close() }()
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("path=%q want /v1/traces", r.URL.Path)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(data, &req); err != nil {
+			t.Errorf("unmarshal protobuf: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []*tracepb.Span {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*tracepb.Span(nil), spans...)
+	}
+}
+
+func spanAttr(span *tracepb.Span, key string) string {
+	for _, kv := range span.Attributes {
+		if kv.Key != key {
+			continue
+		}
+		if arr := kv.Value.GetArrayValue(); arr != nil {
+			var out string
+			for i, v := range arr.Values {
+				if i > 0 {
+					out += ","
+				}
+				out += v.GetStringValue()
+			}
+			return out
+		}
+		if v, ok := kv.Value.Value.(*commonpb.AnyValue_IntValue); ok {
+			return strconv.FormatInt(v.IntValue, 10)
+		}
+		return kv.Value.GetStringValue()
+	}
+	return ""
+}
+
+func TestOTLPTraceHTTP_ExportsOperationSpan(t *testing.T) {
+	t.Parallel()
+
+	evidenceDir := filepath.Join(t.TempDir(), "evidence")
+	svc := lifecycle.NewService(lifecycle.Options{EvidencePath: evidenceDir, Signer: testutil.TestSigner(t)})
+	ctx := context.Background()
+	rx, err := svc.Prescribe(ctx, lifecycle.PrescribeInput{
+		Actor:       evidence.Actor{Type: "agent", ID: "agent-1", Provenance: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n  namespace: prod\n"),
+		Environment: "production",
+	})
+	if err != nil {
+		t.Fatalf("Prescribe: %v", err)
+	}
+	exitCode := 1
+	rep, err := svc.Report(ctx, lifecycle.ReportInput{
+		PrescriptionID: rx.PrescriptionID,
+		Verdict:        evidence.VerdictFailure,
+		ExitCode:       &exitCode,
+		Actor:          rx.Actor,
+		SessionID:      rx.SessionID,
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	op, err := OperationSpanAtPath(evidenceDir, rep.Entry, map[string]int{"retry_loop": 1, "new_scope": 0, "blast_radius": 2})
+	if err != nil {
+		t.Fatalf("OperationSpanAtPath: %v", err)
+	}
+	op.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	server, received := newTraceReceiver(t, http.StatusOK)
+	exporter, err := NewSpanExporter(config.TracingConfig{
+		OTLPEndpoint: server.URL + "/v1/traces",
+		Timeout:      5 * time.Second,
+		ServiceName:  "evidra-test",
+	})
+	if err != nil {
+		t.Fatalf("NewSpanExporter: %v", err)
+	}
+	if err := exporter.ExportOperation(ctx, op); err != nil {
+		t.Fatalf("ExportOperation: %v", err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	spans := received()
+	if len(spans) != 1 {
+		t.Fatalf("spans=%d want 1", len(spans))
+	}
+	span := spans[0]
+	if got := hex.EncodeToString(span.TraceId); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace_id=%s, want the traceparent's", got)
+	}
+	if got := hex.EncodeToString(span.ParentSpanId); got != "00f067aa0ba902b7" {
+		t.Fatalf("parent_span_id=%s, want the traceparent's", got)
+	}
+	if span.Name != "kubectl apply" {
+		t.Fatalf("name=%q", span.Name)
+	}
+	if span.StartTimeUnixNano != uint64(rx.Entry.Timestamp.UnixNano()) || span.EndTimeUnixNano != uint64(rep.Entry.Timestamp.UnixNano()) {
+		t.Fatalf("span [%d,%d] want prescribe..report", span.StartTimeUnixNano, span.EndTimeUnixNano)
+	}
+	if span.Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR {
+		t.Fatalf("status=%v want error", span.Status)
+	}
+	for key, want := range map[string]string{
+		"evidra.tool":            "kubectl",
+		"evidra.operation_class": rx.OperationClass,
+		"evidra.scope_class":     rx.ScopeClass,
+		"evidra.effective_risk":  rx.EffectiveRisk,
+		"evidra.verdict":         "failure",
+		"evidra.exit_code":       "1",
+		"evidra.signals":         "blast_radius,retry_loop",
+		"evidra.prescription_id": rx.PrescriptionID,
+	} {
+		if got := spanAttr(span, key); got != want {
+			t.Fatalf("%s=%q want %q", key, got, want)
+		}
+	}
+}
+
+func TestOTLPTraceHTTP_ReturnsExportError(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newTraceReceiver(t, http.StatusBadRequest)
+	exporter, err := NewOTLPTraceHTTP(config.TracingConfig{OTLPEndpoint: server.URL + "/v1/traces", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewOTLPTraceHTTP: %v", err)
+	}
+	defer func() { _ = exporter.Close() }()
+
+	now := time.Now()
+	if err := exporter.ExportOperation(context.Background(), OperationSpan{Start: now, End: now, Tool: "helm"}); err != nil {
+		t.Fatalf("ExportOperation: %v", err)
+	}
+	if err := exporter.Flush(context.Background()); err == nil {
+		t.Fatal("expected export error from rejecting receiver")
+	}
+}
+
+func TestOTLPTraceHTTP_QueuesUntilFlush(t *testing.T) {
+	t.Parallel()
+
+	server, received := newTraceReceiver(t, http.StatusOK)
+	exporter, err := NewOTLPTraceHTTP(config.TracingConfig{OTLPEndpoint: server.URL + "/v1/traces", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewOTLPTraceHTTP: %v", err)
+	}
+	defer func() { _ = exporter.Close() }()
+
+	now := time.Now()
+	for _, tool := range []string{"kubectl", "helm"} {
+		if err := exporter.ExportOperation(context.Background(), OperationSpan{Start: now, End: now, Tool: tool}); err != nil {
+			t.Fatalf("ExportOperation: %v", err)
+		}
+	}
+	if got := len(received()); got != 0 {
+		t.Fatalf("spans=%d before flush, want 0 (queued)", got)
+	}
+	if err := exporter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := len(received()); got != 2 {
+		t.Fatalf("spans=%d after flush, want 2", got)
+	}
+}
+
+func TestNewSpanExporterDisabledIsNoop(t *testing.T) {
+	t.Parallel()
+
+	exporter, err := NewSpanExporter(config.TracingConfig{})
+	if err != nil {
+		t.Fatalf("NewSpanExporter: %v", err)
+	}
+	if err := exporter.ExportOperation(context.Background(), OperationSpan{}); err != nil {
+		t.Fatalf("ExportOperation: %v", err)
+	}
+	if err := exporter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/internal/config"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// OperationSpan is one prescribed operation, from its prescription to its
+// report.
+type OperationSpan struct {
+	// TraceParent is the caller's W3C traceparent; empty starts a new trace.
+	TraceParent    string
+	Start          time.Time
+	End            time.Time
+	SessionID      string
+	TraceID        string
+	PrescriptionID string
+	ReportID       string
+	Tool           string
+	Operation      string
+	OperationClass string
+	ScopeClass     string
+	EffectiveRisk  string
+	Verdict        string
+	ExitCode       *int
+	// Signals lists the signals detected in the session at report time.
+	Signals []string
+}
+
+// SpanExporter exports operation spans. ExportOperation queues the span
+// without waiting for the network; Flush sends what is queued and Close
+// flushes before releasing the exporter.
+type SpanExporter interface {
+	ExportOperation(ctx context.Context, span OperationSpan) error
+	Flush(ctx context.Context) error
+	Close() error
+}
+
+// NewSpanExporter returns an OTLP/HTTP exporter, or a no-op one when
+// tracing is disabled.
+func NewSpanExporter(cfg config.TracingConfig) (SpanExporter, error) {
+	if !cfg.Enabled() {
+		return noopSpanExporter{}, nil
+	}
+	return NewOTLPTraceHTTP(cfg)
+}
+
+type noopSpanExporter struct{}
+
+func (noopSpanExporter) ExportOperation(context.Context, OperationSpan) error { return nil }
+
+func (noopSpanExporter) Flush(context.Context) error { return nil }
+
+func (noopSpanExporter) Close() error { return nil }
+
+// OperationSpanFromEntries builds the span of the operation a report
+// closes. signals is the session's signal summary at report time.
+func OperationSpanFromEntries(prescription, report evidence.EvidenceEntry, signals map[string]int) (OperationSpan, error) {
+	var p evidence.PrescriptionPayload
+	if err := json.Unmarshal(prescription.Payload, &p); err != nil {
+		return OperationSpan{}, fmt.Errorf("decode prescription %s: %w", prescription.EntryID, err)
+	}
+	var r evidence.ReportPayload
+	if err := json.Unmarshal(report.Payload, &r); err != nil {
+		return OperationSpan{}, fmt.Errorf("decode report %s: %w", report.EntryID, err)
+	}
+	var action canon.CanonicalAction
+	if len(p.CanonicalAction) > 0 {
+		if err := json.Unmarshal(p.CanonicalAction, &action); err != nil {
+			return OperationSpan{}, fmt.Errorf("decode canonical action of %s: %w", prescription.EntryID, err)
+		}
+	}
+
+	var names []string
+	for name, count := range signals {
+		if count > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return OperationSpan{
+		Start:          prescription.Timestamp,
+		End:            report.Timestamp,
+		SessionID:      report.SessionID,
+		TraceID:        prescription.TraceID,
+		PrescriptionID: prescription.EntryID,
+		ReportID:       report.EntryID,
+		Tool:           action.Tool,
+		Operation:      action.Operation,
+		OperationClass: action.OperationClass,
+		ScopeClass:     action.ScopeClass,
+		EffectiveRisk:  p.EffectiveRisk,
+		Verdict:        string(r.Verdict),
+		ExitCode:       r.ExitCode,
+		Signals:        names,
+	}, nil
+}
+
+// OperationSpanAtPath is OperationSpanFromEntries with the prescription
+// read from the evidence chain at evidencePath.
+func OperationSpanAtPath(evidencePath string, report evidence.EvidenceEntry, signals map[string]int) (OperationSpan, error) {
+	var r evidence.ReportPayload
+	if err := json.Unmarshal(report.Payload, &r); err != nil {
+		return OperationSpan{}, fmt.Errorf("decode report %s: %w", report.EntryID, err)
+	}
+	prescription, ok, err := evidence.FindEntryByID(evidencePath, r.PrescriptionID)
+	if err != nil {
+		return OperationSpan{}, fmt.Errorf("read prescription %s: %w", r.PrescriptionID, err)
+	}
+	if !ok {
+		return OperationSpan{}, fmt.Errorf("prescription %s not found", r.PrescriptionID)
+	}
+	return OperationSpanFromEntries(prescription, report, signals)
+}
@@ -0,0 +1,197 @@
+// Package transcript captures the output of wrapped commands for evidence:
+// size-capped, with secrets redacted before the transcript is stored.
+package transcript
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// DefaultMaxBytes caps a stored transcript.
+const DefaultMaxBytes = 1 << 20
+
+// redactedMarker replaces every redacted value.
+const redactedMarker = "[REDACTED]"
+
+// redactWindow is how much recent output a Recorder holds back before
+// redacting it, so a secret split across writes is still matched whole.
+const redactWindow = 8 << 10
+
+// maxPending bounds the held-back output when an unterminated private key
+// block would otherwise keep all of it pending.
+const maxPending = 64 << 10
+
+// Recorder is an io.Writer that keeps the first and last halves of at most
+// maxBytes of output, so both the command's preamble and its final errors
+// survive truncation. Output is redacted as it streams in, before it is
+// truncated, so a secret cut by the truncation is never stored in part.
+type Recorder struct {
+	mu         sync.Mutex
+	maxBytes   int
+	pending    []byte // not yet redacted
+	head       []byte
+	tail       []byte // ring buffer once full
+	tailPos    int
+	total      int64
+	redacted   int64 // redacted bytes offered to head and tail
+	redactions int
+}
+
+// NewRecorder returns a Recorder capped at maxBytes; non-positive values use
+// DefaultMaxBytes.
+func NewRecorder(maxBytes int) *Recorder {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	return &Recorder{maxBytes: maxBytes}
+}
+
+// Write records p; it never fails so it can sit in an io.MultiWriter.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total += int64(len(p))
+	r.pending = append(r.pending, p...)
+	if len(r.pending) <= 2*redactWindow {
+		return len(p), nil
+	}
+	cut := safeCut(r.pending, len(r.pending)-redactWindow)
+	if cut == 0 {
+		if len(r.pending) <= maxPending {
+			return len(p), nil
+		}
+		cut = len(r.pending) - redactWindow
+	}
+	r.redactAndKeep(r.pending[:cut])
+	r.pending = append(r.pending[:0], r.pending[cut:]...)
+	return len(p), nil
+}
+
+// redactAndKeep redacts data and adds it to the head, then the tail.
+func (r *Recorder) redactAndKeep(data []byte) {
+	p, n := Redact(data)
+	r.redactions += n
+	r.redacted += int64(len(p))
+
+	headCap := r.maxBytes / 2
+	if room := headCap - len(r.head); room > 0 {
+		n := min(room, len(p))
+		r.head = append(r.head, p[:n]...)
+		p = p[n:]
+	}
+	tailCap := r.maxBytes - headCap
+	for len(p) > 0 && tailCap > 0 {
+		if len(r.tail) < tailCap {
+			n := min(tailCap-len(r.tail), len(p))
+			r.tail = append(r.tail, p[:n]...)
+			p = p[n:]
+			continue
+		}
+		n := copy(r.tail[r.tailPos:], p)
+		r.tailPos = (r.tailPos + n) % tailCap
+		p = p[n:]
+	}
+}
+
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY-----`)
+)
+
+// safeCut moves cut back to the start of its line, then before any
+// redaction match it would split and any private key block not yet
+// terminated in data. Zero means nothing can be cut yet.
+func safeCut(data []byte, cut int) int {
+	if i := bytes.LastIndexByte(data[:cut], '\n'); i >= 0 {
+		cut = i + 1
+	}
+	for moved := true; moved; {
+		moved = false
+		for _, rule := range redactionRules {
+			for _, loc := range rule.re.FindAllIndex(data, -1) {
+				if loc[0] < cut && cut < loc[1] {
+					cut, moved = loc[0], true
+				}
+			}
+		}
+	}
+	if begins := privateKeyBegin.FindAllIndex(data[:cut], -1); len(begins) > 0 {
+		last := begins[len(begins)-1][0]
+		if !privateKeyEnd.Match(data[last:]) {
+			cut = last
+		}
+	}
+	return cut
+}
+
+// Captured is a finished transcript.
+type Captured struct {
+	Data []byte
+	// TotalBytes is everything the command wrote, before truncation.
+	TotalBytes int64
+	Truncated  bool
+	Redactions int
+}
+
+// Finish returns the redacted transcript. Dropped output is replaced by a
+// marker line between the kept head and tail.
+func (r *Recorder) Finish() Captured {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactAndKeep(r.pending)
+	r.pending = nil
+
+	kept := int64(len(r.head) + len(r.tail))
+	out := make([]byte, 0, kept+64)
+	out = append(out, r.head...)
+	truncated := r.redacted > kept
+	if truncated {
+		out = append(out, fmt.Sprintf("\n... [%d bytes truncated] ...\n", r.redacted-kept)...)
+	}
+	out = append(out, r.tail[r.tailPos:]...)
+	out = append(out, r.tail[:r.tailPos]...)
+	return Captured{Data: out, TotalBytes: r.total, Truncated: truncated, Redactions: r.redactions}
+}
+
+// redactionRule replaces the secret part of each match. Rules with a
+// prefix group keep it, so "password=hunter2" becomes "password=[REDACTED]".
+type redactionRule struct {
+	re        *regexp.Regexp
+	keepGroup bool
+}
+
+var redactionRules = []redactionRule{
+	{re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{re: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{re: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\b`)},
+	{re: regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/=-]{8,}`), keepGroup: true},
+	{re: regexp.MustCompile(`(?i)(\b[a-z0-9_.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key)[a-z0-9_.-]*["']?\s*[:=]\s*["']?)[^\s"',;]{4,}`), keepGroup: true},
+	{re: regexp.MustCompile(`(?i)(\b[a-z][a-z0-9+.-]*://[^\s:/@]+:)[^\s@/]+(@)`), keepGroup: true},
+}
+
+// Redact replaces credentials and key material in data and returns the
+// number of replacements. Redaction is best-effort pattern matching.
+func Redact(data []byte) ([]byte, int) {
+	count := 0
+	for _, rule := range redactionRules {
+		data = rule.re.ReplaceAllFunc(data, func(match []byte) []byte {
+			count++
+			if !rule.keepGroup {
+				return []byte(redactedMarker)
+			}
+			groups := rule.re.FindSubmatch(match)
+			out := append([]byte{}, groups[1]...)
+			out = append(out, redactedMarker...)
+			if len(groups) > 2 {
+				out = append(out, groups[2]...)
+			}
+			return out
+		})
+	}
+	return data, count
+}
@@ -0,0 +1,98 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// verifyK8s runs one `kubectl get -o yaml` per prescribed resource.
+func verifyK8s(ctx context.Context, run CommandRunner, req Request) (evidence.VerificationPayload, error) {
+	binary := "kubectl"
+	if req.Tool == "oc" {
+		binary = "oc"
+	}
+	expected := make(map[canon.ResourceID]map[string]interface{})
+	if len(req.Artifact) > 0 {
+		objects, err := canon.K8sObjects(req.Artifact)
+		if err != nil {
+			return evidence.VerificationPayload{}, fmt.Errorf("verify: parse artifact: %w", err)
+		}
+		for _, o := range objects {
+			expected[o.Identity] = o.Object
+		}
+	}
+
+	destroy := req.OperationClass == "destroy"
+	payload := evidence.VerificationPayload{Method: MethodKubectlGet}
+	for _, res := range req.Resources {
+		if res.Name == "" || res.Kind == "" {
+			continue
+		}
+		out, err := run(ctx, binary, k8sGetArgs(res, req.KubeContext)...)
+		if err != nil {
+			return evidence.VerificationPayload{}, fmt.Errorf("verify: read back %s: %w", describeK8s(res), err)
+		}
+		live, err := canon.K8sObjects(out)
+		if err != nil {
+			return evidence.VerificationPayload{}, fmt.Errorf("verify: parse live %s: %w", describeK8s(res), err)
+		}
+
+		vr := evidence.VerifiedResource{
+			Resource: describeK8s(res),
+			Status:   presenceOutcome(destroy, len(live) > 0),
+		}
+		if want, ok := expected[res]; ok && !destroy && len(live) > 0 {
+			if diffs := subsetDiff("", comparableK8s(res, want), live[0].Object, nil); len(diffs) > 0 {
+				vr.Status = evidence.ResourceDrifted
+				vr.Differences = capDifferences(diffs)
+			}
+		}
+		payload.Resources = append(payload.Resources, vr)
+	}
+	return payload, nil
+}
+
+func k8sGetArgs(res canon.ResourceID, kubeContext string) []string {
+	target := res.Kind
+	if group, _, ok := strings.Cut(res.APIVersion, "/"); ok && group != "" {
+		target += "." + group
+	}
+	args := []string{"get", target + "/" + res.Name, "-o", "yaml", "--ignore-not-found"}
+	if res.Namespace != "" {
+		args = append(args, "--namespace", res.Namespace)
+	}
+	if kubeContext != "" {
+		args = append(args, "--context", kubeContext)
+	}
+	return args
+}
+
+// comparableK8s drops fields the API server rewrites on write, so they are
+// not reported as drift.
+func comparableK8s(res canon.ResourceID, obj map[string]interface{}) map[string]interface{} {
+	if res.Kind != "secret" {
+		return obj
+	}
+	// stringData is folded into data on write.
+	out := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		if k != "stringData" {
+			out[k] = v
+		}
+	}
+	if _, hasStringData := obj["stringData"]; hasStringData {
+		delete(out, "data")
+	}
+	return out
+}
+
+func describeK8s(res canon.ResourceID) string {
+	if res.Namespace == "" {
+		return res.Kind + "/" + res.Name
+	}
+	return res.Kind + "/" + res.Namespace + "/" + res.Name
+}
@@ -0,0 +1,159 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// verifyTerraform reads `terraform show -json` state. With the prescribed
+// plan, each change is checked by address against its known after-values;
+// without it, resources are matched by type and name and only presence is
+// checked.
+func verifyTerraform(ctx context.Context, run CommandRunner, req Request) (evidence.VerificationPayload, error) {
+	args := []string{"show", "-json"}
+	if req.Dir != "" {
+		args = append([]string{"-chdir=" + req.Dir}, args...)
+	}
+	out, err := run(ctx, "terraform", args...)
+	if err != nil {
+		return evidence.VerificationPayload{}, fmt.Errorf("verify: read back terraform state: %w", err)
+	}
+	live, err := terraformStateResources(out)
+	if err != nil {
+		return evidence.VerificationPayload{}, err
+	}
+
+	payload := evidence.VerificationPayload{Method: MethodTerraformShow}
+	if len(req.Artifact) == 0 {
+		byTypeName := make(map[string]bool, len(live))
+		for _, r := range live {
+			byTypeName[r.Type+"."+r.Name] = true
+		}
+		for _, res := range req.Resources {
+			destroy, skip := terraformActionKind(strings.Split(res.Actions, ","))
+			if skip {
+				continue
+			}
+			payload.Resources = append(payload.Resources, evidence.VerifiedResource{
+				Resource: res.Type + "." + res.Name,
+				Status:   presenceOutcome(destroy, byTypeName[res.Type+"."+res.Name]),
+			})
+		}
+		return payload, nil
+	}
+
+	var plan tfjson.Plan
+	if err := json.Unmarshal(req.Artifact, &plan); err != nil {
+		return evidence.VerificationPayload{}, fmt.Errorf("verify: parse plan: %w", err)
+	}
+	changes := append([]*tfjson.ResourceChange(nil), plan.ResourceChanges...)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Address < changes[j].Address })
+	for _, rc := range changes {
+		if rc.Change == nil || rc.Mode == tfjson.DataResourceMode {
+			continue
+		}
+		actions := make([]string, len(rc.Change.Actions))
+		for i, a := range rc.Change.Actions {
+			actions[i] = string(a)
+		}
+		destroy, skip := terraformActionKind(actions)
+		if skip {
+			continue
+		}
+		state, present := live[rc.Address]
+		vr := evidence.VerifiedResource{Resource: rc.Address, Status: presenceOutcome(destroy, present)}
+		if !destroy && present {
+			want := pruneUnknown(rc.Change.After, rc.Change.AfterUnknown)
+			if diffs := subsetDiff("", want, state.AttributeValues, nil); len(diffs) > 0 {
+				vr.Status = evidence.ResourceDrifted
+				vr.Differences = capDifferences(diffs)
+			}
+		}
+		payload.Resources = append(payload.Resources, vr)
+	}
+	return payload, nil
+}
+
+// terraformActionKind classifies plan actions: delete-only changes must be
+// absent afterwards, no-op and read changes are not verified.
+func terraformActionKind(actions []string) (destroy, skip bool) {
+	if len(actions) == 0 {
+		return false, true
+	}
+	if len(actions) == 1 {
+		switch actions[0] {
+		case "delete":
+			return true, false
+		case "no-op", "read", "":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// terraformStateResources indexes managed resources by address across
+// all modules.
+func terraformStateResources(raw []byte) (map[string]*tfjson.StateResource, error) {
+	var state tfjson.State
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, fmt.Errorf("verify: parse terraform state: %w", err)
+	}
+	out := make(map[string]*tfjson.StateResource)
+	if state.Values == nil {
+		return out, nil
+	}
+	var walk func(m *tfjson.StateModule)
+	walk = func(m *tfjson.StateModule) {
+		if m == nil {
+			return
+		}
+		for _, r := range m.Resources {
+			if r.Mode == tfjson.ManagedResourceMode {
+				out[r.Address] = r
+			}
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	walk(state.Values.RootModule)
+	return out, nil
+}
+
+// pruneUnknown removes values the plan could not know (after_unknown), so
+// computed attributes are not reported as drift.
+func pruneUnknown(after, unknown interface{}) interface{} {
+	if unknown == true {
+		return nil
+	}
+	switch a := after.(type) {
+	case map[string]interface{}:
+		u, _ := unknown.(map[string]interface{})
+		out := make(map[string]interface{}, len(a))
+		for k, v := range a {
+			if pruned := pruneUnknown(v, u[k]); pruned != nil {
+				out[k] = pruned
+			}
+		}
+		return out
+	case []interface{}:
+		u, _ := unknown.([]interface{})
+		out := make([]interface{}, len(a))
+		for i, v := range a {
+			var ui interface{}
+			if i < len(u) {
+				ui = u[i]
+			}
+			out[i] = pruneUnknown(v, ui)
+		}
+		return out
+	}
+	return after
+}
@@ -0,0 +1,199 @@
+// Package verify reads back live state after an operation and compares it
+// with what the prescription intended, closing the gap that artifact
+// digests alone leave: both digests are self-reported.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// SubSignal is the artifact_drift sub-signal for mismatched verifications.
+const SubSignal = "state_drift"
+
+// Read-back methods recorded in VerificationPayload.Method.
+const (
+	MethodKubectlGet    = "kubectl_get"
+	MethodTerraformShow = "terraform_show"
+)
+
+// maxDifferences caps the field paths listed per resource.
+const maxDifferences = 10
+
+// CommandRunner runs a read-back command and returns its stdout.
+type CommandRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// ExecRunner runs read-back commands as subprocesses.
+func ExecRunner(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, msg)
+		}
+		return nil, fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// Request describes the prescribed operation to verify.
+type Request struct {
+	Tool           string
+	OperationClass string
+	Resources      []canon.ResourceID
+	// Artifact is the prescribed artifact. When set, live fields are
+	// compared against it; otherwise only presence is checked.
+	Artifact []byte
+	// Kubeconfig context passed to kubectl/oc, if any.
+	KubeContext string
+	// Dir is the terraform working directory.
+	Dir string
+}
+
+// Supported reports whether tool has a read-back.
+func Supported(tool string) bool {
+	switch tool {
+	case "kubectl", "oc", "helm", "kustomize", "terraform":
+		return true
+	}
+	return false
+}
+
+// Verify reads back live state and returns the comparison. PrescriptionID
+// and ReportID are left for the caller to fill in. A failed read-back is an
+// error, not a mismatch.
+func Verify(ctx context.Context, run CommandRunner, req Request) (evidence.VerificationPayload, error) {
+	var (
+		payload evidence.VerificationPayload
+		err     error
+	)
+	switch req.Tool {
+	case "kubectl", "oc", "helm", "kustomize":
+		payload, err = verifyK8s(ctx, run, req)
+	case "terraform":
+		payload, err = verifyTerraform(ctx, run, req)
+	default:
+		return evidence.VerificationPayload{}, fmt.Errorf("verify: no read-back for tool %q", req.Tool)
+	}
+	if err != nil {
+		return evidence.VerificationPayload{}, err
+	}
+	payload.FieldsCompared = len(req.Artifact) > 0
+	payload.Status = evidence.VerificationMatched
+	for _, r := range payload.Resources {
+		if r.Status == evidence.ResourceMatched {
+			payload.Matched++
+		} else {
+			payload.Mismatched++
+		}
+	}
+	if payload.Mismatched > 0 {
+		payload.Status = evidence.VerificationMismatched
+	}
+	return payload, nil
+}
+
+// presenceOutcome is the status of a resource whose fields are not compared.
+func presenceOutcome(destroy, present bool) string {
+	switch {
+	case destroy && present:
+		return evidence.ResourceNotDeleted
+	case !destroy && !present:
+		return evidence.ResourceMissing
+	}
+	return evidence.ResourceMatched
+}
+
+// subsetDiff returns the paths under prefix where live does not carry the
+// value expected. Fields only live has, such as server defaults, are fine.
+func subsetDiff(prefix string, expected, live interface{}, out []string) []string {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return append(out, pathOrRoot(prefix))
+		}
+		keys := make([]string, 0, len(exp))
+		for k := range exp {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if exp[k] == nil {
+				continue
+			}
+			out = subsetDiff(joinPath(prefix, k), exp[k], liveMap[k], out)
+		}
+		return out
+	case []interface{}:
+		liveSlice, ok := live.([]interface{})
+		if !ok || len(liveSlice) != len(exp) {
+			return append(out, pathOrRoot(prefix))
+		}
+		for i := range exp {
+			if exp[i] == nil {
+				continue
+			}
+			out = subsetDiff(fmt.Sprintf("%s[%d]", prefix, i), exp[i], liveSlice[i], out)
+		}
+		return out
+	default:
+		if !scalarEqual(expected, live) {
+			return append(out, pathOrRoot(prefix))
+		}
+		return out
+	}
+}
+
+func scalarEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func pathOrRoot(p string) string {
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func capDifferences(diffs []string) []string {
+	if len(diffs) > maxDifferences {
+		return append(diffs[:maxDifferences:maxDifferences], fmt.Sprintf("... %d more", len(diffs)-maxDifferences))
+	}
+	return diffs
+}
@@ -0,0 +1,212 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// fakeRunner answers read-backs from canned output keyed by the joined
+// command line.
+func fakeRunner(outputs map[string]string) CommandRunner {
+	return func(_ context.Context, name string, args ...string) ([]byte, error) {
+		key := name + " " + strings.Join(args, " ")
+		out, ok := outputs[key]
+		if !ok {
+			return nil, errors.New("unexpected command: " + key)
+		}
+		return []byte(out), nil
+	}
+}
+
+const deployArtifact = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: "{}"
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: prod
+data:
+  mode: blue
+`
+
+func TestVerifyK8s(t *testing.T) {
+	t.Parallel()
+
+	liveDeploy := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  uid: 1234
+  resourceVersion: "99"
+spec:
+  replicas: 2
+  progressDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.2
+        imagePullPolicy: IfNotPresent
+status:
+  readyReplicas: 2
+`
+	run := fakeRunner(map[string]string{
+		"kubectl get deployment.apps/web -o yaml --ignore-not-found --namespace prod":  liveDeploy,
+		"kubectl get configmap/web-config -o yaml --ignore-not-found --namespace prod": "",
+	})
+	result := canon.Canonicalize("kubectl", "apply", "production", []byte(deployArtifact))
+	req := Request{
+		Tool:           "kubectl",
+		OperationClass: result.CanonicalAction.OperationClass,
+		Resources:      result.CanonicalAction.ResourceIdentity,
+		Artifact:       []byte(deployArtifact),
+	}
+
+	got, err := Verify(context.Background(), run, req)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got.Status != evidence.VerificationMismatched || got.Matched != 0 || got.Mismatched != 2 || !got.FieldsCompared {
+		t.Fatalf("payload = %+v", got)
+	}
+	byResource := map[string]evidence.VerifiedResource{}
+	for _, r := range got.Resources {
+		byResource[r.Resource] = r
+	}
+	if r := byResource["deployment/prod/web"]; r.Status != evidence.ResourceDrifted || len(r.Differences) != 1 || r.Differences[0] != "spec.replicas" {
+		t.Fatalf("deployment = %+v", r)
+	}
+	if r := byResource["configmap/prod/web-config"]; r.Status != evidence.ResourceMissing {
+		t.Fatalf("configmap = %+v", r)
+	}
+
+	// Without the artifact only presence is checked.
+	req.Artifact = nil
+	got, err = Verify(context.Background(), run, req)
+	if err != nil || got.Matched != 1 || got.Mismatched != 1 || got.FieldsCompared {
+		t.Fatalf("presence-only payload = %+v, err = %v", got, err)
+	}
+}
+
+func TestVerifyK8sDelete(t *testing.T) {
+	t.Parallel()
+
+	run := fakeRunner(map[string]string{
+		"kubectl get configmap/web-config -o yaml --ignore-not-found --namespace prod --context prod-admin": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\n  namespace: prod\n",
+	})
+	got, err := Verify(context.Background(), run, Request{
+		Tool:           "kubectl",
+		OperationClass: "destroy",
+		Resources:      []canon.ResourceID{{APIVersion: "v1", Kind: "configmap", Namespace: "prod", Name: "web-config"}},
+		KubeContext:    "prod-admin",
+	})
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got.Status != evidence.VerificationMismatched || got.Resources[0].Status != evidence.ResourceNotDeleted {
+		t.Fatalf("payload = %+v", got)
+	}
+}
+
+func TestVerifyReadBackFailure(t *testing.T) {
+	t.Parallel()
+
+	_, err := Verify(context.Background(), fakeRunner(nil), Request{
+		Tool:      "kubectl",
+		Resources: []canon.ResourceID{{APIVersion: "v1", Kind: "configmap", Name: "x"}},
+	})
+	if err == nil {
+		t.Fatal("read-back failure not surfaced")
+	}
+	if _, err := Verify(context.Background(), fakeRunner(nil), Request{Tool: "docker"}); err == nil {
+		t.Fatal("unsupported tool accepted")
+	}
+}
+
+const tfPlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "aws_s3_bucket.logs", "mode": "managed", "type": "aws_s3_bucket", "name": "logs",
+     "change": {"actions": ["create"], "after": {"bucket": "logs", "force_destroy": false, "arn": null}, "after_unknown": {"arn": true}}},
+    {"address": "module.net.aws_vpc.main", "mode": "managed", "type": "aws_vpc", "name": "main",
+     "change": {"actions": ["update"], "after": {"cidr_block": "10.0.0.0/16", "tags": {"env": "prod"}}, "after_unknown": {}}},
+    {"address": "aws_iam_user.old", "mode": "managed", "type": "aws_iam_user", "name": "old",
+     "change": {"actions": ["delete"], "before": {"name": "old"}, "after": null}},
+    {"address": "aws_iam_role.keep", "mode": "managed", "type": "aws_iam_role", "name": "keep",
+     "change": {"actions": ["no-op"]}}
+  ]
+}`
+
+const tfState = `{
+  "format_version": "1.0",
+  "values": {"root_module": {
+    "resources": [
+      {"address": "aws_s3_bucket.logs", "mode": "managed", "type": "aws_s3_bucket", "name": "logs",
+       "values": {"bucket": "logs", "force_destroy": false, "arn": "arn:aws:s3:::logs"}},
+      {"address": "data.aws_caller_identity.me", "mode": "data", "type": "aws_caller_identity", "name": "me", "values": {}}
+    ],
+    "child_modules": [{"address": "module.net", "resources": [
+      {"address": "module.net.aws_vpc.main", "mode": "managed", "type": "aws_vpc", "name": "main",
+       "values": {"cidr_block": "10.0.0.0/16", "tags": {"env": "staging"}}}
+    ]}]
+  }}
+}`
+
+func TestVerifyTerraform(t *testing.T) {
+	t.Parallel()
+
+	run := fakeRunner(map[string]string{"terraform -chdir=infra show -json": tfState})
+	result := canon.Canonicalize("terraform", "apply", "production", []byte(tfPlan))
+	req := Request{
+		Tool:      "terraform",
+		Resources: result.CanonicalAction.ResourceIdentity,
+		Artifact:  []byte(tfPlan),
+		Dir:       "infra",
+	}
+
+	got, err := Verify(context.Background(), run, req)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	want := map[string]string{
+		"aws_iam_user.old":        evidence.ResourceMatched,
+		"aws_s3_bucket.logs":      evidence.ResourceMatched,
+		"module.net.aws_vpc.main": evidence.ResourceDrifted,
+	}
+	if len(got.Resources) != len(want) {
+		t.Fatalf("resources = %+v", got.Resources)
+	}
+	for _, r := range got.Resources {
+		if want[r.Resource] != r.Status {
+			t.Errorf("%s status = %s, want %s (%v)", r.Resource, r.Status, want[r.Resource], r.Differences)
+		}
+		if r.Status == evidence.ResourceDrifted && (len(r.Differences) != 1 || r.Differences[0] != "tags.env") {
+			t.Errorf("differences = %v", r.Differences)
+		}
+	}
+
+	req.Artifact = nil
+	got, err = Verify(context.Background(), run, req)
+	if err != nil || got.Status != evidence.VerificationMatched || got.Matched != 3 {
+		t.Fatalf("presence-only payload = %+v, err = %v", got, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -59,8 +60,22 @@ func (c *Client) Forward(ctx context.Context, entry json.RawMessage) (ForwardRes
 
 // BatchResponse is the response from POST /v1/evidence/batch.
 type BatchResponse struct {
-	Accepted int      `json:"accepted"`
-	Errors   []string `json:"errors,omitempty"`
+	Accepted    int      `json:"accepted"`
+	Rejected    int      `json:"rejected,omitempty"`
+	Quarantined int      `json:"quarantined,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+	// Results has one item per entry when the server verifies ingestion.
+	Results []EntryResult `json:"results,omitempty"`
+}
+
+// EntryResult is the server's verdict on one entry of a batch.
+type EntryResult struct {
+	Index   int    `json:"index"`
+	EntryID string `json:"entry_id,omitempty"`
+	// Status is accepted, rejected, or quarantined.
+	Status  string `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 // BenchmarkRunRequest is the payload for POST /v1/benchmark/run.
@@ -111,6 +126,123 @@ func (c *Client) SubmitBenchmarkRun(ctx context.Context, req BenchmarkRunRequest
 	return resp, nil
 }
 
+// Chain is one writer's evidence chain as tracked by the server.
+type Chain struct {
+	ChainID string `json:"chain_id"`
+	// Writer is "key:<key_id>" for signed chains, otherwise
+	// "actor:<type>/<id>[/<instance_id>]".
+	Writer              string    `json:"writer"`
+	KeyID               string    `json:"key_id,omitempty"`
+	GenesisHash         string    `json:"genesis_hash"`
+	GenesisPreviousHash string    `json:"genesis_previous_hash,omitempty"`
+	Detached            bool      `json:"detached"`
+	HeadHash            string    `json:"head_hash"`
+	HeadEntryID         string    `json:"head_entry_id"`
+	Length              int64     `json:"length"`
+	FirstSeen           time.Time `json:"first_seen"`
+	LastSeen            time.Time `json:"last_seen"`
+	// Validity is unverified, valid, or invalid.
+	Validity        string     `json:"validity"`
+	ValidationError string     `json:"validation_error,omitempty"`
+	ValidatedAt     *time.Time `json:"validated_at,omitempty"`
+}
+
+// ChainReport is the outcome of POST /v1/evidence/chains/{id}/validate.
+type ChainReport struct {
+	ChainID       string    `json:"chain_id"`
+	Valid         bool      `json:"valid"`
+	Length        int64     `json:"length"`
+	Checked       int       `json:"checked"`
+	Unverifiable  int       `json:"unverifiable,omitempty"`
+	Unsigned      int       `json:"unsigned,omitempty"`
+	Detached      bool      `json:"detached,omitempty"`
+	FailedSeq     int64     `json:"failed_seq,omitempty"`
+	FailedEntryID string    `json:"failed_entry_id,omitempty"`
+	Code          string    `json:"code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	ValidatedAt   time.Time `json:"validated_at"`
+}
+
+// Chains lists the tenant's evidence chains via GET /v1/evidence/chains.
+func (c *Client) Chains(ctx context.Context) ([]Chain, error) {
+	var resp struct {
+		Chains []Chain `json:"chains"`
+	}
+	if err := c.get(ctx, "/v1/evidence/chains", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Chains, nil
+}
+
+// ValidateChain re-verifies one stored chain server-side.
+func (c *Client) ValidateChain(ctx context.Context, chainID string) (ChainReport, error) {
+	var resp ChainReport
+	if err := c.post(ctx, "/v1/evidence/chains/"+url.PathEscape(chainID)+"/validate", nil, &resp); err != nil {
+		return ChainReport{}, err
+	}
+	return resp, nil
+}
+
+// PendingApproval is a prescription awaiting an approval decision, with
+// the signed prescription entry the decision refers to.
+type PendingApproval struct {
+	PrescriptionID   string          `json:"prescription_id"`
+	SessionID        string          `json:"session_id"`
+	ActorID          string          `json:"actor_id"`
+	Tool             string          `json:"tool"`
+	Operation        string          `json:"operation"`
+	EffectiveRisk    string          `json:"effective_risk,omitempty"`
+	ApprovalRequired bool            `json:"approval_required,omitempty"`
+	PrescribedAt     time.Time       `json:"prescribed_at"`
+	AgeMs            int64           `json:"age_ms"`
+	TTLMs            int64           `json:"ttl_ms"`
+	Expired          bool            `json:"expired"`
+	Entry            json.RawMessage `json:"entry"`
+}
+
+// PendingApprovals lists prescriptions awaiting approval via
+// GET /v1/approvals. Empty sessionID lists every session.
+func (c *Client) PendingApprovals(ctx context.Context, sessionID string) ([]PendingApproval, error) {
+	path := "/v1/approvals"
+	if sessionID != "" {
+		path += "?session_id=" + url.QueryEscape(sessionID)
+	}
+	var resp struct {
+		Approvals []PendingApproval `json:"approvals"`
+	}
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Approvals, nil
+}
+
+// ApprovalDecision returns the decision recorded on a prescription via
+// GET /v1/approvals/{prescription_id}, or "" while it awaits one.
+// sessionID narrows the lookup to the prescription's session.
+func (c *Client) ApprovalDecision(ctx context.Context, sessionID, prescriptionID string) (string, error) {
+	path := "/v1/approvals/" + url.PathEscape(prescriptionID)
+	if sessionID != "" {
+		path += "?session_id=" + url.QueryEscape(sessionID)
+	}
+	var resp struct {
+		Decision string `json:"decision"`
+	}
+	if err := c.get(ctx, path, &resp); err != nil {
+		return "", err
+	}
+	return resp.Decision, nil
+}
+
+// SubmitApproval stores a signed approval entry via
+// POST /v1/approvals/{prescription_id}.
+func (c *Client) SubmitApproval(ctx context.Context, prescriptionID string, entry json.RawMessage) (ForwardResponse, error) {
+	var resp ForwardResponse
+	if err := c.post(ctx, "/v1/approvals/"+url.PathEscape(prescriptionID), entry, &resp); err != nil {
+		return ForwardResponse{}, err
+	}
+	return resp, nil
+}
+
 // Ping checks API reachability via GET /healthz.
 func (c *Client) Ping(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.URL+"/healthz", nil)
@@ -129,13 +261,27 @@ func (c *Client) Ping(ctx context.Context) error {
 }
 
 func (c *Client) post(ctx context.Context, path string, body json.RawMessage, out interface{}) error {
+	return c.do(ctx, http.MethodPost, path, body, out)
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body json.RawMessage, out interface{}) error {
 	reqID := newRequestID()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL+path, bytes.NewReader(body))
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.config.URL+path, reqBody)
 	if err != nil {
 		return fmt.Errorf("%w: create request: %v", ErrUnreachable, err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
 	req.Header.Set("X-Request-ID", reqID)
 
@@ -177,6 +323,8 @@ func classifyHTTPStatus(resp *http.Response) error {
 		return ErrUnauthorized
 	case resp.StatusCode == 403:
 		return ErrForbidden
+	case resp.StatusCode == 404:
+		return ErrNotFound
 	case resp.StatusCode == 422:
 		return ErrInvalidInput
 	case resp.StatusCode == 429:
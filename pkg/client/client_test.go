@@ -162,3 +162,69 @@ func TestSubmitBenchmarkRun_ServerError(t *testing.T) {
 		t.Fatalf("error = %v", err)
 	}
 }
+
+func TestChainsAndValidateChain(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/evidence/chains":
+			_, _ = w.Write([]byte(`{"chains":[{"chain_id":"c1","writer":"key:k1","length":2,"validity":"unverified"}]}`))
+		case "POST /v1/evidence/chains/c1/validate":
+			_, _ = w.Write([]byte(`{"chain_id":"c1","valid":true,"length":2,"checked":2}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := New(Config{URL: ts.URL, APIKey: "test-key"})
+	chains, err := c.Chains(context.Background())
+	if err != nil {
+		t.Fatalf("Chains: %v", err)
+	}
+	if len(chains) != 1 || chains[0].Writer != "key:k1" {
+		t.Fatalf("chains = %+v", chains)
+	}
+	report, err := c.ValidateChain(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("ValidateChain: %v", err)
+	}
+	if !report.Valid || report.Checked != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if _, err := c.ValidateChain(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ValidateChain(missing) err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPendingApprovalsAndSubmitApproval(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/approvals":
+			if r.URL.Query().Get("session_id") != "sess-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"approvals":[{"prescription_id":"p1","session_id":"sess-1","effective_risk":"critical","entry":{"entry_id":"p1"}}],"total":1}`))
+		case "POST /v1/approvals/p1":
+			_, _ = w.Write([]byte(`{"receipt_id":"r1","status":"accepted"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := New(Config{URL: ts.URL, APIKey: "test-key"})
+	pending, err := c.PendingApprovals(context.Background(), "sess-1")
+	if err != nil {
+		t.Fatalf("PendingApprovals: %v", err)
+	}
+	if len(pending) != 1 || pending[0].PrescriptionID != "p1" || string(pending[0].Entry) != `{"entry_id":"p1"}` {
+		t.Fatalf("pending = %+v", pending)
+	}
+	resp, err := c.SubmitApproval(context.Background(), "p1", json.RawMessage(`{"type":"approval"}`))
+	if err != nil || resp.Status != "accepted" {
+		t.Fatalf("SubmitApproval = %+v, %v", resp, err)
+	}
+}
@@ -11,6 +11,7 @@ var (
 	ErrRateLimited  = errors.New("rate_limited")
 	ErrServerError  = errors.New("server_error")
 	ErrInvalidInput = errors.New("invalid_input")
+	ErrNotFound     = errors.New("not_found")
 )
 
 // IsReachabilityError returns true for errors that can trigger fallback-offline.
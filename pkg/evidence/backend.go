@@ -0,0 +1,311 @@
+package evidence
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend is an evidence store selected by a URI-style evidence path such as
+// "sqlite:///var/lib/evidra/evidence.db". Paths without a registered scheme
+// use the JSONL segment store. The package-level *AtPath functions dispatch
+// to the backend, so callers work unchanged with either store.
+type Backend interface {
+	// AppendEntries appends pre-built entries in order, atomically.
+	AppendEntries(entries []EvidenceEntry) error
+	// AppendLinked atomically reads the chain tip and appends the entry
+	// returned by build, which receives the tip's entry count and hash.
+	AppendLinked(build func(count int, lastHash string) (EvidenceEntry, error)) (EvidenceEntry, error)
+	// ForEach calls fn for every entry in chain order over a consistent
+	// snapshot.
+	ForEach(fn func(EvidenceEntry) error) error
+	// FindByID returns the entry with the given entry_id.
+	FindByID(entryID string) (EvidenceEntry, bool, error)
+	// Tip returns the number of entries and the hash of the last one.
+	Tip() (count int, lastHash string, err error)
+	// Query returns entries matching q in chain order.
+	Query(q EntryQuery) ([]EvidenceEntry, error)
+	// Dir is the directory for files kept alongside the store (published
+	// heads, exports).
+	Dir() string
+}
+
+// BackendOpener opens the store named by dsn, the evidence path with its
+// "<scheme>:" prefix (and any leading "//") removed.
+type BackendOpener func(dsn string) (Backend, error)
+
+var (
+	backendMu      sync.Mutex
+	backendOpeners = map[string]BackendOpener{}
+	openBackends   = map[string]Backend{}
+)
+
+// RegisterBackend makes a store backend available under scheme. It is
+// intended to be called from the backend package's init function.
+func RegisterBackend(scheme string, open BackendOpener) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	if _, dup := backendOpeners[scheme]; dup {
+		panic("evidence: RegisterBackend called twice for scheme " + scheme)
+	}
+	backendOpeners[scheme] = open
+}
+
+// backendScheme splits "scheme:dsn" evidence paths. A "scheme:" prefix
+// names a backend only when the scheme is registered or the path has the
+// "scheme://" form, so a relative path with a colon such as "runs:today"
+// stays a JSONL path. It returns ok=false for plain filesystem paths,
+// including Windows drive letters.
+func backendScheme(path string) (scheme, dsn string, ok bool) {
+	scheme, rest, found := strings.Cut(path, ":")
+	if !found || len(scheme) < 2 || scheme[0] < 'a' || scheme[0] > 'z' {
+		return "", "", false
+	}
+	for _, r := range scheme {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '+' && r != '-' && r != '.' {
+			return "", "", false
+		}
+	}
+	if dsn, ok := strings.CutPrefix(rest, "//"); ok {
+		return scheme, dsn, true
+	}
+	backendMu.Lock()
+	_, registered := backendOpeners[scheme]
+	backendMu.Unlock()
+	if !registered {
+		return "", "", false
+	}
+	return scheme, rest, true
+}
+
+// IsBackendPath reports whether path names a registered non-JSONL backend.
+func IsBackendPath(path string) bool {
+	scheme, _, ok := backendScheme(path)
+	if !ok {
+		return false
+	}
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	_, registered := backendOpeners[scheme]
+	return registered
+}
+
+// backendFor returns the backend for path. The boolean is false for the
+// JSONL segment store. Opened backends are cached for the process lifetime.
+func backendFor(path string) (Backend, bool, error) {
+	scheme, dsn, ok := backendScheme(path)
+	if !ok {
+		return nil, false, nil
+	}
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	open, registered := backendOpeners[scheme]
+	if !registered {
+		return nil, true, fmt.Errorf("evidence store scheme %q is not available in this build", scheme)
+	}
+	if b, cached := openBackends[path]; cached {
+		return b, true, nil
+	}
+	b, err := open(dsn)
+	if err != nil {
+		return nil, true, fmt.Errorf("open %s evidence store: %w", scheme, err)
+	}
+	openBackends[path] = b
+	return b, true, nil
+}
+
+// StoreDir returns the directory for files kept alongside the evidence
+// store: the segment directory itself, or the backend's directory.
+func StoreDir(path string) string {
+	if b, ok, err := backendFor(path); ok && err == nil {
+		return b.Dir()
+	}
+	return path
+}
+
+// EntryQuery selects entries by indexed attributes. Zero fields match
+// everything.
+type EntryQuery struct {
+	SessionID      string
+	ActorID        string
+	IntentDigest   string
+	ArtifactDigest string
+	Types          []EntryType
+	// Tool, OperationClass and ScopeClass match a prescription's canonical
+	// action. EffectiveRisks and Verdicts match any of their values.
+	Tool           string
+	OperationClass string
+	ScopeClass     string
+	EffectiveRisks []string
+	Verdicts       []string
+	// ScopeDimensions matches entries carrying every key/value pair.
+	ScopeDimensions map[string]string
+	// Since and Until bound the entry timestamp (inclusive, exclusive).
+	Since time.Time
+	Until time.Time
+	// After resumes the results after the entry with this ID.
+	After string
+	// Limit caps the number of results; 0 means no limit.
+	Limit int
+}
+
+// HasPayloadFilters reports whether q filters on fields inside the entry
+// payload, which backends cannot answer from their indexes.
+func (q EntryQuery) HasPayloadFilters() bool {
+	return q.Tool != "" || q.OperationClass != "" || q.ScopeClass != "" ||
+		len(q.EffectiveRisks) > 0 || len(q.Verdicts) > 0
+}
+
+// Matches reports whether e satisfies q (ignoring After and Limit).
+func (q EntryQuery) Matches(e EvidenceEntry) bool {
+	if q.SessionID != "" && e.SessionID != q.SessionID {
+		return false
+	}
+	if q.ActorID != "" && e.Actor.ID != q.ActorID {
+		return false
+	}
+	if q.IntentDigest != "" && e.IntentDigest != q.IntentDigest {
+		return false
+	}
+	if q.ArtifactDigest != "" && e.ArtifactDigest != q.ArtifactDigest {
+		return false
+	}
+	for k, v := range q.ScopeDimensions {
+		if got, ok := e.ScopeDimensions[k]; !ok || got != v {
+			return false
+		}
+	}
+	if len(q.Types) > 0 {
+		found := false
+		for _, t := range q.Types {
+			if e.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Timestamp.Before(q.Until) {
+		return false
+	}
+	if q.HasPayloadFilters() {
+		return q.matchesPayload(e.Payload)
+	}
+	return true
+}
+
+func (q EntryQuery) matchesPayload(raw json.RawMessage) bool {
+	var p struct {
+		CanonicalAction struct {
+			Tool           string `json:"tool"`
+			OperationClass string `json:"operation_class"`
+			ScopeClass     string `json:"scope_class"`
+		} `json:"canonical_action"`
+		EffectiveRisk string `json:"effective_risk"`
+		Verdict       string `json:"verdict"`
+	}
+	if json.Unmarshal(raw, &p) != nil {
+		return false
+	}
+	ca := p.CanonicalAction
+	return (q.Tool == "" || ca.Tool == q.Tool) &&
+		(q.OperationClass == "" || ca.OperationClass == q.OperationClass) &&
+		(q.ScopeClass == "" || ca.ScopeClass == q.ScopeClass) &&
+		(len(q.EffectiveRisks) == 0 || slices.Contains(q.EffectiveRisks, p.EffectiveRisk)) &&
+		(len(q.Verdicts) == 0 || slices.Contains(q.Verdicts, p.Verdict))
+}
+
+// QueryEntriesAtPath returns the entries matching q in chain order. Backends
+// answer from their indexes; the JSONL store scans.
+func QueryEntriesAtPath(path string, q EntryQuery) ([]EvidenceEntry, error) {
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return nil, err
+		}
+		return b.Query(q)
+	}
+	out := make([]EvidenceEntry, 0)
+	errLimit := fmt.Errorf("query limit reached")
+	resumed := q.After == ""
+	err := ForEachEntryAtPath(path, func(e EvidenceEntry) error {
+		if !resumed {
+			resumed = e.EntryID == q.After
+			return nil
+		}
+		if !q.Matches(e) {
+			return nil
+		}
+		out = append(out, e)
+		if q.Limit > 0 && len(out) >= q.Limit {
+			return errLimit
+		}
+		return nil
+	})
+	if err != nil && err != errLimit {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CopyEntries copies the validated chain at src into the empty store at dst,
+// preserving hashes and signatures. Either side may be a JSONL segment
+// directory or a backend URI, so it serves as both import and export.
+func CopyEntries(dst, src string) (int, error) {
+	entries, err := ReadAllEntriesAtPath(src)
+	if err != nil {
+		return 0, fmt.Errorf("read source: %w", err)
+	}
+	if err := validateChainEntries(entries); err != nil {
+		return 0, fmt.Errorf("validate source: %w", err)
+	}
+	for _, e := range entries {
+		if err := validatePersistedEntry(e); err != nil {
+			return 0, fmt.Errorf("entry %s: %w", e.EntryID, err)
+		}
+	}
+
+	if b, ok, err := backendFor(dst); ok {
+		if err != nil {
+			return 0, err
+		}
+		count, _, err := b.Tip()
+		if err != nil {
+			return 0, err
+		}
+		if count != 0 {
+			return 0, fmt.Errorf("destination store is not empty (%d entries)", count)
+		}
+		if err := b.AppendEntries(entries); err != nil {
+			return 0, err
+		}
+		return len(entries), nil
+	}
+
+	err = storeLock(dst, func() error {
+		manifest, err := loadManifestForAppend(dst)
+		if err != nil {
+			return err
+		}
+		if manifest.RecordsTotal != 0 {
+			return fmt.Errorf("destination store is not empty (%d entries)", manifest.RecordsTotal)
+		}
+		for _, e := range entries {
+			if err := appendEntryToSegment(dst, &manifest, e); err != nil {
+				return err
+			}
+		}
+		return writeManifestAtomic(dst, manifest)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
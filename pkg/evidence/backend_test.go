@@ -0,0 +1,84 @@
+package evidence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// teststore is registered so "scheme:dsn" paths without "//" resolve; it is
+// never opened.
+func init() {
+	RegisterBackend("teststore", func(string) (Backend, error) {
+		return nil, errors.New("teststore is not openable")
+	})
+}
+
+func TestBackendScheme(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		path       string
+		wantScheme string
+		wantDSN    string
+		wantOK     bool
+	}{
+		{path: "sqlite:///var/lib/evidra/evidence.db", wantScheme: "sqlite", wantDSN: "/var/lib/evidra/evidence.db", wantOK: true},
+		{path: "sqlite:evidence.db"},
+		{path: "teststore:evidence.db", wantScheme: "teststore", wantDSN: "evidence.db", wantOK: true},
+		{path: "/home/user/.evidra/evidence"},
+		{path: "relative/evidence"},
+		{path: `C:\evidra\evidence`},
+		{path: "Sqlite:evidence.db"},
+		{path: "nosuchstore:///tmp/x", wantScheme: "nosuchstore", wantDSN: "/tmp/x", wantOK: true},
+		{path: "runs:today"},
+		{path: "runs:/today"},
+		{path: "2024:evidence"},
+	}
+	for _, tt := range tests {
+		scheme, dsn, ok := backendScheme(tt.path)
+		if scheme != tt.wantScheme || dsn != tt.wantDSN || ok != tt.wantOK {
+			t.Errorf("backendScheme(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.path, scheme, dsn, ok, tt.wantScheme, tt.wantDSN, tt.wantOK)
+		}
+	}
+}
+
+func TestBackendFor_UnregisteredSchemeFails(t *testing.T) {
+	t.Parallel()
+	if _, err := ReadAllEntriesAtPath("nosuchstore:///tmp/x"); err == nil {
+		t.Fatal("expected error for unregistered store scheme")
+	}
+	if IsBackendPath("nosuchstore:///tmp/x") {
+		t.Fatal("IsBackendPath reported an unregistered scheme")
+	}
+}
+
+func TestQueryEntriesAtPath_SegmentStoreScans(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := newTestSigner(t)
+	for _, sessionID := range []string{"a", "b", "a"} {
+		if _, err := AppendAtPath(dir, sequencerParams(signer, sessionID)); err != nil {
+			t.Fatalf("AppendAtPath: %v", err)
+		}
+	}
+
+	got, err := QueryEntriesAtPath(dir, EntryQuery{SessionID: "a"})
+	if err != nil {
+		t.Fatalf("QueryEntriesAtPath: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("session a: got %d entries, want 2", len(got))
+	}
+	resumed, err := QueryEntriesAtPath(dir, EntryQuery{SessionID: "a", After: got[0].EntryID})
+	if err != nil || len(resumed) != 1 || resumed[0].EntryID != got[1].EntryID {
+		t.Fatalf("after: got %d entries, %v", len(resumed), err)
+	}
+	got, err = QueryEntriesAtPath(dir, EntryQuery{Limit: 1, Until: time.Now().Add(time.Minute)})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("limit: got %d entries, %v", len(got), err)
+	}
+	if StoreDir(dir) != dir {
+		t.Fatalf("StoreDir(%q) = %q", dir, StoreDir(dir))
+	}
+}
@@ -0,0 +1,90 @@
+package evidence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const blobsDirName = "blobs"
+
+// ErrBlobNotFound is returned when no blob with the requested digest exists.
+var ErrBlobNotFound = errors.New("evidence_blob_not_found")
+
+// BlobDir returns the directory holding content-addressed blobs for the
+// evidence store at path. Legacy single-file stores keep blobs next to the
+// file.
+func BlobDir(path string) string {
+	if !IsBackendPath(path) {
+		if mode, clean, err := detectStoreMode(path); err == nil && mode == "legacy" {
+			return filepath.Join(filepath.Dir(clean), blobsDirName, "sha256")
+		}
+	}
+	return filepath.Join(StoreDir(path), blobsDirName, "sha256")
+}
+
+// WriteBlobAtPath stores data under its sha256 digest alongside the evidence
+// store and returns the digest as sha256:<hex>. Writing the same content
+// twice is a no-op.
+func WriteBlobAtPath(path string, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hexDigest := hex.EncodeToString(sum[:])
+	dir := BlobDir(path)
+	target := filepath.Join(dir, hexDigest)
+	if _, err := os.Stat(target); err == nil {
+		return "sha256:" + hexDigest, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("create blob: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("write blob: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("sync blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("close blob: %w", err)
+	}
+	if err := os.Rename(tmpPath, target); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("publish blob: %w", err)
+	}
+	return "sha256:" + hexDigest, nil
+}
+
+// ReadBlobAtPath returns the blob stored under digest, verifying that its
+// content still hashes to it.
+func ReadBlobAtPath(path, digest string) ([]byte, error) {
+	normalized, err := FormatDigest(digest)
+	if err != nil || normalized == "" {
+		return nil, fmt.Errorf("invalid blob digest %q", digest)
+	}
+	hexDigest := normalized[len("sha256:"):]
+	data, err := os.ReadFile(filepath.Join(BlobDir(path), hexDigest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, normalized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read blob: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != hexDigest {
+		return nil, fmt.Errorf("%w: blob %s does not match its digest", ErrChainInvalid, normalized)
+	}
+	return data, nil
+}
@@ -0,0 +1,67 @@
+package evidence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteBlobAtPath_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "evidence")
+	digest, err := WriteBlobAtPath(dir, []byte("deployment.apps/web configured\n"))
+	if err != nil {
+		t.Fatalf("WriteBlobAtPath: %v", err)
+	}
+	if !strings.HasPrefix(digest, "sha256:") || len(digest) != len("sha256:")+64 {
+		t.Fatalf("digest = %q", digest)
+	}
+	again, err := WriteBlobAtPath(dir, []byte("deployment.apps/web configured\n"))
+	if err != nil || again != digest {
+		t.Fatalf("second write = %q, %v; want %q", again, err, digest)
+	}
+
+	data, err := ReadBlobAtPath(dir, digest)
+	if err != nil || string(data) != "deployment.apps/web configured\n" {
+		t.Fatalf("ReadBlobAtPath = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "blobs", "sha256", digest[len("sha256:"):])); err != nil {
+		t.Fatalf("blob not stored alongside the store: %v", err)
+	}
+}
+
+func TestReadBlobAtPath_DetectsMissingAndTamperedBlobs(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "evidence")
+	digest, err := WriteBlobAtPath(dir, []byte("original"))
+	if err != nil {
+		t.Fatalf("WriteBlobAtPath: %v", err)
+	}
+
+	if _, err := ReadBlobAtPath(dir, "sha256:"+strings.Repeat("0", 64)); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("missing blob err = %v, want ErrBlobNotFound", err)
+	}
+	if _, err := ReadBlobAtPath(dir, "not-a-digest"); err == nil {
+		t.Fatal("invalid digest accepted")
+	}
+
+	if err := os.WriteFile(filepath.Join(BlobDir(dir), digest[len("sha256:"):]), []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	if _, err := ReadBlobAtPath(dir, digest); !errors.Is(err, ErrChainInvalid) {
+		t.Fatalf("tampered blob err = %v, want ErrChainInvalid", err)
+	}
+}
+
+func TestBlobDir_LegacyFileStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if got, want := BlobDir(filepath.Join(dir, "evidence.jsonl")), filepath.Join(dir, "blobs", "sha256"); got != want {
+		t.Fatalf("BlobDir = %q, want %q", got, want)
+	}
+}
@@ -27,6 +27,21 @@ const (
 	EntryTypeSessionEnd EntryType = "session_end"
 	// EntryTypeAnnotation is a human or system annotation on a session.
 	EntryTypeAnnotation EntryType = "annotation"
+	// EntryTypeTreeHead is a signed checkpoint over all preceding entries.
+	EntryTypeTreeHead EntryType = "tree_head"
+	// EntryTypeKeyRotation is signed by the outgoing key and introduces its successor.
+	EntryTypeKeyRotation EntryType = "key_rotation"
+	// EntryTypeVerification is a post-execution read-back of live state
+	// compared against the prescription.
+	EntryTypeVerification EntryType = "verification"
+	// EntryTypePlan prescribes an ordered set of operations up front; each
+	// step's prescription links back to it.
+	EntryTypePlan EntryType = "plan"
+	// EntryTypeCancel withdraws an open prescription before it is reported.
+	EntryTypeCancel EntryType = "cancel"
+	// EntryTypeApproval is a human sign-off on, or rejection of, a
+	// prescription that requires approval.
+	EntryTypeApproval EntryType = "approval"
 )
 
 // validEntryTypes enumerates all allowed EntryType values.
@@ -40,6 +55,12 @@ var validEntryTypes = map[EntryType]bool{
 	EntryTypeSessionStart: true,
 	EntryTypeSessionEnd:   true,
 	EntryTypeAnnotation:   true,
+	EntryTypeTreeHead:     true,
+	EntryTypeKeyRotation:  true,
+	EntryTypeVerification: true,
+	EntryTypePlan:         true,
+	EntryTypeCancel:       true,
+	EntryTypeApproval:     true,
 }
 
 // Valid reports whether et is a recognised entry type.
@@ -60,12 +81,15 @@ type Actor struct {
 // EvidenceEntry is an append-only event log entry. Every JSONL line in an
 // evidence segment file is one EvidenceEntry.
 type EvidenceEntry struct {
-	EntryID      string    `json:"entry_id"`
-	PreviousHash string    `json:"previous_hash"`
-	Hash         string    `json:"hash"`
-	Signature    string    `json:"signature"`
-	Type         EntryType `json:"type"`
-	TenantID     string    `json:"tenant_id,omitempty"`
+	EntryID      string `json:"entry_id"`
+	PreviousHash string `json:"previous_hash"`
+	Hash         string `json:"hash"`
+	Signature    string `json:"signature"`
+	// KeyID identifies the Ed25519 key that produced Signature (see KeyID()).
+	// Empty on entries written before key identifiers were introduced.
+	KeyID    string    `json:"key_id,omitempty"`
+	Type     EntryType `json:"type"`
+	TenantID string    `json:"tenant_id,omitempty"`
 	// SessionID groups operations from one automation attempt (for example:
 	// one CI pipeline run, one AI agent task, or one operator workflow).
 	// For meaningful signal detection and scorecards, callers should generate
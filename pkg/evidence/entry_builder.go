@@ -21,6 +21,26 @@ type Signer interface {
 	PublicKey() ed25519.PublicKey
 }
 
+// FallibleSigner is implemented by signers whose key lives outside the
+// process (HSM, agent socket, remote service), where signing can fail.
+type FallibleSigner interface {
+	Signer
+	TrySign(payload []byte) ([]byte, error)
+}
+
+// SignPayload signs payload with s, surfacing backend failures when s is a
+// FallibleSigner instead of producing an empty signature.
+func SignPayload(s Signer, payload []byte) ([]byte, error) {
+	if fs, ok := s.(FallibleSigner); ok {
+		return fs.TrySign(payload)
+	}
+	sig := s.Sign(payload)
+	if len(sig) == 0 {
+		return nil, fmt.Errorf("signer returned an empty signature")
+	}
+	return sig, nil
+}
+
 // DefaultTTLMs is the default time-to-live for a prescription in milliseconds (5 minutes).
 const DefaultTTLMs = 300000
 
@@ -77,6 +97,19 @@ type EntryBuildParams struct {
 // It generates a ULID entry_id, timestamps the entry, formats digests with
 // sha256: prefix, and computes the hash chain.
 func BuildEntry(p EntryBuildParams) (EvidenceEntry, error) {
+	entry, err := prepareEntry(p)
+	if err != nil {
+		return EvidenceEntry{}, err
+	}
+	entry.PreviousHash = p.PreviousHash
+	if err := sealEntry(&entry, p.Signer); err != nil {
+		return EvidenceEntry{}, err
+	}
+	return entry, nil
+}
+
+// prepareEntry builds everything except the chain link, hash and signature.
+func prepareEntry(p EntryBuildParams) (EvidenceEntry, error) {
 	if p.Signer == nil {
 		return EvidenceEntry{}, fmt.Errorf("evidence.BuildEntry: Signer is required")
 	}
@@ -107,7 +140,6 @@ func BuildEntry(p EntryBuildParams) (EvidenceEntry, error) {
 		IntentDigest:    intentDigest,
 		ArtifactDigest:  artifactDigest,
 		Payload:         p.Payload,
-		PreviousHash:    p.PreviousHash,
 		ScopeDimensions: p.ScopeDimensions,
 		SpecVersion:     p.SpecVersion,
 		CanonVersion:    p.CanonVersion,
@@ -117,17 +149,33 @@ func BuildEntry(p EntryBuildParams) (EvidenceEntry, error) {
 	if entry.EntryID == "" {
 		entry.EntryID = ulid.Make().String()
 	}
+	entry.KeyID = signerKeyID(p.Signer)
+	return entry, nil
+}
 
-	hash, err := computeEntryHash(entry)
+// sealEntry computes the entry hash over its current fields (including
+// previous_hash) and signs it.
+func sealEntry(entry *EvidenceEntry, signer Signer) error {
+	hash, err := computeEntryHash(*entry)
+	if err != nil {
+		return fmt.Errorf("evidence.BuildEntry: %w", err)
+	}
+	sig, err := SignPayload(signer, []byte(hash))
 	if err != nil {
-		return EvidenceEntry{}, fmt.Errorf("evidence.BuildEntry: %w", err)
+		return fmt.Errorf("evidence.BuildEntry: sign: %w", err)
 	}
 	entry.Hash = hash
-
-	sig := p.Signer.Sign([]byte(hash))
 	entry.Signature = base64.StdEncoding.EncodeToString(sig)
+	return nil
+}
 
-	return entry, nil
+// signerKeyID returns the key_id for s, or "" when s does not expose an
+// Ed25519 public key.
+func signerKeyID(s Signer) string {
+	if pub := s.PublicKey(); len(pub) == ed25519.PublicKeySize {
+		return KeyID(pub)
+	}
+	return ""
 }
 
 // hashableEntry is a projection of EvidenceEntry that excludes Hash and
@@ -153,6 +201,7 @@ type hashableEntry struct {
 	CanonVersion    string            `json:"canonical_version"`
 	AdapterVersion  string            `json:"adapter_version"`
 	ScoringVersion  string            `json:"scoring_version,omitempty"`
+	KeyID           string            `json:"key_id,omitempty"`
 }
 
 // computeEntryHash computes sha256:<hex> over all entry fields except hash and signature.
@@ -178,6 +227,7 @@ func computeEntryHash(e EvidenceEntry) (string, error) {
 		CanonVersion:    e.CanonVersion,
 		AdapterVersion:  e.AdapterVersion,
 		ScoringVersion:  e.ScoringVersion,
+		KeyID:           e.KeyID,
 	}
 
 	data, err := json.Marshal(h)
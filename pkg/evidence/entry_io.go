@@ -2,8 +2,10 @@ package evidence
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
@@ -49,6 +51,40 @@ func streamFileEntries(path string, fn func(EvidenceEntry, int) error) error {
 	return nil
 }
 
+// streamFileEntriesFrom reads the complete JSONL lines of path from byte
+// offset on, passing each entry to fn, and returns the bytes it consumed. A
+// trailing line without a newline is left for the next read.
+func streamFileEntriesFrom(path string, offset int64, fn func(EvidenceEntry)) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	r := bufio.NewReader(f)
+	var read int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return read, nil
+		}
+		if err != nil {
+			return read, fmt.Errorf("read JSONL: %w", err)
+		}
+		read += int64(len(line))
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var entry EvidenceEntry
+			if err := json.Unmarshal(trimmed, &entry); err != nil {
+				return read, fmt.Errorf("parse JSONL at byte %d: %w", offset+read-int64(len(line)), err)
+			}
+			fn(entry)
+		}
+	}
+}
+
 // appendEntryLine marshals an EvidenceEntry to JSON and appends it as a
 // single line to the file at path, creating the file if it does not exist.
 func appendEntryLine(path string, entry EvidenceEntry) (err error) {
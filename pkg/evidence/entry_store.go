@@ -19,6 +19,15 @@ var (
 // The entry must already have Hash computed (via BuildEntry).
 // Updates manifest RecordsTotal, LastHash, and UpdatedAt.
 func AppendEntryAtPath(path string, entry EvidenceEntry) error {
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return err
+		}
+		if err := validatePersistedEntry(entry); err != nil {
+			return err
+		}
+		return b.AppendEntries([]EvidenceEntry{entry})
+	}
 	if err := storeLock(path, func() error {
 		return appendEntryUnlocked(path, entry)
 	}); err != nil {
@@ -33,10 +42,23 @@ func appendEntryUnlocked(path string, entry EvidenceEntry) error {
 		return err
 	}
 
+	manifest, err := loadManifestForAppend(path)
+	if err != nil {
+		return err
+	}
+	if err := appendEntryToSegment(path, &manifest, entry); err != nil {
+		return err
+	}
+	return writeManifestAtomic(path, manifest)
+}
+
+// loadManifestForAppend loads (or initializes) the manifest and normalizes
+// the fields appendEntryToSegment relies on.
+func loadManifestForAppend(path string) (StoreManifest, error) {
 	maxBytes := segmentMaxBytesFromEnv()
 	manifest, err := loadOrInitManifest(path, maxBytes, true)
 	if err != nil {
-		return err
+		return StoreManifest{}, err
 	}
 	if manifest.SegmentMaxBytes <= 0 {
 		manifest.SegmentMaxBytes = maxBytes
@@ -45,7 +67,12 @@ func appendEntryUnlocked(path string, entry EvidenceEntry) error {
 		manifest.CurrentSegment = segmentName(1)
 	}
 	manifest.SealedSegments = normalizeSealedSegments(manifest.SealedSegments)
+	return manifest, nil
+}
 
+// appendEntryToSegment writes entry to the current segment and advances the
+// in-memory manifest. The caller persists the manifest.
+func appendEntryToSegment(path string, manifest *StoreManifest, entry EvidenceEntry) error {
 	segPath := filepath.Join(path, segmentsDirName, manifest.CurrentSegment)
 	if err := os.MkdirAll(filepath.Dir(segPath), 0o755); err != nil {
 		return fmt.Errorf("create segments directory: %w", err)
@@ -88,8 +115,7 @@ func appendEntryUnlocked(path string, entry EvidenceEntry) error {
 			}
 		}
 	}
-
-	return writeManifestAtomic(path, manifest)
+	return nil
 }
 
 func validatePersistedEntry(entry EvidenceEntry) error {
@@ -105,11 +131,9 @@ func validatePersistedEntry(entry EvidenceEntry) error {
 // ReadAllEntriesAtPath reads all EvidenceEntry records from the segmented store.
 func ReadAllEntriesAtPath(path string) ([]EvidenceEntry, error) {
 	entries := make([]EvidenceEntry, 0)
-	err := storeLock(path, func() error {
-		return forEachEntryAtPathUnlocked(path, func(e EvidenceEntry) error {
-			entries = append(entries, e)
-			return nil
-		})
+	err := ForEachEntryAtPath(path, func(e EvidenceEntry) error {
+		entries = append(entries, e)
+		return nil
 	})
 	if err != nil {
 		return nil, err
@@ -120,11 +144,71 @@ func ReadAllEntriesAtPath(path string) ([]EvidenceEntry, error) {
 // ForEachEntryAtPath iterates over all entries in the segmented store,
 // calling fn for each EvidenceEntry in order.
 func ForEachEntryAtPath(path string, fn func(EvidenceEntry) error) error {
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return err
+		}
+		return b.ForEach(fn)
+	}
 	return storeLock(path, func() error {
 		return forEachEntryAtPathUnlocked(path, fn)
 	})
 }
 
+// EntryCursor is a position in a store's chain, returned by
+// EntriesSinceAtPath. The zero value is the start of the chain.
+type EntryCursor struct {
+	// LastEntryID is the entry at the position; empty at the start.
+	LastEntryID string
+	segment     int   // 1-based segment the JSONL store was read up to
+	offset      int64 // bytes of that segment already read
+}
+
+// EntriesSinceAtPath returns the entries appended after cursor, in chain
+// order, and the cursor past them. The JSONL store resumes at the cursor's
+// byte offset and backends resume after LastEntryID, so a caller polling a
+// growing chain reads each entry once.
+func EntriesSinceAtPath(path string, cursor EntryCursor) ([]EvidenceEntry, EntryCursor, error) {
+	var out []EvidenceEntry
+	next := cursor
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return nil, cursor, err
+		}
+		if out, err = b.Query(EntryQuery{After: cursor.LastEntryID}); err != nil {
+			return nil, cursor, err
+		}
+	} else {
+		err := storeLock(path, func() error {
+			_, names, err := orderedSegmentNames(path)
+			if err != nil {
+				return err
+			}
+			for idx := max(cursor.segment, 1); idx <= len(names); idx++ {
+				offset := int64(0)
+				if idx == cursor.segment {
+					offset = cursor.offset
+				}
+				read, err := streamFileEntriesFrom(filepath.Join(path, segmentsDirName, names[idx-1]), offset, func(e EvidenceEntry) {
+					out = append(out, e)
+				})
+				if err != nil {
+					return err
+				}
+				next.segment, next.offset = idx, offset+read
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, cursor, err
+		}
+	}
+	if len(out) > 0 {
+		next.LastEntryID = out[len(out)-1].EntryID
+	}
+	return out, next, nil
+}
+
 func forEachEntryAtPathUnlocked(path string, fn func(EvidenceEntry) error) error {
 	_, names, err := orderedSegmentNames(path)
 	if err != nil {
@@ -174,12 +258,25 @@ func validateChainEntries(entries []EvidenceEntry) error {
 				Message: fmt.Sprintf("hash mismatch: stored %s, computed %s", entry.Hash, recomputed),
 			}
 		}
+		if entry.Type == EntryTypeTreeHead {
+			if err := validateTreeHeadEntry(i, entry); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func validateEntrySignatures(entries []EvidenceEntry, pubKey ed25519.PublicKey) error {
+func validateEntrySignatures(entries []EvidenceEntry, keyring *Keyring) (*Keyring, error) {
+	// Work on a copy: keys introduced by key_rotation entries are trusted
+	// only for the remainder of this chain.
+	trusted := NewKeyring()
+	for _, kid := range keyring.KeyIDs() {
+		pub, _ := keyring.Lookup(kid)
+		trusted.Add(pub)
+	}
+
 	signed := 0
 	for i, entry := range entries {
 		if entry.Signature == "" {
@@ -187,31 +284,60 @@ func validateEntrySignatures(entries []EvidenceEntry, pubKey ed25519.PublicKey)
 		}
 		sig, decErr := base64.StdEncoding.DecodeString(entry.Signature)
 		if decErr != nil {
-			return &ChainValidationError{
+			return nil, &ChainValidationError{
 				Index:   i,
 				EventID: entry.EntryID,
 				Message: fmt.Sprintf("invalid base64 signature: %v", decErr),
 			}
 		}
-		if !ed25519.Verify(pubKey, []byte(entry.Hash), sig) {
-			return &ChainValidationError{
-				Index:   i,
-				EventID: entry.EntryID,
-				Message: "signature verification failed",
+		if !verifyEntrySignature(trusted, entry, sig) {
+			msg := "signature verification failed"
+			if _, known := trusted.Lookup(entry.KeyID); entry.KeyID != "" && !known {
+				msg = fmt.Sprintf("signature verification failed: unknown key_id %s", entry.KeyID)
+			}
+			return nil, &ChainValidationError{Index: i, EventID: entry.EntryID, Message: msg}
+		}
+		if entry.Type == EntryTypeKeyRotation {
+			newPub, err := IntroducedKey(entry)
+			if err != nil {
+				return nil, &ChainValidationError{Index: i, EventID: entry.EntryID, Message: err.Error()}
 			}
+			trusted.Add(newPub)
 		}
 		signed++
 	}
 
 	if signed == 0 && len(entries) > 0 {
-		return fmt.Errorf("validate signatures: no signed entries found (chain has %d entries)", len(entries))
+		return nil, fmt.Errorf("validate signatures: no signed entries found (chain has %d entries)", len(entries))
 	}
 
-	return nil
+	return trusted, nil
+}
+
+// verifyEntrySignature checks sig against the key named by entry.KeyID, or
+// against every trusted key for legacy entries that carry no key_id.
+func verifyEntrySignature(trusted *Keyring, entry EvidenceEntry, sig []byte) bool {
+	if entry.KeyID != "" {
+		pub, ok := trusted.Lookup(entry.KeyID)
+		return ok && ed25519.Verify(pub, []byte(entry.Hash), sig)
+	}
+	for _, kid := range trusted.KeyIDs() {
+		pub, _ := trusted.Lookup(kid)
+		if ed25519.Verify(pub, []byte(entry.Hash), sig) {
+			return true
+		}
+	}
+	return false
 }
 
 // FindEntryByID finds an entry by its entry_id in the segmented store.
 func FindEntryByID(path string, entryID string) (EvidenceEntry, bool, error) {
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return EvidenceEntry{}, false, err
+		}
+		return b.FindByID(entryID)
+	}
 	if cached, ok := lookupCachedEntryByID(path, entryID); ok {
 		return cached, true, nil
 	}
@@ -239,6 +365,13 @@ func FindEntryByID(path string, entryID string) (EvidenceEntry, bool, error) {
 // LastHashAtPath returns the last hash from the manifest for chain linking.
 // Returns empty string if the store is empty or does not exist yet.
 func LastHashAtPath(path string) (string, error) {
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return "", err
+		}
+		_, lastHash, err := b.Tip()
+		return lastHash, err
+	}
 	var lastHash string
 	err := storeLock(path, func() error {
 		manifest, err := loadOrInitManifest(path, segmentMaxBytesFromEnv(), false)
@@ -261,32 +394,38 @@ func LastHashAtPath(path string) (string, error) {
 // For each entry it checks that previous_hash links correctly and that the
 // stored hash matches a recomputed hash over the entry fields.
 func ValidateChainAtPath(root string) error {
-	return storeLock(root, func() error {
-		entries := make([]EvidenceEntry, 0)
-		if err := forEachEntryAtPathUnlocked(root, func(e EvidenceEntry) error {
-			entries = append(entries, e)
-			return nil
-		}); err != nil {
-			return fmt.Errorf("validate chain: %w", err)
-		}
-		return validateChainEntries(entries)
-	})
+	entries, err := ReadAllEntriesAtPath(root)
+	if err != nil {
+		return fmt.Errorf("validate chain: %w", err)
+	}
+	return validateChainEntries(entries)
 }
 
 // ValidateChainWithSignatures validates hash chain integrity AND verifies
 // Ed25519 signatures on all entries that have a non-empty Signature field.
+// Keys introduced by key_rotation entries signed with pubKey are trusted for
+// the entries that follow them.
 func ValidateChainWithSignatures(root string, pubKey ed25519.PublicKey) error {
-	return storeLock(root, func() error {
-		entries := make([]EvidenceEntry, 0)
-		if err := forEachEntryAtPathUnlocked(root, func(e EvidenceEntry) error {
-			entries = append(entries, e)
-			return nil
-		}); err != nil {
-			return fmt.Errorf("validate signatures: %w", err)
-		}
-		if err := validateChainEntries(entries); err != nil {
-			return err
-		}
-		return validateEntrySignatures(entries, pubKey)
-	})
+	return ValidateChainWithKeyring(root, NewKeyring(pubKey))
+}
+
+// ValidateChainWithKeyring is ValidateChainWithSignatures for a set of
+// trusted keys. Each entry is verified with the key named by its key_id.
+func ValidateChainWithKeyring(root string, keyring *Keyring) error {
+	_, err := TrustedKeysAtPath(root, keyring)
+	return err
+}
+
+// TrustedKeysAtPath validates the chain like ValidateChainWithKeyring and
+// returns keyring extended with every key introduced by a verified
+// key_rotation entry.
+func TrustedKeysAtPath(root string, keyring *Keyring) (*Keyring, error) {
+	entries, err := ReadAllEntriesAtPath(root)
+	if err != nil {
+		return nil, fmt.Errorf("validate signatures: %w", err)
+	}
+	if err := validateChainEntries(entries); err != nil {
+		return nil, err
+	}
+	return validateEntrySignatures(entries, keyring)
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -123,6 +124,56 @@ func TestReadAllEntriesAtPath(t *testing.T) {
 	}
 }
 
+func TestEntriesSinceAtPath(t *testing.T) {
+	// Not parallel: shrinks segments so the chain spans several files.
+	t.Setenv(segmentMaxBytesEnv, "1500")
+	dir := t.TempDir()
+
+	var prev string
+	appendN := func(n int) []string {
+		t.Helper()
+		var ids []string
+		for i := 0; i < n; i++ {
+			e := buildTestEntry(t, EntryTypePrescribe, prev)
+			if err := AppendEntryAtPath(dir, e); err != nil {
+				t.Fatalf("AppendEntryAtPath: %v", err)
+			}
+			prev = e.Hash
+			ids = append(ids, e.EntryID)
+		}
+		return ids
+	}
+	since := func(cursor EntryCursor) ([]string, EntryCursor) {
+		t.Helper()
+		entries, next, err := EntriesSinceAtPath(dir, cursor)
+		if err != nil {
+			t.Fatalf("EntriesSinceAtPath: %v", err)
+		}
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.EntryID)
+		}
+		return ids, next
+	}
+
+	first := appendN(3)
+	got, cursor := since(EntryCursor{})
+	if !slices.Equal(got, first) || cursor.LastEntryID != first[2] {
+		t.Fatalf("first read = %v (cursor %+v), want %v", got, cursor, first)
+	}
+	if got, again := since(cursor); len(got) != 0 || again != cursor {
+		t.Fatalf("read without appends = %v (cursor %+v), want nothing", got, again)
+	}
+
+	second := appendN(4)
+	if _, names, _ := orderedSegmentNames(dir); len(names) < 2 {
+		t.Fatalf("segments = %v, want the chain to span several", names)
+	}
+	if got, _ = since(cursor); !slices.Equal(got, second) {
+		t.Fatalf("second read = %v, want %v", got, second)
+	}
+}
+
 func TestLastHashAtPath(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
@@ -151,6 +151,10 @@ func TestEntryType_Validate(t *testing.T) {
 		{name: "session_start", et: EntryTypeSessionStart, valid: true},
 		{name: "session_end", et: EntryTypeSessionEnd, valid: true},
 		{name: "annotation", et: EntryTypeAnnotation, valid: true},
+		{name: "verification", et: EntryTypeVerification, valid: true},
+		{name: "plan", et: EntryTypePlan, valid: true},
+		{name: "cancel", et: EntryTypeCancel, valid: true},
+		{name: "approval", et: EntryTypeApproval, valid: true},
 		{name: "empty string", et: EntryType(""), valid: false},
 		{name: "unknown type", et: EntryType("unknown"), valid: false},
 		{name: "uppercase", et: EntryType("PRESCRIBE"), valid: false},
@@ -0,0 +1,65 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// KeyRotationProofMessage is the message the incoming key signs to prove
+// possession when it is introduced by a key_rotation entry.
+func KeyRotationProofMessage(previousKeyID, newKeyID string) []byte {
+	return []byte("evidra-key-rotation\n" + previousKeyID + "\n" + newKeyID)
+}
+
+// BuildKeyRotationPayload returns the payload for a key_rotation entry that
+// hands over from oldSigner to newSigner. The entry carrying it must be
+// built with oldSigner.
+func BuildKeyRotationPayload(oldSigner, newSigner Signer, reason string) (json.RawMessage, error) {
+	if oldSigner == nil || newSigner == nil {
+		return nil, fmt.Errorf("evidence.BuildKeyRotationPayload: both signers are required")
+	}
+	prevID := KeyID(oldSigner.PublicKey())
+	newPub := newSigner.PublicKey()
+	newID := KeyID(newPub)
+	if prevID == newID {
+		return nil, fmt.Errorf("evidence.BuildKeyRotationPayload: new key must differ from the current key")
+	}
+	proof, err := SignPayload(newSigner, KeyRotationProofMessage(prevID, newID))
+	if err != nil {
+		return nil, fmt.Errorf("evidence.BuildKeyRotationPayload: sign proof: %w", err)
+	}
+	return json.Marshal(KeyRotationPayload{
+		PreviousKeyID: prevID,
+		NewKeyID:      newID,
+		NewPublicKey:  base64.RawURLEncoding.EncodeToString(newPub),
+		NewKeyProof:   base64.StdEncoding.EncodeToString(proof),
+		Reason:        reason,
+	})
+}
+
+// IntroducedKey validates a key_rotation entry whose signature has already
+// been verified and returns the key it introduces.
+func IntroducedKey(entry EvidenceEntry) (ed25519.PublicKey, error) {
+	var payload KeyRotationPayload
+	if err := json.Unmarshal(entry.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode key rotation payload: %w", err)
+	}
+	if entry.KeyID != "" && payload.PreviousKeyID != entry.KeyID {
+		return nil, fmt.Errorf("key rotation previous_key_id %s does not match signing key %s", payload.PreviousKeyID, entry.KeyID)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload.NewPublicKey)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("key rotation new_public_key is not an Ed25519 key")
+	}
+	newPub := ed25519.PublicKey(raw)
+	if KeyID(newPub) != payload.NewKeyID {
+		return nil, fmt.Errorf("key rotation new_key_id does not match new_public_key")
+	}
+	proof, err := base64.StdEncoding.DecodeString(payload.NewKeyProof)
+	if err != nil || !ed25519.Verify(newPub, KeyRotationProofMessage(payload.PreviousKeyID, payload.NewKeyID), proof) {
+		return nil, fmt.Errorf("key rotation proof of possession failed")
+	}
+	return newPub, nil
+}
@@ -0,0 +1,142 @@
+package evidence
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func buildTestEntryWithSigner(t *testing.T, signer Signer, typ EntryType, payload json.RawMessage, previousHash string) EvidenceEntry {
+	t.Helper()
+	entry, err := BuildEntry(EntryBuildParams{
+		Type:         typ,
+		SessionID:    "session-rotation",
+		TraceID:      "01TRACE",
+		Actor:        Actor{Type: "ci", ID: "test", Provenance: "cli"},
+		Payload:      payload,
+		PreviousHash: previousHash,
+		SpecVersion:  "0.3.0",
+		Signer:       signer,
+	})
+	if err != nil {
+		t.Fatalf("BuildEntry: %v", err)
+	}
+	return entry
+}
+
+func appendSignedEntries(t *testing.T, dir string, entries ...func(prev string) EvidenceEntry) {
+	t.Helper()
+	for _, build := range entries {
+		prev, err := LastHashAtPath(dir)
+		if err != nil {
+			t.Fatalf("LastHashAtPath: %v", err)
+		}
+		if err := AppendEntryAtPath(dir, build(prev)); err != nil {
+			t.Fatalf("AppendEntryAtPath: %v", err)
+		}
+	}
+}
+
+func TestValidateChainWithSignatures_FollowsKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := newTestSigner(t), newTestSigner(t)
+	annotation := json.RawMessage(`{"key":"k","value":"v"}`)
+	rotation, err := BuildKeyRotationPayload(oldKey, newKey, "scheduled")
+	if err != nil {
+		t.Fatalf("BuildKeyRotationPayload: %v", err)
+	}
+
+	dir := t.TempDir()
+	appendSignedEntries(t, dir,
+		func(prev string) EvidenceEntry {
+			return buildTestEntryWithSigner(t, oldKey, EntryTypeAnnotation, annotation, prev)
+		},
+		func(prev string) EvidenceEntry {
+			return buildTestEntryWithSigner(t, oldKey, EntryTypeKeyRotation, rotation, prev)
+		},
+		func(prev string) EvidenceEntry {
+			return buildTestEntryWithSigner(t, newKey, EntryTypeAnnotation, annotation, prev)
+		},
+	)
+
+	if err := ValidateChainWithSignatures(dir, oldKey.PublicKey()); err != nil {
+		t.Fatalf("old key should verify the whole chain via rotation: %v", err)
+	}
+	trusted, err := TrustedKeysAtPath(dir, NewKeyring(oldKey.PublicKey()))
+	if err != nil {
+		t.Fatalf("TrustedKeysAtPath: %v", err)
+	}
+	if _, ok := trusted.Lookup(KeyID(newKey.PublicKey())); !ok {
+		t.Fatal("rotated key should be trusted after validation")
+	}
+
+	err = ValidateChainWithSignatures(dir, newKey.PublicKey())
+	var chainErr *ChainValidationError
+	if !errors.As(err, &chainErr) || chainErr.Index != 0 || !strings.Contains(chainErr.Message, "unknown key_id") {
+		t.Fatalf("new key alone should not verify pre-rotation entries, got %v", err)
+	}
+	if err := ValidateChainWithKeyring(dir, NewKeyring(oldKey.PublicKey(), newKey.PublicKey())); err != nil {
+		t.Fatalf("ValidateChainWithKeyring: %v", err)
+	}
+}
+
+func TestValidateChainWithSignatures_RejectsEntryBeforeRotation(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := newTestSigner(t), newTestSigner(t)
+	annotation := json.RawMessage(`{"key":"k","value":"v"}`)
+
+	dir := t.TempDir()
+	appendSignedEntries(t, dir,
+		func(prev string) EvidenceEntry {
+			return buildTestEntryWithSigner(t, oldKey, EntryTypeAnnotation, annotation, prev)
+		},
+		func(prev string) EvidenceEntry {
+			return buildTestEntryWithSigner(t, newKey, EntryTypeAnnotation, annotation, prev)
+		},
+	)
+
+	err := ValidateChainWithSignatures(dir, oldKey.PublicKey())
+	var chainErr *ChainValidationError
+	if !errors.As(err, &chainErr) || chainErr.Index != 1 {
+		t.Fatalf("expected failure at index 1, got %v", err)
+	}
+}
+
+func TestValidateChainWithSignatures_RejectsRotationWithoutProof(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey, otherKey := newTestSigner(t), newTestSigner(t), newTestSigner(t)
+	rotation, err := BuildKeyRotationPayload(oldKey, newKey, "")
+	if err != nil {
+		t.Fatalf("BuildKeyRotationPayload: %v", err)
+	}
+	var payload KeyRotationPayload
+	if err := json.Unmarshal(rotation, &payload); err != nil {
+		t.Fatal(err)
+	}
+	// Proof made by a key that is not the one being introduced.
+	payload.NewKeyProof = base64.StdEncoding.EncodeToString(otherKey.Sign(KeyRotationProofMessage(payload.PreviousKeyID, payload.NewKeyID)))
+	forged, _ := json.Marshal(payload)
+
+	dir := t.TempDir()
+	appendSignedEntries(t, dir, func(prev string) EvidenceEntry {
+		return buildTestEntryWithSigner(t, oldKey, EntryTypeKeyRotation, forged, prev)
+	})
+
+	err = ValidateChainWithSignatures(dir, oldKey.PublicKey())
+	if err == nil || !strings.Contains(err.Error(), "proof of possession") {
+		t.Fatalf("expected proof of possession failure, got %v", err)
+	}
+}
+
+func TestBuildKeyRotationPayload_RejectsSameKey(t *testing.T) {
+	t.Parallel()
+	key := newTestSigner(t)
+	if _, err := BuildKeyRotationPayload(key, key, ""); err == nil {
+		t.Fatal("expected error rotating to the same key")
+	}
+}
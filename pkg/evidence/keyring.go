@@ -0,0 +1,118 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// JWK is an RFC 8037 OKP JSON Web Key carrying an Ed25519 public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set. It is the keyring file format accepted by
+// `evidra validate --keyring` and the response of GET /v1/evidence/pubkey.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyID returns the identifier recorded in EvidenceEntry.KeyID for pub: the
+// RFC 7638 JWK thumbprint of the Ed25519 key, base64url-encoded.
+func KeyID(pub ed25519.PublicKey) string {
+	// Members in lexicographic order, no whitespace, as RFC 7638 requires.
+	canonical := `{"crv":"Ed25519","kty":"OKP","x":"` + base64.RawURLEncoding.EncodeToString(pub) + `"}`
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Keyring is a set of trusted Ed25519 public keys indexed by KeyID.
+type Keyring struct {
+	keys map[string]ed25519.PublicKey
+}
+
+// NewKeyring returns a keyring trusting the given keys.
+func NewKeyring(keys ...ed25519.PublicKey) *Keyring {
+	k := &Keyring{keys: make(map[string]ed25519.PublicKey, len(keys))}
+	for _, pub := range keys {
+		k.Add(pub)
+	}
+	return k
+}
+
+// Add trusts pub and returns its KeyID.
+func (k *Keyring) Add(pub ed25519.PublicKey) string {
+	kid := KeyID(pub)
+	k.keys[kid] = pub
+	return kid
+}
+
+// Lookup returns the key with the given KeyID.
+func (k *Keyring) Lookup(kid string) (ed25519.PublicKey, bool) {
+	pub, ok := k.keys[kid]
+	return pub, ok
+}
+
+// Len reports the number of trusted keys.
+func (k *Keyring) Len() int {
+	return len(k.keys)
+}
+
+// KeyIDs returns all trusted KeyIDs in sorted order.
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// JWKS renders the keyring as a JSON Web Key Set in KeyID order.
+func (k *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(k.keys))}
+	for _, kid := range k.KeyIDs() {
+		set.Keys = append(set.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k.keys[kid]),
+			Kid: kid,
+			Use: "sig",
+			Alg: "EdDSA",
+		})
+	}
+	return set
+}
+
+// ParseJWKS decodes a JSON Web Key Set into a keyring. Keys other than
+// Ed25519 are rejected; a kid that does not match the key's thumbprint is
+// rejected so entries cannot be attributed to the wrong key.
+func ParseJWKS(data []byte) (*Keyring, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("evidence.ParseJWKS: %w", err)
+	}
+	k := NewKeyring()
+	for i, jwk := range set.Keys {
+		if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("evidence.ParseJWKS: key %d: unsupported kty/crv %s/%s", i, jwk.Kty, jwk.Crv)
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("evidence.ParseJWKS: key %d: invalid x", i)
+		}
+		kid := k.Add(ed25519.PublicKey(raw))
+		if jwk.Kid != "" && jwk.Kid != kid {
+			return nil, fmt.Errorf("evidence.ParseJWKS: key %d: kid %q does not match thumbprint %q", i, jwk.Kid, kid)
+		}
+	}
+	return k, nil
+}
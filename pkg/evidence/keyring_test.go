@@ -0,0 +1,79 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestKeyID_RFC8037Thumbprint(t *testing.T) {
+	t.Parallel()
+	// RFC 8037 appendix A.3.
+	raw, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := KeyID(ed25519.PublicKey(raw)), "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"; got != want {
+		t.Fatalf("KeyID = %s, want %s", got, want)
+	}
+}
+
+func TestBuildEntry_SetsKeyID(t *testing.T) {
+	t.Parallel()
+	signer := newTestSigner(t)
+	entry := buildTestEntryWithSigner(t, signer, EntryTypeAnnotation, json.RawMessage(`{"key":"k","value":"v"}`), "")
+	if entry.KeyID != KeyID(signer.PublicKey()) {
+		t.Fatalf("KeyID = %q, want %q", entry.KeyID, KeyID(signer.PublicKey()))
+	}
+
+	// key_id is covered by the hash: swapping it breaks the chain.
+	entry.KeyID = "other"
+	if err := validateChainEntries([]EvidenceEntry{entry}); err == nil {
+		t.Fatal("expected hash mismatch after changing key_id")
+	}
+}
+
+func TestKeyring_JWKSRoundTrip(t *testing.T) {
+	t.Parallel()
+	a, b := newTestSigner(t), newTestSigner(t)
+	keyring := NewKeyring(a.PublicKey(), b.PublicKey())
+
+	data, err := json.Marshal(keyring.JWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("ParseJWKS: %v", err)
+	}
+	if strings.Join(parsed.KeyIDs(), ",") != strings.Join(keyring.KeyIDs(), ",") {
+		t.Fatalf("key ids = %v, want %v", parsed.KeyIDs(), keyring.KeyIDs())
+	}
+	if _, ok := parsed.Lookup(KeyID(a.PublicKey())); !ok {
+		t.Fatal("expected key a in parsed keyring")
+	}
+}
+
+func TestParseJWKS_Rejects(t *testing.T) {
+	t.Parallel()
+	x := base64.RawURLEncoding.EncodeToString(newTestSigner(t).PublicKey())
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "wrong kty", data: `{"keys":[{"kty":"EC","crv":"P-256","x":"` + x + `"}]}`},
+		{name: "bad x", data: `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"short"}]}`},
+		{name: "kid mismatch", data: `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"` + x + `","kid":"nope"}]}`},
+		{name: "not json", data: `nope`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := ParseJWKS([]byte(tt.data)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -13,7 +13,20 @@ func ManifestPath(root string) string {
 	return filepath.Join(root, manifestFileName)
 }
 
+// LoadManifest returns the segment store manifest at path. For backend
+// stores it returns a synthesized manifest carrying RecordsTotal and
+// LastHash.
 func LoadManifest(path string) (StoreManifest, error) {
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return StoreManifest{}, err
+		}
+		count, lastHash, err := b.Tip()
+		if err != nil {
+			return StoreManifest{}, err
+		}
+		return StoreManifest{Format: "evidra-evidence-backend", RecordsTotal: count, LastHash: lastHash}, nil
+	}
 	var out StoreManifest
 	err := storeLock(path, func() error {
 		mode, resolved, err := detectStoreMode(path)
@@ -70,8 +70,29 @@ type PrescriptionPayload struct {
 	RiskTags    []string `json:"risk_tags,omitempty"`
 	TTLMs       int64    `json:"ttl_ms"`
 	CanonSource string   `json:"canon_source"`
+	// PlanID links the prescription to a plan entry; PlanStep is the
+	// 1-based step it carries out, or 0 for a step the plan did not list.
+	PlanID   string `json:"plan_id,omitempty"`
+	PlanStep int    `json:"plan_step,omitempty"`
+	// RevertsPrescriptionID marks the operation as a rollback of an earlier
+	// prescription; RevertMatch says how much of that prescription's
+	// resources it covers.
+	RevertsPrescriptionID string `json:"reverts_prescription_id,omitempty"`
+	RevertMatch           string `json:"revert_match,omitempty"`
+	// ApprovalRequired holds the operation until a human approval entry
+	// signs it off.
+	ApprovalRequired bool `json:"approval_required,omitempty"`
 }
 
+// Revert match values compare a rollback's resources with the reverted
+// prescription's.
+const (
+	RevertMatchFull    = "full"    // every reverted resource is covered
+	RevertMatchPartial = "partial" // some reverted resources are covered
+	RevertMatchNone    = "none"    // no reverted resource is covered
+	RevertMatchUnknown = "unknown" // either side names no resources
+)
+
 // EffectiveRiskDetails returns canonical risk details when present,
 // otherwise falls back to legacy risk_tags for backward compatibility.
 func (p PrescriptionPayload) EffectiveRiskDetails() []string {
@@ -110,6 +131,104 @@ type ReportPayload struct {
 	Verdict         Verdict          `json:"verdict"`
 	DecisionContext *DecisionContext `json:"decision_context,omitempty"`
 	ExternalRefs    []ExternalRef    `json:"external_refs,omitempty"`
+	Transcript      *TranscriptRef   `json:"transcript,omitempty"`
+}
+
+// CancelPayload is the typed payload for EntryTypeCancel entries. A
+// cancellation closes the prescription the same way a report does, without
+// claiming the operation ran.
+type CancelPayload struct {
+	PrescriptionID string `json:"prescription_id"`
+	Reason         string `json:"reason"`
+}
+
+// ApprovalPayload is the typed payload for EntryTypeApproval entries. The
+// entry is signed with the approver's own key, not the agent's.
+type ApprovalPayload struct {
+	PrescriptionID string `json:"prescription_id"`
+	Decision       string `json:"decision"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// Approval decisions.
+const (
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// TranscriptRef points a report at the captured output of the executed
+// command, stored as a content-addressed blob alongside the evidence store.
+// The blob is redacted and size-capped before it is hashed.
+type TranscriptRef struct {
+	Digest    string `json:"digest"`
+	SizeBytes int64  `json:"size_bytes"`
+	// CapturedBytes is the total output the command produced, before
+	// truncation.
+	CapturedBytes int64 `json:"captured_bytes"`
+	Truncated     bool  `json:"truncated,omitempty"`
+	Redactions    int   `json:"redactions,omitempty"`
+}
+
+// Verification statuses and per-resource outcomes.
+const (
+	VerificationMatched    = "matched"
+	VerificationMismatched = "mismatched"
+
+	ResourceMatched    = "matched"
+	ResourceDrifted    = "drifted"
+	ResourceMissing    = "missing"
+	ResourceNotDeleted = "not_deleted"
+)
+
+// VerificationPayload is the typed payload for EntryTypeVerification
+// entries. It records live state read back after execution and compared
+// with what the prescription intended.
+type VerificationPayload struct {
+	PrescriptionID string `json:"prescription_id"`
+	ReportID       string `json:"report_id,omitempty"`
+	// Method names the read-back, e.g. "kubectl_get" or "terraform_show".
+	Method     string             `json:"method"`
+	Status     string             `json:"status"`
+	Matched    int                `json:"matched"`
+	Mismatched int                `json:"mismatched"`
+	Resources  []VerifiedResource `json:"resources"`
+	// FieldsCompared is false when no artifact was available and only
+	// presence was checked.
+	FieldsCompared bool `json:"fields_compared"`
+}
+
+// VerifiedResource is the outcome for one prescribed resource. Differences
+// lists field paths only, never values, so secrets do not leak into the
+// chain.
+type VerifiedResource struct {
+	Resource    string   `json:"resource"`
+	Status      string   `json:"status"`
+	Differences []string `json:"differences,omitempty"`
+}
+
+// PlanPayload is the typed payload for EntryTypePlan entries. It records
+// the operations an actor intends to run, in order, before running any.
+type PlanPayload struct {
+	PlanID string     `json:"plan_id"`
+	Steps  []PlanStep `json:"steps"`
+	// EffectiveRisk is the highest step risk.
+	EffectiveRisk string `json:"effective_risk"`
+	TTLMs         int64  `json:"ttl_ms"`
+}
+
+// PlanStep is one planned operation. Steps planned without an artifact
+// carry only the tool/operation classification and matrix risk.
+type PlanStep struct {
+	Step           int    `json:"step"`
+	Tool           string `json:"tool"`
+	Operation      string `json:"operation"`
+	Description    string `json:"description,omitempty"`
+	OperationClass string `json:"operation_class"`
+	ScopeClass     string `json:"scope_class"`
+	ResourceCount  int    `json:"resource_count,omitempty"`
+	IntentDigest   string `json:"intent_digest,omitempty"`
+	ArtifactDigest string `json:"artifact_digest,omitempty"`
+	EffectiveRisk  string `json:"effective_risk"`
 }
 
 // FindingPayload is the typed payload for EntryTypeFinding entries.
@@ -157,3 +276,36 @@ type AnnotationPayload struct {
 	Value   string `json:"value"`
 	Message string `json:"message,omitempty"`
 }
+
+// TreeHeadPayload is the typed payload for EntryTypeTreeHead entries.
+// It commits to the number of entries preceding the head and the hash of
+// the last one, so the head entry's PreviousHash always equals LastHash.
+type TreeHeadPayload struct {
+	EntryCount int    `json:"entry_count"`
+	LastHash   string `json:"last_hash"`
+}
+
+// KeyRotationPayload is the typed payload for EntryTypeKeyRotation entries.
+// The entry itself is signed by the outgoing key; NewKeyProof is a signature
+// by the incoming key over KeyRotationProofMessage, proving possession.
+type KeyRotationPayload struct {
+	PreviousKeyID string `json:"previous_key_id"`
+	NewKeyID      string `json:"new_key_id"`
+	NewPublicKey  string `json:"new_public_key"` // base64url raw Ed25519 key
+	NewKeyProof   string `json:"new_key_proof"`  // base64 Ed25519 signature
+	Reason        string `json:"reason,omitempty"`
+}
+
+// ReceiptPayload is the typed payload for EntryTypeReceipt entries. It is
+// written locally after an API acknowledges forwarded entries and records
+// how far the outbox cursor for APIURL has advanced.
+type ReceiptPayload struct {
+	APIURL       string `json:"api_url"`
+	EntriesAcked int    `json:"entries_acked"`
+	FirstEntryID string `json:"first_entry_id"`
+	LastEntryID  string `json:"last_entry_id"`
+	LastHash     string `json:"last_hash"`
+	// ChainPosition is the number of chain entries acknowledged so far,
+	// including this batch.
+	ChainPosition int `json:"chain_position"`
+}
@@ -0,0 +1,409 @@
+package evidence
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Concurrent writers do not link the chain themselves. AppendAtPath stages
+// the unsealed entry as a file in the writer's staging segment
+// (staging/<writer>-<seq>.staged.json), then whichever writer holds the
+// store lock acts as sequencer: it merges every staged entry carrying its
+// key into the single chain in staging order, sets previous_hash, seals and
+// signs each one, and commits the manifest once per batch. Writers contend
+// only for these short group commits and keep waiting for their entry to be
+// sealed instead of failing with evidence_store_busy.
+//
+// Entries are only sealed by a writer holding their key. An entry staged
+// under a key no writer uses any more waits in staging, outside the chain,
+// until one does; StagedCountAtPath reports it.
+
+const (
+	stagingDirName           = "staging"
+	stagedFileSuffix         = ".staged.json"
+	quarantineSuffix         = ".corrupt"
+	defaultSequenceTimeoutMS = 30000
+	sequenceTimeoutEnv       = "EVIDRA_EVIDENCE_SEQUENCE_TIMEOUT_MS"
+)
+
+var (
+	stagingWriterID  = newStagingWriterID()
+	stagingWriterSeq atomic.Uint64
+)
+
+func newStagingWriterID() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:]) // crypto/rand does not fail on supported platforms
+	return strconv.Itoa(os.Getpid()) + "-" + hex.EncodeToString(b[:])
+}
+
+func sequenceTimeoutFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv(sequenceTimeoutEnv))
+	if raw == "" {
+		return time.Duration(defaultSequenceTimeoutMS) * time.Millisecond
+	}
+	ms, err := strconv.Atoi(raw)
+	if err != nil || ms <= 0 {
+		return time.Duration(defaultSequenceTimeoutMS) * time.Millisecond
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// AppendAtPath builds an entry from p and appends it to the chain at path,
+// returning the sealed entry. p.PreviousHash is ignored: the sequencer links
+// the entry to the chain tip at commit time, so concurrent writers sharing
+// an evidence directory never fork the chain.
+//
+// If no writer can commit within EVIDRA_EVIDENCE_SEQUENCE_TIMEOUT_MS
+// (default 30s), AppendAtPath returns a StoreError with code
+// evidence_entry_staged: the entry is durable in the staging area and is
+// sealed by the next writer using the same key.
+//
+// Any other failure removes the staged entry, so an entry reported as
+// failed is never sealed later. Store failures are StoreErrors with code
+// evidence_store_read_failed or evidence_store_write_failed; with them, and
+// with evidence_entry_staged, the unsealed entry is returned.
+//
+// Backend stores serialize writers with their own transactions, so the entry
+// is linked and sealed directly without staging.
+func AppendAtPath(path string, p EntryBuildParams) (EvidenceEntry, error) {
+	entry, err := prepareEntry(p)
+	if err != nil {
+		return EvidenceEntry{}, err
+	}
+	if err := validatePersistedEntry(entry); err != nil {
+		return EvidenceEntry{}, err
+	}
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return EvidenceEntry{}, err
+		}
+		return b.AppendLinked(func(_ int, lastHash string) (EvidenceEntry, error) {
+			linked := entry
+			linked.PreviousHash = lastHash
+			if err := sealEntry(&linked, p.Signer); err != nil {
+				return EvidenceEntry{}, err
+			}
+			return linked, nil
+		})
+	}
+	mode, _, err := detectStoreMode(path)
+	if err != nil {
+		return entry, asStoreError(ErrorCodeStoreRead, err)
+	}
+	if mode == "legacy" {
+		return entry, &StoreError{
+			Code:    ErrorCodeStoreRead,
+			Message: fmt.Sprintf("evidence store %s is a legacy log file; appends need a store directory", path),
+		}
+	}
+	stagedName, err := stageEntry(path, entry)
+	if err != nil {
+		return entry, asStoreError(ErrorCodeStoreWrite, err)
+	}
+
+	deadline := time.Now().Add(sequenceTimeoutFromEnv())
+	for {
+		var (
+			sealed EvidenceEntry
+			locked bool
+		)
+		err := storeLock(path, func() error {
+			locked = true
+			batch, err := sequenceStagedUnlocked(path, p.Signer)
+			if err != nil {
+				// Removed under the lock, so no sequencer can be
+				// sealing it concurrently.
+				discardStaged(path, stagedName)
+				return err
+			}
+			for _, e := range batch {
+				if e.EntryID == entry.EntryID {
+					sealed = e
+					return nil
+				}
+			}
+			// Another writer sealed it before we got the lock.
+			found, ok, err := findRecentEntryUnlocked(path, entry.EntryID)
+			if err != nil {
+				return asStoreError(ErrorCodeStoreRead, err)
+			}
+			if !ok {
+				discardStaged(path, stagedName)
+				return &StoreError{
+					Code:    ErrorCodeStoreRead,
+					Message: fmt.Sprintf("staged entry %s missing after sequencing", entry.EntryID),
+				}
+			}
+			sealed = found
+			return nil
+		})
+		if err == nil {
+			cacheEntryByID(path, sealed)
+			return sealed, nil
+		}
+		if !IsStoreBusyError(err) {
+			if !locked {
+				// The lock itself failed, so no writer is sequencing.
+				discardStaged(path, stagedName)
+				err = asStoreError(ErrorCodeStoreWrite, err)
+			}
+			return entry, err
+		}
+		if time.Now().After(deadline) {
+			return entry, &StoreError{
+				Code:    ErrorCodeEntryStaged,
+				Message: fmt.Sprintf("Evidence entry %s staged (%s); it will be sealed by the next writer", entry.EntryID, stagedName),
+				Err:     err,
+			}
+		}
+	}
+}
+
+// discardStaged removes a staged entry that will not be sealed.
+func discardStaged(path, name string) {
+	_ = os.Remove(filepath.Join(path, stagingDirName, name)) // best-effort: the entry may already be gone
+}
+
+// SequenceStagedAtPath seals any staged entries carrying signer's key into
+// the chain and returns them in chain order.
+func SequenceStagedAtPath(path string, signer Signer) ([]EvidenceEntry, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("evidence.SequenceStagedAtPath: Signer is required")
+	}
+	if IsBackendPath(path) {
+		return nil, nil
+	}
+	var batch []EvidenceEntry
+	err := storeLock(path, func() error {
+		var err error
+		batch, err = sequenceStagedUnlocked(path, signer)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range batch {
+		cacheEntryByID(path, e)
+	}
+	return batch, nil
+}
+
+// QuarantinedCountAtPath reports how many staged files could not be parsed
+// and were set aside under a .corrupt name instead of being sealed.
+func QuarantinedCountAtPath(path string) (int, error) {
+	if IsBackendPath(path) {
+		return 0, nil
+	}
+	dirEntries, err := os.ReadDir(filepath.Join(path, stagingDirName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read staging directory: %w", err)
+	}
+	n := 0
+	for _, de := range dirEntries {
+		if strings.HasSuffix(de.Name(), stagedFileSuffix+quarantineSuffix) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// StagedCountAtPath reports how many entries are waiting in the staging area.
+func StagedCountAtPath(path string) (int, error) {
+	if IsBackendPath(path) {
+		return 0, nil
+	}
+	names, err := stagedFileNames(path)
+	if err != nil {
+		return 0, err
+	}
+	return len(names), nil
+}
+
+// stageEntry durably writes the unsealed entry to the writer's staging
+// segment via write-to-temp and rename, so sequencers never observe a
+// partial file.
+func stageEntry(path string, entry EvidenceEntry) (string, error) {
+	dir := filepath.Join(path, stagingDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create staging directory: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("marshal staged entry: %w", err)
+	}
+	name := fmt.Sprintf("%s-%010d%s", stagingWriterID, stagingWriterSeq.Add(1), stagedFileSuffix)
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("create staged entry: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("write staged entry: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("sync staged entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("close staged entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(dir, name)); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("publish staged entry: %w", err)
+	}
+	return name, nil
+}
+
+func stagedFileNames(path string) ([]string, error) {
+	dirEntries, err := os.ReadDir(filepath.Join(path, stagingDirName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read staging directory: %w", err)
+	}
+	names := make([]string, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		if !de.IsDir() && strings.HasSuffix(de.Name(), stagedFileSuffix) {
+			names = append(names, de.Name())
+		}
+	}
+	return names, nil
+}
+
+type stagedEntry struct {
+	name  string
+	entry EvidenceEntry
+}
+
+// sequenceStagedUnlocked seals staged entries whose key_id matches signer
+// into the chain. Entries staged under other keys are left for a writer
+// holding that key, so every entry stays signed by the key it was staged
+// with. Staged files that do not parse are renamed with a .corrupt suffix
+// so they cannot block other writers. The caller must hold the store lock.
+func sequenceStagedUnlocked(path string, signer Signer) ([]EvidenceEntry, error) {
+	names, err := stagedFileNames(path)
+	if err != nil || len(names) == 0 {
+		return nil, asStoreError(ErrorCodeStoreRead, err)
+	}
+	manifest, err := loadManifestForAppend(path)
+	if err != nil {
+		return nil, asStoreError(ErrorCodeStoreRead, err)
+	}
+	dir := filepath.Join(path, stagingDirName)
+
+	applied := make(map[string]bool, len(manifest.StagedApplied))
+	for _, name := range manifest.StagedApplied {
+		applied[name] = true
+	}
+	keyID := signerKeyID(signer)
+	pending := make([]stagedEntry, 0, len(names))
+	for _, name := range names {
+		if applied[name] {
+			// Sealed by a sequencer that crashed before cleaning up.
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, asStoreError(ErrorCodeStoreRead, fmt.Errorf("read staged entry %s: %w", name, err))
+		}
+		var entry EvidenceEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			_ = os.Rename(filepath.Join(dir, name), filepath.Join(dir, name+quarantineSuffix)) // best-effort: skipped either way
+			continue
+		}
+		if entry.KeyID != keyID {
+			continue
+		}
+		pending = append(pending, stagedEntry{name: name, entry: entry})
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		ti, tj := pending[i].entry.Timestamp, pending[j].entry.Timestamp
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return pending[i].name < pending[j].name
+	})
+
+	// Seal the whole batch before writing anything, so a signer failure
+	// leaves the store untouched.
+	batch := make([]EvidenceEntry, len(pending))
+	lastHash := manifest.LastHash
+	for i, staged := range pending {
+		entry := staged.entry
+		entry.PreviousHash = lastHash
+		if err := sealEntry(&entry, signer); err != nil {
+			return nil, err
+		}
+		batch[i] = entry
+		lastHash = entry.Hash
+	}
+
+	appliedNames := make([]string, len(pending))
+	for i, entry := range batch {
+		if err := appendEntryToSegment(path, &manifest, entry); err != nil {
+			return nil, asStoreError(ErrorCodeStoreWrite, err)
+		}
+		appliedNames[i] = pending[i].name
+	}
+	manifest.StagedApplied = appliedNames
+	if err := writeManifestAtomic(path, manifest); err != nil {
+		return nil, asStoreError(ErrorCodeStoreWrite, err)
+	}
+	for _, name := range appliedNames {
+		_ = os.Remove(filepath.Join(dir, name)) // best-effort: StagedApplied guards against re-sealing
+	}
+	return batch, nil
+}
+
+// findRecentEntryUnlocked looks for entryID starting from the newest segment,
+// where entries sealed by a concurrent sequencer land.
+func findRecentEntryUnlocked(path, entryID string) (EvidenceEntry, bool, error) {
+	_, names, err := orderedSegmentNames(path)
+	if err != nil {
+		return EvidenceEntry{}, false, err
+	}
+	errFound := errors.New("entry_found")
+	for i := len(names) - 1; i >= 0; i-- {
+		var out EvidenceEntry
+		err := streamFileEntries(filepath.Join(path, segmentsDirName, names[i]), func(e EvidenceEntry, _ int) error {
+			if e.EntryID == entryID {
+				out = e
+				return errFound
+			}
+			return nil
+		})
+		if errors.Is(err, errFound) {
+			return out, true, nil
+		}
+		if err != nil {
+			return EvidenceEntry{}, false, err
+		}
+	}
+	return EvidenceEntry{}, false, nil
+}
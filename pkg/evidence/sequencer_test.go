@@ -0,0 +1,269 @@
+package evidence
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"samebits.com/evidra/pkg/evlock"
+)
+
+type failingSigner struct{ *testSigner }
+
+func (s failingSigner) TrySign([]byte) ([]byte, error) { return nil, errors.New("hsm offline") }
+
+func sequencerParams(signer Signer, sessionID string) EntryBuildParams {
+	return EntryBuildParams{
+		Type:      EntryTypeSignal,
+		SessionID: sessionID,
+		TraceID:   sessionID,
+		Actor:     Actor{Type: "agent", ID: "writer", Provenance: "test"},
+		Payload:   []byte(`{"signal_name":"retry_loop"}`),
+		Signer:    signer,
+	}
+}
+
+func stageTestEntry(t *testing.T, dir string, signer Signer) EvidenceEntry {
+	t.Helper()
+	entry, err := prepareEntry(sequencerParams(signer, "staged"))
+	if err != nil {
+		t.Fatalf("prepareEntry: %v", err)
+	}
+	if _, err := stageEntry(dir, entry); err != nil {
+		t.Fatalf("stageEntry: %v", err)
+	}
+	return entry
+}
+
+func TestAppendAtPath_ConcurrentWritersShareOneChain(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := newTestSigner(t)
+
+	const writers, perWriter = 8, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				entry, err := AppendAtPath(dir, sequencerParams(signer, "session"))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if entry.Hash == "" || entry.Signature == "" {
+					errs <- errors.New("returned entry is not sealed")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("AppendAtPath: %v", err)
+	}
+
+	entries, err := ReadAllEntriesAtPath(dir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	if len(entries) != writers*perWriter {
+		t.Fatalf("entries = %d, want %d", len(entries), writers*perWriter)
+	}
+	if err := ValidateChainWithSignatures(dir, signer.pub); err != nil {
+		t.Fatalf("ValidateChainWithSignatures: %v", err)
+	}
+	if n, err := StagedCountAtPath(dir); err != nil || n != 0 {
+		t.Fatalf("staged = %d, %v; want 0", n, err)
+	}
+}
+
+func TestSequenceStagedAtPath_LeavesOtherKeysStaged(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signerA := newTestSigner(t)
+	signerB := newTestSigner(t)
+
+	stageTestEntry(t, dir, signerB)
+	first := stageTestEntry(t, dir, signerA)
+
+	batch, err := SequenceStagedAtPath(dir, signerA)
+	if err != nil {
+		t.Fatalf("SequenceStagedAtPath(A): %v", err)
+	}
+	if len(batch) != 1 || batch[0].EntryID != first.EntryID {
+		t.Fatalf("batch = %+v, want only A's entry", batch)
+	}
+	if n, _ := StagedCountAtPath(dir); n != 1 {
+		t.Fatalf("staged = %d, want 1 (B's entry)", n)
+	}
+
+	if _, err := SequenceStagedAtPath(dir, signerB); err != nil {
+		t.Fatalf("SequenceStagedAtPath(B): %v", err)
+	}
+	if err := ValidateChainWithKeyring(dir, NewKeyring(signerA.pub, signerB.pub)); err != nil {
+		t.Fatalf("ValidateChainWithKeyring: %v", err)
+	}
+	entries, err := ReadAllEntriesAtPath(dir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	if len(entries) != 2 || entries[1].PreviousHash != entries[0].Hash {
+		t.Fatalf("entries not linked: %+v", entries)
+	}
+}
+
+func TestSequenceStagedAtPath_DoesNotReapplyAfterCrash(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := newTestSigner(t)
+	stageTestEntry(t, dir, signer)
+
+	names, err := stagedFileNames(dir)
+	if err != nil || len(names) != 1 {
+		t.Fatalf("stagedFileNames = %v, %v", names, err)
+	}
+	stagedPath := filepath.Join(dir, stagingDirName, names[0])
+	data, err := os.ReadFile(stagedPath)
+	if err != nil {
+		t.Fatalf("read staged entry: %v", err)
+	}
+	if _, err := SequenceStagedAtPath(dir, signer); err != nil {
+		t.Fatalf("SequenceStagedAtPath: %v", err)
+	}
+	// Simulate a crash between the manifest commit and staging cleanup.
+	if err := os.WriteFile(stagedPath, data, 0o644); err != nil {
+		t.Fatalf("restore staged entry: %v", err)
+	}
+
+	batch, err := SequenceStagedAtPath(dir, signer)
+	if err != nil {
+		t.Fatalf("SequenceStagedAtPath after crash: %v", err)
+	}
+	if len(batch) != 0 {
+		t.Fatalf("re-sealed %d entries", len(batch))
+	}
+	entries, err := ReadAllEntriesAtPath(dir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	if _, err := os.Stat(stagedPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("applied staged file not removed: %v", err)
+	}
+}
+
+func TestAppendAtPath_SignerFailureLeavesStoreUntouched(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := failingSigner{newTestSigner(t)}
+
+	failed, err := AppendAtPath(dir, sequencerParams(signer, "s"))
+	if err == nil {
+		t.Fatal("expected signer error")
+	}
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if manifest.RecordsTotal != 0 {
+		t.Fatalf("records_total = %d, want 0", manifest.RecordsTotal)
+	}
+	if n, _ := StagedCountAtPath(dir); n != 0 {
+		t.Fatalf("staged = %d, want the failed entry discarded", n)
+	}
+
+	// A healthy signer with the same key must not seal the failed entry.
+	next, err := AppendAtPath(dir, sequencerParams(signer.testSigner, "next"))
+	if err != nil {
+		t.Fatalf("AppendAtPath: %v", err)
+	}
+	entries, err := ReadAllEntriesAtPath(dir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	if len(entries) != 1 || entries[0].EntryID != next.EntryID || entries[0].EntryID == failed.EntryID {
+		t.Fatalf("chain = %+v, want only the healthy entry", entries)
+	}
+}
+
+func TestAppendAtPath_QuarantinesCorruptStagedFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	signer := newTestSigner(t)
+	stagingDir := filepath.Join(dir, stagingDirName)
+	if err := os.MkdirAll(stagingDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(stagingDir, "other-0000000001"+stagedFileSuffix), []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("write corrupt staged file: %v", err)
+	}
+
+	for _, session := range []string{"a", "b"} {
+		if _, err := AppendAtPath(dir, sequencerParams(signer, session)); err != nil {
+			t.Fatalf("AppendAtPath(%s): %v", session, err)
+		}
+	}
+	if n, _ := StagedCountAtPath(dir); n != 0 {
+		t.Fatalf("staged = %d, want 0", n)
+	}
+	if n, _ := QuarantinedCountAtPath(dir); n != 1 {
+		t.Fatalf("quarantined = %d, want 1", n)
+	}
+}
+
+func TestAppendAtPath_LegacyFileIsReadError(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "legacy.log")
+	if err := os.WriteFile(path, []byte("legacy"), 0o644); err != nil {
+		t.Fatalf("write legacy file: %v", err)
+	}
+	if _, err := AppendAtPath(path, sequencerParams(newTestSigner(t), "s")); ErrorCode(err) != ErrorCodeStoreRead {
+		t.Fatalf("error = %v, want %s", err, ErrorCodeStoreRead)
+	}
+}
+
+func TestAppendAtPath_StagesWhenLockHeld(t *testing.T) {
+	t.Setenv(lockTimeoutEnv, "20")
+	t.Setenv(sequenceTimeoutEnv, "60")
+	dir := t.TempDir()
+	signer := newTestSigner(t)
+	if _, err := AppendAtPath(dir, sequencerParams(signer, "first")); err != nil {
+		t.Fatalf("AppendAtPath: %v", err)
+	}
+
+	lock, err := evlock.Acquire(filepath.Join(dir, lockFileName), 0)
+	if err != nil {
+		t.Fatalf("hold store lock: %v", err)
+	}
+	staged, err := AppendAtPath(dir, sequencerParams(signer, "second"))
+	if ErrorCode(err) != ErrorCodeEntryStaged {
+		t.Fatalf("error = %v, want %s", err, ErrorCodeEntryStaged)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("release lock: %v", err)
+	}
+
+	// The next writer seals the staged entry ahead of its own.
+	third, err := AppendAtPath(dir, sequencerParams(signer, "third"))
+	if err != nil {
+		t.Fatalf("AppendAtPath: %v", err)
+	}
+	entries, err := ReadAllEntriesAtPath(dir)
+	if err != nil {
+		t.Fatalf("ReadAllEntriesAtPath: %v", err)
+	}
+	if len(entries) != 3 || entries[1].EntryID != staged.EntryID || entries[2].EntryID != third.EntryID {
+		t.Fatalf("unexpected chain order: %+v", entries)
+	}
+	if err := ValidateChainWithSignatures(dir, signer.pub); err != nil {
+		t.Fatalf("ValidateChainWithSignatures: %v", err)
+	}
+}
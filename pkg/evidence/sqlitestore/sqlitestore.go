@@ -0,0 +1,363 @@
+// Package sqlitestore provides a SQLite evidence store for pkg/evidence.
+//
+// Importing the package registers the "sqlite" scheme, so an evidence path
+// such as "sqlite:///var/lib/evidra/evidence.db" (absolute) or
+// "sqlite:evidence.db" (relative) selects this backend for every
+// evidence.*AtPath function. Entries are stored verbatim as JSON alongside
+// indexed columns for session, actor, intent, type, and timestamp, so
+// scorecard and explain queries over large histories do not scan the chain.
+package sqlitestore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"samebits.com/evidra/pkg/evidence"
+)
+
+// Scheme is the evidence path scheme handled by this package.
+const Scheme = "sqlite"
+
+const (
+	schemaVersion        = 1
+	defaultBusyTimeoutMS = 30000
+	busyTimeoutEnv       = "EVIDRA_EVIDENCE_SEQUENCE_TIMEOUT_MS"
+	driverName           = "sqlite"
+	entriesTableDDL      = `CREATE TABLE IF NOT EXISTS entries (
+	seq           INTEGER PRIMARY KEY,
+	entry_id      TEXT NOT NULL UNIQUE,
+	type          TEXT NOT NULL,
+	session_id    TEXT NOT NULL DEFAULT '',
+	actor_id      TEXT NOT NULL DEFAULT '',
+	intent_digest TEXT NOT NULL DEFAULT '',
+	ts_unix_nano  INTEGER NOT NULL,
+	hash          TEXT NOT NULL,
+	previous_hash TEXT NOT NULL,
+	entry_json    TEXT NOT NULL
+)`
+)
+
+var indexDDL = []string{
+	`CREATE INDEX IF NOT EXISTS entries_session ON entries (session_id, seq)`,
+	`CREATE INDEX IF NOT EXISTS entries_actor ON entries (actor_id, ts_unix_nano)`,
+	`CREATE INDEX IF NOT EXISTS entries_intent ON entries (intent_digest, seq)`,
+	`CREATE INDEX IF NOT EXISTS entries_type ON entries (type, seq)`,
+	`CREATE INDEX IF NOT EXISTS entries_ts ON entries (ts_unix_nano)`,
+}
+
+func init() {
+	evidence.RegisterBackend(Scheme, func(dsn string) (evidence.Backend, error) {
+		return Open(dsn)
+	})
+}
+
+// Store is a SQLite-backed evidence.Backend.
+type Store struct {
+	db   *sql.DB
+	path string
+}
+
+var _ evidence.Backend = (*Store)(nil)
+
+// Open opens (creating if needed) the SQLite evidence database at path.
+// Writers wait up to EVIDRA_EVIDENCE_SEQUENCE_TIMEOUT_MS (default 30s) for
+// the database write lock before failing with evidence_store_busy.
+func Open(path string) (*Store, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("sqlite evidence store path is empty")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		return nil, fmt.Errorf("create evidence directory: %w", err)
+	}
+	dsn := abs + "?_txlock=immediate" +
+		"&_pragma=busy_timeout(" + strconv.Itoa(busyTimeoutMS()) + ")" +
+		"&_pragma=journal_mode(WAL)" +
+		"&_pragma=synchronous(FULL)"
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{db: db, path: abs}
+	if err := s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func busyTimeoutMS() int {
+	ms, err := strconv.Atoi(strings.TrimSpace(os.Getenv(busyTimeoutEnv)))
+	if err != nil || ms <= 0 {
+		return defaultBusyTimeoutMS
+	}
+	return ms
+}
+
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return storeErr("read schema version", err)
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("evidence database %s has schema version %d; this build supports %d", s.path, version, schemaVersion)
+	}
+	stmts := append([]string{entriesTableDDL}, indexDDL...)
+	stmts = append(stmts, fmt.Sprintf(`PRAGMA user_version = %d`, schemaVersion))
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return storeErr("migrate schema", err)
+		}
+	}
+	return nil
+}
+
+// Path returns the absolute database file path.
+func (s *Store) Path() string { return s.path }
+
+// Dir returns the directory holding the database file.
+func (s *Store) Dir() string { return filepath.Dir(s.path) }
+
+// Close closes the database.
+func (s *Store) Close() error { return s.db.Close() }
+
+// AppendEntries inserts entries in order within one transaction.
+func (s *Store) AppendEntries(entries []evidence.EvidenceEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return storeErr("begin append", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, e := range entries {
+		if err := insertEntry(tx, e); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return storeErr("commit append", err)
+	}
+	return nil
+}
+
+// AppendLinked reads the chain tip and inserts the entry built from it in a
+// single write transaction, so concurrent writers never fork the chain.
+func (s *Store) AppendLinked(build func(count int, lastHash string) (evidence.EvidenceEntry, error)) (evidence.EvidenceEntry, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return evidence.EvidenceEntry{}, storeErr("begin append", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	count, lastHash, err := tip(tx)
+	if err != nil {
+		return evidence.EvidenceEntry{}, err
+	}
+	entry, err := build(count, lastHash)
+	if err != nil {
+		return evidence.EvidenceEntry{}, err
+	}
+	if err := insertEntry(tx, entry); err != nil {
+		return evidence.EvidenceEntry{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return evidence.EvidenceEntry{}, storeErr("commit append", err)
+	}
+	return entry, nil
+}
+
+// Tip returns the number of entries and the hash of the last one.
+func (s *Store) Tip() (int, string, error) {
+	return tip(s.db)
+}
+
+// ForEach calls fn for every entry in chain order. The scan reads one
+// snapshot, so entries appended meanwhile are not visited.
+func (s *Store) ForEach(fn func(evidence.EvidenceEntry) error) error {
+	rows, err := s.db.Query(`SELECT entry_json FROM entries ORDER BY seq`)
+	if err != nil {
+		return storeErr("read entries", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return storeErr("read entries", err)
+	}
+	return nil
+}
+
+// FindByID returns the entry with the given entry_id.
+func (s *Store) FindByID(entryID string) (evidence.EvidenceEntry, bool, error) {
+	e, err := scanEntry(s.db.QueryRow(`SELECT entry_json FROM entries WHERE entry_id = ?`, entryID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return evidence.EvidenceEntry{}, false, nil
+	}
+	if err != nil {
+		return evidence.EvidenceEntry{}, false, err
+	}
+	return e, true, nil
+}
+
+// Query returns entries matching q in chain order using the column indexes.
+// Filters without a column are applied to the decoded entries.
+func (s *Store) Query(q evidence.EntryQuery) ([]evidence.EvidenceEntry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if q.SessionID != "" {
+		where = append(where, "session_id = ?")
+		args = append(args, q.SessionID)
+	}
+	if q.ActorID != "" {
+		where = append(where, "actor_id = ?")
+		args = append(args, q.ActorID)
+	}
+	if q.IntentDigest != "" {
+		where = append(where, "intent_digest = ?")
+		args = append(args, q.IntentDigest)
+	}
+	if q.After != "" {
+		where = append(where, "seq > (SELECT seq FROM entries WHERE entry_id = ?)")
+		args = append(args, q.After)
+	}
+	if len(q.Types) > 0 {
+		where = append(where, "type IN (?"+strings.Repeat(", ?", len(q.Types)-1)+")")
+		for _, t := range q.Types {
+			args = append(args, string(t))
+		}
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "ts_unix_nano >= ?")
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "ts_unix_nano < ?")
+		args = append(args, q.Until.UnixNano())
+	}
+
+	stmt := `SELECT entry_json FROM entries`
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	stmt += " ORDER BY seq"
+	filtered := q.HasPayloadFilters() || q.ArtifactDigest != "" || len(q.ScopeDimensions) > 0
+	if q.Limit > 0 && !filtered {
+		stmt += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.Query(stmt, args...)
+	if err != nil {
+		return nil, storeErr("query entries", err)
+	}
+	defer rows.Close()
+	out := make([]evidence.EvidenceEntry, 0)
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		if filtered && !q.Matches(e) {
+			continue
+		}
+		out = append(out, e)
+		if q.Limit > 0 && len(out) >= q.Limit {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storeErr("query entries", err)
+	}
+	return out, nil
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func tip(q queryRower) (int, string, error) {
+	var (
+		seq  int
+		hash string
+	)
+	err := q.QueryRow(`SELECT seq, hash FROM entries ORDER BY seq DESC LIMIT 1`).Scan(&seq, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", storeErr("read chain tip", err)
+	}
+	// Rows are never deleted, so the last seq is the entry count.
+	return seq, hash, nil
+}
+
+func insertEntry(tx *sql.Tx, e evidence.EvidenceEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal entry %s: %w", e.EntryID, err)
+	}
+	_, err = tx.Exec(`INSERT INTO entries
+		(entry_id, type, session_id, actor_id, intent_digest, ts_unix_nano, hash, previous_hash, entry_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.EntryID, string(e.Type), e.SessionID, e.Actor.ID, e.IntentDigest,
+		e.Timestamp.UnixNano(), e.Hash, e.PreviousHash, string(data))
+	if err != nil {
+		return storeErr("insert entry "+e.EntryID, err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row rowScanner) (evidence.EvidenceEntry, error) {
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return evidence.EvidenceEntry{}, err
+		}
+		return evidence.EvidenceEntry{}, storeErr("read entry", err)
+	}
+	var e evidence.EvidenceEntry
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		return evidence.EvidenceEntry{}, fmt.Errorf("parse stored entry: %w", err)
+	}
+	return e, nil
+}
+
+// storeErr wraps err, mapping SQLite lock contention to the store-busy
+// error code used by the segment store.
+func storeErr(op string, err error) error {
+	var sqlErr *sqlite.Error
+	if errors.As(err, &sqlErr) {
+		switch sqlErr.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+			return &evidence.StoreError{
+				Code:    evidence.ErrorCodeStoreBusy,
+				Message: "Evidence store is busy (another writer is running)",
+				Err:     err,
+			}
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
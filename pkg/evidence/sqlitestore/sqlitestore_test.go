@@ -0,0 +1,234 @@
+package sqlitestore_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"samebits.com/evidra/pkg/evidence"
+	"samebits.com/evidra/pkg/evidence/sqlitestore"
+)
+
+type testSigner struct {
+	priv ed25519.PrivateKey
+	pub  ed25519.PublicKey
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testSigner{priv: priv, pub: pub}
+}
+
+func (s *testSigner) Sign(payload []byte) []byte      { return ed25519.Sign(s.priv, payload) }
+func (s *testSigner) Verify(payload, sig []byte) bool { return ed25519.Verify(s.pub, payload, sig) }
+func (s *testSigner) PublicKey() ed25519.PublicKey    { return s.pub }
+
+func storeURI(t *testing.T) string {
+	t.Helper()
+	return sqlitestore.Scheme + "://" + filepath.Join(t.TempDir(), "evidence.db")
+}
+
+func intentDigest(actorID string) string {
+	sum := sha256.Sum256([]byte(actorID))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func params(signer evidence.Signer, sessionID, actorID string) evidence.EntryBuildParams {
+	return evidence.EntryBuildParams{
+		Type:         evidence.EntryTypeSignal,
+		SessionID:    sessionID,
+		TraceID:      sessionID,
+		Actor:        evidence.Actor{Type: "agent", ID: actorID, Provenance: "test"},
+		IntentDigest: intentDigest(actorID),
+		Payload:      []byte(`{"signal_name":"retry_loop"}`),
+		Signer:       signer,
+	}
+}
+
+func TestAppendAtPath_ConcurrentWritersShareOneChain(t *testing.T) {
+	t.Parallel()
+	path := storeURI(t)
+	signer := newTestSigner(t)
+
+	const writers, perWriter = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if _, err := evidence.AppendAtPath(path, params(signer, "s", "writer")); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("AppendAtPath: %v", err)
+	}
+
+	manifest, err := evidence.LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if manifest.RecordsTotal != writers*perWriter {
+		t.Fatalf("records_total = %d, want %d", manifest.RecordsTotal, writers*perWriter)
+	}
+	if err := evidence.ValidateChainWithSignatures(path, signer.pub); err != nil {
+		t.Fatalf("ValidateChainWithSignatures: %v", err)
+	}
+	if n, err := evidence.StagedCountAtPath(path); err != nil || n != 0 {
+		t.Fatalf("staged = %d, %v; want 0", n, err)
+	}
+}
+
+func TestQueryEntriesAtPath_UsesIndexedFilters(t *testing.T) {
+	t.Parallel()
+	path := storeURI(t)
+	signer := newTestSigner(t)
+
+	start := time.Now().UTC()
+	for _, p := range []evidence.EntryBuildParams{
+		params(signer, "s1", "alice"),
+		params(signer, "s1", "bob"),
+		params(signer, "s2", "alice"),
+	} {
+		if _, err := evidence.AppendAtPath(path, p); err != nil {
+			t.Fatalf("AppendAtPath: %v", err)
+		}
+	}
+	if _, err := evidence.AppendTreeHeadAtPath(path, params(signer, "s3", "evidra")); err != nil {
+		t.Fatalf("AppendTreeHeadAtPath: %v", err)
+	}
+	rx := params(signer, "s4", "carol")
+	rx.Type = evidence.EntryTypePrescribe
+	rx.Payload = []byte(`{"prescription_id":"rx","canonical_action":{"tool":"kubectl","operation_class":"destroy","scope_class":"production"},"effective_risk":"critical"}`)
+	rx.ScopeDimensions = map[string]string{"cluster": "prod-eu", "namespace": "payments"}
+	if _, err := evidence.AppendAtPath(path, rx); err != nil {
+		t.Fatalf("AppendAtPath: %v", err)
+	}
+	all, err := evidence.QueryEntriesAtPath(path, evidence.EntryQuery{})
+	if err != nil || len(all) != 5 {
+		t.Fatalf("QueryEntriesAtPath = %d entries, %v", len(all), err)
+	}
+
+	tests := []struct {
+		name  string
+		query evidence.EntryQuery
+		want  int
+	}{
+		{name: "all", query: evidence.EntryQuery{}, want: 5},
+		{name: "session", query: evidence.EntryQuery{SessionID: "s1"}, want: 2},
+		{name: "actor", query: evidence.EntryQuery{ActorID: "alice"}, want: 2},
+		{name: "actor and session", query: evidence.EntryQuery{ActorID: "alice", SessionID: "s2"}, want: 1},
+		{name: "intent", query: evidence.EntryQuery{IntentDigest: intentDigest("bob")}, want: 1},
+		{name: "type", query: evidence.EntryQuery{Types: []evidence.EntryType{evidence.EntryTypeTreeHead}}, want: 1},
+		{name: "since", query: evidence.EntryQuery{Since: start.Add(-time.Minute)}, want: 5},
+		{name: "until", query: evidence.EntryQuery{Until: start.Add(-time.Minute)}, want: 0},
+		{name: "limit", query: evidence.EntryQuery{Limit: 3}, want: 3},
+		{name: "after", query: evidence.EntryQuery{After: all[1].EntryID}, want: 3},
+		{name: "after with limit", query: evidence.EntryQuery{After: all[1].EntryID, ActorID: "alice", Limit: 1}, want: 1},
+		{name: "tool", query: evidence.EntryQuery{Tool: "kubectl", ScopeClass: "production"}, want: 1},
+		{name: "risk", query: evidence.EntryQuery{EffectiveRisks: []string{"high", "critical"}, Limit: 1}, want: 1},
+		{name: "verdict", query: evidence.EntryQuery{Verdicts: []string{"declined"}}, want: 0},
+		{name: "scope dimensions", query: evidence.EntryQuery{ScopeDimensions: map[string]string{"cluster": "prod-eu"}}, want: 1},
+		{name: "scope dimension mismatch", query: evidence.EntryQuery{ScopeDimensions: map[string]string{"cluster": "prod-us"}}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evidence.QueryEntriesAtPath(path, tt.query)
+			if err != nil {
+				t.Fatalf("QueryEntriesAtPath: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("got %d entries, want %d", len(got), tt.want)
+			}
+			for _, e := range got {
+				if !tt.query.Matches(e) {
+					t.Fatalf("entry %s does not match query", e.EntryID)
+				}
+			}
+		})
+	}
+
+	if err := evidence.ValidateChainAtPath(path); err != nil {
+		t.Fatalf("ValidateChainAtPath: %v", err)
+	}
+}
+
+func TestCopyEntries_RoundTripsThroughJSONL(t *testing.T) {
+	t.Parallel()
+	signer := newTestSigner(t)
+	jsonlSrc := t.TempDir()
+	for i := 0; i < 5; i++ {
+		if _, err := evidence.AppendAtPath(jsonlSrc, params(signer, "s", "alice")); err != nil {
+			t.Fatalf("AppendAtPath: %v", err)
+		}
+	}
+
+	db := storeURI(t)
+	if n, err := evidence.CopyEntries(db, jsonlSrc); err != nil || n != 5 {
+		t.Fatalf("import = %d, %v", n, err)
+	}
+	if _, err := evidence.CopyEntries(db, jsonlSrc); err == nil {
+		t.Fatal("expected error importing into a non-empty store")
+	}
+	if err := evidence.ValidateChainWithSignatures(db, signer.pub); err != nil {
+		t.Fatalf("ValidateChainWithSignatures: %v", err)
+	}
+
+	jsonlDst := t.TempDir()
+	if n, err := evidence.CopyEntries(jsonlDst, db); err != nil || n != 5 {
+		t.Fatalf("export = %d, %v", n, err)
+	}
+	want, err := evidence.ReadAllEntriesAtPath(jsonlSrc)
+	if err != nil {
+		t.Fatalf("read source: %v", err)
+	}
+	got, err := evidence.ReadAllEntriesAtPath(jsonlDst)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("exported entries differ from the original chain")
+	}
+	if err := evidence.ValidateChainWithSignatures(jsonlDst, signer.pub); err != nil {
+		t.Fatalf("ValidateChainWithSignatures(export): %v", err)
+	}
+
+	// Appends continue the imported chain.
+	next, err := evidence.AppendAtPath(db, params(signer, "s", "alice"))
+	if err != nil {
+		t.Fatalf("AppendAtPath: %v", err)
+	}
+	if next.PreviousHash != want[len(want)-1].Hash {
+		t.Fatalf("previous_hash = %s, want %s", next.PreviousHash, want[len(want)-1].Hash)
+	}
+	found, ok, err := evidence.FindEntryByID(db, next.EntryID)
+	if err != nil || !ok || found.Hash != next.Hash {
+		t.Fatalf("FindEntryByID = %v, %v, %v", found.EntryID, ok, err)
+	}
+}
+
+func TestStoreDir_IsDatabaseDirectory(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := "sqlite://" + filepath.Join(dir, "evidence.db")
+	if got := evidence.StoreDir(path); got != dir {
+		t.Fatalf("StoreDir = %q, want %q", got, dir)
+	}
+}
@@ -0,0 +1,171 @@
+package evidence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHeadInconsistent is returned when the local chain no longer matches a
+// previously published tree head (history was truncated or rewritten).
+var ErrHeadInconsistent = errors.New("evidence_head_inconsistent")
+
+// TreeHead is a point-in-time commitment to the evidence chain: the number of
+// entries it covers and the hash of the last covered entry. Published heads
+// let third parties detect history rewrites by the signing key holder.
+type TreeHead struct {
+	EntryCount int       `json:"entry_count"`
+	LastHash   string    `json:"last_hash"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// AppendTreeHeadAtPath appends a signed tree_head entry covering every entry
+// currently in the store, after sealing entries staged under p.Signer's key.
+// The store lock is held between reading the manifest and appending, so the
+// head always commits to the exact chain tip.
+// Type, Payload and PreviousHash in p are overwritten.
+func AppendTreeHeadAtPath(path string, p EntryBuildParams) (EvidenceEntry, error) {
+	if b, ok, err := backendFor(path); ok {
+		if err != nil {
+			return EvidenceEntry{}, err
+		}
+		return b.AppendLinked(func(count int, lastHash string) (EvidenceEntry, error) {
+			payload, err := json.Marshal(TreeHeadPayload{EntryCount: count, LastHash: lastHash})
+			if err != nil {
+				return EvidenceEntry{}, fmt.Errorf("marshal tree head payload: %w", err)
+			}
+			p.Type = EntryTypeTreeHead
+			p.Payload = payload
+			p.PreviousHash = lastHash
+			return BuildEntry(p)
+		})
+	}
+	var entry EvidenceEntry
+	err := storeLock(path, func() error {
+		if p.Signer != nil {
+			if _, err := sequenceStagedUnlocked(path, p.Signer); err != nil {
+				return err
+			}
+		}
+		manifest, err := loadOrInitManifest(path, segmentMaxBytesFromEnv(), true)
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(TreeHeadPayload{
+			EntryCount: manifest.RecordsTotal,
+			LastHash:   manifest.LastHash,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal tree head payload: %w", err)
+		}
+		p.Type = EntryTypeTreeHead
+		p.Payload = payload
+		p.PreviousHash = manifest.LastHash
+		entry, err = BuildEntry(p)
+		if err != nil {
+			return err
+		}
+		return appendEntryUnlocked(path, entry)
+	})
+	if err != nil {
+		return EvidenceEntry{}, err
+	}
+	cacheEntryByID(path, entry)
+	return entry, nil
+}
+
+// TreeHeadFromEntry extracts the TreeHead committed to by a tree_head entry.
+func TreeHeadFromEntry(entry EvidenceEntry) (TreeHead, error) {
+	if entry.Type != EntryTypeTreeHead {
+		return TreeHead{}, fmt.Errorf("entry %s is %q, not %q", entry.EntryID, entry.Type, EntryTypeTreeHead)
+	}
+	var payload TreeHeadPayload
+	if err := json.Unmarshal(entry.Payload, &payload); err != nil {
+		return TreeHead{}, fmt.Errorf("decode tree head payload: %w", err)
+	}
+	return TreeHead{
+		EntryCount: payload.EntryCount,
+		LastHash:   payload.LastHash,
+		Timestamp:  entry.Timestamp,
+	}, nil
+}
+
+// LatestTreeHeadAtPath returns the most recent tree_head entry in the store.
+// The boolean is false when no head has been written yet.
+func LatestTreeHeadAtPath(path string) (EvidenceEntry, bool, error) {
+	var latest EvidenceEntry
+	found := false
+	err := ForEachEntryAtPath(path, func(e EvidenceEntry) error {
+		if e.Type == EntryTypeTreeHead {
+			latest = e
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return EvidenceEntry{}, false, err
+	}
+	return latest, found, nil
+}
+
+// ValidatePublishedHeadsAtPath validates hash chain integrity and checks
+// that every published head is a prefix of the local chain: the chain must
+// have at least EntryCount entries and entry EntryCount-1 must hash to
+// LastHash.
+func ValidatePublishedHeadsAtPath(root string, heads []TreeHead) error {
+	entries, err := ReadAllEntriesAtPath(root)
+	if err != nil {
+		return fmt.Errorf("validate published heads: %w", err)
+	}
+	if err := validateChainEntries(entries); err != nil {
+		return err
+	}
+	return validateHeadConsistency(entries, heads)
+}
+
+func validateHeadConsistency(entries []EvidenceEntry, heads []TreeHead) error {
+	for _, head := range heads {
+		if head.EntryCount < 0 {
+			return fmt.Errorf("%w: published head has negative entry_count %d", ErrHeadInconsistent, head.EntryCount)
+		}
+		if head.EntryCount > len(entries) {
+			return fmt.Errorf("%w: published head covers %d entries but chain has %d",
+				ErrHeadInconsistent, head.EntryCount, len(entries))
+		}
+		if head.EntryCount == 0 {
+			if head.LastHash != "" {
+				return fmt.Errorf("%w: empty published head has last_hash %s", ErrHeadInconsistent, head.LastHash)
+			}
+			continue
+		}
+		got := entries[head.EntryCount-1].Hash
+		if got != head.LastHash {
+			return fmt.Errorf("%w: entry %d hash %s does not match published last_hash %s",
+				ErrHeadInconsistent, head.EntryCount-1, got, head.LastHash)
+		}
+	}
+	return nil
+}
+
+func validateTreeHeadEntry(index int, entry EvidenceEntry) error {
+	head, err := TreeHeadFromEntry(entry)
+	if err != nil {
+		return &ChainValidationError{Index: index, EventID: entry.EntryID, Message: err.Error()}
+	}
+	if head.EntryCount != index {
+		return &ChainValidationError{
+			Index:   index,
+			EventID: entry.EntryID,
+			Message: fmt.Sprintf("tree head entry_count mismatch: got %d, want %d", head.EntryCount, index),
+		}
+	}
+	if head.LastHash != entry.PreviousHash {
+		return &ChainValidationError{
+			Index:   index,
+			EventID: entry.EntryID,
+			Message: fmt.Sprintf("tree head last_hash mismatch: got %s, want %s", head.LastHash, entry.PreviousHash),
+		}
+	}
+	return nil
+}
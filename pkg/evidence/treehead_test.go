@@ -0,0 +1,195 @@
+package evidence
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func appendTestChain(t *testing.T, dir string, signer Signer, n int) []EvidenceEntry {
+	t.Helper()
+	out := make([]EvidenceEntry, 0, n)
+	for i := 0; i < n; i++ {
+		lastHash, err := LastHashAtPath(dir)
+		if err != nil {
+			t.Fatalf("LastHashAtPath: %v", err)
+		}
+		entry, err := BuildEntry(EntryBuildParams{
+			Type:         EntryTypeAnnotation,
+			SessionID:    "session-heads",
+			TraceID:      "01TRACE",
+			Actor:        Actor{Type: "ci", ID: "test", Provenance: "cli"},
+			Payload:      json.RawMessage(`{"key":"k","value":"v"}`),
+			PreviousHash: lastHash,
+			SpecVersion:  "0.3.0",
+			Signer:       signer,
+		})
+		if err != nil {
+			t.Fatalf("BuildEntry %d: %v", i, err)
+		}
+		if err := AppendEntryAtPath(dir, entry); err != nil {
+			t.Fatalf("AppendEntryAtPath %d: %v", i, err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func treeHeadParams(signer Signer) EntryBuildParams {
+	return EntryBuildParams{
+		SessionID:   "session-heads",
+		TraceID:     "01TRACE",
+		Actor:       Actor{Type: "cli", ID: "evidra", Provenance: "cli"},
+		SpecVersion: "0.3.0",
+		Signer:      signer,
+	}
+}
+
+func TestAppendTreeHeadAtPath_CommitsToChainTip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	signer := newTestSigner(t)
+	chain := appendTestChain(t, dir, signer, 3)
+
+	entry, err := AppendTreeHeadAtPath(dir, treeHeadParams(signer))
+	if err != nil {
+		t.Fatalf("AppendTreeHeadAtPath: %v", err)
+	}
+	if entry.PreviousHash != chain[2].Hash {
+		t.Fatalf("previous_hash = %s, want %s", entry.PreviousHash, chain[2].Hash)
+	}
+	head, err := TreeHeadFromEntry(entry)
+	if err != nil {
+		t.Fatalf("TreeHeadFromEntry: %v", err)
+	}
+	if head.EntryCount != 3 || head.LastHash != chain[2].Hash {
+		t.Fatalf("head = %+v", head)
+	}
+
+	latest, found, err := LatestTreeHeadAtPath(dir)
+	if err != nil || !found {
+		t.Fatalf("LatestTreeHeadAtPath found=%v err=%v", found, err)
+	}
+	if latest.EntryID != entry.EntryID {
+		t.Fatalf("latest head = %s, want %s", latest.EntryID, entry.EntryID)
+	}
+	if err := ValidateChainWithSignatures(dir, signer.PublicKey()); err != nil {
+		t.Fatalf("ValidateChainWithSignatures: %v", err)
+	}
+}
+
+func TestAppendTreeHeadAtPath_EmptyStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	entry, err := AppendTreeHeadAtPath(dir, treeHeadParams(newTestSigner(t)))
+	if err != nil {
+		t.Fatalf("AppendTreeHeadAtPath: %v", err)
+	}
+	head, err := TreeHeadFromEntry(entry)
+	if err != nil {
+		t.Fatalf("TreeHeadFromEntry: %v", err)
+	}
+	if head.EntryCount != 0 || head.LastHash != "" {
+		t.Fatalf("head = %+v", head)
+	}
+	if err := ValidateChainAtPath(dir); err != nil {
+		t.Fatalf("ValidateChainAtPath: %v", err)
+	}
+}
+
+func TestValidateChain_RejectsForgedTreeHead(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	signer := newTestSigner(t)
+	chain := appendTestChain(t, dir, signer, 2)
+
+	payload, _ := json.Marshal(TreeHeadPayload{EntryCount: 5, LastHash: chain[1].Hash})
+	forged, err := BuildEntry(EntryBuildParams{
+		Type:         EntryTypeTreeHead,
+		SessionID:    "session-heads",
+		TraceID:      "01TRACE",
+		Actor:        Actor{Type: "cli", ID: "evidra", Provenance: "cli"},
+		Payload:      payload,
+		PreviousHash: chain[1].Hash,
+		Signer:       signer,
+	})
+	if err != nil {
+		t.Fatalf("BuildEntry: %v", err)
+	}
+	if err := AppendEntryAtPath(dir, forged); err != nil {
+		t.Fatalf("AppendEntryAtPath: %v", err)
+	}
+
+	err = ValidateChainAtPath(dir)
+	var chainErr *ChainValidationError
+	if !errors.As(err, &chainErr) || !strings.Contains(chainErr.Message, "entry_count mismatch") {
+		t.Fatalf("expected entry_count mismatch, got %v", err)
+	}
+}
+
+func TestValidatePublishedHeadsAtPath(t *testing.T) {
+	t.Parallel()
+
+	signer := newTestSigner(t)
+	dir := t.TempDir()
+	chain := appendTestChain(t, dir, signer, 3)
+
+	good := []TreeHead{
+		{EntryCount: 0},
+		{EntryCount: 2, LastHash: chain[1].Hash},
+		{EntryCount: 3, LastHash: chain[2].Hash},
+	}
+	if err := ValidatePublishedHeadsAtPath(dir, good); err != nil {
+		t.Fatalf("ValidatePublishedHeadsAtPath: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		head TreeHead
+	}{
+		{name: "beyond chain", head: TreeHead{EntryCount: 4, LastHash: chain[2].Hash}},
+		{name: "hash mismatch", head: TreeHead{EntryCount: 2, LastHash: chain[2].Hash}},
+		{name: "empty with hash", head: TreeHead{EntryCount: 0, LastHash: chain[0].Hash}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidatePublishedHeadsAtPath(dir, []TreeHead{tt.head})
+			if !errors.Is(err, ErrHeadInconsistent) {
+				t.Fatalf("expected ErrHeadInconsistent, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatePublishedHeadsAtPath_DetectsRewrittenHistory(t *testing.T) {
+	t.Parallel()
+
+	signer := newTestSigner(t)
+	dir := t.TempDir()
+	chain := appendTestChain(t, dir, signer, 2)
+	published := []TreeHead{{EntryCount: 2, LastHash: chain[1].Hash}}
+
+	// The key holder rebuilds the store from scratch: the new chain is
+	// internally valid and correctly signed, but diverges from the head.
+	if err := os.RemoveAll(filepath.Join(dir, segmentsDirName)); err != nil {
+		t.Fatalf("remove segments: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, manifestFileName)); err != nil {
+		t.Fatalf("remove manifest: %v", err)
+	}
+	appendTestChain(t, dir, signer, 3)
+
+	if err := ValidateChainWithSignatures(dir, signer.PublicKey()); err != nil {
+		t.Fatalf("rewritten chain should be self-consistent: %v", err)
+	}
+	if err := ValidatePublishedHeadsAtPath(dir, published); !errors.Is(err, ErrHeadInconsistent) {
+		t.Fatalf("expected ErrHeadInconsistent, got %v", err)
+	}
+}
@@ -17,6 +17,10 @@ type StoreManifest struct {
 	RecordsTotal    int      `json:"records_total"`
 	LastHash        string   `json:"last_hash"`
 	Notes           string   `json:"notes"`
+	// StagedApplied lists the staging files sealed into the chain by the
+	// most recent sequencer commit, so a crash before they are removed does
+	// not append them twice.
+	StagedApplied []string `json:"staged_applied,omitempty"`
 }
 
 const (
@@ -35,6 +39,9 @@ var ErrCursorLineOutOfRange = errors.New("cursor_line_out_of_range")
 
 const (
 	ErrorCodeStoreBusy               = "evidence_store_busy"
+	ErrorCodeEntryStaged             = "evidence_entry_staged"
+	ErrorCodeStoreRead               = "evidence_store_read_failed"
+	ErrorCodeStoreWrite              = "evidence_store_write_failed"
 	ErrorCodeLockNotSupportedWindows = "evidence_lock_not_supported_on_windows"
 )
 
@@ -68,6 +75,15 @@ func ErrorCode(err error) string {
 	return ""
 }
 
+// asStoreError wraps err in a StoreError with code unless it already
+// carries one.
+func asStoreError(code string, err error) error {
+	if err == nil || ErrorCode(err) != "" {
+		return err
+	}
+	return &StoreError{Code: code, Message: err.Error(), Err: err}
+}
+
 // IsStoreBusyError reports whether err is a store-busy error.
 func IsStoreBusyError(err error) bool {
 	return ErrorCode(err) == ErrorCodeStoreBusy
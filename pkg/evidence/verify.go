@@ -0,0 +1,71 @@
+package evidence
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the single-entry checks used when entries arrive one at
+// a time (for example, on API ingestion) rather than as a whole chain.
+var (
+	ErrEntryHashMismatch     = errors.New("entry_hash_mismatch")
+	ErrEntryUnsigned         = errors.New("entry_unsigned")
+	ErrEntryUnknownKey       = errors.New("entry_unknown_key")
+	ErrEntrySignatureInvalid = errors.New("entry_signature_invalid")
+)
+
+// VerifyEntryHash recomputes the hash over entry's fields and compares it
+// with entry.Hash.
+func VerifyEntryHash(entry EvidenceEntry) error {
+	recomputed, err := computeEntryHash(entry)
+	if err != nil {
+		return err
+	}
+	if entry.Hash != recomputed {
+		return fmt.Errorf("%w: stored %s, computed %s", ErrEntryHashMismatch, entry.Hash, recomputed)
+	}
+	return nil
+}
+
+// VerifyEntrySignature checks entry.Signature over entry.Hash with the key
+// named by entry.KeyID, or with any key in keyring for legacy entries that
+// carry no key_id.
+func VerifyEntrySignature(entry EvidenceEntry, keyring *Keyring) error {
+	_, err := SignerKeyID(entry, keyring)
+	return err
+}
+
+// SignerKeyID verifies entry's signature as VerifyEntrySignature does and
+// returns the ID of the key that produced it. Unlike entry.KeyID, which the
+// entry's author chooses, the result is backed by the signature.
+func SignerKeyID(entry EvidenceEntry, keyring *Keyring) (string, error) {
+	if entry.Signature == "" {
+		return "", ErrEntryUnsigned
+	}
+	if keyring == nil {
+		keyring = NewKeyring()
+	}
+	if _, known := keyring.Lookup(entry.KeyID); entry.KeyID != "" && !known {
+		return "", fmt.Errorf("%w: %s", ErrEntryUnknownKey, entry.KeyID)
+	}
+	if entry.KeyID == "" && keyring.Len() == 0 {
+		return "", ErrEntryUnknownKey
+	}
+	sig, err := base64.StdEncoding.DecodeString(entry.Signature)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid base64: %v", ErrEntrySignatureInvalid, err)
+	}
+	kids := keyring.KeyIDs()
+	if entry.KeyID != "" {
+		kids = []string{entry.KeyID}
+	}
+	for _, kid := range kids {
+		pub, _ := keyring.Lookup(kid)
+		if ed25519.Verify(pub, []byte(entry.Hash), sig) {
+			return kid, nil
+		}
+	}
+	return "", ErrEntrySignatureInvalid
+}
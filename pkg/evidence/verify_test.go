@@ -0,0 +1,77 @@
+package evidence
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestVerifyEntryHash(t *testing.T) {
+	t.Parallel()
+	entry := buildTestEntryWithSigner(t, newTestSigner(t), EntryTypeAnnotation, json.RawMessage(`{"note":"a"}`), "")
+	if err := VerifyEntryHash(entry); err != nil {
+		t.Fatalf("VerifyEntryHash: %v", err)
+	}
+	entry.Payload = json.RawMessage(`{"note":"b"}`)
+	if err := VerifyEntryHash(entry); !errors.Is(err, ErrEntryHashMismatch) {
+		t.Fatalf("err = %v, want %v", err, ErrEntryHashMismatch)
+	}
+}
+
+func TestVerifyEntrySignature(t *testing.T) {
+	t.Parallel()
+	signer, other := newTestSigner(t), newTestSigner(t)
+	signed := buildTestEntryWithSigner(t, signer, EntryTypeAnnotation, json.RawMessage(`{"note":"a"}`), "")
+
+	legacy := signed
+	legacy.KeyID = ""
+	unsigned := signed
+	unsigned.Signature = ""
+	forged := signed
+	forged.Hash = "sha256:0000"
+
+	tests := []struct {
+		name    string
+		entry   EvidenceEntry
+		keyring *Keyring
+		want    error
+	}{
+		{name: "registered key", entry: signed, keyring: NewKeyring(signer.PublicKey())},
+		{name: "legacy entry without key_id", entry: legacy, keyring: NewKeyring(other.PublicKey(), signer.PublicKey())},
+		{name: "unsigned", entry: unsigned, keyring: NewKeyring(signer.PublicKey()), want: ErrEntryUnsigned},
+		{name: "unknown key", entry: signed, keyring: NewKeyring(other.PublicKey()), want: ErrEntryUnknownKey},
+		{name: "no keys", entry: legacy, keyring: nil, want: ErrEntryUnknownKey},
+		{name: "signature over other hash", entry: forged, keyring: NewKeyring(signer.PublicKey()), want: ErrEntrySignatureInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := VerifyEntrySignature(tt.entry, tt.keyring)
+			if tt.want == nil && err != nil {
+				t.Fatalf("VerifyEntrySignature: %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignerKeyID(t *testing.T) {
+	t.Parallel()
+	signer, other := newTestSigner(t), newTestSigner(t)
+	signed := buildTestEntryWithSigner(t, signer, EntryTypeAnnotation, json.RawMessage(`{"note":"a"}`), "")
+	keyring := NewKeyring(other.PublicKey(), signer.PublicKey())
+
+	legacy := signed
+	legacy.KeyID = ""
+	if kid, err := SignerKeyID(legacy, keyring); err != nil || kid != KeyID(signer.PublicKey()) {
+		t.Fatalf("legacy entry signer = %q, %v", kid, err)
+	}
+	// Claiming another registered key does not make it the signer.
+	claimed := signed
+	claimed.KeyID = KeyID(other.PublicKey())
+	if _, err := SignerKeyID(claimed, keyring); !errors.Is(err, ErrEntrySignatureInvalid) {
+		t.Fatalf("claimed key err = %v, want %v", err, ErrEntrySignatureInvalid)
+	}
+}
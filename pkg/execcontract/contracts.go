@@ -58,19 +58,22 @@ type CanonicalAction struct {
 }
 
 type PrescribeInput struct {
-	Tool            string            `json:"tool"`
-	Operation       string            `json:"operation"`
-	RawArtifact     string            `json:"raw_artifact"`
-	CanonicalAction *CanonicalAction  `json:"canonical_action,omitempty"`
-	Actor           Actor             `json:"actor"`
-	SessionID       string            `json:"session_id,omitempty"`
-	OperationID     string            `json:"operation_id,omitempty"`
-	Attempt         int               `json:"attempt,omitempty"`
-	TraceID         string            `json:"trace_id,omitempty"`
-	SpanID          string            `json:"span_id,omitempty"`
-	ParentSpanID    string            `json:"parent_span_id,omitempty"`
-	Environment     string            `json:"environment,omitempty"`
-	ScopeDimensions map[string]string `json:"scope_dimensions,omitempty"`
+	Tool                  string            `json:"tool"`
+	Operation             string            `json:"operation"`
+	RawArtifact           string            `json:"raw_artifact"`
+	CanonicalAction       *CanonicalAction  `json:"canonical_action,omitempty"`
+	Actor                 Actor             `json:"actor"`
+	SessionID             string            `json:"session_id,omitempty"`
+	OperationID           string            `json:"operation_id,omitempty"`
+	Attempt               int               `json:"attempt,omitempty"`
+	TraceID               string            `json:"trace_id,omitempty"`
+	SpanID                string            `json:"span_id,omitempty"`
+	ParentSpanID          string            `json:"parent_span_id,omitempty"`
+	PlanID                string            `json:"plan_id,omitempty"`
+	PlanStep              int               `json:"plan_step,omitempty"`
+	RevertsPrescriptionID string            `json:"reverts_prescription_id,omitempty"`
+	Environment           string            `json:"environment,omitempty"`
+	ScopeDimensions       map[string]string `json:"scope_dimensions,omitempty"`
 }
 
 type DecisionContext struct {
@@ -136,6 +136,9 @@ func Export(opts Options) error {
 }
 
 func loadAllEntries(evidenceDir string) ([]evidence.EvidenceEntry, error) {
+	if evidence.IsBackendPath(evidenceDir) {
+		return evidence.ReadAllEntriesAtPath(evidenceDir)
+	}
 	var entries []evidence.EvidenceEntry
 
 	err := filepath.WalkDir(evidenceDir, func(path string, d os.DirEntry, err error) error {
@@ -30,15 +30,42 @@ func toEvidenceActor(actor InputActor) evidence.Actor {
 
 func toLifecyclePrescribeInput(input PrescribeInput) lifecycle.PrescribeInput {
 	return lifecycle.PrescribeInput{
+		Actor:                 toEvidenceActor(input.Actor),
+		Tool:                  input.Tool,
+		Operation:             input.Operation,
+		RawArtifact:           []byte(input.RawArtifact),
+		Environment:           input.Environment,
+		CanonicalAction:       input.CanonicalAction,
+		SessionID:             input.SessionID,
+		OperationID:           input.OperationID,
+		Attempt:               input.Attempt,
+		TraceID:               input.TraceID,
+		SpanID:                input.SpanID,
+		ParentSpanID:          input.ParentSpanID,
+		PlanID:                input.PlanID,
+		PlanStep:              input.PlanStep,
+		ScopeDimensions:       input.ScopeDimensions,
+		RevertsPrescriptionID: input.RevertsPrescriptionID,
+	}
+}
+
+func toLifecyclePlanInput(input PrescribePlanInput) lifecycle.PlanInput {
+	steps := make([]lifecycle.PlanStepInput, len(input.Steps))
+	for i, step := range input.Steps {
+		steps[i] = lifecycle.PlanStepInput{
+			Tool:            step.Tool,
+			Operation:       step.Operation,
+			Description:     step.Description,
+			RawArtifact:     []byte(step.RawArtifact),
+			CanonicalAction: step.CanonicalAction,
+		}
+	}
+	return lifecycle.PlanInput{
 		Actor:           toEvidenceActor(input.Actor),
-		Tool:            input.Tool,
-		Operation:       input.Operation,
-		RawArtifact:     []byte(input.RawArtifact),
 		Environment:     input.Environment,
-		CanonicalAction: input.CanonicalAction,
+		Steps:           steps,
 		SessionID:       input.SessionID,
 		OperationID:     input.OperationID,
-		Attempt:         input.Attempt,
 		TraceID:         input.TraceID,
 		SpanID:          input.SpanID,
 		ParentSpanID:    input.ParentSpanID,
@@ -61,3 +88,15 @@ func toLifecycleReportInput(input ReportInput) lifecycle.ReportInput {
 		ParentSpanID:    input.ParentSpanID,
 	}
 }
+
+func toLifecycleCancelInput(input CancelInput) lifecycle.CancelInput {
+	return lifecycle.CancelInput{
+		PrescriptionID: input.PrescriptionID,
+		Reason:         input.Reason,
+		Actor:          toEvidenceActor(input.Actor),
+		SessionID:      input.SessionID,
+		OperationID:    input.OperationID,
+		SpanID:         input.SpanID,
+		ParentSpanID:   input.ParentSpanID,
+	}
+}
@@ -48,7 +48,7 @@ func TestE2E_PrescribeReportLifecycle(t *testing.T) {
 	}
 	defer func() { _ = session.Close() }()
 
-	// List tools — verify all 3 registered
+	// List tools — verify all 5 registered
 	tools, err := session.ListTools(ctx, nil)
 	if err != nil {
 		t.Fatalf("list tools: %v", err)
@@ -59,7 +59,7 @@ func TestE2E_PrescribeReportLifecycle(t *testing.T) {
 		toolNames[tool.Name] = true
 		toolDefs[tool.Name] = tool
 	}
-	for _, name := range []string{"prescribe", "report", "get_event"} {
+	for _, name := range []string{"prescribe", "report", "prescribe_plan", "cancel", "get_event"} {
 		if !toolNames[name] {
 			t.Errorf("missing tool %q in tools/list response", name)
 		}
@@ -232,6 +232,92 @@ func TestE2E_UnprescribedReport(t *testing.T) {
 	}
 }
 
+func TestE2E_PrescribePlanLinksSteps(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	server, serverErr := NewServer(Options{
+		Name:         "test",
+		Version:      "0.0.1",
+		EvidencePath: dir,
+		Signer:       newTestSigner(t),
+	})
+	if serverErr != nil {
+		t.Fatalf("NewServer: %v", serverErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+
+	client := mcp.NewClient(
+		&mcp.Implementation{Name: "test-client", Version: "0.0.1"},
+		nil,
+	)
+	session, err := client.Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	actor := map[string]any{"type": "agent", "id": "test-agent", "origin": "e2e-test"}
+	planResult, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "prescribe_plan",
+		Arguments: map[string]any{
+			"actor":       actor,
+			"environment": "staging",
+			"steps": []any{
+				map[string]any{"tool": "kubectl", "operation": "apply", "description": "roll out config"},
+				map[string]any{"tool": "kubectl", "operation": "delete", "description": "drop old deployment"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("prescribe_plan: %v", err)
+	}
+	var planOut PrescribePlanOutput
+	if err := extractStructuredContent(planResult, &planOut); err != nil {
+		t.Fatalf("parse prescribe_plan output: %v", err)
+	}
+	if !planOut.OK || planOut.PlanID == "" || len(planOut.Steps) != 2 || planOut.EffectiveRisk == "" {
+		t.Fatalf("prescribe_plan output = %+v", planOut)
+	}
+
+	prescribeResult, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "prescribe",
+		Arguments: map[string]any{
+			"actor":        actor,
+			"tool":         "kubectl",
+			"operation":    "apply",
+			"raw_artifact": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n  namespace: staging\n",
+			"plan_id":      planOut.PlanID,
+			"plan_step":    1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("prescribe: %v", err)
+	}
+	var prescribeOut PrescribeOutput
+	if err := extractStructuredContent(prescribeResult, &prescribeOut); err != nil {
+		t.Fatalf("parse prescribe output: %v", err)
+	}
+	if !prescribeOut.OK || prescribeOut.PlanID != planOut.PlanID || prescribeOut.PlanStep != 1 {
+		t.Fatalf("prescribe output = %+v", prescribeOut)
+	}
+
+	entry, found, err := evidence.FindEntryByID(dir, prescribeOut.PrescriptionID)
+	if err != nil || !found {
+		t.Fatalf("FindEntryByID: found=%v err=%v", found, err)
+	}
+	if entry.SessionID != planOut.SessionID {
+		t.Fatalf("step session_id = %q, want the plan's %q", entry.SessionID, planOut.SessionID)
+	}
+}
+
 func TestE2E_ListResources(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
@@ -6,6 +6,12 @@ import (
 	"fmt"
 )
 
+//go:embed schemas/prescribe_plan.schema.json
+var prescribePlanSchemaBytes []byte
+
+//go:embed schemas/cancel.schema.json
+var cancelSchemaBytes []byte
+
 //go:embed schemas/get_event.schema.json
 var getEventSchemaBytes []byte
 
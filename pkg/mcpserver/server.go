@@ -11,8 +11,10 @@ import (
 
 	"samebits.com/evidra/internal/assessment"
 	"samebits.com/evidra/internal/canon"
+	"samebits.com/evidra/internal/config"
 	"samebits.com/evidra/internal/lifecycle"
 	"samebits.com/evidra/internal/score"
+	"samebits.com/evidra/internal/telemetry"
 	"samebits.com/evidra/pkg/evidence"
 	"samebits.com/evidra/pkg/execcontract"
 	"samebits.com/evidra/pkg/version"
@@ -22,6 +24,34 @@ import (
 // ForwardFunc is an optional callback to forward evidence entries to the API.
 type ForwardFunc func(ctx context.Context, entry json.RawMessage)
 
+// SpanFunc is an optional callback to export the span of a reported
+// operation. It runs in the report handler, so it should hand the span off
+// rather than export it inline.
+type SpanFunc func(ctx context.Context, span OperationSpan)
+
+// OperationSpan is one reported operation, from its prescription to its
+// report.
+type OperationSpan struct {
+	// TraceParent is the W3C traceparent to parent the span to; empty
+	// starts a new trace.
+	TraceParent    string
+	Start          time.Time
+	End            time.Time
+	SessionID      string
+	TraceID        string
+	PrescriptionID string
+	ReportID       string
+	Tool           string
+	Operation      string
+	OperationClass string
+	ScopeClass     string
+	EffectiveRisk  string
+	Verdict        string
+	ExitCode       *int
+	// Signals lists the signals detected in the session at report time.
+	Signals []string
+}
+
 // Options configures the benchmark MCP server.
 type Options struct {
 	Name               string
@@ -33,6 +63,13 @@ type Options struct {
 	ScoringProfilePath string
 	Signer             evidence.Signer // required: signs evidence entries
 	Forward            ForwardFunc     // optional: best-effort forward to API
+	// ApprovalRisk is the lowest effective risk that requires a human
+	// approval entry before the operation runs; empty disables approvals.
+	ApprovalRisk string
+	// Approvers holds the public keys whose approval entries count; with
+	// none, no operation that requires approval is approved.
+	Approvers *evidence.Keyring
+	Spans     SpanFunc // optional: export one span per reported operation
 }
 
 // InputActor identifies the caller in a prescribe request.
@@ -59,7 +96,11 @@ type PrescribeInput struct {
 	TraceID         string                 `json:"trace_id,omitempty"`
 	SpanID          string                 `json:"span_id,omitempty"`
 	ParentSpanID    string                 `json:"parent_span_id,omitempty"`
+	PlanID          string                 `json:"plan_id,omitempty"`
+	PlanStep        int                    `json:"plan_step,omitempty"`
 	ScopeDimensions map[string]string      `json:"scope_dimensions,omitempty"`
+	// RevertsPrescriptionID marks the operation as a rollback.
+	RevertsPrescriptionID string `json:"reverts_prescription_id,omitempty"`
 }
 
 // PrescribeOutput is returned by the prescribe tool.
@@ -76,7 +117,49 @@ type PrescribeOutput struct {
 	ScopeClass     string               `json:"scope_class"`
 	CanonVersion   string               `json:"canon_version"`
 	RetryCount     int                  `json:"retry_count,omitempty"`
-	Error          *ErrInfo             `json:"error,omitempty"`
+	PlanID         string               `json:"plan_id,omitempty"`
+	PlanStep       int                  `json:"plan_step,omitempty"`
+	// RevertMatch compares the rollback's resources with the reverted
+	// prescription's: full, partial, none or unknown.
+	RevertsPrescriptionID string `json:"reverts_prescription_id,omitempty"`
+	RevertMatch           string `json:"revert_match,omitempty"`
+	// ApprovalRequired means a human must record an approval before the
+	// operation runs; executing without one is a protocol violation.
+	ApprovalRequired bool     `json:"approval_required,omitempty"`
+	Error            *ErrInfo `json:"error,omitempty"`
+}
+
+// PrescribePlanInput is the input schema for the prescribe_plan tool.
+type PrescribePlanInput struct {
+	Actor           InputActor        `json:"actor"`
+	Steps           []PlanStepInput   `json:"steps"`
+	Environment     string            `json:"environment,omitempty"`
+	SessionID       string            `json:"session_id,omitempty"`
+	OperationID     string            `json:"operation_id,omitempty"`
+	TraceID         string            `json:"trace_id,omitempty"`
+	SpanID          string            `json:"span_id,omitempty"`
+	ParentSpanID    string            `json:"parent_span_id,omitempty"`
+	ScopeDimensions map[string]string `json:"scope_dimensions,omitempty"`
+}
+
+// PlanStepInput is one planned operation in a prescribe_plan request.
+type PlanStepInput struct {
+	Tool            string                 `json:"tool"`
+	Operation       string                 `json:"operation"`
+	Description     string                 `json:"description,omitempty"`
+	RawArtifact     string                 `json:"raw_artifact,omitempty"`
+	CanonicalAction *canon.CanonicalAction `json:"canonical_action,omitempty"`
+}
+
+// PrescribePlanOutput is returned by the prescribe_plan tool.
+type PrescribePlanOutput struct {
+	OK            bool                `json:"ok"`
+	PlanID        string              `json:"plan_id"`
+	SessionID     string              `json:"session_id,omitempty"`
+	TraceID       string              `json:"trace_id,omitempty"`
+	EffectiveRisk string              `json:"effective_risk,omitempty"`
+	Steps         []evidence.PlanStep `json:"steps,omitempty"`
+	Error         *ErrInfo            `json:"error,omitempty"`
 }
 
 // ReportInput is the input schema for the report tool.
@@ -102,6 +185,8 @@ type ReportOutput struct {
 	ExitCode         *int                      `json:"exit_code,omitempty"`
 	Verdict          evidence.Verdict          `json:"verdict"`
 	DecisionContext  *evidence.DecisionContext `json:"decision_context,omitempty"`
+	LateReport       bool                      `json:"late_report,omitempty"`
+	ApprovalMissing  bool                      `json:"approval_missing,omitempty"`
 	Score            float64                   `json:"score"`
 	ScoreBand        string                    `json:"score_band"`
 	ScoringProfileID string                    `json:"scoring_profile_id"`
@@ -111,6 +196,26 @@ type ReportOutput struct {
 	Error            *ErrInfo                  `json:"error,omitempty"`
 }
 
+// CancelInput is the input schema for the cancel tool.
+type CancelInput struct {
+	PrescriptionID string     `json:"prescription_id"`
+	Reason         string     `json:"reason"`
+	Actor          InputActor `json:"actor"`
+	SessionID      string     `json:"session_id,omitempty"`
+	OperationID    string     `json:"operation_id,omitempty"`
+	SpanID         string     `json:"span_id,omitempty"`
+	ParentSpanID   string     `json:"parent_span_id,omitempty"`
+}
+
+// CancelOutput is returned by the cancel tool.
+type CancelOutput struct {
+	OK             bool     `json:"ok"`
+	CancelID       string   `json:"cancel_id"`
+	PrescriptionID string   `json:"prescription_id"`
+	SessionID      string   `json:"session_id,omitempty"`
+	Error          *ErrInfo `json:"error,omitempty"`
+}
+
 // ErrInfo represents an error in tool output.
 type ErrInfo struct {
 	Code    string `json:"code"`
@@ -125,25 +230,49 @@ type reportHandler struct {
 	service *MCPService
 }
 
+type prescribePlanHandler struct {
+	service *MCPService
+}
+
+type cancelHandler struct {
+	service *MCPService
+}
+
 // MCPService provides prescribe and report operations.
 type MCPService struct {
 	evidencePath      string
 	retryTracker      *RetryTracker
 	signer            evidence.Signer
 	bestEffortWrites  bool
+	approvalRisk      string
+	approvers         *evidence.Keyring
 	lifecycle         *lifecycle.Service
 	forwardFunc       ForwardFunc
+	spanFunc          SpanFunc
 	scoringProfile    score.Profile
 	assessmentTracker *assessment.Tracker
 	initOnce          sync.Once
 	initErr           error
 	closeOnce         sync.Once
 	closeErr          error
+
+	// traceParents holds the traceparent of prescribe calls until the
+	// operation is reported or cancelled.
+	traceParentsMu sync.Mutex
+	traceParents   map[string]string
 }
 
 const (
 	defaultGetEventToolDescription = "Look up an evidence record by event_id."
 
+	prescribePlanToolDescription = "Prescribe an ordered multi-step operation up front. " +
+		"Returns a plan_id and the aggregate risk; then call `prescribe` for each step with plan_id and plan_step, and `report` each step as usual. " +
+		"Skipped, reordered, and unplanned steps are recorded as protocol violations."
+
+	cancelToolDescription = "Withdraw a prescription you decided not to execute. " +
+		"Closes the prescription with a reason so it is not counted as unreported; do not `report` it afterwards. " +
+		"Use `report` with verdict=declined instead when the decision not to act is itself the outcome worth scoring."
+
 	defaultInitializeInstructions = "Evidra — Flight recorder for AI infrastructure agents. " +
 		"Call `prescribe` BEFORE any infrastructure operation and `report` with an explicit verdict AFTER execution or decision."
 )
@@ -184,61 +313,98 @@ func NewServerWithCleanup(opts Options) (*mcp.Server, func() error, error) {
 		return nil, nil, err
 	}
 
-	prescribe := &prescribeHandler{service: svc}
-	report := &reportHandler{service: svc}
-	getEvent := &getEventHandler{service: svc}
+	server := mcp.NewServer(
+		&mcp.Implementation{Name: opts.Name, Version: opts.Version},
+		&mcp.ServerOptions{
+			Instructions: initializeInstructions,
+		},
+	)
+	if err := addLifecycleTools(server, svc); err != nil {
+		return nil, nil, err
+	}
+	if err := addEvidenceTools(server, svc); err != nil {
+		return nil, nil, err
+	}
+	return server, svc.Close, nil
+}
 
+// addLifecycleTools registers the tools that record evidence: prescribe,
+// report, prescribe_plan, and cancel.
+func addLifecycleTools(server *mcp.Server, svc *MCPService) error {
 	prescribeDef, err := execcontract.PrescribeToolDefinition()
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	reportDef, err := execcontract.ReportToolDefinition()
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
-	getEventSchema, err := loadSchema(getEventSchemaBytes, "schemas/get_event.schema.json")
+	prescribePlanSchema, err := loadSchema(prescribePlanSchemaBytes, "schemas/prescribe_plan.schema.json")
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
-	getEventOutputSchema, err := loadSchema(getEventOutputSchemaBytes, "schemas/get_event.output.schema.json")
+	cancelSchema, err := loadSchema(cancelSchemaBytes, "schemas/cancel.schema.json")
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
-	server := mcp.NewServer(
-		&mcp.Implementation{Name: opts.Name, Version: opts.Version},
-		&mcp.ServerOptions{
-			Instructions: initializeInstructions,
-		},
-	)
-
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "prescribe",
 		Title:       "Record Infrastructure Intent",
 		Description: prescribeDef.Description,
-		Annotations: &mcp.ToolAnnotations{
-			Title:           "Prescribe",
-			ReadOnlyHint:    false,
-			IdempotentHint:  false,
-			DestructiveHint: boolPtr(false),
-			OpenWorldHint:   boolPtr(false),
-		},
+		Annotations: recordingToolAnnotations("Prescribe"),
 		InputSchema: prescribeDef.Parameters,
-	}, prescribe.Handle)
+	}, (&prescribeHandler{service: svc}).Handle)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "report",
 		Title:       "Report Operation Result",
 		Description: reportDef.Description,
-		Annotations: &mcp.ToolAnnotations{
-			Title:           "Report",
-			ReadOnlyHint:    false,
-			IdempotentHint:  false,
-			DestructiveHint: boolPtr(false),
-			OpenWorldHint:   boolPtr(false),
-		},
+		Annotations: recordingToolAnnotations("Report"),
 		InputSchema: reportDef.Parameters,
-	}, report.Handle)
+	}, (&reportHandler{service: svc}).Handle)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "prescribe_plan",
+		Title:       "Record Multi-Step Plan",
+		Description: prescribePlanToolDescription,
+		Annotations: recordingToolAnnotations("Prescribe Plan"),
+		InputSchema: prescribePlanSchema,
+	}, (&prescribePlanHandler{service: svc}).Handle)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "cancel",
+		Title:       "Withdraw Prescription",
+		Description: cancelToolDescription,
+		Annotations: recordingToolAnnotations("Cancel"),
+		InputSchema: cancelSchema,
+	}, (&cancelHandler{service: svc}).Handle)
+	return nil
+}
+
+// recordingToolAnnotations describes a tool that appends evidence but
+// touches nothing outside the evidence store.
+func recordingToolAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		ReadOnlyHint:    false,
+		IdempotentHint:  false,
+		DestructiveHint: boolPtr(false),
+		OpenWorldHint:   boolPtr(false),
+	}
+}
+
+// addEvidenceTools registers the read-only get_event tool and the evidence
+// resources.
+func addEvidenceTools(server *mcp.Server, svc *MCPService) error {
+	getEventSchema, err := loadSchema(getEventSchemaBytes, "schemas/get_event.schema.json")
+	if err != nil {
+		return err
+	}
+	getEventOutputSchema, err := loadSchema(getEventOutputSchemaBytes, "schemas/get_event.output.schema.json")
+	if err != nil {
+		return err
+	}
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_event",
@@ -253,7 +419,7 @@ func NewServerWithCleanup(opts Options) (*mcp.Server, func() error, error) {
 		},
 		InputSchema:  getEventSchema,
 		OutputSchema: getEventOutputSchema,
-	}, getEvent.Handle)
+	}, (&getEventHandler{service: svc}).Handle)
 
 	// Evidence resources
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -270,8 +436,7 @@ func NewServerWithCleanup(opts Options) (*mcp.Server, func() error, error) {
 		MIMEType:    "application/json",
 		URI:         "evidra://evidence/manifest",
 	}, svc.readResourceManifest)
-
-	return server, svc.Close, nil
+	return nil
 }
 
 func newMCPService(opts Options) (*MCPService, error) {
@@ -279,7 +444,10 @@ func newMCPService(opts Options) (*MCPService, error) {
 		evidencePath:      opts.EvidencePath,
 		signer:            opts.Signer,
 		bestEffortWrites:  opts.BestEffortWrites,
+		approvalRisk:      opts.ApprovalRisk,
+		approvers:         opts.Approvers,
 		forwardFunc:       opts.Forward,
+		spanFunc:          opts.Spans,
 		assessmentTracker: assessment.NewTracker(opts.EvidencePath),
 	}
 	if opts.RetryTracker {
@@ -304,19 +472,37 @@ func defaultServerVersion(input string) string {
 
 func (h *prescribeHandler) Handle(
 	ctx context.Context,
-	_ *mcp.CallToolRequest,
+	req *mcp.CallToolRequest,
 	input PrescribeInput,
 ) (*mcp.CallToolResult, PrescribeOutput, error) {
-	output := h.service.PrescribeCtx(ctx, input)
+	output := h.service.PrescribeCtx(contextWithTraceParent(ctx, req), input)
 	return &mcp.CallToolResult{}, output, nil
 }
 
 func (h *reportHandler) Handle(
 	ctx context.Context,
-	_ *mcp.CallToolRequest,
+	req *mcp.CallToolRequest,
 	input ReportInput,
 ) (*mcp.CallToolResult, ReportOutput, error) {
-	output := h.service.ReportCtx(ctx, input)
+	output := h.service.ReportCtx(contextWithTraceParent(ctx, req), input)
+	return &mcp.CallToolResult{}, output, nil
+}
+
+func (h *prescribePlanHandler) Handle(
+	ctx context.Context,
+	_ *mcp.CallToolRequest,
+	input PrescribePlanInput,
+) (*mcp.CallToolResult, PrescribePlanOutput, error) {
+	output := h.service.PrescribePlanCtx(ctx, input)
+	return &mcp.CallToolResult{}, output, nil
+}
+
+func (h *cancelHandler) Handle(
+	ctx context.Context,
+	_ *mcp.CallToolRequest,
+	input CancelInput,
+) (*mcp.CallToolResult, CancelOutput, error) {
+	output := h.service.CancelCtx(ctx, input)
 	return &mcp.CallToolResult{}, output, nil
 }
 
@@ -326,6 +512,8 @@ func (s *MCPService) newLifecycleService() *lifecycle.Service {
 		Signer:           s.signer,
 		RetryTracker:     toRetryRecorder(s.retryTracker),
 		BestEffortWrites: s.bestEffortWrites,
+		ApprovalRisk:     s.approvalRisk,
+		Approvers:        s.approvers,
 	})
 }
 
@@ -381,21 +569,94 @@ func (s *MCPService) PrescribeCtx(ctx context.Context, input PrescribeInput) Pre
 	if out.Persisted {
 		s.observeWrittenEntry(out.Entry)
 		s.tryForwardEntry(ctx, out.RawEntry)
+		s.rememberTraceParent(ctx, out.PrescriptionID)
 	}
 
 	return PrescribeOutput{
+		OK:                    true,
+		PrescriptionID:        out.PrescriptionID,
+		RiskInputs:            out.RiskInputs,
+		EffectiveRisk:         out.EffectiveRisk,
+		ArtifactDigest:        out.ArtifactDigest,
+		IntentDigest:          out.IntentDigest,
+		ShapeHash:             out.ShapeHash,
+		ResourceCount:         out.ResourceCount,
+		OperationClass:        out.OperationClass,
+		ScopeClass:            out.ScopeClass,
+		CanonVersion:          out.CanonVersion,
+		RetryCount:            out.RetryCount,
+		PlanID:                out.PlanID,
+		PlanStep:              out.PlanStep,
+		RevertsPrescriptionID: out.RevertsPrescriptionID,
+		RevertMatch:           out.RevertMatch,
+		ApprovalRequired:      out.ApprovalRequired,
+	}
+}
+
+// PrescribePlanCtx records a multi-step plan and returns its aggregate risk.
+func (s *MCPService) PrescribePlanCtx(ctx context.Context, input PrescribePlanInput) PrescribePlanOutput {
+	svc, err := s.lifecycleService()
+	if err != nil {
+		return PrescribePlanOutput{
+			OK:    false,
+			Error: &ErrInfo{Code: string(lifecycle.ErrCodeInternal), Message: err.Error()},
+		}
+	}
+
+	out, err := svc.PrescribePlan(ctx, toLifecyclePlanInput(input))
+	if err != nil {
+		return PrescribePlanOutput{
+			OK:    false,
+			Error: lifecycleErrInfo(err),
+		}
+	}
+
+	if out.Persisted {
+		s.observeWrittenEntry(out.Entry)
+		s.tryForwardEntry(ctx, out.RawEntry)
+	}
+
+	return PrescribePlanOutput{
+		OK:            true,
+		PlanID:        out.PlanID,
+		SessionID:     out.SessionID,
+		TraceID:       out.TraceID,
+		EffectiveRisk: out.EffectiveRisk,
+		Steps:         out.Steps,
+	}
+}
+
+// CancelCtx withdraws an open prescription.
+func (s *MCPService) CancelCtx(ctx context.Context, input CancelInput) CancelOutput {
+	svc, err := s.lifecycleService()
+	if err != nil {
+		return CancelOutput{
+			OK:             false,
+			PrescriptionID: input.PrescriptionID,
+			Error:          &ErrInfo{Code: string(lifecycle.ErrCodeInternal), Message: err.Error()},
+		}
+	}
+
+	out, err := svc.Cancel(ctx, toLifecycleCancelInput(input))
+	if err != nil {
+		return CancelOutput{
+			OK:             false,
+			PrescriptionID: input.PrescriptionID,
+			Error:          lifecycleErrInfo(err),
+		}
+	}
+
+	if out.Persisted {
+		s.observeWrittenEntry(out.Entry)
+		s.tryForwardEntry(ctx, out.RawEntry)
+	}
+	s.takeTraceParent(out.PrescriptionID)
+
+	return CancelOutput{
 		OK:             true,
+		CancelID:       out.CancelID,
 		PrescriptionID: out.PrescriptionID,
-		RiskInputs:     out.RiskInputs,
-		EffectiveRisk:  out.EffectiveRisk,
-		ArtifactDigest: out.ArtifactDigest,
-		IntentDigest:   out.IntentDigest,
-		ShapeHash:      out.ShapeHash,
-		ResourceCount:  out.ResourceCount,
-		OperationClass: out.OperationClass,
-		ScopeClass:     out.ScopeClass,
-		CanonVersion:   out.CanonVersion,
-		RetryCount:     out.RetryCount,
+		SessionID:      out.SessionID,
 	}
 }
 
@@ -444,6 +705,7 @@ func (s *MCPService) ReportCtx(ctx context.Context, input ReportInput) ReportOut
 			Error:           &ErrInfo{Code: string(lifecycle.ErrCodeInternal), Message: "failed to build assessment snapshot"},
 		}
 	}
+	s.tryExportSpan(ctx, out, snapshot.SignalSummary)
 
 	return ReportOutput{
 		OK:               true,
@@ -452,6 +714,8 @@ func (s *MCPService) ReportCtx(ctx context.Context, input ReportInput) ReportOut
 		ExitCode:         out.ExitCode,
 		Verdict:          out.Verdict,
 		DecisionContext:  out.DecisionContext,
+		LateReport:       out.Late,
+		ApprovalMissing:  out.ApprovalMissing,
 		Score:            snapshot.Score,
 		ScoreBand:        snapshot.ScoreBand,
 		ScoringProfileID: snapshot.ScoringProfileID,
@@ -496,6 +760,65 @@ func (s *MCPService) tryForwardEntry(ctx context.Context, entry json.RawMessage)
 	s.forwardFunc(ctx, entry)
 }
 
+type traceParentKey struct{}
+
+// contextWithTraceParent carries the W3C traceparent a client sent in the
+// tool call's _meta.
+func contextWithTraceParent(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil || req.Params == nil {
+		return ctx
+	}
+	if tp, ok := req.Params.GetMeta()["traceparent"].(string); ok && strings.TrimSpace(tp) != "" {
+		return context.WithValue(ctx, traceParentKey{}, strings.TrimSpace(tp))
+	}
+	return ctx
+}
+
+func traceParentFromContext(ctx context.Context) string {
+	tp, _ := ctx.Value(traceParentKey{}).(string)
+	return tp
+}
+
+func (s *MCPService) rememberTraceParent(ctx context.Context, prescriptionID string) {
+	tp := traceParentFromContext(ctx)
+	if s.spanFunc == nil || tp == "" {
+		return
+	}
+	s.traceParentsMu.Lock()
+	defer s.traceParentsMu.Unlock()
+	if s.traceParents == nil {
+		s.traceParents = make(map[string]string)
+	}
+	s.traceParents[prescriptionID] = tp
+}
+
+func (s *MCPService) takeTraceParent(prescriptionID string) string {
+	s.traceParentsMu.Lock()
+	defer s.traceParentsMu.Unlock()
+	tp := s.traceParents[prescriptionID]
+	delete(s.traceParents, prescriptionID)
+	return tp
+}
+
+// tryExportSpan best-effort exports the span of a reported operation. The
+// parent is the report call's traceparent, else the prescribe call's, else
+// TRACEPARENT from the server's environment.
+func (s *MCPService) tryExportSpan(ctx context.Context, report lifecycle.ReportOutput, signals map[string]int) {
+	parent := s.takeTraceParent(report.PrescriptionID)
+	if s.spanFunc == nil || !report.Persisted {
+		return
+	}
+	if tp := traceParentFromContext(ctx); tp != "" {
+		parent = tp
+	}
+	span, err := telemetry.OperationSpanAtPath(s.evidencePath, report.Entry, signals)
+	if err != nil {
+		return
+	}
+	span.TraceParent = config.ResolveTraceParent(parent)
+	s.spanFunc(ctx, OperationSpan(span))
+}
+
 func lifecycleErrInfo(err error) *ErrInfo {
 	code := lifecycle.ErrorCode(err)
 	if code == "" {
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
 	"samebits.com/evidra/internal/lifecycle"
 	"samebits.com/evidra/internal/testutil"
 	"samebits.com/evidra/pkg/evidence"
@@ -252,6 +254,100 @@ func TestReport_MissingPrescriptionID(t *testing.T) {
 	}
 }
 
+func TestCancel_WithdrawsPrescription(t *testing.T) {
+	t.Parallel()
+
+	svc := &MCPService{evidencePath: t.TempDir(), signer: testutil.TestSigner(t)}
+	rx := svc.Prescribe(PrescribeInput{
+		Actor:       InputActor{Type: "agent", ID: "test", Origin: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: k8sDeployment,
+	})
+	if !rx.OK {
+		t.Fatalf("prescribe failed: %v", rx.Error)
+	}
+
+	out := svc.CancelCtx(context.Background(), CancelInput{PrescriptionID: rx.PrescriptionID, Reason: "changed plan"})
+	if !out.OK || out.CancelID == "" || out.PrescriptionID != rx.PrescriptionID {
+		t.Fatalf("cancel output = %+v", out)
+	}
+	again := svc.CancelCtx(context.Background(), CancelInput{PrescriptionID: rx.PrescriptionID, Reason: "changed plan"})
+	if again.OK || again.Error == nil || again.Error.Code != "invalid_input" {
+		t.Fatalf("second cancel = %+v, want invalid_input", again)
+	}
+}
+
+func TestReport_FlagsMissingApproval(t *testing.T) {
+	t.Parallel()
+
+	svc := &MCPService{evidencePath: t.TempDir(), signer: testutil.TestSigner(t), approvalRisk: "high"}
+	rx := svc.Prescribe(PrescribeInput{
+		Actor:       InputActor{Type: "agent", ID: "test", Origin: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "delete",
+		RawArtifact: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n",
+	})
+	if !rx.OK || !rx.ApprovalRequired {
+		t.Fatalf("prescribe = %+v, want approval_required", rx)
+	}
+
+	exitCode := 0
+	out := svc.ReportCtx(context.Background(), ReportInput{
+		PrescriptionID: rx.PrescriptionID,
+		Verdict:        evidence.VerdictSuccess,
+		ExitCode:       &exitCode,
+	})
+	if !out.OK || !out.ApprovalMissing {
+		t.Fatalf("report = %+v, want approval_missing", out)
+	}
+}
+
+func TestReport_ExportsSpanParentedToPrescribeMeta(t *testing.T) {
+	t.Parallel()
+
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var spans []OperationSpan
+	svc := &MCPService{
+		evidencePath: t.TempDir(),
+		signer:       testutil.TestSigner(t),
+		spanFunc:     func(_ context.Context, span OperationSpan) { spans = append(spans, span) },
+	}
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Meta: mcp.Meta{"traceparent": traceParent}}}
+	rx := svc.PrescribeCtx(contextWithTraceParent(context.Background(), req), PrescribeInput{
+		Actor:       InputActor{Type: "agent", ID: "test", Origin: "mcp"},
+		Tool:        "kubectl",
+		Operation:   "apply",
+		RawArtifact: k8sDeployment,
+	})
+	if !rx.OK {
+		t.Fatalf("prescribe = %+v", rx)
+	}
+
+	exitCode := 0
+	out := svc.ReportCtx(context.Background(), ReportInput{
+		PrescriptionID: rx.PrescriptionID,
+		Verdict:        evidence.VerdictSuccess,
+		ExitCode:       &exitCode,
+	})
+	if !out.OK {
+		t.Fatalf("report = %+v", out)
+	}
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.TraceParent != traceParent || span.PrescriptionID != rx.PrescriptionID || span.ReportID != out.ReportID {
+		t.Fatalf("span = %+v", span)
+	}
+	if span.Tool != "kubectl" || span.OperationClass != rx.OperationClass || span.EffectiveRisk != rx.EffectiveRisk || span.Verdict != "success" {
+		t.Fatalf("span attributes = %+v", span)
+	}
+	if len(svc.traceParents) != 0 {
+		t.Fatalf("traceParents not released: %v", svc.traceParents)
+	}
+}
+
 func TestRetryTracker_CountsRetries(t *testing.T) {
 	t.Parallel()
 
@@ -288,6 +384,7 @@ func TestSchemaStructParity(t *testing.T) {
 	}{
 		{name: "prescribe", schema: mustToolSchema(t, execcontract.PrescribeToolDefinition), structType: reflect.TypeOf(PrescribeInput{})},
 		{name: "report", schema: mustToolSchema(t, execcontract.ReportToolDefinition), structType: reflect.TypeOf(ReportInput{})},
+		{name: "cancel", schema: mustEmbeddedSchema(t, cancelSchemaBytes), structType: reflect.TypeOf(CancelInput{})},
 	}
 
 	for _, tc := range cases {
@@ -335,6 +432,16 @@ func mustToolSchema(t *testing.T, load func() (execcontract.ToolDefinition, erro
 	return def.Parameters
 }
 
+func mustEmbeddedSchema(t *testing.T, raw []byte) map[string]any {
+	t.Helper()
+
+	schema, err := loadSchema(raw, "embedded")
+	if err != nil {
+		t.Fatalf("load embedded schema: %v", err)
+	}
+	return schema
+}
+
 func assertRiskInputTagPresent(t *testing.T, inputs []evidence.RiskInput, source, want string) {
 	t.Helper()
 	for _, input := range inputs {